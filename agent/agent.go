@@ -34,6 +34,7 @@ import (
 	"github.com/aws/amazon-ssm-agent/agent/rebooter"
 	"github.com/aws/amazon-ssm-agent/agent/session/utility"
 	"github.com/aws/amazon-ssm-agent/agent/ssm"
+	"github.com/aws/amazon-ssm-agent/agent/watchdog"
 )
 
 const (
@@ -104,7 +105,9 @@ func startAgent(ssmAgent agent.ISSMAgent, context context.T, log logger.T, insta
 	return
 }
 
-func blockUntilSignaled(log logger.T) {
+// blockUntilSignaled blocks until the agent is signaled to exit or the watchdog requests it to restart,
+// it returns true in the latter case
+func blockUntilSignaled(log logger.T) (restart bool) {
 	// Below channel will handle all machine initiated shutdown/reboot requests.
 
 	// Set up channel on which to receive signal notifications.
@@ -117,8 +120,14 @@ func blockUntilSignaled(log logger.T) {
 	// Otherwise we will continue execution and exit the program.
 	signal.Notify(c, os.Interrupt, os.Kill, syscall.SIGTERM)
 
-	s := <-c
-	log.Info("Got signal:", s, " value:", s.Signal)
+	select {
+	case s := <-c:
+		log.Info("Got signal:", s, " value:", s.Signal)
+		return false
+	case <-watchdog.RestartRequested():
+		log.Info("Watchdog requested the agent to restart")
+		return true
+	}
 }
 
 // Run as a single process. Used by Unix systems and when running agent from console.
@@ -138,6 +147,9 @@ func run(log logger.T) {
 		log.Errorf("error occurred when starting amazon-ssm-agent: %v", err)
 		return
 	}
-	blockUntilSignaled(log)
+	restart := blockUntilSignaled(log)
 	agent.Stop()
+	if restart {
+		restartAgent(log)
+	}
 }
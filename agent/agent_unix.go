@@ -2,7 +2,14 @@
 
 package main
 
-import logger "github.com/aws/amazon-ssm-agent/agent/log/ssmlog"
+import (
+	"os"
+	"syscall"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	logger "github.com/aws/amazon-ssm-agent/agent/log/ssmlog"
+)
 
 func main() {
 	// initialize logger
@@ -16,3 +23,17 @@ func main() {
 	// run agent
 	run(log)
 }
+
+// restartAgent replaces the stopped agent process with a new agent process, keeping its process id
+// so that the service manager keeps tracking it
+func restartAgent(log log.T) {
+	executable, err := os.Executable()
+	if err == nil {
+		log.Info("Restarting the agent")
+		log.Flush()
+		err = syscall.Exec(executable, os.Args, os.Environ())
+	}
+	log.Errorf("Failed to restart the agent, %v", err)
+	log.Flush()
+	os.Exit(appconfig.ErrorExitCode)
+}
@@ -3,8 +3,10 @@
 package main
 
 import (
+	"fmt"
 	"log"
 	"os"
+	"os/exec"
 	"time"
 
 	"github.com/aws/amazon-ssm-agent/agent/appconfig"
@@ -12,6 +14,7 @@ import (
 	"github.com/aws/amazon-ssm-agent/agent/log/ssmlog"
 	"github.com/aws/amazon-ssm-agent/agent/longrunning/plugin/cloudwatch"
 	"github.com/aws/amazon-ssm-agent/agent/proxyconfig"
+	"github.com/aws/amazon-ssm-agent/agent/watchdog"
 	"golang.org/x/sys/windows/registry"
 	"golang.org/x/sys/windows/svc"
 	"golang.org/x/sys/windows/svc/mgr"
@@ -192,11 +195,19 @@ func (a *amazonSSMAgentService) Execute(args []string, r <-chan svc.ChangeReques
 	const acceptCmds = svc.AcceptStop | svc.AcceptShutdown
 	s <- svc.Status{State: svc.Running, Accepts: acceptCmds}
 
+	restart := false
 loop:
 	// using an infinite loop to wait for ChangeRequests
 	for {
-		// block and wait for ChangeRequests
-		c := <-r
+		// block and wait for ChangeRequests or the watchdog
+		var c svc.ChangeRequest
+		select {
+		case c = <-r:
+		case <-watchdog.RestartRequested():
+			log.Info("Watchdog requested the agent to restart")
+			restart = true
+			break loop
+		}
 
 		// handle ChangeRequest, svc.Pause is not supported
 		switch c.Cmd {
@@ -213,5 +224,26 @@ loop:
 	}
 	s <- svc.Status{State: svc.StopPending}
 	agent.Stop()
+	if restart {
+		startServiceWhenStopped(log)
+		return true, appconfig.ErrorExitCode
+	}
 	return false, appconfig.SuccessExitCode
 }
+
+// startServiceWhenStopped starts a PowerShell process that starts the agent service again once it stopped
+func startServiceWhenStopped(log logger.T) {
+	command := fmt.Sprintf("(Get-Service -Name '%v').WaitForStatus('Stopped', '00:05:00'); Start-Service -Name '%v'",
+		serviceName, serviceName)
+	if err := exec.Command(appconfig.PowerShellPluginCommandName, "-NoProfile", "-NonInteractive", "-Command", command).Start(); err != nil {
+		log.Errorf("Failed to schedule the restart of the agent service, %v", err)
+	}
+	log.Flush()
+}
+
+// restartAgent exits the agent run from the console, it can't be restarted without the service manager
+func restartAgent(log logger.T) {
+	log.Info("Exiting, restart the agent to resume")
+	log.Flush()
+	os.Exit(appconfig.ErrorExitCode)
+}
@@ -0,0 +1,192 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package logshipper
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/agentlogstocloudwatch/cloudwatchlogspublisher/cloudwatchlogsinterface"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+)
+
+const (
+	// maxReadBytes is the size of the log file read for one PutLogEvents call
+	maxReadBytes = 256 * 1024
+	// maxEventsPerBatch is the number of events PutLogEvents accepts in one call
+	maxEventsPerBatch = 10000
+	// maxEventBytes is the size of an event PutLogEvents accepts, minus the 26 bytes it counts for each event
+	maxEventBytes = 256*1024 - 26
+	// entryTimeLayout is the layout of the time starting every entry of the agent logs
+	entryTimeLayout = "2006-01-02 15:04:05"
+)
+
+// entryStart matches the time starting every entry of the agent logs, the lines that don't start with it continue
+// the previous entry
+var entryStart = regexp.MustCompile(`^\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}`)
+
+// fileStream ships a log file to its log stream, from the offset of the first byte not shipped yet
+type fileStream struct {
+	path          string
+	logGroup      string
+	logStream     string
+	offset        int64
+	sequenceToken *string
+	created       bool
+	backoff       time.Duration
+	retryAt       time.Time
+}
+
+// ship ships the entries appended to the file since the last call, the offset is only moved past the entries
+// CloudWatch Logs accepted so that the failed entries are shipped again by the next call
+func (f *fileStream) ship(log log.T, service cloudwatchlogsinterface.ICloudWatchLogsService) error {
+	if !f.created {
+		if err := f.createLogStream(log, service); err != nil {
+			return err
+		}
+	}
+
+	for {
+		events, next, err := readEvents(f.path, f.offset)
+		if err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			f.offset = next
+			return nil
+		}
+		sequenceToken, err := service.PutLogEvents(log, events, f.logGroup, f.logStream, f.sequenceToken)
+		if err != nil {
+			return err
+		}
+		f.sequenceToken = sequenceToken
+		f.offset = next
+	}
+}
+
+// createLogStream creates the log group and the log stream unless they exist
+func (f *fileStream) createLogStream(log log.T, service cloudwatchlogsinterface.ICloudWatchLogsService) error {
+	if !service.IsLogGroupPresent(log, f.logGroup) {
+		if err := service.CreateLogGroup(log, f.logGroup); err != nil {
+			return err
+		}
+	}
+	if !service.IsLogStreamPresent(log, f.logGroup, f.logStream) {
+		if err := service.CreateLogStream(log, f.logGroup, f.logStream); err != nil {
+			return err
+		}
+	}
+	f.sequenceToken = service.GetSequenceTokenForStream(log, f.logGroup, f.logStream)
+	f.created = true
+	return nil
+}
+
+// readEvents returns the events of the complete lines of the file after the offset, and the offset following them.
+// A file smaller than the offset was rolled over and is read from its start.
+func readEvents(path string, offset int64) (events []*cloudwatchlogs.InputLogEvent, next int64, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, 0, nil
+		}
+		return nil, offset, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, offset, err
+	}
+	if info.Size() < offset {
+		offset = 0
+	}
+	if _, err = file.Seek(offset, io.SeekStart); err != nil {
+		return nil, offset, err
+	}
+	buffer := make([]byte, maxReadBytes)
+	n, err := io.ReadFull(file, buffer)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, offset, err
+	}
+	buffer = buffer[:n]
+
+	// the last line is only shipped once it's complete, unless it doesn't fit in the buffer
+	if end := bytes.LastIndexByte(buffer, '\n'); end >= 0 {
+		buffer = buffer[:end+1]
+	} else if n < maxReadBytes {
+		return nil, offset, nil
+	}
+
+	events, consumed := parseEvents(buffer)
+	return events, offset + int64(consumed), nil
+}
+
+// parseEvents returns an event per entry of the lines, and the number of bytes of the entries returned.
+// The events are in chronological order as PutLogEvents requires, an entry logged before the previous one
+// gets the time of the previous one.
+func parseEvents(lines []byte) (events []*cloudwatchlogs.InputLogEvent, consumed int) {
+	var message []string
+	var timestamp, lastTimestamp time.Time
+	flush := func() {
+		text := strings.TrimRight(strings.Join(message, "\n"), "\n")
+		message = nil
+		if strings.TrimSpace(text) == "" {
+			return
+		}
+		if len(text) > maxEventBytes {
+			text = text[:maxEventBytes]
+		}
+		if timestamp.IsZero() {
+			timestamp = time.Now()
+		}
+		if timestamp.Before(lastTimestamp) {
+			timestamp = lastTimestamp
+		}
+		lastTimestamp = timestamp
+		events = append(events, &cloudwatchlogs.InputLogEvent{
+			Message:   aws.String(text),
+			Timestamp: aws.Int64(timestamp.UnixNano() / int64(time.Millisecond)),
+		})
+	}
+
+	for position := 0; position < len(lines); {
+		lineEnd := bytes.IndexByte(lines[position:], '\n')
+		if lineEnd < 0 {
+			lineEnd = len(lines)
+		} else {
+			lineEnd += position + 1
+		}
+		line := strings.TrimRight(string(lines[position:lineEnd]), "\r\n")
+
+		if match := entryStart.FindString(line); match != "" {
+			flush()
+			consumed = position
+			if len(events) == maxEventsPerBatch {
+				return
+			}
+			timestamp, _ = time.ParseInLocation(entryTimeLayout, match, time.Local)
+		}
+		message = append(message, line)
+		position = lineEnd
+	}
+	flush()
+	consumed = len(lines)
+	return
+}
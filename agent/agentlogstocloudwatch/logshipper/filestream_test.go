@@ -0,0 +1,135 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package logshipper
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	cloudwatchlogspublisher_mock "github.com/aws/amazon-ssm-agent/agent/agentlogstocloudwatch/cloudwatchlogspublisher/mock"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const testLog = "2020-03-01 10:00:00 INFO [ssm-agent-worker] Entering SSM Agent hibernate\n" +
+	"2020-03-01 10:00:01 ERROR [instanceID=i-0123456789abcdef0] Document failed\n" +
+	"panic: runtime error\n" +
+	"goroutine 1 [running]:\n" +
+	"2020-03-01 10:00:02 INFO Document completed\n"
+
+func eventMessages(events []*cloudwatchlogs.InputLogEvent) (messages []string) {
+	for _, event := range events {
+		messages = append(messages, *event.Message)
+	}
+	return
+}
+
+func TestParseEvents(t *testing.T) {
+	events, consumed := parseEvents([]byte(testLog))
+
+	assert.Equal(t, len(testLog), consumed)
+	assert.Equal(t, []string{
+		"2020-03-01 10:00:00 INFO [ssm-agent-worker] Entering SSM Agent hibernate",
+		"2020-03-01 10:00:01 ERROR [instanceID=i-0123456789abcdef0] Document failed\npanic: runtime error\ngoroutine 1 [running]:",
+		"2020-03-01 10:00:02 INFO Document completed",
+	}, eventMessages(events))
+	expected := time.Date(2020, 3, 1, 10, 0, 1, 0, time.Local)
+	assert.Equal(t, expected.UnixNano()/int64(time.Millisecond), *events[1].Timestamp)
+}
+
+func TestParseEventsKeepsChronologicalOrder(t *testing.T) {
+	events, _ := parseEvents([]byte("2020-03-01 10:00:05 INFO first\r\n2020-03-01 10:00:01 INFO second\r\n\r\n"))
+
+	assert.Equal(t, []string{"2020-03-01 10:00:05 INFO first", "2020-03-01 10:00:01 INFO second"}, eventMessages(events))
+	assert.Equal(t, *events[0].Timestamp, *events[1].Timestamp)
+}
+
+func TestParseEventsLimitsBatch(t *testing.T) {
+	entry := "2020-03-01 10:00:00 INFO entry\n"
+	events, consumed := parseEvents([]byte(strings.Repeat(entry, maxEventsPerBatch+5)))
+
+	assert.Equal(t, maxEventsPerBatch, len(events))
+	assert.Equal(t, maxEventsPerBatch*len(entry), consumed)
+}
+
+func TestReadEvents(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "logshipper")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, log.LogFile)
+
+	// a missing file has no events
+	events, next, err := readEvents(path, 0)
+	assert.Nil(t, err)
+	assert.Nil(t, events)
+	assert.Equal(t, int64(0), next)
+
+	// the incomplete last line isn't read
+	ioutil.WriteFile(path, []byte(testLog+"2020-03-01 10:00:03 INFO Docu"), 0600)
+	events, next, err = readEvents(path, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(events))
+	assert.Equal(t, int64(len(testLog)), next)
+
+	events, next, err = readEvents(path, next)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(events))
+	assert.Equal(t, int64(len(testLog)), next)
+
+	// a rolled over file is read from its start
+	ioutil.WriteFile(path, []byte("2020-03-01 10:00:04 INFO Rolled over\n"), 0600)
+	events, next, err = readEvents(path, int64(len(testLog)))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"2020-03-01 10:00:04 INFO Rolled over"}, eventMessages(events))
+	assert.Equal(t, int64(len("2020-03-01 10:00:04 INFO Rolled over\n")), next)
+}
+
+func TestShip(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "logshipper")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, log.ErrorFile)
+	ioutil.WriteFile(path, []byte(testLog), 0600)
+
+	logMock := log.NewMockLog()
+	serviceMock := cloudwatchlogspublisher_mock.NewServiceMockDefault()
+	serviceMock.On("IsLogGroupPresent", logMock, "SSMAgentLogs").Return(false)
+	serviceMock.On("CreateLogGroup", logMock, "SSMAgentLogs").Return(nil)
+	serviceMock.On("IsLogStreamPresent", logMock, "SSMAgentLogs", "i-0123456789abcdef0/errors.log").Return(false)
+	serviceMock.On("CreateLogStream", logMock, "SSMAgentLogs", "i-0123456789abcdef0/errors.log").Return(nil)
+	serviceMock.On("GetSequenceTokenForStream", logMock, "SSMAgentLogs", "i-0123456789abcdef0/errors.log").Return(nil)
+	serviceMock.On("PutLogEvents", logMock, mock.Anything, "SSMAgentLogs", "i-0123456789abcdef0/errors.log", (*string)(nil)).
+		Return(nil, errors.New("ServiceUnavailableException")).Once()
+	serviceMock.On("PutLogEvents", logMock, mock.Anything, "SSMAgentLogs", "i-0123456789abcdef0/errors.log", (*string)(nil)).
+		Return(aws.String("49590302"), nil).Once()
+
+	stream := &fileStream{path: path, logGroup: "SSMAgentLogs", logStream: "i-0123456789abcdef0/errors.log"}
+	// the entries CloudWatch Logs didn't accept are shipped again
+	assert.NotNil(t, stream.ship(logMock, serviceMock))
+	assert.Equal(t, int64(0), stream.offset)
+	assert.Nil(t, stream.ship(logMock, serviceMock))
+	assert.Equal(t, int64(len(testLog)), stream.offset)
+	assert.Equal(t, "49590302", *stream.sequenceToken)
+
+	// nothing is shipped until new entries are logged
+	assert.Nil(t, stream.ship(logMock, serviceMock))
+	serviceMock.AssertNumberOfCalls(t, "PutLogEvents", 2)
+	serviceMock.AssertNumberOfCalls(t, "CreateLogGroup", 1)
+}
@@ -0,0 +1,207 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package logshipper implements the core module streaming the agent log files to CloudWatch Logs.
+//
+// The amazon-ssm-agent.log and errors.log files are shipped to the <instance id>/<file name> log streams of the
+// LogShippingLogGroup group every few seconds, an entry spanning several lines is shipped as one event. The offset
+// of the first byte not shipped yet is kept across restarts, the entries CloudWatch Logs doesn't accept are shipped
+// again with an increasing delay.
+package logshipper
+
+import (
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/agentlogstocloudwatch/cloudwatchlogspublisher"
+	"github.com/aws/amazon-ssm-agent/agent/agentlogstocloudwatch/cloudwatchlogspublisher/cloudwatchlogsinterface"
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/fileutil"
+	"github.com/aws/amazon-ssm-agent/agent/jsonutil"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/platform"
+)
+
+const (
+	// Name is the core module name for the log shipper
+	Name = "LogShipper"
+
+	// stateFileName is the name of the file keeping the offsets of the log files, in the instance folder
+	stateFileName = "logshipper.json"
+	// maxRetryInterval is the longest delay before shipping the entries CloudWatch Logs didn't accept again
+	maxRetryInterval = 5 * time.Minute
+)
+
+// newService, instanceID, logDir, stateDir and shipInterval are assigned to variables to allow unit tests to override them
+var newService = func() cloudwatchlogsinterface.ICloudWatchLogsService {
+	return cloudwatchlogspublisher.NewCloudWatchLogsService()
+}
+var instanceID = platform.InstanceID
+var logDir = log.DefaultLogDir
+var stateDir = appconfig.DefaultDataStorePath
+var shipInterval = 5 * time.Second
+
+// logFiles are the names of the shipped log files in the log folder
+var logFiles = []string{log.LogFile, log.ErrorFile}
+
+// Shipper is the core module streaming the agent log files to CloudWatch Logs
+type Shipper struct {
+	context context.T
+	lock    sync.Mutex
+	stop    chan contracts.StopType
+	stopped chan struct{}
+}
+
+// NewShipper returns the log shipper
+func NewShipper(context context.T) *Shipper {
+	return &Shipper{
+		context: context.With("[" + Name + "]"),
+	}
+}
+
+// ModuleName returns the name of the module
+func (s *Shipper) ModuleName() string {
+	return Name
+}
+
+// ModuleExecute starts shipping the log files if LogShippingLogGroup is set
+func (s *Shipper) ModuleExecute(context context.T) (err error) {
+	log := s.context.Log()
+	logGroup := s.context.AppConfig().Agent.LogShippingLogGroup
+	if logGroup == "" {
+		log.Debug("Log shipper is disabled")
+		return nil
+	}
+	instance, err := instanceID()
+	if err != nil {
+		log.Errorf("Failed to get the instance id, %v", err)
+		return err
+	}
+
+	statePath := filepath.Join(stateDir, instance, stateFileName)
+	offsets := loadOffsets(log, statePath)
+	var streams []*fileStream
+	for _, fileName := range logFiles {
+		path := filepath.Join(logDir, fileName)
+		streams = append(streams, &fileStream{
+			path:      path,
+			logGroup:  logGroup,
+			logStream: instance + "/" + fileName,
+			offset:    offsets[path],
+		})
+	}
+
+	stop := make(chan contracts.StopType, 1)
+	stopped := make(chan struct{})
+	s.lock.Lock()
+	s.stop = stop
+	s.stopped = stopped
+	s.lock.Unlock()
+
+	log.Infof("Shipping the agent logs to the CloudWatch Logs group %v", logGroup)
+	go s.run(streams, newService(), statePath, stop, stopped)
+	return nil
+}
+
+// ModuleRequestStop stops shipping the log files, the entries logged until then are shipped on a soft stop
+func (s *Shipper) ModuleRequestStop(stopType contracts.StopType) (err error) {
+	s.lock.Lock()
+	stop, stopped := s.stop, s.stopped
+	s.stop = nil
+	s.lock.Unlock()
+	if stop == nil {
+		return nil
+	}
+	stop <- stopType
+	<-stopped
+	return nil
+}
+
+// run ships the log files every ship interval until the shipper stops
+func (s *Shipper) run(streams []*fileStream, service cloudwatchlogsinterface.ICloudWatchLogsService, statePath string, stop chan contracts.StopType, stopped chan struct{}) {
+	defer close(stopped)
+	log := s.context.Log()
+	ticker := time.NewTicker(shipInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case stopType := <-stop:
+			if stopType == contracts.StopTypeSoftStop {
+				shipStreams(log, streams, service, time.Time{})
+				saveOffsets(log, statePath, streams)
+			}
+			return
+		case <-ticker.C:
+		}
+		if shipStreams(log, streams, service, time.Now()) {
+			saveOffsets(log, statePath, streams)
+		}
+	}
+}
+
+// shipStreams ships the streams that aren't waiting to be retried at the time, it returns whether an offset moved
+func shipStreams(log log.T, streams []*fileStream, service cloudwatchlogsinterface.ICloudWatchLogsService, now time.Time) (moved bool) {
+	for _, stream := range streams {
+		if now.Before(stream.retryAt) {
+			continue
+		}
+		offset := stream.offset
+		if err := stream.ship(log, service); err != nil {
+			stream.backoff *= 2
+			if stream.backoff == 0 {
+				stream.backoff = shipInterval
+			}
+			if stream.backoff > maxRetryInterval {
+				stream.backoff = maxRetryInterval
+			}
+			stream.retryAt = now.Add(stream.backoff)
+			log.Warnf("Failed to ship %v to CloudWatch Logs, retrying in %v, %v", stream.path, stream.backoff, err)
+		} else {
+			stream.backoff = 0
+		}
+		moved = moved || stream.offset != offset
+	}
+	return
+}
+
+// loadOffsets returns the offsets of the log files saved by a previous run of the agent
+func loadOffsets(log log.T, statePath string) (offsets map[string]int64) {
+	offsets = make(map[string]int64)
+	if !fileutil.Exists(statePath) {
+		return
+	}
+	if err := jsonutil.UnmarshalFile(statePath, &offsets); err != nil {
+		log.Warnf("Failed to load the offsets of the log files, shipping them from their start, %v", err)
+	}
+	return
+}
+
+// saveOffsets saves the offsets of the log files for the next run of the agent
+func saveOffsets(log log.T, statePath string, streams []*fileStream) {
+	offsets := make(map[string]int64)
+	for _, stream := range streams {
+		offsets[stream.path] = stream.offset
+	}
+	content, err := jsonutil.Marshal(offsets)
+	if err == nil {
+		if err = fileutil.MakeDirs(filepath.Dir(statePath)); err == nil {
+			err = fileutil.WriteAllText(statePath, content)
+		}
+	}
+	if err != nil {
+		log.Warnf("Failed to save the offsets of the log files, %v", err)
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package logshipper
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/agentlogstocloudwatch/cloudwatchlogspublisher/cloudwatchlogsinterface"
+	cloudwatchlogspublisher_mock "github.com/aws/amazon-ssm-agent/agent/agentlogstocloudwatch/cloudwatchlogspublisher/mock"
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newContext(logGroup string) *context.Mock {
+	config := appconfig.SsmagentConfig{}
+	config.Agent.LogShippingLogGroup = logGroup
+	mockContext := new(context.Mock)
+	mockContext.On("Log").Return(log.NewMockLog())
+	mockContext.On("AppConfig").Return(config)
+	mockContext.On("With", "["+Name+"]").Return(mockContext)
+	return mockContext
+}
+
+func TestShipperDisabled(t *testing.T) {
+	ctx := newContext("")
+	shipper := NewShipper(ctx)
+	assert.Equal(t, Name, shipper.ModuleName())
+	assert.Nil(t, shipper.ModuleExecute(ctx))
+	assert.Nil(t, shipper.ModuleRequestStop(contracts.StopTypeSoftStop))
+}
+
+func TestShipper(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "logshipper")
+	defer os.RemoveAll(dir)
+	defer func(previousLogDir, previousStateDir string, previousInterval time.Duration, previousInstanceID func() (string, error),
+		previousNewService func() cloudwatchlogsinterface.ICloudWatchLogsService) {
+		logDir, stateDir, shipInterval, instanceID, newService = previousLogDir, previousStateDir, previousInterval, previousInstanceID, previousNewService
+	}(logDir, stateDir, shipInterval, instanceID, newService)
+	logDir, stateDir, shipInterval = dir, dir, time.Hour
+	instanceID = func() (string, error) { return "i-0123456789abcdef0", nil }
+
+	// the entries shipped by a previous run of the agent aren't shipped again
+	shipped := "2020-03-01 10:00:00 INFO Shipped before the restart\n"
+	ioutil.WriteFile(filepath.Join(dir, log.LogFile), []byte(shipped+testLog), 0600)
+	statePath := filepath.Join(dir, "i-0123456789abcdef0", stateFileName)
+	saveOffsets(log.NewMockLog(), statePath, []*fileStream{{path: filepath.Join(dir, log.LogFile), offset: int64(len(shipped))}})
+
+	serviceMock := cloudwatchlogspublisher_mock.NewServiceMockDefault()
+	serviceMock.On("IsLogGroupPresent", mock.Anything, "SSMAgentLogs").Return(true)
+	serviceMock.On("IsLogStreamPresent", mock.Anything, "SSMAgentLogs", mock.Anything).Return(true)
+	serviceMock.On("GetSequenceTokenForStream", mock.Anything, "SSMAgentLogs", mock.Anything).Return(nil)
+	serviceMock.On("PutLogEvents", mock.Anything, mock.Anything, "SSMAgentLogs", "i-0123456789abcdef0/amazon-ssm-agent.log", (*string)(nil)).
+		Return(aws.String("49590302"), nil)
+	newService = func() cloudwatchlogsinterface.ICloudWatchLogsService { return serviceMock }
+
+	ctx := newContext("SSMAgentLogs")
+	shipper := NewShipper(ctx)
+	assert.Nil(t, shipper.ModuleExecute(ctx))
+	// the entries logged until the soft stop are shipped
+	assert.Nil(t, shipper.ModuleRequestStop(contracts.StopTypeSoftStop))
+
+	serviceMock.AssertNumberOfCalls(t, "PutLogEvents", 1)
+	for _, call := range serviceMock.Calls {
+		if call.Method == "PutLogEvents" {
+			assert.Equal(t, 3, len(call.Arguments.Get(1).([]*cloudwatchlogs.InputLogEvent)))
+		}
+	}
+	offsets := loadOffsets(log.NewMockLog(), statePath)
+	assert.Equal(t, int64(len(shipped+testLog)), offsets[filepath.Join(dir, log.LogFile)])
+}
+
+func TestShipStreamsBacksOff(t *testing.T) {
+	serviceMock := cloudwatchlogspublisher_mock.NewServiceMockDefault()
+	serviceMock.On("IsLogGroupPresent", mock.Anything, "SSMAgentLogs").Return(false)
+	serviceMock.On("CreateLogGroup", mock.Anything, "SSMAgentLogs").Return(errors.New("AccessDeniedException"))
+	stream := &fileStream{path: "amazon-ssm-agent.log", logGroup: "SSMAgentLogs", logStream: "i-0123456789abcdef0/amazon-ssm-agent.log"}
+	streams := []*fileStream{stream}
+	now := time.Now()
+
+	assert.False(t, shipStreams(log.NewMockLog(), streams, serviceMock, now))
+	assert.Equal(t, shipInterval, stream.backoff)
+	assert.Equal(t, now.Add(shipInterval), stream.retryAt)
+
+	// the stream isn't shipped again before its retry time
+	shipStreams(log.NewMockLog(), streams, serviceMock, now.Add(time.Second))
+	serviceMock.AssertNumberOfCalls(t, "CreateLogGroup", 1)
+
+	for i := 0; i < 10; i++ {
+		shipStreams(log.NewMockLog(), streams, serviceMock, stream.retryAt)
+	}
+	assert.Equal(t, maxRetryInterval, stream.backoff)
+}
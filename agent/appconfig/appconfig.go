@@ -19,6 +19,8 @@ import (
 	"log"
 	"os"
 	"runtime"
+	"strconv"
+	"strings"
 	"sync"
 
 	"github.com/aws/amazon-ssm-agent/agent/jsonutil"
@@ -36,6 +38,7 @@ func Config(reload bool) (SsmagentConfig, error) {
 	if reload || !isLoaded() {
 		var agentConfig SsmagentConfig
 		agentConfig = DefaultConfig()
+		applyEnvironmentOverrides(&agentConfig)
 		path, pathErr := getAppConfigPath()
 		if pathErr != nil {
 			return agentConfig, nil
@@ -51,11 +54,29 @@ func Config(reload bool) (SsmagentConfig, error) {
 			return agentConfig, err
 		}
 		parser(&agentConfig)
+		applyEnvironmentOverrides(&agentConfig)
 		cache(agentConfig)
 	}
 	return getCached(), nil
 }
 
+// applyEnvironmentOverrides applies the settings set by environment variables, they take precedence over the config file
+func applyEnvironmentOverrides(config *SsmagentConfig) {
+	if value, ok := os.LookupEnv(UseFipsEndpointEnvVar); ok {
+		if useFips, err := strconv.ParseBool(strings.TrimSpace(value)); err == nil {
+			config.Agent.UseFipsEndpoints = useFips
+		}
+	}
+	if value, ok := os.LookupEnv(UseDualStackEndpointEnvVar); ok {
+		if useDualStack, err := strconv.ParseBool(strings.TrimSpace(value)); err == nil {
+			config.Agent.UseDualStackEndpoints = useDualStack
+		}
+	}
+	if value, ok := os.LookupEnv(Ec2MetadataEndpointModeEnvVar); ok {
+		config.Agent.Ec2MetadataEndpointMode = getEc2MetadataEndpointMode(value)
+	}
+}
+
 func isLoaded() bool {
 	lock.RLock()
 	defer lock.RUnlock()
@@ -105,26 +126,59 @@ func DefaultConfig() SsmagentConfig {
 	}
 	var s3 S3Cfg
 	var mds = MdsCfg{
-		CommandWorkersLimit: DefaultCommandWorkersLimit,
-		StopTimeoutMillis:   DefaultStopTimeoutMillis,
-		CommandRetryLimit:   DefaultCommandRetryLimit,
+		CommandWorkersLimit:            DefaultCommandWorkersLimit,
+		StopTimeoutMillis:              DefaultStopTimeoutMillis,
+		CommandRetryLimit:              DefaultCommandRetryLimit,
+		PollBackoffFloorSeconds:        DefaultPollBackoffFloorSeconds,
+		PollBackoffCeilingSeconds:      DefaultPollBackoffCeilingSeconds,
+		StepStatusFlushIntervalSeconds: DefaultStepStatusFlushIntervalSeconds,
+		ReplyRetryMaxAgeMinutes:        DefaultReplyRetryMaxAgeMinutes,
+		ReplyRetryMaxAttempts:          DefaultReplyRetryMaxAttempts,
 	}
 	var mgs = MgsConfig{
 		SessionWorkersLimit: DefaultSessionWorkersLimit,
 		StopTimeoutMillis:   DefaultStopTimeoutMillis,
+		SSHPort:             DefaultSSHPort,
+
+		DataChannelPingIntervalSeconds:     DefaultDataChannelPingIntervalSeconds,
+		DataChannelRetryInitialDelayMillis: DefaultDataChannelRetryInitialDelayMillis,
+		DataChannelRetryMaxIntervalMillis:  DefaultDataChannelRetryMaxIntervalMillis,
 	}
 	var ssm = SsmCfg{
-		HealthFrequencyMinutes:                DefaultSsmHealthFrequencyMinutes,
-		AssociationFrequencyMinutes:           DefaultSsmAssociationFrequencyMinutes,
-		AssociationRetryLimit:                 5,
-		CustomInventoryDefaultLocation:        DefaultCustomInventoryFolder,
-		AssociationLogsRetentionDurationHours: DefaultAssociationLogsRetentionDurationHours,
-		RunCommandLogsRetentionDurationHours:  DefaultRunCommandLogsRetentionDurationHours,
-		SessionLogsRetentionDurationHours:     DefaultSessionLogsRetentionDurationHours,
+		HealthFrequencyMinutes:                     DefaultSsmHealthFrequencyMinutes,
+		AssociationFrequencyMinutes:                DefaultSsmAssociationFrequencyMinutes,
+		AssociationRetryLimit:                      5,
+		AssociationScheduleJitterSeconds:           DefaultAssociationScheduleJitterSeconds,
+		AssociationRetryMaxAttempts:                DefaultAssociationRetryMaxAttempts,
+		AssociationRetryBackoffSeconds:             DefaultAssociationRetryBackoffSeconds,
+		AssociationRetryMaxBackoffSeconds:          DefaultAssociationRetryMaxBackoffSeconds,
+		AssociationHistoryMaxRecords:               DefaultAssociationHistoryMaxRecords,
+		AssociationStatusFlushIntervalSeconds:      DefaultAssociationStatusFlushIntervalSeconds,
+		PluginOutputMaxStdoutBytes:                 DefaultPluginOutputMaxStdoutBytes,
+		PluginOutputMaxStderrBytes:                 DefaultPluginOutputMaxStderrBytes,
+		PluginOutputTruncationStrategy:             OutputTruncationStrategyHead,
+		PluginOutputCloudWatchFlushIntervalSeconds: DefaultPluginOutputCloudWatchFlushIntervalSeconds,
+		OrchestrationRetentionMaxCount:             DefaultOrchestrationRetentionMaxCount,
+		OrchestrationRetentionMaxSizeMB:            DefaultOrchestrationRetentionMaxSizeMB,
+		OrchestrationRetentionSweepMinutes:         DefaultOrchestrationRetentionSweepMinutes,
+		InventoryFullUploadIntervalHours:           DefaultInventoryFullUploadIntervalHours,
+		InventoryOutputMaxFiles:                    DefaultInventoryOutputMaxFiles,
+		CustomInventoryDefaultLocation:             DefaultCustomInventoryFolder,
+		AssociationLogsRetentionDurationHours:      DefaultAssociationLogsRetentionDurationHours,
+		RunCommandLogsRetentionDurationHours:       DefaultRunCommandLogsRetentionDurationHours,
+		SessionLogsRetentionDurationHours:          DefaultSessionLogsRetentionDurationHours,
 	}
 	var agent = AgentInfo{
-		Name:                 "amazon-ssm-agent",
-		OrchestrationRootDir: defaultOrchestrationRootDirName,
+		Name:                        "amazon-ssm-agent",
+		OrchestrationRootDir:        defaultOrchestrationRootDirName,
+		MaxDocumentExecutionSeconds: DefaultMaxDocumentExecutionSeconds,
+		AutoReboot:                  true,
+		Ec2MetadataEndpointMode:     Ec2MetadataEndpointModeIPv4,
+		LogBackend:                  LogBackendFile,
+		SyslogFacility:              DefaultSyslogFacility,
+		LogRotationMaxFileSizeMB:    DefaultLogRotationMaxFileSizeMB,
+		LogRotationMaxFiles:         DefaultLogRotationMaxFiles,
+		LogRotationMaxAgeDays:       DefaultLogRotationMaxAgeDays,
 	}
 	var os = OsInfo{
 		Lang:    "en-US",
@@ -28,6 +28,54 @@ func parser(config *SsmagentConfig) {
 	config.Agent.Name = getStringValue(config.Agent.Name, DefaultAgentName)
 	config.Agent.OrchestrationRootDir = getStringValue(config.Agent.OrchestrationRootDir, defaultOrchestrationRootDirName)
 	config.Agent.Region = getStringValue(config.Agent.Region, "")
+	config.Agent.MaxDocumentExecutionSeconds = getNumericValue(
+		config.Agent.MaxDocumentExecutionSeconds,
+		DefaultMaxDocumentExecutionSecondsMin,
+		DefaultMaxDocumentExecutionSecondsMax,
+		DefaultMaxDocumentExecutionSeconds)
+	config.Agent.MetricsPort = getNumericValue(
+		config.Agent.MetricsPort,
+		DefaultMetricsPortMin,
+		DefaultMetricsPortMax,
+		DefaultMetricsPort)
+	config.Agent.HealthPort = getNumericValue(
+		config.Agent.HealthPort,
+		DefaultHealthPortMin,
+		DefaultHealthPortMax,
+		DefaultHealthPort)
+	config.Agent.WatchdogMaxMemoryMB = getNumericValue(
+		config.Agent.WatchdogMaxMemoryMB,
+		DefaultWatchdogMaxMemoryMBMin,
+		DefaultWatchdogMaxMemoryMBMax,
+		DefaultWatchdogMaxMemoryMB)
+	config.Agent.WatchdogMaxGoroutines = getNumericValue(
+		config.Agent.WatchdogMaxGoroutines,
+		DefaultWatchdogMaxGoroutinesMin,
+		DefaultWatchdogMaxGoroutinesMax,
+		DefaultWatchdogMaxGoroutines)
+	config.Agent.WatchdogMaxOpenFiles = getNumericValue(
+		config.Agent.WatchdogMaxOpenFiles,
+		DefaultWatchdogMaxOpenFilesMin,
+		DefaultWatchdogMaxOpenFilesMax,
+		DefaultWatchdogMaxOpenFiles)
+	config.Agent.LogRotationMaxFileSizeMB = getNumericValue(
+		config.Agent.LogRotationMaxFileSizeMB,
+		DefaultLogRotationMaxFileSizeMBMin,
+		DefaultLogRotationMaxFileSizeMBMax,
+		DefaultLogRotationMaxFileSizeMB)
+	config.Agent.LogRotationMaxFiles = getNumericValue(
+		config.Agent.LogRotationMaxFiles,
+		DefaultLogRotationMaxFilesMin,
+		DefaultLogRotationMaxFilesMax,
+		DefaultLogRotationMaxFiles)
+	config.Agent.LogRotationMaxAgeDays = getNumericValue(
+		config.Agent.LogRotationMaxAgeDays,
+		DefaultLogRotationMaxAgeDaysMin,
+		DefaultLogRotationMaxAgeDaysMax,
+		DefaultLogRotationMaxAgeDays)
+	config.Agent.Ec2MetadataEndpointMode = getEc2MetadataEndpointMode(config.Agent.Ec2MetadataEndpointMode)
+	config.Agent.LogBackend = getLogBackend(config.Agent.LogBackend)
+	config.Agent.SyslogFacility = getStringValue(config.Agent.SyslogFacility, DefaultSyslogFacility)
 
 	// MDS config
 	config.Mds.CommandWorkersLimit = getNumericValue(
@@ -45,8 +93,85 @@ func parser(config *SsmagentConfig) {
 		DefaultStopTimeoutMillisMin,
 		DefaultStopTimeoutMillisMax,
 		DefaultStopTimeoutMillis)
+	config.Mds.PollBackoffFloorSeconds = getNumericValue(
+		config.Mds.PollBackoffFloorSeconds,
+		DefaultPollBackoffFloorSecondsMin,
+		DefaultPollBackoffFloorSecondsMax,
+		DefaultPollBackoffFloorSeconds)
+	config.Mds.PollBackoffCeilingSeconds = getNumericValue(
+		config.Mds.PollBackoffCeilingSeconds,
+		DefaultPollBackoffCeilingSecondsMin,
+		DefaultPollBackoffCeilingSecondsMax,
+		DefaultPollBackoffCeilingSeconds)
+	config.Mds.StepStatusFlushIntervalSeconds = getNumericValue(
+		config.Mds.StepStatusFlushIntervalSeconds,
+		DefaultStepStatusFlushIntervalSecondsMin,
+		DefaultStepStatusFlushIntervalSecondsMax,
+		DefaultStepStatusFlushIntervalSeconds)
+	config.Mds.ReplyRetryMaxAgeMinutes = getNumericValue(
+		config.Mds.ReplyRetryMaxAgeMinutes,
+		DefaultReplyRetryMaxAgeMinutesMin,
+		DefaultReplyRetryMaxAgeMinutesMax,
+		DefaultReplyRetryMaxAgeMinutes)
+	config.Mds.ReplyRetryMaxAttempts = getNumericValue(
+		config.Mds.ReplyRetryMaxAttempts,
+		DefaultReplyRetryMaxAttemptsMin,
+		DefaultReplyRetryMaxAttemptsMax,
+		DefaultReplyRetryMaxAttempts)
 	config.Mds.Endpoint = getStringValue(config.Mds.Endpoint, "")
 
+	// MGS config
+	config.Mgs.MaxConcurrentSessions = getNumericValue(
+		config.Mgs.MaxConcurrentSessions,
+		DefaultMaxConcurrentSessionsMin,
+		DefaultMaxConcurrentSessionsMax,
+		DefaultMaxConcurrentSessions)
+	config.Mgs.MaxConcurrentSessionsPerUser = getNumericValue(
+		config.Mgs.MaxConcurrentSessionsPerUser,
+		DefaultMaxConcurrentSessionsMin,
+		DefaultMaxConcurrentSessionsMax,
+		DefaultMaxConcurrentSessions)
+	config.Mgs.SessionOutgoingBytesPerSecond = getNumericValue(
+		config.Mgs.SessionOutgoingBytesPerSecond,
+		DefaultSessionBytesPerSecondMin,
+		DefaultSessionBytesPerSecondMax,
+		DefaultSessionBytesPerSecond)
+	config.Mgs.SessionIncomingBytesPerSecond = getNumericValue(
+		config.Mgs.SessionIncomingBytesPerSecond,
+		DefaultSessionBytesPerSecondMin,
+		DefaultSessionBytesPerSecondMax,
+		DefaultSessionBytesPerSecond)
+	config.Mgs.DataChannelPingIntervalSeconds = getNumericValue(
+		config.Mgs.DataChannelPingIntervalSeconds,
+		DefaultDataChannelPingIntervalSecondsMin,
+		DefaultDataChannelPingIntervalSecondsMax,
+		DefaultDataChannelPingIntervalSeconds)
+	config.Mgs.DataChannelRetryInitialDelayMillis = getNumericValue(
+		config.Mgs.DataChannelRetryInitialDelayMillis,
+		DefaultDataChannelRetryInitialDelayMillisMin,
+		DefaultDataChannelRetryInitialDelayMillisMax,
+		DefaultDataChannelRetryInitialDelayMillis)
+	config.Mgs.DataChannelRetryMaxIntervalMillis = getNumericValue(
+		config.Mgs.DataChannelRetryMaxIntervalMillis,
+		DefaultDataChannelRetryMaxIntervalMillisMin,
+		DefaultDataChannelRetryMaxIntervalMillisMax,
+		DefaultDataChannelRetryMaxIntervalMillis)
+	config.Mgs.DataChannelReconnectWindowSeconds = getNumericValue(
+		config.Mgs.DataChannelReconnectWindowSeconds,
+		DefaultDataChannelReconnectWindowSecondsMin,
+		DefaultDataChannelReconnectWindowSecondsMax,
+		DefaultDataChannelReconnectWindowSeconds)
+	config.Mgs.IdleSessionTimeoutMinutes = getNumericValue(
+		config.Mgs.IdleSessionTimeoutMinutes,
+		DefaultIdleSessionTimeoutMinutesMin,
+		DefaultIdleSessionTimeoutMinutesMax,
+		DefaultIdleSessionTimeoutMinutes)
+	config.Mgs.SSHPort = getNumericValue(
+		config.Mgs.SSHPort,
+		DefaultSSHPortMin,
+		DefaultSSHPortMax,
+		DefaultSSHPort)
+
 	// SSM config
 	config.Ssm.Endpoint = getStringValue(config.Ssm.Endpoint, "")
 	config.Ssm.HealthFrequencyMinutes = getNumericValue(
@@ -59,6 +184,84 @@ func parser(config *SsmagentConfig) {
 		DefaultSsmAssociationFrequencyMinutesMin,
 		DefaultSsmAssociationFrequencyMinutesMax,
 		DefaultSsmAssociationFrequencyMinutes)
+	config.Ssm.AssociationScheduleJitterSeconds = getNumericValue(
+		config.Ssm.AssociationScheduleJitterSeconds,
+		DefaultAssociationScheduleJitterSecondsMin,
+		DefaultAssociationScheduleJitterSecondsMax,
+		DefaultAssociationScheduleJitterSeconds)
+	config.Ssm.AssociationRetryMaxAttempts = getNumericValue(
+		config.Ssm.AssociationRetryMaxAttempts,
+		DefaultAssociationRetryMaxAttemptsMin,
+		DefaultAssociationRetryMaxAttemptsMax,
+		DefaultAssociationRetryMaxAttempts)
+	config.Ssm.AssociationRetryBackoffSeconds = getNumericValue(
+		config.Ssm.AssociationRetryBackoffSeconds,
+		DefaultAssociationRetryBackoffSecondsMin,
+		DefaultAssociationRetryBackoffSecondsMax,
+		DefaultAssociationRetryBackoffSeconds)
+	config.Ssm.AssociationRetryMaxBackoffSeconds = getNumericValue(
+		config.Ssm.AssociationRetryMaxBackoffSeconds,
+		DefaultAssociationRetryMaxBackoffSecondsMin,
+		DefaultAssociationRetryMaxBackoffSecondsMax,
+		DefaultAssociationRetryMaxBackoffSeconds)
+	config.Ssm.AssociationHistoryMaxRecords = getNumericValue(
+		config.Ssm.AssociationHistoryMaxRecords,
+		DefaultAssociationHistoryMaxRecordsMin,
+		DefaultAssociationHistoryMaxRecordsMax,
+		DefaultAssociationHistoryMaxRecords)
+	config.Ssm.AssociationStatusFlushIntervalSeconds = getNumericValue(
+		config.Ssm.AssociationStatusFlushIntervalSeconds,
+		DefaultAssociationStatusFlushIntervalSecondsMin,
+		DefaultAssociationStatusFlushIntervalSecondsMax,
+		DefaultAssociationStatusFlushIntervalSeconds)
+	config.Ssm.PluginOutputMaxStdoutBytes = getNumericValue(
+		config.Ssm.PluginOutputMaxStdoutBytes,
+		DefaultPluginOutputMaxBytesMin,
+		DefaultPluginOutputMaxBytesMax,
+		DefaultPluginOutputMaxStdoutBytes)
+	config.Ssm.PluginOutputMaxStderrBytes = getNumericValue(
+		config.Ssm.PluginOutputMaxStderrBytes,
+		DefaultPluginOutputMaxBytesMin,
+		DefaultPluginOutputMaxBytesMax,
+		DefaultPluginOutputMaxStderrBytes)
+	config.Ssm.PluginOutputCloudWatchFlushIntervalSeconds = getNumericValue(
+		config.Ssm.PluginOutputCloudWatchFlushIntervalSeconds,
+		DefaultPluginOutputCloudWatchFlushIntervalSecondsMin,
+		DefaultPluginOutputCloudWatchFlushIntervalSecondsMax,
+		DefaultPluginOutputCloudWatchFlushIntervalSeconds)
+	config.Ssm.OrchestrationRetentionMaxCount = getNumericValue(
+		config.Ssm.OrchestrationRetentionMaxCount,
+		DefaultOrchestrationRetentionMaxCountMin,
+		DefaultOrchestrationRetentionMaxCountMax,
+		DefaultOrchestrationRetentionMaxCount)
+	config.Ssm.OrchestrationRetentionMaxSizeMB = getNumericValue(
+		config.Ssm.OrchestrationRetentionMaxSizeMB,
+		DefaultOrchestrationRetentionMaxSizeMBMin,
+		DefaultOrchestrationRetentionMaxSizeMBMax,
+		DefaultOrchestrationRetentionMaxSizeMB)
+	config.Ssm.OrchestrationRetentionSweepMinutes = getNumericValue(
+		config.Ssm.OrchestrationRetentionSweepMinutes,
+		DefaultOrchestrationRetentionSweepMinutesMin,
+		DefaultOrchestrationRetentionSweepMinutesMax,
+		DefaultOrchestrationRetentionSweepMinutes)
+	config.Ssm.InventoryFullUploadIntervalHours = getNumericValue(
+		config.Ssm.InventoryFullUploadIntervalHours,
+		DefaultInventoryFullUploadIntervalHoursMin,
+		DefaultInventoryFullUploadIntervalHoursMax,
+		DefaultInventoryFullUploadIntervalHours)
+	config.Ssm.InventoryOutputMaxFiles = getNumericValue(
+		config.Ssm.InventoryOutputMaxFiles,
+		DefaultInventoryOutputMaxFilesMin,
+		DefaultInventoryOutputMaxFilesMax,
+		DefaultInventoryOutputMaxFiles)
+	config.Ssm.PluginOutputTruncationStrategy = strings.ToLower(config.Ssm.PluginOutputTruncationStrategy)
+	if config.Ssm.PluginOutputTruncationStrategy != OutputTruncationStrategyTail {
+		config.Ssm.PluginOutputTruncationStrategy = OutputTruncationStrategyHead
+	}
+	config.Ssm.PluginResultSink = strings.ToLower(strings.TrimSpace(config.Ssm.PluginResultSink))
+	if config.Ssm.PluginResultSink != PluginResultSinkFirehose {
+		config.Ssm.PluginResultSink = ""
+	}
 	config.Ssm.AssociationLogsRetentionDurationHours = getNumericValueAboveMin(
 		config.Ssm.AssociationLogsRetentionDurationHours,
 		DefaultStateOrchestrationLogsRetentionDurationHoursMin,
@@ -70,10 +273,36 @@ func parser(config *SsmagentConfig) {
 
 }
 
+// fipsRegions are the regions where the services the agent calls have FIPS endpoints
+var fipsRegions = map[string]bool{
+	"us-east-1":     true,
+	"us-east-2":     true,
+	"us-west-1":     true,
+	"us-west-2":     true,
+	"us-gov-east-1": true,
+	"us-gov-west-1": true,
+	"ca-central-1":  true,
+}
+
+// useFipsEndpoints and useDualStackEndpoints are assigned to variables to allow unit tests to override them
+var useFipsEndpoints = func() bool {
+	config, _ := Config(false)
+	return config.Agent.UseFipsEndpoints
+}
+
+var useDualStackEndpoints = func() bool {
+	config, _ := Config(false)
+	return config.Agent.UseDualStackEndpoints
+}
+
 // TODO https://sim.amazon.com/issues/SSM-3439
 // getDefaultEndPoint returns the default endpoint for a service, it should be empty unless it's a china region
+// or the agent uses the dual-stack or FIPS endpoints
 func GetDefaultEndPoint(region string, service string) string {
-	endpoint := ""
+	endpoint := GetPreferredEndPoint(region, service)
+	if endpoint != "" {
+		return endpoint
+	}
 
 	parts := strings.Split(region, "-")
 	if len(parts) > 1 && parts[0] == "cn" {
@@ -83,6 +312,56 @@ func GetDefaultEndPoint(region string, service string) string {
 	return endpoint
 }
 
+// GetPreferredEndPoint returns the dual-stack or FIPS endpoint for a service when the agent is configured to use them,
+// it is empty when the agent uses the regular endpoints
+func GetPreferredEndPoint(region string, service string) string {
+	if useDualStackEndpoints() {
+		return GetDualStackEndPoint(region, service, useFipsEndpoints())
+	}
+	if useFipsEndpoints() {
+		return GetFipsEndPoint(region, service)
+	}
+	return ""
+}
+
+// GetDualStackEndPoint returns the dual-stack endpoint for a service, the FIPS one when fips is set and the region has one
+func GetDualStackEndPoint(region string, service string, fips bool) string {
+	if region == "" {
+		return ""
+	}
+	name := service
+	if fips && fipsRegions[region] {
+		name = service + "-fips"
+	}
+	china := strings.HasPrefix(region, "cn-")
+	if service == "s3" {
+		if china {
+			return name + ".dualstack." + region + ".amazonaws.com.cn"
+		}
+		return name + ".dualstack." + region + ".amazonaws.com"
+	}
+	if china {
+		return name + "." + region + ".api.amazonwebservices.com.cn"
+	}
+	return name + "." + region + ".api.aws"
+}
+
+// GetFipsEndPoint returns the FIPS endpoint for a service, it is empty if the region has no FIPS endpoints
+func GetFipsEndPoint(region string, service string) string {
+	if !fipsRegions[region] {
+		return ""
+	}
+	return service + "-fips." + region + ".amazonaws.com"
+}
+
+// getEc2MetadataEndpointMode returns the endpoint mode matching the value regardless of case, IPv4 for any other value
+func getEc2MetadataEndpointMode(value string) string {
+	if strings.EqualFold(strings.TrimSpace(value), Ec2MetadataEndpointModeIPv6) {
+		return Ec2MetadataEndpointModeIPv6
+	}
+	return Ec2MetadataEndpointModeIPv4
+}
+
 // getStringValue returns the default value if config is empty, else the config value
 func getStringValue(configValue string, defaultValue string) string {
 	if configValue == "" {
@@ -114,3 +393,13 @@ func getNumeric64Value(configValue int64, minValue int64, maxValue int64, defaul
 	}
 	return configValue
 }
+
+// getLogBackend returns the log backend matching the value regardless of case, file for any other value
+func getLogBackend(value string) string {
+	for _, backend := range []string{LogBackendSyslog, LogBackendJournald} {
+		if strings.EqualFold(strings.TrimSpace(value), backend) {
+			return backend
+		}
+	}
+	return LogBackendFile
+}
@@ -14,6 +14,7 @@
 package appconfig
 
 import (
+	"os"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -65,6 +66,76 @@ func TestGetDefaultEndPoint(t *testing.T) {
 	}
 }
 
+func TestGetDefaultEndPointWithFips(t *testing.T) {
+	original := useFipsEndpoints
+	useFipsEndpoints = func() bool { return true }
+	defer func() { useFipsEndpoints = original }()
+
+	assert.Equal(t, "ssm-fips.us-east-1.amazonaws.com", GetDefaultEndPoint("us-east-1", "ssm"))
+	assert.Equal(t, "ec2messages-fips.us-gov-west-1.amazonaws.com", GetDefaultEndPoint("us-gov-west-1", "ec2messages"))
+	// regions without FIPS endpoints keep their default endpoint
+	assert.Equal(t, "", GetDefaultEndPoint("eu-west-1", "ssm"))
+	assert.Equal(t, "ssm.cn-north-1.amazonaws.com.cn", GetDefaultEndPoint("cn-north-1", "ssm"))
+}
+
+func TestGetDefaultEndPointWithDualStack(t *testing.T) {
+	originalFips, originalDualStack := useFipsEndpoints, useDualStackEndpoints
+	useDualStackEndpoints = func() bool { return true }
+	useFipsEndpoints = func() bool { return false }
+	defer func() { useFipsEndpoints, useDualStackEndpoints = originalFips, originalDualStack }()
+
+	assert.Equal(t, "ssm.eu-west-1.api.aws", GetDefaultEndPoint("eu-west-1", "ssm"))
+	assert.Equal(t, "s3.dualstack.eu-west-1.amazonaws.com", GetDefaultEndPoint("eu-west-1", "s3"))
+	assert.Equal(t, "ssm.cn-north-1.api.amazonwebservices.com.cn", GetDefaultEndPoint("cn-north-1", "ssm"))
+	assert.Equal(t, "s3.dualstack.cn-north-1.amazonaws.com.cn", GetDefaultEndPoint("cn-north-1", "s3"))
+
+	useFipsEndpoints = func() bool { return true }
+	assert.Equal(t, "ssm-fips.us-east-1.api.aws", GetDefaultEndPoint("us-east-1", "ssm"))
+	assert.Equal(t, "s3-fips.dualstack.us-east-1.amazonaws.com", GetDefaultEndPoint("us-east-1", "s3"))
+	// regions without FIPS endpoints use the regular dual-stack endpoints
+	assert.Equal(t, "ssm.eu-west-1.api.aws", GetDefaultEndPoint("eu-west-1", "ssm"))
+}
+
+func TestGetEc2MetadataEndpointMode(t *testing.T) {
+	assert.Equal(t, Ec2MetadataEndpointModeIPv6, getEc2MetadataEndpointMode("ipv6"))
+	assert.Equal(t, Ec2MetadataEndpointModeIPv6, getEc2MetadataEndpointMode(" IPv6 "))
+	assert.Equal(t, Ec2MetadataEndpointModeIPv4, getEc2MetadataEndpointMode(""))
+	assert.Equal(t, Ec2MetadataEndpointModeIPv4, getEc2MetadataEndpointMode("other"))
+}
+
+func TestGetLogBackend(t *testing.T) {
+	assert.Equal(t, LogBackendSyslog, getLogBackend("Syslog"))
+	assert.Equal(t, LogBackendJournald, getLogBackend(" journald "))
+	assert.Equal(t, LogBackendFile, getLogBackend(""))
+	assert.Equal(t, LogBackendFile, getLogBackend("other"))
+}
+
+func TestApplyEnvironmentOverrides(t *testing.T) {
+	defer os.Unsetenv(UseFipsEndpointEnvVar)
+
+	config := DefaultConfig()
+	os.Setenv(UseFipsEndpointEnvVar, "true")
+	applyEnvironmentOverrides(&config)
+	assert.True(t, config.Agent.UseFipsEndpoints)
+
+	os.Setenv(UseFipsEndpointEnvVar, "false")
+	applyEnvironmentOverrides(&config)
+	assert.False(t, config.Agent.UseFipsEndpoints)
+
+	config.Agent.UseFipsEndpoints = true
+	os.Setenv(UseFipsEndpointEnvVar, "not a boolean")
+	applyEnvironmentOverrides(&config)
+	assert.True(t, config.Agent.UseFipsEndpoints)
+
+	defer os.Unsetenv(UseDualStackEndpointEnvVar)
+	defer os.Unsetenv(Ec2MetadataEndpointModeEnvVar)
+	os.Setenv(UseDualStackEndpointEnvVar, "true")
+	os.Setenv(Ec2MetadataEndpointModeEnvVar, "ipv6")
+	applyEnvironmentOverrides(&config)
+	assert.True(t, config.Agent.UseDualStackEndpoints)
+	assert.Equal(t, Ec2MetadataEndpointModeIPv6, config.Agent.Ec2MetadataEndpointMode)
+}
+
 // getNumericValue Tests
 
 type GetNumericValueTest struct {
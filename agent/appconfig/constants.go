@@ -31,6 +31,28 @@ const (
 	DefaultStopTimeoutMillisMin = 10000
 	DefaultStopTimeoutMillisMax = 1000000
 
+	// the delay between polls without messages doubles from the floor up to the ceiling
+	DefaultPollBackoffFloorSeconds    = 5
+	DefaultPollBackoffFloorSecondsMin = 1
+	DefaultPollBackoffFloorSecondsMax = 900
+
+	DefaultPollBackoffCeilingSeconds    = 0
+	DefaultPollBackoffCeilingSecondsMin = 0
+	DefaultPollBackoffCeilingSecondsMax = 900
+
+	DefaultStepStatusFlushIntervalSeconds    = 5
+	DefaultStepStatusFlushIntervalSecondsMin = 0
+	DefaultStepStatusFlushIntervalSecondsMax = 300
+
+	// MDS marks the commands without reply for 2 hours as timed out
+	DefaultReplyRetryMaxAgeMinutes    = 120
+	DefaultReplyRetryMaxAgeMinutesMin = 1
+	DefaultReplyRetryMaxAgeMinutesMax = 1440
+
+	DefaultReplyRetryMaxAttempts    = 0
+	DefaultReplyRetryMaxAttemptsMin = 0
+	DefaultReplyRetryMaxAttemptsMax = 1000
+
 	// SSM defaults
 	DefaultSsmHealthFrequencyMinutes    = 5
 	DefaultSsmHealthFrequencyMinutesMin = 5
@@ -40,6 +62,103 @@ const (
 	DefaultSsmAssociationFrequencyMinutesMin = 5
 	DefaultSsmAssociationFrequencyMinutesMax = 60
 
+	DefaultAssociationScheduleJitterSeconds    = 0
+	DefaultAssociationScheduleJitterSecondsMin = 0
+	DefaultAssociationScheduleJitterSecondsMax = 3600
+
+	DefaultAssociationRetryMaxAttempts    = 0
+	DefaultAssociationRetryMaxAttemptsMin = 0
+	DefaultAssociationRetryMaxAttemptsMax = 10
+
+	DefaultAssociationRetryBackoffSeconds    = 30
+	DefaultAssociationRetryBackoffSecondsMin = 1
+	DefaultAssociationRetryBackoffSecondsMax = 3600
+
+	DefaultAssociationRetryMaxBackoffSeconds    = 900
+	DefaultAssociationRetryMaxBackoffSecondsMin = 1
+	DefaultAssociationRetryMaxBackoffSecondsMax = 86400
+
+	DefaultAssociationHistoryMaxRecords    = 100
+	DefaultAssociationHistoryMaxRecordsMin = 0
+	DefaultAssociationHistoryMaxRecordsMax = 1000
+
+	DefaultAssociationStatusFlushIntervalSeconds    = 5
+	DefaultAssociationStatusFlushIntervalSecondsMin = 0
+	DefaultAssociationStatusFlushIntervalSecondsMax = 300
+
+	DefaultPluginOutputMaxStdoutBytes = MaxStdoutLength
+	DefaultPluginOutputMaxStderrBytes = MaxStderrLength
+	DefaultPluginOutputMaxBytesMin    = 1
+	DefaultPluginOutputMaxBytesMax    = 1048576
+
+	DefaultPluginOutputCloudWatchFlushIntervalSeconds    = 3
+	DefaultPluginOutputCloudWatchFlushIntervalSecondsMin = 1
+	DefaultPluginOutputCloudWatchFlushIntervalSecondsMax = 60
+
+	DefaultMaxDocumentExecutionSeconds    = 0
+	DefaultMaxDocumentExecutionSecondsMin = 0
+	DefaultMaxDocumentExecutionSecondsMax = 172800
+
+	// Port of the localhost Prometheus metrics endpoint, 0 disables the endpoint
+	DefaultMetricsPort    = 0
+	DefaultMetricsPortMin = 0
+	DefaultMetricsPortMax = 65535
+
+	// Port of the localhost health endpoint, 0 disables the endpoint
+	DefaultHealthPort    = 0
+	DefaultHealthPortMin = 0
+	DefaultHealthPortMax = 65535
+
+	// Resource thresholds of the agent watchdog, 0 doesn't watch the resource
+	DefaultWatchdogMaxMemoryMB      = 0
+	DefaultWatchdogMaxMemoryMBMin   = 0
+	DefaultWatchdogMaxMemoryMBMax   = 1048576
+	DefaultWatchdogMaxGoroutines    = 0
+	DefaultWatchdogMaxGoroutinesMin = 0
+	DefaultWatchdogMaxGoroutinesMax = 10000000
+	DefaultWatchdogMaxOpenFiles     = 0
+	DefaultWatchdogMaxOpenFilesMin  = 0
+	DefaultWatchdogMaxOpenFilesMax  = 10000000
+
+	// Rotation of the agent log files, a max age of 0 keeps the rolled files regardless of age
+	DefaultLogRotationMaxFileSizeMB    = 30
+	DefaultLogRotationMaxFileSizeMBMin = 1
+	DefaultLogRotationMaxFileSizeMBMax = 10240
+	DefaultLogRotationMaxFiles         = 5
+	DefaultLogRotationMaxFilesMin      = 1
+	DefaultLogRotationMaxFilesMax      = 1000
+	DefaultLogRotationMaxAgeDays       = 0
+	DefaultLogRotationMaxAgeDaysMin    = 0
+	DefaultLogRotationMaxAgeDaysMax    = 3650
+
+	DefaultOrchestrationRetentionMaxCount    = 0
+	DefaultOrchestrationRetentionMaxCountMin = 0
+	DefaultOrchestrationRetentionMaxCountMax = 100000
+
+	DefaultOrchestrationRetentionMaxSizeMB    = 0
+	DefaultOrchestrationRetentionMaxSizeMBMin = 0
+	DefaultOrchestrationRetentionMaxSizeMBMax = 1048576
+
+	DefaultOrchestrationRetentionSweepMinutes    = 60
+	DefaultOrchestrationRetentionSweepMinutesMin = 5
+	DefaultOrchestrationRetentionSweepMinutesMax = 1440
+
+	DefaultInventoryFullUploadIntervalHours    = 24
+	DefaultInventoryFullUploadIntervalHoursMin = 0
+	DefaultInventoryFullUploadIntervalHoursMax = 720
+
+	DefaultInventoryOutputMaxFiles    = 100
+	DefaultInventoryOutputMaxFilesMin = 0
+	DefaultInventoryOutputMaxFilesMax = 100000
+
+	// OutputTruncationStrategyHead keeps the beginning of truncated plugin output
+	OutputTruncationStrategyHead = "head"
+	// OutputTruncationStrategyTail keeps the end of truncated plugin output
+	OutputTruncationStrategyTail = "tail"
+
+	// PluginResultSinkFirehose publishes plugin results to a Kinesis Data Firehose delivery stream
+	PluginResultSinkFirehose = "firehose"
+
 	//aws-ssm-agent bookkeeping constants
 	DefaultLocationOfPending     = "pending"
 	DefaultLocationOfCurrent     = "current"
@@ -67,10 +186,51 @@ const (
 	FileInventoryRootDirName     = "file"
 	RoleInventoryRootDirName     = "role"
 	InventoryContentHashFileName = "contentHash"
+	InventoryFullUploadFileName  = "fullUpload"
+	InventoryCollectionFileName  = "lastCollection"
+
+	// UseFipsEndpointEnvVar is the environment variable overriding the UseFipsEndpoints setting
+	UseFipsEndpointEnvVar = "AWS_USE_FIPS_ENDPOINT"
+
+	// UseDualStackEndpointEnvVar is the environment variable overriding the UseDualStackEndpoints setting
+	UseDualStackEndpointEnvVar = "AWS_USE_DUALSTACK_ENDPOINT"
+
+	// Ec2MetadataEndpointModeEnvVar is the environment variable overriding the Ec2MetadataEndpointMode setting
+	Ec2MetadataEndpointModeEnvVar = "AWS_EC2_METADATA_SERVICE_ENDPOINT_MODE"
+
+	// Ec2MetadataEndpointModeIPv4 reaches the instance metadata over IPv4
+	Ec2MetadataEndpointModeIPv4 = "IPv4"
+
+	// Ec2MetadataEndpointModeIPv6 reaches the instance metadata over IPv6
+	Ec2MetadataEndpointModeIPv6 = "IPv6"
+
+	// LogBackendFile writes the agent logs to the files configured in seelog.xml
+	LogBackendFile = "file"
+
+	// LogBackendSyslog writes the agent logs to the local syslog daemon
+	LogBackendSyslog = "syslog"
+
+	// LogBackendJournald writes the agent logs to journald with structured fields
+	LogBackendJournald = "journald"
+
+	// DefaultSyslogFacility is the syslog facility of the agent logs
+	DefaultSyslogFacility = "daemon"
 
 	//aws-ssm-agent bookkeeping constants for failed sent replies
 	RepliesRootDirName = "replies"
 
+	//aws-ssm-agent bookkeeping constants for the replies abandoned after their retries
+	AbandonedRepliesRootDirName = "abandonedreplies"
+
+	//aws-ssm-agent bookkeeping constants for requests waiting to be sent to the service
+	OutboundQueueRootDirName = "outbound"
+
+	//aws-ssm-agent bookkeeping constants for the messages already processed
+	ProcessedMessagesRootDirName = "processedmessages"
+
+	//aws-ssm-agent bookkeeping constants for the messages which failed to parse
+	DeadLetterRootDirName = "deadletter"
+
 	//aws-ssm-agent bookkeeping constants for compliance
 	ComplianceRootDirName         = "compliance"
 	ComplianceContentHashFileName = "contentHash"
@@ -81,6 +241,12 @@ const (
 	// DefaultSessionRootDirName is the root directory for storing session manager data
 	DefaultSessionRootDirName = "session"
 
+	// SessionRecordingsRootDirName is the directory under the session root directory spooling the session recordings
+	SessionRecordingsRootDirName = "recordings"
+
+	// SessionAuditRootDirName is the directory under the session root directory keeping the audit of the restricted sessions
+	SessionAuditRootDirName = "audit"
+
 	// Orchestration Root Dir
 	defaultOrchestrationRootDirName = "orchestration"
 
@@ -156,6 +322,30 @@ const (
 	// PluginNameAwsApplications is the name of the Applications plugin
 	PluginNameAwsApplications = "aws:applications"
 
+	// PluginNameAwsRunChefRecipe is the name of the run chef recipe plugin
+	PluginNameAwsRunChefRecipe = "aws:runChefRecipe"
+
+	// PluginNameAwsRunSaltState is the name of the run salt state plugin
+	PluginNameAwsRunSaltState = "aws:runSaltState"
+
+	// PluginNameAwsCopyFile is the name of the copy file plugin
+	PluginNameAwsCopyFile = "aws:copyFile"
+
+	// PluginNameAwsRunPythonScript is the name of the run python script plugin
+	PluginNameAwsRunPythonScript = "aws:runPythonScript"
+
+	// PluginNameAwsExtractArchive is the name of the extract archive plugin
+	PluginNameAwsExtractArchive = "aws:extractArchive"
+
+	// PluginNameAwsServiceControl is the name of the service control plugin
+	PluginNameAwsServiceControl = "aws:serviceControl"
+
+	// PluginNameAwsEditRegistry is the name of the edit registry plugin
+	PluginNameAwsEditRegistry = "aws:editRegistry"
+
+	// PluginNameAwsManageFilesystem is the name of the manage filesystem plugin
+	PluginNameAwsManageFilesystem = "aws:manageFilesystem"
+
 	AppConfigFileName    = "amazon-ssm-agent.json"
 	SeelogConfigFileName = "seelog.xml"
 
@@ -170,6 +360,53 @@ const (
 	// PluginNameStandardStream is the name for session manager standard stream plugin aka shell.
 	PluginNameStandardStream = "Standard_Stream"
 
+	// PluginNamePort is the name for session manager port plugin, forwarding a port over the session.
+	PluginNamePort = "Port"
+
+	// PluginNameSSH is the name for session manager ssh plugin, tunneling the session to the local sshd.
+	PluginNameSSH = "SSH"
+
+	// PluginNameInteractiveCommands is the name for session manager plugin running a single command in a pseudo terminal.
+	PluginNameInteractiveCommands = "InteractiveCommands"
+
+	// Limits of the concurrent sessions, 0 doesn't limit the sessions
+	DefaultMaxConcurrentSessions    = 0
+	DefaultMaxConcurrentSessionsMin = 0
+	DefaultMaxConcurrentSessionsMax = DefaultSessionWorkersLimit
+
+	// Throughput limits of the sessions in bytes per second, 0 doesn't limit the throughput
+	DefaultSessionBytesPerSecond    = 0
+	DefaultSessionBytesPerSecondMin = 0
+	DefaultSessionBytesPerSecondMax = 1 << 30
+
+	// Keep-alive of the session data channels
+	DefaultDataChannelPingIntervalSeconds    = 300
+	DefaultDataChannelPingIntervalSecondsMin = 10
+	DefaultDataChannelPingIntervalSecondsMax = 3600
+
+	// Backoff between the reconnection attempts of the session data channels
+	DefaultDataChannelRetryInitialDelayMillis    = 100
+	DefaultDataChannelRetryInitialDelayMillisMin = 10
+	DefaultDataChannelRetryInitialDelayMillisMax = 60000
+	DefaultDataChannelRetryMaxIntervalMillis     = 5000
+	DefaultDataChannelRetryMaxIntervalMillisMin  = 100
+	DefaultDataChannelRetryMaxIntervalMillisMax  = 300000
+
+	// Reconnection window of the session data channels, 0 gives up after a few attempts
+	DefaultDataChannelReconnectWindowSeconds    = 0
+	DefaultDataChannelReconnectWindowSecondsMin = 0
+	DefaultDataChannelReconnectWindowSecondsMax = 86400
+
+	// Idle timeout of the interactive sessions, 0 never terminates the idle sessions
+	DefaultIdleSessionTimeoutMinutes    = 0
+	DefaultIdleSessionTimeoutMinutesMin = 0
+	DefaultIdleSessionTimeoutMinutesMax = 1440
+
+	// Port of the local sshd the ssh sessions connect to
+	DefaultSSHPort    = 22
+	DefaultSSHPortMin = 1
+	DefaultSSHPortMax = 65535
+
 	// Session default RunAs user name
 	DefaultRunAsUserName = "ssm-user"
 )
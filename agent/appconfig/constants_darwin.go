@@ -47,6 +47,9 @@ const (
 	// are moved if the service cannot validate the document (generally impossible via cli)
 	LocalCommandRootInvalid = DefaultProgramFolder + "localcommands/invalid"
 
+	// LocalIpcAddress is the unix socket local tooling submits documents to the agent through
+	LocalIpcAddress = DefaultProgramFolder + "ipc/agent.sock"
+
 	// DownloadRoot specifies the directory under which files will be downloaded
 	DownloadRoot = DefaultProgramFolder + "download/"
 
@@ -80,11 +83,11 @@ const (
 	DefaultSessionWorker  = DefaultProgramFolder + "bin/ssm-session-worker"
 	DefaultSessionLogger  = DefaultProgramFolder + "bin/ssm-session-logger"
 
-	// PowerShellPluginCommandName is the path of the powershell.exe to be used by the runPowerShellScript plugin
-	PowerShellPluginCommandName = "/usr/bin/powershell"
+	// PowerShellPluginCommandName is the path of the pwsh to be used by the runPowerShellScript plugin
+	PowerShellPluginCommandName = "/usr/local/bin/pwsh"
 
-	// PowerShellPluginCommandArgs is the arguments of powershell.exe to be used by the runPowerShellScript plugin
-	PowerShellPluginCommandArgs = ""
+	// PowerShellPluginCommandArgs is the arguments of pwsh to be used by the runPowerShellScript plugin
+	PowerShellPluginCommandArgs = "-NoLogo -NonInteractive -NoProfile -File"
 
 	// Exit Code for a command that exits before completion (generally due to timeout or cancel)
 	CommandStoppedPreemptivelyExitCode = 137 // Fatal error (128) + signal for SIGKILL (9) = 137
@@ -92,3 +95,10 @@ const (
 	// RunCommandScriptName is the script name where all downloaded or provided commands will be stored
 	RunCommandScriptName = "_script.sh"
 )
+
+// PowerShellPluginCommandPaths are the paths PowerShell is installed to, the first one that exists is used
+var PowerShellPluginCommandPaths = []string{
+	"/usr/local/bin/pwsh",
+	"/opt/homebrew/bin/pwsh",
+	"/usr/local/microsoft/powershell/7/pwsh",
+}
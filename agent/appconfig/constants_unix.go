@@ -49,6 +49,9 @@ const (
 	// are moved if the service cannot validate the document (generally impossible via cli)
 	LocalCommandRootInvalid = "/var/lib/amazon/ssm/localcommands/invalid"
 
+	// LocalIpcAddress is the unix socket local tooling submits documents to the agent through
+	LocalIpcAddress = "/var/lib/amazon/ssm/ipc/agent.sock"
+
 	// DownloadRoot specifies the directory under which files will be downloaded
 	DownloadRoot = "/var/log/amazon/ssm/download/"
 
@@ -78,8 +81,8 @@ const (
 	// Default Custom Inventory Inventory Folder
 	DefaultCustomInventoryFolder = DefaultDataStorePath + "inventory/custom"
 
-	// PowerShellPluginCommandArgs is the arguments of powershell.exe to be used by the runPowerShellScript plugin
-	PowerShellPluginCommandArgs = ""
+	// PowerShellPluginCommandArgs is the arguments of pwsh to be used by the runPowerShellScript plugin
+	PowerShellPluginCommandArgs = "-NoLogo -NonInteractive -NoProfile -File"
 
 	// Exit Code for a command that exits before completion (generally due to timeout or cancel)
 	CommandStoppedPreemptivelyExitCode = 137 // Fatal error (128) + signal for SIGKILL (9) = 137
@@ -94,6 +97,16 @@ const (
 // PowerShellPluginCommandName is the path of the powershell.exe to be used by the runPowerShellScript plugin
 var PowerShellPluginCommandName string
 
+// PowerShellPluginCommandPaths are the paths PowerShell is installed to, the first one that exists is used
+var PowerShellPluginCommandPaths = []string{
+	"/usr/bin/pwsh",
+	"/usr/local/bin/pwsh",
+	"/opt/microsoft/powershell/7/pwsh",
+	"/snap/bin/pwsh",
+	// the alpha versions of PowerShell installed powershell instead of pwsh
+	"/usr/bin/powershell",
+}
+
 // DefaultProgramFolder is the default folder for SSM
 var DefaultProgramFolder = "/etc/amazon/ssm/"
 var DefaultDocumentWorker = "/home/core/ssm-document-worker"
@@ -104,12 +117,12 @@ var DefaultSessionLogger = "/home/core/ssm-session-logger"
 var AppConfigPath = DefaultProgramFolder + AppConfigFileName
 
 func init() {
-	/*
-	   Powershell command used to be poweshell in alpha versions, now it's pwsh in prod versions
-	*/
-	PowerShellPluginCommandName = "/usr/bin/powershell"
-	if _, err := os.Stat(PowerShellPluginCommandName); err != nil {
-		PowerShellPluginCommandName = "/usr/bin/pwsh"
+	PowerShellPluginCommandName = PowerShellPluginCommandPaths[0]
+	for _, path := range PowerShellPluginCommandPaths {
+		if _, err := os.Stat(path); err == nil {
+			PowerShellPluginCommandName = path
+			break
+		}
 	}
 
 	// Find current directory path for amazon-ssm-agent, DefaultDocumentWorker should exist in same directory
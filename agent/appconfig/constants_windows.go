@@ -65,6 +65,9 @@ const (
 //PowerShellPluginCommandName is the path of the powershell.exe to be used by the runPowerShellScript plugin
 var PowerShellPluginCommandName = filepath.Join(os.Getenv("SystemRoot"), "System32", "WindowsPowerShell", "v1.0", "powershell.exe")
 
+// PowerShellPluginCommandPaths are the paths PowerShell is installed to, windows always uses the built-in powershell.exe
+var PowerShellPluginCommandPaths = []string{PowerShellPluginCommandName}
+
 // Program Folder
 var DefaultProgramFolder string
 
@@ -104,6 +107,9 @@ var LocalCommandRootCompleted string
 // are moved if the service cannot validate the document (generally impossible via cli)
 var LocalCommandRootInvalid string
 
+// LocalIpcAddress is the named pipe local tooling submits documents to the agent through
+const LocalIpcAddress = `\\.\pipe\amazon-ssm-agent`
+
 // DefaultPluginPath represents the directory for storing plugins in SSM
 var DefaultPluginPath string
 
@@ -28,6 +28,19 @@ type MdsCfg struct {
 	CommandWorkersLimit int
 	StopTimeoutMillis   int64
 	CommandRetryLimit   int
+	// PollBackoffFloorSeconds is the delay before polling again after a poll without messages
+	PollBackoffFloorSeconds int
+	// PollBackoffCeilingSeconds is the maximum delay between polls while no message arrives, 0 polls continuously
+	PollBackoffCeilingSeconds int
+	// CommandDeliveryOverMgs receives run command documents over the Message Gateway Service websocket as they are sent,
+	// polling MDS remains the fallback for the documents the websocket doesn't deliver
+	CommandDeliveryOverMgs bool
+	// StepStatusFlushIntervalSeconds is the interval at which the progress replies of commands are batched, 0 sends every reply
+	StepStatusFlushIntervalSeconds int
+	// ReplyRetryMaxAgeMinutes is how long a reply which failed to reach the service is retried before it is abandoned
+	ReplyRetryMaxAgeMinutes int
+	// ReplyRetryMaxAttempts is the number of times a reply is retried before it is abandoned, 0 retries it until it is too old
+	ReplyRetryMaxAttempts int
 }
 
 // SsmCfg represents configuration for Simple system manager (SSM)
@@ -36,6 +49,80 @@ type SsmCfg struct {
 	HealthFrequencyMinutes      int
 	AssociationFrequencyMinutes int
 	AssociationRetryLimit       int
+	// AssociationScheduleJitterSeconds is the window each instance spreads its scheduled association runs over
+	AssociationScheduleJitterSeconds int
+	// AssociationRetryMaxAttempts is the number of times a failed association is retried before it is reported Failed
+	AssociationRetryMaxAttempts       int
+	AssociationRetryBackoffSeconds    int
+	AssociationRetryMaxBackoffSeconds int
+	// AssociationRetryOnExitCodes restricts retries to failures with the given plugin exit codes, empty retries any failure
+	AssociationRetryOnExitCodes []int
+	// AssociationDryRun makes the agent validate associations and report the plugins that would run without running them
+	AssociationDryRun bool
+	// AssociationSkipUnchanged skips associations whose document and parameters are unchanged since their last successful run
+	AssociationSkipUnchanged bool
+	// AssociationExecutionWindow restricts association runs to a daily window of instance time, such as "02:00-04:00"
+	AssociationExecutionWindow string
+	// AssociationHistoryMaxRecords is the number of association executions kept in the local execution history, 0 disables the history
+	AssociationHistoryMaxRecords int
+	// AssociationPriorities maps document names to the priority of their associations, due associations with
+	// a higher priority run first, associations of documents not listed have priority 0
+	AssociationPriorities map[string]int
+	// AssociationStatusFlushIntervalSeconds is the interval at which plugin progress updates of associations are sent, 0 sends every update
+	AssociationStatusFlushIntervalSeconds int
+	// PluginOutputMaxStdoutBytes is the number of standard output bytes of a plugin kept in the reply
+	PluginOutputMaxStdoutBytes int
+	// PluginOutputMaxStderrBytes is the number of standard error bytes of a plugin kept in the reply
+	PluginOutputMaxStderrBytes int
+	// PluginOutputTruncationStrategy is either head or tail, the part of the plugin output kept when it is truncated
+	PluginOutputTruncationStrategy string
+	// PluginOutputCloudWatchLogGroup is the log group the plugin output of commands and associations is streamed to
+	// when they don't enable CloudWatch output themselves, empty disables it
+	PluginOutputCloudWatchLogGroup string
+	// PluginOutputCloudWatchFlushIntervalSeconds is the longest the plugin output is held before it's streamed to CloudWatch Logs
+	PluginOutputCloudWatchFlushIntervalSeconds int
+	// PluginResultSink is the destination every plugin result is published to in addition to the reply,
+	// either firehose or empty to disable it
+	PluginResultSink string
+	// PluginResultFirehoseDeliveryStream is the Kinesis Data Firehose delivery stream of the firehose result sink
+	PluginResultFirehoseDeliveryStream string
+	// AssociationHookUrl is an endpoint on localhost or a loopback address receiving a JSON POST on every association status transition
+	AssociationHookUrl string
+	// AssociationHookScript is a script executed with the event as JSON on stdin on every association status transition
+	AssociationHookScript string
+	// OrchestrationRetentionMaxCount is the number of orchestration directories kept for completed documents, 0 disables the limit
+	OrchestrationRetentionMaxCount int
+	// OrchestrationRetentionMaxSizeMB is the total size of the orchestration directories kept for completed documents, 0 disables the limit
+	OrchestrationRetentionMaxSizeMB int
+	// OrchestrationRetentionSweepMinutes is the interval at which the orchestration directories are swept
+	OrchestrationRetentionSweepMinutes int
+	// CustomInventoryGatherers are the executables printing custom inventory items in JSON, they run along the custom
+	// inventory files once the inventory policy enables the custom inventory
+	CustomInventoryGatherers []string
+	// NetworkInventoryExcludedItems are the network inventory types, such as Custom:ListeningPort, and the attributes of
+	// AWS:Network, such as MacAddress, left out of the network inventory
+	NetworkInventoryExcludedItems []string
+	// InventoryFullUploadIntervalHours is the interval at which the content of an unchanged inventory type is uploaded
+	// again instead of its content hash, reconciling the inventory of the instance, 0 disables it
+	InventoryFullUploadIntervalHours int
+	// InventoryGathererIntervalMinutes maps inventory gatherers, such as AWS:Network, to the minimum interval between two
+	// of their collections, the gatherers not listed collect on every run of the inventory association
+	InventoryGathererIntervalMinutes map[string]int
+	// InventoryOutputDirectory is the directory every collected inventory is written to as JSON, empty disables it
+	InventoryOutputDirectory string
+	// InventoryOutputMaxFiles is the number of inventory files kept in InventoryOutputDirectory, the oldest are deleted first,
+	// 0 keeps them all
+	InventoryOutputMaxFiles int
+	// InventoryOutputS3BucketName is the bucket every collected inventory is uploaded to as JSON, empty disables it
+	InventoryOutputS3BucketName string
+	// InventoryOutputS3KeyPrefix is the key prefix of the inventory uploaded to InventoryOutputS3BucketName
+	InventoryOutputS3KeyPrefix string
+	// InventoryUploadDisabled stops uploading the inventory to Systems Manager, the inventory is only written to its outputs.
+	// The inventory plugin fails when no output is configured.
+	InventoryUploadDisabled bool
+	// InventoryCertificateLocations are the certificate files and directories reported by the certificate inventory,
+	// along with the Windows certificate stores such as Cert:\LocalMachine\My
+	InventoryCertificateLocations []string
 	// TODO: test hook, can be removed before release
 	// this is to skip ssl verification for the beta self signed certs
 	InsecureSkipVerify                    bool
@@ -52,6 +139,68 @@ type AgentInfo struct {
 	Region               string
 	OrchestrationRootDir string
 	DownloadRootDir      string
+	// FailInterruptedPlugins fails the plugins interrupted by an agent restart instead of executing them again
+	FailInterruptedPlugins bool
+	// MaxDocumentExecutionSeconds is the wall clock limit of a document execution, 0 disables the limit
+	MaxDocumentExecutionSeconds int
+	// AutoReboot lets the agent reboot the machine when a document requires it, otherwise the document waits for an external reboot
+	AutoReboot bool
+	// RebootWindow is the daily HH:MM-HH:MM window of instance time the agent reboots the machine in, empty reboots right away
+	RebootWindow string
+	// PowerShellPath is the PowerShell binary the runPowerShellScript plugin uses, empty uses the first PowerShell found on the instance
+	PowerShellPath string
+	// UpdateManifestLocation is the manifest the updateSsmAgent plugin updates from when the document has no source, empty uses the AWS manifest of the region
+	UpdateManifestLocation string
+	// UpdateManifestKeyring is the absolute path of the GPG public keyring that verifies the signature of manifests from sources other than AWS,
+	// the agent doesn't update from those sources without it
+	UpdateManifestKeyring string
+	// LocalIpcEnabled serves the local control gRPC API to root or Administrator local tooling over a unix socket or named pipe
+	LocalIpcEnabled bool
+	// MetricsPort serves the agent metrics in the Prometheus text format on http://127.0.0.1:<port>/metrics,
+	// 0 disables the endpoint
+	MetricsPort int
+	// StatsdAddress is the host:port of the StatsD or DogStatsD server the agent sends its metrics to over UDP,
+	// tagged with the association, the plugin and the status, empty disables it
+	StatsdAddress string
+	// TracingEndpoint is the base URL of the OTLP/HTTP receiver of the OpenTelemetry collector, such as http://127.0.0.1:4318,
+	// the spans of the documents, plugins and API calls are posted to <endpoint>/v1/traces, empty disables tracing
+	TracingEndpoint string
+	// LogShippingLogGroup is the CloudWatch Logs group the agent streams its amazon-ssm-agent.log and errors.log to,
+	// in the <instance id>/<file name> log streams, empty disables the shipping
+	LogShippingLogGroup string
+	// UseFipsEndpoints makes the SSM, MDS, MGS, S3 and KMS clients use the FIPS endpoints of the regions that have them,
+	// the AWS_USE_FIPS_ENDPOINT environment variable overrides it
+	UseFipsEndpoints bool
+	// UseDualStackEndpoints makes the SSM, MDS, MGS, S3 and KMS clients use the dual-stack endpoints reachable over IPv4 and IPv6,
+	// the AWS_USE_DUALSTACK_ENDPOINT environment variable overrides it
+	UseDualStackEndpoints bool
+	// Ec2MetadataEndpointMode is IPv6 to reach the instance metadata over IPv6 on IPv6-only instances, otherwise IPv4,
+	// the AWS_EC2_METADATA_SERVICE_ENDPOINT_MODE environment variable overrides it
+	Ec2MetadataEndpointMode string
+	// LogBackend is syslog to write the agent logs to the local syslog daemon, journald to write them to journald
+	// on systemd hosts, otherwise file to write them as configured in seelog.xml
+	LogBackend string
+	// SyslogFacility is the facility of the agent logs written to syslog, such as daemon or local0
+	SyslogFacility string
+	// WindowsEventLogEnabled writes the start and the end of the documents and sessions to the Amazon SSM Agent
+	// Windows event log, it has no effect on other platforms
+	WindowsEventLogEnabled bool
+	// HealthPort serves the registration, credentials, service contact, queue and association status of the agent
+	// as JSON on http://127.0.0.1:<port>/health, 0 disables the endpoint
+	HealthPort int
+	// WatchdogMaxMemoryMB, WatchdogMaxGoroutines and WatchdogMaxOpenFiles are the memory, goroutines and open files
+	// or handles of the agent above which the watchdog restarts the agent, 0 doesn't watch the resource
+	WatchdogMaxMemoryMB   int
+	WatchdogMaxGoroutines int
+	WatchdogMaxOpenFiles  int
+	// LogRotationMaxFileSizeMB rolls the agent log files over once they reach that size, LogRotationMaxFiles is the number
+	// of rolled files kept for each log file and LogRotationMaxAgeDays deletes the rolled files older than that, 0 keeps
+	// them regardless of age. These apply to the rotating_file outputs of seelog.xml.
+	LogRotationMaxFileSizeMB int
+	LogRotationMaxFiles      int
+	LogRotationMaxAgeDays    int
+	// LogRotationCompress gzips the rolled log files
+	LogRotationCompress bool
 }
 
 // MgsConfig represents configuration for Message Gateway service
@@ -60,11 +209,86 @@ type MgsConfig struct {
 	Endpoint            string
 	StopTimeoutMillis   int64
 	SessionWorkersLimit int
+	// PortForwardingAllowedDestinations are the host:port destinations other than the instance itself that port sessions
+	// may forward to, * matches any port of a host. Port sessions can always forward to the ports of the instance.
+	PortForwardingAllowedDestinations []string
+	// MaxConcurrentSessions rejects the new sessions while the instance runs that many sessions, 0 doesn't limit them
+	MaxConcurrentSessions int
+	// MaxConcurrentSessionsPerUser rejects the new sessions of a user running that many sessions, 0 doesn't limit them
+	MaxConcurrentSessionsPerUser int
+	// SessionOutgoingBytesPerSecond limits the throughput of the data each session sends to its client, 0 doesn't limit it
+	SessionOutgoingBytesPerSecond int
+	// SessionIncomingBytesPerSecond limits the throughput of the data each session receives from its client, 0 doesn't limit it
+	SessionIncomingBytesPerSecond int
+	// DataChannelPingIntervalSeconds is the interval of the websocket pings keeping the session data channels alive
+	DataChannelPingIntervalSeconds int
+	// DataChannelRetryInitialDelayMillis and DataChannelRetryMaxIntervalMillis bound the exponential backoff between
+	// the attempts to reconnect a session data channel
+	DataChannelRetryInitialDelayMillis int
+	DataChannelRetryMaxIntervalMillis  int
+	// DataChannelReconnectWindowSeconds keeps reconnecting a session data channel for that long before giving up on
+	// the session, 0 gives up after a few attempts
+	DataChannelReconnectWindowSeconds int
+	// SessionOpenHookScript and SessionCloseHookScript are local scripts executed as the sessions open and close, with
+	// the session id, user and type in the SSM_SESSION_ID, SSM_SESSION_USER and SSM_SESSION_TYPE environment variables
+	SessionOpenHookScript  string
+	SessionCloseHookScript string
+	// RunAsMappingFile is a JSON file mapping the IAM principals starting sessions to the local accounts their sessions
+	// run as, by value of their SSMSessionRunAs tag or by ARN of the IAM user or role, e.g.
+	// {"Tags": {"alice": {"User": "alice", "WorkingDirectory": "/srv/app"}},
+	//  "Principals": {"arn:aws:iam::123456789012:role/Deploy": {"User": "deploy"}}}.
+	// The sessions start in the home directory of their account unless the file sets their working directory.
+	// The sessions never run as root, and only run as the default ssm user on windows.
+	RunAsMappingFile string
+	// RunAsDefaultUser is the local account of the sessions of the users the mapping file doesn't map, empty is ssm-user
+	RunAsDefaultUser string
+	// SessionFileTransferEnabled lets the shell sessions upload and download files over their data channel. The
+	// transfers are confined to the working directory of the account of the session, unless the session runs elevated.
+	SessionFileTransferEnabled bool
+	// IdleSessionTimeoutMinutes terminates the interactive sessions which got no input for that long, 0 disables it
+	IdleSessionTimeoutMinutes int
+	// SSHPort is the port of the local sshd ssh sessions connect to
+	SSHPort int
+	// ShellProfile is the profile of the interactive shell sessions set by the local admins, per platform
+	ShellProfile ShellProfileCfg
+	// SessionRecordingS3BucketName enables the local recording of the interactive sessions, whatever the logging of the
+	// session document, the recordings are uploaded to the bucket once the sessions end
+	SessionRecordingS3BucketName string
+	SessionRecordingS3KeyPrefix  string
+	// SessionRecordingKmsKeyArn is the KMS key the recordings are encrypted with on the server side (SSE-KMS)
+	SessionRecordingKmsKeyArn string
+	// RestrictedSessionAllowedCommands restricts the shell sessions to the commands typed exactly as one of these or
+	// matching in full one of RestrictedSessionAllowedPatterns. The sessions are restricted once either is set, the
+	// other commands are rejected and every command is audited locally.
+	RestrictedSessionAllowedCommands []string
+	// RestrictedSessionAllowedPatterns are the regular expressions of the commands allowed in the restricted sessions,
+	// the commands containing shell metacharacters such as ; | $ or a backtick never match them
+	RestrictedSessionAllowedPatterns []string
+}
+
+// ShellProfileCfg represents the shell profile of the interactive sessions of each platform
+type ShellProfileCfg struct {
+	Linux   SessionShellProfile
+	Windows SessionShellProfile
+}
+
+// SessionShellProfile represents the shell profile of the interactive sessions of a platform
+type SessionShellProfile struct {
+	// Shell is the shell the sessions start, empty starts sh on linux and powershell on windows
+	Shell string
+	// Commands are run at the start of the interactive sessions, after the profile of the session document
+	Commands string
+	// Environment are the variables set in the interactive sessions before any profile command runs
+	Environment map[string]string
+	// OverrideServiceProfile ignores the profile of the session document instead of running it before Commands
+	OverrideServiceProfile bool
 }
 
 // KmsConfig represents configuration for Key Management Service
 type KmsConfig struct {
 	Endpoint string
+	// KeyId restricts the SecureString parameters documents can refer to to the ones encrypted with this key
+	KeyId string
 }
 
 // OsInfo represents os related information
@@ -80,6 +304,11 @@ type S3Cfg struct {
 	Region    string
 	LogBucket string
 	LogKey    string
+	// OutputKmsKeyArn is the KMS key the output uploaded to S3 is encrypted with (SSE-KMS), documents can set their own
+	OutputKmsKeyArn string
+	// OutputAcl is the canned ACL of the output uploaded to S3, either bucket-owner-full-control or none.
+	// If empty, bucket-owner-full-control is requested after the upload and failing to set it is ignored.
+	OutputAcl string
 }
 
 // BirdwatcherCfg represents configuration related to ConfigurePackage Birdwatcher integration
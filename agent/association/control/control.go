@@ -0,0 +1,252 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package control manages local requests controlling the association processing of the running agent
+package control
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/association/recorder"
+	"github.com/aws/amazon-ssm-agent/agent/fileutil"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/times"
+	"github.com/fsnotify/fsnotify"
+)
+
+const (
+	// CancelRequestDirName represents the folder where requests to cancel associations are dropped
+	CancelRequestDirName = "cancel"
+	// RunRequestDirName represents the folder where requests to run associations immediately are dropped
+	RunRequestDirName = "run"
+	// InventoryRunRequest requests an immediate run of the association executing the software inventory plugin
+	InventoryRunRequest = "inventory"
+	// PauseMarkerName represents the file which pauses association processing while it exists
+	PauseMarkerName = "paused"
+)
+
+// dataStorePath is assigned to a variable to allow unit tests to override it
+var dataStorePath = appconfig.DefaultDataStorePath
+
+// runPollInterval is assigned to a variable to allow unit tests to override it
+var runPollInterval = time.Second
+
+// RequestCancel records a request to cancel the running execution of the association,
+// the request is picked up by the running agent
+func RequestCancel(instanceID string, associationID string) error {
+	return dropRequest(getCancelLocation(instanceID), associationID)
+}
+
+// RequestRun records a request to run the association immediately, outside of its schedule,
+// the request is picked up by the running agent. InventoryRunRequest requests a run of the inventory association.
+func RequestRun(instanceID string, associationID string) error {
+	return dropRequest(getRunLocation(instanceID), associationID)
+}
+
+// WaitForRun waits until the agent records an execution of the association started at or after since, and returns it.
+// Executions of the inventory association are waited for when associationID is InventoryRunRequest.
+func WaitForRun(instanceID string, associationID string, since time.Time, timeout time.Duration) (recorder.AssociationExecution, error) {
+	since = since.UTC().Truncate(time.Millisecond)
+	deadline := time.Now().Add(timeout)
+	for {
+		for _, execution := range recorder.ExecutionHistory(instanceID, "", 0) {
+			if isRequestedRun(execution, associationID) && !times.ParseIso8601UTC(execution.StartDateTime).Before(since) {
+				return execution, nil
+			}
+		}
+		if time.Now().After(deadline) {
+			return recorder.AssociationExecution{}, fmt.Errorf("no execution of association %v completed within %v", associationID, timeout)
+		}
+		time.Sleep(runPollInterval)
+	}
+}
+
+// isRequestedRun returns true if the execution belongs to the association the run was requested for
+func isRequestedRun(execution recorder.AssociationExecution, associationID string) bool {
+	if associationID != InventoryRunRequest {
+		return execution.AssociationID == associationID
+	}
+	for _, plugin := range execution.PluginResults {
+		if plugin.PluginName == appconfig.PluginNameAwsSoftwareInventory {
+			return true
+		}
+	}
+	return false
+}
+
+// dropRequest drops the request file of the association in the location watched by the running agent
+func dropRequest(location string, associationID string) error {
+	if associationID == "" || filepath.Base(associationID) != associationID {
+		return fmt.Errorf("invalid association id %v", associationID)
+	}
+
+	if err := fileutil.MakeDirs(location); err != nil {
+		return fmt.Errorf("cannot make directory of %v because: %v", location, err)
+	}
+
+	_, err := fileutil.WriteIntoFileWithPermissions(
+		path.Join(location, associationID),
+		"",
+		os.FileMode(int(appconfig.ReadWriteAccess)))
+	return err
+}
+
+// Pause pauses association processing of the running agent, associations already running are not affected
+func Pause(instanceID string) error {
+	location := getLocation(instanceID)
+	if err := fileutil.MakeDirs(location); err != nil {
+		return fmt.Errorf("cannot make directory of %v because: %v", location, err)
+	}
+
+	_, err := fileutil.WriteIntoFileWithPermissions(
+		getPauseMarker(instanceID),
+		"",
+		os.FileMode(int(appconfig.ReadWriteAccess)))
+	return err
+}
+
+// Resume resumes association processing of the running agent
+func Resume(instanceID string) error {
+	if err := os.Remove(getPauseMarker(instanceID)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// IsPaused returns true while association processing is paused
+func IsPaused(instanceID string) bool {
+	return fileutil.Exists(getPauseMarker(instanceID))
+}
+
+// Watcher delivers the control requests dropped for the running agent
+type Watcher struct {
+	watcher *fsnotify.Watcher
+}
+
+// WatchCancelRequests calls onCancel for every pending and future cancel request, every request is delivered once
+func WatchCancelRequests(log log.T, instanceID string, onCancel func(associationID string)) (*Watcher, error) {
+	return watchRequests(log, getCancelLocation(instanceID), "cancel", onCancel)
+}
+
+// WatchRunRequests calls onRun for every pending and future run request, every request is delivered once
+func WatchRunRequests(log log.T, instanceID string, onRun func(associationID string)) (*Watcher, error) {
+	return watchRequests(log, getRunLocation(instanceID), "run", onRun)
+}
+
+// watchRequests calls onRequest for every request dropped in the location, every request is delivered once
+func watchRequests(log log.T, location string, action string, onRequest func(associationID string)) (*Watcher, error) {
+	if err := fileutil.MakeDirs(location); err != nil {
+		return nil, fmt.Errorf("cannot make directory of %v because: %v", location, err)
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if err = watcher.Add(location); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+
+	go func() {
+		for event := range watcher.Events {
+			if event.Op&fsnotify.Create == fsnotify.Create || event.Op&fsnotify.Write == fsnotify.Write {
+				takeRequest(log, event.Name, action, onRequest)
+			}
+		}
+	}()
+
+	// requests made while the agent was not watching
+	if files, err := ioutil.ReadDir(location); err == nil {
+		for _, file := range files {
+			takeRequest(log, path.Join(location, file.Name()), action, onRequest)
+		}
+	}
+
+	return &Watcher{watcher: watcher}, nil
+}
+
+// WatchResume calls onResume every time association processing is resumed
+func WatchResume(log log.T, instanceID string, onResume func()) (*Watcher, error) {
+	location := getLocation(instanceID)
+	if err := fileutil.MakeDirs(location); err != nil {
+		return nil, fmt.Errorf("cannot make directory of %v because: %v", location, err)
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if err = watcher.Add(location); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+
+	marker := getPauseMarker(instanceID)
+	go func() {
+		for event := range watcher.Events {
+			if event.Name == marker && (event.Op&fsnotify.Remove == fsnotify.Remove || event.Op&fsnotify.Rename == fsnotify.Rename) {
+				log.Info("Association processing resumed")
+				onResume()
+			}
+		}
+	}()
+
+	return &Watcher{watcher: watcher}, nil
+}
+
+// Stop stops delivering control requests
+func (w *Watcher) Stop() {
+	if w != nil && w.watcher != nil {
+		w.watcher.Close()
+	}
+}
+
+// takeRequest removes the request file and delivers the request, unless another event delivered it already
+func takeRequest(log log.T, fileName string, action string, onRequest func(associationID string)) {
+	if err := os.Remove(fileName); err != nil {
+		return
+	}
+	associationID := filepath.Base(fileName)
+	log.Infof("Received request to %v association %v", action, associationID)
+	onRequest(associationID)
+}
+
+// getLocation returns the association folder holding the control requests
+func getLocation(instanceID string) string {
+	return path.Join(dataStorePath,
+		instanceID,
+		appconfig.DefaultDocumentRootDirName,
+		appconfig.DefaultLocationOfAssociation)
+}
+
+// getCancelLocation returns the folder where cancel requests are dropped
+func getCancelLocation(instanceID string) string {
+	return path.Join(getLocation(instanceID), CancelRequestDirName)
+}
+
+// getRunLocation returns the folder where run requests are dropped
+func getRunLocation(instanceID string) string {
+	return path.Join(getLocation(instanceID), RunRequestDirName)
+}
+
+// getPauseMarker returns the full file name of the pause marker
+func getPauseMarker(instanceID string) string {
+	return path.Join(getLocation(instanceID), PauseMarkerName)
+}
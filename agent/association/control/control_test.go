@@ -0,0 +1,93 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package control manages local requests controlling the association processing of the running agent
+package control
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/association/recorder"
+	"github.com/aws/amazon-ssm-agent/agent/fileutil"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestCancelRejectsInvalidAssociationID(t *testing.T) {
+	assert.Error(t, RequestCancel("i-1234567890", ""))
+	assert.Error(t, RequestCancel("i-1234567890", "../b2f71a28"))
+}
+
+func TestRequestRun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "control")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	defer func(original string) { dataStorePath = original }(dataStorePath)
+	dataStorePath = dir
+
+	assert.Error(t, RequestRun("i-1234567890", "../b2f71a28"))
+	assert.NoError(t, RequestRun("i-1234567890", InventoryRunRequest))
+	assert.True(t, fileutil.Exists(path.Join(getRunLocation("i-1234567890"), InventoryRunRequest)))
+}
+
+func TestIsRequestedRun(t *testing.T) {
+	inventory := recorder.AssociationExecution{
+		AssociationID: "b2f71a28-cbe1-4429-b848-26c7e1f5ad0d",
+		PluginResults: []recorder.PluginExecution{{PluginID: "collectSoftwareInventoryItems", PluginName: appconfig.PluginNameAwsSoftwareInventory}},
+	}
+	script := recorder.AssociationExecution{
+		AssociationID: "0aa6c4b2-4a5c-4b4e-9d0e-6f0f3a1b2c3d",
+		PluginResults: []recorder.PluginExecution{{PluginID: "runShellScript", PluginName: appconfig.PluginNameAwsRunShellScript}},
+	}
+
+	assert.True(t, isRequestedRun(inventory, InventoryRunRequest))
+	assert.True(t, isRequestedRun(inventory, "b2f71a28-cbe1-4429-b848-26c7e1f5ad0d"))
+	assert.False(t, isRequestedRun(script, InventoryRunRequest))
+	assert.False(t, isRequestedRun(script, "b2f71a28-cbe1-4429-b848-26c7e1f5ad0d"))
+}
+
+func TestPauseAndResume(t *testing.T) {
+	dir, err := ioutil.TempDir("", "control")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	defer func(original string) { dataStorePath = original }(dataStorePath)
+	dataStorePath = dir
+
+	assert.False(t, IsPaused("i-1234567890"))
+	assert.NoError(t, Pause("i-1234567890"))
+	assert.True(t, IsPaused("i-1234567890"))
+	assert.NoError(t, Resume("i-1234567890"))
+	assert.False(t, IsPaused("i-1234567890"))
+	assert.NoError(t, Resume("i-1234567890"))
+}
+
+func TestTakeRequestDeliversOnce(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cancel")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	fileName := path.Join(dir, "b2f71a28-cbe1-4429-b848-26c7e1f5ad0d")
+	assert.NoError(t, ioutil.WriteFile(fileName, []byte{}, 0600))
+
+	canceled := []string{}
+	onCancel := func(associationID string) {
+		canceled = append(canceled, associationID)
+	}
+	takeRequest(log.NewMockLog(), fileName, "cancel", onCancel)
+	takeRequest(log.NewMockLog(), fileName, "cancel", onCancel)
+
+	assert.Equal(t, []string{"b2f71a28-cbe1-4429-b848-26c7e1f5ad0d"}, canceled)
+}
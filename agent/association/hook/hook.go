@@ -0,0 +1,188 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package hook notifies local tooling of association lifecycle events
+package hook
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/jsonutil"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+)
+
+const (
+	// hookTimeout is the time a hook endpoint or script is given to handle an event
+	hookTimeout = 10 * time.Second
+	// eventQueueSize is the number of events waiting for delivery before new events are dropped
+	eventQueueSize = 100
+)
+
+// Event is the association lifecycle event sent to the hooks
+type Event struct {
+	AssociationID string `json:"associationId"`
+	DocumentName  string `json:"documentName"`
+	InstanceID    string `json:"instanceId"`
+	Status        string `json:"status"`
+	Summary       string `json:"summary"`
+	EventTime     string `json:"eventTime"`
+}
+
+// Config is the location of the hooks, empty values disable the corresponding hook.
+// The URL must be an endpoint of the instance itself, the events aren't sent anywhere else.
+type Config struct {
+	URL    string
+	Script string
+}
+
+type notification struct {
+	log    log.T
+	config Config
+	event  Event
+}
+
+var queue chan notification
+var once sync.Once
+
+// Notify queues the event for delivery to the configured hooks, events are delivered one at a time in order
+func Notify(log log.T, config Config, event Event) {
+	if config.URL == "" && config.Script == "" {
+		return
+	}
+
+	once.Do(func() {
+		queue = make(chan notification, eventQueueSize)
+		go deliver()
+	})
+
+	select {
+	case queue <- notification{log: log, config: config, event: event}:
+	default:
+		log.Warnf("Association hook queue is full, dropping %v event of association %v", event.Status, event.AssociationID)
+	}
+}
+
+func deliver() {
+	for n := range queue {
+		if n.config.URL != "" {
+			if err := post(n.config.URL, n.event); err != nil {
+				n.log.Warnf("Failed to post association event to hook %v, %v", n.config.URL, err)
+			}
+		}
+		if n.config.Script != "" {
+			if err := runScript(n.config.Script, n.event); err != nil {
+				n.log.Warnf("Failed to run association hook script %v, %v", n.config.Script, err)
+			}
+		}
+	}
+}
+
+// post sends the event as JSON to the hook endpoint
+func post(hookURL string, event Event) error {
+	if err := validateURL(hookURL); err != nil {
+		return err
+	}
+	content, err := jsonutil.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	// the events never leave the instance, neither through a proxy nor a redirect
+	client := &http.Client{
+		Timeout:   hookTimeout,
+		Transport: &http.Transport{Dial: dialLoopback},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	resp, err := client.Post(hookURL, "application/json", bytes.NewBufferString(content))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("hook responded with status %v", resp.Status)
+	}
+	return nil
+}
+
+// validateURL checks the hook is an http or https endpoint on the loopback interface
+func validateURL(hookURL string) error {
+	parsed, err := url.Parse(hookURL)
+	if err != nil {
+		return err
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return fmt.Errorf("hook %v must be an http or https URL", hookURL)
+	}
+	if host := parsed.Hostname(); host != "localhost" {
+		if ip := net.ParseIP(host); ip == nil || !ip.IsLoopback() {
+			return fmt.Errorf("hook %v must be an endpoint of the instance, localhost or a loopback address", hookURL)
+		}
+	}
+	return nil
+}
+
+// dialLoopback only connects to the loopback addresses the host resolves to
+func dialLoopback(network, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	ips, err := net.LookupIP(host)
+	if err != nil {
+		return nil, err
+	}
+	for _, ip := range ips {
+		if ip.IsLoopback() {
+			return net.DialTimeout(network, net.JoinHostPort(ip.String(), port), hookTimeout)
+		}
+	}
+	return nil, fmt.Errorf("hook host %v doesn't resolve to a loopback address", host)
+}
+
+// runScript executes the hook script with the event as JSON on its standard input,
+// the event fields are also available in SSM_ASSOCIATION_* environment variables
+func runScript(script string, event Event) error {
+	content, err := jsonutil.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command(script)
+	cmd.Stdin = bytes.NewBufferString(content)
+	cmd.Env = append(os.Environ(),
+		"SSM_ASSOCIATION_ID="+event.AssociationID,
+		"SSM_ASSOCIATION_DOCUMENT_NAME="+event.DocumentName,
+		"SSM_ASSOCIATION_INSTANCE_ID="+event.InstanceID,
+		"SSM_ASSOCIATION_STATUS="+event.Status,
+		"SSM_ASSOCIATION_SUMMARY="+event.Summary)
+	if err = cmd.Start(); err != nil {
+		return err
+	}
+
+	timer := time.AfterFunc(hookTimeout, func() {
+		cmd.Process.Kill()
+	})
+	defer timer.Stop()
+	return cmd.Wait()
+}
@@ -0,0 +1,104 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package hook notifies local tooling of association lifecycle events
+package hook
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func newEvent(status string) Event {
+	return Event{
+		AssociationID: "b2f71a28-cbe1-4429-b848-26c7e1f5ad0d",
+		DocumentName:  "AWS-RunShellScript",
+		InstanceID:    "i-1234567890",
+		Status:        status,
+		Summary:       "summary",
+	}
+}
+
+func TestPostSendsEventAsJson(t *testing.T) {
+	var received Event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+	}))
+	defer server.Close()
+
+	err := post(server.URL, newEvent("Success"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, newEvent("Success"), received)
+}
+
+func TestPostFailsOnErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	assert.Error(t, post(server.URL, newEvent("Failed")))
+}
+
+func TestPostRejectsRemoteEndpoints(t *testing.T) {
+	for _, hookURL := range []string{
+		"http://example.com/hook",
+		"https://10.0.0.5:8443/hook",
+		"http://169.254.169.254/latest",
+		"ftp://localhost/hook",
+	} {
+		assert.Error(t, post(hookURL, newEvent("Success")), hookURL)
+	}
+}
+
+func TestPostDoesNotFollowRedirects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://example.com/hook", http.StatusTemporaryRedirect)
+	}))
+	defer server.Close()
+
+	assert.Error(t, post(server.URL, newEvent("Success")))
+}
+
+func TestNotifyDeliversEventsInOrder(t *testing.T) {
+	statuses := make(chan string, 3)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event Event
+		json.NewDecoder(r.Body).Decode(&event)
+		statuses <- event.Status
+	}))
+	defer server.Close()
+
+	config := Config{URL: server.URL}
+	for _, status := range []string{"Pending", "InProgress", "Success"} {
+		Notify(log.NewMockLog(), config, newEvent(status))
+	}
+
+	for _, expected := range []string{"Pending", "InProgress", "Success"} {
+		select {
+		case status := <-statuses:
+			assert.Equal(t, expected, status)
+		case <-time.After(5 * time.Second):
+			assert.Fail(t, "event was not delivered")
+			return
+		}
+	}
+}
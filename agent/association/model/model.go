@@ -35,6 +35,10 @@ type InstanceAssociation struct {
 	ParsedExpression  scheduleexpression.ScheduleExpression
 	Document          *string
 	Errors            []error
+	// ScheduleJitter offsets the scheduled runs of the association to spread load across instances
+	ScheduleJitter time.Duration
+	// Priority orders the association ahead of due associations with a lower priority
+	Priority int
 }
 
 // ParseExpression parses the expression with the given association
@@ -98,9 +102,12 @@ func (newAssoc *InstanceAssociation) SetNextScheduledDate(log log.T) {
 		}
 	}
 
-	// Set next schedule date of association according to it's schedule
+	// Set next schedule date of association according to it's schedule.
+	// The jitter is removed from the last execution date before looking up the next date so
+	// that the offset does not accumulate over consecutive runs.
+	lastScheduledDate := newAssoc.Association.LastExecutionDate.UTC().Add(-newAssoc.ScheduleJitter)
 	newAssoc.NextScheduledDate = aws.Time(
-		newAssoc.ParsedExpression.Next(newAssoc.Association.LastExecutionDate.UTC()).UTC())
+		newAssoc.ParsedExpression.Next(lastScheduledDate).UTC().Add(newAssoc.ScheduleJitter))
 	log.Infof("Based upon expression %v and last execution date %v, next scheduled date for association %v is %v",
 		*newAssoc.Association.ScheduleExpression, times.ToIsoDashUTC(*newAssoc.Association.LastExecutionDate),
 		*newAssoc.Association.AssociationId, times.ToIsoDashUTC(*newAssoc.NextScheduledDate))
@@ -384,3 +384,34 @@ func TestNextScheduledDateIsNilWhenAssociationExpressionTypeIsUnknown(t *testing
 	// Assert
 	assert.Nil(t, assocRawData.NextScheduledDate)
 }
+
+func TestNextScheduledDateIsOffsetByScheduleJitter(t *testing.T) {
+
+	// Assemble
+	logger := log.DefaultLogger()
+
+	assocRawData := InstanceAssociation{
+		ScheduleJitter: 7 * time.Minute,
+	}
+
+	assocRawData.Association = &ssm.InstanceAssociationSummary{}
+	testAssociationName := "Test"
+	assocRawData.Association.Name = &testAssociationName
+	assocId := "b2f71a28-cbe1-4429-b848-26c7e1f5ad0d"
+	assocRawData.Association.AssociationId = &assocId
+	testCronExpression := "cron(0 0 0/1 * * ? *)" // hourly cron expression
+	assocRawData.Association.ScheduleExpression = &testCronExpression
+
+	// the previous run happened at its jittered time
+	lastExecutionDateTime := time.Date(
+		2009, 11, 17, 21, 07, 02, 000000000, time.UTC)
+	assocRawData.Association.LastExecutionDate = &lastExecutionDateTime
+
+	expectedNextScheduledDateTime := time.Date(
+		2009, 11, 17, 22, 07, 00, 000000000, time.UTC)
+	// Act
+	assocRawData.SetNextScheduledDate(logger)
+
+	// Assert
+	assert.Equal(t, expectedNextScheduledDateTime, *assocRawData.NextScheduledDate)
+}
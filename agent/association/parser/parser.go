@@ -95,13 +95,21 @@ func InitializeDocumentState(context context.T,
 		MessageId:        documentInfo.MessageID,
 		DocumentId:       documentInfo.DocumentID,
 	}
+	// associations have no cloudWatch output of their own, their output is streamed to the log group configured for the agent, if any
+	if logGroupName := context.AppConfig().Ssm.PluginOutputCloudWatchLogGroup; logGroupName != "" {
+		parserInfo.CloudWatchConfig = contracts.CloudWatchConfiguration{
+			LogGroupName:    logGroupName,
+			LogStreamPrefix: path.Join(documentInfo.AssociationID, documentInfo.InstanceID, documentInfo.RunID),
+		}
+	}
 
 	docContent := &docparser.DocContent{
-		SchemaVersion: payload.DocumentContent.SchemaVersion,
-		Description:   payload.DocumentContent.Description,
-		RuntimeConfig: payload.DocumentContent.RuntimeConfig,
-		MainSteps:     payload.DocumentContent.MainSteps,
-		Parameters:    payload.DocumentContent.Parameters,
+		SchemaVersion:   payload.DocumentContent.SchemaVersion,
+		Description:     payload.DocumentContent.Description,
+		RuntimeConfig:   payload.DocumentContent.RuntimeConfig,
+		MainSteps:       payload.DocumentContent.MainSteps,
+		Parameters:      payload.DocumentContent.Parameters,
+		OutputS3Options: payload.DocumentContent.OutputS3Options,
 	}
 	return docparser.InitializeDocState(context.Log(), contracts.Association, docContent, documentInfo, parserInfo, payload.Parameters)
 }
@@ -121,6 +129,7 @@ func newDocumentInfo(rawData *model.InstanceAssociation, payload *messageContrac
 	documentInfo.DocumentName = payload.DocumentName
 	documentInfo.DocumentVersion = *(rawData.Association.DocumentVersion)
 	documentInfo.DocumentStatus = contracts.ResultStatusInProgress
+	documentInfo.Priority = rawData.Priority
 
 	return *documentInfo
 }
@@ -0,0 +1,61 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package processor manage polling of associations, dispatching association to processor
+package processor
+
+import (
+	"fmt"
+
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/times"
+)
+
+// cancelAssociation cancels the running execution of the association, the association jobs are keyed by association id
+func (p *Processor) cancelAssociation(log log.T, associationID string) {
+	instanceID, err := sys.InstanceID()
+	if err != nil {
+		log.Error("failed to load instance id ", err)
+		return
+	}
+
+	log.Infof("Canceling association %v", associationID)
+	p.proc.Cancel(newCancelAssociationDocState(instanceID, associationID))
+}
+
+// newCancelAssociationDocState returns the cancel document for the running execution of the association
+func newCancelAssociationDocState(instanceID string, associationID string) contracts.DocumentState {
+	runID := times.ToIsoDashUTC(times.DefaultClock.Now())
+	cancelID := fmt.Sprintf("cancel.%v.%v", associationID, runID)
+	documentInfo := contracts.DocumentInfo{
+		InstanceID:     instanceID,
+		MessageID:      cancelID,
+		CommandID:      cancelID,
+		DocumentID:     cancelID,
+		RunID:          runID,
+		DocumentStatus: contracts.ResultStatusInProgress,
+	}
+
+	cancelInfo := contracts.CancelCommandInfo{
+		CancelMessageID: associationID,
+		CancelCommandID: associationID,
+		DebugInfo:       fmt.Sprintf("Association %v is yet to be cancelled", associationID),
+	}
+
+	return contracts.DocumentState{
+		DocumentInformation: documentInfo,
+		CancelInformation:   cancelInfo,
+		DocumentType:        contracts.CancelCommand,
+	}
+}
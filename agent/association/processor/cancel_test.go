@@ -0,0 +1,40 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package processor manage polling of associations, dispatching association to processor
+package processor
+
+import (
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	processormock "github.com/aws/amazon-ssm-agent/agent/framework/processor/mock"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCancelAssociationSubmitsCancelDocument(t *testing.T) {
+	sys = &systemStub{}
+	processorMock := &processormock.MockedProcessor{}
+	processorMock.On("Cancel", mock.Anything).Return()
+	processor := Processor{proc: processorMock}
+
+	processor.cancelAssociation(log.NewMockLog(), "b2f71a28-cbe1-4429-b848-26c7e1f5ad0d")
+
+	processorMock.AssertNumberOfCalls(t, "Cancel", 1)
+	docState := processorMock.Calls[0].Arguments.Get(0).(contracts.DocumentState)
+	assert.Equal(t, contracts.CancelCommand, docState.DocumentType)
+	assert.Equal(t, "b2f71a28-cbe1-4429-b848-26c7e1f5ad0d", docState.CancelInformation.CancelMessageID)
+	assert.NotEqual(t, docState.CancelInformation.CancelMessageID, docState.DocumentInformation.MessageID)
+}
@@ -0,0 +1,101 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package processor manage polling of associations, dispatching association to processor
+package processor
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/association/model"
+	"github.com/aws/amazon-ssm-agent/agent/association/schedulemanager"
+	"github.com/aws/amazon-ssm-agent/agent/association/schedulemanager/signal"
+	"github.com/aws/amazon-ssm-agent/agent/association/service"
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/framework/runpluginutil"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/times"
+)
+
+const dryRunMessageTemplate = "Dry run, %v out of %v step%v would run, %v would be skipped, %v would fail.%v"
+
+// validateStep allows unittest to override the step validation
+var validateStep = func(log log.T, pluginState contracts.PluginState) (bool, error) {
+	return runpluginutil.ValidateStep(log, pluginState, runpluginutil.SSMPluginRegistry)
+}
+
+// dryRunAssociation parses and validates the association and reports the steps that would run, it doesn't
+// mark the association pending, notify the hooks or submit the document
+func (p *Processor) dryRunAssociation(log log.T, scheduledAssociation *model.InstanceAssociation) {
+	associationID := *scheduledAssociation.Association.AssociationId
+	log = p.context.With("[associationId=" + associationID + "]").Log()
+
+	status := contracts.AssociationStatusSuccess
+	errorCode := contracts.AssociationErrorCodeNoError
+	var summary string
+	if docState, err := p.parseAssociation(scheduledAssociation); err != nil {
+		status = contracts.AssociationStatusFailed
+		errorCode = contracts.AssociationErrorCodeInvalidAssociation
+		summary = fmt.Sprintf("Dry run, encountered error while parsing association %v, %v", associationID, err)
+	} else {
+		var failed bool
+		if summary, failed = buildDryRunOutput(log, docState.InstancePluginsInformation); failed {
+			status = contracts.AssociationStatusFailed
+			errorCode = contracts.AssociationErrorCodeDryRunError
+		}
+	}
+
+	log.Infof("Dry run of association %v completed, %v", associationID, summary)
+	p.assocSvc.UpdateInstanceAssociationStatus(
+		log,
+		associationID,
+		*scheduledAssociation.Association.Name,
+		*scheduledAssociation.Association.InstanceId,
+		status,
+		errorCode,
+		times.ToIso8601UTC(time.Now()),
+		summary,
+		service.NoOutputUrl)
+
+	schedulemanager.UpdateNextScheduledDate(log, associationID)
+	signal.ExecuteAssociation(log)
+}
+
+// buildDryRunOutput builds the summary of the steps that would run, it returns true if any of the steps would fail
+func buildDryRunOutput(log log.T, plugins []contracts.PluginState) (summary string, failed bool) {
+	plural := ""
+	if len(plugins) > 1 {
+		plural = "s"
+	}
+
+	var run, skipped, failures int
+	var buffer bytes.Buffer
+	for _, plugin := range plugins {
+		isSkipped, err := validateStep(log, plugin)
+		switch {
+		case err != nil:
+			failures++
+			buffer.WriteString(fmt.Sprintf("\nThe step %v would fail because %v.", plugin.Id, err))
+		case isSkipped:
+			skipped++
+			buffer.WriteString(fmt.Sprintf("\nThe step %v (%v) would be skipped.", plugin.Id, plugin.Name))
+		default:
+			run++
+			buffer.WriteString(fmt.Sprintf("\nThe step %v (%v) would run.", plugin.Id, plugin.Name))
+		}
+	}
+
+	return fmt.Sprintf(dryRunMessageTemplate, run, len(plugins), plural, skipped, failures, buffer.String()), failures > 0
+}
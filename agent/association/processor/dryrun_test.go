@@ -0,0 +1,64 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package processor manage polling of associations, dispatching association to processor
+package processor
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func stubValidateStep(results map[string]error, skipped map[string]bool) func() {
+	original := validateStep
+	validateStep = func(log log.T, pluginState contracts.PluginState) (bool, error) {
+		return skipped[pluginState.Id], results[pluginState.Id]
+	}
+	return func() { validateStep = original }
+}
+
+func TestBuildDryRunOutputAllStepsRun(t *testing.T) {
+	defer stubValidateStep(nil, nil)()
+	plugins := []contracts.PluginState{
+		{Id: "step1", Name: "aws:runShellScript"},
+		{Id: "step2", Name: "aws:runShellScript"},
+	}
+
+	summary, failed := buildDryRunOutput(log.NewMockLog(), plugins)
+
+	assert.False(t, failed)
+	assert.Equal(t, "Dry run, 2 out of 2 steps would run, 0 would be skipped, 0 would fail."+
+		"\nThe step step1 (aws:runShellScript) would run."+
+		"\nThe step step2 (aws:runShellScript) would run.", summary)
+}
+
+func TestBuildDryRunOutputWithSkippedAndFailedSteps(t *testing.T) {
+	defer stubValidateStep(
+		map[string]error{"step2": errors.New("plugin not found")},
+		map[string]bool{"step1": true})()
+	plugins := []contracts.PluginState{
+		{Id: "step1", Name: "aws:runPowerShellScript"},
+		{Id: "step2", Name: "aws:unknown"},
+	}
+
+	summary, failed := buildDryRunOutput(log.NewMockLog(), plugins)
+
+	assert.True(t, failed)
+	assert.Equal(t, "Dry run, 0 out of 2 steps would run, 1 would be skipped, 1 would fail."+
+		"\nThe step step1 (aws:runPowerShellScript) would be skipped."+
+		"\nThe step step2 would fail because plugin not found.", summary)
+}
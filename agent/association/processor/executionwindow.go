@@ -0,0 +1,66 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package processor manage polling of associations, dispatching association to processor
+package processor
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/association/model"
+	"github.com/aws/amazon-ssm-agent/agent/association/schedulemanager"
+	"github.com/aws/amazon-ssm-agent/agent/association/schedulemanager/signal"
+	"github.com/aws/amazon-ssm-agent/agent/association/service"
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/times"
+)
+
+// deferOutsideExecutionWindow defers the association to the start of the configured execution window
+// if it is due outside of the window, it returns true if the association is deferred
+func (p *Processor) deferOutsideExecutionWindow(log log.T, assoc *model.InstanceAssociation) bool {
+	configuredWindow := p.context.AppConfig().Ssm.AssociationExecutionWindow
+	if configuredWindow == "" {
+		return false
+	}
+
+	window, err := times.ParseDailyWindow(configuredWindow)
+	if err != nil {
+		log.Errorf("Ignoring association execution window, %v", err)
+		return false
+	}
+
+	now := time.Now()
+	if window.Contains(now) {
+		return false
+	}
+
+	deferredDate := window.NextStart(now)
+	log.Infof("Association %v is due outside of the execution window %v, deferring it to %v",
+		*assoc.Association.AssociationId, configuredWindow, deferredDate)
+	p.assocSvc.UpdateInstanceAssociationStatus(
+		log,
+		*assoc.Association.AssociationId,
+		*assoc.Association.Name,
+		*assoc.Association.InstanceId,
+		contracts.AssociationStatusDeferred,
+		contracts.AssociationErrorCodeNoError,
+		times.ToIso8601UTC(now),
+		fmt.Sprintf(contracts.AssociationDeferredMessage, times.ToIso8601UTC(deferredDate)),
+		service.NoOutputUrl)
+
+	schedulemanager.Defer(log, *assoc.Association.AssociationId, deferredDate)
+	signal.ExecuteAssociation(log)
+	return true
+}
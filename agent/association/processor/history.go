@@ -0,0 +1,100 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package processor manage polling of associations, dispatching association to processor
+package processor
+
+import (
+	"sort"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/association/recorder"
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/times"
+)
+
+// recordExecution is assigned to a variable to allow unit tests to override it
+var recordExecution = recorder.RecordExecution
+
+// recordExecutionHistory adds the completed association execution to the local execution history
+func recordExecutionHistory(log log.T, res contracts.DocumentResult, maxRecords int) {
+	instanceID, err := sys.InstanceID()
+	if err != nil {
+		log.Error("failed to load instance id ", err)
+		return
+	}
+
+	if err = recordExecution(instanceID, buildExecutionRecord(res), maxRecords); err != nil {
+		log.Errorf("Failed to record the execution history of association %v, %v", res.AssociationID, err)
+	}
+}
+
+// buildExecutionRecord converts the document result to an execution history record,
+// the execution spans from the first plugin start to the last plugin end
+func buildExecutionRecord(res contracts.DocumentResult) recorder.AssociationExecution {
+	execution := recorder.AssociationExecution{
+		AssociationID:   res.AssociationID,
+		DocumentName:    res.DocumentName,
+		DocumentVersion: res.DocumentVersion,
+		ExecutionID:     res.MessageID,
+		Status:          string(res.Status),
+		PluginResults:   []recorder.PluginExecution{},
+	}
+
+	var startDateTime, endDateTime time.Time
+	pluginResults := make([]*contracts.PluginResult, 0, len(res.PluginResults))
+	for _, pluginResult := range res.PluginResults {
+		pluginResults = append(pluginResults, pluginResult)
+		if !pluginResult.StartDateTime.IsZero() && (startDateTime.IsZero() || pluginResult.StartDateTime.Before(startDateTime)) {
+			startDateTime = pluginResult.StartDateTime
+		}
+		if pluginResult.EndDateTime.After(endDateTime) {
+			endDateTime = pluginResult.EndDateTime
+		}
+	}
+	sort.Sort(byStartDateTime(pluginResults))
+
+	for _, pluginResult := range pluginResults {
+		execution.PluginResults = append(execution.PluginResults, recorder.PluginExecution{
+			PluginID:   pluginResult.PluginID,
+			PluginName: pluginResult.PluginName,
+			Status:     string(pluginResult.Status),
+			Code:       pluginResult.Code,
+			Error:      pluginResult.Error,
+		})
+	}
+
+	if !startDateTime.IsZero() {
+		execution.StartDateTime = times.ToIso8601UTC(startDateTime)
+	}
+	if !endDateTime.IsZero() {
+		execution.EndDateTime = times.ToIso8601UTC(endDateTime)
+	}
+	if !startDateTime.IsZero() && endDateTime.After(startDateTime) {
+		execution.DurationSeconds = endDateTime.Sub(startDateTime).Seconds()
+	}
+	return execution
+}
+
+// byStartDateTime sorts plugin results in the order the plugins were started
+type byStartDateTime []*contracts.PluginResult
+
+func (r byStartDateTime) Len() int      { return len(r) }
+func (r byStartDateTime) Swap(i, j int) { r[i], r[j] = r[j], r[i] }
+func (r byStartDateTime) Less(i, j int) bool {
+	if r[i].StartDateTime.Equal(r[j].StartDateTime) {
+		return r[i].PluginID < r[j].PluginID
+	}
+	return r[i].StartDateTime.Before(r[j].StartDateTime)
+}
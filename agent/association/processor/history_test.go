@@ -0,0 +1,92 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package processor manage polling of associations, dispatching association to processor
+package processor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/association/recorder"
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildExecutionRecord(t *testing.T) {
+	start := time.Date(2017, 6, 1, 10, 0, 0, 0, time.UTC)
+	res := contracts.DocumentResult{
+		AssociationID:   "assocID",
+		DocumentName:    "AWS-RunShellScript",
+		DocumentVersion: "1",
+		MessageID:       "assocID.runID",
+		Status:          contracts.ResultStatusFailed,
+		PluginResults: map[string]*contracts.PluginResult{
+			"second": {
+				PluginID:      "second",
+				PluginName:    "aws:runShellScript",
+				Status:        contracts.ResultStatusFailed,
+				Code:          1,
+				Error:         "failed to run commands",
+				StartDateTime: start.Add(30 * time.Second),
+				EndDateTime:   start.Add(90 * time.Second),
+			},
+			"first": {
+				PluginID:      "first",
+				PluginName:    "aws:runShellScript",
+				Status:        contracts.ResultStatusSuccess,
+				StartDateTime: start,
+				EndDateTime:   start.Add(30 * time.Second),
+			},
+		},
+	}
+
+	execution := buildExecutionRecord(res)
+
+	assert.Equal(t, "assocID", execution.AssociationID)
+	assert.Equal(t, "assocID.runID", execution.ExecutionID)
+	assert.Equal(t, string(contracts.ResultStatusFailed), execution.Status)
+	assert.Equal(t, "2017-06-01T10:00:00.000Z", execution.StartDateTime)
+	assert.Equal(t, "2017-06-01T10:01:30.000Z", execution.EndDateTime)
+	assert.Equal(t, float64(90), execution.DurationSeconds)
+	assert.Equal(t, []recorder.PluginExecution{
+		{PluginID: "first", PluginName: "aws:runShellScript", Status: string(contracts.ResultStatusSuccess)},
+		{PluginID: "second", PluginName: "aws:runShellScript", Status: string(contracts.ResultStatusFailed), Code: 1, Error: "failed to run commands"},
+	}, execution.PluginResults)
+}
+
+func TestBuildExecutionRecordWithoutPlugins(t *testing.T) {
+	execution := buildExecutionRecord(contracts.DocumentResult{AssociationID: "assocID", Status: contracts.ResultStatusSkipped})
+
+	assert.Equal(t, string(contracts.ResultStatusSkipped), execution.Status)
+	assert.Empty(t, execution.StartDateTime)
+	assert.Equal(t, float64(0), execution.DurationSeconds)
+	assert.Empty(t, execution.PluginResults)
+}
+
+func TestRecordExecutionHistory(t *testing.T) {
+	sys = &systemStub{}
+	var recorded []recorder.AssociationExecution
+	recordExecution = func(instanceID string, execution recorder.AssociationExecution, maxRecords int) error {
+		assert.Equal(t, 10, maxRecords)
+		recorded = append(recorded, execution)
+		return nil
+	}
+	defer func() { recordExecution = recorder.RecordExecution }()
+
+	recordExecutionHistory(log.NewMockLog(), contracts.DocumentResult{AssociationID: "assocID", Status: contracts.ResultStatusSuccess}, 10)
+
+	assert.Equal(t, 1, len(recorded))
+	assert.Equal(t, "assocID", recorded[0].AssociationID)
+}
@@ -0,0 +1,49 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package processor manage polling of associations, dispatching association to processor
+package processor
+
+import (
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/association/hook"
+	"github.com/aws/amazon-ssm-agent/agent/framework/resultsink"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/times"
+)
+
+// notifyHook and publishResult are assigned to variables to allow unit tests to override them
+var (
+	notifyHook    = hook.Notify
+	publishResult = resultsink.Publish
+)
+
+// notifyHooks sends the association status transition to the locally configured hooks
+func (p *Processor) notifyHooks(log log.T, associationID, documentName, instanceID, status, summary string) {
+	config := p.context.AppConfig()
+	notifyHook(
+		log,
+		hook.Config{
+			URL:    config.Ssm.AssociationHookUrl,
+			Script: config.Ssm.AssociationHookScript,
+		},
+		hook.Event{
+			AssociationID: associationID,
+			DocumentName:  documentName,
+			InstanceID:    instanceID,
+			Status:        status,
+			Summary:       summary,
+			EventTime:     times.ToIso8601UTC(time.Now()),
+		})
+}
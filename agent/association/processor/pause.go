@@ -0,0 +1,39 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package processor manage polling of associations, dispatching association to processor
+package processor
+
+import (
+	"github.com/aws/amazon-ssm-agent/agent/association/control"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+)
+
+// isPaused is assigned to a variable to allow unit tests to override it
+var isPaused = control.IsPaused
+
+// isProcessingPaused returns true while association processing is paused locally,
+// due associations stay pending until processing is resumed
+func isProcessingPaused(log log.T) bool {
+	instanceID, err := sys.InstanceID()
+	if err != nil {
+		log.Error("failed to load instance id ", err)
+		return false
+	}
+
+	if isPaused(instanceID) {
+		log.Info("Association processing is paused, due associations will run once it is resumed")
+		return true
+	}
+	return false
+}
@@ -0,0 +1,33 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package processor manage polling of associations, dispatching association to processor
+package processor
+
+import (
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsProcessingPaused(t *testing.T) {
+	sys = &systemStub{}
+	defer func(original func(string) bool) { isPaused = original }(isPaused)
+
+	isPaused = func(instanceID string) bool { return true }
+	assert.True(t, isProcessingPaused(log.NewMockLog()))
+
+	isPaused = func(instanceID string) bool { return false }
+	assert.False(t, isProcessingPaused(log.NewMockLog()))
+}
@@ -23,6 +23,7 @@ import (
 	"time"
 
 	"github.com/aws/amazon-ssm-agent/agent/association/cache"
+	"github.com/aws/amazon-ssm-agent/agent/association/control"
 	"github.com/aws/amazon-ssm-agent/agent/association/frequentcollector"
 	"github.com/aws/amazon-ssm-agent/agent/association/model"
 	"github.com/aws/amazon-ssm-agent/agent/association/schedulemanager"
@@ -33,6 +34,7 @@ import (
 	"github.com/aws/amazon-ssm-agent/agent/context"
 	"github.com/aws/amazon-ssm-agent/agent/contracts"
 	"github.com/aws/amazon-ssm-agent/agent/framework/processor"
+	"github.com/aws/amazon-ssm-agent/agent/framework/resultsink"
 	"github.com/aws/amazon-ssm-agent/agent/jsonutil"
 	"github.com/aws/amazon-ssm-agent/agent/log"
 	"github.com/aws/amazon-ssm-agent/agent/platform"
@@ -59,6 +61,10 @@ type Processor struct {
 	proc               processor.Processor
 	resChan            chan contracts.DocumentResult
 	onBoot             bool
+	statusBatcher      *statusBatcher
+	cancelWatcher      *control.Watcher
+	resumeWatcher      *control.Watcher
+	runWatcher         *control.Watcher
 }
 
 var lock sync.RWMutex
@@ -91,6 +97,10 @@ func NewAssociationProcessor(context context.T) *Processor {
 		agentInfo:          &agentInfo,
 		proc:               proc,
 		onBoot:             true,
+		statusBatcher: newStatusBatcher(
+			assocContext.Log(),
+			assocSvc,
+			time.Duration(config.Ssm.AssociationStatusFlushIntervalSeconds)*time.Second),
 	}
 }
 
@@ -112,6 +122,9 @@ func (p *Processor) ModuleExecute(context context.T) {
 }
 func (p *Processor) ModuleRequestStop(stopType contracts.StopType) (err error) {
 	assocScheduler.Stop(p.pollJob)
+	p.cancelWatcher.Stop()
+	p.resumeWatcher.Stop()
+	p.runWatcher.Stop()
 	signal.Stop()
 	p.proc.Stop(stopType)
 	return nil
@@ -138,6 +151,24 @@ func (p *Processor) InitializeAssociationProcessor() {
 	log.Info("Initializing association scheduling service")
 	signal.InitializeAssociationSignalService(log, p.runScheduledAssociation)
 	log.Info("Association scheduling service initialized")
+
+	signal.InitializeCancelSignalService(log, p.cancelAssociation)
+	if instanceID, err := sys.InstanceID(); err != nil {
+		log.Errorf("failed to load instance id, local cancel requests are disabled, %v", err)
+	} else if p.cancelWatcher, err = control.WatchCancelRequests(log, instanceID, func(associationID string) {
+		signal.CancelAssociation(log, associationID)
+	}); err != nil {
+		log.Errorf("failed to watch local cancel requests, %v", err)
+	} else if p.resumeWatcher, err = control.WatchResume(log, instanceID, func() {
+		// drain the associations which became due while processing was paused
+		signal.ExecuteAssociation(log)
+	}); err != nil {
+		log.Errorf("failed to watch local resume requests, %v", err)
+	} else if p.runWatcher, err = control.WatchRunRequests(log, instanceID, func(associationID string) {
+		runAssociationNow(log, associationID)
+	}); err != nil {
+		log.Errorf("failed to watch local run requests, %v", err)
+	}
 }
 
 // SetPollJob represents setter for PollJob
@@ -296,6 +327,11 @@ func (p *Processor) runScheduledAssociation(log log.T) {
 	// stop previous wait timer if there is scheduled association
 	signal.StopWaitTimerForNextScheduledAssociation()
 
+	if p.context.AppConfig().Ssm.AssociationDryRun {
+		p.dryRunAssociation(log, scheduledAssociation)
+		return
+	}
+
 	if schedulemanager.IsAssociationInProgress(*scheduledAssociation.Association.AssociationId) {
 		log.Debug("runScheduledAssociation is InProgress")
 		if isAssociationTimedOut(scheduledAssociation) {
@@ -324,6 +360,14 @@ func (p *Processor) runScheduledAssociation(log log.T) {
 		return
 	}
 
+	if p.deferOutsideExecutionWindow(log, scheduledAssociation) {
+		return
+	}
+
+	if isProcessingPaused(log) {
+		return
+	}
+
 	log.Debugf("Update association %v to pending ", *scheduledAssociation.Association.AssociationId)
 	// Update association status to pending
 	p.assocSvc.UpdateInstanceAssociationStatus(
@@ -336,6 +380,13 @@ func (p *Processor) runScheduledAssociation(log log.T) {
 		times.ToIso8601UTC(time.Now()),
 		contracts.AssociationPendingMessage,
 		service.NoOutputUrl)
+	p.notifyHooks(
+		log,
+		*scheduledAssociation.Association.AssociationId,
+		*scheduledAssociation.Association.Name,
+		*scheduledAssociation.Association.InstanceId,
+		contracts.AssociationStatusPending,
+		contracts.AssociationPendingMessage)
 
 	var docState *contracts.DocumentState
 	if docState, err = p.parseAssociation(scheduledAssociation); err != nil {
@@ -363,8 +414,12 @@ func (p *Processor) runScheduledAssociation(log log.T) {
 		return
 	}
 
-	updatePluginAssociationInstances(*scheduledAssociation.Association.AssociationId, docState)
 	log = p.context.With("[associationId=" + docState.DocumentInformation.AssociationID + "]").Log()
+	if p.context.AppConfig().Ssm.AssociationSkipUnchanged && p.skipUnchangedAssociation(log, docState) {
+		return
+	}
+
+	updatePluginAssociationInstances(*scheduledAssociation.Association.AssociationId, docState)
 	instanceID, _ := sys.InstanceID()
 	p.assocSvc.UpdateInstanceAssociationStatus(
 		log,
@@ -376,6 +431,13 @@ func (p *Processor) runScheduledAssociation(log log.T) {
 		times.ToIso8601UTC(time.Now()),
 		contracts.AssociationInProgressMessage,
 		service.NoOutputUrl)
+	p.notifyHooks(
+		log,
+		docState.DocumentInformation.AssociationID,
+		docState.DocumentInformation.DocumentName,
+		instanceID,
+		contracts.AssociationStatusInProgress,
+		contracts.AssociationInProgressMessage)
 
 	log.Debug("runScheduledAssociation submitting document")
 
@@ -478,16 +540,25 @@ func (r *Processor) pluginExecutionReport(
 
 	executionSummary, outputUrl := buildOutput(runtimeStatuses, totalNumberOfPlugins)
 
-	r.assocSvc.UpdateInstanceAssociationStatus(
-		log,
+	if r.statusBatcher == nil {
+		r.assocSvc.UpdateInstanceAssociationStatus(
+			log,
+			associationID,
+			"",
+			instanceID,
+			contracts.AssociationStatusInProgress,
+			contracts.AssociationErrorCodeNoError,
+			times.ToIso8601UTC(time.Now()),
+			executionSummary,
+			outputUrl)
+		return
+	}
+
+	// coalesce the progress updates to avoid throttling on documents with many plugins
+	r.statusBatcher.update(
 		associationID,
-		"",
-		instanceID,
-		contracts.AssociationStatusInProgress,
-		contracts.AssociationErrorCodeNoError,
-		times.ToIso8601UTC(time.Now()),
-		executionSummary,
-		outputUrl)
+		newPluginStatusUpdate(instanceID, executionSummary, outputUrl),
+		isSignificantUpdate(pluginID, runtimeStatuses, totalNumberOfPlugins))
 }
 
 // associationExecutionReport update the status for association
@@ -521,6 +592,7 @@ func (r *Processor) associationExecutionReport(
 		times.ToIso8601UTC(time.Now()),
 		executionSummary,
 		outputUrl)
+	r.notifyHooks(log, associationID, documentName, instanceID, associationStatus, executionSummary)
 
 	r.complianceUploader.UpdateAssociationCompliance(
 		associationID,
@@ -531,14 +603,26 @@ func (r *Processor) associationExecutionReport(
 		time.Now().UTC())
 }
 
+// publishPluginResult publishes the result of the plugin that completed to the result sink configured for the agent
+func (r *Processor) publishPluginResult(log log.T, res contracts.DocumentResult) {
+	instanceID, _ := sys.InstanceID()
+	if record, ok := resultsink.NewRecord(instanceID, res); ok {
+		publishResult(log, resultsink.ConfigFromAppConfig(r.context.AppConfig()), record)
+	}
+}
+
 func (r *Processor) listenToResponses() {
 	log := r.context.Log()
 	for res := range r.resChan {
 		if res.LastPlugin != "" {
 			log.Infof("update association status upon plugin $v completion", res.LastPlugin)
+			r.publishPluginResult(log, res)
 			r.pluginExecutionReport(log, res.AssociationID, res.LastPlugin, res.PluginResults, res.NPlugins)
 		}
 		if res.Status == contracts.ResultStatusSuccessAndReboot {
+			if r.statusBatcher != nil {
+				r.statusBatcher.flush()
+			}
 			signal.StopExecutionSignal()
 			return
 		}
@@ -546,6 +630,16 @@ func (r *Processor) listenToResponses() {
 		if res.LastPlugin == "" {
 			log.Debug("Association execution completion: ", res.AssociationID)
 			log.Debug("Association execution status is ", res.Status)
+			if r.statusBatcher != nil {
+				r.statusBatcher.discard(res.AssociationID)
+			}
+			if maxRecords := r.context.AppConfig().Ssm.AssociationHistoryMaxRecords; maxRecords > 0 {
+				recordExecutionHistory(log, res, maxRecords)
+			}
+			if res.Status == contracts.ResultStatusFailed && r.retryAssociation(log, res) {
+				signal.ExecuteAssociation(log)
+				continue
+			}
 			if res.Status == contracts.ResultStatusFailed {
 				r.associationExecutionReport(
 					log,
@@ -557,6 +651,29 @@ func (r *Processor) listenToResponses() {
 					contracts.AssociationErrorCodeExecutionError,
 					contracts.AssociationStatusFailed)
 
+			} else if res.Status == contracts.ResultStatusCancelled {
+				// association canceled through a local cancel request
+				r.associationExecutionReport(
+					log,
+					res.AssociationID,
+					res.DocumentName,
+					res.DocumentVersion,
+					res.PluginResults,
+					res.NPlugins,
+					contracts.AssociationErrorCodeCancelledError,
+					contracts.AssociationStatusFailed)
+
+			} else if res.ErrorCode == contracts.AssociationErrorCodeExecutionTimedOutError {
+				// document stopped by the document execution limit
+				r.associationExecutionReport(
+					log,
+					res.AssociationID,
+					res.DocumentName,
+					res.DocumentVersion,
+					res.PluginResults,
+					res.NPlugins,
+					res.ErrorCode,
+					contracts.AssociationStatusTimedOut)
 			} else if res.Status == contracts.ResultStatusSuccess ||
 				res.Status == contracts.AssociationStatusTimedOut ||
 				res.Status == contracts.ResultStatusSkipped {
@@ -584,6 +701,9 @@ func (r *Processor) listenToResponses() {
 				)
 			}
 			instanceID, _ := sys.InstanceID()
+			if r.context.AppConfig().Ssm.AssociationSkipUnchanged {
+				recordStateHash(log, instanceID, res.AssociationID, res.Status)
+			}
 			//clean association logs once the document state is moved to completed
 			//clean completed document state files and orchestration dirs. Takes care of only files generated by association in the folder
 			go assocBookkeeping.DeleteOldOrchestrationDirectories(log,
@@ -592,6 +712,7 @@ func (r *Processor) listenToResponses() {
 				r.context.AppConfig().Ssm.RunCommandLogsRetentionDurationHours,
 				r.context.AppConfig().Ssm.AssociationLogsRetentionDurationHours)
 			//TODO move this part to service
+			schedulemanager.ClearRetries(res.AssociationID)
 			schedulemanager.UpdateNextScheduledDate(log, res.AssociationID)
 			signal.ExecuteAssociation(log)
 
@@ -600,6 +721,30 @@ func (r *Processor) listenToResponses() {
 	}
 }
 
+// retryAssociation schedules a retry of the failed association if the retry policy allows it,
+// the association is reported Pending until its retries are exhausted
+func (r *Processor) retryAssociation(log log.T, res contracts.DocumentResult) bool {
+	policy := newRetryPolicy(r.context.AppConfig().Ssm)
+	attempts := schedulemanager.RetryAttempts(res.AssociationID)
+	if !policy.shouldRetry(attempts, res.PluginResults) {
+		return false
+	}
+
+	delay := policy.delay(attempts)
+	log.Infof("Association %v failed, retry %v of %v in %v", res.AssociationID, attempts+1, policy.maxAttempts, delay)
+	r.associationExecutionReport(
+		log,
+		res.AssociationID,
+		res.DocumentName,
+		res.DocumentVersion,
+		res.PluginResults,
+		res.NPlugins,
+		contracts.AssociationErrorCodeNoError,
+		contracts.AssociationStatusPending)
+	schedulemanager.ScheduleRetry(log, res.AssociationID, time.Now().Add(delay))
+	return true
+}
+
 // buildOutput build the output message for association update
 // TODO: totalNumberOfPlugins is no longer needed, we can get the same value from len(runtimeStatuses)
 func buildOutput(runtimeStatuses map[string]*contracts.PluginRuntimeStatus, totalNumberOfPlugins int) (outputSummary, outputUrl string) {
@@ -68,8 +68,8 @@ func (p *Processor) ProcessRefreshAssociation(log log.T, pluginRes *contracts.Pl
 	pluginRes.Code = out.GetExitCode()
 	pluginRes.Status = contracts.ResultStatusSuccess
 	pluginRes.Output = out.GetOutput()
-	pluginRes.StandardOutput = pluginutil.StringPrefix(out.GetStdout(), pluginConfig.MaxStdoutLength, pluginConfig.OutputTruncatedSuffix)
-	pluginRes.StandardError = pluginutil.StringPrefix(out.GetStderr(), pluginConfig.MaxStderrLength, pluginConfig.OutputTruncatedSuffix)
+	pluginRes.StandardOutput = pluginutil.TruncateString(out.GetStdout(), pluginConfig.MaxStdoutLength, pluginConfig.OutputTruncatedSuffix, pluginConfig.TruncationStrategy)
+	pluginRes.StandardError = pluginutil.TruncateString(out.GetStderr(), pluginConfig.MaxStderrLength, pluginConfig.OutputTruncatedSuffix, pluginConfig.TruncationStrategy)
 }
 
 // refreshAssociation executes one the command and returns their output.
@@ -0,0 +1,74 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package processor manage polling of associations, dispatching association to processor
+package processor
+
+import (
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+)
+
+// retryPolicy decides whether a failed association is retried and how long to wait before the retry
+type retryPolicy struct {
+	maxAttempts int
+	backoff     time.Duration
+	maxBackoff  time.Duration
+	exitCodes   []int
+}
+
+func newRetryPolicy(config appconfig.SsmCfg) retryPolicy {
+	return retryPolicy{
+		maxAttempts: config.AssociationRetryMaxAttempts,
+		backoff:     time.Duration(config.AssociationRetryBackoffSeconds) * time.Second,
+		maxBackoff:  time.Duration(config.AssociationRetryMaxBackoffSeconds) * time.Second,
+		exitCodes:   config.AssociationRetryOnExitCodes,
+	}
+}
+
+// shouldRetry returns true if the association failed with the given plugin results should be retried,
+// given the number of retries already made
+func (policy retryPolicy) shouldRetry(attempts int, outputs map[string]*contracts.PluginResult) bool {
+	if attempts >= policy.maxAttempts {
+		return false
+	}
+	if len(policy.exitCodes) == 0 {
+		return true
+	}
+
+	for _, output := range outputs {
+		if output.Status != contracts.ResultStatusFailed {
+			continue
+		}
+		for _, code := range policy.exitCodes {
+			if output.Code == code {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// delay returns the exponential backoff before the retry following the given number of retries
+func (policy retryPolicy) delay(attempts int) time.Duration {
+	delay := policy.backoff
+	for i := 0; i < attempts && delay < policy.maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > policy.maxBackoff {
+		delay = policy.maxBackoff
+	}
+	return delay
+}
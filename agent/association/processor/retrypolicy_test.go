@@ -0,0 +1,73 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package processor manage polling of associations, dispatching association to processor
+package processor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/stretchr/testify/assert"
+)
+
+func failedOutputs(code int) map[string]*contracts.PluginResult {
+	return map[string]*contracts.PluginResult{
+		"aws:runShellScript": {
+			Status: contracts.ResultStatusFailed,
+			Code:   code,
+		},
+	}
+}
+
+func TestRetryPolicyIsDisabledByDefault(t *testing.T) {
+	policy := newRetryPolicy(appconfig.DefaultConfig().Ssm)
+
+	assert.False(t, policy.shouldRetry(0, failedOutputs(1)))
+}
+
+func TestRetryPolicyRetriesUntilMaxAttempts(t *testing.T) {
+	config := appconfig.DefaultConfig().Ssm
+	config.AssociationRetryMaxAttempts = 2
+	policy := newRetryPolicy(config)
+
+	assert.True(t, policy.shouldRetry(0, failedOutputs(1)))
+	assert.True(t, policy.shouldRetry(1, failedOutputs(1)))
+	assert.False(t, policy.shouldRetry(2, failedOutputs(1)))
+}
+
+func TestRetryPolicyRetriesOnlyConfiguredExitCodes(t *testing.T) {
+	config := appconfig.DefaultConfig().Ssm
+	config.AssociationRetryMaxAttempts = 3
+	config.AssociationRetryOnExitCodes = []int{75, 111}
+	policy := newRetryPolicy(config)
+
+	assert.True(t, policy.shouldRetry(0, failedOutputs(75)))
+	assert.True(t, policy.shouldRetry(0, failedOutputs(111)))
+	assert.False(t, policy.shouldRetry(0, failedOutputs(1)))
+}
+
+func TestRetryPolicyDelayBacksOffExponentially(t *testing.T) {
+	config := appconfig.DefaultConfig().Ssm
+	config.AssociationRetryBackoffSeconds = 10
+	config.AssociationRetryMaxBackoffSeconds = 60
+	policy := newRetryPolicy(config)
+
+	assert.Equal(t, 10*time.Second, policy.delay(0))
+	assert.Equal(t, 20*time.Second, policy.delay(1))
+	assert.Equal(t, 40*time.Second, policy.delay(2))
+	assert.Equal(t, 60*time.Second, policy.delay(3))
+	assert.Equal(t, 60*time.Second, policy.delay(30))
+}
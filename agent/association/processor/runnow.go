@@ -0,0 +1,41 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package processor manage polling of associations, dispatching association to processor
+package processor
+
+import (
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/association/control"
+	"github.com/aws/amazon-ssm-agent/agent/association/schedulemanager"
+	"github.com/aws/amazon-ssm-agent/agent/association/schedulemanager/signal"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+)
+
+// runAssociationNow runs the association immediately on local request, outside of its schedule.
+// InventoryRunRequest runs the association executing the software inventory plugin.
+func runAssociationNow(log log.T, associationID string) {
+	if associationID == control.InventoryRunRequest {
+		var found bool
+		if associationID, found = schedulemanager.AssociationRunningPlugin(appconfig.PluginNameAwsSoftwareInventory); !found {
+			log.Warn("Ignoring request to run the inventory association, no association runs the software inventory plugin")
+			return
+		}
+	}
+
+	if !schedulemanager.RunNow(log, associationID) {
+		log.Warnf("Ignoring request to run association %v, the association is not scheduled on the instance", associationID)
+		return
+	}
+	signal.ExecuteAssociation(log)
+}
@@ -0,0 +1,153 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package processor manage polling of associations, dispatching association to processor
+package processor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/association/recorder"
+	"github.com/aws/amazon-ssm-agent/agent/association/schedulemanager"
+	"github.com/aws/amazon-ssm-agent/agent/association/schedulemanager/signal"
+	"github.com/aws/amazon-ssm-agent/agent/association/service"
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/times"
+)
+
+// AssociationSkippedUnchangedMessage represents the summary message for association skipped as nothing changed
+const AssociationSkippedUnchangedMessage = "Association skipped, the document and its parameters are unchanged since the last successful execution"
+
+// stateHashRecorder allows unittest to override the persisted state hashes
+type stateHashRecorder interface {
+	SuccessfulStateHash(instanceID string, associationID string) string
+	UpdateSuccessfulStateHash(instanceID string, associationID string, hash string) error
+}
+
+type stateHashRecorderImpl struct{}
+
+func (stateHashRecorderImpl) SuccessfulStateHash(instanceID string, associationID string) string {
+	return recorder.SuccessfulStateHash(instanceID, associationID)
+}
+
+func (stateHashRecorderImpl) UpdateSuccessfulStateHash(instanceID string, associationID string, hash string) error {
+	return recorder.UpdateSuccessfulStateHash(instanceID, associationID, hash)
+}
+
+var stateHashes stateHashRecorder = stateHashRecorderImpl{}
+
+// submittedStateHashes keeps the state hash of the submitted associations until their execution completes
+var submittedStateHashes = make(map[string]string)
+var submittedStateHashesLock sync.Mutex
+
+// hashedPluginState is the part of the plugin state that decides what the plugin does
+type hashedPluginState struct {
+	Name          string
+	Id            string
+	Properties    interface{}
+	Settings      interface{}
+	Preconditions map[string][]string
+}
+
+// documentStateHash returns the hash of the resolved document, it does not depend on the run specific information
+func documentStateHash(docState *contracts.DocumentState) (string, error) {
+	plugins := make([]hashedPluginState, len(docState.InstancePluginsInformation))
+	for i, plugin := range docState.InstancePluginsInformation {
+		plugins[i] = hashedPluginState{
+			Name:          plugin.Name,
+			Id:            plugin.Id,
+			Properties:    plugin.Configuration.Properties,
+			Settings:      plugin.Configuration.Settings,
+			Preconditions: plugin.Configuration.Preconditions,
+		}
+	}
+
+	content, err := json.Marshal(struct {
+		DocumentName    string
+		DocumentVersion string
+		Plugins         []hashedPluginState
+	}{
+		DocumentName:    docState.DocumentInformation.DocumentName,
+		DocumentVersion: docState.DocumentInformation.DocumentVersion,
+		Plugins:         plugins,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// skipUnchangedAssociation reports the association as skipped if its document state is unchanged since its last
+// successful run, otherwise it remembers the state hash to record it once the run succeeds
+func (p *Processor) skipUnchangedAssociation(log log.T, docState *contracts.DocumentState) bool {
+	associationID := docState.DocumentInformation.AssociationID
+	instanceID := docState.DocumentInformation.InstanceID
+
+	hash, err := documentStateHash(docState)
+	if err != nil {
+		log.Errorf("Failed to hash the document state of association %v, %v", associationID, err)
+		return false
+	}
+
+	if hash != stateHashes.SuccessfulStateHash(instanceID, associationID) {
+		submittedStateHashesLock.Lock()
+		defer submittedStateHashesLock.Unlock()
+		submittedStateHashes[associationID] = hash
+		return false
+	}
+
+	log.Infof("Skipping association %v as it is unchanged since its last successful execution", associationID)
+	p.assocSvc.UpdateInstanceAssociationStatus(
+		log,
+		associationID,
+		docState.DocumentInformation.DocumentName,
+		instanceID,
+		string(contracts.ResultStatusSkipped),
+		contracts.AssociationErrorCodeNoError,
+		times.ToIso8601UTC(time.Now()),
+		AssociationSkippedUnchangedMessage,
+		service.NoOutputUrl)
+	p.complianceUploader.UpdateAssociationCompliance(
+		associationID,
+		instanceID,
+		docState.DocumentInformation.DocumentName,
+		docState.DocumentInformation.DocumentVersion,
+		contracts.AssociationStatusSuccess,
+		time.Now().UTC())
+
+	schedulemanager.UpdateNextScheduledDate(log, associationID)
+	signal.ExecuteAssociation(log)
+	return true
+}
+
+// recordStateHash records the state hash of the completed association if it succeeded, and forgets it otherwise
+func recordStateHash(log log.T, instanceID string, associationID string, status contracts.ResultStatus) {
+	submittedStateHashesLock.Lock()
+	hash, found := submittedStateHashes[associationID]
+	delete(submittedStateHashes, associationID)
+	submittedStateHashesLock.Unlock()
+
+	if status != contracts.ResultStatusSuccess || !found {
+		hash = ""
+	}
+	if err := stateHashes.UpdateSuccessfulStateHash(instanceID, associationID, hash); err != nil {
+		log.Errorf("Failed to record the document state hash of association %v, %v", associationID, err)
+	}
+}
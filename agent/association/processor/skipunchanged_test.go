@@ -0,0 +1,109 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package processor manage polling of associations, dispatching association to processor
+package processor
+
+import (
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/association/service"
+	complianceUploader "github.com/aws/amazon-ssm-agent/agent/compliance/uploader"
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type stateHashRecorderStub struct {
+	hashes map[string]string
+}
+
+func (s *stateHashRecorderStub) SuccessfulStateHash(instanceID string, associationID string) string {
+	return s.hashes[associationID]
+}
+
+func (s *stateHashRecorderStub) UpdateSuccessfulStateHash(instanceID string, associationID string, hash string) error {
+	if hash == "" {
+		delete(s.hashes, associationID)
+	} else {
+		s.hashes[associationID] = hash
+	}
+	return nil
+}
+
+func createSkipTestDocState(runID string, commands string) *contracts.DocumentState {
+	return &contracts.DocumentState{
+		DocumentInformation: contracts.DocumentInfo{
+			AssociationID:   "Id-Test",
+			InstanceID:      "i-1234567890",
+			DocumentName:    "Test-Association",
+			DocumentVersion: "1",
+			RunID:           runID,
+		},
+		InstancePluginsInformation: []contracts.PluginState{
+			{
+				Name: "aws:runShellScript",
+				Id:   "step1",
+				Configuration: contracts.Configuration{
+					Properties:             map[string]interface{}{"runCommand": []interface{}{commands}},
+					OrchestrationDirectory: "/var/lib/amazon/ssm/Id-Test/" + runID,
+				},
+			},
+		},
+	}
+}
+
+func TestDocumentStateHashIgnoresRunSpecificInformation(t *testing.T) {
+	first, err := documentStateHash(createSkipTestDocState("2017-01-01T00-00-00.000Z", "echo hello"))
+	assert.Nil(t, err)
+	second, err := documentStateHash(createSkipTestDocState("2017-01-02T00-00-00.000Z", "echo hello"))
+	assert.Nil(t, err)
+	changed, err := documentStateHash(createSkipTestDocState("2017-01-02T00-00-00.000Z", "echo world"))
+	assert.Nil(t, err)
+
+	assert.Equal(t, first, second)
+	assert.NotEqual(t, first, changed)
+}
+
+func TestSkipUnchangedAssociation(t *testing.T) {
+	processor := createProcessor()
+	svcMock := service.NewMockDefault()
+	complianceMock := complianceUploader.NewMockDefault()
+	processor.assocSvc = svcMock
+	processor.complianceUploader = complianceMock
+	recorderStub := &stateHashRecorderStub{hashes: make(map[string]string)}
+	originalStateHashes := stateHashes
+	stateHashes = recorderStub
+	defer func() { stateHashes = originalStateHashes }()
+
+	svcMock.On("UpdateInstanceAssociationStatus", mock.Anything, "Id-Test", "Test-Association", "i-1234567890", mock.Anything).Return()
+	complianceMock.On("UpdateAssociationCompliance", "Id-Test", "i-1234567890", "Test-Association", "1",
+		contracts.AssociationStatusSuccess, mock.Anything).Return(nil)
+	logger := log.NewMockLog()
+
+	// first run executes and records the hash once it succeeds
+	assert.False(t, processor.skipUnchangedAssociation(logger, createSkipTestDocState("run1", "echo hello")))
+	recordStateHash(logger, "i-1234567890", "Id-Test", contracts.ResultStatusSuccess)
+	assert.NotEmpty(t, recorderStub.hashes["Id-Test"])
+
+	// unchanged run is skipped
+	assert.True(t, processor.skipUnchangedAssociation(logger, createSkipTestDocState("run2", "echo hello")))
+	svcMock.AssertNumberOfCalls(t, "UpdateInstanceAssociationStatus", 1)
+	complianceMock.AssertNumberOfCalls(t, "UpdateAssociationCompliance", 1)
+
+	// changed run executes, and its failure forgets the hash
+	assert.False(t, processor.skipUnchangedAssociation(logger, createSkipTestDocState("run3", "echo world")))
+	recordStateHash(logger, "i-1234567890", "Id-Test", contracts.ResultStatusFailed)
+	assert.Empty(t, recorderStub.hashes["Id-Test"])
+}
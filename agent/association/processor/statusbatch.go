@@ -0,0 +1,124 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package processor manage polling of associations, dispatching association to processor
+package processor
+
+import (
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/association/service"
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/times"
+)
+
+// pluginStatusUpdate is the in progress status of an association waiting to be sent to the service
+type pluginStatusUpdate struct {
+	instanceID       string
+	executionDate    string
+	executionSummary string
+	outputUrl        string
+}
+
+// statusBatcher coalesces the in progress status updates of associations, only the latest update
+// of each association is sent to the service when the flush interval elapses
+type statusBatcher struct {
+	log      log.T
+	assocSvc service.T
+	interval time.Duration
+	lock     sync.Mutex
+	pending  map[string]pluginStatusUpdate
+	timer    *time.Timer
+}
+
+// newStatusBatcher returns a statusBatcher flushing at the given interval, a zero interval sends every update immediately
+func newStatusBatcher(log log.T, assocSvc service.T, interval time.Duration) *statusBatcher {
+	return &statusBatcher{
+		log:      log,
+		assocSvc: assocSvc,
+		interval: interval,
+		pending:  make(map[string]pluginStatusUpdate),
+	}
+}
+
+// update queues the in progress status of the association, significant updates flush all pending updates right away
+func (b *statusBatcher) update(associationID string, update pluginStatusUpdate, significant bool) {
+	b.lock.Lock()
+	b.pending[associationID] = update
+	if significant || b.interval <= 0 {
+		b.flushLocked()
+	} else if b.timer == nil {
+		b.timer = time.AfterFunc(b.interval, b.flush)
+	}
+	b.lock.Unlock()
+}
+
+// discard drops the pending update of the association, used once its final status supersedes the progress
+func (b *statusBatcher) discard(associationID string) {
+	b.lock.Lock()
+	delete(b.pending, associationID)
+	b.lock.Unlock()
+}
+
+// flush sends all pending updates to the service
+func (b *statusBatcher) flush() {
+	b.lock.Lock()
+	b.flushLocked()
+	b.lock.Unlock()
+}
+
+func (b *statusBatcher) flushLocked() {
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	for associationID, update := range b.pending {
+		b.assocSvc.UpdateInstanceAssociationStatus(
+			b.log,
+			associationID,
+			"",
+			update.instanceID,
+			contracts.AssociationStatusInProgress,
+			contracts.AssociationErrorCodeNoError,
+			update.executionDate,
+			update.executionSummary,
+			update.outputUrl)
+	}
+	b.pending = make(map[string]pluginStatusUpdate)
+}
+
+// isSignificantUpdate returns true when the plugin result should be reported without delay,
+// that is when the plugin did not succeed or it was the last plugin of the document
+func isSignificantUpdate(pluginID string, runtimeStatuses map[string]*contracts.PluginRuntimeStatus, totalNumberOfPlugins int) bool {
+	if status, ok := runtimeStatuses[pluginID]; ok {
+		if status.Status == contracts.ResultStatusFailed || status.Status == contracts.ResultStatusTimedOut {
+			return true
+		}
+	}
+	completed := len(filterByStatus(runtimeStatuses, func(status contracts.ResultStatus) bool {
+		return status != ""
+	}))
+	return completed >= totalNumberOfPlugins
+}
+
+// newPluginStatusUpdate returns the status update for the given execution summary stamped with the current time
+func newPluginStatusUpdate(instanceID, executionSummary, outputUrl string) pluginStatusUpdate {
+	return pluginStatusUpdate{
+		instanceID:       instanceID,
+		executionDate:    times.ToIso8601UTC(time.Now()),
+		executionSummary: executionSummary,
+		outputUrl:        outputUrl,
+	}
+}
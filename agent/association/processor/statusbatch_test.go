@@ -0,0 +1,92 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package processor manage polling of associations, dispatching association to processor
+package processor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/association/service"
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestStatusBatcherCoalescesUpdates(t *testing.T) {
+	svcMock := service.NewMockDefault()
+	svcMock.On("UpdateInstanceAssociationStatus", mock.Anything, "assocID", "", "i-1234567890", mock.Anything).Return()
+	batcher := newStatusBatcher(log.NewMockLog(), svcMock, time.Hour)
+
+	batcher.update("assocID", newPluginStatusUpdate("i-1234567890", "1 out of 3 plugins processed", ""), false)
+	batcher.update("assocID", newPluginStatusUpdate("i-1234567890", "2 out of 3 plugins processed", ""), false)
+	svcMock.AssertNotCalled(t, "UpdateInstanceAssociationStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	batcher.flush()
+	svcMock.AssertNumberOfCalls(t, "UpdateInstanceAssociationStatus", 1)
+}
+
+func TestStatusBatcherFlushesSignificantUpdates(t *testing.T) {
+	svcMock := service.NewMockDefault()
+	svcMock.On("UpdateInstanceAssociationStatus", mock.Anything, mock.Anything, "", "i-1234567890", mock.Anything).Return()
+	batcher := newStatusBatcher(log.NewMockLog(), svcMock, time.Hour)
+
+	batcher.update("assocID1", newPluginStatusUpdate("i-1234567890", "1 out of 3 plugins processed", ""), false)
+	batcher.update("assocID2", newPluginStatusUpdate("i-1234567890", "1 out of 1 plugin processed", ""), true)
+
+	svcMock.AssertNumberOfCalls(t, "UpdateInstanceAssociationStatus", 2)
+}
+
+func TestStatusBatcherFlushesOnInterval(t *testing.T) {
+	svcMock := service.NewMockDefault()
+	flushed := make(chan bool, 1)
+	svcMock.On("UpdateInstanceAssociationStatus", mock.Anything, "assocID", "", "i-1234567890", mock.Anything).Return().Run(func(mock.Arguments) {
+		flushed <- true
+	})
+	batcher := newStatusBatcher(log.NewMockLog(), svcMock, 10*time.Millisecond)
+
+	batcher.update("assocID", newPluginStatusUpdate("i-1234567890", "1 out of 3 plugins processed", ""), false)
+
+	select {
+	case <-flushed:
+	case <-time.After(time.Second):
+		assert.Fail(t, "pending update was not flushed")
+	}
+}
+
+func TestStatusBatcherDiscardsPendingUpdate(t *testing.T) {
+	svcMock := service.NewMockDefault()
+	batcher := newStatusBatcher(log.NewMockLog(), svcMock, time.Hour)
+
+	batcher.update("assocID", newPluginStatusUpdate("i-1234567890", "1 out of 3 plugins processed", ""), false)
+	batcher.discard("assocID")
+	batcher.flush()
+
+	svcMock.AssertNotCalled(t, "UpdateInstanceAssociationStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestIsSignificantUpdate(t *testing.T) {
+	runtimeStatuses := map[string]*contracts.PluginRuntimeStatus{
+		"step1": {Status: contracts.ResultStatusSuccess},
+		"step2": {Status: contracts.ResultStatusFailed},
+		"step3": {},
+	}
+
+	assert.False(t, isSignificantUpdate("step1", runtimeStatuses, 3))
+	assert.True(t, isSignificantUpdate("step2", runtimeStatuses, 3))
+
+	runtimeStatuses["step3"].Status = contracts.ResultStatusSuccess
+	assert.True(t, isSignificantUpdate("step3", runtimeStatuses, 3))
+}
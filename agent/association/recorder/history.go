@@ -0,0 +1,126 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package recorder
+
+import (
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/fileutil"
+	"github.com/aws/amazon-ssm-agent/agent/jsonutil"
+)
+
+// ExecutionHistoryName represents file recording the rolling history of association executions
+const ExecutionHistoryName = "ExecutionHistory.json"
+
+// AssociationExecution is the record of a completed association execution
+type AssociationExecution struct {
+	AssociationID   string
+	DocumentName    string
+	DocumentVersion string
+	ExecutionID     string
+	Status          string
+	StartDateTime   string
+	EndDateTime     string
+	DurationSeconds float64
+	PluginResults   []PluginExecution
+}
+
+// PluginExecution is the record of a plugin executed as part of an association execution
+type PluginExecution struct {
+	PluginID   string
+	PluginName string
+	Status     string
+	Code       int
+	Error      string
+}
+
+// executionHistory is the content of the execution history file, oldest execution first
+type executionHistory struct {
+	Executions []AssociationExecution
+}
+
+// RecordExecution appends the execution to the history, only the maxRecords latest executions are kept
+func RecordExecution(instanceID string, execution AssociationExecution, maxRecords int) error {
+	lock.Lock()
+	defer lock.Unlock()
+	var err error
+	var content string
+
+	history := loadExecutionHistory(instanceID)
+	history.Executions = append(history.Executions, execution)
+	if len(history.Executions) > maxRecords {
+		history.Executions = history.Executions[len(history.Executions)-maxRecords:]
+	}
+
+	location := getLocation(instanceID)
+	//verify if parent folder exist
+	if !fileutil.Exists(location) {
+		if err = fileutil.MakeDirs(location); err != nil {
+			return fmt.Errorf("cannot make directory of %v because: %v", location, err)
+		}
+	}
+
+	if content, err = jsonutil.Marshal(history); err != nil {
+		return err
+	}
+
+	if _, err = fileutil.WriteIntoFileWithPermissions(
+		getExecutionHistoryFileName(instanceID),
+		content,
+		os.FileMode(int(appconfig.ReadWriteAccess))); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ExecutionHistory returns up to maxResults recorded executions, latest first.
+// Executions of all associations are returned when associationID is empty, maxResults 0 returns all of them.
+func ExecutionHistory(instanceID string, associationID string, maxResults int) []AssociationExecution {
+	lock.RLock()
+	defer lock.RUnlock()
+
+	history := loadExecutionHistory(instanceID)
+	executions := []AssociationExecution{}
+	for i := len(history.Executions) - 1; i >= 0; i-- {
+		if maxResults > 0 && len(executions) == maxResults {
+			break
+		}
+		if associationID == "" || history.Executions[i].AssociationID == associationID {
+			executions = append(executions, history.Executions[i])
+		}
+	}
+	return executions
+}
+
+// loadExecutionHistory reads the recorded execution history, caller must hold lock
+func loadExecutionHistory(instanceID string) executionHistory {
+	var history executionHistory
+	fileName := getExecutionHistoryFileName(instanceID)
+	if !fileutil.Exists(fileName) {
+		return history
+	}
+
+	if err := jsonutil.UnmarshalFile(fileName, &history); err != nil {
+		return executionHistory{}
+	}
+	return history
+}
+
+// getExecutionHistoryFileName returns the full file name of the recorded execution history.
+func getExecutionHistoryFileName(instanceID string) string {
+	return path.Join(getLocation(instanceID), ExecutionHistoryName)
+}
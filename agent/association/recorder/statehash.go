@@ -0,0 +1,94 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package recorder
+
+import (
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/fileutil"
+	"github.com/aws/amazon-ssm-agent/agent/jsonutil"
+)
+
+// SuccessfulStateHashName represents file recording the document state hash of the last successful run of each association
+const SuccessfulStateHashName = "SuccessfulStateHash.json"
+
+// SuccessfulStateHash returns the document state hash recorded for the last successful run of the given association
+func SuccessfulStateHash(instanceID string, associationID string) string {
+	lock.RLock()
+	defer lock.RUnlock()
+
+	return loadStateHashes(instanceID)[associationID]
+}
+
+// UpdateSuccessfulStateHash persists the document state hash of the last successful run of the given association,
+// an empty hash removes the record
+func UpdateSuccessfulStateHash(instanceID string, associationID string, hash string) error {
+	lock.Lock()
+	defer lock.Unlock()
+	var err error
+	var content string
+
+	hashes := loadStateHashes(instanceID)
+	if hashes[associationID] == hash {
+		return nil
+	}
+
+	if hash == "" {
+		delete(hashes, associationID)
+	} else {
+		hashes[associationID] = hash
+	}
+
+	location := getLocation(instanceID)
+	//verify if parent folder exist
+	if !fileutil.Exists(location) {
+		if err = fileutil.MakeDirs(location); err != nil {
+			return fmt.Errorf("cannot make directory of %v because: %v", location, err)
+		}
+	}
+
+	if content, err = jsonutil.Marshal(hashes); err != nil {
+		return err
+	}
+
+	if _, err = fileutil.WriteIntoFileWithPermissions(
+		getStateHashFileName(instanceID),
+		content,
+		os.FileMode(int(appconfig.ReadWriteAccess))); err != nil {
+		return err
+	}
+	return nil
+}
+
+// loadStateHashes reads the recorded state hashes, caller must hold lock
+func loadStateHashes(instanceID string) map[string]string {
+	hashes := make(map[string]string)
+	fileName := getStateHashFileName(instanceID)
+	if !fileutil.Exists(fileName) {
+		return hashes
+	}
+
+	if err := jsonutil.UnmarshalFile(fileName, &hashes); err != nil || hashes == nil {
+		return make(map[string]string)
+	}
+	return hashes
+}
+
+// getStateHashFileName returns the full file name of the recorded state hashes.
+func getStateHashFileName(instanceID string) string {
+	return path.Join(getLocation(instanceID), SuccessfulStateHashName)
+}
@@ -0,0 +1,98 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package scheduleexpression
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gorhill/cronexpr"
+)
+
+const (
+	minCronFieldCount = 5
+	maxCronFieldCount = 7
+)
+
+// CronExpression represents a parsed cron expression evaluated in a given time zone.
+// The expression body supports the fields
+//   [seconds] minutes hours day-of-month month day-of-week [year] [time-zone]
+// where time-zone is an optional IANA time zone name such as America/New_York.
+// When no time zone is given the expression is evaluated in UTC.
+type CronExpression struct {
+	expression *cronexpr.Expression
+	location   *time.Location
+}
+
+// ParseCronExpression parses the body of a cron(...) schedule expression.
+func ParseCronExpression(cronLine string) (*CronExpression, error) {
+	fields := strings.Fields(cronLine)
+	location := time.UTC
+
+	if len(fields) > minCronFieldCount {
+		if loc, ok := parseTimeZone(fields[len(fields)-1]); ok {
+			location = loc
+			fields = fields[:len(fields)-1]
+		}
+	}
+
+	if len(fields) < minCronFieldCount || len(fields) > maxCronFieldCount {
+		return nil, fmt.Errorf("cron expression should have between %v and %v fields plus an optional time zone, found %v",
+			minCronFieldCount, maxCronFieldCount, len(fields))
+	}
+
+	expression, err := cronexpr.Parse(strings.Join(fields, " "))
+	if err != nil {
+		return nil, err
+	}
+
+	return &CronExpression{
+		expression: expression,
+		location:   location,
+	}, nil
+}
+
+// Next returns the closest time instant immediately following fromTime which matches the cron expression.
+// The returned time is in UTC. A zero time is returned if no matching time instant exists.
+func (expr *CronExpression) Next(fromTime time.Time) time.Time {
+	next := expr.expression.Next(fromTime.In(expr.location))
+	if next.IsZero() {
+		return next
+	}
+	return next.UTC()
+}
+
+// Location returns the time zone the cron expression is evaluated in.
+func (expr *CronExpression) Location() *time.Location {
+	return expr.location
+}
+
+// parseTimeZone returns the time zone named by field, if field is a time zone name.
+// Month and day names (JAN, MON-FRI, ...) are not zone names, so they are never mistaken for one.
+func parseTimeZone(field string) (*time.Location, bool) {
+	if field == "" || !isLetter(field[0]) || strings.EqualFold(field, "Local") {
+		return nil, false
+	}
+
+	location, err := time.LoadLocation(field)
+	if err != nil {
+		return nil, false
+	}
+	return location, true
+}
+
+func isLetter(c byte) bool {
+	return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
@@ -0,0 +1,102 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package scheduleexpression
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCronExpressionDefaultsToUTC(t *testing.T) {
+	// Act
+	expr, err := ParseCronExpression("0 9 * * ? *")
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, time.UTC, expr.Location())
+	from := time.Date(2017, time.March, 1, 10, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2017, time.March, 2, 9, 0, 0, 0, time.UTC), expr.Next(from))
+}
+
+func TestCronExpressionDayOfWeek(t *testing.T) {
+	// Act
+	expr, err := ParseCronExpression("30 2 ? * MON-FRI *")
+
+	// Assert
+	assert.Nil(t, err)
+	// Friday 2017-03-03 03:00 UTC, next weekday run is Monday
+	from := time.Date(2017, time.March, 3, 3, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2017, time.March, 6, 2, 30, 0, 0, time.UTC), expr.Next(from))
+}
+
+func TestCronExpressionLastDayOfMonth(t *testing.T) {
+	// Act
+	expr, err := ParseCronExpression("0 0 L * ? *")
+
+	// Assert
+	assert.Nil(t, err)
+	from := time.Date(2016, time.February, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2016, time.February, 29, 0, 0, 0, 0, time.UTC), expr.Next(from))
+}
+
+func TestCronExpressionWithTimeZone(t *testing.T) {
+	location, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("time zone database is not available")
+	}
+
+	// Act
+	expr, err := ParseCronExpression("0 9 ? * MON-FRI * America/New_York")
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, location.String(), expr.Location().String())
+	// 09:00 EST is 14:00 UTC
+	from := time.Date(2017, time.March, 1, 0, 0, 0, 0, time.UTC)
+	next := expr.Next(from)
+	assert.Equal(t, time.Date(2017, time.March, 1, 14, 0, 0, 0, time.UTC), next)
+	assert.Equal(t, time.UTC, next.Location())
+	// 09:00 EDT is 13:00 UTC after the daylight saving switch
+	from = time.Date(2017, time.March, 13, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2017, time.March, 13, 13, 0, 0, 0, time.UTC), expr.Next(from))
+}
+
+func TestCronExpressionWithUnknownTimeZone(t *testing.T) {
+	// Act
+	expr, err := ParseCronExpression("0 9 ? * MON-FRI * Mars/Olympus_Mons")
+
+	// Assert
+	assert.Nil(t, expr)
+	assert.NotNil(t, err)
+}
+
+func TestCronExpressionWithTooFewFields(t *testing.T) {
+	// Act
+	expr, err := ParseCronExpression("0 9 *")
+
+	// Assert
+	assert.Nil(t, expr)
+	assert.NotNil(t, err)
+}
+
+func TestCronExpressionWithTooManyFields(t *testing.T) {
+	// Act
+	expr, err := ParseCronExpression("0 0 9 * * ? * 2017")
+
+	// Assert
+	assert.Nil(t, expr)
+	assert.NotNil(t, err)
+}
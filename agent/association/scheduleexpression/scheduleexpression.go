@@ -23,7 +23,6 @@ import (
 
 	"github.com/aws/amazon-ssm-agent/agent/association/rateexpr"
 	"github.com/aws/amazon-ssm-agent/agent/log"
-	"github.com/gorhill/cronexpr"
 )
 
 const (
@@ -47,7 +46,7 @@ func CreateScheduleExpression(log log.T, scheduleExpression string) (ScheduleExp
 		}
 
 		cronExpression := scheduleExpression[len(expressionTypeCron)+1 : len(scheduleExpression)-1]
-		parsedCronExpression, err := cronexpr.Parse(cronExpression)
+		parsedCronExpression, err := ParseCronExpression(cronExpression)
 
 		if err == nil {
 			return parsedCronExpression, nil
@@ -131,3 +131,15 @@ func TestParseReturnsErrorWhenScheduleExpressionIsOfUnknownType(t *testing.T) {
 	assert.NotNil(t, err)
 	assert.Equal(t, "Unknown expression type detected in expression at(12:00)", err.Error())
 }
+
+func TestParseReturnsSuccessfullyForCronExpressionWithTimeZone(t *testing.T) {
+	// Assemble
+	logger := log.DefaultLogger()
+
+	// Act
+	parsedExpression, err := CreateScheduleExpression(logger, "cron(0 9 ? * MON-FRI * UTC)")
+
+	// Assert
+	assert.NotNil(t, parsedExpression)
+	assert.Nil(t, err)
+}
@@ -0,0 +1,59 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package schedulemanager
+
+import (
+	"hash/fnv"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/association/model"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+)
+
+// jitterWindow returns the configured window scheduled association runs are spread over
+func jitterWindow(log log.T) time.Duration {
+	config, err := appconfig.Config(false)
+	if err != nil {
+		log.Debugf("Failed to load agent config, association schedule jitter disabled, %v", err)
+		return 0
+	}
+	return time.Duration(config.Ssm.AssociationScheduleJitterSeconds) * time.Second
+}
+
+// scheduleJitter returns the splay of the given association within window.
+// The splay is derived from the instance and association ids, so an instance always offsets
+// the runs of an association by the same amount while different instances are spread across the window.
+func scheduleJitter(assoc *model.InstanceAssociation, window time.Duration) time.Duration {
+	seconds := int64(window / time.Second)
+	if seconds <= 0 {
+		return 0
+	}
+
+	hash := fnv.New64a()
+	if assoc.Association.InstanceId != nil {
+		hash.Write([]byte(*assoc.Association.InstanceId))
+	}
+	if assoc.Association.AssociationId != nil {
+		hash.Write([]byte(*assoc.Association.AssociationId))
+	}
+
+	return time.Duration(hash.Sum64()%uint64(seconds)) * time.Second
+}
+
+// setNextScheduledDate applies the schedule jitter and sets next scheduled date for the given association
+func setNextScheduledDate(log log.T, assoc *model.InstanceAssociation, window time.Duration) {
+	assoc.ScheduleJitter = scheduleJitter(assoc, window)
+	assoc.SetNextScheduledDate(log)
+}
@@ -0,0 +1,61 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package schedulemanager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/association/model"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/stretchr/testify/assert"
+)
+
+func newAssociation(instanceID, associationID string) *model.InstanceAssociation {
+	return &model.InstanceAssociation{
+		Association: &ssm.InstanceAssociationSummary{
+			Name:          aws.String("Test"),
+			InstanceId:    aws.String(instanceID),
+			AssociationId: aws.String(associationID),
+		},
+	}
+}
+
+func TestScheduleJitterIsDisabledWithoutWindow(t *testing.T) {
+	assoc := newAssociation("i-1234567890", "b2f71a28-cbe1-4429-b848-26c7e1f5ad0d")
+
+	assert.Equal(t, time.Duration(0), scheduleJitter(assoc, 0))
+}
+
+func TestScheduleJitterIsDeterministicAndWithinWindow(t *testing.T) {
+	window := 10 * time.Minute
+	assoc := newAssociation("i-1234567890", "b2f71a28-cbe1-4429-b848-26c7e1f5ad0d")
+
+	jitter := scheduleJitter(assoc, window)
+
+	assert.Equal(t, jitter, scheduleJitter(assoc, window))
+	assert.True(t, jitter >= 0)
+	assert.True(t, jitter < window)
+}
+
+func TestScheduleJitterSpreadsInstances(t *testing.T) {
+	window := time.Hour
+	jitters := make(map[time.Duration]bool)
+	for _, instanceID := range []string{"i-0000000001", "i-0000000002", "i-0000000003", "i-0000000004"} {
+		jitters[scheduleJitter(newAssociation(instanceID, "b2f71a28-cbe1-4429-b848-26c7e1f5ad0d"), window)] = true
+	}
+
+	assert.True(t, len(jitters) > 1)
+}
@@ -0,0 +1,38 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package schedulemanager
+
+import (
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/association/model"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+)
+
+// associationPriorities returns the configured association priorities by document name
+func associationPriorities(log log.T) map[string]int {
+	config, err := appconfig.Config(false)
+	if err != nil {
+		log.Debugf("Failed to load agent config, association priorities disabled, %v", err)
+		return nil
+	}
+	return config.Ssm.AssociationPriorities
+}
+
+// setPriority sets the priority of the given association from the priority of its document
+func setPriority(assoc *model.InstanceAssociation, priorities map[string]int) {
+	assoc.Priority = 0
+	if assoc.Association.Name != nil {
+		assoc.Priority = priorities[*assoc.Association.Name]
+	}
+}
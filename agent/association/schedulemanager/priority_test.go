@@ -0,0 +1,55 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package schedulemanager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/association/model"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetPriorityUsesDocumentName(t *testing.T) {
+	assoc := newAssociation("i-1234567890", "b2f71a28-cbe1-4429-b848-26c7e1f5ad0d")
+
+	setPriority(assoc, map[string]int{"Test": 10})
+	assert.Equal(t, 10, assoc.Priority)
+
+	setPriority(assoc, map[string]int{"Other": 10})
+	assert.Equal(t, 0, assoc.Priority)
+}
+
+func TestLoadNextScheduledAssociationPrefersHigherPriority(t *testing.T) {
+	due := time.Now().UTC().Add(-time.Minute)
+	low := newAssociation("i-1234567890", "b2f71a28-cbe1-4429-b848-26c7e1f5ad0d")
+	low.NextScheduledDate = aws.Time(due)
+	high := newAssociation("i-1234567890", "c2f71a28-cbe1-4429-b848-26c7e1f5ad0d")
+	high.NextScheduledDate = aws.Time(due)
+	high.Priority = 5
+	notDue := newAssociation("i-1234567890", "d2f71a28-cbe1-4429-b848-26c7e1f5ad0d")
+	notDue.NextScheduledDate = aws.Time(time.Now().UTC().Add(time.Hour))
+	notDue.Priority = 10
+
+	lock.Lock()
+	associations = []*model.InstanceAssociation{low, high, notDue}
+	lock.Unlock()
+
+	next, err := LoadNextScheduledAssociation(log.NewMockLog())
+
+	assert.NoError(t, err)
+	assert.Equal(t, high, next)
+}
@@ -0,0 +1,124 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package schedulemanager
+
+import (
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/times"
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+// retryState tracks the retries of a failed association, it is kept across refreshes
+type retryState struct {
+	attempts  int
+	retryDate time.Time
+}
+
+// retries is guarded by lock
+var retries = map[string]*retryState{}
+
+// deferrals keeps the date deferred associations are scheduled at, it is guarded by lock
+var deferrals = map[string]time.Time{}
+
+// RetryAttempts returns the number of retries scheduled for the given association since its last completed run
+func RetryAttempts(associationID string) int {
+	lock.RLock()
+	defer lock.RUnlock()
+
+	if state, ok := retries[associationID]; ok {
+		return state.attempts
+	}
+	return 0
+}
+
+// ScheduleRetry sets next scheduled date of the given association to retryDate and records the retry attempt
+func ScheduleRetry(log log.T, associationID string, retryDate time.Time) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	state, ok := retries[associationID]
+	if !ok {
+		state = &retryState{}
+		retries[associationID] = state
+	}
+	state.attempts++
+	state.retryDate = retryDate.UTC()
+	delete(deferrals, associationID)
+
+	for _, assoc := range associations {
+		if *assoc.Association.AssociationId == associationID {
+			assoc.NextScheduledDate = aws.Time(state.retryDate)
+			log.Infof("Scheduling retry %v of association %v, setting next ScheduledDate to %v",
+				state.attempts, associationID, times.ToIsoDashUTC(state.retryDate))
+			break
+		}
+	}
+}
+
+// Defer sets next scheduled date of the given association to deferredDate until the association runs
+func Defer(log log.T, associationID string, deferredDate time.Time) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	deferrals[associationID] = deferredDate.UTC()
+	for _, assoc := range associations {
+		if *assoc.Association.AssociationId == associationID {
+			assoc.NextScheduledDate = aws.Time(deferredDate.UTC())
+			log.Infof("Deferring association %v, setting next ScheduledDate to %v",
+				associationID, times.ToIsoDashUTC(deferredDate))
+			break
+		}
+	}
+}
+
+// ClearRetries forgets the retries of the given association
+func ClearRetries(associationID string) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	delete(retries, associationID)
+}
+
+// applyRetries reschedules the associations with a pending retry or deferral and drops the retries and
+// deferrals of removed associations. Caller must hold lock.
+func applyRetries(log log.T) {
+	active := make(map[string]bool)
+	for _, assoc := range associations {
+		associationID := *assoc.Association.AssociationId
+		active[associationID] = true
+		if state, ok := retries[associationID]; ok {
+			assoc.NextScheduledDate = aws.Time(state.retryDate)
+			log.Infof("Association %v has retry %v pending, setting next ScheduledDate to %v",
+				associationID, state.attempts, times.ToIsoDashUTC(state.retryDate))
+		}
+		if deferredDate, ok := deferrals[associationID]; ok {
+			assoc.NextScheduledDate = aws.Time(deferredDate)
+			log.Infof("Association %v is deferred, setting next ScheduledDate to %v",
+				associationID, times.ToIsoDashUTC(deferredDate))
+		}
+	}
+
+	for associationID := range retries {
+		if !active[associationID] {
+			delete(retries, associationID)
+		}
+	}
+	for associationID := range deferrals {
+		if !active[associationID] {
+			delete(deferrals, associationID)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package schedulemanager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/association/model"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduleRetrySurvivesRefresh(t *testing.T) {
+	logger := log.NewMockLog()
+	associationID := "b2f71a28-cbe1-4429-b848-26c7e1f5ad0d"
+	assoc := newAssociation("i-1234567890", associationID)
+	assoc.Association.LastExecutionDate = aws.Time(time.Now().UTC())
+	Refresh(logger, []*model.InstanceAssociation{assoc})
+	defer ClearRetries(associationID)
+
+	retryDate := time.Now().Add(5 * time.Minute).UTC()
+	ScheduleRetry(logger, associationID, retryDate)
+	assert.Equal(t, 1, RetryAttempts(associationID))
+	assert.Equal(t, retryDate, *assoc.NextScheduledDate)
+
+	refreshed := newAssociation("i-1234567890", associationID)
+	refreshed.Association.LastExecutionDate = aws.Time(time.Now().UTC())
+	Refresh(logger, []*model.InstanceAssociation{refreshed})
+	assert.Equal(t, retryDate, *refreshed.NextScheduledDate)
+
+	ClearRetries(associationID)
+	assert.Equal(t, 0, RetryAttempts(associationID))
+}
+
+func TestRefreshDropsRetriesOfRemovedAssociations(t *testing.T) {
+	logger := log.NewMockLog()
+	associationID := "b2f71a28-cbe1-4429-b848-26c7e1f5ad0d"
+	Refresh(logger, []*model.InstanceAssociation{newAssociation("i-1234567890", associationID)})
+	ScheduleRetry(logger, associationID, time.Now())
+
+	Refresh(logger, []*model.InstanceAssociation{})
+
+	assert.Equal(t, 0, RetryAttempts(associationID))
+}
+
+func TestDeferSurvivesRefreshUntilAssociationRuns(t *testing.T) {
+	logger := log.NewMockLog()
+	associationID := "b2f71a28-cbe1-4429-b848-26c7e1f5ad0d"
+	assoc := newAssociation("i-1234567890", associationID)
+	assoc.Association.LastExecutionDate = aws.Time(time.Now().UTC())
+	Refresh(logger, []*model.InstanceAssociation{assoc})
+
+	deferredDate := time.Now().Add(time.Hour).UTC()
+	Defer(logger, associationID, deferredDate)
+	assert.Equal(t, deferredDate, *assoc.NextScheduledDate)
+
+	refreshed := newAssociation("i-1234567890", associationID)
+	refreshed.Association.LastExecutionDate = aws.Time(time.Now().UTC())
+	Refresh(logger, []*model.InstanceAssociation{refreshed})
+	assert.Equal(t, deferredDate, *refreshed.NextScheduledDate)
+
+	UpdateNextScheduledDate(logger, associationID)
+	Refresh(logger, []*model.InstanceAssociation{refreshed})
+	assert.Nil(t, refreshed.NextScheduledDate)
+}
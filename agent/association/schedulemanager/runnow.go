@@ -0,0 +1,73 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package schedulemanager
+
+import (
+	"encoding/json"
+
+	"github.com/aws/amazon-ssm-agent/agent/association/model"
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+)
+
+// RunNow schedules the given association to run immediately, outside of its schedule.
+// It returns false when the association is not scheduled on the instance.
+func RunNow(log log.T, associationID string) bool {
+	lock.Lock()
+	defer lock.Unlock()
+
+	for _, assoc := range associations {
+		if *assoc.Association.AssociationId == associationID {
+			delete(deferrals, associationID)
+			assoc.RunNow()
+			log.Infof("Running association %v now on request", associationID)
+			return true
+		}
+	}
+	return false
+}
+
+// AssociationRunningPlugin returns the id of the first scheduled association whose document runs the given plugin
+func AssociationRunningPlugin(pluginName string) (associationID string, found bool) {
+	lock.RLock()
+	defer lock.RUnlock()
+
+	for _, assoc := range associations {
+		if runsPlugin(assoc, pluginName) {
+			return *assoc.Association.AssociationId, true
+		}
+	}
+	return "", false
+}
+
+// runsPlugin returns true if the document of the association runs the given plugin
+func runsPlugin(assoc *model.InstanceAssociation, pluginName string) bool {
+	if assoc.Document == nil {
+		return false
+	}
+
+	var docContent contracts.DocumentContent
+	if err := json.Unmarshal([]byte(*assoc.Document), &docContent); err != nil {
+		return false
+	}
+	if _, ok := docContent.RuntimeConfig[pluginName]; ok {
+		return true
+	}
+	for _, step := range docContent.MainSteps {
+		if step != nil && step.Action == pluginName {
+			return true
+		}
+	}
+	return false
+}
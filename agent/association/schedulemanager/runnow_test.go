@@ -0,0 +1,57 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package schedulemanager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/association/model"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunNow(t *testing.T) {
+	logger := log.NewMockLog()
+	associationID := "b2f71a28-cbe1-4429-b848-26c7e1f5ad0d"
+	assoc := newAssociation("i-1234567890", associationID)
+	assoc.Association.LastExecutionDate = aws.Time(time.Now().UTC())
+	Refresh(logger, []*model.InstanceAssociation{assoc})
+	Defer(logger, associationID, time.Now().Add(time.Hour))
+
+	assert.False(t, RunNow(logger, "0aa6c4b2-4a5c-4b4e-9d0e-6f0f3a1b2c3d"))
+	assert.True(t, RunNow(logger, associationID))
+	assert.False(t, assoc.NextScheduledDate.After(time.Now().UTC()))
+
+	next, err := LoadNextScheduledAssociation(logger)
+	assert.NoError(t, err)
+	assert.Equal(t, assoc, next)
+}
+
+func TestAssociationRunningPlugin(t *testing.T) {
+	logger := log.NewMockLog()
+	script := newAssociation("i-1234567890", "0aa6c4b2-4a5c-4b4e-9d0e-6f0f3a1b2c3d")
+	script.Document = aws.String(`{"schemaVersion":"2.2","mainSteps":[{"action":"aws:runShellScript","name":"runShellScript"}]}`)
+	legacy := newAssociation("i-1234567890", "5f1d0c8e-1a2b-4c3d-8e9f-0a1b2c3d4e5f")
+	legacy.Document = aws.String(`{"schemaVersion":"1.2","runtimeConfig":{"aws:softwareInventory":{}}}`)
+	Refresh(logger, []*model.InstanceAssociation{script, legacy})
+
+	associationID, found := AssociationRunningPlugin("aws:softwareInventory")
+	assert.True(t, found)
+	assert.Equal(t, "5f1d0c8e-1a2b-4c3d-8e9f-0a1b2c3d4e5f", associationID)
+
+	_, found = AssociationRunningPlugin("aws:domainJoin")
+	assert.False(t, found)
+}
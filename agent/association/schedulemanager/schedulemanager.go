@@ -49,8 +49,11 @@ func Refresh(log log.T, assocs []*model.InstanceAssociation) {
 	}
 
 	numberOfNewAssoc := 0
+	window := jitterWindow(log)
+	priorities := associationPriorities(log)
 	for _, assoc := range associations {
-		assoc.SetNextScheduledDate(log)
+		setNextScheduledDate(log, assoc, window)
+		setPriority(assoc, priorities)
 		if assoc.NextScheduledDate != nil {
 			log.Infof("Scheduling association %v, setting next ScheduledDate to %v", *assoc.Association.AssociationId, times.ToIsoDashUTC(*assoc.NextScheduledDate))
 		}
@@ -66,6 +69,8 @@ func Refresh(log log.T, assocs []*model.InstanceAssociation) {
 		}
 	}
 
+	applyRetries(log)
+
 	complianceModel.RefreshAssociationComplianceItems(associations)
 
 	log.Infof("Schedule manager refreshed with %v associations, %v new associations associated", len(associations), numberOfNewAssoc)
@@ -80,24 +85,30 @@ func LoadNextScheduledAssociation(log log.T) (*model.InstanceAssociation, error)
 		return nil, nil
 	}
 
+	var next *model.InstanceAssociation
 	for _, assoc := range associations {
 		currentTime := time.Now().UTC()
 		if assoc.NextScheduledDate == nil {
 			continue
 		}
 
+		// the first due association of the highest priority runs next
 		if (*assoc.NextScheduledDate).Before(currentTime) || (*assoc.NextScheduledDate).Equal(currentTime) {
-			if assocContent, err := jsonutil.Marshal(assoc); err != nil {
-				return nil, fmt.Errorf("failed to parse scheduled association, %v", err)
-			} else {
-				log.Infof("Next scheduled association is %v", jsonutil.Indent(assocContent))
+			if next == nil || assoc.Priority > next.Priority {
+				next = assoc
 			}
+		}
+	}
 
-			return assoc, nil
+	if next != nil {
+		if assocContent, err := jsonutil.Marshal(next); err != nil {
+			return nil, fmt.Errorf("failed to parse scheduled association, %v", err)
+		} else {
+			log.Infof("Next scheduled association is %v", jsonutil.Indent(assocContent))
 		}
 	}
 
-	return nil, nil
+	return next, nil
 }
 
 // LoadNextScheduledDate returns next scheduled date
@@ -131,7 +142,8 @@ func UpdateNextScheduledDate(log log.T, associationID string) {
 	for _, assoc := range associations {
 		if *assoc.Association.AssociationId == associationID {
 			assoc.Association.LastExecutionDate = aws.Time(time.Now().UTC())
-			assoc.SetNextScheduledDate(log)
+			delete(deferrals, associationID)
+			setNextScheduledDate(log, assoc, jitterWindow(log))
 			if assoc.NextScheduledDate != nil {
 				log.Infof("Scheduling association %v, setting next ScheduledDate to %v", *assoc.Association.AssociationId, times.ToIsoDashUTC(*assoc.NextScheduledDate))
 			}
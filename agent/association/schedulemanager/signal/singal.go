@@ -30,11 +30,13 @@ const (
 // AssociationExecutionSignal uses to manage the channel required by sending/receiving signals for executing scheduled association
 type AssociationExecutionSignal struct {
 	executeSignal chan struct{}
+	cancelSignal  chan string
 	stopSignal    chan bool
 }
 
 var instance = AssociationExecutionSignal{
 	executeSignal: make(chan struct{}, defaultScheduledJobQueueSize),
+	cancelSignal:  make(chan string, defaultScheduledJobQueueSize),
 	stopSignal:    make(chan bool, 1),
 }
 
@@ -77,6 +79,18 @@ func InitializeAssociationSignalService(log log.T, task func(log log.T)) {
 	}()
 }
 
+// InitializeCancelSignalService creates goroutine to handle signals for canceling running associations
+func InitializeCancelSignalService(log log.T, task func(log log.T, associationID string)) {
+	go func() {
+		for associationID := range instance.cancelSignal {
+			log.Debugf("Received signal for canceling association %v", associationID)
+			if task != nil {
+				task(log, associationID)
+			}
+		}
+	}()
+}
+
 // ResetWaitTimerForNextScheduledAssociation stops old wait timer and creates new one with updated target date
 // It will wait until the target date then sends the signal for executing next scheduled association
 func ResetWaitTimerForNextScheduledAssociation(log log.T, targetDate time.Time) {
@@ -126,6 +140,15 @@ func ExecuteAssociation(log log.T) {
 	}
 }
 
+// CancelAssociation sends out signal to cancel the running execution of the given association
+func CancelAssociation(log log.T, associationID string) {
+	log.Debugf("Sending signal to cancel association %v", associationID)
+
+	if !instance.isStopped() {
+		instance.cancelSignal <- associationID
+	}
+}
+
 // Stop close the channel and stop the timers
 func Stop() {
 	lock.Lock()
@@ -133,6 +156,7 @@ func Stop() {
 
 	if !instance.isStopped() {
 		close(instance.executeSignal)
+		close(instance.cancelSignal)
 		close(instance.stopSignal)
 	}
 	if waitTimerForNextScheduledAssociation != nil {
@@ -147,6 +171,7 @@ func StopExecutionSignal() {
 	defer lock.Unlock()
 	if !instance.isStopped() {
 		close(instance.executeSignal)
+		close(instance.cancelSignal)
 		close(instance.stopSignal)
 	}
 }
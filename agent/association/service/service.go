@@ -17,6 +17,7 @@ package service
 import (
 	"bytes"
 	"fmt"
+	"path/filepath"
 	"sync"
 	"time"
 
@@ -24,6 +25,7 @@ import (
 	"github.com/aws/amazon-ssm-agent/agent/association/cache"
 	"github.com/aws/amazon-ssm-agent/agent/association/model"
 	"github.com/aws/amazon-ssm-agent/agent/association/schedulemanager"
+	"github.com/aws/amazon-ssm-agent/agent/framework/outboundqueue"
 	"github.com/aws/amazon-ssm-agent/agent/jsonutil"
 	"github.com/aws/amazon-ssm-agent/agent/log"
 	"github.com/aws/amazon-ssm-agent/agent/plugins/pluginutil"
@@ -40,6 +42,15 @@ const (
 	latestDoc                      = "$LATEST"
 	cronExpressionEveryFiveMinutes = "cron(0 0/5 * 1/1 * ? *)"
 	NoOutputUrl                    = ""
+
+	// statusQueueDirName is the directory of the status updates that failed to reach the service
+	statusQueueDirName = "associationstatus"
+
+	// statusQueueMaxAge is how long a status update is kept while the service is unreachable
+	statusQueueMaxAge = 24 * time.Hour
+
+	// statusQueueMaxItems is the maximum number of queued status updates, one per association
+	statusQueueMaxItems = 1000
 )
 
 type associationApiMode string
@@ -54,6 +65,19 @@ var (
 	lock                      sync.RWMutex
 )
 
+// Assign method to global variables to allow unittest to override
+var newStatusQueue = func(instanceID string) *outboundqueue.Queue {
+	dir := filepath.Join(appconfig.DefaultDataStorePath, instanceID, appconfig.OutboundQueueRootDirName, statusQueueDirName)
+	return outboundqueue.New(dir, statusQueueMaxAge, statusQueueMaxItems)
+}
+
+// queuedStatus is an association status update waiting in the outbound queue
+type queuedStatus struct {
+	AssociationID   string
+	InstanceID      string
+	ExecutionResult *ssm.InstanceAssociationExecutionResult
+}
+
 // T represents interface for association
 type T interface {
 	CreateNewServiceIfUnHealthy(log log.T)
@@ -81,9 +105,11 @@ type T interface {
 
 // AssociationService wraps the Ssm Service
 type AssociationService struct {
-	ssmSvc     ssmsvc.Service
-	stopPolicy *sdkutil.StopPolicy
-	name       string
+	ssmSvc      ssmsvc.Service
+	stopPolicy  *sdkutil.StopPolicy
+	name        string
+	statusQueue *outboundqueue.Queue
+	queueLock   sync.Mutex
 }
 
 // NewAssociationService returns a new association service
@@ -152,6 +178,9 @@ func (s *AssociationService) ListInstanceAssociations(log log.T, instanceID stri
 	}
 
 	log.Debug("Number of associations is ", len(results))
+
+	// the service is reachable, send the status updates that failed to reach it
+	s.sendQueuedStatuses(log, instanceID)
 	return results, nil
 }
 
@@ -260,11 +289,28 @@ func (s *AssociationService) UpdateInstanceAssociationStatus(
 
 			if associationID == associationName {
 				s.UpdateAssociationStatus(log, associationName, instanceID, status, executionSummary)
+			} else if !sdkutil.IsRetryableError(err) {
+				log.Errorf("not queuing status of association %v, the service rejected it", associationID)
+			} else if err = s.getStatusQueue(instanceID).Enqueue(log, associationID, queuedStatus{
+				AssociationID:   associationID,
+				InstanceID:      instanceID,
+				ExecutionResult: &executionResult,
+			}); err != nil {
+				log.Errorf("unable to queue association status, %v", err)
+			} else {
+				log.Infof("Queued status of association %v until the service is reachable", associationID)
 			}
 
 			return
 		}
 
+		// the queued status of the association is superseded, send the status updates of the other associations
+		statusQueue := s.getStatusQueue(instanceID)
+		statusQueue.Remove(log, associationID)
+		if statusQueue.Len(log) > 0 {
+			go s.sendQueuedStatuses(log, instanceID)
+		}
+
 		var responseContent string
 		if responseContent, err = jsonutil.Marshal(response); err != nil {
 			log.Error("could not marshal response! ", err)
@@ -279,6 +325,37 @@ func (s *AssociationService) UpdateInstanceAssociationStatus(
 	return
 }
 
+// getStatusQueue returns the outbound queue of the association status updates of the instance
+func (s *AssociationService) getStatusQueue(instanceID string) *outboundqueue.Queue {
+	s.queueLock.Lock()
+	defer s.queueLock.Unlock()
+
+	if s.statusQueue == nil {
+		s.statusQueue = newStatusQueue(instanceID)
+	}
+	return s.statusQueue
+}
+
+// sendQueuedStatuses sends the association status updates that failed to reach the service, oldest first
+func (s *AssociationService) sendQueuedStatuses(log log.T, instanceID string) {
+	if !s.IsInstanceAssociationApiMode() {
+		return
+	}
+
+	s.getStatusQueue(instanceID).Flush(log, func(entry outboundqueue.Entry) (err error) {
+		var queued queuedStatus
+		if err = jsonutil.Unmarshal(entry.Content, &queued); err != nil || queued.ExecutionResult == nil {
+			// a corrupted entry can't be sent, drop it rather than blocking the queue
+			log.Errorf("dropping invalid queued status %v, %v", entry.Key, err)
+			return nil
+		}
+
+		log.Infof("Sending queued status %v of association %v", aws.StringValue(queued.ExecutionResult.Status), queued.AssociationID)
+		_, err = s.ssmSvc.UpdateInstanceAssociationStatus(log, queued.AssociationID, queued.InstanceID, queued.ExecutionResult)
+		return
+	})
+}
+
 // UsingInstanceAssociationApi represents if the agent is using new InstanceAssociationApi for listing and updating
 func (s *AssociationService) IsInstanceAssociationApiMode() bool {
 	lock.Lock()
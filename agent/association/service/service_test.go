@@ -15,13 +15,19 @@
 package service
 
 import (
+	"fmt"
+	"io/ioutil"
+	"os"
 	"testing"
+	"time"
 
 	"github.com/aws/amazon-ssm-agent/agent/association/model"
 	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/framework/outboundqueue"
 	"github.com/aws/amazon-ssm-agent/agent/log"
 	"github.com/aws/amazon-ssm-agent/agent/sdkutil"
 	ssmSvc "github.com/aws/amazon-ssm-agent/agent/ssm"
+	"github.com/aws/amazon-ssm-agent/agent/times"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ssm"
 	"github.com/stretchr/testify/assert"
@@ -34,6 +40,7 @@ var (
 
 var ssmMock = ssmSvc.NewMockDefault()
 var logMock = log.NewMockLog()
+var defaultStatusQueue = newStatusQueue
 
 func TestListAssociations(t *testing.T) {
 	service := AssociationService{
@@ -127,3 +134,51 @@ func TestUpdateAssociationStatus(t *testing.T) {
 		status,
 		"TestMessage")
 }
+
+func TestUpdateInstanceAssociationStatusQueuesFailedUpdate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "associationstatus")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	statusQueue := outboundqueue.New(dir, time.Hour, 10)
+	newStatusQueue = func(instanceID string) *outboundqueue.Queue { return statusQueue }
+	defer func() { newStatusQueue = defaultStatusQueue }()
+
+	failingSsm := ssmSvc.NewMockDefault()
+	service := AssociationService{
+		ssmSvc:     failingSsm,
+		stopPolicy: &sdkutil.StopPolicy{},
+	}
+	failingSsm.On("UpdateInstanceAssociationStatus",
+		mock.AnythingOfType("*log.Mock"),
+		"assoc-id",
+		instanceID,
+		mock.AnythingOfType("*ssm.InstanceAssociationExecutionResult")).Return(&ssm.UpdateInstanceAssociationStatusOutput{}, fmt.Errorf("service unreachable")).Once()
+
+	service.UpdateInstanceAssociationStatus(
+		logMock,
+		"assoc-id",
+		"assoc-name",
+		instanceID,
+		contracts.AssociationStatusSuccess,
+		contracts.AssociationErrorCodeNoError,
+		times.ToIso8601UTC(time.Now()),
+		"TestMessage",
+		NoOutputUrl)
+	assert.Equal(t, 1, statusQueue.Len(logMock))
+
+	// the queued update is sent once the associations can be listed again
+	failingSsm.On("UpdateInstanceAssociationStatus",
+		mock.AnythingOfType("*log.Mock"),
+		"assoc-id",
+		instanceID,
+		mock.AnythingOfType("*ssm.InstanceAssociationExecutionResult")).Return(&ssm.UpdateInstanceAssociationStatusOutput{}, nil)
+	failingSsm.On("ListInstanceAssociations", mock.AnythingOfType("*log.Mock"), instanceID).Return(&ssm.ListInstanceAssociationsOutput{}, nil)
+	statusQueue.Reset()
+
+	_, err = service.ListInstanceAssociations(logMock, instanceID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, statusQueue.Len(logMock))
+	failingSsm.AssertNumberOfCalls(t, "UpdateInstanceAssociationStatus", 2)
+}
@@ -0,0 +1,137 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package clicommand contains the implementation of all commands for the ssm agent cli
+package clicommand
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/aws/amazon-ssm-agent/agent/association/control"
+	"github.com/aws/amazon-ssm-agent/agent/cli/cliutil"
+	"github.com/aws/amazon-ssm-agent/agent/platform"
+)
+
+const (
+	cancelAssociationCommand       = "cancel-association"
+	cancelAssociationAssociationID = "association-id"
+)
+
+const cancelAssociationCommandHelp = `NAME:
+    {{.CancelAssociationCommandName}}
+
+DESCRIPTION
+    Requests the local amazon-ssm-agent service to cancel the running execution of an association.
+
+SYNOPSIS
+    {{.CancelAssociationCommandName}}
+    {{.AssociationIdFlag}} <value>
+
+PARAMETERS
+    {{.AssociationIdFlag}} (string) The association to cancel.
+
+EXAMPLES
+    This example cancels the running execution of an association.
+
+    Command:
+
+      {{.SsmCliName}} {{.CancelAssociationCommandName}} {{.AssociationIdFlag}} 01234567-890a-bcde-f012-34567890abcd
+
+    Output:
+      Cancel requested for association 01234567-890a-bcde-f012-34567890abcd
+
+OUTPUT
+    Confirmation that the cancel request was handed to the agent, the association is reported as Failed once canceled
+`
+
+type cancelAssociationHelpParams struct {
+	SsmCliName                   string
+	CancelAssociationCommandName string
+	AssociationIdFlag            string
+}
+
+func init() {
+	cliutil.Register(&CancelAssociationCommand{})
+}
+
+type CancelAssociationCommand struct {
+	helpText string
+}
+
+// Execute validates and executes the cancel-association cli command
+func (c *CancelAssociationCommand) Execute(subcommands []string, parameters map[string][]string) (error, string) {
+	validation, associationID := c.validateCancelAssociationInput(subcommands, parameters)
+	// return validation errors if any were found
+	if len(validation) > 0 {
+		return errors.New(strings.Join(validation, "\n")), ""
+	}
+
+	instanceID, err := platform.InstanceID()
+	if err != nil {
+		return err, ""
+	}
+
+	if err = control.RequestCancel(instanceID, associationID); err != nil {
+		return err, ""
+	}
+	return nil, fmt.Sprintf("Cancel requested for association %v", associationID)
+}
+
+// Help prints help for the cancel-association cli command
+func (c *CancelAssociationCommand) Help() string {
+	if len(c.helpText) == 0 {
+		t, _ := template.New("CancelAssociationCommandHelp").Parse(cancelAssociationCommandHelp)
+		params := cancelAssociationHelpParams{
+			cliutil.SsmCliName,
+			cancelAssociationCommand,
+			cliutil.FormatFlag(cancelAssociationAssociationID)}
+		buf := new(bytes.Buffer)
+		t.Execute(buf, params)
+		c.helpText = buf.String()
+	}
+	return c.helpText
+}
+
+// Name is the command name used in the cli
+func (CancelAssociationCommand) Name() string {
+	return cancelAssociationCommand
+}
+
+// validateCancelAssociationInput checks the subcommands and parameters for format and unsupported values
+func (CancelAssociationCommand) validateCancelAssociationInput(subcommands []string, parameters map[string][]string) (validation []string, associationID string) {
+	validation = make([]string, 0)
+
+	if subcommands != nil && len(subcommands) > 0 {
+		validation = append(validation, fmt.Sprintf("%v does not support subcommand %v", cancelAssociationCommand, subcommands), "")
+		return validation, "" // invalid subcommand is an attempt to execute something that really isn't this command, so the rest of the validation is skipped in this case
+	}
+
+	if values, exists := parameters[cancelAssociationAssociationID]; !exists || len(values) != 1 || values[0] == "" {
+		validation = append(validation, fmt.Sprintf("expected 1 value for parameter %v",
+			cliutil.FormatFlag(cancelAssociationAssociationID)))
+	} else {
+		associationID = values[0]
+	}
+
+	// look for unsupported parameters
+	for key := range parameters {
+		if key != cancelAssociationAssociationID {
+			validation = append(validation, fmt.Sprintf("unknown parameter %v", cliutil.FormatFlag(key)))
+		}
+	}
+	return validation, associationID
+}
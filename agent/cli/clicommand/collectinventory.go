@@ -0,0 +1,194 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package clicommand contains the implementation of all commands for the ssm agent cli
+package clicommand
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/association/control"
+	"github.com/aws/amazon-ssm-agent/agent/cli/cliutil"
+	"github.com/aws/amazon-ssm-agent/agent/jsonutil"
+	"github.com/aws/amazon-ssm-agent/agent/platform"
+)
+
+const (
+	collectInventoryCommand        = "collect-inventory"
+	collectInventoryAssociationID  = "association-id"
+	collectInventoryTimeoutSeconds = "timeout-seconds"
+	defaultCollectInventoryTimeout = 600
+)
+
+const collectInventoryCommandHelp = `NAME:
+    {{.CollectInventoryCommandName}}
+
+DESCRIPTION
+    Requests the local amazon-ssm-agent service to collect and upload the inventory immediately, outside of the
+    schedule of the inventory association, and waits for the result of the upload.
+
+SYNOPSIS
+    {{.CollectInventoryCommandName}}
+    [{{.AssociationIdFlag}} <value>]
+    [{{.TimeoutSecondsFlag}} <value>]
+
+PARAMETERS
+    {{.AssociationIdFlag}} (string) The inventory association to run, by default the association running the
+    aws:softwareInventory plugin.
+
+    {{.TimeoutSecondsFlag}} (integer) Maximum number of seconds to wait for the result, 600 by default.
+
+EXAMPLES
+    This example collects and uploads the inventory of the instance.
+
+    Command:
+
+      {{.SsmCliName}} {{.CollectInventoryCommandName}}
+
+    Output:
+      {
+        "AssociationID": "01234567-890a-bcde-f012-34567890abcd",
+        "DocumentName": "AWS-GatherSoftwareInventory",
+        "DocumentVersion": "1",
+        "ExecutionID": "01234567-890a-bcde-f012-34567890abcd.2017-06-01T10-00-00.000Z",
+        "Status": "Success",
+        "StartDateTime": "2017-06-01T10:00:00.000Z",
+        "EndDateTime": "2017-06-01T10:00:05.000Z",
+        "DurationSeconds": 5,
+        "PluginResults": [
+          {
+            "PluginID": "collectSoftwareInventoryItems",
+            "PluginName": "aws:softwareInventory",
+            "Status": "Success",
+            "Code": 0,
+            "Error": ""
+          }
+        ]
+      }
+
+OUTPUT
+    The execution of the inventory association in JSON format, the plugin results hold the result of the upload
+`
+
+type collectInventoryHelpParams struct {
+	SsmCliName                  string
+	CollectInventoryCommandName string
+	AssociationIdFlag           string
+	TimeoutSecondsFlag          string
+}
+
+func init() {
+	cliutil.Register(&CollectInventoryCommand{})
+}
+
+type CollectInventoryCommand struct {
+	helpText string
+}
+
+// Execute validates and executes the collect-inventory cli command
+func (c *CollectInventoryCommand) Execute(subcommands []string, parameters map[string][]string) (error, string) {
+	validation, associationID, timeout := c.validateCollectInventoryInput(subcommands, parameters)
+	// return validation errors if any were found
+	if len(validation) > 0 {
+		return errors.New(strings.Join(validation, "\n")), ""
+	}
+
+	instanceID, err := platform.InstanceID()
+	if err != nil {
+		return err, ""
+	}
+
+	requested := time.Now()
+	if err = control.RequestRun(instanceID, associationID); err != nil {
+		return err, ""
+	}
+
+	execution, err := control.WaitForRun(instanceID, associationID, requested, timeout)
+	if err != nil {
+		return err, ""
+	}
+
+	result, err := jsonutil.Marshal(execution)
+	if err != nil {
+		return err, ""
+	}
+	return nil, jsonutil.Indent(result)
+}
+
+// Help prints help for the collect-inventory cli command
+func (c *CollectInventoryCommand) Help() string {
+	if len(c.helpText) == 0 {
+		t, _ := template.New("CollectInventoryCommandHelp").Parse(collectInventoryCommandHelp)
+		params := collectInventoryHelpParams{
+			cliutil.SsmCliName,
+			collectInventoryCommand,
+			cliutil.FormatFlag(collectInventoryAssociationID),
+			cliutil.FormatFlag(collectInventoryTimeoutSeconds)}
+		buf := new(bytes.Buffer)
+		t.Execute(buf, params)
+		c.helpText = buf.String()
+	}
+	return c.helpText
+}
+
+// Name is the command name used in the cli
+func (CollectInventoryCommand) Name() string {
+	return collectInventoryCommand
+}
+
+// validateCollectInventoryInput checks the subcommands and parameters for format and unsupported values
+func (CollectInventoryCommand) validateCollectInventoryInput(subcommands []string, parameters map[string][]string) (validation []string, associationID string, timeout time.Duration) {
+	validation = make([]string, 0)
+	associationID = control.InventoryRunRequest
+	timeout = defaultCollectInventoryTimeout * time.Second
+
+	if subcommands != nil && len(subcommands) > 0 {
+		validation = append(validation, fmt.Sprintf("%v does not support subcommand %v", collectInventoryCommand, subcommands), "")
+		return validation, "", 0 // invalid subcommand is an attempt to execute something that really isn't this command, so the rest of the validation is skipped in this case
+	}
+
+	if values, exists := parameters[collectInventoryAssociationID]; exists {
+		if len(values) != 1 || values[0] == "" {
+			validation = append(validation, fmt.Sprintf("expected 1 value for parameter %v",
+				cliutil.FormatFlag(collectInventoryAssociationID)))
+		} else {
+			associationID = values[0]
+		}
+	}
+
+	if values, exists := parameters[collectInventoryTimeoutSeconds]; exists {
+		if len(values) != 1 {
+			validation = append(validation, fmt.Sprintf("expected 1 value for parameter %v",
+				cliutil.FormatFlag(collectInventoryTimeoutSeconds)))
+		} else if value, err := strconv.Atoi(values[0]); err != nil || value <= 0 {
+			validation = append(validation, fmt.Sprintf("parameter %v should be a positive integer",
+				cliutil.FormatFlag(collectInventoryTimeoutSeconds)))
+		} else {
+			timeout = time.Duration(value) * time.Second
+		}
+	}
+
+	// look for unsupported parameters
+	for key := range parameters {
+		if key != collectInventoryAssociationID && key != collectInventoryTimeoutSeconds {
+			validation = append(validation, fmt.Sprintf("unknown parameter %v", cliutil.FormatFlag(key)))
+		}
+	}
+	return validation, associationID, timeout
+}
@@ -0,0 +1,257 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package clicommand contains the implementation of all commands for the ssm agent cli
+package clicommand
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/aws/amazon-ssm-agent/agent/cli/cliutil"
+	"github.com/aws/amazon-ssm-agent/agent/jsonutil"
+	"github.com/aws/amazon-ssm-agent/agent/platform"
+	"github.com/aws/amazon-ssm-agent/agent/runcommand/deadletter"
+)
+
+const (
+	listDeadLettersCommand    = "list-dead-letters"
+	getDeadLetterCommand      = "get-dead-letter"
+	requeueDeadLetterCommand  = "requeue-dead-letter"
+	deadLetterMessageIDFlag   = "message-id"
+	deadLetterMessageIDSample = "aws.ssm.01234567-890a-bcde-f012-34567890abcd.i-01234567890abcdef"
+)
+
+const deadLetterCommandHelp = `NAME:
+    {{.CommandName}}
+
+DESCRIPTION
+    {{.Description}}
+
+SYNOPSIS
+    {{.CommandName}}{{if .MessageIdFlag}}
+    {{.MessageIdFlag}} <value>
+
+PARAMETERS
+    {{.MessageIdFlag}} (string) ID of the message which failed to parse.{{end}}
+
+EXAMPLES
+    Command:
+
+      {{.SsmCliName}} {{.CommandName}}{{if .MessageIdFlag}} {{.MessageIdFlag}} {{.MessageIdSample}}{{end}}
+
+    Output:
+      {{.Output}}
+
+OUTPUT
+    {{.OutputDescription}}
+`
+
+type deadLetterHelpParams struct {
+	SsmCliName        string
+	CommandName       string
+	Description       string
+	MessageIdFlag     string
+	MessageIdSample   string
+	Output            string
+	OutputDescription string
+}
+
+// deadLetterSummary is an entry listed without its message
+type deadLetterSummary struct {
+	ID           string
+	Service      string
+	ReceivedDate string
+	Error        string
+}
+
+func init() {
+	cliutil.Register(&ListDeadLettersCommand{})
+	cliutil.Register(&GetDeadLetterCommand{})
+	cliutil.Register(&RequeueDeadLetterCommand{})
+}
+
+type ListDeadLettersCommand struct {
+	helpText string
+}
+
+// Execute validates and executes the list-dead-letters cli command
+func (c *ListDeadLettersCommand) Execute(subcommands []string, parameters map[string][]string) (error, string) {
+	if validation := validateNoInput(listDeadLettersCommand, subcommands, parameters); len(validation) > 0 {
+		return errors.New(strings.Join(validation, "\n")), ""
+	}
+
+	instanceID, err := platform.InstanceID()
+	if err != nil {
+		return err, ""
+	}
+
+	entries, err := deadletter.List(instanceID)
+	if err != nil {
+		return err, ""
+	}
+	summaries := make([]deadLetterSummary, 0, len(entries))
+	for _, entry := range entries {
+		summaries = append(summaries, deadLetterSummary{entry.ID, entry.Service, entry.ReceivedDate, entry.Error})
+	}
+	result, err := jsonutil.Marshal(summaries)
+	if err != nil {
+		return err, ""
+	}
+	return nil, jsonutil.Indent(result)
+}
+
+// Help prints help for the list-dead-letters cli command
+func (c *ListDeadLettersCommand) Help() string {
+	if len(c.helpText) == 0 {
+		c.helpText = deadLetterHelp(deadLetterHelpParams{
+			SsmCliName:        cliutil.SsmCliName,
+			CommandName:       listDeadLettersCommand,
+			Description:       "Lists the run command messages the local amazon-ssm-agent service failed to parse, the latest 100 messages are kept.",
+			Output:            `[{"ID": "` + deadLetterMessageIDSample + `", "Service": "MessagingDeliveryService", "ReceivedDate": "2017-06-01T10:00:00.000Z", "Error": "..."}]`,
+			OutputDescription: "Messages which failed to parse in JSON format, oldest first",
+		})
+	}
+	return c.helpText
+}
+
+// Name is the command name used in the cli
+func (ListDeadLettersCommand) Name() string {
+	return listDeadLettersCommand
+}
+
+type GetDeadLetterCommand struct {
+	helpText string
+}
+
+// Execute validates and executes the get-dead-letter cli command
+func (c *GetDeadLetterCommand) Execute(subcommands []string, parameters map[string][]string) (error, string) {
+	validation, messageID := validateDeadLetterInput(getDeadLetterCommand, subcommands, parameters)
+	if len(validation) > 0 {
+		return errors.New(strings.Join(validation, "\n")), ""
+	}
+
+	instanceID, err := platform.InstanceID()
+	if err != nil {
+		return err, ""
+	}
+
+	entry, err := deadletter.Get(instanceID, messageID)
+	if err != nil {
+		return err, ""
+	}
+	result, err := jsonutil.Marshal(entry)
+	if err != nil {
+		return err, ""
+	}
+	return nil, jsonutil.Indent(result)
+}
+
+// Help prints help for the get-dead-letter cli command
+func (c *GetDeadLetterCommand) Help() string {
+	if len(c.helpText) == 0 {
+		c.helpText = deadLetterHelp(deadLetterHelpParams{
+			SsmCliName:        cliutil.SsmCliName,
+			CommandName:       getDeadLetterCommand,
+			Description:       "Returns a run command message the local amazon-ssm-agent service failed to parse, with the parse error.",
+			MessageIdFlag:     cliutil.FormatFlag(deadLetterMessageIDFlag),
+			MessageIdSample:   deadLetterMessageIDSample,
+			Output:            `{"ID": "` + deadLetterMessageIDSample + `", "Service": "MessagingDeliveryService", "ReceivedDate": "2017-06-01T10:00:00.000Z", "Error": "...", "Message": {...}}`,
+			OutputDescription: "Message which failed to parse in JSON format",
+		})
+	}
+	return c.helpText
+}
+
+// Name is the command name used in the cli
+func (GetDeadLetterCommand) Name() string {
+	return getDeadLetterCommand
+}
+
+type RequeueDeadLetterCommand struct {
+	helpText string
+}
+
+// Execute validates and executes the requeue-dead-letter cli command
+func (c *RequeueDeadLetterCommand) Execute(subcommands []string, parameters map[string][]string) (error, string) {
+	validation, messageID := validateDeadLetterInput(requeueDeadLetterCommand, subcommands, parameters)
+	if len(validation) > 0 {
+		return errors.New(strings.Join(validation, "\n")), ""
+	}
+
+	instanceID, err := platform.InstanceID()
+	if err != nil {
+		return err, ""
+	}
+
+	if err = deadletter.Requeue(instanceID, messageID); err != nil {
+		return err, ""
+	}
+	return nil, fmt.Sprintf("Message %v requeued", messageID)
+}
+
+// Help prints help for the requeue-dead-letter cli command
+func (c *RequeueDeadLetterCommand) Help() string {
+	if len(c.helpText) == 0 {
+		c.helpText = deadLetterHelp(deadLetterHelpParams{
+			SsmCliName:        cliutil.SsmCliName,
+			CommandName:       requeueDeadLetterCommand,
+			Description:       "Hands a run command message which failed to parse back to the local amazon-ssm-agent service, the message is processed again on the next poll.",
+			MessageIdFlag:     cliutil.FormatFlag(deadLetterMessageIDFlag),
+			MessageIdSample:   deadLetterMessageIDSample,
+			Output:            "Message " + deadLetterMessageIDSample + " requeued",
+			OutputDescription: "Confirmation that the message was requeued",
+		})
+	}
+	return c.helpText
+}
+
+// Name is the command name used in the cli
+func (RequeueDeadLetterCommand) Name() string {
+	return requeueDeadLetterCommand
+}
+
+func deadLetterHelp(params deadLetterHelpParams) string {
+	t, _ := template.New("DeadLetterCommandHelp").Parse(deadLetterCommandHelp)
+	buf := new(bytes.Buffer)
+	t.Execute(buf, params)
+	return buf.String()
+}
+
+// validateDeadLetterInput checks the command was given a single message id and no other parameter
+func validateDeadLetterInput(command string, subcommands []string, parameters map[string][]string) (validation []string, messageID string) {
+	validation = make([]string, 0)
+
+	if subcommands != nil && len(subcommands) > 0 {
+		validation = append(validation, fmt.Sprintf("%v does not support subcommand %v", command, subcommands), "")
+		return validation, ""
+	}
+
+	if values, exists := parameters[deadLetterMessageIDFlag]; !exists {
+		validation = append(validation, fmt.Sprintf("%v is required", cliutil.FormatFlag(deadLetterMessageIDFlag)))
+	} else if len(values) != 1 {
+		validation = append(validation, fmt.Sprintf("expected 1 value for parameter %v", cliutil.FormatFlag(deadLetterMessageIDFlag)))
+	} else {
+		messageID = values[0]
+	}
+
+	for key := range parameters {
+		if key != deadLetterMessageIDFlag {
+			validation = append(validation, fmt.Sprintf("unknown parameter %v", cliutil.FormatFlag(key)))
+		}
+	}
+	return validation, messageID
+}
@@ -0,0 +1,182 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package clicommand contains the implementation of all commands for the ssm agent cli
+package clicommand
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/aws/amazon-ssm-agent/agent/association/recorder"
+	"github.com/aws/amazon-ssm-agent/agent/cli/cliutil"
+	"github.com/aws/amazon-ssm-agent/agent/jsonutil"
+	"github.com/aws/amazon-ssm-agent/agent/platform"
+)
+
+const (
+	getAssociationHistoryCommand       = "get-association-history"
+	getAssociationHistoryAssociationID = "association-id"
+	getAssociationHistoryMaxResults    = "max-results"
+	defaultAssociationHistoryResults   = 10
+)
+
+const getAssociationHistoryCommandHelp = `NAME:
+    {{.GetAssociationHistoryCommandName}}
+
+DESCRIPTION
+    Returns the latest association executions recorded by the local amazon-ssm-agent service.
+
+SYNOPSIS
+    {{.GetAssociationHistoryCommandName}}
+    [{{.AssociationIdFlag}} <value>]
+    [{{.MaxResultsFlag}} <value>]
+
+PARAMETERS
+    {{.AssociationIdFlag}} (string) Only return executions of the given association.
+
+    {{.MaxResultsFlag}} (integer) Maximum number of executions to return, 10 by default, 0 returns all recorded executions.
+
+EXAMPLES
+    This example returns the latest execution of an association.
+
+    Command:
+
+      {{.SsmCliName}} {{.GetAssociationHistoryCommandName}} {{.AssociationIdFlag}} 01234567-890a-bcde-f012-34567890abcd {{.MaxResultsFlag}} 1
+
+    Output:
+      [
+        {
+          "AssociationID": "01234567-890a-bcde-f012-34567890abcd",
+          "DocumentName": "AWS-RunShellScript",
+          "DocumentVersion": "1",
+          "ExecutionID": "01234567-890a-bcde-f012-34567890abcd.2017-06-01T10-00-00.000Z",
+          "Status": "Success",
+          "StartDateTime": "2017-06-01T10:00:00.000Z",
+          "EndDateTime": "2017-06-01T10:00:05.000Z",
+          "DurationSeconds": 5,
+          "PluginResults": [
+            {
+              "PluginID": "aws:runShellScript",
+              "PluginName": "aws:runShellScript",
+              "Status": "Success",
+              "Code": 0,
+              "Error": ""
+            }
+          ]
+        }
+      ]
+
+OUTPUT
+    Association executions in JSON format, latest first
+`
+
+type getAssociationHistoryHelpParams struct {
+	SsmCliName                       string
+	GetAssociationHistoryCommandName string
+	AssociationIdFlag                string
+	MaxResultsFlag                   string
+}
+
+func init() {
+	cliutil.Register(&GetAssociationHistoryCommand{})
+}
+
+type GetAssociationHistoryCommand struct {
+	helpText string
+}
+
+// Execute validates and executes the get-association-history cli command
+func (c *GetAssociationHistoryCommand) Execute(subcommands []string, parameters map[string][]string) (error, string) {
+	validation, associationID, maxResults := c.validateGetAssociationHistoryInput(subcommands, parameters)
+	// return validation errors if any were found
+	if len(validation) > 0 {
+		return errors.New(strings.Join(validation, "\n")), ""
+	}
+
+	instanceID, err := platform.InstanceID()
+	if err != nil {
+		return err, ""
+	}
+
+	result, err := jsonutil.Marshal(recorder.ExecutionHistory(instanceID, associationID, maxResults))
+	if err != nil {
+		return err, ""
+	}
+	return nil, jsonutil.Indent(result)
+}
+
+// Help prints help for the get-association-history cli command
+func (c *GetAssociationHistoryCommand) Help() string {
+	if len(c.helpText) == 0 {
+		t, _ := template.New("GetAssociationHistoryCommandHelp").Parse(getAssociationHistoryCommandHelp)
+		params := getAssociationHistoryHelpParams{
+			cliutil.SsmCliName,
+			getAssociationHistoryCommand,
+			cliutil.FormatFlag(getAssociationHistoryAssociationID),
+			cliutil.FormatFlag(getAssociationHistoryMaxResults)}
+		buf := new(bytes.Buffer)
+		t.Execute(buf, params)
+		c.helpText = buf.String()
+	}
+	return c.helpText
+}
+
+// Name is the command name used in the cli
+func (GetAssociationHistoryCommand) Name() string {
+	return getAssociationHistoryCommand
+}
+
+// validateGetAssociationHistoryInput checks the subcommands and parameters for format and unsupported values
+func (GetAssociationHistoryCommand) validateGetAssociationHistoryInput(subcommands []string, parameters map[string][]string) (validation []string, associationID string, maxResults int) {
+	validation = make([]string, 0)
+	maxResults = defaultAssociationHistoryResults
+
+	if subcommands != nil && len(subcommands) > 0 {
+		validation = append(validation, fmt.Sprintf("%v does not support subcommand %v", getAssociationHistoryCommand, subcommands), "")
+		return validation, "", 0 // invalid subcommand is an attempt to execute something that really isn't this command, so the rest of the validation is skipped in this case
+	}
+
+	if values, exists := parameters[getAssociationHistoryAssociationID]; exists {
+		if len(values) != 1 {
+			validation = append(validation, fmt.Sprintf("expected 1 value for parameter %v",
+				cliutil.FormatFlag(getAssociationHistoryAssociationID)))
+		} else {
+			associationID = values[0]
+		}
+	}
+
+	if values, exists := parameters[getAssociationHistoryMaxResults]; exists {
+		if len(values) != 1 {
+			validation = append(validation, fmt.Sprintf("expected 1 value for parameter %v",
+				cliutil.FormatFlag(getAssociationHistoryMaxResults)))
+		} else if value, err := strconv.Atoi(values[0]); err != nil || value < 0 {
+			validation = append(validation, fmt.Sprintf("parameter %v should be a non-negative integer",
+				cliutil.FormatFlag(getAssociationHistoryMaxResults)))
+		} else {
+			maxResults = value
+		}
+	}
+
+	// look for unsupported parameters
+	for key := range parameters {
+		if key != getAssociationHistoryAssociationID && key != getAssociationHistoryMaxResults {
+			validation = append(validation, fmt.Sprintf("unknown parameter %v", cliutil.FormatFlag(key)))
+		}
+	}
+	return validation, associationID, maxResults
+}
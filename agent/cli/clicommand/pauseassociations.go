@@ -0,0 +1,166 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package clicommand contains the implementation of all commands for the ssm agent cli
+package clicommand
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/aws/amazon-ssm-agent/agent/association/control"
+	"github.com/aws/amazon-ssm-agent/agent/cli/cliutil"
+	"github.com/aws/amazon-ssm-agent/agent/platform"
+)
+
+const (
+	pauseAssociationsCommand  = "pause-associations"
+	resumeAssociationsCommand = "resume-associations"
+)
+
+const pauseAssociationsCommandHelp = `NAME:
+    {{.CommandName}}
+
+DESCRIPTION
+    {{.Description}}
+
+SYNOPSIS
+    {{.CommandName}}
+
+EXAMPLES
+    Command:
+
+      {{.SsmCliName}} {{.CommandName}}
+
+    Output:
+      {{.Output}}
+
+OUTPUT
+    Confirmation that association processing was {{.State}}
+`
+
+type pauseAssociationsHelpParams struct {
+	SsmCliName  string
+	CommandName string
+	Description string
+	Output      string
+	State       string
+}
+
+func init() {
+	cliutil.Register(&PauseAssociationsCommand{})
+	cliutil.Register(&ResumeAssociationsCommand{})
+}
+
+type PauseAssociationsCommand struct {
+	helpText string
+}
+
+// Execute validates and executes the pause-associations cli command
+func (c *PauseAssociationsCommand) Execute(subcommands []string, parameters map[string][]string) (error, string) {
+	if validation := validateNoInput(pauseAssociationsCommand, subcommands, parameters); len(validation) > 0 {
+		return errors.New(strings.Join(validation, "\n")), ""
+	}
+
+	instanceID, err := platform.InstanceID()
+	if err != nil {
+		return err, ""
+	}
+
+	if err = control.Pause(instanceID); err != nil {
+		return err, ""
+	}
+	return nil, "Association processing paused"
+}
+
+// Help prints help for the pause-associations cli command
+func (c *PauseAssociationsCommand) Help() string {
+	if len(c.helpText) == 0 {
+		c.helpText = pauseAssociationsHelp(pauseAssociationsHelpParams{
+			cliutil.SsmCliName,
+			pauseAssociationsCommand,
+			"Pauses association processing of the local amazon-ssm-agent service. Running associations finish, due associations wait until processing is resumed.",
+			"Association processing paused",
+			"paused"})
+	}
+	return c.helpText
+}
+
+// Name is the command name used in the cli
+func (PauseAssociationsCommand) Name() string {
+	return pauseAssociationsCommand
+}
+
+type ResumeAssociationsCommand struct {
+	helpText string
+}
+
+// Execute validates and executes the resume-associations cli command
+func (c *ResumeAssociationsCommand) Execute(subcommands []string, parameters map[string][]string) (error, string) {
+	if validation := validateNoInput(resumeAssociationsCommand, subcommands, parameters); len(validation) > 0 {
+		return errors.New(strings.Join(validation, "\n")), ""
+	}
+
+	instanceID, err := platform.InstanceID()
+	if err != nil {
+		return err, ""
+	}
+
+	if err = control.Resume(instanceID); err != nil {
+		return err, ""
+	}
+	return nil, "Association processing resumed"
+}
+
+// Help prints help for the resume-associations cli command
+func (c *ResumeAssociationsCommand) Help() string {
+	if len(c.helpText) == 0 {
+		c.helpText = pauseAssociationsHelp(pauseAssociationsHelpParams{
+			cliutil.SsmCliName,
+			resumeAssociationsCommand,
+			"Resumes association processing of the local amazon-ssm-agent service. Associations which became due while processing was paused run right away.",
+			"Association processing resumed",
+			"resumed"})
+	}
+	return c.helpText
+}
+
+// Name is the command name used in the cli
+func (ResumeAssociationsCommand) Name() string {
+	return resumeAssociationsCommand
+}
+
+func pauseAssociationsHelp(params pauseAssociationsHelpParams) string {
+	t, _ := template.New("PauseAssociationsCommandHelp").Parse(pauseAssociationsCommandHelp)
+	buf := new(bytes.Buffer)
+	t.Execute(buf, params)
+	return buf.String()
+}
+
+// validateNoInput checks that a command without parameters was given neither subcommands nor parameters
+func validateNoInput(command string, subcommands []string, parameters map[string][]string) (validation []string) {
+	validation = make([]string, 0)
+
+	if subcommands != nil && len(subcommands) > 0 {
+		validation = append(validation, fmt.Sprintf("%v does not support subcommand %v", command, subcommands), "")
+		return validation
+	}
+
+	for key := range parameters {
+		validation = append(validation, fmt.Sprintf("unknown parameter %v", cliutil.FormatFlag(key)))
+	}
+	return validation
+}
@@ -42,6 +42,8 @@ func prepareRuntimeStatus(log log.T, pluginResult PluginResult) PluginRuntimeSta
 		EndDateTime:    times.ToIso8601UTC(pluginResult.EndDateTime),
 		StandardOutput: pluginResult.StandardOutput,
 		StandardError:  pluginResult.StandardError,
+		Attempts:       pluginResult.Attempts,
+		ResourceUsage:  pluginResult.ResourceUsage,
 	}
 
 	if pluginResult.OutputS3BucketName != "" {
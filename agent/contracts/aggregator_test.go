@@ -40,6 +40,28 @@ func TestPrepareRuntimeStatus(t *testing.T) {
 				StandardOutput: "output",
 			},
 		},
+		{
+			Input: PluginResult{
+				PluginName:    "aws:runShellScript",
+				Code:          1,
+				Status:        "Failed",
+				Output:        "failed",
+				StartDateTime: times.ParseIso8601UTC("2015-07-09T23:23:39.019Z"),
+				EndDateTime:   times.ParseIso8601UTC("2015-07-09T23:23:49.023Z"),
+				Attempts:      3,
+				ResourceUsage: &ResourceUsage{UserCPUSeconds: 1.5, PeakMemoryKilobytes: 2048},
+			},
+			Output: PluginRuntimeStatus{
+				Name:          "aws:runShellScript",
+				Code:          1,
+				Status:        "Failed",
+				Output:        "failed",
+				StartDateTime: "2015-07-09T23:23:39.019Z",
+				EndDateTime:   "2015-07-09T23:23:49.023Z",
+				Attempts:      3,
+				ResourceUsage: &ResourceUsage{UserCPUSeconds: 1.5, PeakMemoryKilobytes: 2048},
+			},
+		},
 	}
 
 	// run test cases
@@ -58,6 +80,15 @@ func TestPrepareRuntimeStatus(t *testing.T) {
 	return
 }
 
+func TestResourceUsageAdd(t *testing.T) {
+	first := ResourceUsage{UserCPUSeconds: 1, SystemCPUSeconds: 0.5, PeakMemoryKilobytes: 4096, ReadBytes: 512, WriteBytes: 1024}
+	second := ResourceUsage{UserCPUSeconds: 2, SystemCPUSeconds: 0.25, PeakMemoryKilobytes: 1024, WriteBytes: 512}
+
+	assert.Equal(t, ResourceUsage{UserCPUSeconds: 3, SystemCPUSeconds: 0.75, PeakMemoryKilobytes: 4096, ReadBytes: 512, WriteBytes: 1536},
+		first.Add(second))
+	assert.Equal(t, first.Add(second), second.Add(first))
+}
+
 //TODO add test for DocumentStatusAggregator
 func TestDocumentStatus(t *testing.T) {
 	type testCase struct {
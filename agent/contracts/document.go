@@ -74,6 +74,14 @@ type DocumentInfo struct {
 	RunCount        int
 	ProcInfo        OSProcInfo
 	ClientId        string
+	// Priority orders the document ahead of queued documents with a lower priority
+	Priority int
+	// TraceParent is the W3C traceparent of the span of the document execution, the plugins are traced as its children
+	TraceParent string `json:",omitempty"`
+	// RunAsUser and SessionOwner are the SSMSessionRunAs principal tag and the principal ARN of the sessions,
+	// as authenticated by the service
+	RunAsUser    string `json:",omitempty"`
+	SessionOwner string `json:",omitempty"`
 }
 
 //CloudWatchConfiguration represents information relevant to command output in cloudWatch
@@ -83,11 +91,22 @@ type CloudWatchConfiguration struct {
 	LogGroupEncryptionEnabled bool
 }
 
+// S3OutputOptions represents how the output of a document is uploaded to S3, empty options fall back to the agent config
+type S3OutputOptions struct {
+	// KmsKeyArn is the KMS key the output is encrypted with on the server side (SSE-KMS)
+	KmsKeyArn string `json:"kmsKeyArn" yaml:"kmsKeyArn"`
+	// Acl is the canned ACL of the output, either bucket-owner-full-control or none
+	Acl string `json:"acl" yaml:"acl"`
+	// Endpoint is the S3 endpoint the output is uploaded to
+	Endpoint string `json:"endpoint" yaml:"endpoint"`
+}
+
 // IOConfiguration represents information relevant to the output sources of a command
 type IOConfiguration struct {
 	OrchestrationDirectory string
 	OutputS3BucketName     string
 	OutputS3KeyPrefix      string
+	OutputS3Options        S3OutputOptions
 	CloudWatchConfig       CloudWatchConfiguration
 }
 
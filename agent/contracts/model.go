@@ -15,6 +15,8 @@
 // necessary for communication and sharing within the agent.
 package contracts
 
+import "strings"
+
 // ResultStatus provides the granular status of a plugin.
 // These are internal states maintained by agent during the execution of a command/config
 type ResultStatus string
@@ -109,6 +111,8 @@ const (
 	AssociationStatusFailed = "Failed"
 	// AssociationStatusTimedOut represents TimedOut status
 	AssociationStatusTimedOut = "TimedOut"
+	// AssociationStatusDeferred represents Deferred status
+	AssociationStatusDeferred = "Deferred"
 )
 
 const (
@@ -128,6 +132,12 @@ const (
 	AssociationErrorCodeSubmitAssociationError = "SubmitAssocError"
 	// AssociationErrorCodeStuckAtInProgressError represents association stuck in InProgress Error
 	AssociationErrorCodeStuckAtInProgressError = "StuckAtInProgress"
+	// AssociationErrorCodeDryRunError represents a dry run which found steps that would fail
+	AssociationErrorCodeDryRunError = "DryRunError"
+	// AssociationErrorCodeCancelledError represents an association canceled on the instance
+	AssociationErrorCodeCancelledError = "Cancelled"
+	// AssociationErrorCodeExecutionTimedOutError represents a document stopped by the document execution limit
+	AssociationErrorCodeExecutionTimedOutError = "ExecutionTimedOut"
 	// AssociationErrorCodeNoError represents no error
 	AssociationErrorCodeNoError = ""
 )
@@ -137,6 +147,8 @@ const (
 	AssociationPendingMessage string = "Association is pending"
 	// DocumentInProgressMessage represents the summary message for inprogress association
 	AssociationInProgressMessage string = "Executing association"
+	// AssociationDeferredMessage represents the summary message for association deferred to the execution window
+	AssociationDeferredMessage string = "Association is deferred to the execution window starting at %v"
 )
 
 const (
@@ -180,7 +192,47 @@ type InstancePluginConfig struct {
 	OnFailure     string              `json:"onFailure" yaml:"onFailure"`
 	Settings      interface{}         `json:"settings" yaml:"settings"`
 	Timeout       int                 `json:"timeoutSeconds" yaml:"timeoutSeconds"`
+	OnTimeout     string              `json:"onTimeout" yaml:"onTimeout"`
 	Preconditions map[string][]string `json:"precondition" yaml:"precondition"`
+	Loop          *StepLoop           `json:"loop" yaml:"loop"`
+	// Retries is the number of times a failed step is run again, waiting RetryIntervalSeconds before each retry
+	Retries              int `json:"retries" yaml:"retries"`
+	RetryIntervalSeconds int `json:"retryIntervalSeconds" yaml:"retryIntervalSeconds"`
+}
+
+// StepLoop runs a step once for each item of a list, the inputs of the step refer to the current item as {{ variable }}
+type StepLoop struct {
+	Items    interface{} `json:"items" yaml:"items"`
+	Variable string      `json:"variable" yaml:"variable"`
+}
+
+// DefaultLoopVariable is the name the step inputs refer to the current loop item with if the loop doesn't name it
+const DefaultLoopVariable = "item"
+
+const (
+	// OnTimeoutContinue continues executing the remaining steps after a step times out
+	OnTimeoutContinue = "continue"
+	// OnTimeoutFail fails the step that timed out and does not execute the remaining steps
+	OnTimeoutFail = "fail"
+)
+
+const (
+	// OnFailureContinue continues executing the remaining steps after a step fails
+	OnFailureContinue = "continue"
+	// OnFailureRunStepPrefix precedes the name of the step that runs after a step fails, the remaining steps are executed after it
+	OnFailureRunStepPrefix = "runStep:"
+	// OnFailureRollbackStepPrefix precedes the name of the step that runs after a step fails, the remaining steps are skipped after it
+	OnFailureRollbackStepPrefix = "rollbackStep:"
+)
+
+// ParseOnFailure returns the prefix of the onFailure handler and the name of the step it runs, if any
+func ParseOnFailure(onFailure string) (prefix string, stepName string) {
+	for _, prefix := range []string{OnFailureRunStepPrefix, OnFailureRollbackStepPrefix} {
+		if strings.HasPrefix(onFailure, prefix) {
+			return prefix, strings.TrimPrefix(onFailure, prefix)
+		}
+	}
+	return onFailure, ""
 }
 
 // DocumentContent object which represents ssm document content.
@@ -190,6 +242,8 @@ type DocumentContent struct {
 	RuntimeConfig map[string]*PluginConfig `json:"runtimeConfig" yaml:"runtimeConfig"`
 	MainSteps     []*InstancePluginConfig  `json:"mainSteps" yaml:"mainSteps"`
 	Parameters    map[string]*Parameter    `json:"parameters" yaml:"parameters"`
+	// OutputS3Options sets how the output of the document is uploaded to S3
+	OutputS3Options *S3OutputOptions `json:"outputS3Options,omitempty" yaml:"outputS3Options,omitempty"`
 }
 
 // SessionInputs stores session configuration
@@ -200,6 +254,14 @@ type SessionInputs struct {
 	CloudWatchLogGroupName      string `json:"cloudWatchLogGroupName" yaml:"cloudWatchLogGroupName"`
 	CloudWatchEncryptionEnabled bool   `json:"cloudWatchEncryptionEnabled" yaml:"cloudWatchEncryptionEnabled"`
 	KmsKeyId                    string `json:"kmsKeyId" yaml:"kmsKeyId"`
+	// ShellProfile holds the commands run at the start of the interactive sessions, per platform
+	ShellProfile ShellProfileConfig `json:"shellProfile" yaml:"shellProfile"`
+}
+
+// ShellProfileConfig stores the commands run at the start of the interactive shell sessions of each platform
+type ShellProfileConfig struct {
+	Windows string `json:"windows" yaml:"windows"`
+	Linux   string `json:"linux" yaml:"linux"`
 }
 
 // SessionDocumentContent object which represents ssm session content.
@@ -210,6 +272,8 @@ type SessionDocumentContent struct {
 	Inputs          SessionInputs         `json:"inputs" yaml:"inputs"`
 	Parameters      map[string]*Parameter `json:"parameters" yaml:"parameters"`
 	SessionCommands []*SessionCommand     `json:"sessionCommands" yaml:"sessionCommands"`
+	// Properties configures the session plugins that don't run commands, like the destination of port sessions
+	Properties interface{} `json:"properties" yaml:"properties"`
 }
 
 // SessionCommand object represents session manager commands with cross-platform preconditions.
@@ -238,16 +302,18 @@ type AgentInfo struct {
 
 // PluginRuntimeStatus represents plugin runtime status section in agent response
 type PluginRuntimeStatus struct {
-	Status             ResultStatus `json:"status"`
-	Code               int          `json:"code"`
-	Name               string       `json:"name"`
-	Output             string       `json:"output"`
-	StartDateTime      string       `json:"startDateTime"`
-	EndDateTime        string       `json:"endDateTime"`
-	OutputS3BucketName string       `json:"outputS3BucketName"`
-	OutputS3KeyPrefix  string       `json:"outputS3KeyPrefix"`
-	StandardOutput     string       `json:"standardOutput"`
-	StandardError      string       `json:"standardError"`
+	Status             ResultStatus   `json:"status"`
+	Code               int            `json:"code"`
+	Name               string         `json:"name"`
+	Output             string         `json:"output"`
+	StartDateTime      string         `json:"startDateTime"`
+	EndDateTime        string         `json:"endDateTime"`
+	OutputS3BucketName string         `json:"outputS3BucketName"`
+	OutputS3KeyPrefix  string         `json:"outputS3KeyPrefix"`
+	StandardOutput     string         `json:"standardOutput"`
+	StandardError      string         `json:"standardError"`
+	Attempts           int            `json:"attempts,omitempty"`
+	ResourceUsage      *ResourceUsage `json:"resourceUsage,omitempty"`
 }
 
 // AgentConfiguration is a struct that stores information about the agent and instance
@@ -266,4 +332,6 @@ type DocumentResult struct {
 	Status          ResultStatus
 	LastPlugin      string
 	NPlugins        int
+	// ErrorCode explains a document level failure, it is empty unless the document was stopped by the agent
+	ErrorCode string `json:",omitempty"`
 }
@@ -40,6 +40,34 @@ type PluginResult struct {
 	Error              string       `json:"error"`
 	StandardOutput     string       `json:"standardOutput"`
 	StandardError      string       `json:"standardError"`
+	Attempts           int          `json:"attempts,omitempty"`
+	// ResourceUsage is nil when the plugin didn't run any process to completion
+	ResourceUsage *ResourceUsage `json:"resourceUsage,omitempty"`
+}
+
+// ResourceUsage is the resources used by the processes a plugin ran, including the child processes they waited for
+type ResourceUsage struct {
+	UserCPUSeconds   float64 `json:"userCpuSeconds"`
+	SystemCPUSeconds float64 `json:"systemCpuSeconds"`
+	// PeakMemoryKilobytes is the peak resident set size of the largest process, on Windows the peak memory
+	// committed by a process
+	PeakMemoryKilobytes int64 `json:"peakMemoryKilobytes"`
+	// ReadBytes and WriteBytes are the bytes the processes read from and wrote to the disks or, on Windows,
+	// through any I/O, they are only counted on Linux and Windows
+	ReadBytes  int64 `json:"readBytes,omitempty"`
+	WriteBytes int64 `json:"writeBytes,omitempty"`
+}
+
+// Add returns the usage of the processes of both usages, the peak memory is the larger of both
+func (usage ResourceUsage) Add(other ResourceUsage) ResourceUsage {
+	usage.UserCPUSeconds += other.UserCPUSeconds
+	usage.SystemCPUSeconds += other.SystemCPUSeconds
+	if other.PeakMemoryKilobytes > usage.PeakMemoryKilobytes {
+		usage.PeakMemoryKilobytes = other.PeakMemoryKilobytes
+	}
+	usage.ReadBytes += other.ReadBytes
+	usage.WriteBytes += other.WriteBytes
+	return usage
 }
 
 // IPlugin is interface for authoring a functionality of work.
@@ -84,8 +112,16 @@ type Configuration struct {
 	SessionId                   string
 	ClientId                    string
 	KmsKeyId                    string
+	ShellProfile                ShellProfileConfig
 	Commands                    string
 	RunAsElevated               bool
+	RunAsUser                   string
+	SessionOwner                string
+	TimeoutSeconds              int
+	OnTimeout                   string
+	OnFailure                   string
+	Retries                     int
+	RetryIntervalSeconds        int
 }
 
 // Plugin wraps the plugin configuration and plugin result.
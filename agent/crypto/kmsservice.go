@@ -53,6 +53,10 @@ func NewKMSService(log log.T) (kmsService *KMSService, err error) {
 		log.Warnf("Failed to load appconfig: %s. Using default config.", err)
 	} else if appConfig.Kms.Endpoint != "" {
 		awsConfig.Endpoint = &appConfig.Kms.Endpoint
+	} else if awsConfig.Region != nil {
+		if preferredEndpoint := appconfig.GetPreferredEndPoint(*awsConfig.Region, "kms"); preferredEndpoint != "" {
+			awsConfig.Endpoint = &preferredEndpoint
+		}
 	}
 	agentName = appConfig.Agent.Name
 	agentVersion = appConfig.Agent.Version
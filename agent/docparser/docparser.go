@@ -27,6 +27,7 @@ import (
 
 	"fmt"
 	"path/filepath"
+	"regexp"
 	"strings"
 )
 
@@ -34,6 +35,9 @@ const (
 	preconditionSchemaVersion string = "2.2"
 )
 
+// loopVariableRegex matches the names a step loop can give to its items, the same names parameters can have
+var loopVariableRegex = regexp.MustCompile("^[a-zA-Z0-9]+$")
+
 // DocumentParserInfo represents the parsed information from the request
 type DocumentParserInfo struct {
 	OrchestrationDir  string
@@ -84,12 +88,16 @@ func (docContent *DocContent) GetSchemaVersion() string {
 
 // GetIOConfiguration is a method used to get IO config from the document
 func (docContent *DocContent) GetIOConfiguration(parserInfo DocumentParserInfo) contracts.IOConfiguration {
-	return contracts.IOConfiguration{
+	ioConfig := contracts.IOConfiguration{
 		OrchestrationDirectory: parserInfo.OrchestrationDir,
 		OutputS3BucketName:     parserInfo.S3Bucket,
 		OutputS3KeyPrefix:      parserInfo.S3Prefix,
 		CloudWatchConfig:       parserInfo.CloudWatchConfig,
 	}
+	if docContent.OutputS3Options != nil {
+		ioConfig.OutputS3Options = *docContent.OutputS3Options
+	}
+	return ioConfig
 }
 
 // ParseDocument is a method used to parse documents that are not received by any service (MDS or State manager)
@@ -104,6 +112,9 @@ func (docContent *DocContent) ParseDocument(log log.T,
 	if err = getValidatedParameters(log, params, docContent); err != nil {
 		return
 	}
+	if err = expandStepLoops(log, docContent); err != nil {
+		return
+	}
 
 	return parseDocumentContent(*docContent, parserInfo)
 }
@@ -138,7 +149,7 @@ func (sessionDocContent *SessionDocContent) ParseDocument(log log.T,
 	resolvedDocContent, _ := jsonutil.MarshalIndent(*sessionDocContent)
 	log.Debugf("Resolved session document content %s", resolvedDocContent)
 
-	return sessionDocContent.parsePluginStateForStartSession(parserInfo, docInfo.DocumentID, docInfo.ClientId)
+	return sessionDocContent.parsePluginStateForStartSession(parserInfo, docInfo)
 }
 
 // validateAndReplaceSessionDocumentParameters validates the parameters and modifies the document content by replacing all parameters with their actual values.
@@ -197,6 +208,16 @@ func replaceValidatedSessionParameters(
 		docContent.SessionCommands = resolvedSessionCommands
 	}
 
+	if docContent.Properties != nil {
+		resolvedProperties := parameters.ReplaceParameters(docContent.Properties, params, logger)
+
+		// Resolve SSM Parameters
+		if resolvedProperties, err = parameterstore.Resolve(logger, resolvedProperties); err != nil {
+			return err
+		}
+		docContent.Properties = resolvedProperties
+	}
+
 	inputs := docContent.Inputs
 	var rawData map[string]interface{}
 	if err = jsonutil.Remarshal(inputs, &rawData); err != nil {
@@ -315,9 +336,40 @@ func parsePluginStateForV20Schema(
 	// set precondition flag based on document schema version
 	isPreconditionEnabled := isPreconditionEnabled(docContent.SchemaVersion)
 
+	stepNames := make(map[string]bool)
+	for _, instancePluginConfig := range docContent.MainSteps {
+		stepNames[instancePluginConfig.Name] = true
+	}
+
 	// getPluginConfigurations converts from PluginConfig (structure from the MDS message) to plugin.Configuration (structure expected by the plugin)
 	for _, instancePluginConfig := range docContent.MainSteps {
 		pluginName := instancePluginConfig.Action
+		if instancePluginConfig.Timeout < 0 {
+			return pluginsInfo, fmt.Errorf("Invalid timeoutSeconds %v for step %v", instancePluginConfig.Timeout, instancePluginConfig.Name)
+		}
+		if instancePluginConfig.Retries < 0 {
+			return pluginsInfo, fmt.Errorf("Invalid retries %v for step %v", instancePluginConfig.Retries, instancePluginConfig.Name)
+		}
+		if instancePluginConfig.RetryIntervalSeconds < 0 {
+			return pluginsInfo, fmt.Errorf("Invalid retryIntervalSeconds %v for step %v", instancePluginConfig.RetryIntervalSeconds, instancePluginConfig.Name)
+		}
+		switch instancePluginConfig.OnTimeout {
+		case "", contracts.OnTimeoutContinue, contracts.OnTimeoutFail:
+		default:
+			return pluginsInfo, fmt.Errorf("Invalid onTimeout %v for step %v, expected %v or %v",
+				instancePluginConfig.OnTimeout, instancePluginConfig.Name, contracts.OnTimeoutContinue, contracts.OnTimeoutFail)
+		}
+		switch prefix, stepName := contracts.ParseOnFailure(instancePluginConfig.OnFailure); prefix {
+		case "", contracts.OnFailureContinue:
+		case contracts.OnFailureRunStepPrefix, contracts.OnFailureRollbackStepPrefix:
+			if !stepNames[stepName] || stepName == instancePluginConfig.Name {
+				return pluginsInfo, fmt.Errorf("Invalid onFailure %v for step %v, step %v is not another step of the document",
+					instancePluginConfig.OnFailure, instancePluginConfig.Name, stepName)
+			}
+		default:
+			return pluginsInfo, fmt.Errorf("Invalid onFailure %v for step %v, expected %v, %v<step name> or %v<step name>",
+				instancePluginConfig.OnFailure, instancePluginConfig.Name, contracts.OnFailureContinue, contracts.OnFailureRunStepPrefix, contracts.OnFailureRollbackStepPrefix)
+		}
 		config := contracts.Configuration{
 			Settings:                instancePluginConfig.Settings,
 			Properties:              instancePluginConfig.Inputs,
@@ -331,6 +383,11 @@ func parsePluginStateForV20Schema(
 			Preconditions:           instancePluginConfig.Preconditions,
 			IsPreconditionEnabled:   isPreconditionEnabled,
 			DefaultWorkingDirectory: defaultWorkingDir,
+			TimeoutSeconds:          instancePluginConfig.Timeout,
+			OnTimeout:               instancePluginConfig.OnTimeout,
+			OnFailure:               instancePluginConfig.OnFailure,
+			Retries:                 instancePluginConfig.Retries,
+			RetryIntervalSeconds:    instancePluginConfig.RetryIntervalSeconds,
 		}
 
 		var plugin contracts.PluginState
@@ -345,8 +402,7 @@ func parsePluginStateForV20Schema(
 // parsePluginStateForStartSession initializes instancePluginsInfo for the docState. Used by startSession.
 func (sessionDocContent *SessionDocContent) parsePluginStateForStartSession(
 	parserInfo DocumentParserInfo,
-	sessionId string,
-	clientId string) (pluginsInfo []contracts.PluginState, err error) {
+	docInfo contracts.DocumentInfo) (pluginsInfo []contracts.PluginState, err error) {
 
 	// getPluginConfigurations converts from PluginConfig (structure from the MGS message) to plugin.Configuration (structure expected by the plugin)
 	pluginName := sessionDocContent.SessionType
@@ -358,19 +414,23 @@ func (sessionDocContent *SessionDocContent) parsePluginStateForStartSession(
 				PluginName:                  pluginName,
 				PluginID:                    pluginName,
 				DefaultWorkingDirectory:     parserInfo.DefaultWorkingDir,
-				SessionId:                   sessionId,
+				SessionId:                   docInfo.DocumentID,
 				OutputS3KeyPrefix:           sessionDocContent.Inputs.S3KeyPrefix,
 				OutputS3BucketName:          sessionDocContent.Inputs.S3BucketName,
 				S3EncryptionEnabled:         sessionDocContent.Inputs.S3EncryptionEnabled,
 				OrchestrationDirectory:      fileutil.BuildPath(parserInfo.OrchestrationDir, pluginName),
-				ClientId:                    clientId,
+				ClientId:                    docInfo.ClientId,
+				RunAsUser:                   docInfo.RunAsUser,
+				SessionOwner:                docInfo.SessionOwner,
 				CloudWatchLogGroup:          sessionDocContent.Inputs.CloudWatchLogGroupName,
 				CloudWatchEncryptionEnabled: sessionDocContent.Inputs.CloudWatchEncryptionEnabled,
 				KmsKeyId:                    sessionDocContent.Inputs.KmsKeyId,
+				ShellProfile:                sessionDocContent.Inputs.ShellProfile,
 				Commands:                    sessionCommandConfig.Commands,
 				IsPreconditionEnabled:       true,
 				Preconditions:               sessionCommandConfig.Preconditions,
 				RunAsElevated:               sessionCommandConfig.RunAsElevated,
+				Properties:                  sessionDocContent.Properties,
 			}
 
 			var plugin contracts.PluginState
@@ -386,15 +446,19 @@ func (sessionDocContent *SessionDocContent) parsePluginStateForStartSession(
 			PluginName:                  pluginName,
 			PluginID:                    pluginName,
 			DefaultWorkingDirectory:     parserInfo.DefaultWorkingDir,
-			SessionId:                   sessionId,
+			SessionId:                   docInfo.DocumentID,
 			OutputS3KeyPrefix:           sessionDocContent.Inputs.S3KeyPrefix,
 			OutputS3BucketName:          sessionDocContent.Inputs.S3BucketName,
 			S3EncryptionEnabled:         sessionDocContent.Inputs.S3EncryptionEnabled,
 			OrchestrationDirectory:      fileutil.BuildPath(parserInfo.OrchestrationDir, pluginName),
-			ClientId:                    clientId,
+			ClientId:                    docInfo.ClientId,
+			RunAsUser:                   docInfo.RunAsUser,
+			SessionOwner:                docInfo.SessionOwner,
 			CloudWatchLogGroup:          sessionDocContent.Inputs.CloudWatchLogGroupName,
 			CloudWatchEncryptionEnabled: sessionDocContent.Inputs.CloudWatchEncryptionEnabled,
 			KmsKeyId:                    sessionDocContent.Inputs.KmsKeyId,
+			ShellProfile:                sessionDocContent.Inputs.ShellProfile,
+			Properties:                  sessionDocContent.Properties,
 		}
 
 		var plugin contracts.PluginState
@@ -492,6 +556,10 @@ func replaceValidatedPluginParameters(
 			updatedMainSteps[index] = instancePluginConfig
 			updatedMainSteps[index].Settings = parameters.ReplaceParameters(instancePluginConfig.Settings, params, logger)
 			updatedMainSteps[index].Inputs = parameters.ReplaceParameters(instancePluginConfig.Inputs, params, logger)
+			updatedMainSteps[index].Preconditions = replacePreconditionParameters(instancePluginConfig.Preconditions, params, logger)
+			if instancePluginConfig.Loop != nil {
+				updatedMainSteps[index].Loop.Items = parameters.ReplaceParameters(instancePluginConfig.Loop.Items, params, logger)
+			}
 
 			logger.Debug("Resolving SSM parameters")
 			// Resolves SSM parameters
@@ -510,6 +578,63 @@ func replaceValidatedPluginParameters(
 	return nil
 }
 
+// replacePreconditionParameters replaces parameters within the operands of the preconditions,
+// so that steps can be executed or skipped based on the parameter values.
+func replacePreconditionParameters(preconditions map[string][]string, params map[string]interface{}, logger log.T) map[string][]string {
+	if preconditions == nil {
+		return nil
+	}
+	updatedPreconditions := make(map[string][]string)
+	for operator, operands := range preconditions {
+		updatedOperands := make([]string, len(operands))
+		for i, operand := range operands {
+			updatedOperands[i] = parameters.ReplaceParametersInString(operand, params, logger)
+		}
+		updatedPreconditions[operator] = updatedOperands
+	}
+	return updatedPreconditions
+}
+
+// expandStepLoops replaces each step with a loop by one step per item of the loop, named after the step and the index of the item.
+// The inputs and preconditions of each iteration have the loop variable replaced with the item.
+func expandStepLoops(log log.T, docContent *DocContent) error {
+	var expandedSteps []*contracts.InstancePluginConfig
+	for _, step := range docContent.MainSteps {
+		if step.Loop == nil {
+			expandedSteps = append(expandedSteps, step)
+			continue
+		}
+
+		variable := step.Loop.Variable
+		if variable == "" {
+			variable = contracts.DefaultLoopVariable
+		}
+		if !loopVariableRegex.MatchString(variable) {
+			return fmt.Errorf("Invalid loop variable %v for step %v, only letters and digits are allowed", variable, step.Name)
+		}
+		if _, ok := docContent.Parameters[variable]; ok {
+			return fmt.Errorf("Invalid loop variable %v for step %v, a document parameter has the same name", variable, step.Name)
+		}
+		items, ok := step.Loop.Items.([]interface{})
+		if !ok || len(items) == 0 {
+			return fmt.Errorf("Invalid loop items for step %v, expected a non-empty list", step.Name)
+		}
+
+		log.Debugf("Expanding step %v into %v iterations", step.Name, len(items))
+		for index, item := range items {
+			itemParams := map[string]interface{}{variable: item}
+			iteration := *step
+			iteration.Name = fmt.Sprintf("%v[%v]", step.Name, index)
+			iteration.Inputs = parameters.ReplaceParameters(step.Inputs, itemParams, log)
+			iteration.Preconditions = replacePreconditionParameters(step.Preconditions, itemParams, log)
+			iteration.Loop = nil
+			expandedSteps = append(expandedSteps, &iteration)
+		}
+	}
+	docContent.MainSteps = expandedSteps
+	return nil
+}
+
 // isPreConditionEnabled checks if precondition support is enabled by checking document schema version
 func isPreconditionEnabled(schemaVersion string) (response bool) {
 	response = false
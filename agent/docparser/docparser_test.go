@@ -594,3 +594,195 @@ func loadMessageFromFile(t *testing.T, fileName string) (testDocContent DocConte
 	}
 	return testDocContent, params
 }
+
+func TestParseDocument_StepTimeout(t *testing.T) {
+	mockLog := log.NewMockLog()
+	testParserInfo := DocumentParserInfo{
+		OrchestrationDir: testOrchDir,
+		MessageId:        testMessageID,
+		DocumentId:       testDocumentID,
+	}
+
+	var testDocContent DocContent
+	doc := `{"schemaVersion":"2.0","mainSteps":[{"action":"aws:runShellScript","name":"test","timeoutSeconds":60,"onTimeout":"fail","inputs":{"runCommand":["date"]}}]}`
+	err := json.Unmarshal([]byte(doc), &testDocContent)
+	assert.Nil(t, err)
+	pluginsInfo, err := testDocContent.ParseDocument(mockLog, contracts.DocumentInfo{}, testParserInfo, nil)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(pluginsInfo))
+	assert.Equal(t, 60, pluginsInfo[0].Configuration.TimeoutSeconds)
+	assert.Equal(t, contracts.OnTimeoutFail, pluginsInfo[0].Configuration.OnTimeout)
+}
+
+func TestParseDocument_InvalidOnTimeout(t *testing.T) {
+	mockLog := log.NewMockLog()
+	testParserInfo := DocumentParserInfo{
+		OrchestrationDir: testOrchDir,
+		MessageId:        testMessageID,
+		DocumentId:       testDocumentID,
+	}
+
+	var testDocContent DocContent
+	doc := `{"schemaVersion":"2.0","mainSteps":[{"action":"aws:runShellScript","name":"test","timeoutSeconds":60,"onTimeout":"retry","inputs":{"runCommand":["date"]}}]}`
+	err := json.Unmarshal([]byte(doc), &testDocContent)
+	assert.Nil(t, err)
+	_, err = testDocContent.ParseDocument(mockLog, contracts.DocumentInfo{}, testParserInfo, nil)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Invalid onTimeout")
+}
+
+func TestParseDocument_PreconditionParameters(t *testing.T) {
+	mockLog := log.NewMockLog()
+	testParserInfo := DocumentParserInfo{
+		OrchestrationDir: testOrchDir,
+		MessageId:        testMessageID,
+		DocumentId:       testDocumentID,
+	}
+
+	var testDocContent DocContent
+	doc := `{"schemaVersion":"2.2","parameters":{"mode":{"type":"String","default":"install"}},` +
+		`"mainSteps":[{"action":"aws:runShellScript","name":"test","precondition":{"StringEquals":["{{ mode }}","install"]},"inputs":{"runCommand":["date"]}}]}`
+	err := json.Unmarshal([]byte(doc), &testDocContent)
+	assert.Nil(t, err)
+	pluginsInfo, err := testDocContent.ParseDocument(mockLog, contracts.DocumentInfo{}, testParserInfo, map[string]interface{}{"mode": "uninstall"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(pluginsInfo))
+	assert.True(t, pluginsInfo[0].Configuration.IsPreconditionEnabled)
+	assert.Equal(t, map[string][]string{"StringEquals": {"uninstall", "install"}}, pluginsInfo[0].Configuration.Preconditions)
+}
+
+func TestParseDocument_StepLoop(t *testing.T) {
+	mockLog := log.NewMockLog()
+	testParserInfo := DocumentParserInfo{
+		OrchestrationDir: testOrchDir,
+		MessageId:        testMessageID,
+		DocumentId:       testDocumentID,
+	}
+
+	var testDocContent DocContent
+	doc := `{"schemaVersion":"2.2","parameters":{"packages":{"type":"StringList","default":["git"]}},"mainSteps":[` +
+		`{"action":"aws:runShellScript","name":"install","loop":{"items":"{{ packages }}","variable":"package"},"inputs":{"runCommand":["yum install -y {{ package }}"]}},` +
+		`{"action":"aws:runShellScript","name":"verify","inputs":{"runCommand":["rpm -q {{ packages }}"]}}]}`
+	err := json.Unmarshal([]byte(doc), &testDocContent)
+	assert.Nil(t, err)
+	pluginsInfo, err := testDocContent.ParseDocument(mockLog, contracts.DocumentInfo{}, testParserInfo, map[string]interface{}{"packages": []interface{}{"git", "jq"}})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(pluginsInfo))
+	assert.Equal(t, "install[0]", pluginsInfo[0].Id)
+	assert.Equal(t, "install[1]", pluginsInfo[1].Id)
+	assert.Equal(t, "verify", pluginsInfo[2].Id)
+	assert.Equal(t, map[string]interface{}{"runCommand": []interface{}{"yum install -y jq"}}, pluginsInfo[1].Configuration.Properties)
+	assert.Equal(t, filepath.Join(testOrchDir, "install[1]"), pluginsInfo[1].Configuration.OrchestrationDirectory)
+	assert.Equal(t, appconfig.PluginNameAwsRunShellScript, pluginsInfo[1].Name)
+}
+
+func TestParseDocument_InvalidStepLoop(t *testing.T) {
+	mockLog := log.NewMockLog()
+	testParserInfo := DocumentParserInfo{
+		OrchestrationDir: testOrchDir,
+		MessageId:        testMessageID,
+		DocumentId:       testDocumentID,
+	}
+
+	for _, loop := range []string{
+		`{"items":[]}`,
+		`{"items":"git"}`,
+		`{"items":["git"],"variable":"pack-age"}`,
+		`{"items":["git"],"variable":"packages"}`,
+	} {
+		var testDocContent DocContent
+		doc := `{"schemaVersion":"2.2","parameters":{"packages":{"type":"String","default":"git"}},"mainSteps":[` +
+			`{"action":"aws:runShellScript","name":"install","loop":` + loop + `,"inputs":{"runCommand":["yum install -y {{ item }}"]}}]}`
+		err := json.Unmarshal([]byte(doc), &testDocContent)
+		assert.Nil(t, err)
+		_, err = testDocContent.ParseDocument(mockLog, contracts.DocumentInfo{}, testParserInfo, nil)
+		assert.Error(t, err, loop)
+	}
+}
+
+func TestParseDocument_OnFailure(t *testing.T) {
+	mockLog := log.NewMockLog()
+	testParserInfo := DocumentParserInfo{
+		OrchestrationDir: testOrchDir,
+		MessageId:        testMessageID,
+		DocumentId:       testDocumentID,
+	}
+
+	for onFailure, valid := range map[string]bool{
+		"":                     true,
+		"continue":             true,
+		"rollbackStep:restore": true,
+		"runStep:restore":      true,
+		"runStep:update":       false,
+		"runStep:missing":      false,
+		"abort":                false,
+	} {
+		var testDocContent DocContent
+		doc := `{"schemaVersion":"2.2","mainSteps":[` +
+			`{"action":"aws:runShellScript","name":"update","onFailure":"` + onFailure + `","inputs":{"runCommand":["yum update -y"]}},` +
+			`{"action":"aws:runShellScript","name":"restore","inputs":{"runCommand":["systemctl start app"]}}]}`
+		err := json.Unmarshal([]byte(doc), &testDocContent)
+		assert.Nil(t, err)
+		pluginsInfo, err := testDocContent.ParseDocument(mockLog, contracts.DocumentInfo{}, testParserInfo, nil)
+		if valid {
+			assert.NoError(t, err, onFailure)
+			assert.Equal(t, onFailure, pluginsInfo[0].Configuration.OnFailure)
+		} else {
+			assert.Error(t, err, onFailure)
+		}
+	}
+}
+
+func TestParseDocument_StepRetries(t *testing.T) {
+	mockLog := log.NewMockLog()
+	testParserInfo := DocumentParserInfo{
+		OrchestrationDir: testOrchDir,
+		MessageId:        testMessageID,
+		DocumentId:       testDocumentID,
+	}
+
+	var testDocContent DocContent
+	doc := `{"schemaVersion":"2.2","mainSteps":[{"action":"aws:runShellScript","name":"test","retries":3,"retryIntervalSeconds":10,"inputs":{"runCommand":["yum install -y git"]}}]}`
+	err := json.Unmarshal([]byte(doc), &testDocContent)
+	assert.Nil(t, err)
+	pluginsInfo, err := testDocContent.ParseDocument(mockLog, contracts.DocumentInfo{}, testParserInfo, nil)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 3, pluginsInfo[0].Configuration.Retries)
+	assert.Equal(t, 10, pluginsInfo[0].Configuration.RetryIntervalSeconds)
+
+	doc = `{"schemaVersion":"2.2","mainSteps":[{"action":"aws:runShellScript","name":"test","retries":-1,"inputs":{"runCommand":["date"]}}]}`
+	testDocContent = DocContent{}
+	err = json.Unmarshal([]byte(doc), &testDocContent)
+	assert.Nil(t, err)
+	_, err = testDocContent.ParseDocument(mockLog, contracts.DocumentInfo{}, testParserInfo, nil)
+	assert.Error(t, err)
+}
+
+func TestInitializeDocState_OutputS3Options(t *testing.T) {
+	mockLog := log.NewMockLog()
+	testParserInfo := DocumentParserInfo{
+		OrchestrationDir: testOrchDir,
+		S3Bucket:         testS3Bucket,
+		S3Prefix:         testS3Prefix,
+		MessageId:        testMessageID,
+		DocumentId:       testDocumentID,
+	}
+
+	var testDocContent DocContent
+	doc := `{"schemaVersion":"2.2","outputS3Options":{"kmsKeyArn":"arn:aws:kms:us-east-1:123456789012:key/1234abcd","acl":"bucket-owner-full-control"},` +
+		`"mainSteps":[{"action":"aws:runShellScript","name":"test","inputs":{"runCommand":["date"]}}]}`
+	err := json.Unmarshal([]byte(doc), &testDocContent)
+	assert.Nil(t, err)
+	docState, err := InitializeDocState(mockLog, contracts.SendCommand, &testDocContent, contracts.DocumentInfo{}, testParserInfo, nil)
+
+	assert.Nil(t, err)
+	assert.Equal(t, contracts.S3OutputOptions{
+		KmsKeyArn: "arn:aws:kms:us-east-1:123456789012:key/1234abcd",
+		Acl:       "bucket-owner-full-control",
+	}, docState.IOConfig.OutputS3Options)
+}
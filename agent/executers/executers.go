@@ -22,6 +22,7 @@ import (
 	"os"
 	"os/exec"
 	"runtime"
+	"sort"
 	"strings"
 	"syscall"
 	"time"
@@ -42,10 +43,19 @@ const (
 type T interface {
 	//TODO: Remove Execute and rename NewExecute to Execute.
 	Execute(log.T, string, string, string, task.CancelFlag, int, string, []string) (io.Reader, io.Reader, int, []error)
-	NewExecute(log.T, string, io.Writer, io.Writer, task.CancelFlag, int, string, []string) (int, error)
+	NewExecute(log.T, string, io.Writer, io.Writer, task.CancelFlag, int, string, []string, map[string]string) (int, error)
+	NewExecuteAs(log.T, string, io.Writer, io.Writer, task.CancelFlag, int, string, []string, map[string]string, RunAs) (int, error)
 	StartExe(log.T, string, io.Writer, io.Writer, task.CancelFlag, string, []string) (*os.Process, int, error)
 }
 
+// RunAs is the account commands are executed as.
+type RunAs struct {
+	// User is the name of the user, the commands run as the agent user if it's empty
+	User string
+	// Group is the name of the primary group, the primary group of User is used if it's empty
+	Group string
+}
+
 // ShellCommandExecuter is specially added for testing purposes
 type ShellCommandExecuter struct {
 }
@@ -122,7 +132,7 @@ func (ShellCommandExecuter) Execute(
 	// writers as long as it is after the process starts.
 
 	var err error
-	exitCode, err = ExecuteCommand(log, cancelFlag, workingDir, stdoutWriter, stderrWriter, executionTimeout, commandName, commandArguments)
+	exitCode, err = ExecuteCommand(log, cancelFlag, workingDir, stdoutWriter, stderrWriter, executionTimeout, commandName, commandArguments, nil)
 	if err != nil {
 		errs = append(errs, err)
 	}
@@ -159,6 +169,7 @@ func (ShellCommandExecuter) Execute(
 }
 
 // NewExecute executes a list of shell commands in the given working directory and provides the stdout and stderr writers.
+// The given environment variables are set for the process in addition to the environment of the agent.
 func (ShellCommandExecuter) NewExecute(
 	log log.T,
 	workingDir string,
@@ -168,8 +179,27 @@ func (ShellCommandExecuter) NewExecute(
 	executionTimeout int,
 	commandName string,
 	commandArguments []string,
+	envVars map[string]string,
 ) (exitCode int, err error) {
-	exitCode, err = ExecuteCommand(log, cancelFlag, workingDir, stdoutWriter, stderrWriter, executionTimeout, commandName, commandArguments)
+	exitCode, err = ExecuteCommand(log, cancelFlag, workingDir, stdoutWriter, stderrWriter, executionTimeout, commandName, commandArguments, envVars)
+	return
+}
+
+// NewExecuteAs executes a list of shell commands in the given working directory as the given user.
+// The process gets the home directory and a default search path of the user, the given environment variables take precedence.
+func (ShellCommandExecuter) NewExecuteAs(
+	log log.T,
+	workingDir string,
+	stdoutWriter io.Writer,
+	stderrWriter io.Writer,
+	cancelFlag task.CancelFlag,
+	executionTimeout int,
+	commandName string,
+	commandArguments []string,
+	envVars map[string]string,
+	runAs RunAs,
+) (exitCode int, err error) {
+	exitCode, err = executeCommand(log, cancelFlag, workingDir, stdoutWriter, stderrWriter, executionTimeout, commandName, commandArguments, envVars, runAs)
 	return
 }
 
@@ -243,7 +273,7 @@ func (r *cancellableWriter) Write(p []byte) (n int, err error) {
 	}
 }
 
-// ExecuteCommand executes the given commands using the given working directory.
+// ExecuteCommand executes the given commands using the given working directory and additional environment variables.
 // Standard output and standard error are sent to the given writers.
 func ExecuteCommand(log log.T,
 	cancelFlag task.CancelFlag,
@@ -253,6 +283,22 @@ func ExecuteCommand(log log.T,
 	executionTimeout int,
 	commandName string,
 	commandArguments []string,
+	envVars map[string]string,
+) (exitCode int, err error) {
+	return executeCommand(log, cancelFlag, workingDir, stdoutWriter, stderrWriter, executionTimeout, commandName, commandArguments, envVars, RunAs{})
+}
+
+// executeCommand executes the given commands as the given user.
+func executeCommand(log log.T,
+	cancelFlag task.CancelFlag,
+	workingDir string,
+	stdoutWriter io.Writer,
+	stderrWriter io.Writer,
+	executionTimeout int,
+	commandName string,
+	commandArguments []string,
+	envVars map[string]string,
+	runAs RunAs,
 ) (exitCode int, err error) {
 
 	stdoutInterruptable, stopStdout := newWriter(stdoutWriter)
@@ -282,8 +328,22 @@ func ExecuteCommand(log log.T,
 	// configure OS-specific process settings
 	prepareProcess(command)
 
+	// configure the user the process runs as
+	userEnvVars, err := prepareRunAs(command, runAs)
+	if err != nil {
+		log.Errorf("failed to run the command as user %v: %v", runAs.User, err)
+		exitCode = 1
+		return
+	}
+	for name, value := range envVars {
+		userEnvVars[name] = value
+	}
+
 	// configure environment variables
-	prepareEnvironment(command)
+	prepareEnvironment(command, userEnvVars)
+
+	// configure the measure of the resources used by the process tree
+	prepareAccounting(command)
 
 	log.Debug()
 	log.Debugf("Running in directory %v, command: %v %v", workingDir, commandName, commandArguments)
@@ -293,6 +353,9 @@ func ExecuteCommand(log log.T,
 		exitCode = 1
 		return
 	}
+	// measure the resources used by the process tree, the usage is recorded when the process completes
+	resources := startAccounting(log, command.Process)
+	defer resources.close()
 
 	signal := timeoutSignal{}
 
@@ -340,6 +403,9 @@ func ExecuteCommand(log log.T,
 		}
 	case err = <-done:
 		log.Debug("Process completed.")
+		if usage, ok := resources.usage(command.ProcessState); ok {
+			recordResourceUsage(usage, stderrWriter, stdoutWriter)
+		}
 		if err != nil {
 			exitCode = 1
 			log.Debugf("command returned error %v", err)
@@ -400,7 +466,7 @@ func StartCommand(log log.T,
 	prepareProcess(command)
 
 	// configure environment variables
-	prepareEnvironment(command)
+	prepareEnvironment(command, nil)
 
 	log.Debug()
 	log.Debugf("Running in directory %v, command: %v %v", workingDir, commandName, commandArguments)
@@ -445,8 +511,8 @@ func killProcessOnCancel(log log.T, command *exec.Cmd, cancelStdout chan bool, c
 	}
 }
 
-// prepareEnvironment adds ssm agent standard environment variables to the command
-func prepareEnvironment(command *exec.Cmd) {
+// prepareEnvironment adds ssm agent standard environment variables and the given variables to the command
+func prepareEnvironment(command *exec.Cmd, envVars map[string]string) {
 	env := os.Environ()
 	if instance, err := instance.InstanceID(); err == nil {
 		env = append(env, fmtEnvVariable(envVarInstanceID, instance))
@@ -454,6 +520,15 @@ func prepareEnvironment(command *exec.Cmd) {
 	if region, err := instance.Region(); err == nil {
 		env = append(env, fmtEnvVariable(envVarRegionName, region))
 	}
+	// sort the names so the environment of the process doesn't change between runs
+	names := make([]string, 0, len(envVars))
+	for name := range envVars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		env = append(env, fmtEnvVariable(name, envVars[name]))
+	}
 	command.Env = env
 
 	// Running powershell on linux erquired the HOME env variable to be set and to remove the TERM env variable
@@ -327,7 +327,7 @@ func prepareTestShellCommandExecuter(t *testing.T) (orchestrationDir string, com
 
 		// Used to mimic the process
 		CreateScriptFile(scriptPath, commands)
-		return sh.Execute(logger, workDir, stdoutFilePath, stderrFilePath, cancelFlag, defaultExecutionTimeout, commands[0], commands[1:])
+		return sh.Execute(logger, workDir, stdoutFilePath, stderrFilePath, cancelFlag, defaultExecutionTimeout, commands[0], commands[1:], nil)
 	}
 
 	return
@@ -343,7 +343,7 @@ func prepareTestRunCommand(t *testing.T) (commandInvoker CommandInvoker, cancelF
 		var stdoutBuf bytes.Buffer
 		var stderrBuf bytes.Buffer
 		workDir := "."
-		tempExitCode, err := ExecuteCommand(logger, cancelFlag, workDir, &stdoutBuf, &stderrBuf, defaultExecutionTimeout, commands[0], commands[1:])
+		tempExitCode, err := ExecuteCommand(logger, cancelFlag, workDir, &stdoutBuf, &stderrBuf, defaultExecutionTimeout, commands[0], commands[1:], nil)
 		exitCode = tempExitCode
 
 		// record error if any
@@ -388,7 +388,7 @@ func prepareTestStartCommand(t *testing.T) (commandInvoker CommandInvoker, cance
 		defer os.Remove(stdoutFilePath)
 
 		workDir := "."
-		process, tempExitCode, err := StartCommand(logger, cancelFlag, workDir, stdoutWriter, stderrWriter, commands[0], commands[1:])
+		process, tempExitCode, err := StartCommand(logger, cancelFlag, workDir, stdoutWriter, stderrWriter, commands[0], commands[1:], nil)
 		stdoutWriter.Close()
 		stderrWriter.Close()
 		exitCode = tempExitCode
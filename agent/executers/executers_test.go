@@ -72,19 +72,32 @@ func TestEnvironmentVariables_All(t *testing.T) {
 	defer func() { instance = instanceTemp }()
 
 	command := getTestCommand(t)
-	prepareEnvironment(command)
+	prepareEnvironment(command, nil)
 
 	assert.Equal(t, getEnvVariableValue(command.Env, envVarInstanceID), testInstanceID)
 	assert.Equal(t, getEnvVariableValue(command.Env, envVarRegionName), testRegionName)
 }
 
+func TestEnvironmentVariables_Additional(t *testing.T) {
+	instanceTemp := instance
+	instance = &instanceInfoStub{instanceID: testInstanceID, regionName: testRegionName}
+	defer func() { instance = instanceTemp }()
+
+	command := getTestCommand(t)
+	prepareEnvironment(command, map[string]string{"DB_PASSWORD": "pa$$word=1", "APP_ENV": "prod"})
+
+	assert.Equal(t, getEnvVariableValue(command.Env, envVarInstanceID), testInstanceID)
+	assert.Equal(t, "pa$$word=1", getEnvVariableValue(command.Env, "DB_PASSWORD"))
+	assert.Equal(t, "prod", getEnvVariableValue(command.Env, "APP_ENV"))
+}
+
 func TestEnvironmentVariables_None(t *testing.T) {
 	instanceTemp := instance
 	instance = &instanceInfoStub{"", errors.New(testError), "", errors.New(testError)}
 	defer func() { instance = instanceTemp }()
 
 	command := getTestCommand(t)
-	prepareEnvironment(command)
+	prepareEnvironment(command, nil)
 
 	assert.Empty(t, getEnvVariableValue(command.Env, envVarInstanceID))
 	assert.Empty(t, getEnvVariableValue(command.Env, envVarRegionName))
@@ -16,8 +16,12 @@
 package executers
 
 import (
+	"fmt"
 	"os"
 	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"syscall"
 
@@ -30,6 +34,91 @@ func prepareProcess(command *exec.Cmd) {
 	command.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
 }
 
+// default search paths for commands run as another user, the same as su uses for login shells
+const (
+	userPath = "/usr/local/bin:/usr/bin:/bin"
+	rootPath = "/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"
+)
+
+// prepareRunAs makes the process run with the ids of the given user and group.
+// Returns the environment variables describing the user that the process should get.
+func prepareRunAs(command *exec.Cmd, runAs RunAs) (envVars map[string]string, err error) {
+	envVars = make(map[string]string)
+	if runAs.User == "" {
+		if runAs.Group != "" {
+			return nil, fmt.Errorf("a group can only be set together with a user")
+		}
+		return envVars, nil
+	}
+
+	credential, account, err := lookupCredential(runAs)
+	if err != nil {
+		return nil, err
+	}
+	command.SysProcAttr.Credential = credential
+
+	envVars["HOME"] = account.HomeDir
+	envVars["USER"] = account.Username
+	envVars["LOGNAME"] = account.Username
+	envVars["PATH"] = userPath
+	if credential.Uid == 0 {
+		envVars["PATH"] = rootPath
+	}
+	return envVars, nil
+}
+
+// lookupCredential returns the ids of the user, its primary group (or the given group) and its supplementary groups.
+func lookupCredential(runAs RunAs) (credential *syscall.Credential, account *user.User, err error) {
+	if account, err = user.Lookup(runAs.User); err != nil {
+		return nil, nil, fmt.Errorf("user %v doesn't exist: %v", runAs.User, err)
+	}
+	gid := account.Gid
+	if runAs.Group != "" {
+		group, err := user.LookupGroup(runAs.Group)
+		if err != nil {
+			return nil, nil, fmt.Errorf("group %v doesn't exist: %v", runAs.Group, err)
+		}
+		gid = group.Gid
+	}
+
+	credential = &syscall.Credential{}
+	if credential.Uid, err = parseID(account.Uid); err != nil {
+		return nil, nil, err
+	}
+	if credential.Gid, err = parseID(gid); err != nil {
+		return nil, nil, err
+	}
+	groupIds, err := account.GroupIds()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to look up the groups of user %v: %v", runAs.User, err)
+	}
+	for _, groupID := range groupIds {
+		id, err := parseID(groupID)
+		if err != nil {
+			return nil, nil, err
+		}
+		credential.Groups = append(credential.Groups, id)
+	}
+	return credential, account, nil
+}
+
+func parseID(id string) (uint32, error) {
+	value, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid id %v: %v", id, err)
+	}
+	return uint32(value), nil
+}
+
+// ChangeOwner makes the given user and group the owner of the file, so that commands run as them can access it.
+func ChangeOwner(path string, runAs RunAs) error {
+	credential, _, err := lookupCredential(runAs)
+	if err != nil {
+		return err
+	}
+	return os.Chown(path, int(credential.Uid), int(credential.Gid))
+}
+
 func killProcess(process *os.Process, signal *timeoutSignal) error {
 	//   NOTE: go only kills the process but not its sub processes.
 	//   The consequence is that command.Wait() does not return, for some reason.
@@ -46,9 +135,11 @@ func killProcess(process *os.Process, signal *timeoutSignal) error {
 // Running powershell on linux erquired the HOME env variable to be set and to remove the TERM env variable
 func validateEnvironmentVariables(command *exec.Cmd) {
 
-	if command.Path == appconfig.PowerShellPluginCommandName {
+	if isPowerShell(command.Path) {
 		env := command.Env
-		env = append(env, fmtEnvVariable("HOME", "/"))
+		if !hasEnvVariable(env, "HOME") {
+			env = append(env, fmtEnvVariable("HOME", "/"))
+		}
 		i := 0
 		for _, a := range env {
 			if strings.Contains(a, "TERM") {
@@ -64,3 +155,19 @@ func validateEnvironmentVariables(command *exec.Cmd) {
 		command.Env = env
 	}
 }
+
+// isPowerShell checks whether the command is PowerShell, whichever path it's installed to
+func isPowerShell(commandPath string) bool {
+	name := filepath.Base(commandPath)
+	return name == "pwsh" || name == "powershell" || commandPath == appconfig.PowerShellPluginCommandName
+}
+
+// hasEnvVariable checks whether the variable is set in the environment
+func hasEnvVariable(env []string, name string) bool {
+	for _, variable := range env {
+		if strings.HasPrefix(variable, name+"=") {
+			return true
+		}
+	}
+	return false
+}
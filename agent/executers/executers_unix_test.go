@@ -0,0 +1,125 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// +build darwin freebsd linux netbsd openbsd
+
+package executers
+
+import (
+	"bytes"
+	"os/exec"
+	"os/user"
+	"strconv"
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrepareRunAs(t *testing.T) {
+	current, err := user.Current()
+	assert.NoError(t, err)
+
+	command := exec.Command("test")
+	prepareProcess(command)
+	envVars, err := prepareRunAs(command, RunAs{User: current.Username})
+	assert.NoError(t, err)
+
+	assert.Equal(t, current.Uid, strconv.Itoa(int(command.SysProcAttr.Credential.Uid)))
+	assert.Equal(t, current.Gid, strconv.Itoa(int(command.SysProcAttr.Credential.Gid)))
+	assert.True(t, command.SysProcAttr.Setpgid)
+	assert.Equal(t, current.HomeDir, envVars["HOME"])
+	assert.Equal(t, current.Username, envVars["USER"])
+	assert.NotEmpty(t, envVars["PATH"])
+}
+
+func TestPrepareRunAs_AgentUser(t *testing.T) {
+	command := exec.Command("test")
+	prepareProcess(command)
+	envVars, err := prepareRunAs(command, RunAs{})
+	assert.NoError(t, err)
+	assert.Nil(t, command.SysProcAttr.Credential)
+	assert.Empty(t, envVars)
+}
+
+func TestPrepareRunAs_Invalid(t *testing.T) {
+	for _, runAs := range []RunAs{
+		{User: "ssm-agent-test-no-such-user"},
+		{User: "root", Group: "ssm-agent-test-no-such-group"},
+		{Group: "root"},
+	} {
+		command := exec.Command("test")
+		prepareProcess(command)
+		_, err := prepareRunAs(command, runAs)
+		assert.Error(t, err, "%v", runAs)
+		assert.Nil(t, command.SysProcAttr.Credential)
+	}
+}
+
+func TestValidateEnvironmentVariables_PowerShell(t *testing.T) {
+	command := exec.Command("/opt/microsoft/powershell/7/pwsh")
+	command.Env = []string{"TERM=xterm", "PATH=/usr/bin"}
+	validateEnvironmentVariables(command)
+	assert.Equal(t, []string{"PATH=/usr/bin", "HOME=/"}, command.Env)
+
+	// the home directory of the user the commands run as is kept
+	command = exec.Command("/usr/bin/pwsh")
+	command.Env = []string{"HOME=/home/ssm-user"}
+	validateEnvironmentVariables(command)
+	assert.Equal(t, []string{"HOME=/home/ssm-user"}, command.Env)
+
+	command = exec.Command("/bin/sh")
+	command.Env = []string{"TERM=xterm"}
+	validateEnvironmentVariables(command)
+	assert.Equal(t, []string{"TERM=xterm"}, command.Env)
+}
+
+// recordingWriter records the resource usage of the processes writing to it
+type recordingWriter struct {
+	bytes.Buffer
+	usages []contracts.ResourceUsage
+}
+
+func (w *recordingWriter) RecordResourceUsage(usage contracts.ResourceUsage) {
+	w.usages = append(w.usages, usage)
+}
+
+func TestAccountingUsage(t *testing.T) {
+	command := exec.Command("sh", "-c", "echo done")
+	assert.NoError(t, command.Start())
+	resources := startAccounting(log.NewMockLog(), command.Process)
+	defer resources.close()
+	assert.NoError(t, command.Wait())
+
+	usage, ok := resources.usage(command.ProcessState)
+	assert.True(t, ok)
+	assert.True(t, usage.PeakMemoryKilobytes > 0)
+	assert.True(t, usage.UserCPUSeconds >= 0)
+
+	_, ok = resources.usage(nil)
+	assert.False(t, ok)
+}
+
+func TestRecordResourceUsage(t *testing.T) {
+	usage := contracts.ResourceUsage{UserCPUSeconds: 1}
+	stdout := &recordingWriter{}
+	stderr := &recordingWriter{}
+
+	recordResourceUsage(usage, &bytes.Buffer{}, stderr, stdout)
+	assert.Equal(t, []contracts.ResourceUsage{usage}, stderr.usages)
+	assert.Empty(t, stdout.usages)
+
+	// nothing records the usage
+	recordResourceUsage(usage, &bytes.Buffer{})
+}
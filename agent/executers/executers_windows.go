@@ -16,6 +16,7 @@
 package executers
 
 import (
+	"fmt"
 	"os"
 	"os/exec"
 )
@@ -28,6 +29,19 @@ func prepareProcess(command *exec.Cmd) {
 	// nothing to do on windows
 }
 
+// prepareRunAs fails if a user is given, commands always run as the agent user on windows.
+func prepareRunAs(command *exec.Cmd, runAs RunAs) (envVars map[string]string, err error) {
+	if runAs.User != "" || runAs.Group != "" {
+		return nil, fmt.Errorf("running commands as another user is not supported on windows")
+	}
+	return make(map[string]string), nil
+}
+
+// ChangeOwner is not supported on windows.
+func ChangeOwner(path string, runAs RunAs) error {
+	return fmt.Errorf("running commands as another user is not supported on windows")
+}
+
 func killProcess(process *os.Process, signal *timeoutSignal) error {
 	// process kill doesn't send proper signal to the process status
 	// Setting the signal to indicate execution was interrupted
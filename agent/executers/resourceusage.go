@@ -0,0 +1,38 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package executers contains general purpose (shell) command executing objects.
+package executers
+
+import (
+	"io"
+
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+)
+
+// ResourceUsageRecorder is implemented by the output writers that record the resource usage
+// of the processes writing to them, such as the writers of the plugin output.
+type ResourceUsageRecorder interface {
+	RecordResourceUsage(usage contracts.ResourceUsage)
+}
+
+// recordResourceUsage reports the resource usage of a completed process to the first writer recording it.
+// Standard error is checked first since plugins sometimes wrap their standard output writer.
+func recordResourceUsage(usage contracts.ResourceUsage, writers ...io.Writer) {
+	for _, writer := range writers {
+		if recorder, ok := writer.(ResourceUsageRecorder); ok {
+			recorder.RecordResourceUsage(usage)
+			return
+		}
+	}
+}
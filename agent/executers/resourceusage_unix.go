@@ -0,0 +1,68 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// +build darwin freebsd linux netbsd openbsd
+
+package executers
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+	"syscall"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+)
+
+// blockSize is the unit of the blocks read and written counted by Linux
+const blockSize = 512
+
+// accounting measures the resource usage of a process, the usage returned by wait includes
+// the child processes the process waited for
+type accounting struct{}
+
+// prepareAccounting has nothing to prepare, the usage is returned by wait
+func prepareAccounting(command *exec.Cmd) {}
+
+func startAccounting(log log.T, process *os.Process) *accounting {
+	return &accounting{}
+}
+
+// usage returns the resource usage of the exited process
+func (a *accounting) usage(state *os.ProcessState) (usage contracts.ResourceUsage, ok bool) {
+	if state == nil {
+		return usage, false
+	}
+	rusage, ok := state.SysUsage().(*syscall.Rusage)
+	if !ok {
+		return usage, false
+	}
+
+	usage.UserCPUSeconds = time.Duration(rusage.Utime.Nano()).Seconds()
+	usage.SystemCPUSeconds = time.Duration(rusage.Stime.Nano()).Seconds()
+	// ru_maxrss is in bytes on macOS and in kilobytes elsewhere
+	usage.PeakMemoryKilobytes = int64(rusage.Maxrss)
+	if runtime.GOOS == "darwin" {
+		usage.PeakMemoryKilobytes /= 1024
+	}
+	// the other systems count block operations of any size
+	if runtime.GOOS == "linux" {
+		usage.ReadBytes = int64(rusage.Inblock) * blockSize
+		usage.WriteBytes = int64(rusage.Oublock) * blockSize
+	}
+	return usage, true
+}
+
+func (a *accounting) close() {}
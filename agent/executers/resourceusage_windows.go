@@ -0,0 +1,189 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// +build windows
+
+package executers
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+)
+
+const (
+	jobObjectBasicAndIoAccountingInformation = 8
+	jobObjectExtendedLimitInformation        = 9
+	processSetQuotaAccess                    = 0x100
+	processTerminateAccess                   = 0x1
+	processSuspendResumeAccess               = 0x800
+	createSuspended                          = 0x4
+)
+
+var (
+	kernel32                      = syscall.NewLazyDLL("kernel32.dll")
+	procCreateJobObjectW          = kernel32.NewProc("CreateJobObjectW")
+	procAssignProcessToJobObject  = kernel32.NewProc("AssignProcessToJobObject")
+	procQueryInformationJobObject = kernel32.NewProc("QueryInformationJobObject")
+
+	ntdll               = syscall.NewLazyDLL("ntdll.dll")
+	procNtResumeProcess = ntdll.NewProc("NtResumeProcess")
+)
+
+type ioCounters struct {
+	ReadOperationCount  uint64
+	WriteOperationCount uint64
+	OtherOperationCount uint64
+	ReadTransferCount   uint64
+	WriteTransferCount  uint64
+	OtherTransferCount  uint64
+}
+
+type jobObjectBasicAndIoAccounting struct {
+	TotalUserTime             int64
+	TotalKernelTime           int64
+	ThisPeriodTotalUserTime   int64
+	ThisPeriodTotalKernelTime int64
+	TotalPageFaultCount       uint32
+	TotalProcesses            uint32
+	ActiveProcesses           uint32
+	TotalTerminatedProcesses  uint32
+	IoInfo                    ioCounters
+}
+
+type jobObjectBasicLimit struct {
+	PerProcessUserTimeLimit int64
+	PerJobUserTimeLimit     int64
+	LimitFlags              uint32
+	MinimumWorkingSetSize   uintptr
+	MaximumWorkingSetSize   uintptr
+	ActiveProcessLimit      uint32
+	Affinity                uintptr
+	PriorityClass           uint32
+	SchedulingClass         uint32
+}
+
+type jobObjectExtendedLimit struct {
+	BasicLimitInformation jobObjectBasicLimit
+	IoInfo                ioCounters
+	ProcessMemoryLimit    uintptr
+	JobMemoryLimit        uintptr
+	PeakProcessMemoryUsed uintptr
+	PeakJobMemoryUsed     uintptr
+}
+
+// accounting measures the resource usage of a process and the processes it starts with a job object,
+// when the process can't be assigned to a job only its own processor times are measured
+type accounting struct {
+	job syscall.Handle
+}
+
+// prepareAccounting makes the process start suspended, so that it's assigned to the job object
+// before it runs and starts other processes
+func prepareAccounting(command *exec.Cmd) {
+	if command.SysProcAttr == nil {
+		command.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	command.SysProcAttr.CreationFlags |= createSuspended
+}
+
+// startAccounting assigns the suspended process to a new job object and resumes it
+func startAccounting(log log.T, process *os.Process) (a *accounting) {
+	a = &accounting{}
+	handle, err := syscall.OpenProcess(processSetQuotaAccess|processTerminateAccess|processSuspendResumeAccess, false, uint32(process.Pid))
+	if err != nil {
+		// the process can't be resumed without a handle, it would never run
+		log.Errorf("Failed to open the process to start it, %v", err)
+		process.Kill()
+		return
+	}
+	defer syscall.CloseHandle(handle)
+	defer resumeProcess(log, process, handle)
+
+	job, _, err := procCreateJobObjectW.Call(0, 0)
+	if job == 0 {
+		log.Debugf("Failed to create the job object measuring the resource usage, %v", err)
+		return
+	}
+	if ret, _, err := procAssignProcessToJobObject.Call(job, uintptr(handle)); ret == 0 {
+		log.Debugf("Failed to assign the process to the job object measuring its resource usage, %v", err)
+		syscall.CloseHandle(syscall.Handle(job))
+		return
+	}
+	a.job = syscall.Handle(job)
+	return
+}
+
+// resumeProcess resumes the process started suspended, the process is killed if it can't be resumed
+func resumeProcess(log log.T, process *os.Process, handle syscall.Handle) {
+	if status, _, _ := procNtResumeProcess.Call(uintptr(handle)); status != 0 {
+		log.Errorf("Failed to resume the process, status %#x", status)
+		process.Kill()
+	}
+}
+
+// usage returns the resource usage of the exited process and the processes of its job
+func (a *accounting) usage(state *os.ProcessState) (usage contracts.ResourceUsage, ok bool) {
+	if a.job != 0 {
+		var accountingInfo jobObjectBasicAndIoAccounting
+		var limitInfo jobObjectExtendedLimit
+		if queryJob(a.job, jobObjectBasicAndIoAccountingInformation, unsafe.Pointer(&accountingInfo), unsafe.Sizeof(accountingInfo)) &&
+			queryJob(a.job, jobObjectExtendedLimitInformation, unsafe.Pointer(&limitInfo), unsafe.Sizeof(limitInfo)) {
+			usage.UserCPUSeconds = hundredNanoseconds(accountingInfo.TotalUserTime)
+			usage.SystemCPUSeconds = hundredNanoseconds(accountingInfo.TotalKernelTime)
+			usage.PeakMemoryKilobytes = int64(limitInfo.PeakProcessMemoryUsed / 1024)
+			usage.ReadBytes = int64(accountingInfo.IoInfo.ReadTransferCount)
+			usage.WriteBytes = int64(accountingInfo.IoInfo.WriteTransferCount)
+			return usage, true
+		}
+	}
+
+	if state == nil {
+		return usage, false
+	}
+	rusage, ok := state.SysUsage().(*syscall.Rusage)
+	if !ok {
+		return usage, false
+	}
+	usage.UserCPUSeconds = hundredNanoseconds(filetimeTicks(rusage.UserTime))
+	usage.SystemCPUSeconds = hundredNanoseconds(filetimeTicks(rusage.KernelTime))
+	return usage, true
+}
+
+// close closes the job object, the processes still running in the job keep running
+func (a *accounting) close() {
+	if a.job != 0 {
+		syscall.CloseHandle(a.job)
+		a.job = 0
+	}
+}
+
+// queryJob reads the information of the given class of the job
+func queryJob(job syscall.Handle, class uintptr, info unsafe.Pointer, size uintptr) bool {
+	ret, _, _ := procQueryInformationJobObject.Call(uintptr(job), class, uintptr(info), size, 0)
+	return ret != 0
+}
+
+// filetimeTicks returns the duration of a FILETIME in 100-nanosecond ticks
+func filetimeTicks(filetime syscall.Filetime) int64 {
+	return int64(filetime.HighDateTime)<<32 | int64(filetime.LowDateTime)
+}
+
+func hundredNanoseconds(ticks int64) float64 {
+	return (time.Duration(ticks) * 100).Seconds()
+}
@@ -55,8 +55,27 @@ func (m *MockCommandExecuter) NewExecute(
 	executionTimeout int,
 	commandName string,
 	commandArguments []string,
+	envVars map[string]string,
 ) (exitCode int, err error) {
-	args := m.Called(log, workingDir, stdoutWriter, stderrWriter, cancelFlag, executionTimeout, commandName, commandArguments)
+	args := m.Called(log, workingDir, stdoutWriter, stderrWriter, cancelFlag, executionTimeout, commandName, commandArguments, envVars)
+	log.Infof("args are %v", args)
+	return args.Get(0).(int), args.Error(1)
+}
+
+// NewExecuteAs is a mocked method that just returns what mock tells it to.
+func (m *MockCommandExecuter) NewExecuteAs(
+	log log.T,
+	workingDir string,
+	stdoutWriter io.Writer,
+	stderrWriter io.Writer,
+	cancelFlag task.CancelFlag,
+	executionTimeout int,
+	commandName string,
+	commandArguments []string,
+	envVars map[string]string,
+	runAs RunAs,
+) (exitCode int, err error) {
+	args := m.Called(log, workingDir, stdoutWriter, stderrWriter, cancelFlag, executionTimeout, commandName, commandArguments, envVars, runAs)
 	log.Infof("args are %v", args)
 	return args.Get(0).(int), args.Error(1)
 }
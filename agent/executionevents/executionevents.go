@@ -0,0 +1,159 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package executionevents reports the start and the end of the documents and the sessions to the Windows event log,
+// so that Windows monitoring and auditing tools can consume them.
+//
+// The events are written to the Amazon SSM Agent log by the SSMAgentExecution source, with an event id per kind of
+// event and a message listing the fields of the execution, one Name: value per line.
+package executionevents
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+)
+
+const (
+	// LogName is the name of the Windows event log of the events
+	LogName = "Amazon SSM Agent"
+	// Source is the source of the events in the Windows event log
+	Source = "SSMAgentExecution"
+)
+
+// EventType is the severity of an event
+type EventType int
+
+const (
+	// TypeInformation is the type of the events of executions that started or succeeded
+	TypeInformation EventType = iota
+	// TypeWarning is the type of the events of executions that were cancelled
+	TypeWarning
+	// TypeError is the type of the events of executions that failed or timed out
+	TypeError
+)
+
+// Event ids, the document events are in the 100 range and the session events in the 200 range
+const (
+	DocumentStarted         uint32 = 100
+	DocumentSucceeded       uint32 = 101
+	DocumentFailed          uint32 = 102
+	DocumentTimedOut        uint32 = 103
+	DocumentCancelled       uint32 = 104
+	DocumentRebootRequested uint32 = 105
+	SessionOpened           uint32 = 200
+	SessionClosed           uint32 = 201
+	SessionFailed           uint32 = 202
+	SessionTerminated       uint32 = 203
+)
+
+// Event is an event of the Windows event log
+type Event struct {
+	ID      uint32
+	Type    EventType
+	Summary string
+	Fields  []Field
+}
+
+// Field is a field of the execution the event is reported for
+type Field struct {
+	Name  string
+	Value string
+}
+
+// Message returns the summary of the event followed by its fields, the fields with an empty value are skipped
+func (e Event) Message() string {
+	lines := []string{e.Summary, ""}
+	for _, field := range e.Fields {
+		if field.Value != "" {
+			lines = append(lines, field.Name+": "+field.Value)
+		}
+	}
+	return strings.Join(lines, "\r\n")
+}
+
+// ReportStarted reports the start of the document or the session
+func ReportStarted(context context.T, docState *contracts.DocumentState) {
+	write(context, startedEvent(docState))
+}
+
+// ReportFinished reports the end of the document or the session with its final status
+func ReportFinished(context context.T, docState *contracts.DocumentState, status contracts.ResultStatus) {
+	write(context, finishedEvent(docState, status))
+}
+
+// startedEvent returns the event of the start of the document or the session
+func startedEvent(docState *contracts.DocumentState) Event {
+	if docState.DocumentType == contracts.StartSession {
+		return Event{ID: SessionOpened, Type: TypeInformation, Summary: "Session opened.", Fields: sessionFields(docState, "")}
+	}
+	return Event{ID: DocumentStarted, Type: TypeInformation, Summary: "Document started.", Fields: documentFields(docState, "")}
+}
+
+// finishedEvent returns the event of the end of the document or the session with the status
+func finishedEvent(docState *contracts.DocumentState, status contracts.ResultStatus) Event {
+	if docState.DocumentType == contracts.StartSession {
+		event := Event{ID: SessionClosed, Type: TypeInformation, Summary: "Session closed.", Fields: sessionFields(docState, status)}
+		switch status {
+		case contracts.ResultStatusFailed, contracts.ResultStatusTimedOut:
+			event.ID, event.Type, event.Summary = SessionFailed, TypeError, "Session failed."
+		case contracts.ResultStatusCancelled:
+			event.ID, event.Type, event.Summary = SessionTerminated, TypeWarning, "Session terminated."
+		}
+		return event
+	}
+
+	event := Event{Fields: documentFields(docState, status)}
+	switch status {
+	case contracts.ResultStatusSuccess:
+		event.ID, event.Type, event.Summary = DocumentSucceeded, TypeInformation, "Document succeeded."
+	case contracts.ResultStatusSuccessAndReboot, contracts.ResultStatusPassedAndReboot:
+		event.ID, event.Type, event.Summary = DocumentRebootRequested, TypeInformation, "Document requested a reboot."
+	case contracts.ResultStatusTimedOut:
+		event.ID, event.Type, event.Summary = DocumentTimedOut, TypeError, "Document timed out."
+	case contracts.ResultStatusCancelled:
+		event.ID, event.Type, event.Summary = DocumentCancelled, TypeWarning, "Document cancelled."
+	default:
+		event.ID, event.Type, event.Summary = DocumentFailed, TypeError, fmt.Sprintf("Document finished with status %v.", status)
+	}
+	return event
+}
+
+// documentFields returns the fields of the document execution
+func documentFields(docState *contracts.DocumentState, status contracts.ResultStatus) []Field {
+	info := docState.DocumentInformation
+	return []Field{
+		{Name: "DocumentName", Value: info.DocumentName},
+		{Name: "DocumentVersion", Value: info.DocumentVersion},
+		{Name: "DocumentType", Value: string(docState.DocumentType)},
+		{Name: "CommandId", Value: info.CommandID},
+		{Name: "AssociationId", Value: info.AssociationID},
+		{Name: "MessageId", Value: info.MessageID},
+		{Name: "InstanceId", Value: info.InstanceID},
+		{Name: "Status", Value: string(status)},
+	}
+}
+
+// sessionFields returns the fields of the session
+func sessionFields(docState *contracts.DocumentState, status contracts.ResultStatus) []Field {
+	info := docState.DocumentInformation
+	return []Field{
+		{Name: "SessionId", Value: info.DocumentID},
+		{Name: "DocumentName", Value: info.DocumentName},
+		{Name: "ClientId", Value: info.ClientId},
+		{Name: "InstanceId", Value: info.InstanceID},
+		{Name: "Status", Value: string(status)},
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package executionevents
+
+import (
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/stretchr/testify/assert"
+)
+
+func newDocState(documentType contracts.DocumentType) *contracts.DocumentState {
+	return &contracts.DocumentState{
+		DocumentType: documentType,
+		DocumentInformation: contracts.DocumentInfo{
+			DocumentID:   "3c2ec6b5-1e0f-4d4c-8e8a-5d2b5c1f0a9e",
+			CommandID:    "3c2ec6b5-1e0f-4d4c-8e8a-5d2b5c1f0a9e",
+			MessageID:    "aws.ssm.3c2ec6b5-1e0f-4d4c-8e8a-5d2b5c1f0a9e.i-0123456789abcdef0",
+			InstanceID:   "i-0123456789abcdef0",
+			DocumentName: "AWS-RunPowerShellScript",
+		},
+	}
+}
+
+func TestDocumentEvents(t *testing.T) {
+	docState := newDocState(contracts.SendCommand)
+
+	event := startedEvent(docState)
+	assert.Equal(t, DocumentStarted, event.ID)
+	assert.Equal(t, TypeInformation, event.Type)
+	assert.Equal(t, "Document started.\r\n\r\n"+
+		"DocumentName: AWS-RunPowerShellScript\r\n"+
+		"DocumentType: SendCommand\r\n"+
+		"CommandId: 3c2ec6b5-1e0f-4d4c-8e8a-5d2b5c1f0a9e\r\n"+
+		"MessageId: aws.ssm.3c2ec6b5-1e0f-4d4c-8e8a-5d2b5c1f0a9e.i-0123456789abcdef0\r\n"+
+		"InstanceId: i-0123456789abcdef0", event.Message())
+
+	for status, expected := range map[contracts.ResultStatus]struct {
+		id        uint32
+		eventType EventType
+	}{
+		contracts.ResultStatusSuccess:          {DocumentSucceeded, TypeInformation},
+		contracts.ResultStatusSuccessAndReboot: {DocumentRebootRequested, TypeInformation},
+		contracts.ResultStatusFailed:           {DocumentFailed, TypeError},
+		contracts.ResultStatusTimedOut:         {DocumentTimedOut, TypeError},
+		contracts.ResultStatusCancelled:        {DocumentCancelled, TypeWarning},
+	} {
+		event = finishedEvent(docState, status)
+		assert.Equal(t, expected.id, event.ID, string(status))
+		assert.Equal(t, expected.eventType, event.Type, string(status))
+		assert.Contains(t, event.Message(), "Status: "+string(status))
+	}
+}
+
+func TestSessionEvents(t *testing.T) {
+	docState := newDocState(contracts.StartSession)
+	docState.DocumentInformation.DocumentName = "SSM-SessionManagerRunShell"
+	docState.DocumentInformation.ClientId = "e9f5a2d1-6c3b-4f2e-9a8d-7b1c0e4f5a6b"
+
+	event := startedEvent(docState)
+	assert.Equal(t, SessionOpened, event.ID)
+	assert.Equal(t, "Session opened.\r\n\r\n"+
+		"SessionId: 3c2ec6b5-1e0f-4d4c-8e8a-5d2b5c1f0a9e\r\n"+
+		"DocumentName: SSM-SessionManagerRunShell\r\n"+
+		"ClientId: e9f5a2d1-6c3b-4f2e-9a8d-7b1c0e4f5a6b\r\n"+
+		"InstanceId: i-0123456789abcdef0", event.Message())
+
+	assert.Equal(t, SessionClosed, finishedEvent(docState, contracts.ResultStatusSuccess).ID)
+	assert.Equal(t, SessionFailed, finishedEvent(docState, contracts.ResultStatusFailed).ID)
+	assert.Equal(t, SessionTerminated, finishedEvent(docState, contracts.ResultStatusCancelled).ID)
+}
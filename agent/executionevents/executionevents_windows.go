@@ -0,0 +1,98 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// +build windows
+
+package executionevents
+
+import (
+	"sync"
+
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"golang.org/x/sys/windows/registry"
+	"golang.org/x/sys/windows/svc/eventlog"
+)
+
+const (
+	// eventLogKey is the registry key of the Windows event logs
+	eventLogKey = `SYSTEM\CurrentControlSet\Services\EventLog`
+	// messageFile is the message file of the source, which formats the events with their message whatever their id
+	// between 1 and 1000
+	messageFile = `%SystemRoot%\System32\EventCreate.exe`
+)
+
+var (
+	lock     sync.Mutex
+	eventLog *eventlog.Log
+)
+
+// write writes the event to the Windows event log if WindowsEventLogEnabled is set, the log and the source are
+// created by the first event
+func write(context context.T, event Event) {
+	if !context.AppConfig().Agent.WindowsEventLogEnabled {
+		return
+	}
+	log := context.Log()
+
+	lock.Lock()
+	defer lock.Unlock()
+	if eventLog == nil {
+		if err := installSource(); err != nil {
+			log.Warnf("Failed to create the %v event log source, %v", Source, err)
+			return
+		}
+		var err error
+		if eventLog, err = eventlog.Open(Source); err != nil {
+			log.Warnf("Failed to open the %v event log, %v", LogName, err)
+			return
+		}
+	}
+
+	var err error
+	switch event.Type {
+	case TypeError:
+		err = eventLog.Error(event.ID, event.Message())
+	case TypeWarning:
+		err = eventLog.Warning(event.ID, event.Message())
+	default:
+		err = eventLog.Info(event.ID, event.Message())
+	}
+	if err != nil {
+		log.Warnf("Failed to write event %v to the %v event log, %v", event.ID, LogName, err)
+	}
+}
+
+// installSource creates the event log and its source unless they exist
+func installSource() error {
+	logKey, _, err := registry.CreateKey(registry.LOCAL_MACHINE, eventLogKey+`\`+LogName, registry.CREATE_SUB_KEY)
+	if err != nil {
+		return err
+	}
+	defer logKey.Close()
+
+	sourceKey, alreadyExist, err := registry.CreateKey(logKey, Source, registry.SET_VALUE)
+	if err != nil {
+		return err
+	}
+	defer sourceKey.Close()
+	if alreadyExist {
+		return nil
+	}
+	if err = sourceKey.SetExpandStringValue("EventMessageFile", messageFile); err != nil {
+		return err
+	}
+	if err = sourceKey.SetDWordValue("CustomSource", 1); err != nil {
+		return err
+	}
+	return sourceKey.SetDWordValue("TypesSupported", eventlog.Error|eventlog.Warning|eventlog.Info)
+}
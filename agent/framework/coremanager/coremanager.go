@@ -25,6 +25,8 @@ import (
 	"github.com/aws/amazon-ssm-agent/agent/contracts"
 	"github.com/aws/amazon-ssm-agent/agent/fileutil"
 	"github.com/aws/amazon-ssm-agent/agent/framework/coremodules"
+	"github.com/aws/amazon-ssm-agent/agent/framework/docmanager"
+	"github.com/aws/amazon-ssm-agent/agent/framework/processor"
 	"github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/plugin"
 	"github.com/aws/amazon-ssm-agent/agent/framework/runpluginutil"
 	logger "github.com/aws/amazon-ssm-agent/agent/log"
@@ -50,6 +52,7 @@ type CoreManager struct {
 	coreModules         coremodules.ModuleRegistry
 	cloudwatchPublisher *cloudwatchlogspublisher.CloudWatchPublisher
 	rebooter            rebooter.IRebootType
+	stopRetentionSweep  func()
 }
 
 // NewCoreManager creates a new core module manager.
@@ -96,6 +99,10 @@ func NewCoreManager(context context.T, mr coremodules.ModuleRegistry, cwp *cloud
 		return
 	}
 
+	// documents which requested a reboot resume from the plugin following the reboot, the processors pick them up
+	// from the in-progress documents and send their final reply once they complete
+	processor.ResumeAfterReboot(rebooter.ResumeAfterReboot(log, instanceId))
+
 	// Initialize the client diagnostics
 	cwp.Init(log)
 	context = context.With("[instanceID=" + instanceId + "]")
@@ -226,15 +233,47 @@ func initializeBookkeepingLocations(log logger.T, instanceID string) bool {
 // Start executes the registered core modules while watching for reboot request
 func (c *CoreManager) Start() {
 	go c.watchForReboot()
+	c.startRetentionSweeper()
 	c.executeCoreModules()
 }
 
 // Stop requests the core modules to stop executing
 // Stop would be called by the agent and should be treated as hard stop
 func (c *CoreManager) Stop() {
+	if c.stopRetentionSweep != nil {
+		c.stopRetentionSweep()
+	}
 	c.stopCoreModules(contracts.StopTypeHardStop)
 }
 
+// startRetentionSweeper starts sweeping the orchestration directories of completed documents when a count or size limit is configured,
+// the age limit alone is already enforced by the core modules after every document
+func (c *CoreManager) startRetentionSweeper() {
+	log := c.context.Log()
+	config := c.context.AppConfig()
+	if config.Ssm.OrchestrationRetentionMaxCount == 0 && config.Ssm.OrchestrationRetentionMaxSizeMB == 0 {
+		return
+	}
+
+	instanceID, err := platform.InstanceID()
+	if err != nil {
+		log.Errorf("error fetching the instanceID, orchestration retention sweeper not started, %v", err)
+		return
+	}
+
+	c.stopRetentionSweep = docmanager.StartRetentionSweeper(
+		log,
+		instanceID,
+		config.Agent.OrchestrationRootDir,
+		docmanager.RetentionPolicy{
+			MaxAgeHours:            config.Ssm.RunCommandLogsRetentionDurationHours,
+			AssociationMaxAgeHours: config.Ssm.AssociationLogsRetentionDurationHours,
+			MaxCount:               config.Ssm.OrchestrationRetentionMaxCount,
+			MaxTotalSizeMB:         config.Ssm.OrchestrationRetentionMaxSizeMB,
+		},
+		time.Duration(config.Ssm.OrchestrationRetentionSweepMinutes)*time.Minute)
+}
+
 // executeCoreModules launches all the core modules
 func (c *CoreManager) executeCoreModules() {
 	l := len(c.coreModules)
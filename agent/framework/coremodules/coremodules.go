@@ -15,14 +15,21 @@
 package coremodules
 
 import (
+	"github.com/aws/amazon-ssm-agent/agent/agentlogstocloudwatch/logshipper"
 	"github.com/aws/amazon-ssm-agent/agent/context"
 	"github.com/aws/amazon-ssm-agent/agent/contracts"
 	"github.com/aws/amazon-ssm-agent/agent/health"
+	"github.com/aws/amazon-ssm-agent/agent/health/status"
+	"github.com/aws/amazon-ssm-agent/agent/localipc"
 	"github.com/aws/amazon-ssm-agent/agent/longrunning/manager"
+	"github.com/aws/amazon-ssm-agent/agent/metrics/endpoint"
+	"github.com/aws/amazon-ssm-agent/agent/metrics/statsd"
 	"github.com/aws/amazon-ssm-agent/agent/runcommand"
 	"github.com/aws/amazon-ssm-agent/agent/session"
 	"github.com/aws/amazon-ssm-agent/agent/ssm"
 	"github.com/aws/amazon-ssm-agent/agent/startup"
+	"github.com/aws/amazon-ssm-agent/agent/tracing/otlp"
+	"github.com/aws/amazon-ssm-agent/agent/watchdog"
 )
 
 // ModuleRegistry stores a set of core modules.
@@ -50,11 +57,19 @@ func loadCoreModules(context context.T) {
 
 	if offlineProcessor, err := runcommand.NewOfflineService(context); err == nil {
 		registeredCoreModules = append(registeredCoreModules, offlineProcessor)
+		// local tooling submits documents to the offline processor
+		registeredCoreModules = append(registeredCoreModules, localipc.NewServer(context, offlineProcessor))
 	} else {
 		context.Log().Errorf("Failed to start offline command document processor")
 	}
 
 	registeredCoreModules = append(registeredCoreModules, startup.NewProcessor(context))
+	registeredCoreModules = append(registeredCoreModules, endpoint.NewEndpoint(context))
+	registeredCoreModules = append(registeredCoreModules, statsd.NewEmitter(context))
+	registeredCoreModules = append(registeredCoreModules, otlp.NewExporter(context))
+	registeredCoreModules = append(registeredCoreModules, logshipper.NewShipper(context))
+	registeredCoreModules = append(registeredCoreModules, status.NewServer(context))
+	registeredCoreModules = append(registeredCoreModules, watchdog.NewWatchdog(context))
 
 	// registering the long running plugin manager as a core module
 	manager.EnsureInitialization(context)
@@ -0,0 +1,187 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package docmanager helps persist documents state to disk
+package docmanager
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/fileutil"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+)
+
+const bytesPerMB = 1024 * 1024
+
+// RetentionPolicy limits the orchestration directories kept for completed documents, a zero value disables the limit.
+// MaxAgeHours applies to the directories of the commands, AssociationMaxAgeHours to the directories of the associations.
+type RetentionPolicy struct {
+	MaxAgeHours            int
+	AssociationMaxAgeHours int
+	MaxCount               int
+	MaxTotalSizeMB         int
+}
+
+// RetentionStats summarizes a sweep of the orchestration directories
+type RetentionStats struct {
+	Directories    int
+	Deleted        int
+	ReclaimedBytes int64
+	RemainingBytes int64
+}
+
+type orchestrationDirectory struct {
+	name        string
+	modTime     time.Time
+	size        int64
+	association bool
+}
+
+// StartRetentionSweeper sweeps the orchestration directories at the given interval, until the returned function is called
+func StartRetentionSweeper(log log.T, instanceID, orchestrationRootDirName string, policy RetentionPolicy, interval time.Duration) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				SweepOrchestrationDirectories(log, instanceID, orchestrationRootDirName, policy)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		ticker.Stop()
+		close(done)
+	}
+}
+
+// SweepOrchestrationDirectories deletes the oldest orchestration directories of completed documents
+// until the retention policy is satisfied, directories of pending and running documents are kept.
+func SweepOrchestrationDirectories(log log.T, instanceID, orchestrationRootDirName string, policy RetentionPolicy) (stats RetentionStats) {
+	orchestrationRootDir, dirNames, err := getOrchestrationDirectoryNames(log, instanceID, orchestrationRootDirName, appconfig.DefaultDocumentRootDirName)
+	if err != nil {
+		log.Debugf("Failed to get orchestration directories under %v", err)
+		return
+	}
+
+	activeDocuments := activeDocumentIDs(instanceID)
+	directories := []orchestrationDirectory{}
+	for _, dirName := range dirNames {
+		if isActiveDirectory(dirName, activeDocuments) {
+			continue
+		}
+		dirPath := filepath.Join(orchestrationRootDir, dirName)
+		modTime, err := fileutil.GetFileModificationTime(dirPath)
+		if err != nil {
+			log.Debugf("Failed to get modification time %v", err)
+			continue
+		}
+		isAssoc, _ := isLegacyAssociationDirectory(log, dirPath)
+		directories = append(directories, orchestrationDirectory{name: dirName, modTime: modTime, size: directorySize(dirPath), association: isAssoc})
+	}
+
+	stats.Directories = len(directories)
+	for _, dir := range directories {
+		stats.RemainingBytes += dir.size
+	}
+
+	for _, dir := range expiredDirectories(directories, policy, time.Now()) {
+		dirPath := filepath.Join(orchestrationRootDir, dir.name)
+		if err := fileutil.DeleteDirectory(dirPath); err != nil {
+			log.Debugf("Error deleting directory %v: %v", dirPath, err)
+			continue
+		}
+		stats.Deleted++
+		stats.ReclaimedBytes += dir.size
+		stats.RemainingBytes -= dir.size
+	}
+
+	log.Infof("Orchestration retention sweep deleted %v of %v directories, reclaimed %v bytes, %v bytes remaining",
+		stats.Deleted, stats.Directories, stats.ReclaimedBytes, stats.RemainingBytes)
+	return
+}
+
+// expiredDirectories returns the directories to delete, oldest first, so the remaining ones satisfy the policy
+func expiredDirectories(directories []orchestrationDirectory, policy RetentionPolicy, now time.Time) []orchestrationDirectory {
+	sorted := make([]orchestrationDirectory, len(directories))
+	copy(sorted, directories)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].modTime.Before(sorted[j].modTime)
+	})
+
+	var totalSize int64
+	for _, dir := range sorted {
+		totalSize += dir.size
+	}
+
+	expired := []orchestrationDirectory{}
+	remaining := len(sorted)
+	for _, dir := range sorted {
+		maxAgeHours := policy.MaxAgeHours
+		if dir.association {
+			maxAgeHours = policy.AssociationMaxAgeHours
+		}
+		// the commands and the associations have their own max age, so a directory kept by age doesn't stop the sweep
+		tooOld := maxAgeHours > 0 && dir.modTime.Add(time.Duration(maxAgeHours)*time.Hour).Before(now)
+		tooMany := policy.MaxCount > 0 && remaining > policy.MaxCount
+		tooLarge := policy.MaxTotalSizeMB > 0 && totalSize > int64(policy.MaxTotalSizeMB)*bytesPerMB
+		if !tooOld && !tooMany && !tooLarge {
+			continue
+		}
+		expired = append(expired, dir)
+		remaining--
+		totalSize -= dir.size
+	}
+	return expired
+}
+
+// activeDocumentIDs returns the ids of the pending and running documents
+func activeDocumentIDs(instanceID string) []string {
+	ids := []string{}
+	for _, location := range []string{appconfig.DefaultLocationOfPending, appconfig.DefaultLocationOfCurrent} {
+		if fileNames, err := fileutil.GetFileNames(DocumentStateDir(instanceID, location)); err == nil {
+			ids = append(ids, fileNames...)
+		}
+	}
+	return ids
+}
+
+// isActiveDirectory returns true if the orchestration directory belongs to a pending or running document,
+// document ids start with the command id or association id naming the orchestration directory
+func isActiveDirectory(dirName string, activeDocuments []string) bool {
+	for _, documentID := range activeDocuments {
+		if strings.HasPrefix(documentID, dirName) {
+			return true
+		}
+	}
+	return false
+}
+
+// directorySize returns the total size of the files under the directory
+func directorySize(dirPath string) (size int64) {
+	filepath.Walk(dirPath, func(_ string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return
+}
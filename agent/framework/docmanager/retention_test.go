@@ -0,0 +1,119 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package docmanager helps persist documents state to disk
+package docmanager
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func newOrchestrationDirectories(now time.Time) []orchestrationDirectory {
+	return []orchestrationDirectory{
+		{name: "new", modTime: now.Add(-time.Hour), size: bytesPerMB},
+		{name: "old", modTime: now.Add(-72 * time.Hour), size: bytesPerMB},
+		{name: "middle", modTime: now.Add(-24 * time.Hour), size: 2 * bytesPerMB},
+	}
+}
+
+func expiredNames(directories []orchestrationDirectory) []string {
+	names := []string{}
+	for _, dir := range directories {
+		names = append(names, dir.name)
+	}
+	return names
+}
+
+func TestExpiredDirectoriesByAge(t *testing.T) {
+	now := time.Now()
+
+	expired := expiredDirectories(newOrchestrationDirectories(now), RetentionPolicy{MaxAgeHours: 48}, now)
+
+	assert.Equal(t, []string{"old"}, expiredNames(expired))
+}
+
+func TestExpiredDirectoriesByAssociationAge(t *testing.T) {
+	now := time.Now()
+	directories := []orchestrationDirectory{
+		{name: "old-association", modTime: now.Add(-96 * time.Hour), association: true},
+		{name: "old-command", modTime: now.Add(-72 * time.Hour)},
+		{name: "association", modTime: now.Add(-48 * time.Hour), association: true},
+		{name: "command", modTime: now.Add(-36 * time.Hour)},
+		{name: "new-command", modTime: now.Add(-time.Hour)},
+	}
+
+	// the association kept by age doesn't keep the older commands
+	expired := expiredDirectories(directories, RetentionPolicy{MaxAgeHours: 24, AssociationMaxAgeHours: 72}, now)
+	assert.Equal(t, []string{"old-association", "old-command", "command"}, expiredNames(expired))
+
+	// the command age doesn't apply to the associations
+	expired = expiredDirectories(directories, RetentionPolicy{MaxAgeHours: 24}, now)
+	assert.Equal(t, []string{"old-command", "command"}, expiredNames(expired))
+
+	expired = expiredDirectories(directories, RetentionPolicy{AssociationMaxAgeHours: 24}, now)
+	assert.Equal(t, []string{"old-association", "association"}, expiredNames(expired))
+}
+
+func TestExpiredDirectoriesByCount(t *testing.T) {
+	now := time.Now()
+
+	expired := expiredDirectories(newOrchestrationDirectories(now), RetentionPolicy{MaxCount: 1}, now)
+
+	assert.Equal(t, []string{"old", "middle"}, expiredNames(expired))
+}
+
+func TestExpiredDirectoriesBySize(t *testing.T) {
+	now := time.Now()
+
+	expired := expiredDirectories(newOrchestrationDirectories(now), RetentionPolicy{MaxTotalSizeMB: 2}, now)
+
+	assert.Equal(t, []string{"old", "middle"}, expiredNames(expired))
+}
+
+func TestExpiredDirectoriesWithoutPolicy(t *testing.T) {
+	now := time.Now()
+
+	assert.Empty(t, expiredDirectories(newOrchestrationDirectories(now), RetentionPolicy{}, now))
+}
+
+func TestIsActiveDirectory(t *testing.T) {
+	activeDocuments := []string{"b2f71a28-cbe1-4429-b848-26c7e1f5ad0d.2017-06-01T10-00-00.000Z"}
+
+	assert.True(t, isActiveDirectory("b2f71a28-cbe1-4429-b848-26c7e1f5ad0d", activeDocuments))
+	assert.False(t, isActiveDirectory("c2f71a28-cbe1-4429-b848-26c7e1f5ad0d", activeDocuments))
+}
+
+func TestIsLegacyAssociationDirectory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "orchestration")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	association := filepath.Join(dir, "association")
+	command := filepath.Join(dir, "command")
+	assert.NoError(t, os.MkdirAll(filepath.Join(association, "2017-06-01T10-00-00.000Z", "awsrunShellScript"), 0755))
+	assert.NoError(t, os.MkdirAll(filepath.Join(command, "awsrunShellScript"), 0755))
+
+	isAssoc, err := isLegacyAssociationDirectory(log.NewMockLog(), association)
+	assert.NoError(t, err)
+	assert.True(t, isAssoc)
+	isAssoc, err = isLegacyAssociationDirectory(log.NewMockLog(), command)
+	assert.NoError(t, err)
+	assert.False(t, isAssoc)
+}
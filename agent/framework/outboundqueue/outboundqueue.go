@@ -0,0 +1,305 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package outboundqueue implements a persistent queue of the requests that failed to reach the service,
+// the requests are sent again with an exponential backoff once the service is reachable.
+package outboundqueue
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/fileutil"
+	"github.com/aws/amazon-ssm-agent/agent/jsonutil"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/sdkutil"
+)
+
+const (
+	// DefaultMinBackoff is the delay before the first retry of a failed flush
+	DefaultMinBackoff = 30 * time.Second
+
+	// DefaultMaxBackoff is the maximum delay between the retries of a failed flush
+	DefaultMaxBackoff = 15 * time.Minute
+
+	// timestampFormat is the format of the enqueue time in the entry file names, it sorts in chronological order
+	timestampFormat = "2006-01-02T15-04-05.000000000"
+)
+
+// Entry is a request waiting in the queue
+type Entry struct {
+	Key     string
+	Content string
+	Date    time.Time
+	file    string
+}
+
+// Queue is a directory of requests waiting to be sent to the service, one file per request.
+// A request replaces the queued request with the same key, so that only the latest request of each key is sent.
+type Queue struct {
+	dir      string
+	maxAge   time.Duration
+	maxItems int
+	backoff  *Backoff
+
+	// lock guards the files of the queue, flushLock makes sure a single flush sends the requests
+	lock      sync.Mutex
+	flushLock sync.Mutex
+}
+
+// Backoff spaces out the attempts to reach the service, the delay doubles after each failure up to the maximum
+type Backoff struct {
+	min   time.Duration
+	max   time.Duration
+	delay time.Duration
+	next  time.Time
+	lock  sync.Mutex
+}
+
+// New returns a queue persisted in dir, requests older than maxAge are dropped and
+// the oldest requests are dropped once the queue holds maxItems, zero values disable the limits
+func New(dir string, maxAge time.Duration, maxItems int) *Queue {
+	return &Queue{
+		dir:      dir,
+		maxAge:   maxAge,
+		maxItems: maxItems,
+		backoff:  NewBackoff(DefaultMinBackoff, DefaultMaxBackoff),
+	}
+}
+
+// Enqueue persists the request under the given key, replacing the request already queued for the key
+func (q *Queue) Enqueue(log log.T, key string, request interface{}) (err error) {
+	if strings.ContainsAny(key, `_/\`) {
+		return fmt.Errorf("invalid queue key %v", key)
+	}
+
+	var content string
+	if content, err = jsonutil.Marshal(request); err != nil {
+		return fmt.Errorf("failed to marshal request %v, %v", key, err)
+	}
+
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if err = fileutil.MakeDirs(q.dir); err != nil {
+		return fmt.Errorf("failed to create queue directory %v, %v", q.dir, err)
+	}
+	q.removeLocked(log, key)
+
+	fileName := filepath.Join(q.dir, fmt.Sprintf("%v_%v", key, time.Now().UTC().Format(timestampFormat)))
+	if _, err = fileutil.WriteIntoFileWithPermissions(fileName, content, os.FileMode(int(appconfig.ReadWriteAccess))); err != nil {
+		return fmt.Errorf("failed to persist request %v in %v, %v", key, fileName, err)
+	}
+	log.Debugf("Queued request %v in %v", key, fileName)
+
+	if entries := q.entriesLocked(log); q.maxItems > 0 && len(entries) > q.maxItems {
+		for _, entry := range entries[:len(entries)-q.maxItems] {
+			log.Warnf("Outbound queue %v is full, dropping request %v queued at %v", q.dir, entry.Key, entry.Date)
+			q.deleteLocked(log, entry.file)
+		}
+	}
+	return nil
+}
+
+// Remove drops the request queued under the given key, used when a newer request reached the service
+func (q *Queue) Remove(log log.T, key string) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	q.removeLocked(log, key)
+}
+
+// Len returns the number of queued requests
+func (q *Queue) Len(log log.T) int {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	return len(q.entriesLocked(log))
+}
+
+// Flush sends the queued requests oldest first and drops each request the service accepted.
+// A request the service rejected for good, like a validation error, is dropped as well, while a request that failed
+// with a transient error, like throttling, a server error or a network error, stays queued for the next flush.
+// A failing request doesn't hold back the requests of the other keys, but after any transient failure
+// the following flushes are skipped until the backoff delay elapsed.
+func (q *Queue) Flush(log log.T, send func(entry Entry) error) (err error) {
+	q.flushLock.Lock()
+	defer q.flushLock.Unlock()
+
+	if !q.backoff.Ready() {
+		log.Debugf("Skipping flush of outbound queue %v until %v", q.dir, q.backoff.Next())
+		return nil
+	}
+
+	q.lock.Lock()
+	entries := q.entriesLocked(log)
+	q.lock.Unlock()
+
+	var failed []string
+	for _, entry := range entries {
+		if q.maxAge > 0 && time.Since(entry.Date) > q.maxAge {
+			log.Infof("Dropping request %v queued at %v, it is older than %v", entry.Key, entry.Date, q.maxAge)
+			q.delete(log, entry.file)
+			continue
+		}
+
+		if sendErr := send(entry); sendErr != nil {
+			if !sdkutil.IsRetryableError(sendErr) {
+				log.Errorf("Dropping queued request %v, the service rejected it, %v", entry.Key, sendErr)
+				q.delete(log, entry.file)
+				continue
+			}
+			if err == nil {
+				err = sendErr
+			}
+			failed = append(failed, entry.Key)
+			continue
+		}
+		log.Debugf("Sent queued request %v", entry.Key)
+		q.delete(log, entry.file)
+	}
+
+	if err != nil {
+		delay := q.backoff.Failed()
+		log.Infof("Failed to send queued requests %v, retrying in %v, %v", failed, delay, err)
+		return err
+	}
+	q.backoff.Succeeded()
+	return nil
+}
+
+// Reset clears the backoff so that the next flush sends the requests right away, used when the service is reachable again
+func (q *Queue) Reset() {
+	q.backoff.Succeeded()
+}
+
+// entriesLocked returns the queued requests oldest first. Caller must hold lock.
+func (q *Queue) entriesLocked(log log.T) (entries []Entry) {
+	files, err := ioutil.ReadDir(q.dir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Errorf("encountered error %v while listing outbound queue %v", err, q.dir)
+		}
+		return
+	}
+
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+		separator := strings.LastIndex(file.Name(), "_")
+		if separator < 0 {
+			continue
+		}
+		date, err := time.Parse(timestampFormat, file.Name()[separator+1:])
+		if err != nil {
+			log.Debugf("Ignoring file %v in outbound queue %v", file.Name(), q.dir)
+			continue
+		}
+		content, err := fileutil.ReadAllText(filepath.Join(q.dir, file.Name()))
+		if err != nil {
+			log.Errorf("encountered error %v while reading queued request %v", err, file.Name())
+			continue
+		}
+		entries = append(entries, Entry{
+			Key:     file.Name()[:separator],
+			Content: content,
+			Date:    date,
+			file:    file.Name(),
+		})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Date.Before(entries[j].Date)
+	})
+	return
+}
+
+// removeLocked deletes the files queued under the given key. Caller must hold lock.
+func (q *Queue) removeLocked(log log.T, key string) {
+	files, _ := fileutil.GetFileNames(q.dir)
+	for _, file := range files {
+		if strings.HasPrefix(file, key+"_") {
+			q.deleteLocked(log, file)
+		}
+	}
+}
+
+func (q *Queue) delete(log log.T, file string) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	q.deleteLocked(log, file)
+}
+
+// deleteLocked deletes the file of a queued request. Caller must hold lock.
+func (q *Queue) deleteLocked(log log.T, file string) {
+	absoluteFileName := filepath.Join(q.dir, file)
+	if !fileutil.Exists(absoluteFileName) {
+		return
+	}
+	if err := fileutil.DeleteFile(absoluteFileName); err != nil {
+		log.Errorf("encountered error %v while deleting file %v", err, absoluteFileName)
+	}
+}
+
+// NewBackoff returns a backoff starting at min and doubling up to max
+func NewBackoff(min time.Duration, max time.Duration) *Backoff {
+	return &Backoff{min: min, max: max}
+}
+
+// Ready returns true if the backoff delay since the last failure elapsed
+func (b *Backoff) Ready() bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return !time.Now().Before(b.next)
+}
+
+// Next returns the time of the next attempt, the zero time if there is no failure
+func (b *Backoff) Next() time.Time {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.next
+}
+
+// Failed records a failed attempt and returns the delay before the next attempt
+func (b *Backoff) Failed() time.Duration {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.delay == 0 {
+		b.delay = b.min
+	} else if b.delay *= 2; b.delay > b.max {
+		b.delay = b.max
+	}
+	b.next = time.Now().Add(b.delay)
+	return b.delay
+}
+
+// Succeeded records a successful attempt, the next attempt doesn't wait
+func (b *Backoff) Succeeded() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.delay = 0
+	b.next = time.Time{}
+}
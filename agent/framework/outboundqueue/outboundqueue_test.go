@@ -0,0 +1,187 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package outboundqueue implements a persistent queue of the requests that failed to reach the service,
+// the requests are sent again with an exponential backoff once the service is reachable.
+package outboundqueue
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/stretchr/testify/assert"
+)
+
+var logMock = log.NewMockLog()
+
+type request struct {
+	Status string
+}
+
+func newTestQueue(t *testing.T, maxAge time.Duration, maxItems int) (*Queue, func()) {
+	dir, err := ioutil.TempDir("", "outboundqueue")
+	assert.NoError(t, err)
+	return New(filepath.Join(dir, "queue"), maxAge, maxItems), func() { os.RemoveAll(dir) }
+}
+
+func TestFlushSendsOldestFirst(t *testing.T) {
+	queue, cleanup := newTestQueue(t, 0, 0)
+	defer cleanup()
+
+	assert.NoError(t, queue.Enqueue(logMock, "first", request{Status: "Failed"}))
+	assert.NoError(t, queue.Enqueue(logMock, "second", request{Status: "Success"}))
+	// the latest request of a key replaces the queued one
+	assert.NoError(t, queue.Enqueue(logMock, "first", request{Status: "Success"}))
+	assert.Equal(t, 2, queue.Len(logMock))
+
+	sent := []string{}
+	err := queue.Flush(logMock, func(entry Entry) error {
+		sent = append(sent, entry.Key+" "+entry.Content)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{`second {"Status":"Success"}`, `first {"Status":"Success"}`}, sent)
+	assert.Equal(t, 0, queue.Len(logMock))
+}
+
+func TestFlushBacksOffAfterFailure(t *testing.T) {
+	queue, cleanup := newTestQueue(t, 0, 0)
+	defer cleanup()
+
+	assert.NoError(t, queue.Enqueue(logMock, "first", request{}))
+	assert.NoError(t, queue.Enqueue(logMock, "second", request{}))
+
+	calls := 0
+	send := func(entry Entry) error {
+		calls++
+		return fmt.Errorf("service unreachable")
+	}
+	assert.Error(t, queue.Flush(logMock, send))
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, 2, queue.Len(logMock))
+
+	// the following flush waits for the backoff delay
+	assert.NoError(t, queue.Flush(logMock, send))
+	assert.Equal(t, 2, calls)
+
+	queue.Reset()
+	assert.NoError(t, queue.Flush(logMock, func(entry Entry) error { return nil }))
+	assert.Equal(t, 0, queue.Len(logMock))
+}
+
+func TestFlushContinuesAfterFailure(t *testing.T) {
+	queue, cleanup := newTestQueue(t, 0, 0)
+	defer cleanup()
+
+	for _, key := range []string{"throttled", "rejected", "sent"} {
+		assert.NoError(t, queue.Enqueue(logMock, key, request{}))
+	}
+
+	sent := []string{}
+	err := queue.Flush(logMock, func(entry Entry) error {
+		switch entry.Key {
+		case "throttled":
+			return awserr.NewRequestFailure(awserr.New("ThrottlingException", "Rate exceeded", nil), 400, "requestID")
+		case "rejected":
+			return awserr.NewRequestFailure(awserr.New("InvalidInstanceId", "", nil), 400, "requestID")
+		}
+		sent = append(sent, entry.Key)
+		return nil
+	})
+
+	assert.Error(t, err)
+	assert.Equal(t, []string{"sent"}, sent)
+	// the rejected request is dropped, the throttled one is retried after the backoff delay
+	assert.Equal(t, 1, queue.Len(logMock))
+	assert.False(t, queue.backoff.Ready())
+
+	queue.Reset()
+	assert.NoError(t, queue.Flush(logMock, func(entry Entry) error {
+		sent = append(sent, entry.Key)
+		return nil
+	}))
+	assert.Equal(t, []string{"sent", "throttled"}, sent)
+	assert.Equal(t, 0, queue.Len(logMock))
+}
+
+func TestFlushDropsExpiredRequests(t *testing.T) {
+	queue, cleanup := newTestQueue(t, time.Millisecond, 0)
+	defer cleanup()
+
+	assert.NoError(t, queue.Enqueue(logMock, "expired", request{}))
+	time.Sleep(10 * time.Millisecond)
+
+	calls := 0
+	assert.NoError(t, queue.Flush(logMock, func(entry Entry) error {
+		calls++
+		return nil
+	}))
+	assert.Equal(t, 0, calls)
+	assert.Equal(t, 0, queue.Len(logMock))
+}
+
+func TestEnqueueDropsOldestWhenFull(t *testing.T) {
+	queue, cleanup := newTestQueue(t, 0, 2)
+	defer cleanup()
+
+	for _, key := range []string{"first", "second", "third"} {
+		assert.NoError(t, queue.Enqueue(logMock, key, request{}))
+	}
+
+	keys := []string{}
+	queue.Flush(logMock, func(entry Entry) error {
+		keys = append(keys, entry.Key)
+		return nil
+	})
+	assert.Equal(t, []string{"second", "third"}, keys)
+}
+
+func TestEnqueueInvalidKey(t *testing.T) {
+	queue, cleanup := newTestQueue(t, 0, 0)
+	defer cleanup()
+
+	assert.Error(t, queue.Enqueue(logMock, "invalid_key", request{}))
+	assert.Error(t, queue.Enqueue(logMock, "../key", request{}))
+}
+
+func TestRemove(t *testing.T) {
+	queue, cleanup := newTestQueue(t, 0, 0)
+	defer cleanup()
+
+	assert.NoError(t, queue.Enqueue(logMock, "assoc", request{}))
+	assert.NoError(t, queue.Enqueue(logMock, "assoc-2", request{}))
+	queue.Remove(logMock, "assoc")
+	assert.Equal(t, 1, queue.Len(logMock))
+}
+
+func TestBackoff(t *testing.T) {
+	backoff := NewBackoff(time.Second, 5*time.Second)
+	assert.True(t, backoff.Ready())
+
+	assert.Equal(t, time.Second, backoff.Failed())
+	assert.False(t, backoff.Ready())
+	assert.Equal(t, 2*time.Second, backoff.Failed())
+	assert.Equal(t, 4*time.Second, backoff.Failed())
+	assert.Equal(t, 5*time.Second, backoff.Failed())
+
+	backoff.Succeeded()
+	assert.True(t, backoff.Ready())
+	assert.Equal(t, time.Second, backoff.Failed())
+}
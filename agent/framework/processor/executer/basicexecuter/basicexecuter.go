@@ -51,6 +51,8 @@ func run(context context.T,
 		}
 	}()
 	docState := docStore.Load()
+	executer.RecoverInterruptedPlugins(context.Log(), &docState, context.AppConfig().Agent.FailInterruptedPlugins)
+	executer.Checkpoint(docStore, &docState)
 	//document information summary
 	messageID := docState.DocumentInformation.MessageID
 	associationID := docState.DocumentInformation.AssociationID
@@ -86,6 +88,7 @@ func run(context context.T,
 			}
 			resChan <- docResult
 			contracts.UpdateDocState(&docResult, state)
+			executer.Checkpoint(docStore, state)
 		}
 	}(&docState)
 
@@ -23,6 +23,7 @@ import (
 	"github.com/aws/amazon-ssm-agent/agent/log"
 	"github.com/aws/amazon-ssm-agent/agent/task"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 var logger = log.NewMockLog()
@@ -100,6 +101,8 @@ func testBasicExecuter(t *testing.T, testCase TestCase) {
 	resultState.InstancePluginsInformation[0].Result = *testCase.PluginResults["plugin1"]
 	dataStoreMock.On("Load").Return(state)
 	dataStoreMock.On("Save", resultState).Return()
+	// plugin progress checkpoints
+	dataStoreMock.On("Save", mock.Anything).Return()
 	pluginRunner = func(context context.T,
 		docState contracts.DocumentState,
 		resChan chan contracts.PluginResult,
@@ -0,0 +1,76 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package executer provides interfaces as document execution logic
+package executer
+
+import (
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+)
+
+// InterruptedPluginError is the error of a plugin failed because an agent restart interrupted it
+const InterruptedPluginError = "Plugin execution was interrupted by an agent restart"
+
+//Checkpoint persists the plugin progress of the document, the plugin about to be executed is saved as InProgress
+//such that a plugin found InProgress when the document is resumed is known to have been interrupted
+func Checkpoint(docStore DocumentStore, docState *contracts.DocumentState) {
+	//copy the plugin states, the in-memory states are shared with the plugin runner
+	checkpoint := *docState
+	checkpoint.InstancePluginsInformation = make([]contracts.PluginState, len(docState.InstancePluginsInformation))
+	copy(checkpoint.InstancePluginsInformation, docState.InstancePluginsInformation)
+
+	if next := nextPlugin(checkpoint.InstancePluginsInformation); next >= 0 {
+		checkpoint.InstancePluginsInformation[next].Result.Status = contracts.ResultStatusInProgress
+	}
+	docStore.Save(checkpoint)
+}
+
+//RecoverInterruptedPlugins handles the plugins that were executing when the agent stopped, they are failed
+//if failInterrupted is set, otherwise they are executed again when the document resumes
+func RecoverInterruptedPlugins(log log.T, docState *contracts.DocumentState, failInterrupted bool) {
+	for i := range docState.InstancePluginsInformation {
+		plugin := &docState.InstancePluginsInformation[i]
+		if plugin.Result.Status != contracts.ResultStatusInProgress {
+			continue
+		}
+		if !failInterrupted {
+			log.Infof("plugin %v was interrupted by an agent restart, resuming it", plugin.Id)
+			continue
+		}
+		log.Infof("plugin %v was interrupted by an agent restart, marking it failed", plugin.Id)
+		plugin.Result.PluginID = plugin.Id
+		plugin.Result.PluginName = plugin.Name
+		plugin.Result.Status = contracts.ResultStatusFailed
+		plugin.Result.Code = 1
+		plugin.Result.Error = InterruptedPluginError
+		plugin.Result.Output = InterruptedPluginError
+		plugin.Result.EndDateTime = time.Now()
+	}
+}
+
+//nextPlugin returns the index of the plugin that runs next, plugins run in order and the document
+//stops after a plugin requests a reboot, -1 is returned if no plugin is left to run
+func nextPlugin(plugins []contracts.PluginState) int {
+	for i, plugin := range plugins {
+		switch plugin.Result.Status {
+		case "", contracts.ResultStatusNotStarted, contracts.ResultStatusInProgress:
+			return i
+		case contracts.ResultStatusSuccessAndReboot:
+			return -1
+		}
+	}
+	return -1
+}
@@ -0,0 +1,84 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package executer provides interfaces as document execution logic
+package executer
+
+import (
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/stretchr/testify/assert"
+)
+
+type documentStoreStub struct {
+	saved []contracts.DocumentState
+}
+
+func (s *documentStoreStub) Save(docState contracts.DocumentState) {
+	s.saved = append(s.saved, docState)
+}
+
+func (s *documentStoreStub) Load() contracts.DocumentState {
+	return s.saved[len(s.saved)-1]
+}
+
+func newCheckpointDocState(statuses ...contracts.ResultStatus) contracts.DocumentState {
+	docState := contracts.DocumentState{}
+	for i, status := range statuses {
+		docState.InstancePluginsInformation = append(docState.InstancePluginsInformation, contracts.PluginState{
+			Id:     "abc"[i : i+1],
+			Name:   "aws:runShellScript",
+			Result: contracts.PluginResult{Status: status},
+		})
+	}
+	return docState
+}
+
+func TestCheckpointMarksNextPluginInProgress(t *testing.T) {
+	store := &documentStoreStub{}
+	docState := newCheckpointDocState(contracts.ResultStatusSuccess, contracts.ResultStatusNotStarted, "")
+
+	Checkpoint(store, &docState)
+
+	saved := store.Load()
+	assert.Equal(t, contracts.ResultStatusSuccess, saved.InstancePluginsInformation[0].Result.Status)
+	assert.Equal(t, contracts.ResultStatusInProgress, saved.InstancePluginsInformation[1].Result.Status)
+	assert.Equal(t, contracts.ResultStatus(""), saved.InstancePluginsInformation[2].Result.Status)
+	// the in-memory state is left unchanged
+	assert.Equal(t, contracts.ResultStatusNotStarted, docState.InstancePluginsInformation[1].Result.Status)
+}
+
+func TestCheckpointAfterRebootRequest(t *testing.T) {
+	store := &documentStoreStub{}
+	docState := newCheckpointDocState(contracts.ResultStatusSuccessAndReboot, contracts.ResultStatusNotStarted)
+
+	Checkpoint(store, &docState)
+
+	assert.Equal(t, contracts.ResultStatusNotStarted, store.Load().InstancePluginsInformation[1].Result.Status)
+}
+
+func TestRecoverInterruptedPlugins(t *testing.T) {
+	docState := newCheckpointDocState(contracts.ResultStatusSuccess, contracts.ResultStatusInProgress, contracts.ResultStatusNotStarted)
+
+	RecoverInterruptedPlugins(log.NewMockLog(), &docState, false)
+	assert.Equal(t, contracts.ResultStatusInProgress, docState.InstancePluginsInformation[1].Result.Status)
+
+	RecoverInterruptedPlugins(log.NewMockLog(), &docState, true)
+	assert.Equal(t, contracts.ResultStatusSuccess, docState.InstancePluginsInformation[0].Result.Status)
+	assert.Equal(t, contracts.ResultStatusFailed, docState.InstancePluginsInformation[1].Result.Status)
+	assert.Equal(t, InterruptedPluginError, docState.InstancePluginsInformation[1].Result.Error)
+	assert.Equal(t, "b", docState.InstancePluginsInformation[1].Result.PluginID)
+	assert.Equal(t, contracts.ResultStatusNotStarted, docState.InstancePluginsInformation[2].Result.Status)
+}
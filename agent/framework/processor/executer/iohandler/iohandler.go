@@ -18,8 +18,11 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"strings"
+	"time"
 
 	"github.com/aws/amazon-ssm-agent/agent/agentlogstocloudwatch/cloudwatchlogspublisher"
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
 	"github.com/aws/amazon-ssm-agent/agent/contracts"
 	"github.com/aws/amazon-ssm-agent/agent/fileutil"
 	"github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler/iomodule"
@@ -45,19 +48,32 @@ type PluginConfig struct {
 	MaxStdoutLength       int
 	MaxStderrLength       int
 	OutputTruncatedSuffix string
+	TruncationStrategy    string
+	// CloudWatchFlushIntervalSeconds is the longest the output is held before it's streamed to CloudWatchLogs
+	CloudWatchFlushIntervalSeconds int
 }
 
 // DefaultOutputConfig returns the default values for the plugin
+// with the output limits, truncation strategy and CloudWatch flush interval taken from the agent config
 func DefaultOutputConfig() PluginConfig {
-	return PluginConfig{
-		StdoutFileName:        "stdout",
-		StderrFileName:        "stderr",
-		StdoutConsoleFileName: "stdoutConsole",
-		StderrConsoleFileName: "stderrConsole",
-		MaxStdoutLength:       24000,
-		MaxStderrLength:       8000,
-		OutputTruncatedSuffix: "--output truncated--",
+	pluginConfig := PluginConfig{
+		StdoutFileName:                 "stdout",
+		StderrFileName:                 "stderr",
+		StdoutConsoleFileName:          "stdoutConsole",
+		StderrConsoleFileName:          "stderrConsole",
+		MaxStdoutLength:                appconfig.DefaultPluginOutputMaxStdoutBytes,
+		MaxStderrLength:                appconfig.DefaultPluginOutputMaxStderrBytes,
+		OutputTruncatedSuffix:          "--output truncated--",
+		TruncationStrategy:             appconfig.OutputTruncationStrategyHead,
+		CloudWatchFlushIntervalSeconds: appconfig.DefaultPluginOutputCloudWatchFlushIntervalSeconds,
 	}
+	if config, err := appconfig.Config(false); err == nil {
+		pluginConfig.MaxStdoutLength = config.Ssm.PluginOutputMaxStdoutBytes
+		pluginConfig.MaxStderrLength = config.Ssm.PluginOutputMaxStderrBytes
+		pluginConfig.TruncationStrategy = config.Ssm.PluginOutputTruncationStrategy
+		pluginConfig.CloudWatchFlushIntervalSeconds = config.Ssm.PluginOutputCloudWatchFlushIntervalSeconds
+	}
+	return pluginConfig
 }
 
 // IOHandler Interface defines interface for IOHandler type
@@ -108,6 +124,12 @@ type DefaultIOHandler struct {
 	// List of Writers attached to the IOHandler instance
 	StdoutWriter multiwriter.DocumentIOMultiWriter
 	StderrWriter multiwriter.DocumentIOMultiWriter
+
+	// secrets are replaced in everything written to the writers
+	secrets []string
+
+	// usage accumulates the resource usage the executers report through the writers
+	usage *resourceUsage
 }
 
 // NewDefaultIOHandler returns a new instance of the IOHandler
@@ -115,6 +137,7 @@ func NewDefaultIOHandler(log log.T, ioConfig contracts.IOConfiguration) *Default
 	log.Debugf("IOHandler Initialization with config: %v", ioConfig)
 	out := new(DefaultIOHandler)
 	out.ioConfig = ioConfig
+	out.usage = new(resourceUsage)
 
 	return out
 }
@@ -131,19 +154,16 @@ func (out *DefaultIOHandler) Init(log log.T, filePath ...string) {
 		s3KeyPrefix = fileutil.BuildS3Path(s3KeyPrefix, element)
 	}
 
-	stdOutLogStreamName := ""
-	stdErrLogStreamName := ""
-	if out.ioConfig.CloudWatchConfig.LogGroupName != "" {
+	cloudWatchLogGroupName := out.ioConfig.CloudWatchConfig.LogGroupName
+	if cloudWatchLogGroupName != "" {
 		cwl := cloudwatchlogspublisher.NewCloudWatchLogsService()
-		if !cwl.IsLogGroupPresent(log, out.ioConfig.CloudWatchConfig.LogGroupName) {
-			if err := cwl.CreateLogGroup(log, out.ioConfig.CloudWatchConfig.LogGroupName); err != nil {
+		if !cwl.IsLogGroupPresent(log, cloudWatchLogGroupName) {
+			if err := cwl.CreateLogGroup(log, cloudWatchLogGroupName); err != nil {
 				log.Errorf("Error Creating Log Group for CloudWatchLogs output: %v", err)
 				//Stop CloudWatch Streaming on Error
-				out.ioConfig.CloudWatchConfig.LogGroupName = ""
+				cloudWatchLogGroupName = ""
 			}
 		}
-		stdOutLogStreamName = fmt.Sprintf("%s/%s", out.ioConfig.CloudWatchConfig.LogStreamPrefix, pluginConfig.StdoutFileName)
-		stdErrLogStreamName = fmt.Sprintf("%s/%s", out.ioConfig.CloudWatchConfig.LogStreamPrefix, pluginConfig.StderrFileName)
 	}
 
 	// Initialize file output module
@@ -152,8 +172,7 @@ func (out *DefaultIOHandler) Init(log log.T, filePath ...string) {
 		OrchestrationDirectory: fullPath,
 		OutputS3BucketName:     out.ioConfig.OutputS3BucketName,
 		OutputS3KeyPrefix:      s3KeyPrefix,
-		LogGroupName:           out.ioConfig.CloudWatchConfig.LogGroupName,
-		LogStreamName:          stdOutLogStreamName,
+		OutputS3Options:        out.ioConfig.OutputS3Options,
 	}
 
 	// Initialize console output module
@@ -165,8 +184,8 @@ func (out *DefaultIOHandler) Init(log log.T, filePath ...string) {
 
 	log.Debug("Initializing the Stdout Multi-writer with file and console listeners")
 	// Get a multi-writer for standard output
-	out.StdoutWriter = multiwriter.NewDocumentIOMultiWriter()
-	out.RegisterOutputSource(log, out.StdoutWriter, stdoutFile, stdoutConsole)
+	out.StdoutWriter = out.newMultiWriter()
+	out.RegisterOutputSource(log, out.StdoutWriter, out.outputModules(pluginConfig, cloudWatchLogGroupName, pluginConfig.StdoutFileName, stdoutFile, stdoutConsole)...)
 
 	// Initialize file error module
 	stderrFile := iomodule.File{
@@ -174,8 +193,7 @@ func (out *DefaultIOHandler) Init(log log.T, filePath ...string) {
 		OrchestrationDirectory: fullPath,
 		OutputS3BucketName:     out.ioConfig.OutputS3BucketName,
 		OutputS3KeyPrefix:      s3KeyPrefix,
-		LogGroupName:           out.ioConfig.CloudWatchConfig.LogGroupName,
-		LogStreamName:          stdErrLogStreamName,
+		OutputS3Options:        out.ioConfig.OutputS3Options,
 	}
 
 	// Initialize console error module
@@ -187,8 +205,40 @@ func (out *DefaultIOHandler) Init(log log.T, filePath ...string) {
 
 	log.Debug("Initializing the Stderr Multi-writer with file and console listeners")
 	// Get a multi-writer for standard error
-	out.StderrWriter = multiwriter.NewDocumentIOMultiWriter()
-	out.RegisterOutputSource(log, out.StderrWriter, stderrFile, stderrConsole)
+	out.StderrWriter = out.newMultiWriter()
+	out.RegisterOutputSource(log, out.StderrWriter, out.outputModules(pluginConfig, cloudWatchLogGroupName, pluginConfig.StderrFileName, stderrFile, stderrConsole)...)
+}
+
+// outputModules adds the module streaming the output to CloudWatchLogs to the modules if CloudWatch output is enabled,
+// the output is streamed to <LogStreamPrefix>/<fileName> in the log group
+func (out *DefaultIOHandler) outputModules(pluginConfig PluginConfig, logGroupName string, fileName string, modules ...iomodule.IOModule) []iomodule.IOModule {
+	if logGroupName == "" {
+		return modules
+	}
+	return append(modules, iomodule.CloudWatchLogs{
+		LogGroupName:  logGroupName,
+		LogStreamName: fmt.Sprintf("%s/%s", out.ioConfig.CloudWatchConfig.LogStreamPrefix, fileName),
+		FlushInterval: time.Duration(pluginConfig.CloudWatchFlushIntervalSeconds) * time.Second,
+	})
+}
+
+// RedactSecrets makes the handler replace the secrets in the output written by the plugin.
+// It has to be called before Init.
+func (out *DefaultIOHandler) RedactSecrets(secrets []string) {
+	out.secrets = secrets
+}
+
+// newMultiWriter returns a multi-writer that records the resource usage of the processes writing to it
+// and redacts the secrets, if there are any
+func (out *DefaultIOHandler) newMultiWriter() multiwriter.DocumentIOMultiWriter {
+	var writer multiwriter.DocumentIOMultiWriter = multiwriter.NewDocumentIOMultiWriter()
+	if len(out.secrets) > 0 {
+		writer = newRedactingWriter(writer, out.secrets)
+	}
+	if out.usage == nil {
+		out.usage = new(resourceUsage)
+	}
+	return &usageRecordingWriter{DocumentIOMultiWriter: writer, usage: out.usage}
 }
 
 // RegisterOutputSource returns a new output source by creating a multiwriter for the output modules.
@@ -227,7 +277,7 @@ func (out *DefaultIOHandler) Close(log log.T) {
 
 // String returns the output by concatenating stdout and stderr
 func (out DefaultIOHandler) String() (response string) {
-	return TruncateOutput(out.stdout, out.stderr, MaximumPluginOutputSize)
+	return TruncateOutputWithStrategy(out.stdout, out.stderr, MaximumPluginOutputSize, DefaultOutputConfig().TruncationStrategy)
 }
 
 // GetOutput returns the output to be appended to the response
@@ -322,6 +372,10 @@ func (out *DefaultIOHandler) Merge(log log.T, mergeOutput *DefaultIOHandler) {
 		out.ExitCode = mergeOutput.GetExitCode()
 	}
 	out.Status = contracts.MergeResultStatus(out.Status, mergeOutput.GetStatus())
+
+	if usage := mergeOutput.GetResourceUsage(); usage != nil {
+		out.RecordResourceUsage(*usage)
+	}
 }
 
 // MarkAsFailed Failed marks plugin as Failed
@@ -416,8 +470,13 @@ func (out *DefaultIOHandler) AppendErrorf(format string, params ...interface{})
 	}
 }
 
-// TruncateOutput truncates the output
+// TruncateOutput truncates the output keeping the beginning of stdout and stderr
 func TruncateOutput(stdout string, stderr string, capacity int) (response string) {
+	return TruncateOutputWithStrategy(stdout, stderr, capacity, appconfig.OutputTruncationStrategyHead)
+}
+
+// TruncateOutputWithStrategy truncates the output keeping either the beginning or the end of stdout and stderr
+func TruncateOutputWithStrategy(stdout string, stderr string, capacity int, strategy string) (response string) {
 	outputSize := len(stdout)
 	errorSize := len(stderr)
 
@@ -440,16 +499,24 @@ func TruncateOutput(stdout string, stderr string, capacity int) (response string
 	// truncate out and error when both exceed the size
 	if outputSize > availableSpace/2 && errorSize > availableSpace/2 {
 		truncateSize := availableSpace - len(truncateError) - len(truncateOut)
-		return fmt.Sprint(stdout[:truncateSize/2], truncateOut, errorTitle, stderr[:truncateSize/2], truncateError)
+		return fmt.Sprint(truncate(stdout, truncateSize/2, truncateOut, strategy), errorTitle, truncate(stderr, truncateSize/2, truncateError, strategy))
 	}
 
 	// truncate error when output is short
 	if outputSize < availableSpace/2 {
 		truncateSize := availableSpace - len(truncateError)
-		return fmt.Sprint(stdout, errorTitle, stderr[:truncateSize-outputSize], truncateError)
+		return fmt.Sprint(stdout, errorTitle, truncate(stderr, truncateSize-outputSize, truncateError, strategy))
 	}
 
 	// truncate output when error is short
 	truncateSize := availableSpace - len(truncateOut)
-	return fmt.Sprint(stdout[:truncateSize-errorSize], truncateOut, errorTitle, stderr)
+	return fmt.Sprint(truncate(stdout, truncateSize-errorSize, truncateOut, strategy), errorTitle, stderr)
+}
+
+// truncate keeps size bytes of the input and marks where the rest was cut off
+func truncate(input string, size int, truncatedMarker string, strategy string) string {
+	if strategy == appconfig.OutputTruncationStrategyTail {
+		return fmt.Sprint(strings.TrimPrefix(truncatedMarker, "\n"), "\n", input[len(input)-size:])
+	}
+	return fmt.Sprint(input[:size], truncatedMarker)
 }
@@ -15,11 +15,13 @@ package iohandler
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"sync"
 	"time"
 
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
 	"github.com/aws/amazon-ssm-agent/agent/contracts"
 	iomodulemock "github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler/iomodule/mock"
 	multiwritermock "github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler/multiwriter/mock"
@@ -68,6 +70,16 @@ func TestTruncateOutput(t *testing.T) {
 	}
 }
 
+func TestTruncateOutputKeepsTail(t *testing.T) {
+	stderr := "error: " + strings.Repeat("x", sampleSize) + " caused by the last line"
+
+	actual := TruncateOutputWithStrategy("sample output", stderr, sampleSize, appconfig.OutputTruncationStrategyTail)
+
+	assert.Equal(t, sampleSize, len(actual))
+	assert.True(t, strings.HasPrefix(actual, "sample output\n----------ERROR-------\n---Error truncated----\n"))
+	assert.True(t, strings.HasSuffix(actual, " caused by the last line"))
+}
+
 var logger = log.NewMockLog()
 
 func TestRegisterOutputSource(t *testing.T) {
@@ -0,0 +1,295 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package iomodule
+
+import (
+	"io"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/aws/amazon-ssm-agent/agent/agentlogstocloudwatch/cloudwatchlogspublisher"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+)
+
+// PutLogEvents limits - https://docs.aws.amazon.com/AmazonCloudWatch/latest/logs/cloudwatch_limits_cwl.html
+const (
+	maxEventsPerBatch  = 10000
+	maxBytesPerBatch   = 1048576
+	eventOverheadBytes = 26
+	maxEventBytes      = 256*1024 - eventOverheadBytes
+)
+
+const (
+	maxCloudWatchUploadRetry = 5
+	readBufferSize           = 4096
+)
+
+// cloudWatchLogsService is the part of the CloudWatch Logs service the output is streamed with
+type cloudWatchLogsService interface {
+	IsLogStreamPresent(log log.T, logGroupName, logStreamName string) bool
+	CreateLogStream(log log.T, logGroup, logStream string) (err error)
+	GetSequenceTokenForStream(log log.T, logGroupName, logStreamName string) (sequenceToken *string)
+	PutLogEvents(log log.T, messages []*cloudwatchlogs.InputLogEvent, logGroup, logStream string, sequenceToken *string) (nextSequenceToken *string, err error)
+}
+
+// Assign method to global variables to allow unittest to override
+var newCloudWatchLogsService = func() cloudWatchLogsService {
+	return cloudwatchlogspublisher.NewCloudWatchLogsService()
+}
+
+// CloudWatchLogs streams the output to a CloudWatch Logs stream while the plugin runs
+type CloudWatchLogs struct {
+	LogGroupName  string
+	LogStreamName string
+	// FlushInterval is the longest the output is held before it's sent
+	FlushInterval time.Duration
+}
+
+// Read reads from the stream as the output is produced and sends it to CloudWatchLogs in batches, every line is an event.
+// The stream is read independently of the uploads, so that a slow upload never holds the plugin back.
+func (c CloudWatchLogs) Read(log log.T, reader *io.PipeReader) {
+	defer func() { reader.Close() }()
+
+	log.Debugf("Streaming output to CloudWatch Logs group %v, stream %v", c.LogGroupName, c.LogStreamName)
+	if c.FlushInterval <= 0 {
+		c.FlushInterval = cloudwatchlogspublisher.UploadFrequency
+	}
+	stream := &cloudWatchLogsStream{
+		CloudWatchLogs: c,
+		service:        newCloudWatchLogsService(),
+		batchFull:      make(chan bool, 1),
+	}
+	done := make(chan bool)
+	finished := make(chan bool)
+	go func() {
+		defer close(finished)
+		stream.run(log, done)
+	}()
+
+	buffer := make([]byte, readBufferSize)
+	for {
+		n, err := reader.Read(buffer)
+		if n > 0 {
+			stream.add(string(buffer[:n]), time.Now())
+		}
+		if err != nil {
+			if err != io.EOF {
+				log.Errorf("Error with the reader while reading the stream: %v", err)
+			}
+			break
+		}
+	}
+
+	close(done)
+	<-finished
+}
+
+// cloudWatchLogsStream holds the output read until it's sent
+type cloudWatchLogsStream struct {
+	CloudWatchLogs
+	service cloudWatchLogsService
+
+	lock      sync.Mutex
+	batch     eventBatch
+	stopped   bool
+	batchFull chan bool
+
+	// only used by the go routine sending the events
+	unsent        []*cloudwatchlogs.InputLogEvent
+	streamCreated bool
+	sequenceToken *string
+	failures      int
+}
+
+// add adds the output to the batch, the batch is sent right away if it reached the size of an upload
+func (s *cloudWatchLogsStream) add(text string, now time.Time) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.stopped {
+		return
+	}
+	s.batch.add(text, now)
+	if s.batch.size >= maxBytesPerBatch || len(s.batch.events) >= maxEventsPerBatch {
+		select {
+		case s.batchFull <- true:
+		default:
+		}
+	}
+}
+
+// run sends the events every FlushInterval and when the batch is full, until the reader is done
+func (s *cloudWatchLogsStream) run(log log.T, done chan bool) {
+	ticker := time.NewTicker(s.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.send(log, false)
+		case <-s.batchFull:
+			s.send(log, false)
+		case <-done:
+			// keep trying to send the end of the output until it's sent or streaming is stopped
+			for !s.send(log, true) {
+				time.Sleep(s.FlushInterval)
+			}
+			return
+		}
+	}
+}
+
+// send uploads the events of the batch along with the ones that failed to upload before, returns true if nothing is left to send
+func (s *cloudWatchLogsStream) send(log log.T, final bool) bool {
+	s.lock.Lock()
+	if final {
+		s.batch.closeLine(time.Now())
+	} else {
+		s.batch.flushLine(time.Now(), s.FlushInterval)
+	}
+	s.unsent = append(s.unsent, s.batch.take()...)
+	stopped := s.stopped
+	s.lock.Unlock()
+
+	if stopped {
+		return true
+	}
+	for len(s.unsent) > 0 {
+		count := nextBatchSize(s.unsent)
+		if err := s.put(log, s.unsent[:count]); err != nil {
+			s.failures++
+			log.Debugf("Failed to upload the output to CloudWatch Logs (%v of %v attempts): %v", s.failures, maxCloudWatchUploadRetry, err)
+			if s.failures >= maxCloudWatchUploadRetry {
+				log.Errorf("Stopped streaming the output to CloudWatch Logs group %v, stream %v: %v", s.LogGroupName, s.LogStreamName, err)
+				s.lock.Lock()
+				s.stopped = true
+				s.batch = eventBatch{}
+				s.lock.Unlock()
+				s.unsent = nil
+				return true
+			}
+			return false
+		}
+		s.failures = 0
+		s.unsent = s.unsent[count:]
+	}
+	return true
+}
+
+// put creates the log stream if needed and uploads the events
+func (s *cloudWatchLogsStream) put(log log.T, events []*cloudwatchlogs.InputLogEvent) (err error) {
+	if !s.streamCreated {
+		if !s.service.IsLogStreamPresent(log, s.LogGroupName, s.LogStreamName) {
+			if err = s.service.CreateLogStream(log, s.LogGroupName, s.LogStreamName); err != nil {
+				return err
+			}
+		} else {
+			s.sequenceToken = s.service.GetSequenceTokenForStream(log, s.LogGroupName, s.LogStreamName)
+		}
+		s.streamCreated = true
+	}
+
+	nextSequenceToken, err := s.service.PutLogEvents(log, events, s.LogGroupName, s.LogStreamName, s.sequenceToken)
+	if err != nil {
+		return err
+	}
+	s.sequenceToken = nextSequenceToken
+	return nil
+}
+
+// nextBatchSize returns the number of events that fit in one upload
+func nextBatchSize(events []*cloudwatchlogs.InputLogEvent) (count int) {
+	size := 0
+	for count < len(events) && count < maxEventsPerBatch {
+		size += len(*events[count].Message) + eventOverheadBytes
+		if size > maxBytesPerBatch {
+			break
+		}
+		count++
+	}
+	return count
+}
+
+// eventBatch splits the output into events, one per line.
+// The end of the output that isn't a complete line yet is kept until the rest of the line is read, or until it waited long enough.
+type eventBatch struct {
+	events   []*cloudwatchlogs.InputLogEvent
+	size     int
+	line     string
+	lineTime time.Time
+}
+
+// add splits the text into events
+func (b *eventBatch) add(text string, now time.Time) {
+	lines := strings.Split(b.line+text, "\n")
+	for _, line := range lines[:len(lines)-1] {
+		b.addEvent(strings.TrimSuffix(line, "\r"), now)
+	}
+	if len(lines) > 1 || b.line == "" {
+		b.lineTime = now
+	}
+	b.line = lines[len(lines)-1]
+	for len(b.line) > maxEventBytes {
+		cut := eventLength(b.line)
+		b.addEvent(b.line[:cut], now)
+		b.line = b.line[cut:]
+	}
+}
+
+// flushLine adds the incomplete line as an event if it has been waiting for the rest of the line for longer than the interval
+func (b *eventBatch) flushLine(now time.Time, interval time.Duration) {
+	if b.line != "" && now.Sub(b.lineTime) >= interval {
+		b.closeLine(now)
+	}
+}
+
+// closeLine adds the incomplete line as an event
+func (b *eventBatch) closeLine(now time.Time) {
+	b.addEvent(b.line, now)
+	b.line = ""
+}
+
+// addEvent adds the message as events, messages longer than an event are split, empty messages are left out
+func (b *eventBatch) addEvent(message string, now time.Time) {
+	for message != "" {
+		cut := eventLength(message)
+		b.events = append(b.events, &cloudwatchlogs.InputLogEvent{
+			Message:   aws.String(message[:cut]),
+			Timestamp: aws.Int64(now.UnixNano() / int64(time.Millisecond)),
+		})
+		b.size += cut + eventOverheadBytes
+		message = message[cut:]
+	}
+}
+
+// take returns the events and empties the batch
+func (b *eventBatch) take() (events []*cloudwatchlogs.InputLogEvent) {
+	events, b.events, b.size = b.events, nil, 0
+	return events
+}
+
+// eventLength returns the length of the start of the message that fits in an event without splitting a character
+func eventLength(message string) int {
+	if len(message) <= maxEventBytes {
+		return len(message)
+	}
+	cut := maxEventBytes
+	for cut > 0 && !utf8.RuneStart(message[cut]) {
+		cut--
+	}
+	return cut
+}
@@ -0,0 +1,201 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package iomodule
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/stretchr/testify/assert"
+)
+
+// cloudWatchLogsServiceStub records the events put to a single log stream
+type cloudWatchLogsServiceStub struct {
+	lock           sync.Mutex
+	streamCreated  int
+	sequenceTokens []string
+	messages       []string
+	putErr         error
+	puts           int
+}
+
+func (s *cloudWatchLogsServiceStub) IsLogStreamPresent(log log.T, logGroupName, logStreamName string) bool {
+	return false
+}
+
+func (s *cloudWatchLogsServiceStub) CreateLogStream(log log.T, logGroup, logStream string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.streamCreated++
+	return nil
+}
+
+func (s *cloudWatchLogsServiceStub) GetSequenceTokenForStream(log log.T, logGroupName, logStreamName string) *string {
+	return nil
+}
+
+func (s *cloudWatchLogsServiceStub) PutLogEvents(log log.T, messages []*cloudwatchlogs.InputLogEvent, logGroup, logStream string, sequenceToken *string) (*string, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.puts++
+	if s.putErr != nil {
+		return nil, s.putErr
+	}
+	s.sequenceTokens = append(s.sequenceTokens, aws.StringValue(sequenceToken))
+	for _, message := range messages {
+		s.messages = append(s.messages, *message.Message)
+	}
+	return aws.String(fmt.Sprintf("token%d", s.puts)), nil
+}
+
+func (s *cloudWatchLogsServiceStub) received() []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]string{}, s.messages...)
+}
+
+func stubCloudWatchLogsService(service *cloudWatchLogsServiceStub) (restore func()) {
+	newCloudWatchLogsService = func() cloudWatchLogsService { return service }
+	return func() {
+		newCloudWatchLogsService = func() cloudWatchLogsService { return nil }
+	}
+}
+
+func TestCloudWatchLogsStreamsWhileRunning(t *testing.T) {
+	service := &cloudWatchLogsServiceStub{}
+	defer stubCloudWatchLogsService(service)()
+
+	r, w := io.Pipe()
+	done := make(chan bool)
+	go func() {
+		defer close(done)
+		CloudWatchLogs{LogGroupName: "group", LogStreamName: "stream", FlushInterval: 10 * time.Millisecond}.Read(logger, r)
+	}()
+
+	w.Write([]byte("first line\r\nsecond "))
+	w.Write([]byte("line\n\n"))
+	// the output is sent before the plugin is done
+	assert.True(t, waitFor(func() bool { return len(service.received()) == 2 }))
+	assert.Equal(t, []string{"first line", "second line"}, service.received())
+
+	w.Write([]byte("incomplete line"))
+	w.Close()
+	<-done
+
+	assert.Equal(t, []string{"first line", "second line", "incomplete line"}, service.received())
+	assert.Equal(t, 1, service.streamCreated)
+	assert.Equal(t, []string{"", "token1"}, service.sequenceTokens)
+}
+
+func TestCloudWatchLogsStopsAfterFailures(t *testing.T) {
+	service := &cloudWatchLogsServiceStub{putErr: errors.New("AccessDeniedException")}
+	defer stubCloudWatchLogsService(service)()
+
+	r, w := io.Pipe()
+	done := make(chan bool)
+	go func() {
+		defer close(done)
+		CloudWatchLogs{LogGroupName: "group", LogStreamName: "stream", FlushInterval: time.Millisecond}.Read(logger, r)
+	}()
+
+	w.Write([]byte("line\n"))
+	assert.True(t, waitFor(func() bool {
+		service.lock.Lock()
+		defer service.lock.Unlock()
+		return service.puts >= maxCloudWatchUploadRetry
+	}))
+	// the writer isn't held back once streaming stopped
+	w.Write([]byte("more output\n"))
+	w.Close()
+	<-done
+
+	assert.Equal(t, maxCloudWatchUploadRetry, service.puts)
+	assert.Empty(t, service.received())
+}
+
+func TestEventBatch(t *testing.T) {
+	start := time.Now()
+	batch := eventBatch{}
+
+	batch.add("a\nb", start)
+	batch.add("c\n\nd", start.Add(time.Second))
+	assert.Equal(t, []string{"a", "bc"}, messages(batch.events))
+	assert.Equal(t, 2+1+2*eventOverheadBytes, batch.size)
+
+	// the incomplete line is added once it waited for the interval
+	batch.flushLine(start.Add(2*time.Second), 2*time.Second)
+	assert.Len(t, batch.events, 2)
+	batch.flushLine(start.Add(3*time.Second), 2*time.Second)
+	assert.Equal(t, []string{"a", "bc", "d"}, messages(batch.take()))
+	assert.Empty(t, batch.events)
+	assert.Equal(t, 0, batch.size)
+
+	// long lines are split into events
+	batch.add(strings.Repeat("x", maxEventBytes+10), start)
+	assert.Equal(t, []int{maxEventBytes}, lengths(batch.events))
+	batch.closeLine(start)
+	assert.Equal(t, []int{maxEventBytes, 10}, lengths(batch.take()))
+
+	// characters aren't split
+	batch.add(strings.Repeat("x", maxEventBytes-1)+"é\n", start)
+	assert.Equal(t, []int{maxEventBytes - 1, 2}, lengths(batch.take()))
+}
+
+func TestNextBatchSize(t *testing.T) {
+	events := []*cloudwatchlogs.InputLogEvent{}
+	for i := 0; i < 5; i++ {
+		events = append(events, &cloudwatchlogs.InputLogEvent{Message: aws.String(strings.Repeat("x", maxEventBytes))})
+	}
+	assert.Equal(t, 4, nextBatchSize(events))
+	assert.Equal(t, 1, nextBatchSize(events[4:]))
+
+	events = make([]*cloudwatchlogs.InputLogEvent, maxEventsPerBatch+1)
+	for i := range events {
+		events[i] = &cloudwatchlogs.InputLogEvent{Message: aws.String("x")}
+	}
+	assert.Equal(t, maxEventsPerBatch, nextBatchSize(events))
+}
+
+func messages(events []*cloudwatchlogs.InputLogEvent) (result []string) {
+	for _, event := range events {
+		result = append(result, *event.Message)
+	}
+	return result
+}
+
+func lengths(events []*cloudwatchlogs.InputLogEvent) (result []int) {
+	for _, event := range events {
+		result = append(result, len(*event.Message))
+	}
+	return result
+}
+
+// waitFor waits up to a second for the condition to be true
+func waitFor(condition func() bool) bool {
+	for i := 0; i < 100; i++ {
+		if condition() {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return condition()
+}
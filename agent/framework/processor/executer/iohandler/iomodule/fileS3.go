@@ -18,30 +18,24 @@ import (
 	"io"
 	"os"
 	"path/filepath"
-	"time"
 
-	"github.com/aws/amazon-ssm-agent/agent/agentlogstocloudwatch/cloudwatchlogspublisher"
 	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
 	"github.com/aws/amazon-ssm-agent/agent/fileutil"
 	"github.com/aws/amazon-ssm-agent/agent/log"
 	"github.com/aws/amazon-ssm-agent/agent/s3util"
 )
 
-const (
-	maxCloudWatchUploadRetry = 5
-)
-
-// File handles writing to an output file and upload to s3 and cloudWatch
+// File handles writing to an output file and upload to s3
 type File struct {
 	FileName               string
 	OrchestrationDirectory string
 	OutputS3BucketName     string
 	OutputS3KeyPrefix      string
-	LogGroupName           string
-	LogStreamName          string
+	OutputS3Options        contracts.S3OutputOptions
 }
 
-// Read reads from the stream and writes to the output file, s3 and CloudWatchLogs.
+// Read reads from the stream and writes to the output file and s3.
 func (file File) Read(log log.T, reader *io.PipeReader) {
 	defer func() { reader.Close() }()
 
@@ -63,13 +57,6 @@ func (file File) Read(log log.T, reader *io.PipeReader) {
 
 	defer fileWriter.Close()
 
-	cwl := cloudwatchlogspublisher.NewCloudWatchLogsService()
-	if file.LogGroupName != "" {
-		log.Debugf("Received CloudWatch Configs: LogGroupName: %s\n, LogStreamName: %s\n", file.LogGroupName, file.LogStreamName)
-		//Start CWL logging on different go routine
-		go cwl.StreamData(log, file.LogGroupName, file.LogStreamName, filePath, false, false)
-	}
-
 	// Read byte by byte and write to file
 	scanner := bufio.NewScanner(reader)
 	scanner.Split(bufio.ScanBytes)
@@ -93,19 +80,8 @@ func (file File) Read(log log.T, reader *io.PipeReader) {
 	// Upload output file to S3
 	if file.OutputS3BucketName != "" && fi.Size() > 0 {
 		s3Key := fileutil.BuildS3Path(file.OutputS3KeyPrefix, file.FileName)
-		if err := s3util.NewAmazonS3Util(log, file.OutputS3BucketName).S3Upload(log, file.OutputS3BucketName, s3Key, filePath); err != nil {
+		if err := s3util.NewAmazonS3UtilWithOptions(log, file.OutputS3BucketName, file.OutputS3Options).S3Upload(log, file.OutputS3BucketName, s3Key, filePath); err != nil {
 			log.Errorf("Failed to upload the output to s3: %v", err)
 		}
 	}
-
-	//Block main thread until CloudWatchLogs uploading is complete or until maxCloudWatchUploadRetry is reached
-	//TODO Add unit test to test maxRetry logic
-	if file.LogGroupName != "" {
-		cwl.IsFileComplete = true
-		retry := 0
-		for !cwl.IsUploadComplete && retry < maxCloudWatchUploadRetry {
-			retry++
-			time.Sleep(cloudwatchlogspublisher.UploadFrequency)
-		}
-	}
 }
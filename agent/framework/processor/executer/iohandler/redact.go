@@ -0,0 +1,108 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package iohandler implements the iohandler for the plugins
+package iohandler
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler/multiwriter"
+)
+
+// RedactedText replaces the secrets in the output of the plugins
+const RedactedText = "****"
+
+// Redact replaces the secrets in the text.
+func Redact(text string, secrets []string) string {
+	for _, secret := range sortSecrets(secrets) {
+		text = strings.Replace(text, secret, RedactedText, -1)
+	}
+	return text
+}
+
+// sortSecrets orders the secrets longest first, so that a secret containing another one is redacted as a whole
+func sortSecrets(secrets []string) []string {
+	sorted := []string{}
+	for _, secret := range secrets {
+		if secret != "" {
+			sorted = append(sorted, secret)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return len(sorted[i]) > len(sorted[j]) })
+	return sorted
+}
+
+// redactingWriter replaces the secrets in everything written to the multi-writer it wraps.
+// A secret can be split across writes, so the end of a write that could be the start of a secret is
+// held back until the next write or until the writer is closed.
+type redactingWriter struct {
+	multiwriter.DocumentIOMultiWriter
+	secrets []string
+	pending string
+	lock    sync.Mutex
+}
+
+func newRedactingWriter(writer multiwriter.DocumentIOMultiWriter, secrets []string) *redactingWriter {
+	return &redactingWriter{DocumentIOMultiWriter: writer, secrets: sortSecrets(secrets)}
+}
+
+// Write redacts the secrets and writes the text to all the attached pipes.
+func (w *redactingWriter) Write(p []byte) (n int, err error) {
+	if _, err = w.WriteString(string(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// WriteString redacts the secrets and writes the text to all the attached pipes.
+func (w *redactingWriter) WriteString(message string) (n int, err error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	text := Redact(w.pending+message, w.secrets)
+	held := w.partialSecretLength(text)
+	w.pending = text[len(text)-held:]
+	if held < len(text) {
+		if _, err = w.DocumentIOMultiWriter.WriteString(text[:len(text)-held]); err != nil {
+			return 0, err
+		}
+	}
+	return len(message), nil
+}
+
+// Close writes the text held back and closes the multi-writer.
+func (w *redactingWriter) Close() error {
+	w.lock.Lock()
+	if w.pending != "" {
+		w.DocumentIOMultiWriter.WriteString(w.pending)
+		w.pending = ""
+	}
+	w.lock.Unlock()
+	return w.DocumentIOMultiWriter.Close()
+}
+
+// partialSecretLength returns the length of the longest end of the text that is the start of a secret
+func (w *redactingWriter) partialSecretLength(text string) (longest int) {
+	for _, secret := range w.secrets {
+		for length := len(secret) - 1; length > longest; length-- {
+			if strings.HasSuffix(text, secret[:length]) {
+				longest = length
+				break
+			}
+		}
+	}
+	return longest
+}
@@ -0,0 +1,51 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package iohandler implements the iohandler for the plugins
+package iohandler
+
+import (
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler/multiwriter"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedact(t *testing.T) {
+	assert.Equal(t, "user **** password ****", Redact("user admin password admin123", []string{"admin", "admin123"}))
+	assert.Equal(t, "nothing to hide", Redact("nothing to hide", []string{""}))
+}
+
+func TestRedactingWriter(t *testing.T) {
+	writer := newRedactingWriter(multiwriter.NewDocumentIOMultiWriter(), []string{"hunter2"})
+	reader, pipe := io.Pipe()
+	writer.AddWriter(pipe)
+	written := make(chan string)
+	go func() {
+		text, _ := ioutil.ReadAll(reader)
+		writer.GetWaitGroup().Done()
+		written <- string(text)
+	}()
+
+	// the secret is split across writes
+	for _, text := range []string{"password: hun", "ter2\n", "hint: hunt", "er\n", "again hunter2"} {
+		n, err := writer.WriteString(text)
+		assert.NoError(t, err)
+		assert.Equal(t, len(text), n)
+	}
+	writer.Close()
+
+	assert.Equal(t, "password: ****\nhint: hunter\nagain ****", <-written)
+}
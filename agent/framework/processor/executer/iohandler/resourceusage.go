@@ -0,0 +1,77 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package iohandler implements the iohandler for the plugins
+package iohandler
+
+import (
+	"sync"
+
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler/multiwriter"
+)
+
+// resourceUsage accumulates the resource usage of the processes run by a plugin
+type resourceUsage struct {
+	lock  sync.Mutex
+	total *contracts.ResourceUsage
+}
+
+func (u *resourceUsage) add(usage contracts.ResourceUsage) {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+
+	if u.total == nil {
+		u.total = &contracts.ResourceUsage{}
+	}
+	*u.total = u.total.Add(usage)
+}
+
+func (u *resourceUsage) get() *contracts.ResourceUsage {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+
+	if u.total == nil {
+		return nil
+	}
+	total := *u.total
+	return &total
+}
+
+// usageRecordingWriter passes the resource usage the executers report for the processes writing
+// to the multi-writer it wraps on to the handler
+type usageRecordingWriter struct {
+	multiwriter.DocumentIOMultiWriter
+	usage *resourceUsage
+}
+
+// RecordResourceUsage adds the resource usage of a process to the usage of the plugin
+func (w *usageRecordingWriter) RecordResourceUsage(usage contracts.ResourceUsage) {
+	w.usage.add(usage)
+}
+
+// RecordResourceUsage adds the resource usage of a process to the usage of the plugin
+func (out *DefaultIOHandler) RecordResourceUsage(usage contracts.ResourceUsage) {
+	if out.usage == nil {
+		out.usage = new(resourceUsage)
+	}
+	out.usage.add(usage)
+}
+
+// GetResourceUsage returns the resource usage of the processes run by the plugin, nil if none completed
+func (out DefaultIOHandler) GetResourceUsage() *contracts.ResourceUsage {
+	if out.usage == nil {
+		return nil
+	}
+	return out.usage.get()
+}
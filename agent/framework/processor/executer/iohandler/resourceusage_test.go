@@ -0,0 +1,55 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package iohandler implements the iohandler for the plugins
+package iohandler
+
+import (
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/stretchr/testify/assert"
+)
+
+// usageRecorder is the interface the executers look up on the writers they are given
+type usageRecorder interface {
+	RecordResourceUsage(usage contracts.ResourceUsage)
+}
+
+func TestWritersRecordResourceUsage(t *testing.T) {
+	out := NewDefaultIOHandler(log.NewMockLog(), contracts.IOConfiguration{})
+	out.RedactSecrets([]string{"secret"})
+	assert.Nil(t, out.GetResourceUsage())
+
+	stdout, ok := out.newMultiWriter().(usageRecorder)
+	assert.True(t, ok)
+	stderr, ok := out.newMultiWriter().(usageRecorder)
+	assert.True(t, ok)
+	stdout.RecordResourceUsage(contracts.ResourceUsage{UserCPUSeconds: 1, PeakMemoryKilobytes: 100})
+	stderr.RecordResourceUsage(contracts.ResourceUsage{UserCPUSeconds: 2, PeakMemoryKilobytes: 50})
+
+	assert.Equal(t, &contracts.ResourceUsage{UserCPUSeconds: 3, PeakMemoryKilobytes: 100}, out.GetResourceUsage())
+}
+
+func TestMergeResourceUsage(t *testing.T) {
+	out := NewDefaultIOHandler(log.NewMockLog(), contracts.IOConfiguration{})
+	withoutProcess := NewDefaultIOHandler(log.NewMockLog(), contracts.IOConfiguration{})
+	out.Merge(log.NewMockLog(), withoutProcess)
+	assert.Nil(t, out.GetResourceUsage())
+
+	withProcess := &DefaultIOHandler{}
+	withProcess.RecordResourceUsage(contracts.ResourceUsage{SystemCPUSeconds: 0.5, ReadBytes: 512})
+	out.Merge(log.NewMockLog(), withProcess)
+	assert.Equal(t, &contracts.ResourceUsage{SystemCPUSeconds: 0.5, ReadBytes: 512}, out.GetResourceUsage())
+}
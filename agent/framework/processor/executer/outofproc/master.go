@@ -94,7 +94,7 @@ func (e *OutOfProcExecuter) Run(
 				log.Info("Executer closed")
 				close(resChan)
 			}()
-			e.messaging(log, ipc, resChan, cancelFlag, stopTimer)
+			e.messaging(log, ipc, resChan, store, cancelFlag, stopTimer)
 		}(docStore)
 
 		return resChan
@@ -104,10 +104,10 @@ func (e *OutOfProcExecuter) Run(
 //Executer spins up an ipc transmission worker, it creates a Data processing backend and hands off the backend to the ipc worker
 //ipc worker and data backend act as 2 threads exchange raw json messages, and messaging protocol happened in data backend, data backend is self-contained and exit when command finishes accordingly
 //Executer however does hold a timer to the worker to forcefully termniate both of them
-func (e *OutOfProcExecuter) messaging(log log.T, ipc channel.Channel, resChan chan contracts.DocumentResult, cancelFlag task.CancelFlag, stopTimer chan bool) {
+func (e *OutOfProcExecuter) messaging(log log.T, ipc channel.Channel, resChan chan contracts.DocumentResult, docStore executer.DocumentStore, cancelFlag task.CancelFlag, stopTimer chan bool) {
 
 	//handoff reply functionalities to data backend.
-	backend := messaging.NewExecuterBackend(resChan, e.docState, cancelFlag)
+	backend := messaging.NewExecuterBackend(resChan, e.docState, docStore, cancelFlag)
 	//handoff the data backend to messaging worker
 	if err := messaging.Messaging(log, ipc, backend, stopTimer); err != nil {
 		//the messaging worker encountered error, either ipc run into error or data backend throws error
@@ -167,6 +167,8 @@ func (e *OutOfProcExecuter) initialize(stopTimer chan bool) (ipc channel.Channel
 		go timeout(stopTimer, stopTime, e.cancelFlag)
 	} else {
 		log.Debug("channel not found, starting a new process...")
+		//the plugins left InProgress are not executed by any worker anymore
+		executer.RecoverInterruptedPlugins(log, e.docState, e.ctx.AppConfig().Agent.FailInterruptedPlugins)
 		var workerName string
 		if e.docState.DocumentType == contracts.StartSession {
 			workerName = appconfig.DefaultSessionWorker
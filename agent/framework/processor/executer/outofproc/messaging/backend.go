@@ -7,6 +7,7 @@ import (
 
 	"github.com/aws/amazon-ssm-agent/agent/context"
 	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/framework/processor/executer"
 	"github.com/aws/amazon-ssm-agent/agent/jsonutil"
 	"github.com/aws/amazon-ssm-agent/agent/task"
 )
@@ -37,18 +38,21 @@ type WorkerBackend struct {
 type ExecuterBackend struct {
 	//the shared state object that Executer hand off to data backend
 	docState   *contracts.DocumentState
+	//the store plugin progress is checkpointed to
+	docStore   executer.DocumentStore
 	input      chan string
 	cancelFlag task.CancelFlag
 	output     chan contracts.DocumentResult
 	stopChan   chan int
 }
 
-func NewExecuterBackend(output chan contracts.DocumentResult, docState *contracts.DocumentState, cancelFlag task.CancelFlag) *ExecuterBackend {
+func NewExecuterBackend(output chan contracts.DocumentResult, docState *contracts.DocumentState, docStore executer.DocumentStore, cancelFlag task.CancelFlag) *ExecuterBackend {
 	stopChan := make(chan int, defaultBackendChannelSize)
 	inputChan := make(chan string, defaultBackendChannelSize)
 	p := ExecuterBackend{
 		output:     output,
 		docState:   docState,
+		docStore:   docStore,
 		input:      inputChan,
 		cancelFlag: cancelFlag,
 		stopChan:   stopChan,
@@ -92,6 +96,9 @@ func (p *ExecuterBackend) Process(datagram string) error {
 		var docResult contracts.DocumentResult
 		jsonutil.Unmarshal(content, &docResult)
 		p.formatDocResult(&docResult)
+		if t == MessageTypeReply && p.docStore != nil {
+			executer.Checkpoint(p.docStore, p.docState)
+		}
 		p.output <- docResult
 		if t == MessageTypeComplete {
 			//get document result, force termniate messaging worker
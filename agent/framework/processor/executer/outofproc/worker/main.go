@@ -16,6 +16,7 @@ import (
 	"github.com/aws/amazon-ssm-agent/agent/log/ssmlog"
 	"github.com/aws/amazon-ssm-agent/agent/platform"
 	"github.com/aws/amazon-ssm-agent/agent/task"
+	"github.com/aws/amazon-ssm-agent/agent/tracing"
 )
 
 const (
@@ -29,7 +30,10 @@ var pluginRunner = func(
 	resChan chan contracts.PluginResult,
 	cancelFlag task.CancelFlag,
 ) {
+	// the plugins are traced as children of the span of the document execution the agent started
+	restoreCurrentSpan := tracing.SetCurrent(tracing.ParseTraceParent(docState.DocumentInformation.TraceParent))
 	runpluginutil.RunPlugins(context, docState.InstancePluginsInformation, docState.IOConfig, runpluginutil.SSMPluginRegistry, resChan, cancelFlag)
+	restoreCurrentSpan()
 	//make sure to signal the client that job complete
 	close(resChan)
 }
@@ -73,6 +77,10 @@ func main() {
 		logger.Close()
 		return
 	}
+	//export the spans to the collector the agent is configured with
+	if agentConfig, err := appconfig.Config(false); err == nil {
+		tracing.Configure(logger, agentConfig.Agent.TracingEndpoint)
+	}
 	//initialize PluginRegistry
 	runpluginutil.SSMPluginRegistry = plugin.RegisteredWorkerPlugins(ctx)
 
@@ -83,10 +91,13 @@ func main() {
 	if err = messaging.Messaging(ctx.Log(), ipc, pipeline, stopTimer); err != nil {
 		logger.Errorf("messaging worker encountered error: %v", err)
 		//If ipc messaging broke, there's nothing worker process can do, exit immediately
+		tracing.Shutdown()
 		logger.Close()
 		return
 	}
 	logger.Info("document worker closed")
+	//export the spans of the document before the worker exits
+	tracing.Shutdown()
 	//ensure logs are flushed
 	logger.Close()
 	//TODO figure out s3 aync problem
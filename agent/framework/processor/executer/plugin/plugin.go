@@ -24,16 +24,24 @@ import (
 	"github.com/aws/amazon-ssm-agent/agent/framework/runpluginutil"
 	"github.com/aws/amazon-ssm-agent/agent/plugins/configurecontainers"
 	"github.com/aws/amazon-ssm-agent/agent/plugins/configurepackage"
+	"github.com/aws/amazon-ssm-agent/agent/plugins/copyfile"
 	"github.com/aws/amazon-ssm-agent/agent/plugins/dockercontainer"
 	"github.com/aws/amazon-ssm-agent/agent/plugins/downloadcontent"
+	"github.com/aws/amazon-ssm-agent/agent/plugins/extractarchive"
 	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory"
 	"github.com/aws/amazon-ssm-agent/agent/plugins/lrpminvoker"
 	"github.com/aws/amazon-ssm-agent/agent/plugins/refreshassociation"
+	"github.com/aws/amazon-ssm-agent/agent/plugins/runchefrecipe"
 	"github.com/aws/amazon-ssm-agent/agent/plugins/rundocument"
+	"github.com/aws/amazon-ssm-agent/agent/plugins/runpythonscript"
+	"github.com/aws/amazon-ssm-agent/agent/plugins/runsaltstate"
 	"github.com/aws/amazon-ssm-agent/agent/plugins/runscript"
+	"github.com/aws/amazon-ssm-agent/agent/plugins/servicecontrol"
 	"github.com/aws/amazon-ssm-agent/agent/plugins/updatessmagent"
+	"github.com/aws/amazon-ssm-agent/agent/session/plugins/port"
 	"github.com/aws/amazon-ssm-agent/agent/session/plugins/sessionplugin"
 	"github.com/aws/amazon-ssm-agent/agent/session/plugins/shell"
+	"github.com/aws/amazon-ssm-agent/agent/session/plugins/ssh"
 )
 
 // allPlugins is the list of all known plugins.
@@ -56,6 +64,14 @@ var allPlugins = map[string]struct{}{
 	appconfig.PluginNameRefreshAssociation:     {},
 	appconfig.PluginDownloadContent:            {},
 	appconfig.PluginRunDocument:                {},
+	appconfig.PluginNameAwsRunChefRecipe:       {},
+	appconfig.PluginNameAwsRunSaltState:        {},
+	appconfig.PluginNameAwsCopyFile:            {},
+	appconfig.PluginNameAwsRunPythonScript:     {},
+	appconfig.PluginNameAwsExtractArchive:      {},
+	appconfig.PluginNameAwsServiceControl:      {},
+	appconfig.PluginNameAwsEditRegistry:        {},
+	appconfig.PluginNameAwsManageFilesystem:    {},
 }
 
 var once sync.Once
@@ -81,7 +97,7 @@ type RunPowerShellFactory struct {
 }
 
 func (f RunPowerShellFactory) Create(context context.T) (runpluginutil.T, error) {
-	return runscript.NewRunPowerShellPlugin()
+	return runscript.NewRunPowerShellPlugin(context)
 }
 
 type UpdateAgentFactory struct {
@@ -133,6 +149,48 @@ func (r RunDocumentFactory) Create(context context.T) (runpluginutil.T, error) {
 	return rundocument.NewPlugin()
 }
 
+type RunChefRecipeFactory struct {
+}
+
+func (r RunChefRecipeFactory) Create(context context.T) (runpluginutil.T, error) {
+	return runchefrecipe.NewPlugin()
+}
+
+type RunSaltStateFactory struct {
+}
+
+func (r RunSaltStateFactory) Create(context context.T) (runpluginutil.T, error) {
+	return runsaltstate.NewPlugin()
+}
+
+type CopyFileFactory struct {
+}
+
+func (r CopyFileFactory) Create(context context.T) (runpluginutil.T, error) {
+	return copyfile.NewPlugin()
+}
+
+type RunPythonScriptFactory struct {
+}
+
+func (r RunPythonScriptFactory) Create(context context.T) (runpluginutil.T, error) {
+	return runpythonscript.NewPlugin()
+}
+
+type ExtractArchiveFactory struct {
+}
+
+func (r ExtractArchiveFactory) Create(context context.T) (runpluginutil.T, error) {
+	return extractarchive.NewPlugin()
+}
+
+type ServiceControlFactory struct {
+}
+
+func (r ServiceControlFactory) Create(context context.T) (runpluginutil.T, error) {
+	return servicecontrol.NewPlugin()
+}
+
 type SessionPluginFactory struct {
 	newPluginFunc sessionplugin.NewPluginFunc
 }
@@ -194,6 +252,22 @@ func loadSessionPlugins() {
 	shellPluginName := appconfig.PluginNameStandardStream
 	sessionPlugins[shellPluginName] = SessionPluginFactory{shell.NewPlugin}
 
+	portPluginName := appconfig.PluginNamePort
+	sessionPlugins[portPluginName] = SessionPluginFactory{port.NewPlugin}
+
+	sshPluginName := appconfig.PluginNameSSH
+	sessionPlugins[sshPluginName] = SessionPluginFactory{ssh.NewPlugin}
+
+	interactiveCommandsPluginName := appconfig.PluginNameInteractiveCommands
+	sessionPlugins[interactiveCommandsPluginName] = SessionPluginFactory{shell.NewInteractiveCommandsPlugin}
+
+	// the session types registered by a session handler can't replace the core session plugins
+	for name, newPluginFunc := range sessionplugin.RegisteredSessionHandlers() {
+		if _, exists := sessionPlugins[name]; !exists {
+			sessionPlugins[name] = SessionPluginFactory{newPluginFunc}
+		}
+	}
+
 	registeredPlugins = &sessionPlugins
 }
 
@@ -236,5 +310,29 @@ func loadPlatformIndependentPlugins(context context.T) runpluginutil.PluginRegis
 	runDocumentPluginName := rundocument.Name()
 	workerPlugins[runDocumentPluginName] = RunDocumentFactory{}
 
+	//registering aws:runChefRecipe
+	runChefRecipePluginName := runchefrecipe.Name()
+	workerPlugins[runChefRecipePluginName] = RunChefRecipeFactory{}
+
+	//registering aws:runSaltState
+	runSaltStatePluginName := runsaltstate.Name()
+	workerPlugins[runSaltStatePluginName] = RunSaltStateFactory{}
+
+	//registering aws:copyFile
+	copyFilePluginName := copyfile.Name()
+	workerPlugins[copyFilePluginName] = CopyFileFactory{}
+
+	//registering aws:runPythonScript
+	runPythonScriptPluginName := runpythonscript.Name()
+	workerPlugins[runPythonScriptPluginName] = RunPythonScriptFactory{}
+
+	//registering aws:extractArchive
+	extractArchivePluginName := extractarchive.Name()
+	workerPlugins[extractArchivePluginName] = ExtractArchiveFactory{}
+
+	//registering aws:serviceControl
+	serviceControlPluginName := servicecontrol.Name()
+	workerPlugins[serviceControlPluginName] = ServiceControlFactory{}
+
 	return workerPlugins
 }
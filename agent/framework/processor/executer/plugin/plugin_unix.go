@@ -19,6 +19,7 @@ import (
 	"github.com/aws/amazon-ssm-agent/agent/appconfig"
 	"github.com/aws/amazon-ssm-agent/agent/context"
 	"github.com/aws/amazon-ssm-agent/agent/framework/runpluginutil"
+	"github.com/aws/amazon-ssm-agent/agent/plugins/managefilesystem"
 	"github.com/aws/amazon-ssm-agent/agent/plugins/runscript"
 )
 
@@ -29,10 +30,21 @@ func (f RunShellScriptFactory) Create(context context.T) (runpluginutil.T, error
 	return runscript.NewRunShellPlugin(context.Log())
 }
 
+type ManageFilesystemFactory struct {
+}
+
+func (f ManageFilesystemFactory) Create(context context.T) (runpluginutil.T, error) {
+	return managefilesystem.NewPlugin()
+}
+
 // loadPlatformDependentPlugins registers platform dependent plugins
 func loadPlatformDependentPlugins(context context.T) runpluginutil.PluginRegistry {
 	var workerPlugins = runpluginutil.PluginRegistry{}
 
 	workerPlugins[appconfig.PluginNameAwsRunShellScript] = RunShellScriptFactory{}
+
+	// registering aws:manageFilesystem plugin
+	manageFilesystemPluginName := managefilesystem.Name()
+	workerPlugins[manageFilesystemPluginName] = ManageFilesystemFactory{}
 	return workerPlugins
 }
@@ -23,6 +23,7 @@ import (
 	"github.com/aws/amazon-ssm-agent/agent/framework/runpluginutil"
 	"github.com/aws/amazon-ssm-agent/agent/plugins/application"
 	"github.com/aws/amazon-ssm-agent/agent/plugins/domainjoin"
+	"github.com/aws/amazon-ssm-agent/agent/plugins/editregistry"
 	"github.com/aws/amazon-ssm-agent/agent/plugins/psmodule"
 	"github.com/aws/amazon-ssm-agent/agent/plugins/updateec2config"
 )
@@ -55,6 +56,13 @@ func (f UpdateEc2ConfigFactory) Create(context context.T) (runpluginutil.T, erro
 	return updateec2config.NewPlugin(updateec2config.GetUpdatePluginConfig(context))
 }
 
+type EditRegistryFactory struct {
+}
+
+func (f EditRegistryFactory) Create(context context.T) (runpluginutil.T, error) {
+	return editregistry.NewPlugin()
+}
+
 // loadPlatformDependentPlugins registers platform dependent plugins
 func loadPlatformDependentPlugins(context context.T) runpluginutil.PluginRegistry {
 	var workerPlugins = runpluginutil.PluginRegistry{}
@@ -75,6 +83,10 @@ func loadPlatformDependentPlugins(context context.T) runpluginutil.PluginRegistr
 	updateEC2AgentPluginName := updateec2config.Name()
 	workerPlugins[updateEC2AgentPluginName] = UpdateEc2ConfigFactory{}
 
+	// registering aws:editRegistry plugin
+	editRegistryPluginName := editregistry.Name()
+	workerPlugins[editRegistryPluginName] = EditRegistryFactory{}
+
 	//// registering aws:configureDaemon
 	//configureDaemonPluginName := configuredaemon.Name()
 	//configureDaemonPlugin, err := configuredaemon.NewPlugin(pluginutil.DefaultPluginConfig())
@@ -0,0 +1,104 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package processor defines the document processing unit interface
+package processor
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+)
+
+// Assign method to global variables to allow unittest to override
+var stopPollInterval = time.Second
+
+// executionLimit cancels the document once it runs longer than the limit
+type executionLimit struct {
+	lock    sync.Mutex
+	expired bool
+	stopped bool
+	timer   *time.Timer
+	// done stops the routine passing cancel and shutdown of the job on
+	done chan struct{}
+}
+
+// withExecutionLimit returns a cancel flag which is canceled once the document runs longer than limit,
+// cancel and shutdown of the job are passed on. Canceling kills the plugin processes with their descendants.
+// The returned function stops the limit and returns true if the document exceeded it, a zero limit is never exceeded.
+// It must be called once the document completes, calling it again returns the same result.
+func withExecutionLimit(log log.T, cancelFlag task.CancelFlag, messageID string, limit time.Duration) (task.CancelFlag, func() bool) {
+	if limit <= 0 {
+		return cancelFlag, func() bool { return false }
+	}
+
+	limitedCancelFlag := task.NewChanneledCancelFlag()
+	executionLimit := &executionLimit{done: make(chan struct{})}
+	// the job cancel flag is polled rather than waited on, so the routine ends with the document
+	ticker := time.NewTicker(stopPollInterval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-executionLimit.done:
+				return
+			case <-ticker.C:
+				if cancelFlag.Canceled() || cancelFlag.ShutDown() {
+					limitedCancelFlag.Set(cancelFlag.State())
+					return
+				}
+			}
+		}
+	}()
+	executionLimit.timer = time.AfterFunc(limit, func() {
+		executionLimit.lock.Lock()
+		defer executionLimit.lock.Unlock()
+		if executionLimit.stopped {
+			return
+		}
+		executionLimit.expired = true
+		log.Infof("Document %v exceeded the execution limit of %v, canceling it", messageID, limit)
+		limitedCancelFlag.Set(task.Canceled)
+	})
+
+	return limitedCancelFlag, func() bool {
+		executionLimit.lock.Lock()
+		defer executionLimit.lock.Unlock()
+		if !executionLimit.stopped {
+			executionLimit.stopped = true
+			executionLimit.timer.Stop()
+			close(executionLimit.done)
+			// wake up the routines of the plugins still waiting on the cancel flag
+			if !limitedCancelFlag.Canceled() && !limitedCancelFlag.ShutDown() {
+				limitedCancelFlag.Set(task.Completed)
+			}
+		}
+		return executionLimit.expired
+	}
+}
+
+// markExecutionTimedOut reports the document as timed out, plugins which did not get to finish are reported timed out too
+func markExecutionTimedOut(res *contracts.DocumentResult, limitSeconds int) {
+	res.Status = contracts.ResultStatusTimedOut
+	res.ErrorCode = contracts.AssociationErrorCodeExecutionTimedOutError
+	for _, pluginRes := range res.PluginResults {
+		if pluginRes.Status == contracts.ResultStatusCancelled || pluginRes.Status == contracts.ResultStatusInProgress || pluginRes.Status == "" {
+			pluginRes.Status = contracts.ResultStatusTimedOut
+			pluginRes.Error = fmt.Sprintf("Document execution timed out after %v seconds", limitSeconds)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package processor defines the document processing unit interface
+package processor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithExecutionLimitDisabled(t *testing.T) {
+	cancelFlag := task.NewChanneledCancelFlag()
+
+	limitedCancelFlag, stop := withExecutionLimit(log.NewMockLog(), cancelFlag, "messageID", 0)
+
+	assert.Equal(t, cancelFlag, limitedCancelFlag)
+	assert.False(t, stop())
+}
+
+func TestWithExecutionLimitCancelsDocument(t *testing.T) {
+	cancelFlag := task.NewChanneledCancelFlag()
+
+	limitedCancelFlag, stop := withExecutionLimit(log.NewMockLog(), cancelFlag, "messageID", 10*time.Millisecond)
+
+	assert.Equal(t, task.Canceled, limitedCancelFlag.Wait())
+	assert.True(t, stop())
+	assert.False(t, cancelFlag.Canceled())
+}
+
+func TestWithExecutionLimitForwardsShutdown(t *testing.T) {
+	defer func() { stopPollInterval = time.Second }()
+	stopPollInterval = time.Millisecond
+	cancelFlag := task.NewChanneledCancelFlag()
+
+	limitedCancelFlag, stop := withExecutionLimit(log.NewMockLog(), cancelFlag, "messageID", time.Hour)
+	cancelFlag.Set(task.ShutDown)
+
+	assert.Equal(t, task.ShutDown, limitedCancelFlag.Wait())
+	assert.False(t, stop())
+}
+
+func TestWithExecutionLimitStoppedWithDocument(t *testing.T) {
+	defer func() { stopPollInterval = time.Second }()
+	stopPollInterval = time.Millisecond
+	cancelFlag := task.NewChanneledCancelFlag()
+
+	limitedCancelFlag, stop := withExecutionLimit(log.NewMockLog(), cancelFlag, "messageID", 10*time.Millisecond)
+
+	assert.False(t, stop())
+	assert.Equal(t, task.Completed, limitedCancelFlag.Wait())
+	// neither the limit nor a later cancel of the job reach the completed document
+	cancelFlag.Set(task.Canceled)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, task.Completed, limitedCancelFlag.State())
+	assert.False(t, stop())
+}
+
+func TestMarkExecutionTimedOut(t *testing.T) {
+	res := contracts.DocumentResult{
+		Status: contracts.ResultStatusCancelled,
+		PluginResults: map[string]*contracts.PluginResult{
+			"step1": {Status: contracts.ResultStatusSuccess},
+			"step2": {Status: contracts.ResultStatusCancelled},
+		},
+	}
+
+	markExecutionTimedOut(&res, 60)
+
+	assert.Equal(t, contracts.ResultStatusTimedOut, res.Status)
+	assert.Equal(t, contracts.AssociationErrorCodeExecutionTimedOutError, res.ErrorCode)
+	assert.Equal(t, contracts.ResultStatusSuccess, res.PluginResults["step1"].Status)
+	assert.Equal(t, contracts.ResultStatusTimedOut, res.PluginResults["step2"].Status)
+	assert.Equal(t, "Document execution timed out after 60 seconds", res.PluginResults["step2"].Error)
+}
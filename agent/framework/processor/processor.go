@@ -26,15 +26,18 @@ import (
 	"github.com/aws/amazon-ssm-agent/agent/appconfig"
 	"github.com/aws/amazon-ssm-agent/agent/context"
 	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/executionevents"
 	"github.com/aws/amazon-ssm-agent/agent/fileutil"
 	"github.com/aws/amazon-ssm-agent/agent/framework/docmanager"
 	"github.com/aws/amazon-ssm-agent/agent/framework/processor/executer"
 	"github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/outofproc"
 	"github.com/aws/amazon-ssm-agent/agent/longrunning/manager"
+	"github.com/aws/amazon-ssm-agent/agent/metrics"
 	"github.com/aws/amazon-ssm-agent/agent/platform"
 	"github.com/aws/amazon-ssm-agent/agent/rebooter"
 	"github.com/aws/amazon-ssm-agent/agent/task"
 	"github.com/aws/amazon-ssm-agent/agent/times"
+	"github.com/aws/amazon-ssm-agent/agent/tracing"
 )
 
 type ExecuterCreator func(ctx context.T) executer.Executer
@@ -45,6 +48,31 @@ const (
 	hardStopTimeout = time.Second * 4
 )
 
+// resumedAfterReboot are the documents of the reboot resume token, they requested the reboot the agent starts from
+var resumedAfterReboot = make(map[string]bool)
+var resumedAfterRebootLock sync.Mutex
+
+// ResumeAfterReboot registers the documents which requested a reboot before the agent started, they resume from
+// the plugin following the reboot without counting as a retry of an interrupted document
+func ResumeAfterReboot(documentIDs []string) {
+	resumedAfterRebootLock.Lock()
+	defer resumedAfterRebootLock.Unlock()
+	for _, documentID := range documentIDs {
+		resumedAfterReboot[documentID] = true
+	}
+}
+
+// takeResumedAfterReboot returns true once for each document which requested the reboot the agent starts from
+func takeResumedAfterReboot(documentID string) bool {
+	resumedAfterRebootLock.Lock()
+	defer resumedAfterRebootLock.Unlock()
+	if !resumedAfterReboot[documentID] {
+		return false
+	}
+	delete(resumedAfterReboot, documentID)
+	return true
+}
+
 type Processor interface {
 	//Start activate the Processor and pick up the left over document in the last run, it returns a channel to caller to gather DocumentResult
 	Start() (chan contracts.DocumentResult, error)
@@ -69,6 +97,7 @@ type EngineProcessor struct {
 	supportedDocTypes []contracts.DocumentType
 	resChan           chan contracts.DocumentResult
 	documentMgr       docmanager.DocumentMgr
+	queued            queuedDocuments
 }
 
 //TODO worker pool should be triggered in the Start() function
@@ -156,7 +185,9 @@ func (p *EngineProcessor) submit(docState *contracts.DocumentState) error {
 	} else {
 		jobID = docState.DocumentInformation.MessageID
 	}
-	return p.sendCommandPool.Submit(log, jobID, func(cancelFlag task.CancelFlag) {
+	dequeue := p.queued.enqueue(jobID)
+	err := p.sendCommandPool.SubmitWithPriority(log, jobID, func(cancelFlag task.CancelFlag) {
+		dequeue()
 		processCommand(
 			p.context,
 			p.executerCreator,
@@ -164,8 +195,11 @@ func (p *EngineProcessor) submit(docState *contracts.DocumentState) error {
 			p.resChan,
 			docState,
 			p.documentMgr)
-	})
-
+	}, docState.DocumentInformation.Priority)
+	if err != nil {
+		dequeue()
+	}
+	return err
 }
 
 func (p *EngineProcessor) Cancel(docState contracts.DocumentState) {
@@ -179,6 +213,8 @@ func (p *EngineProcessor) Cancel(docState contracts.DocumentState) {
 	}
 	//queue up the pending document
 	p.documentMgr.PersistDocumentState(log, docState.DocumentInformation.DocumentID, docState.DocumentInformation.InstanceID, appconfig.DefaultLocationOfPending, docState)
+	// a canceled document which is still queued never starts
+	p.queued.dequeue(docState.CancelInformation.CancelMessageID)
 	err := p.cancelCommandPool.Submit(log, jobID, func(cancelFlag task.CancelFlag) {
 		processCancelCommand(p.context, p.sendCommandPool, &docState, p.documentMgr)
 	})
@@ -281,16 +317,21 @@ func (p *EngineProcessor) processInProgressDocuments(instanceID string) {
 		//inspect document state
 		docState := p.documentMgr.GetDocumentState(log, f.Name(), instanceID, appconfig.DefaultLocationOfCurrent)
 
-		retryLimit := config.Mds.CommandRetryLimit
-		if docState.DocumentInformation.RunCount >= retryLimit {
-			p.documentMgr.MoveDocumentState(log, f.Name(), instanceID, appconfig.DefaultLocationOfCurrent, appconfig.DefaultLocationOfCorrupt)
-			continue
-		}
+		if p.isSupportedDocumentType(docState.DocumentType) && takeResumedAfterReboot(docState.DocumentInformation.DocumentID) {
+			// the document stopped for the reboot it requested, it wasn't interrupted
+			log.Infof("Resuming document %v after the reboot it requested", docState.DocumentInformation.DocumentID)
+		} else {
+			retryLimit := config.Mds.CommandRetryLimit
+			if docState.DocumentInformation.RunCount >= retryLimit {
+				p.documentMgr.MoveDocumentState(log, f.Name(), instanceID, appconfig.DefaultLocationOfCurrent, appconfig.DefaultLocationOfCorrupt)
+				continue
+			}
 
-		// increment the command run count
-		docState.DocumentInformation.RunCount++
+			// increment the command run count
+			docState.DocumentInformation.RunCount++
 
-		p.documentMgr.PersistDocumentState(log, docState.DocumentInformation.DocumentID, instanceID, appconfig.DefaultLocationOfCurrent, docState)
+			p.documentMgr.PersistDocumentState(log, docState.DocumentInformation.DocumentID, instanceID, appconfig.DefaultLocationOfCurrent, docState)
+		}
 
 		if p.isSupportedDocumentType(docState.DocumentType) {
 			log.Infof("Processing in-progress document %v", docState.DocumentInformation.DocumentID)
@@ -325,18 +366,44 @@ func processCommand(context context.T, executerCreator ExecuterCreator, cancelFl
 	instanceID := docState.DocumentInformation.InstanceID
 	messageID := docState.DocumentInformation.MessageID
 	e := executerCreator(context)
+	// a document resumed after a reboot continues the trace of its previous run
+	span := tracing.Start("document "+docState.DocumentInformation.DocumentName, tracing.KindInternal,
+		tracing.ParseTraceParent(docState.DocumentInformation.TraceParent), documentSpanAttributes(docState))
+	defer span.End()
+	docState.DocumentInformation.TraceParent = span.Context().TraceParent()
+	executionevents.ReportStarted(context, docState)
 	docStore := executer.NewDocumentFileStore(context, instanceID, documentID, appconfig.DefaultLocationOfCurrent, docState, docMgr)
-	statusChan := e.Run(
+	limitedCancelFlag, stopExecutionLimit := withExecutionLimit(
+		log,
 		cancelFlag,
+		messageID,
+		time.Duration(context.AppConfig().Agent.MaxDocumentExecutionSeconds)*time.Second)
+	// the limit is also stopped when the document is interrupted by a shutdown or a reboot
+	defer stopExecutionLimit()
+	statusChan := e.Run(
+		limitedCancelFlag,
 		&docStore,
 	)
 	// Listen for reboot
 	var final *contracts.DocumentResult
 	for res := range statusChan {
 		if res.LastPlugin == "" {
+			if stopExecutionLimit() {
+				markExecutionTimedOut(&res, context.AppConfig().Agent.MaxDocumentExecutionSeconds)
+			}
 			log.Infof("sending document: %v complete response", documentID)
+			metrics.IncrementWithTags(metrics.ChannelDocuments, string(res.Status), executionTags(docState, res.Status))
+			executionevents.ReportFinished(context, docState, res.Status)
+			span.SetAttribute("ssm.document.status", string(res.Status))
+			if res.Status == contracts.ResultStatusFailed || res.Status == contracts.ResultStatusTimedOut {
+				span.SetError(fmt.Sprintf("document %v %v", documentID, res.Status))
+			}
 		} else {
 			log.Infof("sending reply for plugin update: %v", res.LastPlugin)
+			if plugin, found := res.PluginResults[res.LastPlugin]; found && !plugin.EndDateTime.IsZero() {
+				metrics.ObserveLatencyWithTags(metrics.ChannelPlugins, plugin.PluginName, plugin.EndDateTime.Sub(plugin.StartDateTime),
+					executionTags(docState, plugin.Status))
+			}
 
 		}
 		handleCloudwatchPlugin(context, res.PluginResults, documentID)
@@ -351,7 +418,7 @@ func processCommand(context context.T, executerCreator ExecuterCreator, cancelFl
 		return
 	} else if final.Status == contracts.ResultStatusSuccessAndReboot {
 		log.Infof("document %v requested reboot, need to resume", messageID)
-		rebooter.RequestPendingReboot(context.Log())
+		rebooter.OrchestrateReboot(context.Log(), instanceID, documentID)
 		return
 	}
 
@@ -365,6 +432,29 @@ func processCommand(context context.T, executerCreator ExecuterCreator, cancelFl
 
 }
 
+// executionTags returns the tags of the metrics of the document execution
+func executionTags(docState *contracts.DocumentState, status contracts.ResultStatus) map[string]string {
+	tags := map[string]string{
+		"document_name": docState.DocumentInformation.DocumentName,
+		"status":        string(status),
+	}
+	if docState.IsAssociation() {
+		tags["association_id"] = docState.DocumentInformation.AssociationID
+	}
+	return tags
+}
+
+// documentSpanAttributes returns the attributes of the span of the document execution
+func documentSpanAttributes(docState *contracts.DocumentState) map[string]string {
+	return map[string]string{
+		"ssm.document.name":    docState.DocumentInformation.DocumentName,
+		"ssm.document.version": docState.DocumentInformation.DocumentVersion,
+		"ssm.message_id":       docState.DocumentInformation.MessageID,
+		"ssm.command_id":       docState.DocumentInformation.CommandID,
+		"ssm.association_id":   docState.DocumentInformation.AssociationID,
+	}
+}
+
 //TODO CancelCommand is currently treated as a special type of Command by the Processor, but in general Cancel operation should be seen as a probe to existing commands
 func processCancelCommand(context context.T, sendCommandPool task.Pool, docState *contracts.DocumentState, docMgr docmanager.DocumentMgr) {
 
@@ -38,7 +38,7 @@ func TestEngineProcessor_Submit(t *testing.T) {
 	creator := func(ctx context.T) executer.Executer {
 		return executerMock
 	}
-	sendCommandPoolMock.On("Submit", ctx.Log(), "messageID", mock.Anything).Return(nil)
+	sendCommandPoolMock.On("SubmitWithPriority", ctx.Log(), "messageID", mock.Anything, 5).Return(nil)
 	docMock := new(DocumentMgrMock)
 	processor := EngineProcessor{
 		executerCreator: creator,
@@ -48,6 +48,7 @@ func TestEngineProcessor_Submit(t *testing.T) {
 	}
 	docState := contracts.DocumentState{}
 	docState.DocumentInformation.MessageID = "messageID"
+	docState.DocumentInformation.Priority = 5
 	docMock.On("PersistDocumentState", mock.Anything, mock.Anything, mock.Anything, appconfig.DefaultLocationOfPending, docState)
 	processor.Submit(docState)
 	sendCommandPoolMock.AssertExpectations(t)
@@ -191,6 +192,15 @@ func TestProcessCancelCommand_Success(t *testing.T) {
 
 }
 
+func TestResumeAfterReboot(t *testing.T) {
+	ResumeAfterReboot([]string{"documentID"})
+
+	assert.False(t, takeResumedAfterReboot("otherDocumentID"))
+	assert.True(t, takeResumedAfterReboot("documentID"))
+	// the reboot resumes the document once, a later restart counts as a retry
+	assert.False(t, takeResumedAfterReboot("documentID"))
+}
+
 type DocumentMgrMock struct {
 	mock.Mock
 }
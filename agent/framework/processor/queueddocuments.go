@@ -0,0 +1,60 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package processor defines the document processing unit interface
+package processor
+
+import (
+	"sync"
+
+	"github.com/aws/amazon-ssm-agent/agent/metrics"
+)
+
+// queuedDocuments keeps the documents submitted to the command pool which haven't started yet,
+// so that the queued documents gauge also goes down when a queued document is canceled
+type queuedDocuments struct {
+	lock      sync.Mutex
+	dequeuers map[string]func()
+}
+
+// enqueue counts the queued job and returns the function to call once the job leaves the queue, it may be called many times
+func (q *queuedDocuments) enqueue(jobID string) (dequeue func()) {
+	var once sync.Once
+	dequeue = func() {
+		once.Do(func() {
+			q.lock.Lock()
+			delete(q.dequeuers, jobID)
+			q.lock.Unlock()
+			metrics.AddGauge(metrics.ChannelDocuments, metrics.GaugeQueued, -1)
+		})
+	}
+
+	q.lock.Lock()
+	if q.dequeuers == nil {
+		q.dequeuers = make(map[string]func())
+	}
+	q.dequeuers[jobID] = dequeue
+	q.lock.Unlock()
+	metrics.AddGauge(metrics.ChannelDocuments, metrics.GaugeQueued, 1)
+	return
+}
+
+// dequeue stops counting the job if it's still queued
+func (q *queuedDocuments) dequeue(jobID string) {
+	q.lock.Lock()
+	dequeue, found := q.dequeuers[jobID]
+	q.lock.Unlock()
+	if found {
+		dequeue()
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package processor defines the document processing unit interface
+package processor
+
+import (
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+func queuedGauge() int64 {
+	return metrics.GetSnapshot().Gauges["documents.queued"]
+}
+
+func TestQueuedDocuments(t *testing.T) {
+	metrics.Reset()
+	defer metrics.Reset()
+
+	var queued queuedDocuments
+	dequeueStarted := queued.enqueue("started")
+	queued.enqueue("canceled")
+	queued.enqueue("queued")
+	assert.Equal(t, int64(3), queuedGauge())
+
+	// a started job is counted once, even if it's canceled later
+	dequeueStarted()
+	dequeueStarted()
+	queued.dequeue("started")
+	assert.Equal(t, int64(2), queuedGauge())
+
+	queued.dequeue("canceled")
+	queued.dequeue("unknown")
+	assert.Equal(t, int64(1), queuedGauge())
+}
@@ -0,0 +1,243 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package resultsink publishes plugin results for centralized analytics, in addition to the reply sent to the service
+package resultsink
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/sdkutil"
+	"github.com/aws/amazon-ssm-agent/agent/times"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/firehose"
+	"github.com/aws/aws-sdk-go/service/firehose/firehoseiface"
+)
+
+// PutRecordBatch limits - https://docs.aws.amazon.com/firehose/latest/dev/limits.html
+const (
+	maxRecordsPerBatch = 500
+	maxBytesPerBatch   = 4 * 1024 * 1024
+	maxRecordBytes     = 1000 * 1024
+)
+
+const (
+	// recordQueueSize is the number of results waiting to be published before new results are dropped
+	recordQueueSize = 1000
+	// maxPutAttempts is the number of times the records the delivery stream rejected are sent
+	maxPutAttempts = 3
+)
+
+// Config is the destination of the results, an empty Sink disables publishing
+type Config struct {
+	Sink           string
+	DeliveryStream string
+}
+
+// Record is the result of a plugin as it is published, one JSON object per line
+type Record struct {
+	InstanceID         string                   `json:"instanceId"`
+	MessageID          string                   `json:"messageId,omitempty"`
+	AssociationID      string                   `json:"associationId,omitempty"`
+	DocumentName       string                   `json:"documentName"`
+	DocumentVersion    string                   `json:"documentVersion,omitempty"`
+	PluginID           string                   `json:"pluginId"`
+	PluginName         string                   `json:"pluginName"`
+	Status             string                   `json:"status"`
+	Code               int                      `json:"code"`
+	StartDateTime      string                   `json:"startDateTime"`
+	EndDateTime        string                   `json:"endDateTime"`
+	Attempts           int                      `json:"attempts,omitempty"`
+	Error              string                   `json:"error,omitempty"`
+	StandardOutput     string                   `json:"standardOutput,omitempty"`
+	StandardError      string                   `json:"standardError,omitempty"`
+	OutputS3BucketName string                   `json:"outputS3BucketName,omitempty"`
+	OutputS3KeyPrefix  string                   `json:"outputS3KeyPrefix,omitempty"`
+	ResourceUsage      *contracts.ResourceUsage `json:"resourceUsage,omitempty"`
+}
+
+// NewRecord creates the record of the result of the plugin that last completed in the document
+func NewRecord(instanceID string, res contracts.DocumentResult) (record Record, ok bool) {
+	pluginResult, ok := res.PluginResults[res.LastPlugin]
+	if !ok || pluginResult == nil {
+		return record, false
+	}
+	return Record{
+		InstanceID:         instanceID,
+		MessageID:          res.MessageID,
+		AssociationID:      res.AssociationID,
+		DocumentName:       res.DocumentName,
+		DocumentVersion:    res.DocumentVersion,
+		PluginID:           res.LastPlugin,
+		PluginName:         pluginResult.PluginName,
+		Status:             string(pluginResult.Status),
+		Code:               pluginResult.Code,
+		StartDateTime:      times.ToIso8601UTC(pluginResult.StartDateTime),
+		EndDateTime:        times.ToIso8601UTC(pluginResult.EndDateTime),
+		Attempts:           pluginResult.Attempts,
+		Error:              pluginResult.Error,
+		StandardOutput:     pluginResult.StandardOutput,
+		StandardError:      pluginResult.StandardError,
+		OutputS3BucketName: pluginResult.OutputS3BucketName,
+		OutputS3KeyPrefix:  pluginResult.OutputS3KeyPrefix,
+		ResourceUsage:      pluginResult.ResourceUsage,
+	}, true
+}
+
+// ConfigFromAppConfig returns the result sink configured for the agent
+func ConfigFromAppConfig(config appconfig.SsmagentConfig) Config {
+	return Config{
+		Sink:           config.Ssm.PluginResultSink,
+		DeliveryStream: config.Ssm.PluginResultFirehoseDeliveryStream,
+	}
+}
+
+type publication struct {
+	log            log.T
+	deliveryStream string
+	data           []byte
+}
+
+var queue chan publication
+var once sync.Once
+
+// Assign method to global variables to allow unittest to override
+var newFirehoseClient = func() firehoseiface.FirehoseAPI {
+	appConfig, _ := appconfig.Config(false)
+	sess := session.New(sdkutil.AwsConfig())
+	sess.Handlers.Build.PushBack(request.MakeAddToUserAgentHandler(appConfig.Agent.Name, appConfig.Agent.Version))
+	return firehose.New(sess)
+}
+
+// Publish queues the record for delivery to the configured sink, records are sent in the background
+// so that the reply to the service is never held back
+func Publish(log log.T, config Config, record Record) {
+	if config.Sink != appconfig.PluginResultSinkFirehose {
+		return
+	}
+	if config.DeliveryStream == "" {
+		log.Warnf("No delivery stream is configured for the %v result sink, plugin %v result is not published", config.Sink, record.PluginID)
+		return
+	}
+
+	data, err := encode(record)
+	if err != nil {
+		log.Warnf("Failed to publish plugin %v result, %v", record.PluginID, err)
+		return
+	}
+
+	once.Do(func() {
+		queue = make(chan publication, recordQueueSize)
+		go deliver(newFirehoseClient())
+	})
+
+	select {
+	case queue <- publication{log: log, deliveryStream: config.DeliveryStream, data: data}:
+	default:
+		log.Warnf("Plugin result queue is full, dropping plugin %v result of %v", record.PluginID, record.DocumentName)
+	}
+}
+
+// encode returns the record as a line of JSON, the output is dropped if the record is too large for the delivery stream
+func encode(record Record) ([]byte, error) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	if len(data)+1 > maxRecordBytes {
+		record.StandardOutput, record.StandardError = "", ""
+		if data, err = json.Marshal(record); err != nil {
+			return nil, err
+		}
+	}
+	return append(data, '\n'), nil
+}
+
+// deliver sends the queued records, the records that are queued together are sent in batches
+func deliver(client firehoseiface.FirehoseAPI) {
+	for first := range queue {
+		pending := []publication{first}
+	drain:
+		for len(pending) < maxRecordsPerBatch {
+			select {
+			case next := <-queue:
+				pending = append(pending, next)
+			default:
+				break drain
+			}
+		}
+
+		for len(pending) > 0 {
+			count := nextBatchSize(pending)
+			putRecords(client, pending[:count])
+			pending = pending[count:]
+		}
+	}
+}
+
+// nextBatchSize returns the number of publications at the start that go to the same delivery stream and fit in one batch
+func nextBatchSize(pending []publication) (count int) {
+	size := 0
+	for count < len(pending) && count < maxRecordsPerBatch && pending[count].deliveryStream == pending[0].deliveryStream {
+		size += len(pending[count].data)
+		if size > maxBytesPerBatch && count > 0 {
+			break
+		}
+		count++
+	}
+	return count
+}
+
+// putRecords sends the records to their delivery stream, the records the delivery stream rejects are sent again
+func putRecords(client firehoseiface.FirehoseAPI, batch []publication) {
+	log := batch[len(batch)-1].log
+	deliveryStream := batch[0].deliveryStream
+
+	var err error
+	for attempt := 1; attempt <= maxPutAttempts && len(batch) > 0; attempt++ {
+		records := make([]*firehose.Record, len(batch))
+		for i, p := range batch {
+			records[i] = &firehose.Record{Data: p.data}
+		}
+
+		var output *firehose.PutRecordBatchOutput
+		if output, err = client.PutRecordBatch(&firehose.PutRecordBatchInput{
+			DeliveryStreamName: aws.String(deliveryStream),
+			Records:            records,
+		}); err != nil {
+			continue
+		}
+		if aws.Int64Value(output.FailedPutCount) == 0 {
+			return
+		}
+
+		failed := []publication{}
+		for i, response := range output.RequestResponses {
+			if i < len(batch) && response != nil && response.ErrorCode != nil {
+				failed = append(failed, batch[i])
+				err = fmt.Errorf("%v: %v", aws.StringValue(response.ErrorCode), aws.StringValue(response.ErrorMessage))
+			}
+		}
+		batch = failed
+	}
+	if len(batch) > 0 {
+		log.Warnf("Failed to publish %v plugin results to delivery stream %v, %v", len(batch), deliveryStream, err)
+	}
+}
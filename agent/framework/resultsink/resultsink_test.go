@@ -0,0 +1,160 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package resultsink
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/firehose"
+	"github.com/aws/aws-sdk-go/service/firehose/firehoseiface"
+	"github.com/stretchr/testify/assert"
+)
+
+// firehoseStub rejects the records listed in rejected on the first attempt
+type firehoseStub struct {
+	firehoseiface.FirehoseAPI
+	rejected map[string]bool
+	err      error
+	calls    []*firehose.PutRecordBatchInput
+}
+
+func (f *firehoseStub) PutRecordBatch(input *firehose.PutRecordBatchInput) (*firehose.PutRecordBatchOutput, error) {
+	f.calls = append(f.calls, input)
+	if f.err != nil {
+		return nil, f.err
+	}
+	output := &firehose.PutRecordBatchOutput{FailedPutCount: aws.Int64(0)}
+	for _, record := range input.Records {
+		response := &firehose.PutRecordBatchResponseEntry{RecordId: aws.String("id")}
+		if f.rejected[string(record.Data)] {
+			delete(f.rejected, string(record.Data))
+			response = &firehose.PutRecordBatchResponseEntry{ErrorCode: aws.String("ServiceUnavailableException"), ErrorMessage: aws.String("Slow down.")}
+			*output.FailedPutCount++
+		}
+		output.RequestResponses = append(output.RequestResponses, response)
+	}
+	return output, nil
+}
+
+func TestNewRecord(t *testing.T) {
+	start := time.Date(2017, 6, 1, 10, 0, 0, 0, time.UTC)
+	res := contracts.DocumentResult{
+		DocumentName:    "AWS-RunShellScript",
+		DocumentVersion: "1",
+		MessageID:       "aws.ssm.commandId.instanceId",
+		LastPlugin:      "step1",
+		PluginResults: map[string]*contracts.PluginResult{
+			"step1": {
+				PluginName:     "aws:runShellScript",
+				Status:         contracts.ResultStatusFailed,
+				Code:           2,
+				StartDateTime:  start,
+				EndDateTime:    start.Add(time.Minute),
+				StandardOutput: "out",
+				StandardError:  "err",
+			},
+			"step2": {PluginName: "aws:runShellScript", Status: contracts.ResultStatusNotStarted},
+		},
+	}
+
+	record, ok := NewRecord("i-1234", res)
+
+	assert.True(t, ok)
+	assert.Equal(t, Record{
+		InstanceID:      "i-1234",
+		MessageID:       "aws.ssm.commandId.instanceId",
+		DocumentName:    "AWS-RunShellScript",
+		DocumentVersion: "1",
+		PluginID:        "step1",
+		PluginName:      "aws:runShellScript",
+		Status:          "Failed",
+		Code:            2,
+		StartDateTime:   "2017-06-01T10:00:00.000Z",
+		EndDateTime:     "2017-06-01T10:01:00.000Z",
+		StandardOutput:  "out",
+		StandardError:   "err",
+	}, record)
+
+	// the document completion has no plugin result
+	res.LastPlugin = ""
+	_, ok = NewRecord("i-1234", res)
+	assert.False(t, ok)
+}
+
+func TestEncode(t *testing.T) {
+	data, err := encode(Record{PluginID: "step1", StandardOutput: "out"})
+	assert.NoError(t, err)
+	assert.True(t, strings.HasSuffix(string(data), "}\n"))
+	var decoded map[string]interface{}
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, "out", decoded["standardOutput"])
+
+	// the output is left out of records too large for the delivery stream
+	data, err = encode(Record{PluginID: "step1", StandardOutput: strings.Repeat("x", maxRecordBytes)})
+	assert.NoError(t, err)
+	assert.True(t, len(data) < 1024)
+	assert.NotContains(t, string(data), "standardOutput")
+}
+
+func TestPublishDisabled(t *testing.T) {
+	Publish(log.NewMockLog(), Config{}, Record{PluginID: "step1"})
+	Publish(log.NewMockLog(), Config{Sink: "firehose"}, Record{PluginID: "step1"})
+	assert.Nil(t, queue)
+}
+
+func TestNextBatchSize(t *testing.T) {
+	large := make([]byte, maxRecordBytes)
+	pending := []publication{}
+	for i := 0; i < 5; i++ {
+		pending = append(pending, publication{deliveryStream: "stream", data: large})
+	}
+	assert.Equal(t, 4, nextBatchSize(pending))
+
+	pending = []publication{{deliveryStream: "stream"}, {deliveryStream: "stream"}, {deliveryStream: "other"}}
+	assert.Equal(t, 2, nextBatchSize(pending))
+
+	pending = make([]publication, maxRecordsPerBatch+1)
+	assert.Equal(t, maxRecordsPerBatch, nextBatchSize(pending))
+}
+
+func TestPutRecordsRetriesRejectedRecords(t *testing.T) {
+	client := &firehoseStub{rejected: map[string]bool{"second\n": true}}
+	batch := []publication{
+		{log: log.NewMockLog(), deliveryStream: "stream", data: []byte("first\n")},
+		{log: log.NewMockLog(), deliveryStream: "stream", data: []byte("second\n")},
+	}
+
+	putRecords(client, batch)
+
+	assert.Len(t, client.calls, 2)
+	assert.Equal(t, "stream", aws.StringValue(client.calls[0].DeliveryStreamName))
+	assert.Len(t, client.calls[0].Records, 2)
+	assert.Len(t, client.calls[1].Records, 1)
+	assert.Equal(t, "second\n", string(client.calls[1].Records[0].Data))
+}
+
+func TestPutRecordsGivesUp(t *testing.T) {
+	client := &firehoseStub{err: errors.New("ResourceNotFoundException")}
+
+	putRecords(client, []publication{{log: log.NewMockLog(), deliveryStream: "stream", data: []byte("first\n")}})
+
+	assert.Len(t, client.calls, maxPutAttempts)
+}
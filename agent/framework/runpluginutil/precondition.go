@@ -0,0 +1,205 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package runpluginutil
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/platform"
+	"github.com/aws/amazon-ssm-agent/agent/versionutil"
+)
+
+// precondition operators
+const (
+	stringEquals               = "StringEquals"
+	stringNotEquals            = "StringNotEquals"
+	versionGreaterThanOrEquals = "VersionGreaterThanOrEquals"
+	versionLessThan            = "VersionLessThan"
+)
+
+// precondition variables, any other operand is compared as is
+const (
+	platformTypeVariable    = "platformType"
+	platformNameVariable    = "platformName"
+	platformVersionVariable = "platformVersion"
+	tagVariablePrefix       = "tag:"
+)
+
+// Assign method to global variables to allow unittest to override
+var (
+	getPlatformType    = platform.PlatformType
+	getPlatformName    = platform.PlatformName
+	getPlatformVersion = platform.PlatformVersion
+	getInstanceTags    = platform.InstanceTags
+)
+
+// unresolvedParameter matches operands referring to a parameter the document doesn't define
+var unresolvedParameter = regexp.MustCompile(`{{.*}}`)
+
+// preconditionEnvironment looks up the values of the instance that precondition variables refer to.
+// Instance tags are fetched the first time a precondition refers to them and reused afterwards.
+type preconditionEnvironment struct {
+	log         log.T
+	tags        map[string]string
+	tagsErr     error
+	tagsFetched bool
+}
+
+func newPreconditionEnvironment(log log.T) *preconditionEnvironment {
+	return &preconditionEnvironment{log: log}
+}
+
+// value returns the value of the instance the variable refers to.
+func (env *preconditionEnvironment) value(variable string) (string, error) {
+	switch variable {
+	case platformTypeVariable:
+		return getPlatformType(env.log)
+	case platformNameVariable:
+		return getPlatformName(env.log)
+	case platformVersionVariable:
+		return getPlatformVersion(env.log)
+	}
+
+	if !env.tagsFetched {
+		env.tags, env.tagsErr = getInstanceTags()
+		env.tagsFetched = true
+	}
+	if env.tagsErr != nil {
+		return "", env.tagsErr
+	}
+	// a tag the instance doesn't have is compared as an empty value
+	return env.tags[strings.TrimPrefix(variable, tagVariablePrefix)], nil
+}
+
+// preconditionResult is the outcome of evaluating the preconditions of a step
+type preconditionResult struct {
+	// isPlatformAllowed is false if a precondition on the platform type of the instance is not met
+	isPlatformAllowed bool
+	// unmet lists the other preconditions that are not met
+	unmet []string
+	// unrecognized lists the preconditions this version of the agent doesn't support
+	unrecognized []string
+	// failed lists the preconditions whose variables couldn't be looked up
+	failed []string
+}
+
+// Evaluate preconditions and return which of them are not met, unrecognized or failed (if any)
+func evaluatePreconditions(env *preconditionEnvironment, preconditions map[string][]string) (result preconditionResult) {
+	result.isPlatformAllowed = true
+
+	// evaluate in a stable order so the messages listing preconditions don't change between runs
+	operators := make([]string, 0, len(preconditions))
+	for operator := range preconditions {
+		operators = append(operators, operator)
+	}
+	sort.Strings(operators)
+
+	for _, operator := range operators {
+		operands := preconditions[operator]
+		precondition := fmt.Sprintf("\"%s\": %v", operator, operands)
+		if !isRecognizedPrecondition(operator, operands) {
+			// mark for unrecognizedPrecondition (which is a form of failure)
+			result.unrecognized = append(result.unrecognized, precondition)
+			continue
+		}
+
+		values, err := resolveOperands(env, operands)
+		if err != nil {
+			env.log.Errorf("Failed to evaluate precondition %s: %v", precondition, err)
+			result.failed = append(result.failed, fmt.Sprintf("%s (%v)", precondition, err))
+			continue
+		}
+		env.log.Debugf("Precondition %s evaluated with values %v", precondition, values)
+
+		if isPreconditionMet(operator, operands, values) {
+			continue
+		}
+		if operands[0] == platformTypeVariable || operands[1] == platformTypeVariable {
+			result.isPlatformAllowed = false
+		} else {
+			result.unmet = append(result.unmet, precondition)
+		}
+	}
+	return
+}
+
+// isRecognizedPrecondition checks the operator is supported and it compares two operands that aren't the same variable.
+func isRecognizedPrecondition(operator string, operands []string) bool {
+	switch operator {
+	case stringEquals, stringNotEquals, versionGreaterThanOrEquals, versionLessThan:
+	default:
+		return false
+	}
+	if len(operands) != 2 || (operands[0] == operands[1] && isVariable(operands[0])) {
+		return false
+	}
+	for _, operand := range operands {
+		if operand == tagVariablePrefix || unresolvedParameter.MatchString(operand) {
+			return false
+		}
+	}
+	return true
+}
+
+// resolveOperands replaces the variables among the operands with the values of the instance.
+// Other operands, such as values of the document parameters, are used as is.
+func resolveOperands(env *preconditionEnvironment, operands []string) (values []string, err error) {
+	values = make([]string, len(operands))
+	for i, operand := range operands {
+		values[i] = operand
+		if isVariable(operand) {
+			if values[i], err = env.value(operand); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return values, nil
+}
+
+// isPreconditionMet compares the values of the operands.
+// Variables and values can be in any order for string comparisons, i.e. both "StringEquals": ["platformType", "Windows"]
+// and "StringEquals": ["Windows", "platformType"] are valid, version comparisons read from left to right.
+func isPreconditionMet(operator string, operands []string, values []string) bool {
+	switch operator {
+	case stringEquals:
+		return equalValues(operands, values)
+	case stringNotEquals:
+		return !equalValues(operands, values)
+	case versionGreaterThanOrEquals:
+		return versionutil.Compare(values[0], values[1], false) >= 0
+	case versionLessThan:
+		return versionutil.Compare(values[0], values[1], false) < 0
+	}
+	return false
+}
+
+// equalValues compares the values, values of the platform are case insensitive
+func equalValues(operands []string, values []string) bool {
+	if isPlatformVariable(operands[0]) || isPlatformVariable(operands[1]) {
+		return strings.EqualFold(values[0], values[1])
+	}
+	return values[0] == values[1]
+}
+
+func isPlatformVariable(operand string) bool {
+	return operand == platformTypeVariable || operand == platformNameVariable || operand == platformVersionVariable
+}
+
+func isVariable(operand string) bool {
+	return isPlatformVariable(operand) || (strings.HasPrefix(operand, tagVariablePrefix) && operand != tagVariablePrefix)
+}
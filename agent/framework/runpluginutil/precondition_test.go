@@ -0,0 +1,135 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package runpluginutil
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubInstance makes the preconditions see a windows instance with the given tags and counts the tag lookups
+func stubInstance(tags map[string]string, tagsErr error) (tagLookups *int, restore func()) {
+	tagLookups = new(int)
+	getPlatformType = func(log log.T) (string, error) { return "windows", nil }
+	getPlatformName = func(log log.T) (string, error) { return "Microsoft Windows Server 2016 Datacenter", nil }
+	getPlatformVersion = func(log log.T) (string, error) { return "10.0.14393", nil }
+	getInstanceTags = func() (map[string]string, error) {
+		*tagLookups++
+		return tags, tagsErr
+	}
+	return tagLookups, func() {
+		getPlatformType = platformTypeOrig
+		getPlatformName = platformNameOrig
+		getPlatformVersion = platformVersionOrig
+		getInstanceTags = instanceTagsOrig
+	}
+}
+
+var (
+	platformTypeOrig    = getPlatformType
+	platformNameOrig    = getPlatformName
+	platformVersionOrig = getPlatformVersion
+	instanceTagsOrig    = getInstanceTags
+)
+
+func TestEvaluatePreconditionsMet(t *testing.T) {
+	tagLookups, restore := stubInstance(map[string]string{"Environment": "prod"}, nil)
+	defer restore()
+	env := newPreconditionEnvironment(log.NewMockLog())
+
+	for _, preconditions := range []map[string][]string{
+		{"StringEquals": {"platformType", "Windows"}},
+		{"StringEquals": {"Windows", "platformType"}},
+		{"StringNotEquals": {"platformType", "Linux"}},
+		{"StringEquals": {"tag:Environment", "prod"}, "StringNotEquals": {"tag:Role", "bastion"}},
+		{"VersionGreaterThanOrEquals": {"platformVersion", "10.0"}, "VersionLessThan": {"platformVersion", "10.1"}},
+		// values of parameters are replaced before the document runs
+		{"StringEquals": {"install", "install"}},
+	} {
+		result := evaluatePreconditions(env, preconditions)
+		assert.Equal(t, preconditionResult{isPlatformAllowed: true}, result, "%v", preconditions)
+	}
+	assert.Equal(t, 1, *tagLookups)
+}
+
+func TestEvaluatePreconditionsNotMet(t *testing.T) {
+	_, restore := stubInstance(map[string]string{"Environment": "test"}, nil)
+	defer restore()
+	env := newPreconditionEnvironment(log.NewMockLog())
+
+	result := evaluatePreconditions(env, map[string][]string{"StringEquals": {"platformType", "Linux"}})
+	assert.False(t, result.isPlatformAllowed)
+	assert.Empty(t, result.unmet)
+
+	result = evaluatePreconditions(env, map[string][]string{
+		"StringEquals":               {"tag:Environment", "prod"},
+		"VersionGreaterThanOrEquals": {"platformVersion", "10.0.17763"},
+		"StringNotEquals":            {"uninstall", "uninstall"},
+	})
+	assert.True(t, result.isPlatformAllowed)
+	assert.Equal(t, []string{
+		`"StringEquals": [tag:Environment prod]`,
+		`"StringNotEquals": [uninstall uninstall]`,
+		`"VersionGreaterThanOrEquals": [platformVersion 10.0.17763]`,
+	}, result.unmet)
+}
+
+func TestEvaluatePreconditionsUnrecognizedAndFailed(t *testing.T) {
+	tagLookups, restore := stubInstance(nil, errors.New("tags are not available"))
+	defer restore()
+	env := newPreconditionEnvironment(log.NewMockLog())
+
+	result := evaluatePreconditions(env, map[string][]string{
+		"StringLike":      {"platformType", "Win*"},
+		"StringEquals":    {"tag:", "prod"},
+		"VersionLessThan": {"{{ minimumVersion }}", "platformVersion"},
+	})
+	assert.Len(t, result.unrecognized, 3)
+
+	result = evaluatePreconditions(env, map[string][]string{"StringEquals": {"tag:Environment", "prod"}})
+	assert.Equal(t, []string{`"StringEquals": [tag:Environment prod] (tags are not available)`}, result.failed)
+	result = evaluatePreconditions(env, map[string][]string{"StringNotEquals": {"tag:Environment", "prod"}})
+	assert.Len(t, result.failed, 1)
+	assert.Equal(t, 1, *tagLookups)
+}
+
+func TestGetStepExecutionOperationWithPreconditions(t *testing.T) {
+	_, restore := stubInstance(map[string]string{"Environment": "test"}, nil)
+	defer restore()
+	logger := log.NewMockLog()
+	env := newPreconditionEnvironment(logger)
+
+	operation, _ := getStepExecutionOperation(logger, testPlugin1, "step", true, true, true, true,
+		map[string][]string{"StringEquals": {"platformType", "Windows"}, "StringNotEquals": {"tag:Environment", "prod"}}, env)
+	assert.Equal(t, executeStep, operation)
+
+	operation, message := getStepExecutionOperation(logger, testPlugin1, "step", true, true, true, true,
+		map[string][]string{"StringEquals": {"tag:Environment", "prod"}}, env)
+	assert.Equal(t, skipStep, operation)
+	assert.Equal(t, `Step execution skipped due to unmet precondition(s): '"StringEquals": [tag:Environment prod]'. Step name: step`, message)
+
+	operation, message = getStepExecutionOperation(logger, testPlugin1, "step", true, true, true, true,
+		map[string][]string{"StringEquals": {"platformType", "Linux"}, "StringNotEquals": {"tag:Environment", "test"}}, env)
+	assert.Equal(t, skipStep, operation)
+	assert.Equal(t, "Step execution skipped due to incompatible platform. Step name: step", message)
+
+	getInstanceTags = func() (map[string]string, error) { return nil, errors.New("tags are not available") }
+	operation, message = getStepExecutionOperation(logger, testPlugin1, "step", true, true, true, true,
+		map[string][]string{"StringEquals": {"tag:Environment", "prod"}}, newPreconditionEnvironment(logger))
+	assert.Equal(t, failStep, operation)
+	assert.Contains(t, message, "Failed to evaluate precondition(s)")
+}
@@ -16,6 +16,7 @@ package runpluginutil
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -26,9 +27,11 @@ import (
 	"github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler"
 	"github.com/aws/amazon-ssm-agent/agent/jsonutil"
 	"github.com/aws/amazon-ssm-agent/agent/log"
-	"github.com/aws/amazon-ssm-agent/agent/platform"
+	"github.com/aws/amazon-ssm-agent/agent/parameterstore"
 	"github.com/aws/amazon-ssm-agent/agent/plugins/pluginutil"
+	"github.com/aws/amazon-ssm-agent/agent/session/plugins/sessionplugin"
 	"github.com/aws/amazon-ssm-agent/agent/task"
+	"github.com/aws/amazon-ssm-agent/agent/tracing"
 )
 
 const (
@@ -71,15 +74,35 @@ var allPlugins = map[string]struct{}{
 	appconfig.PluginNameRefreshAssociation:     {},
 	appconfig.PluginDownloadContent:            {},
 	appconfig.PluginRunDocument:                {},
+	appconfig.PluginNameAwsRunChefRecipe:       {},
+	appconfig.PluginNameAwsRunSaltState:        {},
+	appconfig.PluginNameAwsCopyFile:            {},
+	appconfig.PluginNameAwsRunPythonScript:     {},
+	appconfig.PluginNameAwsExtractArchive:      {},
+	appconfig.PluginNameAwsServiceControl:      {},
+	appconfig.PluginNameAwsEditRegistry:        {},
+	appconfig.PluginNameAwsManageFilesystem:    {},
 }
 
 // allSessionPlugins is the list of all known session plugins.
 var allSessionPlugins = map[string]struct{}{
-	appconfig.PluginNameStandardStream: {},
+	appconfig.PluginNameStandardStream:      {},
+	appconfig.PluginNamePort:                {},
+	appconfig.PluginNameSSH:                 {},
+	appconfig.PluginNameInteractiveCommands: {},
+}
+
+// isSessionPlugin returns true for the core session plugins and the session types registered by a session handler
+func isSessionPlugin(pluginName string) bool {
+	if _, known := allSessionPlugins[pluginName]; known {
+		return true
+	}
+	return sessionplugin.IsRegisteredSessionHandler(pluginName)
 }
 
 // Assign method to global variables to allow unittest to override
 var isSupportedPlugin = IsPluginSupportedForCurrentPlatform
+var resolveSecrets = parameterstore.ResolveSecrets
 
 // TODO remove executionID and creation date
 // RunPlugins executes a set of plugins. The plugin configurations are given in a map with pluginId as key.
@@ -99,13 +122,48 @@ func RunPlugins(
 	//Contains the logStreamPrefix without the pluginID
 	logStreamPrefix := ioConfig.CloudWatchConfig.LogStreamPrefix
 
-	for _, pluginState := range plugins {
+	// once a step timed out with the fail policy or a step was rolled back, the remaining steps are skipped with this message
+	var skipRemainingMessage string
+
+	// instance values the step preconditions refer to, shared by the steps of the document
+	environment := newPreconditionEnvironment(context.Log())
+
+	// steps that only run when a step they handle fails, each of them runs at most once
+	handlerSteps := failureHandlerSteps(plugins)
+	ranHandlers := make(map[string]bool)
+	// handler steps reached before any step they handle failed, they are reported skipped once all the steps ran
+	var idleHandlers []string
+
+	pending := make([]pendingStep, len(plugins))
+	for i, pluginState := range plugins {
+		pending[i] = pendingStep{state: pluginState}
+	}
+
+	for len(pending) > 0 {
+		step := pending[0]
+		pending = pending[1:]
+		pluginState := step.state
 		pluginID := pluginState.Id     // the identifier of the plugin
 		pluginName := pluginState.Name // the name of the plugin
+		if ranHandlers[pluginID] && step.failedStep == "" {
+			// the result of the handler was sent when it ran
+			continue
+		}
 		pluginOutput := pluginState.Result
 		pluginOutput.PluginID = pluginID
 		pluginOutput.PluginName = pluginName
 		pluginOutputs[pluginID] = &pluginOutput
+
+		if skipRemainingMessage != "" && step.failedStep == "" {
+			sendSkipped(context.Log(), &pluginOutput, skipRemainingMessage, resChan)
+			continue
+		}
+		if handlerSteps[pluginID] && step.failedStep == "" &&
+			(pluginOutput.Status == "" || pluginOutput.Status == contracts.ResultStatusNotStarted) {
+			// a later step may still fail and run the handler, which then sends its only result
+			idleHandlers = append(idleHandlers, pluginID)
+			continue
+		}
 		switch pluginOutput.Status {
 		//TODO properly initialize the plugin status
 		case "":
@@ -156,6 +214,7 @@ func RunPlugins(
 			pluginHandlerFound bool
 			isKnown            bool
 			isSupported        bool
+			timedOut           bool
 		)
 
 		pluginFactory, pluginHandlerFound = registry[pluginName]
@@ -168,18 +227,38 @@ func RunPlugins(
 			isSupported,
 			pluginHandlerFound,
 			configuration.IsPreconditionEnabled,
-			configuration.Preconditions)
+			configuration.Preconditions,
+			environment)
 
 		switch operation {
 		case executeStep:
 			context.Log().Infof("Running plugin %s", pluginName)
-			r = runPlugin(context, pluginFactory, pluginName, configuration, cancelFlag, ioConfig)
+			// the API calls of the plugin are traced as children of its span
+			span := tracing.StartChild("plugin "+pluginName, tracing.KindInternal, map[string]string{
+				"ssm.plugin.name": pluginName,
+				"ssm.plugin.id":   pluginID,
+			})
+			restoreCurrentSpan := tracing.SetCurrent(span.Context())
+			r, timedOut = runPluginWithRetries(context, pluginFactory, pluginName, configuration, cancelFlag, ioConfig)
+			restoreCurrentSpan()
+			pluginOutputs[pluginID].Attempts = r.Attempts
+			pluginOutputs[pluginID].ResourceUsage = r.ResourceUsage
 			pluginOutputs[pluginID].Code = r.Code
 			pluginOutputs[pluginID].Status = r.Status
 			pluginOutputs[pluginID].Error = r.Error
 			pluginOutputs[pluginID].Output = r.Output
 			pluginOutputs[pluginID].StandardOutput = r.StandardOutput
 			pluginOutputs[pluginID].StandardError = r.StandardError
+			if timedOut {
+				err := fmt.Errorf("Step %v timed out after %v seconds", pluginID, configuration.TimeoutSeconds)
+				pluginOutputs[pluginID].Status = contracts.ResultStatusTimedOut
+				if configuration.OnTimeout == contracts.OnTimeoutFail {
+					pluginOutputs[pluginID].Status = contracts.ResultStatusFailed
+				}
+				pluginOutputs[pluginID].Error = err.Error()
+				context.Log().Error(err)
+			}
+			endPluginSpan(span, pluginOutputs[pluginID])
 
 		case skipStep:
 			context.Log().Info(logMessage)
@@ -205,8 +284,8 @@ func RunPlugins(
 		// truncate the result and send it back to buffer channel.
 		result := *pluginOutputs[pluginID]
 		pluginConfig := iohandler.DefaultOutputConfig()
-		result.StandardOutput = pluginutil.StringPrefix(result.StandardOutput, pluginConfig.MaxStdoutLength, pluginConfig.OutputTruncatedSuffix)
-		result.StandardError = pluginutil.StringPrefix(result.StandardError, pluginConfig.MaxStdoutLength, pluginConfig.OutputTruncatedSuffix)
+		result.StandardOutput = pluginutil.TruncateString(result.StandardOutput, pluginConfig.MaxStdoutLength, pluginConfig.OutputTruncatedSuffix, pluginConfig.TruncationStrategy)
+		result.StandardError = pluginutil.TruncateString(result.StandardError, pluginConfig.MaxStderrLength, pluginConfig.OutputTruncatedSuffix, pluginConfig.TruncationStrategy)
 		// send to buffer channel, guaranteed to not block since buffer size is plugin number
 		resChan <- result
 
@@ -215,11 +294,201 @@ func RunPlugins(
 			// do not execute the the next plugin
 			break
 		}
+		if timedOut && configuration.OnTimeout == contracts.OnTimeoutFail {
+			skipRemainingMessage = fmt.Sprintf("Step execution skipped because step %v timed out", pluginID)
+		}
+
+		status := pluginOutputs[pluginID].Status
+		if status != contracts.ResultStatusFailed && status != contracts.ResultStatusTimedOut {
+			continue
+		}
+		prefix, handlerID := contracts.ParseOnFailure(configuration.OnFailure)
+		if prefix != contracts.OnFailureRunStepPrefix && prefix != contracts.OnFailureRollbackStepPrefix {
+			continue
+		}
+		if handlerState, found := findStep(plugins, handlerID); !found || ranHandlers[handlerID] {
+			context.Log().Infof("Step %v failed, its onFailure step %v is not found or already ran", pluginID, handlerID)
+		} else {
+			context.Log().Infof("Step %v failed, running its onFailure step %v", pluginID, handlerID)
+			ranHandlers[handlerID] = true
+			pending = append([]pendingStep{{state: handlerState, failedStep: pluginID}}, pending...)
+		}
+		if prefix == contracts.OnFailureRollbackStepPrefix {
+			skipRemainingMessage = fmt.Sprintf("Step execution skipped because step %v failed and was rolled back", pluginID)
+		}
 	}
 
+	for _, handlerID := range idleHandlers {
+		if !ranHandlers[handlerID] {
+			sendSkipped(context.Log(), pluginOutputs[handlerID], "Step execution skipped because no step it handles failed", resChan)
+		}
+	}
 	return
 }
 
+// pendingStep is a step waiting to be executed, failedStep is set when the step runs as the onFailure handler of that step
+type pendingStep struct {
+	state      contracts.PluginState
+	failedStep string
+}
+
+// failureHandlerSteps returns the steps that are the onFailure handler of another step.
+func failureHandlerSteps(plugins []contracts.PluginState) map[string]bool {
+	handlers := make(map[string]bool)
+	for _, pluginState := range plugins {
+		switch prefix, handlerID := contracts.ParseOnFailure(pluginState.Configuration.OnFailure); prefix {
+		case contracts.OnFailureRunStepPrefix, contracts.OnFailureRollbackStepPrefix:
+			handlers[handlerID] = true
+		}
+	}
+	return handlers
+}
+
+func findStep(plugins []contracts.PluginState, pluginID string) (contracts.PluginState, bool) {
+	for _, pluginState := range plugins {
+		if pluginState.Id == pluginID {
+			return pluginState, true
+		}
+	}
+	return contracts.PluginState{}, false
+}
+
+// sendSkipped marks the step as skipped and sends its result.
+func sendSkipped(log log.T, pluginOutput *contracts.PluginResult, message string, resChan chan contracts.PluginResult) {
+	log.Info(message)
+	pluginOutput.Status = contracts.ResultStatusSkipped
+	pluginOutput.Code = 0
+	pluginOutput.Output = message
+	pluginOutput.StartDateTime = time.Now()
+	pluginOutput.EndDateTime = pluginOutput.StartDateTime
+	resChan <- *pluginOutput
+}
+
+// endPluginSpan ends the span of the plugin with the status of its result
+func endPluginSpan(span *tracing.Span, result *contracts.PluginResult) {
+	span.SetAttribute("ssm.plugin.status", string(result.Status))
+	span.SetAttribute("ssm.plugin.attempts", strconv.Itoa(result.Attempts))
+	if result.Status == contracts.ResultStatusFailed || result.Status == contracts.ResultStatusTimedOut {
+		span.SetError(result.Error)
+	}
+	span.End()
+}
+
+// runPluginWithRetries runs the plugin again after an attempt that failed or timed out, until the retries of the step are used up.
+// Each attempt has the full timeout of the step, retries stop once the document is canceled or shut down.
+func runPluginWithRetries(
+	context context.T,
+	factory PluginFactory,
+	pluginName string,
+	config contracts.Configuration,
+	cancelFlag task.CancelFlag,
+	ioConfig contracts.IOConfiguration) (res contracts.PluginResult, timedOut bool) {
+	// stopped is closed once the document is canceled or shut down, it is watched from the first retry on
+	var stopped chan struct{}
+	for attempt := 1; ; attempt++ {
+		res, timedOut = runPluginWithTimeout(context, factory, pluginName, config, cancelFlag, ioConfig)
+		if config.Retries <= 0 {
+			return
+		}
+		res.Attempts = attempt
+		if (res.Status != contracts.ResultStatusFailed && !timedOut) || attempt > config.Retries || isStopped(cancelFlag) {
+			return
+		}
+
+		context.Log().Infof("Step %v failed on attempt %v of %v, retrying in %v seconds", config.PluginID, attempt, config.Retries+1, config.RetryIntervalSeconds)
+		if stopped == nil {
+			stopped = watchStop(cancelFlag)
+		}
+		if !waitForRetry(cancelFlag, stopped, time.Duration(config.RetryIntervalSeconds)*time.Second) {
+			return
+		}
+	}
+}
+
+// Assign method to global variables to allow unittest to override
+var retryAfter = time.After
+
+// isStopped returns true if the document is canceled or shut down
+func isStopped(cancelFlag task.CancelFlag) bool {
+	return cancelFlag.Canceled() || cancelFlag.ShutDown()
+}
+
+// watchStop returns a channel which is closed once the document is canceled or shut down
+func watchStop(cancelFlag task.CancelFlag) chan struct{} {
+	stopped := make(chan struct{})
+	go func() {
+		if state := cancelFlag.Wait(); state != task.Completed {
+			close(stopped)
+		}
+	}()
+	return stopped
+}
+
+// waitForRetry waits for the retry interval, it returns false if the document is canceled or shut down in the meantime.
+func waitForRetry(cancelFlag task.CancelFlag, stopped chan struct{}, interval time.Duration) bool {
+	if interval > 0 {
+		select {
+		case <-retryAfter(interval):
+		case <-stopped:
+			return false
+		}
+	}
+	return !isStopped(cancelFlag)
+}
+
+// Assign method to global variables to allow unittest to override
+var stopPollInterval = time.Second
+
+// runPluginWithTimeout runs the plugin with a cancel flag of its own that is canceled once the step timeout elapses,
+// so only the offending plugin is stopped. Cancel and shutdown of the document are passed on to the plugin.
+// It always waits for the plugin to return, timedOut is only true if the plugin was canceled by the step timeout.
+func runPluginWithTimeout(
+	context context.T,
+	factory PluginFactory,
+	pluginName string,
+	config contracts.Configuration,
+	cancelFlag task.CancelFlag,
+	ioConfig contracts.IOConfiguration) (res contracts.PluginResult, timedOut bool) {
+	if config.TimeoutSeconds <= 0 {
+		return runPlugin(context, factory, pluginName, config, cancelFlag, ioConfig), false
+	}
+
+	pluginCancelFlag := task.NewChanneledCancelFlag()
+	done := make(chan contracts.PluginResult, 1)
+	go func() {
+		done <- runPlugin(context, factory, pluginName, config, pluginCancelFlag, ioConfig)
+	}()
+
+	timer := time.NewTimer(time.Duration(config.TimeoutSeconds) * time.Second)
+	defer timer.Stop()
+	// the document cancel flag is polled rather than waited on, so no routine outlives the step
+	poll := time.NewTicker(stopPollInterval)
+	defer poll.Stop()
+	for {
+		select {
+		case res = <-done:
+			// wake up the routines of the plugin still waiting on its cancel flag
+			pluginCancelFlag.Set(task.Completed)
+			return res, false
+		case <-timer.C:
+			select {
+			case res = <-done:
+				pluginCancelFlag.Set(task.Completed)
+				return res, false
+			default:
+			}
+			context.Log().Infof("Step %v exceeded its timeout of %v seconds, canceling plugin %v", config.PluginID, config.TimeoutSeconds, pluginName)
+			pluginCancelFlag.Set(task.Canceled)
+			return <-done, true
+		case <-poll.C:
+			if isStopped(cancelFlag) {
+				pluginCancelFlag.Set(cancelFlag.State())
+				return <-done, false
+			}
+		}
+	}
+}
+
 func runPlugin(
 	context context.T,
 	factory PluginFactory,
@@ -257,7 +526,18 @@ func runPlugin(
 	res.StartDateTime = time.Now()
 	defer func() { res.EndDateTime = time.Now() }()
 
+	// secrets are resolved right before the plugin runs, so that they are only ever kept in memory
+	var secrets []string
+	if config.Properties, secrets, err = resolveSecrets(log, config.Properties); err != nil {
+		res.Status = contracts.ResultStatusFailed
+		res.Code = 1
+		res.Error = fmt.Errorf("failed to resolve secrets: %v", err).Error()
+		log.Error(res.Error)
+		return
+	}
+
 	output := iohandler.NewDefaultIOHandler(log, ioConfig)
+	output.RedactSecrets(secrets)
 	//check if properties is a list. If true, then unroll
 	switch config.Properties.(type) {
 	case []interface{}:
@@ -269,6 +549,7 @@ func runPlugin(
 		for _, prop := range properties {
 			config.Properties = prop
 			propOutput := iohandler.NewDefaultIOHandler(log, ioConfig)
+			propOutput.RedactSecrets(secrets)
 			executePlugin(context, plugin, pluginName, config, cancelFlag, propOutput)
 			output.Merge(log, propOutput)
 		}
@@ -282,7 +563,16 @@ func runPlugin(
 	res.Output = output.GetOutput()
 	res.StandardOutput = output.GetStdout()
 	res.StandardError = output.GetStderr()
+	res.ResourceUsage = output.GetResourceUsage()
 
+	// the writers already redacted the output, this catches the output plugins set directly
+	if len(secrets) > 0 {
+		if text, ok := res.Output.(string); ok {
+			res.Output = iohandler.Redact(text, secrets)
+		}
+		res.StandardOutput = iohandler.Redact(res.StandardOutput, secrets)
+		res.StandardError = iohandler.Redact(res.StandardError, secrets)
+	}
 	return
 }
 
@@ -318,6 +608,34 @@ func executePlugin(context context.T,
 	}
 }
 
+// ValidateStep checks the step the same way RunPlugins does without executing it.
+// It returns skipped if the step would be skipped, and an error if the step would fail.
+func ValidateStep(log log.T, pluginState contracts.PluginState, registry PluginRegistry) (skipped bool, err error) {
+	_, pluginHandlerFound := registry[pluginState.Name]
+	isKnown, isSupported, _ := isSupportedPlugin(log, pluginState.Name)
+	operation, logMessage := getStepExecutionOperation(
+		log,
+		pluginState.Name,
+		pluginState.Id,
+		isKnown,
+		isSupported,
+		pluginHandlerFound,
+		pluginState.Configuration.IsPreconditionEnabled,
+		pluginState.Configuration.Preconditions,
+		newPreconditionEnvironment(log))
+
+	switch operation {
+	case executeStep:
+		return false, nil
+	case skipStep:
+		return true, nil
+	case failStep:
+		return false, fmt.Errorf("%v", logMessage)
+	default:
+		return false, fmt.Errorf("Unknown error, Operation: %s, Plugin name: %s", operation, pluginState.Name)
+	}
+}
+
 func GetPropertyName(rawPluginInput interface{}) (propertyName string, err error) {
 	pluginInput := struct{ ID string }{}
 	err = jsonutil.Remarshal(rawPluginInput, &pluginInput)
@@ -335,6 +653,7 @@ func getStepExecutionOperation(
 	isPluginHandlerFound bool,
 	isPreconditionEnabled bool,
 	preconditions map[string][]string,
+	environment *preconditionEnvironment,
 ) (string, string) {
 	log.Debugf("isSupported flag = %t", isSupported)
 	log.Debugf("isPluginHandlerFound flag = %t", isPluginHandlerFound)
@@ -386,21 +705,31 @@ func getStepExecutionOperation(
 		} else {
 			log.Debugf("Cross-platform Precondition is present, precondition = %v", preconditions)
 
-			isAllowed, unrecognizedPreconditionList := evaluatePreconditions(log, preconditions)
+			result := evaluatePreconditions(environment, preconditions)
 
-			if isAllowed && !isKnown {
+			if result.isPlatformAllowed && !isKnown {
 				return failStep, fmt.Sprintf(
 					"Plugin with name %s is not supported by this version of ssm agent, please update to latest version. Step name: %s",
 					pluginName,
 					pluginId)
-			} else if !isAllowed || !isSupported || !isPluginHandlerFound {
+			} else if !result.isPlatformAllowed || !isSupported || !isPluginHandlerFound {
 				return skipStep, fmt.Sprintf(
 					"Step execution skipped due to incompatible platform. Step name: %s",
 					pluginId)
-			} else if len(unrecognizedPreconditionList) > 0 {
+			} else if len(result.unrecognized) > 0 {
 				return failStep, fmt.Sprintf(
 					"Unrecognized precondition(s): '%s', please update agent to latest version. Step name: %s",
-					strings.Join(unrecognizedPreconditionList, ", "),
+					strings.Join(result.unrecognized, ", "),
+					pluginId)
+			} else if len(result.failed) > 0 {
+				return failStep, fmt.Sprintf(
+					"Failed to evaluate precondition(s): '%s'. Step name: %s",
+					strings.Join(result.failed, ", "),
+					pluginId)
+			} else if len(result.unmet) > 0 {
+				return skipStep, fmt.Sprintf(
+					"Step execution skipped due to unmet precondition(s): '%s'. Step name: %s",
+					strings.Join(result.unmet, ", "),
 					pluginId)
 			} else {
 				return executeStep, ""
@@ -408,50 +737,3 @@ func getStepExecutionOperation(
 		}
 	}
 }
-
-// Evaluate precondition and return precondition result and unrecognized preconditions (if any)
-func evaluatePreconditions(
-	log log.T,
-	preconditions map[string][]string,
-) (bool, []string) {
-
-	var isAllowed = true
-	var unrecognizedPreconditionList []string
-
-	// For current release, we only support "StringEquals" operator and "platformType"
-	// operand, so explicitly checking for those and number of operands must be 2
-	for key, value := range preconditions {
-		switch key {
-		case "StringEquals":
-			// Platform type of OS on the instance
-			instancePlatformType, _ := platform.PlatformType(log)
-			log.Debugf("OS platform type of this instance = %s", instancePlatformType)
-
-			if len(value) != 2 ||
-				(strings.Compare(value[0], "platformType") == 0 && strings.Compare(value[1], "platformType") == 0) ||
-				(strings.Compare(value[0], "platformType") != 0 && strings.Compare(value[1], "platformType") != 0) {
-
-				unrecognizedPreconditionList = append(unrecognizedPreconditionList, fmt.Sprintf("\"%s\": %v", key, value))
-			} else {
-				// Variable and value can be in any order, i.e. both "StringEquals": ["platformType", "Windows"]
-				// and "StringEquals": ["Windows", "platformType"] are valid
-				var platformTypeValue string
-				if strings.Compare(value[0], "platformType") == 0 {
-					platformTypeValue = value[1]
-				} else {
-					platformTypeValue = value[0]
-				}
-
-				if strings.Compare(instancePlatformType, strings.ToLower(platformTypeValue)) != 0 {
-					// if precondition doesn't match for platformType, mark step for skip
-					isAllowed = false
-				}
-			}
-		default:
-			// mark for unrecognizedPrecondition (which is a form of failure)
-			unrecognizedPreconditionList = append(unrecognizedPreconditionList, fmt.Sprintf("\"%s\": %v", key, value))
-		}
-	}
-
-	return isAllowed, unrecognizedPreconditionList
-}
@@ -15,13 +15,21 @@
 package runpluginutil
 
 import (
+	"errors"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/aws/amazon-ssm-agent/agent/context"
 	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler"
 	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/parameterstore"
 	"github.com/aws/amazon-ssm-agent/agent/task"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -1030,7 +1038,7 @@ func TestRunPluginsWithUnrecognizedPreconditionOperator(t *testing.T) {
 	assert.Equal(t, pluginResults, outputs)
 }
 
-// Crossplatform document with unrecognized precondition operand, steps must fail
+// Crossplatform document with unrecognized precondition operand (an undefined parameter), steps must fail
 func TestRunPluginsWithUnrecognizedPreconditionOperand(t *testing.T) {
 	setIsSupportedMock()
 	defer restoreIsSupported()
@@ -1048,7 +1056,7 @@ func TestRunPluginsWithUnrecognizedPreconditionOperand(t *testing.T) {
 	defaultOutput := ""
 	pluginConfigs2 := make([]contracts.PluginState, len(pluginNames))
 
-	preconditions := map[string][]string{"StringEquals": []string{"foo", "Linux"}}
+	preconditions := map[string][]string{"StringEquals": []string{"{{ foo }}", "Linux"}}
 
 	for index, name := range pluginNames {
 
@@ -1071,7 +1079,7 @@ func TestRunPluginsWithUnrecognizedPreconditionOperand(t *testing.T) {
 		}
 
 		pluginError := fmt.Sprintf(
-			"Unrecognized precondition(s): '\"StringEquals\": [foo Linux]', please update agent to latest version. Step name: %s",
+			"Unrecognized precondition(s): '\"StringEquals\": [{{ foo }} Linux]', please update agent to latest version. Step name: %s",
 			name)
 
 		pluginResults[name] = &contracts.PluginResult{
@@ -1503,3 +1511,425 @@ func TestRunPluginSuccessWithNonTruncatedResult(t *testing.T) {
 		assert.Equal(t, pluginResults[pluginID].StandardOutput, output.StandardOutput)
 	}
 }
+
+func TestValidateStep(t *testing.T) {
+	setIsSupportedMock()
+	defer restoreIsSupported()
+	logger := log.NewMockLog()
+	pluginRegistry := PluginRegistry{testPlugin1: new(PluginFactoryMock)}
+
+	newStep := func(name string, preconditionEnabled bool) contracts.PluginState {
+		return contracts.PluginState{
+			Name: name,
+			Id:   name,
+			Configuration: contracts.Configuration{
+				PluginID:              name,
+				PluginName:            name,
+				IsPreconditionEnabled: preconditionEnabled,
+			},
+		}
+	}
+
+	skipped, err := ValidateStep(logger, newStep(testPlugin1, false), pluginRegistry)
+	assert.False(t, skipped)
+	assert.Nil(t, err)
+
+	skipped, err = ValidateStep(logger, newStep(testPlugin2, false), pluginRegistry)
+	assert.False(t, skipped)
+	assert.Equal(t, fmt.Sprintf("Plugin with name %s not found. Step name: %s", testPlugin2, testPlugin2), err.Error())
+
+	skipped, err = ValidateStep(logger, newStep(testUnknownPlugin, true), pluginRegistry)
+	assert.False(t, skipped)
+	assert.NotNil(t, err)
+
+	skipped, err = ValidateStep(logger, newStep(testUnsupportedPlugin, true), pluginRegistry)
+	assert.True(t, skipped)
+	assert.Nil(t, err)
+}
+
+func runPluginsWithStepTimeout(t *testing.T, onTimeout string) (outputs map[string]*contracts.PluginResult, plugins map[string]*PluginMock) {
+	pluginNames := []string{testPlugin1, testPlugin2}
+	pluginStates := make([]contracts.PluginState, len(pluginNames))
+	plugins = make(map[string]*PluginMock)
+	pluginRegistry := PluginRegistry{}
+	var cancelFlag task.CancelFlag = task.NewChanneledCancelFlag()
+	ctx := context.NewMockDefault()
+
+	for index, name := range pluginNames {
+		plugins[name] = new(PluginMock)
+		config := contracts.Configuration{
+			PluginID:   name,
+			PluginName: name,
+		}
+		if name == testPlugin1 {
+			config.TimeoutSeconds = 1
+			config.OnTimeout = onTimeout
+			// the plugin only returns once its cancel flag is set
+			plugins[name].On("Execute", ctx, config, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				flag := args.Get(2).(task.CancelFlag)
+				flag.Wait()
+			}).Return()
+		} else {
+			plugins[name].On("Execute", ctx, config, cancelFlag, mock.Anything).Return()
+		}
+		pluginStates[index] = contracts.PluginState{
+			Name:          name,
+			Id:            name,
+			Configuration: config,
+		}
+		pluginFactory := new(PluginFactoryMock)
+		pluginFactory.On("Create", mock.Anything).Return(plugins[name], nil)
+		pluginRegistry[name] = pluginFactory
+	}
+
+	ch := make(chan contracts.PluginResult, len(pluginNames))
+	outputs = RunPlugins(ctx, pluginStates, contracts.IOConfiguration{}, pluginRegistry, ch, cancelFlag)
+	close(ch)
+	assert.False(t, cancelFlag.Canceled())
+	return
+}
+
+func TestRunPluginsWithStepTimeoutContinue(t *testing.T) {
+	setIsSupportedMock()
+	defer restoreIsSupported()
+
+	outputs, plugins := runPluginsWithStepTimeout(t, contracts.OnTimeoutContinue)
+
+	for _, mockPlugin := range plugins {
+		mockPlugin.AssertExpectations(t)
+	}
+	assert.Equal(t, contracts.ResultStatusTimedOut, outputs[testPlugin1].Status)
+	assert.Contains(t, outputs[testPlugin1].Error, "timed out after 1 seconds")
+	assert.NotEqual(t, contracts.ResultStatusNotStarted, outputs[testPlugin2].Status)
+}
+
+func TestRunPluginsWithStepTimeoutFail(t *testing.T) {
+	setIsSupportedMock()
+	defer restoreIsSupported()
+
+	outputs, plugins := runPluginsWithStepTimeout(t, contracts.OnTimeoutFail)
+
+	plugins[testPlugin1].AssertExpectations(t)
+	plugins[testPlugin2].AssertNotCalled(t, "Execute", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	assert.Equal(t, contracts.ResultStatusFailed, outputs[testPlugin1].Status)
+	assert.Equal(t, contracts.ResultStatusSkipped, outputs[testPlugin2].Status)
+	assert.Contains(t, outputs[testPlugin2].Output, "step plugin1 timed out")
+}
+
+// runPluginWithTimeoutUntilCanceled runs a step with a timeout whose plugin only returns some time after its cancel flag is set.
+// It returns whether the step timed out and whether the plugin had returned by then.
+func runPluginWithTimeoutUntilCanceled(t *testing.T, timeoutSeconds int, cancelFlag task.CancelFlag) (timedOut bool, returned bool) {
+	defer func() { stopPollInterval = time.Second }()
+	stopPollInterval = 10 * time.Millisecond
+	ctx := context.NewMockDefault()
+	config := contracts.Configuration{PluginID: testPlugin1, PluginName: testPlugin1, TimeoutSeconds: timeoutSeconds}
+	var lock sync.Mutex
+	plugin := new(PluginMock)
+	plugin.On("Execute", ctx, config, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(2).(task.CancelFlag).Wait()
+		time.Sleep(100 * time.Millisecond)
+		lock.Lock()
+		defer lock.Unlock()
+		returned = true
+	}).Return()
+	pluginFactory := new(PluginFactoryMock)
+	pluginFactory.On("Create", mock.Anything).Return(plugin, nil)
+
+	_, timedOut = runPluginWithTimeout(ctx, pluginFactory, testPlugin1, config, cancelFlag, contracts.IOConfiguration{})
+	lock.Lock()
+	defer lock.Unlock()
+	return timedOut, returned
+}
+
+func TestRunPluginWithTimeoutWaitsForCanceledPlugin(t *testing.T) {
+	timedOut, returned := runPluginWithTimeoutUntilCanceled(t, 1, task.NewChanneledCancelFlag())
+
+	assert.True(t, timedOut)
+	assert.True(t, returned)
+}
+
+func TestRunPluginWithTimeoutPassesOnDocumentCancel(t *testing.T) {
+	cancelFlag := task.NewChanneledCancelFlag()
+	cancelFlag.Set(task.Canceled)
+
+	timedOut, returned := runPluginWithTimeoutUntilCanceled(t, 3600, cancelFlag)
+
+	assert.False(t, timedOut)
+	assert.True(t, returned)
+}
+
+func TestRunPluginWithTimeoutCompletesInTime(t *testing.T) {
+	ctx := context.NewMockDefault()
+	config := contracts.Configuration{PluginID: testPlugin1, PluginName: testPlugin1, TimeoutSeconds: 3600}
+	var pluginCancelFlag task.CancelFlag
+	plugin := new(PluginMock)
+	plugin.On("Execute", ctx, config, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		pluginCancelFlag = args.Get(2).(task.CancelFlag)
+		args.Get(3).(iohandler.IOHandler).MarkAsSucceeded()
+	}).Return()
+	pluginFactory := new(PluginFactoryMock)
+	pluginFactory.On("Create", mock.Anything).Return(plugin, nil)
+
+	res, timedOut := runPluginWithTimeout(ctx, pluginFactory, testPlugin1, config, task.NewChanneledCancelFlag(), contracts.IOConfiguration{})
+
+	assert.False(t, timedOut)
+	assert.Equal(t, contracts.ResultStatusSuccess, res.Status)
+	// the routines waiting on the cancel flag of the plugin are woken up
+	assert.Equal(t, task.Completed, pluginCancelFlag.Wait())
+}
+
+// runPluginsWithOnFailure runs steps named after the plugins in the given order, the failing steps mark their output as failed.
+// It returns the outputs and the steps in the order their results were sent.
+func runPluginsWithOnFailure(t *testing.T, steps []string, failing map[string]bool, onFailure map[string]string) (outputs map[string]*contracts.PluginResult, order []string) {
+	setIsSupportedMock()
+	defer restoreIsSupported()
+	orchestrationDir, err := ioutil.TempDir("", "runpluginutil")
+	assert.NoError(t, err)
+	defer os.RemoveAll(orchestrationDir)
+	pluginStates := make([]contracts.PluginState, len(steps))
+	pluginRegistry := PluginRegistry{}
+	var cancelFlag task.CancelFlag = task.NewChanneledCancelFlag()
+	ctx := context.NewMockDefault()
+
+	for index, name := range steps {
+		config := contracts.Configuration{
+			PluginID:   name,
+			PluginName: name,
+			OnFailure:  onFailure[name],
+		}
+		plugin := new(PluginMock)
+		fails := failing[name]
+		plugin.On("Execute", ctx, config, cancelFlag, mock.Anything).Run(func(args mock.Arguments) {
+			if output := args.Get(3).(iohandler.IOHandler); fails {
+				output.MarkAsFailed(errors.New("step failed"))
+			} else {
+				output.MarkAsSucceeded()
+			}
+		}).Return()
+		pluginStates[index] = contracts.PluginState{
+			Name:          name,
+			Id:            name,
+			Configuration: config,
+		}
+		pluginFactory := new(PluginFactoryMock)
+		pluginFactory.On("Create", mock.Anything).Return(plugin, nil)
+		pluginRegistry[name] = pluginFactory
+	}
+
+	ch := make(chan contracts.PluginResult, 2*len(steps))
+	outputs = RunPlugins(ctx, pluginStates, contracts.IOConfiguration{OrchestrationDirectory: orchestrationDir}, pluginRegistry, ch, cancelFlag)
+	close(ch)
+	for result := range ch {
+		order = append(order, result.PluginID)
+	}
+	return
+}
+
+func TestRunPluginsWithOnFailureRollbackStep(t *testing.T) {
+	outputs, order := runPluginsWithOnFailure(t,
+		[]string{"drain", "update", "restore"},
+		map[string]bool{"drain": true},
+		map[string]string{"drain": "rollbackStep:restore"})
+
+	assert.Equal(t, []string{"drain", "restore", "update"}, order)
+	assert.Equal(t, contracts.ResultStatusFailed, outputs["drain"].Status)
+	assert.Equal(t, contracts.ResultStatusSuccess, outputs["restore"].Status)
+	assert.Equal(t, contracts.ResultStatusSkipped, outputs["update"].Status)
+	assert.Contains(t, outputs["update"].Output, "step drain failed and was rolled back")
+}
+
+func TestRunPluginsWithOnFailureRunStep(t *testing.T) {
+	outputs, order := runPluginsWithOnFailure(t,
+		[]string{"notify", "install", "configure"},
+		map[string]bool{"install": true},
+		map[string]string{"install": "runStep:notify", "configure": "runStep:notify"})
+
+	// the handler is only reported once, when it runs
+	assert.Equal(t, []string{"install", "notify", "configure"}, order)
+	assert.Equal(t, contracts.ResultStatusFailed, outputs["install"].Status)
+	assert.Equal(t, contracts.ResultStatusSuccess, outputs["notify"].Status)
+	assert.Equal(t, contracts.ResultStatusSuccess, outputs["configure"].Status)
+}
+
+func TestRunPluginsWithOnFailureHandlerBeforeFailedStep(t *testing.T) {
+	outputs, order := runPluginsWithOnFailure(t,
+		[]string{"restore", "install", "configure"},
+		map[string]bool{"configure": true},
+		map[string]string{"install": "rollbackStep:restore", "configure": "rollbackStep:restore"})
+
+	assert.Equal(t, []string{"install", "configure", "restore"}, order)
+	assert.Equal(t, contracts.ResultStatusSuccess, outputs["install"].Status)
+	assert.Equal(t, contracts.ResultStatusFailed, outputs["configure"].Status)
+	assert.Equal(t, contracts.ResultStatusSuccess, outputs["restore"].Status)
+}
+
+func TestRunPluginsWithOnFailureHandlerNotNeeded(t *testing.T) {
+	outputs, order := runPluginsWithOnFailure(t,
+		[]string{"install", "restore"},
+		map[string]bool{"restore": true},
+		map[string]string{"install": "rollbackStep:restore"})
+
+	assert.Equal(t, []string{"install", "restore"}, order)
+	assert.Equal(t, contracts.ResultStatusSuccess, outputs["install"].Status)
+	assert.Equal(t, contracts.ResultStatusSkipped, outputs["restore"].Status)
+	assert.Contains(t, outputs["restore"].Output, "no step it handles failed")
+}
+
+// runPluginWithFailures runs a step whose plugin fails the given number of times before it succeeds.
+// It returns the output of the step, the number of times the plugin ran and the intervals waited before retrying.
+func runPluginWithFailures(t *testing.T, failures int, retries int) (output *contracts.PluginResult, runs int, intervals []time.Duration) {
+	setIsSupportedMock()
+	defer restoreIsSupported()
+	defer func() { retryAfter = time.After }()
+	retryAfter = func(d time.Duration) <-chan time.Time {
+		intervals = append(intervals, d)
+		elapsed := make(chan time.Time, 1)
+		elapsed <- time.Now()
+		return elapsed
+	}
+	orchestrationDir, err := ioutil.TempDir("", "runpluginutil")
+	assert.NoError(t, err)
+	defer os.RemoveAll(orchestrationDir)
+
+	var cancelFlag task.CancelFlag = task.NewChanneledCancelFlag()
+	ctx := context.NewMockDefault()
+	config := contracts.Configuration{
+		PluginID:             testPlugin1,
+		PluginName:           testPlugin1,
+		Retries:              retries,
+		RetryIntervalSeconds: 5,
+	}
+	plugin := new(PluginMock)
+	plugin.On("Execute", ctx, config, cancelFlag, mock.Anything).Run(func(args mock.Arguments) {
+		if runs++; runs <= failures {
+			args.Get(3).(iohandler.IOHandler).MarkAsFailed(errors.New("mirror unavailable"))
+		} else {
+			args.Get(3).(iohandler.IOHandler).MarkAsSucceeded()
+		}
+	}).Return()
+	pluginFactory := new(PluginFactoryMock)
+	pluginFactory.On("Create", mock.Anything).Return(plugin, nil)
+	pluginStates := []contracts.PluginState{{Name: testPlugin1, Id: testPlugin1, Configuration: config}}
+
+	ch := make(chan contracts.PluginResult, 1)
+	outputs := RunPlugins(ctx, pluginStates, contracts.IOConfiguration{OrchestrationDirectory: orchestrationDir}, PluginRegistry{testPlugin1: pluginFactory}, ch, cancelFlag)
+	close(ch)
+	return outputs[testPlugin1], runs, intervals
+}
+
+func TestRunPluginsRetriesFailedStep(t *testing.T) {
+	output, runs, intervals := runPluginWithFailures(t, 2, 3)
+
+	assert.Equal(t, contracts.ResultStatusSuccess, output.Status)
+	assert.Equal(t, 3, output.Attempts)
+	assert.Equal(t, 3, runs)
+	assert.Equal(t, []time.Duration{5 * time.Second, 5 * time.Second}, intervals)
+}
+
+func TestRunPluginsRetriesUsedUp(t *testing.T) {
+	output, runs, _ := runPluginWithFailures(t, 5, 1)
+
+	assert.Equal(t, contracts.ResultStatusFailed, output.Status)
+	assert.Equal(t, 2, output.Attempts)
+	assert.Equal(t, 2, runs)
+}
+
+func TestRunPluginsWithoutRetries(t *testing.T) {
+	output, runs, intervals := runPluginWithFailures(t, 1, 0)
+
+	assert.Equal(t, contracts.ResultStatusFailed, output.Status)
+	assert.Equal(t, 0, output.Attempts)
+	assert.Equal(t, 1, runs)
+	assert.Empty(t, intervals)
+}
+
+func TestWaitForRetryStopsOnShutDown(t *testing.T) {
+	defer func() { retryAfter = time.After }()
+	retryAfter = func(d time.Duration) <-chan time.Time {
+		// the interval never elapses
+		return make(chan time.Time)
+	}
+
+	cancelFlag := task.NewChanneledCancelFlag()
+	stopped := watchStop(cancelFlag)
+	go cancelFlag.Set(task.ShutDown)
+
+	assert.False(t, waitForRetry(cancelFlag, stopped, 5*time.Second))
+	assert.False(t, waitForRetry(cancelFlag, stopped, 0))
+}
+
+func TestWaitForRetryElapses(t *testing.T) {
+	cancelFlag := task.NewChanneledCancelFlag()
+
+	assert.True(t, waitForRetry(cancelFlag, watchStop(cancelFlag), time.Millisecond))
+	cancelFlag.Set(task.Completed)
+}
+
+func TestRunPluginsResolvesAndRedactsSecrets(t *testing.T) {
+	setIsSupportedMock()
+	defer restoreIsSupported()
+	defer func() { resolveSecrets = parameterstore.ResolveSecrets }()
+	reference := "{{resolve:secretsmanager:db-password}}"
+	resolveSecrets = func(log log.T, input interface{}) (interface{}, []string, error) {
+		properties := input.(map[string]interface{})
+		return map[string]interface{}{"runCommand": strings.Replace(properties["runCommand"].(string), reference, "hunter2", -1)}, []string{"hunter2"}, nil
+	}
+	orchestrationDir, err := ioutil.TempDir("", "runpluginutil")
+	assert.NoError(t, err)
+	defer os.RemoveAll(orchestrationDir)
+
+	var cancelFlag task.CancelFlag = task.NewChanneledCancelFlag()
+	ctx := context.NewMockDefault()
+	config := contracts.Configuration{
+		PluginID:   testPlugin1,
+		PluginName: testPlugin1,
+		Properties: map[string]interface{}{"runCommand": "login --password " + reference},
+	}
+	plugin := new(PluginMock)
+	plugin.On("Execute", ctx, mock.Anything, cancelFlag, mock.Anything).Run(func(args mock.Arguments) {
+		properties := args.Get(1).(contracts.Configuration).Properties.(map[string]interface{})
+		assert.Equal(t, "login --password hunter2", properties["runCommand"])
+		output := args.Get(3).(iohandler.IOHandler)
+		output.AppendInfof("running %v", properties["runCommand"])
+		output.MarkAsSucceeded()
+	}).Return()
+	pluginFactory := new(PluginFactoryMock)
+	pluginFactory.On("Create", mock.Anything).Return(plugin, nil)
+	pluginStates := []contracts.PluginState{{Name: testPlugin1, Id: testPlugin1, Configuration: config}}
+
+	ch := make(chan contracts.PluginResult, 1)
+	outputs := RunPlugins(ctx, pluginStates, contracts.IOConfiguration{OrchestrationDirectory: orchestrationDir}, PluginRegistry{testPlugin1: pluginFactory}, ch, cancelFlag)
+	close(ch)
+
+	plugin.AssertExpectations(t)
+	assert.Equal(t, "running login --password "+iohandler.RedactedText, outputs[testPlugin1].StandardOutput)
+	assert.Equal(t, "login --password "+reference, pluginStates[0].Configuration.Properties.(map[string]interface{})["runCommand"])
+	stdout, err := ioutil.ReadFile(filepath.Join(orchestrationDir, testPlugin1, "stdout"))
+	assert.NoError(t, err)
+	assert.NotContains(t, string(stdout), "hunter2")
+}
+
+func TestRunPluginsFailsOnUnresolvedSecrets(t *testing.T) {
+	setIsSupportedMock()
+	defer restoreIsSupported()
+	defer func() { resolveSecrets = parameterstore.ResolveSecrets }()
+	resolveSecrets = func(log log.T, input interface{}) (interface{}, []string, error) {
+		return input, nil, errors.New("Secrets [db-password] could not be found")
+	}
+
+	var cancelFlag task.CancelFlag = task.NewChanneledCancelFlag()
+	ctx := context.NewMockDefault()
+	plugin := new(PluginMock)
+	pluginFactory := new(PluginFactoryMock)
+	pluginFactory.On("Create", mock.Anything).Return(plugin, nil)
+	config := contracts.Configuration{PluginID: testPlugin1, PluginName: testPlugin1}
+	pluginStates := []contracts.PluginState{{Name: testPlugin1, Id: testPlugin1, Configuration: config}}
+
+	ch := make(chan contracts.PluginResult, 1)
+	outputs := RunPlugins(ctx, pluginStates, contracts.IOConfiguration{}, PluginRegistry{testPlugin1: pluginFactory}, ch, cancelFlag)
+	close(ch)
+
+	plugin.AssertNotCalled(t, "Execute", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	assert.Equal(t, contracts.ResultStatusFailed, outputs[testPlugin1].Status)
+	assert.Contains(t, outputs[testPlugin1].Error, "failed to resolve secrets")
+}
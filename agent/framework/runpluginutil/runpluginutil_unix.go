@@ -29,8 +29,8 @@ func IsPluginSupportedForCurrentPlatform(log log.T, pluginName string) (isKnown
 	platformName, _ := platform.PlatformName(log)
 	platformVersion, _ := platform.PlatformVersion(log)
 
-	if _, known := allSessionPlugins[pluginName]; known == true {
-		return known, true, fmt.Sprintf("%s v%s", platformName, platformVersion)
+	if isSessionPlugin(pluginName) {
+		return true, true, fmt.Sprintf("%s v%s", platformName, platformVersion)
 	}
 	_, known := allPlugins[pluginName]
 	return known, true, fmt.Sprintf("%s v%s", platformName, platformVersion)
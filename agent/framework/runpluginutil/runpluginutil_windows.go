@@ -34,8 +34,8 @@ func IsPluginSupportedForCurrentPlatform(log log.T, pluginName string) (isKnown
 	platformName, _ := platform.PlatformName(log)
 	platformVersion, _ := platform.PlatformVersion(log)
 
-	if _, known := allSessionPlugins[pluginName]; known == true {
-		return known, isSupportedSessionPlugin(log, pluginName), fmt.Sprintf("%s v%s", platformName, platformVersion)
+	if isSessionPlugin(pluginName) {
+		return true, isSupportedSessionPlugin(log, pluginName), fmt.Sprintf("%s v%s", platformName, platformVersion)
 	}
 
 	_, known := allPlugins[pluginName]
@@ -0,0 +1,263 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package status implements the core module serving the health of the agent as JSON
+// on http://127.0.0.1:<HealthPort>/health, the endpoint is only reachable from the instance.
+//
+// The endpoint answers 200 while the agent is healthy and 503 otherwise, so that it can be used
+// by load-balancer-style health checks as well as for local diagnostics.
+package status
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/association/recorder"
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/managedInstances/registration"
+	"github.com/aws/amazon-ssm-agent/agent/managedInstances/rolecreds"
+	"github.com/aws/amazon-ssm-agent/agent/metrics"
+	"github.com/aws/amazon-ssm-agent/agent/platform"
+	"github.com/aws/amazon-ssm-agent/agent/times"
+	"github.com/aws/amazon-ssm-agent/agent/version"
+)
+
+const (
+	// Name is the core module name for the health endpoint
+	Name = "HealthEndpoint"
+
+	// Path is the path the health is served on
+	Path = "/health"
+
+	// ContactTimeout is how long the agent may go without reaching either MDS or MGS before it's reported unhealthy
+	ContactTimeout = 5 * time.Minute
+
+	contentType = "application/json"
+
+	readTimeout = 10 * time.Second
+)
+
+// The dependencies are assigned to variables to allow unit tests to override them
+var (
+	listen                = net.Listen
+	instanceID            = platform.InstanceID
+	region                = platform.Region
+	isManagedInstance     = registration.HasManagedInstancesCredentials
+	credentialsExpiration = rolecreds.CredentialsExpiration
+	executionHistory      = recorder.ExecutionHistory
+	now                   = time.Now
+)
+
+// Status is the health of the agent
+type Status struct {
+	Healthy bool
+	// Problems explains why the agent isn't healthy
+	Problems               []string `json:",omitempty"`
+	Version                string
+	Registration           Registration
+	CredentialsExpiration  string `json:",omitempty"`
+	LastMdsContact         string `json:",omitempty"`
+	LastMgsContact         string `json:",omitempty"`
+	QueueDepths            map[string]int64
+	LastAssociationResults []AssociationResult
+}
+
+// Registration is the identity the agent is registered with
+type Registration struct {
+	Registered bool
+	InstanceID string `json:",omitempty"`
+	Region     string `json:",omitempty"`
+	// OnPremises is true when the agent is registered with a managed instance activation
+	OnPremises bool
+}
+
+// AssociationResult is the result of the latest execution of an association
+type AssociationResult struct {
+	AssociationID   string
+	DocumentName    string
+	DocumentVersion string
+	Status          string
+	EndDateTime     string
+}
+
+// Server is the core module serving the health
+type Server struct {
+	context context.T
+	lock    sync.Mutex
+	server  *http.Server
+	started time.Time
+}
+
+// NewServer returns the health endpoint
+func NewServer(context context.T) *Server {
+	return &Server{
+		context: context.With("[" + Name + "]"),
+	}
+}
+
+// ModuleName returns the name of the module
+func (s *Server) ModuleName() string {
+	return Name
+}
+
+// ModuleExecute starts serving the health if HealthPort is set
+func (s *Server) ModuleExecute(context context.T) (err error) {
+	log := s.context.Log()
+	port := s.context.AppConfig().Agent.HealthPort
+	if port == 0 {
+		log.Debug("Health endpoint is disabled")
+		return nil
+	}
+
+	address := fmt.Sprintf("127.0.0.1:%d", port)
+	listener, err := listen("tcp", address)
+	if err != nil {
+		log.Errorf("Failed to listen on %v, %v", address, err)
+		return err
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(Path, s.serveHealth)
+	server := &http.Server{Handler: mux, ReadTimeout: readTimeout}
+	s.lock.Lock()
+	s.server = server
+	s.started = now()
+	s.lock.Unlock()
+
+	log.Infof("Serving the health on http://%v%v", listener.Addr(), Path)
+	go s.serve(server, listener)
+	return nil
+}
+
+// ModuleRequestStop stops serving the health
+func (s *Server) ModuleRequestStop(stopType contracts.StopType) (err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.server != nil {
+		s.server.Close()
+		s.server = nil
+	}
+	return nil
+}
+
+// serve serves the health checks until the server stops
+func (s *Server) serve(server *http.Server, listener net.Listener) {
+	if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+		s.context.Log().Errorf("Health endpoint stopped, %v", err)
+	}
+}
+
+// serveHealth writes the status as JSON, with 503 Service Unavailable when the agent isn't healthy
+func (s *Server) serveHealth(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	s.lock.Lock()
+	started := s.started
+	s.lock.Unlock()
+
+	status := getStatus(started)
+	content, err := json.Marshal(status)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	if !status.Healthy {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	w.Write(content)
+}
+
+// getStatus gathers the status of the agent, the contact timeout counts from started until the first contact
+func getStatus(started time.Time) Status {
+	current := now()
+	status := Status{
+		Version:                version.Version,
+		QueueDepths:            metrics.GetSnapshot().Gauges,
+		LastAssociationResults: []AssociationResult{},
+	}
+
+	if id, err := instanceID(); err != nil {
+		status.Problems = append(status.Problems, fmt.Sprintf("instance id is unknown, %v", err))
+	} else if id == "" {
+		status.Problems = append(status.Problems, "instance id is unknown")
+	} else {
+		status.Registration.Registered = true
+		status.Registration.InstanceID = id
+		status.Registration.Region, _ = region()
+		status.LastAssociationResults = lastAssociationResults(id)
+	}
+	status.Registration.OnPremises, _ = isManagedInstance()
+
+	if status.Registration.OnPremises {
+		if expiration := credentialsExpiration(); !expiration.IsZero() {
+			status.CredentialsExpiration = times.ToIso8601UTC(expiration)
+			if !expiration.After(current) {
+				status.Problems = append(status.Problems, "managed instance credentials expired")
+			}
+		}
+	}
+
+	lastMds := metrics.LastContact(metrics.ChannelMds)
+	lastMgs := metrics.LastContact(metrics.ChannelMgs)
+	status.LastMdsContact = formatContact(lastMds)
+	status.LastMgsContact = formatContact(lastMgs)
+	lastContact := started
+	for _, contact := range []time.Time{lastMds, lastMgs} {
+		if contact.After(lastContact) {
+			lastContact = contact
+		}
+	}
+	if current.Sub(lastContact) > ContactTimeout {
+		status.Problems = append(status.Problems, fmt.Sprintf("neither MDS nor MGS was reached in the last %v", ContactTimeout))
+	}
+
+	status.Healthy = len(status.Problems) == 0
+	return status
+}
+
+// lastAssociationResults returns the result of the latest recorded execution of each association, latest first
+func lastAssociationResults(instanceID string) []AssociationResult {
+	results := []AssociationResult{}
+	seen := make(map[string]bool)
+	for _, execution := range executionHistory(instanceID, "", 0) {
+		if seen[execution.AssociationID] {
+			continue
+		}
+		seen[execution.AssociationID] = true
+		results = append(results, AssociationResult{
+			AssociationID:   execution.AssociationID,
+			DocumentName:    execution.DocumentName,
+			DocumentVersion: execution.DocumentVersion,
+			Status:          execution.Status,
+			EndDateTime:     execution.EndDateTime,
+		})
+	}
+	return results
+}
+
+// formatContact formats the time of the contact, empty if there was none
+func formatContact(contact time.Time) string {
+	if contact.IsZero() {
+		return ""
+	}
+	return times.ToIso8601UTC(contact)
+}
@@ -0,0 +1,198 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package status implements the core module serving the health of the agent as JSON
+package status
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/association/recorder"
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/managedInstances/registration"
+	"github.com/aws/amazon-ssm-agent/agent/managedInstances/rolecreds"
+	"github.com/aws/amazon-ssm-agent/agent/metrics"
+	"github.com/aws/amazon-ssm-agent/agent/platform"
+	"github.com/aws/amazon-ssm-agent/agent/version"
+	"github.com/stretchr/testify/assert"
+)
+
+var current = time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func newContext(port int) *context.Mock {
+	config := appconfig.SsmagentConfig{}
+	config.Agent.HealthPort = port
+	mockContext := new(context.Mock)
+	mockContext.On("Log").Return(log.NewMockLog())
+	mockContext.On("AppConfig").Return(config)
+	mockContext.On("With", "["+Name+"]").Return(mockContext)
+	return mockContext
+}
+
+// stubDependencies registers the agent on premises as mi-123 in us-east-1 with an association run twice
+func stubDependencies() (restore func()) {
+	instanceID = func() (string, error) { return "mi-123", nil }
+	region = func() (string, error) { return "us-east-1", nil }
+	isManagedInstance = func() (bool, error) { return true, nil }
+	credentialsExpiration = func() time.Time { return current.Add(time.Hour) }
+	executionHistory = func(instanceID string, associationID string, maxResults int) []recorder.AssociationExecution {
+		return []recorder.AssociationExecution{
+			{AssociationID: "a1", DocumentName: "AWS-RunPatchBaseline", Status: "Failed", EndDateTime: "2020-05-01T11:00:00.000Z"},
+			{AssociationID: "a2", DocumentName: "AWS-GatherSoftwareInventory", Status: "Success"},
+			{AssociationID: "a1", DocumentName: "AWS-RunPatchBaseline", Status: "Success"},
+		}
+	}
+	now = func() time.Time { return current }
+	metrics.Reset()
+	return func() {
+		listen = net.Listen
+		instanceID = platform.InstanceID
+		region = platform.Region
+		isManagedInstance = registration.HasManagedInstancesCredentials
+		credentialsExpiration = rolecreds.CredentialsExpiration
+		executionHistory = recorder.ExecutionHistory
+		now = time.Now
+		metrics.Reset()
+	}
+}
+
+func TestGetStatusHealthy(t *testing.T) {
+	defer stubDependencies()()
+	metrics.AddGauge(metrics.ChannelDocuments, metrics.GaugeQueued, 2)
+
+	status := getStatus(current.Add(-time.Minute))
+
+	assert.True(t, status.Healthy)
+	assert.Empty(t, status.Problems)
+	assert.Equal(t, version.Version, status.Version)
+	assert.Equal(t, Registration{Registered: true, InstanceID: "mi-123", Region: "us-east-1", OnPremises: true}, status.Registration)
+	assert.Equal(t, "2020-05-01T13:00:00.000Z", status.CredentialsExpiration)
+	assert.Equal(t, map[string]int64{"documents.queued": 2}, status.QueueDepths)
+	assert.Equal(t, []AssociationResult{
+		{AssociationID: "a1", DocumentName: "AWS-RunPatchBaseline", Status: "Failed", EndDateTime: "2020-05-01T11:00:00.000Z"},
+		{AssociationID: "a2", DocumentName: "AWS-GatherSoftwareInventory", Status: "Success"},
+	}, status.LastAssociationResults)
+}
+
+func TestGetStatusWithoutRecentContact(t *testing.T) {
+	defer stubDependencies()()
+
+	// the agent started long ago and never reached the services
+	status := getStatus(current.Add(-time.Hour))
+	assert.False(t, status.Healthy)
+	assert.Equal(t, []string{fmt.Sprintf("neither MDS nor MGS was reached in the last %v", ContactTimeout)}, status.Problems)
+	assert.Empty(t, status.LastMdsContact)
+	assert.Empty(t, status.LastMgsContact)
+
+	// the agent just started
+	status = getStatus(current.Add(-time.Minute))
+	assert.True(t, status.Healthy)
+}
+
+func TestGetStatusWithRecentContact(t *testing.T) {
+	defer stubDependencies()()
+	now = time.Now
+	credentialsExpiration = func() time.Time { return time.Now().Add(time.Hour) }
+	metrics.RecordContact(metrics.ChannelMgs)
+
+	status := getStatus(time.Now().Add(-time.Hour))
+	assert.True(t, status.Healthy)
+	assert.Empty(t, status.LastMdsContact)
+	assert.NotEmpty(t, status.LastMgsContact)
+}
+
+func TestGetStatusNotRegistered(t *testing.T) {
+	defer stubDependencies()()
+	instanceID = func() (string, error) { return "", fmt.Errorf("no registration") }
+	isManagedInstance = func() (bool, error) { return false, nil }
+
+	status := getStatus(current)
+	assert.False(t, status.Healthy)
+	assert.Equal(t, []string{"instance id is unknown, no registration"}, status.Problems)
+	assert.Equal(t, Registration{}, status.Registration)
+	assert.Empty(t, status.CredentialsExpiration)
+	assert.Empty(t, status.LastAssociationResults)
+}
+
+func TestGetStatusCredentialsExpired(t *testing.T) {
+	defer stubDependencies()()
+	credentialsExpiration = func() time.Time { return current.Add(-time.Minute) }
+
+	status := getStatus(current)
+	assert.False(t, status.Healthy)
+	assert.Equal(t, []string{"managed instance credentials expired"}, status.Problems)
+}
+
+func TestServerServesHealth(t *testing.T) {
+	defer stubDependencies()()
+	var address, listening string
+	listen = func(network, addr string) (net.Listener, error) {
+		address = addr
+		// an ephemeral port keeps the test from conflicting with a running agent
+		listener, err := net.Listen(network, "127.0.0.1:0")
+		if err == nil {
+			listening = listener.Addr().String()
+		}
+		return listener, err
+	}
+
+	ctx := newContext(9912)
+	server := NewServer(ctx)
+	assert.Nil(t, server.ModuleExecute(ctx))
+	defer server.ModuleRequestStop(contracts.StopTypeSoftStop)
+	assert.Equal(t, "127.0.0.1:9912", address)
+
+	response, err := http.Get("http://" + listening + Path)
+	assert.Nil(t, err)
+	var status Status
+	assert.Nil(t, json.NewDecoder(response.Body).Decode(&status))
+	response.Body.Close()
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, contentType, response.Header.Get("Content-Type"))
+	assert.True(t, status.Healthy)
+	assert.Equal(t, "mi-123", status.Registration.InstanceID)
+
+	// the agent can't reach the services any longer
+	now = func() time.Time { return current.Add(time.Hour) }
+	response, err = http.Get("http://" + listening + Path)
+	assert.Nil(t, err)
+	response.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, response.StatusCode)
+
+	response, err = http.Post("http://"+listening+Path, "application/json", nil)
+	assert.Nil(t, err)
+	response.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, response.StatusCode)
+}
+
+func TestServerDisabled(t *testing.T) {
+	defer stubDependencies()()
+	listen = func(network, addr string) (net.Listener, error) {
+		t.Fatal("the disabled endpoint must not listen")
+		return nil, nil
+	}
+
+	ctx := newContext(0)
+	server := NewServer(ctx)
+	assert.Nil(t, server.ModuleExecute(ctx))
+	assert.Nil(t, server.server)
+	assert.Nil(t, server.ModuleRequestStop(contracts.StopTypeSoftStop))
+}
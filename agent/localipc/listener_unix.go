@@ -0,0 +1,48 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// +build darwin freebsd linux netbsd openbsd
+
+// Package localipc implements the endpoint local tooling submits documents to the agent through
+package localipc
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+)
+
+// listenLocal listens on a unix socket in a directory only root can access
+func listenLocal(address string) (net.Listener, error) {
+	dir := filepath.Dir(address)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(dir, 0700); err != nil {
+		return nil, err
+	}
+	// remove the socket left by an agent that didn't stop cleanly
+	if err := os.Remove(address); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	listener, err := net.Listen("unix", address)
+	if err != nil {
+		return nil, err
+	}
+	if err = os.Chmod(address, 0600); err != nil {
+		listener.Close()
+		return nil, err
+	}
+	return listener, nil
+}
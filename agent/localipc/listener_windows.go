@@ -0,0 +1,219 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// +build windows
+
+// Package localipc implements the endpoint local tooling submits documents to the agent through
+package localipc
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+const (
+	pipeAccessDuplex          = 0x3
+	pipeFirstPipeInstance     = 0x00080000
+	pipeTypeByte              = 0x0
+	pipeRejectRemoteClients   = 0x8
+	pipeUnlimitedInstances    = 255
+	pipeBufferSize            = 4096
+	sddlRevision1             = 1
+	errorPipeConnected        = syscall.Errno(535)
+	pipeNetwork               = "pipe"
+	pipeSecurityDescriptorACL = "D:P(A;;GA;;;SY)(A;;GA;;;BA)" // LocalSystem and Administrators only
+)
+
+var (
+	kernel32                                                 = syscall.NewLazyDLL("kernel32.dll")
+	advapi32                                                 = syscall.NewLazyDLL("advapi32.dll")
+	procCreateNamedPipeW                                     = kernel32.NewProc("CreateNamedPipeW")
+	procConnectNamedPipe                                     = kernel32.NewProc("ConnectNamedPipe")
+	procDisconnectNamedPipe                                  = kernel32.NewProc("DisconnectNamedPipe")
+	procLocalFree                                            = kernel32.NewProc("LocalFree")
+	procConvertStringSecurityDescriptorToSecurityDescriptorW = advapi32.NewProc("ConvertStringSecurityDescriptorToSecurityDescriptorW")
+
+	errListenerClosed = errors.New("pipe listener closed")
+)
+
+// pipeListener accepts the connections of a named pipe, the pipe is opened for synchronous I/O
+type pipeListener struct {
+	address            string
+	name               *uint16
+	securityDescriptor uintptr
+	lock               sync.Mutex
+	next               syscall.Handle
+	closed             bool
+}
+
+// pipeConn is a connected instance of the named pipe
+type pipeConn struct {
+	*os.File
+	handle  syscall.Handle
+	address pipeAddr
+}
+
+type pipeAddr string
+
+// listenLocal creates a named pipe only LocalSystem and Administrators can open, the creation fails if the pipe already exists
+func listenLocal(address string) (net.Listener, error) {
+	name, err := syscall.UTF16PtrFromString(address)
+	if err != nil {
+		return nil, err
+	}
+	acl, err := syscall.UTF16PtrFromString(pipeSecurityDescriptorACL)
+	if err != nil {
+		return nil, err
+	}
+
+	listener := &pipeListener{address: address, name: name}
+	if r, _, err := procConvertStringSecurityDescriptorToSecurityDescriptorW.Call(
+		uintptr(unsafe.Pointer(acl)),
+		sddlRevision1,
+		uintptr(unsafe.Pointer(&listener.securityDescriptor)),
+		0); r == 0 {
+		return nil, fmt.Errorf("failed to create the security descriptor of %v, %v", address, err)
+	}
+	// the first instance makes sure no other process owns the pipe
+	if listener.next, err = listener.createInstance(pipeFirstPipeInstance); err != nil {
+		procLocalFree.Call(listener.securityDescriptor)
+		return nil, err
+	}
+	return listener, nil
+}
+
+func (l *pipeListener) createInstance(flags uint32) (syscall.Handle, error) {
+	securityAttributes := syscall.SecurityAttributes{
+		SecurityDescriptor: l.securityDescriptor,
+	}
+	securityAttributes.Length = uint32(unsafe.Sizeof(securityAttributes))
+	r, _, err := procCreateNamedPipeW.Call(
+		uintptr(unsafe.Pointer(l.name)),
+		uintptr(pipeAccessDuplex|flags),
+		pipeTypeByte|pipeRejectRemoteClients,
+		pipeUnlimitedInstances,
+		pipeBufferSize,
+		pipeBufferSize,
+		0,
+		uintptr(unsafe.Pointer(&securityAttributes)))
+	if syscall.Handle(r) == syscall.InvalidHandle {
+		return 0, fmt.Errorf("failed to create named pipe %v, %v", l.address, err)
+	}
+	return syscall.Handle(r), nil
+}
+
+// Accept waits for a client to open the pipe
+func (l *pipeListener) Accept() (net.Conn, error) {
+	l.lock.Lock()
+	handle, closed := l.next, l.closed
+	l.next = 0
+	l.lock.Unlock()
+	if closed {
+		return nil, errListenerClosed
+	}
+
+	var err error
+	if handle == 0 {
+		if handle, err = l.createInstance(0); err != nil {
+			return nil, err
+		}
+	}
+	if r, _, err := procConnectNamedPipe.Call(uintptr(handle), 0); r == 0 && err != errorPipeConnected {
+		syscall.CloseHandle(handle)
+		return nil, fmt.Errorf("failed to connect named pipe %v, %v", l.address, err)
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.closed {
+		syscall.CloseHandle(handle)
+		return nil, errListenerClosed
+	}
+	// create the following instance right away so that the pipe keeps existing while the connection is served
+	if l.next, err = l.createInstance(0); err != nil {
+		l.next = 0
+	}
+	return &pipeConn{
+		File:    os.NewFile(uintptr(handle), l.address),
+		handle:  handle,
+		address: pipeAddr(l.address),
+	}, nil
+}
+
+// Close stops accepting connections, a pending Accept is woken up by opening the pipe
+func (l *pipeListener) Close() error {
+	l.lock.Lock()
+	if l.closed {
+		l.lock.Unlock()
+		return nil
+	}
+	l.closed = true
+	next := l.next
+	l.next = 0
+	l.lock.Unlock()
+
+	if next != 0 {
+		syscall.CloseHandle(next)
+	} else if client, err := syscall.CreateFile(l.name, syscall.GENERIC_READ|syscall.GENERIC_WRITE, 0, nil, syscall.OPEN_EXISTING, 0, 0); err == nil {
+		syscall.CloseHandle(client)
+	}
+	procLocalFree.Call(l.securityDescriptor)
+	return nil
+}
+
+// Addr returns the name of the pipe
+func (l *pipeListener) Addr() net.Addr {
+	return pipeAddr(l.address)
+}
+
+// Close flushes the response to the client before closing the pipe instance
+func (c *pipeConn) Close() error {
+	syscall.FlushFileBuffers(c.handle)
+	procDisconnectNamedPipe.Call(uintptr(c.handle))
+	return c.File.Close()
+}
+
+func (c *pipeConn) LocalAddr() net.Addr {
+	return c.address
+}
+
+func (c *pipeConn) RemoteAddr() net.Addr {
+	return c.address
+}
+
+// SetDeadline isn't supported by synchronous pipes
+func (c *pipeConn) SetDeadline(t time.Time) error {
+	return nil
+}
+
+func (c *pipeConn) SetReadDeadline(t time.Time) error {
+	return nil
+}
+
+func (c *pipeConn) SetWriteDeadline(t time.Time) error {
+	return nil
+}
+
+func (a pipeAddr) Network() string {
+	return pipeNetwork
+}
+
+func (a pipeAddr) String() string {
+	return string(a)
+}
@@ -0,0 +1,257 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package localipc implements the endpoint local tooling integrates with the agent through,
+// a unix socket on Linux and macOS and a named pipe on Windows, only reachable by root or Administrators.
+//
+// The endpoint serves the LocalControl gRPC service, its protobuf contracts are versioned in the localipcv1 package.
+// The service submits and cancels documents, polls their status, lists the associations and their execution history,
+// and reports the health and the metrics of the agent.
+package localipc
+
+import (
+	"encoding/json"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/association/recorder"
+	"github.com/aws/amazon-ssm-agent/agent/association/schedulemanager"
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	api "github.com/aws/amazon-ssm-agent/agent/localipc/localipcv1"
+	"github.com/aws/amazon-ssm-agent/agent/metrics"
+	"github.com/aws/amazon-ssm-agent/agent/platform"
+	"github.com/aws/amazon-ssm-agent/agent/times"
+	"github.com/aws/amazon-ssm-agent/agent/version"
+	"github.com/aws/aws-sdk-go/aws"
+	rpccontext "golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Name is the core module name for the local IPC endpoint
+const Name = "LocalIpc"
+
+// Assign method to global variables to allow unittest to override
+var listen = listenLocal
+var scheduledAssociations = schedulemanager.Schedules
+var executionHistory = recorder.ExecutionHistory
+var instanceID = platform.InstanceID
+
+// DocumentRunner runs the documents submitted locally and reports their status
+type DocumentRunner interface {
+	SubmitDocument(documentName string, content contracts.DocumentContent) (executionID string, err error)
+	CancelDocument(executionID string) error
+	DocumentStatus(executionID string) (status contracts.ResultStatus, result string, found bool, err error)
+}
+
+// Server is the core module serving the local IPC endpoint
+type Server struct {
+	context    context.T
+	runner     DocumentRunner
+	address    string
+	lock       sync.Mutex
+	grpcServer *grpc.Server
+	startTime  time.Time
+}
+
+// NewServer returns the local IPC endpoint running the submitted documents with the given runner
+func NewServer(context context.T, runner DocumentRunner) *Server {
+	return &Server{
+		context:   context.With("[" + Name + "]"),
+		runner:    runner,
+		address:   appconfig.LocalIpcAddress,
+		startTime: time.Now(),
+	}
+}
+
+// ModuleName returns the name of the module
+func (s *Server) ModuleName() string {
+	return Name
+}
+
+// ModuleExecute starts serving the local IPC endpoint if it is enabled
+func (s *Server) ModuleExecute(context context.T) (err error) {
+	log := s.context.Log()
+	if !s.context.AppConfig().Agent.LocalIpcEnabled {
+		log.Debug("Local IPC endpoint is disabled")
+		return nil
+	}
+
+	listener, err := listen(s.address)
+	if err != nil {
+		log.Errorf("Failed to listen on %v, %v", s.address, err)
+		return err
+	}
+	grpcServer := grpc.NewServer()
+	api.RegisterLocalControlServer(grpcServer, s)
+	s.lock.Lock()
+	s.grpcServer = grpcServer
+	s.lock.Unlock()
+
+	log.Infof("Serving the local control API on %v", s.address)
+	go s.serve(grpcServer, listener)
+	return nil
+}
+
+// ModuleRequestStop stops serving local requests
+func (s *Server) ModuleRequestStop(stopType contracts.StopType) (err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.grpcServer != nil {
+		// Stop closes the listener and the connections of the clients
+		s.grpcServer.Stop()
+		s.grpcServer = nil
+	}
+	return nil
+}
+
+// serve serves the local requests until the server stops
+func (s *Server) serve(grpcServer *grpc.Server, listener net.Listener) {
+	if err := grpcServer.Serve(listener); err != nil {
+		s.context.Log().Debugf("Local control API stopped, %v", err)
+	}
+}
+
+// SubmitDocument runs the document of the request and returns its execution ID
+func (s *Server) SubmitDocument(ctx rpccontext.Context, request *api.SubmitDocumentRequest) (*api.SubmitDocumentResponse, error) {
+	var content contracts.DocumentContent
+	if err := json.Unmarshal([]byte(request.DocumentJson), &content); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid document %v, %v", request.DocumentName, err)
+	}
+	executionID, err := s.runner.SubmitDocument(request.DocumentName, content)
+	if err != nil {
+		s.context.Log().Errorf("Failed to run local document %v, %v", request.DocumentName, err)
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return &api.SubmitDocumentResponse{ExecutionId: executionID, Status: string(contracts.ResultStatusNotStarted)}, nil
+}
+
+// GetDocumentStatus returns the status of the execution of the request
+func (s *Server) GetDocumentStatus(ctx rpccontext.Context, request *api.GetDocumentStatusRequest) (*api.GetDocumentStatusResponse, error) {
+	resultStatus, result, found, err := s.runner.DocumentStatus(request.ExecutionId)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if !found {
+		return nil, status.Errorf(codes.NotFound, "execution %v not found", request.ExecutionId)
+	}
+	return &api.GetDocumentStatusResponse{ExecutionId: request.ExecutionId, Status: string(resultStatus), ResultJson: result}, nil
+}
+
+// CancelDocument cancels the execution of the request
+func (s *Server) CancelDocument(ctx rpccontext.Context, request *api.CancelDocumentRequest) (*api.CancelDocumentResponse, error) {
+	if err := s.runner.CancelDocument(request.ExecutionId); err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return &api.CancelDocumentResponse{ExecutionId: request.ExecutionId}, nil
+}
+
+// ListAssociations returns the associations scheduled on the instance
+func (s *Server) ListAssociations(ctx rpccontext.Context, request *api.ListAssociationsRequest) (*api.ListAssociationsResponse, error) {
+	return &api.ListAssociationsResponse{Associations: listAssociations()}, nil
+}
+
+// GetExecutionHistory returns the latest executions of the associations, or of the association of the request
+func (s *Server) GetExecutionHistory(ctx rpccontext.Context, request *api.GetExecutionHistoryRequest) (*api.GetExecutionHistoryResponse, error) {
+	id, err := instanceID()
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "failed to load instance id, %v", err)
+	}
+	response := &api.GetExecutionHistoryResponse{}
+	for _, execution := range executionHistory(id, request.AssociationId, int(request.MaxResults)) {
+		response.Executions = append(response.Executions, toAssociationExecution(execution))
+	}
+	return response, nil
+}
+
+// GetHealth returns the version and the start time of the agent
+func (s *Server) GetHealth(ctx rpccontext.Context, request *api.GetHealthRequest) (*api.GetHealthResponse, error) {
+	id, _ := instanceID()
+	return &api.GetHealthResponse{
+		AgentName:    s.context.AppConfig().Agent.Name,
+		AgentVersion: version.Version,
+		InstanceId:   id,
+		StartTime:    times.ToIso8601UTC(s.startTime),
+	}, nil
+}
+
+// GetMetrics returns the message and reply metrics of the channels
+func (s *Server) GetMetrics(ctx rpccontext.Context, request *api.GetMetricsRequest) (*api.GetMetricsResponse, error) {
+	snapshot := metrics.GetSnapshot()
+	response := &api.GetMetricsResponse{
+		Counters:   snapshot.Counters,
+		Histograms: make(map[string]*api.Histogram),
+	}
+	for name, histogram := range snapshot.Histograms {
+		response.Histograms[name] = &api.Histogram{
+			Count:               histogram.Count,
+			SumMilliseconds:     histogram.SumMilliseconds,
+			BucketsMilliseconds: histogram.BucketsMilliseconds,
+			BucketCounts:        histogram.BucketCounts,
+		}
+	}
+	return response, nil
+}
+
+// listAssociations returns the associations the agent scheduled
+func listAssociations() []*api.Association {
+	associations := []*api.Association{}
+	for _, assoc := range scheduledAssociations() {
+		if assoc.Association == nil {
+			continue
+		}
+		association := &api.Association{
+			AssociationId:      aws.StringValue(assoc.Association.AssociationId),
+			Name:               aws.StringValue(assoc.Association.Name),
+			DocumentVersion:    aws.StringValue(assoc.Association.DocumentVersion),
+			ScheduleExpression: aws.StringValue(assoc.Association.ScheduleExpression),
+		}
+		if assoc.NextScheduledDate != nil {
+			association.NextScheduledDate = times.ToIso8601UTC(*assoc.NextScheduledDate)
+		}
+		if assoc.Association.LastExecutionDate != nil {
+			association.LastExecutionDate = times.ToIso8601UTC(*assoc.Association.LastExecutionDate)
+		}
+		associations = append(associations, association)
+	}
+	return associations
+}
+
+// toAssociationExecution converts the record of an association execution to its contract
+func toAssociationExecution(execution recorder.AssociationExecution) *api.AssociationExecution {
+	result := &api.AssociationExecution{
+		AssociationId:   execution.AssociationID,
+		DocumentName:    execution.DocumentName,
+		DocumentVersion: execution.DocumentVersion,
+		ExecutionId:     execution.ExecutionID,
+		Status:          execution.Status,
+		StartDateTime:   execution.StartDateTime,
+		EndDateTime:     execution.EndDateTime,
+		DurationSeconds: execution.DurationSeconds,
+	}
+	for _, plugin := range execution.PluginResults {
+		result.PluginResults = append(result.PluginResults, &api.PluginExecution{
+			PluginId:   plugin.PluginID,
+			PluginName: plugin.PluginName,
+			Status:     plugin.Status,
+			Code:       int32(plugin.Code),
+			Error:      plugin.Error,
+		})
+	}
+	return result
+}
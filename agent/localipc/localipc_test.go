@@ -0,0 +1,250 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package localipc implements the endpoint local tooling integrates with the agent through
+package localipc
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/association/model"
+	"github.com/aws/amazon-ssm-agent/agent/association/recorder"
+	"github.com/aws/amazon-ssm-agent/agent/association/schedulemanager"
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	api "github.com/aws/amazon-ssm-agent/agent/localipc/localipcv1"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/metrics"
+	"github.com/aws/amazon-ssm-agent/agent/platform"
+	"github.com/aws/amazon-ssm-agent/agent/times"
+	"github.com/aws/amazon-ssm-agent/agent/version"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	rpccontext "golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+type fakeRunner struct {
+	submitted map[string]contracts.DocumentContent
+	cancelled map[string]bool
+}
+
+func (r *fakeRunner) SubmitDocument(documentName string, content contracts.DocumentContent) (string, error) {
+	if documentName == "invalid" {
+		return "", fmt.Errorf("invalid document name %v", documentName)
+	}
+	executionID := fmt.Sprintf("execution-%v", len(r.submitted)+1)
+	r.submitted[executionID] = content
+	return executionID, nil
+}
+
+func (r *fakeRunner) CancelDocument(executionID string) error {
+	if _, found := r.submitted[executionID]; !found {
+		return fmt.Errorf("command %v not found", executionID)
+	}
+	r.cancelled[executionID] = true
+	return nil
+}
+
+func (r *fakeRunner) DocumentStatus(executionID string) (contracts.ResultStatus, string, bool, error) {
+	if _, found := r.submitted[executionID]; !found {
+		return "", "", false, nil
+	}
+	return contracts.ResultStatusSuccess, `{"documentStatus":"Success"}`, true, nil
+}
+
+// startTestServer serves the local control API on a unix socket of a temp dir and returns a client connected to it
+func startTestServer(t *testing.T) (api.LocalControlClient, *fakeRunner, func()) {
+	dir, err := ioutil.TempDir("", "localipc")
+	assert.NoError(t, err)
+	listen = func(address string) (net.Listener, error) {
+		return net.Listen("unix", address)
+	}
+
+	config := appconfig.SsmagentConfig{}
+	config.Agent.Name = "amazon-ssm-agent"
+	config.Agent.LocalIpcEnabled = true
+	ctx := new(context.Mock)
+	ctx.On("Log").Return(log.NewMockLog())
+	ctx.On("AppConfig").Return(config)
+	ctx.On("With", mock.AnythingOfType("string")).Return(ctx)
+
+	runner := &fakeRunner{
+		submitted: make(map[string]contracts.DocumentContent),
+		cancelled: make(map[string]bool),
+	}
+	server := NewServer(ctx, runner)
+	server.address = filepath.Join(dir, "agent.sock")
+	assert.NoError(t, server.ModuleExecute(ctx))
+
+	conn, err := grpc.Dial(server.address,
+		grpc.WithInsecure(),
+		grpc.WithDialer(func(address string, timeout time.Duration) (net.Conn, error) {
+			return net.DialTimeout("unix", address, timeout)
+		}))
+	assert.NoError(t, err)
+	return api.NewLocalControlClient(conn), runner, func() {
+		conn.Close()
+		server.ModuleRequestStop(contracts.StopTypeSoftStop)
+		listen = listenLocal
+		os.RemoveAll(dir)
+	}
+}
+
+func TestSubmitDocumentAndGetStatus(t *testing.T) {
+	client, runner, stop := startTestServer(t)
+	defer stop()
+
+	submitted, err := client.SubmitDocument(rpccontext.Background(), &api.SubmitDocumentRequest{
+		DocumentName: "bootstrap",
+		DocumentJson: `{"schemaVersion": "2.2"}`,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "execution-1", submitted.ExecutionId)
+	assert.Equal(t, string(contracts.ResultStatusNotStarted), submitted.Status)
+	assert.Equal(t, "2.2", runner.submitted["execution-1"].SchemaVersion)
+
+	documentStatus, err := client.GetDocumentStatus(rpccontext.Background(), &api.GetDocumentStatusRequest{ExecutionId: "execution-1"})
+	assert.NoError(t, err)
+	assert.Equal(t, string(contracts.ResultStatusSuccess), documentStatus.Status)
+	assert.JSONEq(t, `{"documentStatus":"Success"}`, documentStatus.ResultJson)
+
+	_, err = client.CancelDocument(rpccontext.Background(), &api.CancelDocumentRequest{ExecutionId: "execution-1"})
+	assert.NoError(t, err)
+	assert.True(t, runner.cancelled["execution-1"])
+}
+
+func TestListAssociations(t *testing.T) {
+	next := time.Date(2017, 5, 1, 10, 30, 0, 0, time.UTC)
+	scheduledAssociations = func() []*model.InstanceAssociation {
+		return []*model.InstanceAssociation{
+			{
+				NextScheduledDate: &next,
+				Association: &ssm.InstanceAssociationSummary{
+					AssociationId:      aws.String("association-1"),
+					Name:               aws.String("AWS-UpdateSSMAgent"),
+					ScheduleExpression: aws.String("rate(30 minutes)"),
+				},
+			},
+			{},
+		}
+	}
+	defer func() { scheduledAssociations = schedulemanager.Schedules }()
+
+	client, _, stop := startTestServer(t)
+	defer stop()
+
+	response, err := client.ListAssociations(rpccontext.Background(), &api.ListAssociationsRequest{})
+	assert.NoError(t, err)
+	assert.Len(t, response.Associations, 1)
+	assert.Equal(t, "association-1", response.Associations[0].AssociationId)
+	assert.Equal(t, "AWS-UpdateSSMAgent", response.Associations[0].Name)
+	assert.Equal(t, "rate(30 minutes)", response.Associations[0].ScheduleExpression)
+	assert.Equal(t, times.ToIso8601UTC(next), response.Associations[0].NextScheduledDate)
+	assert.Empty(t, response.Associations[0].LastExecutionDate)
+}
+
+func TestGetExecutionHistoryAndHealth(t *testing.T) {
+	instanceID = func() (string, error) { return "i-1234567890", nil }
+	executionHistory = func(instanceID string, associationID string, maxResults int) []recorder.AssociationExecution {
+		assert.Equal(t, "i-1234567890", instanceID)
+		assert.Equal(t, "association-1", associationID)
+		assert.Equal(t, 5, maxResults)
+		return []recorder.AssociationExecution{{
+			AssociationID: associationID,
+			ExecutionID:   "execution-1",
+			Status:        "Success",
+			PluginResults: []recorder.PluginExecution{{PluginID: "step1", Status: "Success"}},
+		}}
+	}
+	defer func() {
+		instanceID = platform.InstanceID
+		executionHistory = recorder.ExecutionHistory
+	}()
+
+	client, _, stop := startTestServer(t)
+	defer stop()
+
+	history, err := client.GetExecutionHistory(rpccontext.Background(), &api.GetExecutionHistoryRequest{AssociationId: "association-1", MaxResults: 5})
+	assert.NoError(t, err)
+	assert.Len(t, history.Executions, 1)
+	assert.Equal(t, "execution-1", history.Executions[0].ExecutionId)
+	assert.Equal(t, "step1", history.Executions[0].PluginResults[0].PluginId)
+
+	health, err := client.GetHealth(rpccontext.Background(), &api.GetHealthRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, "amazon-ssm-agent", health.AgentName)
+	assert.Equal(t, "i-1234567890", health.InstanceId)
+	assert.Equal(t, version.Version, health.AgentVersion)
+	assert.NotEmpty(t, health.StartTime)
+}
+
+func TestGetMetrics(t *testing.T) {
+	metrics.Reset()
+	defer metrics.Reset()
+	metrics.Increment(metrics.ChannelMds, metrics.EventAcked)
+	metrics.ObserveLatency(metrics.ChannelMds, "SendReply", 20*time.Millisecond)
+
+	client, _, stop := startTestServer(t)
+	defer stop()
+
+	response, err := client.GetMetrics(rpccontext.Background(), &api.GetMetricsRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), response.Counters["mds.acked"])
+	assert.Len(t, response.Histograms, 1)
+	for _, histogram := range response.Histograms {
+		assert.Equal(t, int64(1), histogram.Count)
+		assert.Len(t, histogram.BucketCounts, len(histogram.BucketsMilliseconds)+1)
+	}
+}
+
+func TestRequestErrors(t *testing.T) {
+	client, _, stop := startTestServer(t)
+	defer stop()
+
+	_, err := client.SubmitDocument(rpccontext.Background(), &api.SubmitDocumentRequest{DocumentName: "invalid", DocumentJson: "{}"})
+	assert.Equal(t, codes.InvalidArgument, grpc.Code(err))
+	assert.Contains(t, grpc.ErrorDesc(err), "invalid document name")
+
+	_, err = client.SubmitDocument(rpccontext.Background(), &api.SubmitDocumentRequest{DocumentName: "bootstrap", DocumentJson: "not a document"})
+	assert.Equal(t, codes.InvalidArgument, grpc.Code(err))
+
+	_, err = client.GetDocumentStatus(rpccontext.Background(), &api.GetDocumentStatusRequest{ExecutionId: "unknown"})
+	assert.Equal(t, codes.NotFound, grpc.Code(err))
+	assert.Equal(t, "execution unknown not found", grpc.ErrorDesc(err))
+
+	_, err = client.CancelDocument(rpccontext.Background(), &api.CancelDocumentRequest{ExecutionId: "unknown"})
+	assert.Equal(t, codes.FailedPrecondition, grpc.Code(err))
+}
+
+func TestModuleExecuteDisabled(t *testing.T) {
+	listen = func(address string) (net.Listener, error) {
+		assert.Fail(t, "the endpoint is disabled by default")
+		return nil, fmt.Errorf("disabled")
+	}
+	defer func() { listen = listenLocal }()
+
+	server := NewServer(context.NewMockDefault(), &fakeRunner{})
+	assert.NoError(t, server.ModuleExecute(server.context))
+	assert.NoError(t, server.ModuleRequestStop(contracts.StopTypeSoftStop))
+}
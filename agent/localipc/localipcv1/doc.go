@@ -0,0 +1,20 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package localipcv1 contains the protobuf contracts and the gRPC stubs of version 1 of the local control API.
+//
+// localipc.pb.go is generated from localipc.proto with protoc and protoc-gen-go from github.com/golang/protobuf v1.1.0,
+// regenerate it after changing the contracts.
+package localipcv1
+
+//go:generate protoc --go_out=plugins=grpc:. localipc.proto
@@ -0,0 +1,1298 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: localipc.proto
+
+package localipcv1
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+
+import (
+	context "golang.org/x/net/context"
+	grpc "google.golang.org/grpc"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+type SubmitDocumentRequest struct {
+	DocumentName string `protobuf:"bytes,1,opt,name=document_name,json=documentName" json:"document_name,omitempty"`
+	// document_json is the content of the command document, in JSON.
+	DocumentJson         string   `protobuf:"bytes,2,opt,name=document_json,json=documentJson" json:"document_json,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SubmitDocumentRequest) Reset()         { *m = SubmitDocumentRequest{} }
+func (m *SubmitDocumentRequest) String() string { return proto.CompactTextString(m) }
+func (*SubmitDocumentRequest) ProtoMessage()    {}
+func (*SubmitDocumentRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_localipc_36eb3758ddee93d3, []int{0}
+}
+func (m *SubmitDocumentRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SubmitDocumentRequest.Unmarshal(m, b)
+}
+func (m *SubmitDocumentRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SubmitDocumentRequest.Marshal(b, m, deterministic)
+}
+func (dst *SubmitDocumentRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SubmitDocumentRequest.Merge(dst, src)
+}
+func (m *SubmitDocumentRequest) XXX_Size() int {
+	return xxx_messageInfo_SubmitDocumentRequest.Size(m)
+}
+func (m *SubmitDocumentRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SubmitDocumentRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SubmitDocumentRequest proto.InternalMessageInfo
+
+func (m *SubmitDocumentRequest) GetDocumentName() string {
+	if m != nil {
+		return m.DocumentName
+	}
+	return ""
+}
+
+func (m *SubmitDocumentRequest) GetDocumentJson() string {
+	if m != nil {
+		return m.DocumentJson
+	}
+	return ""
+}
+
+type SubmitDocumentResponse struct {
+	ExecutionId          string   `protobuf:"bytes,1,opt,name=execution_id,json=executionId" json:"execution_id,omitempty"`
+	Status               string   `protobuf:"bytes,2,opt,name=status" json:"status,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SubmitDocumentResponse) Reset()         { *m = SubmitDocumentResponse{} }
+func (m *SubmitDocumentResponse) String() string { return proto.CompactTextString(m) }
+func (*SubmitDocumentResponse) ProtoMessage()    {}
+func (*SubmitDocumentResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_localipc_36eb3758ddee93d3, []int{1}
+}
+func (m *SubmitDocumentResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SubmitDocumentResponse.Unmarshal(m, b)
+}
+func (m *SubmitDocumentResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SubmitDocumentResponse.Marshal(b, m, deterministic)
+}
+func (dst *SubmitDocumentResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SubmitDocumentResponse.Merge(dst, src)
+}
+func (m *SubmitDocumentResponse) XXX_Size() int {
+	return xxx_messageInfo_SubmitDocumentResponse.Size(m)
+}
+func (m *SubmitDocumentResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_SubmitDocumentResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SubmitDocumentResponse proto.InternalMessageInfo
+
+func (m *SubmitDocumentResponse) GetExecutionId() string {
+	if m != nil {
+		return m.ExecutionId
+	}
+	return ""
+}
+
+func (m *SubmitDocumentResponse) GetStatus() string {
+	if m != nil {
+		return m.Status
+	}
+	return ""
+}
+
+type GetDocumentStatusRequest struct {
+	ExecutionId          string   `protobuf:"bytes,1,opt,name=execution_id,json=executionId" json:"execution_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetDocumentStatusRequest) Reset()         { *m = GetDocumentStatusRequest{} }
+func (m *GetDocumentStatusRequest) String() string { return proto.CompactTextString(m) }
+func (*GetDocumentStatusRequest) ProtoMessage()    {}
+func (*GetDocumentStatusRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_localipc_36eb3758ddee93d3, []int{2}
+}
+func (m *GetDocumentStatusRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetDocumentStatusRequest.Unmarshal(m, b)
+}
+func (m *GetDocumentStatusRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetDocumentStatusRequest.Marshal(b, m, deterministic)
+}
+func (dst *GetDocumentStatusRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetDocumentStatusRequest.Merge(dst, src)
+}
+func (m *GetDocumentStatusRequest) XXX_Size() int {
+	return xxx_messageInfo_GetDocumentStatusRequest.Size(m)
+}
+func (m *GetDocumentStatusRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetDocumentStatusRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetDocumentStatusRequest proto.InternalMessageInfo
+
+func (m *GetDocumentStatusRequest) GetExecutionId() string {
+	if m != nil {
+		return m.ExecutionId
+	}
+	return ""
+}
+
+type GetDocumentStatusResponse struct {
+	ExecutionId string `protobuf:"bytes,1,opt,name=execution_id,json=executionId" json:"execution_id,omitempty"`
+	Status      string `protobuf:"bytes,2,opt,name=status" json:"status,omitempty"`
+	// result_json is the latest reply of the document, in JSON, empty until the document started.
+	ResultJson           string   `protobuf:"bytes,3,opt,name=result_json,json=resultJson" json:"result_json,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetDocumentStatusResponse) Reset()         { *m = GetDocumentStatusResponse{} }
+func (m *GetDocumentStatusResponse) String() string { return proto.CompactTextString(m) }
+func (*GetDocumentStatusResponse) ProtoMessage()    {}
+func (*GetDocumentStatusResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_localipc_36eb3758ddee93d3, []int{3}
+}
+func (m *GetDocumentStatusResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetDocumentStatusResponse.Unmarshal(m, b)
+}
+func (m *GetDocumentStatusResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetDocumentStatusResponse.Marshal(b, m, deterministic)
+}
+func (dst *GetDocumentStatusResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetDocumentStatusResponse.Merge(dst, src)
+}
+func (m *GetDocumentStatusResponse) XXX_Size() int {
+	return xxx_messageInfo_GetDocumentStatusResponse.Size(m)
+}
+func (m *GetDocumentStatusResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetDocumentStatusResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetDocumentStatusResponse proto.InternalMessageInfo
+
+func (m *GetDocumentStatusResponse) GetExecutionId() string {
+	if m != nil {
+		return m.ExecutionId
+	}
+	return ""
+}
+
+func (m *GetDocumentStatusResponse) GetStatus() string {
+	if m != nil {
+		return m.Status
+	}
+	return ""
+}
+
+func (m *GetDocumentStatusResponse) GetResultJson() string {
+	if m != nil {
+		return m.ResultJson
+	}
+	return ""
+}
+
+type CancelDocumentRequest struct {
+	ExecutionId          string   `protobuf:"bytes,1,opt,name=execution_id,json=executionId" json:"execution_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CancelDocumentRequest) Reset()         { *m = CancelDocumentRequest{} }
+func (m *CancelDocumentRequest) String() string { return proto.CompactTextString(m) }
+func (*CancelDocumentRequest) ProtoMessage()    {}
+func (*CancelDocumentRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_localipc_36eb3758ddee93d3, []int{4}
+}
+func (m *CancelDocumentRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CancelDocumentRequest.Unmarshal(m, b)
+}
+func (m *CancelDocumentRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CancelDocumentRequest.Marshal(b, m, deterministic)
+}
+func (dst *CancelDocumentRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CancelDocumentRequest.Merge(dst, src)
+}
+func (m *CancelDocumentRequest) XXX_Size() int {
+	return xxx_messageInfo_CancelDocumentRequest.Size(m)
+}
+func (m *CancelDocumentRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CancelDocumentRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CancelDocumentRequest proto.InternalMessageInfo
+
+func (m *CancelDocumentRequest) GetExecutionId() string {
+	if m != nil {
+		return m.ExecutionId
+	}
+	return ""
+}
+
+type CancelDocumentResponse struct {
+	ExecutionId          string   `protobuf:"bytes,1,opt,name=execution_id,json=executionId" json:"execution_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CancelDocumentResponse) Reset()         { *m = CancelDocumentResponse{} }
+func (m *CancelDocumentResponse) String() string { return proto.CompactTextString(m) }
+func (*CancelDocumentResponse) ProtoMessage()    {}
+func (*CancelDocumentResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_localipc_36eb3758ddee93d3, []int{5}
+}
+func (m *CancelDocumentResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CancelDocumentResponse.Unmarshal(m, b)
+}
+func (m *CancelDocumentResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CancelDocumentResponse.Marshal(b, m, deterministic)
+}
+func (dst *CancelDocumentResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CancelDocumentResponse.Merge(dst, src)
+}
+func (m *CancelDocumentResponse) XXX_Size() int {
+	return xxx_messageInfo_CancelDocumentResponse.Size(m)
+}
+func (m *CancelDocumentResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_CancelDocumentResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CancelDocumentResponse proto.InternalMessageInfo
+
+func (m *CancelDocumentResponse) GetExecutionId() string {
+	if m != nil {
+		return m.ExecutionId
+	}
+	return ""
+}
+
+type ListAssociationsRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListAssociationsRequest) Reset()         { *m = ListAssociationsRequest{} }
+func (m *ListAssociationsRequest) String() string { return proto.CompactTextString(m) }
+func (*ListAssociationsRequest) ProtoMessage()    {}
+func (*ListAssociationsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_localipc_36eb3758ddee93d3, []int{6}
+}
+func (m *ListAssociationsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListAssociationsRequest.Unmarshal(m, b)
+}
+func (m *ListAssociationsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListAssociationsRequest.Marshal(b, m, deterministic)
+}
+func (dst *ListAssociationsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListAssociationsRequest.Merge(dst, src)
+}
+func (m *ListAssociationsRequest) XXX_Size() int {
+	return xxx_messageInfo_ListAssociationsRequest.Size(m)
+}
+func (m *ListAssociationsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListAssociationsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListAssociationsRequest proto.InternalMessageInfo
+
+type ListAssociationsResponse struct {
+	Associations         []*Association `protobuf:"bytes,1,rep,name=associations" json:"associations,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *ListAssociationsResponse) Reset()         { *m = ListAssociationsResponse{} }
+func (m *ListAssociationsResponse) String() string { return proto.CompactTextString(m) }
+func (*ListAssociationsResponse) ProtoMessage()    {}
+func (*ListAssociationsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_localipc_36eb3758ddee93d3, []int{7}
+}
+func (m *ListAssociationsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListAssociationsResponse.Unmarshal(m, b)
+}
+func (m *ListAssociationsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListAssociationsResponse.Marshal(b, m, deterministic)
+}
+func (dst *ListAssociationsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListAssociationsResponse.Merge(dst, src)
+}
+func (m *ListAssociationsResponse) XXX_Size() int {
+	return xxx_messageInfo_ListAssociationsResponse.Size(m)
+}
+func (m *ListAssociationsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListAssociationsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListAssociationsResponse proto.InternalMessageInfo
+
+func (m *ListAssociationsResponse) GetAssociations() []*Association {
+	if m != nil {
+		return m.Associations
+	}
+	return nil
+}
+
+type Association struct {
+	AssociationId      string `protobuf:"bytes,1,opt,name=association_id,json=associationId" json:"association_id,omitempty"`
+	Name               string `protobuf:"bytes,2,opt,name=name" json:"name,omitempty"`
+	DocumentVersion    string `protobuf:"bytes,3,opt,name=document_version,json=documentVersion" json:"document_version,omitempty"`
+	ScheduleExpression string `protobuf:"bytes,4,opt,name=schedule_expression,json=scheduleExpression" json:"schedule_expression,omitempty"`
+	// The dates are ISO 8601 UTC, empty when unknown.
+	NextScheduledDate    string   `protobuf:"bytes,5,opt,name=next_scheduled_date,json=nextScheduledDate" json:"next_scheduled_date,omitempty"`
+	LastExecutionDate    string   `protobuf:"bytes,6,opt,name=last_execution_date,json=lastExecutionDate" json:"last_execution_date,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Association) Reset()         { *m = Association{} }
+func (m *Association) String() string { return proto.CompactTextString(m) }
+func (*Association) ProtoMessage()    {}
+func (*Association) Descriptor() ([]byte, []int) {
+	return fileDescriptor_localipc_36eb3758ddee93d3, []int{8}
+}
+func (m *Association) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Association.Unmarshal(m, b)
+}
+func (m *Association) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Association.Marshal(b, m, deterministic)
+}
+func (dst *Association) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Association.Merge(dst, src)
+}
+func (m *Association) XXX_Size() int {
+	return xxx_messageInfo_Association.Size(m)
+}
+func (m *Association) XXX_DiscardUnknown() {
+	xxx_messageInfo_Association.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Association proto.InternalMessageInfo
+
+func (m *Association) GetAssociationId() string {
+	if m != nil {
+		return m.AssociationId
+	}
+	return ""
+}
+
+func (m *Association) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Association) GetDocumentVersion() string {
+	if m != nil {
+		return m.DocumentVersion
+	}
+	return ""
+}
+
+func (m *Association) GetScheduleExpression() string {
+	if m != nil {
+		return m.ScheduleExpression
+	}
+	return ""
+}
+
+func (m *Association) GetNextScheduledDate() string {
+	if m != nil {
+		return m.NextScheduledDate
+	}
+	return ""
+}
+
+func (m *Association) GetLastExecutionDate() string {
+	if m != nil {
+		return m.LastExecutionDate
+	}
+	return ""
+}
+
+type GetExecutionHistoryRequest struct {
+	// association_id limits the history to one association, empty returns the executions of all associations.
+	AssociationId        string   `protobuf:"bytes,1,opt,name=association_id,json=associationId" json:"association_id,omitempty"`
+	MaxResults           int32    `protobuf:"varint,2,opt,name=max_results,json=maxResults" json:"max_results,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetExecutionHistoryRequest) Reset()         { *m = GetExecutionHistoryRequest{} }
+func (m *GetExecutionHistoryRequest) String() string { return proto.CompactTextString(m) }
+func (*GetExecutionHistoryRequest) ProtoMessage()    {}
+func (*GetExecutionHistoryRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_localipc_36eb3758ddee93d3, []int{9}
+}
+func (m *GetExecutionHistoryRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetExecutionHistoryRequest.Unmarshal(m, b)
+}
+func (m *GetExecutionHistoryRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetExecutionHistoryRequest.Marshal(b, m, deterministic)
+}
+func (dst *GetExecutionHistoryRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetExecutionHistoryRequest.Merge(dst, src)
+}
+func (m *GetExecutionHistoryRequest) XXX_Size() int {
+	return xxx_messageInfo_GetExecutionHistoryRequest.Size(m)
+}
+func (m *GetExecutionHistoryRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetExecutionHistoryRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetExecutionHistoryRequest proto.InternalMessageInfo
+
+func (m *GetExecutionHistoryRequest) GetAssociationId() string {
+	if m != nil {
+		return m.AssociationId
+	}
+	return ""
+}
+
+func (m *GetExecutionHistoryRequest) GetMaxResults() int32 {
+	if m != nil {
+		return m.MaxResults
+	}
+	return 0
+}
+
+type GetExecutionHistoryResponse struct {
+	Executions           []*AssociationExecution `protobuf:"bytes,1,rep,name=executions" json:"executions,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                `json:"-"`
+	XXX_unrecognized     []byte                  `json:"-"`
+	XXX_sizecache        int32                   `json:"-"`
+}
+
+func (m *GetExecutionHistoryResponse) Reset()         { *m = GetExecutionHistoryResponse{} }
+func (m *GetExecutionHistoryResponse) String() string { return proto.CompactTextString(m) }
+func (*GetExecutionHistoryResponse) ProtoMessage()    {}
+func (*GetExecutionHistoryResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_localipc_36eb3758ddee93d3, []int{10}
+}
+func (m *GetExecutionHistoryResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetExecutionHistoryResponse.Unmarshal(m, b)
+}
+func (m *GetExecutionHistoryResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetExecutionHistoryResponse.Marshal(b, m, deterministic)
+}
+func (dst *GetExecutionHistoryResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetExecutionHistoryResponse.Merge(dst, src)
+}
+func (m *GetExecutionHistoryResponse) XXX_Size() int {
+	return xxx_messageInfo_GetExecutionHistoryResponse.Size(m)
+}
+func (m *GetExecutionHistoryResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetExecutionHistoryResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetExecutionHistoryResponse proto.InternalMessageInfo
+
+func (m *GetExecutionHistoryResponse) GetExecutions() []*AssociationExecution {
+	if m != nil {
+		return m.Executions
+	}
+	return nil
+}
+
+type AssociationExecution struct {
+	AssociationId        string             `protobuf:"bytes,1,opt,name=association_id,json=associationId" json:"association_id,omitempty"`
+	DocumentName         string             `protobuf:"bytes,2,opt,name=document_name,json=documentName" json:"document_name,omitempty"`
+	DocumentVersion      string             `protobuf:"bytes,3,opt,name=document_version,json=documentVersion" json:"document_version,omitempty"`
+	ExecutionId          string             `protobuf:"bytes,4,opt,name=execution_id,json=executionId" json:"execution_id,omitempty"`
+	Status               string             `protobuf:"bytes,5,opt,name=status" json:"status,omitempty"`
+	StartDateTime        string             `protobuf:"bytes,6,opt,name=start_date_time,json=startDateTime" json:"start_date_time,omitempty"`
+	EndDateTime          string             `protobuf:"bytes,7,opt,name=end_date_time,json=endDateTime" json:"end_date_time,omitempty"`
+	DurationSeconds      float64            `protobuf:"fixed64,8,opt,name=duration_seconds,json=durationSeconds" json:"duration_seconds,omitempty"`
+	PluginResults        []*PluginExecution `protobuf:"bytes,9,rep,name=plugin_results,json=pluginResults" json:"plugin_results,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
+	XXX_unrecognized     []byte             `json:"-"`
+	XXX_sizecache        int32              `json:"-"`
+}
+
+func (m *AssociationExecution) Reset()         { *m = AssociationExecution{} }
+func (m *AssociationExecution) String() string { return proto.CompactTextString(m) }
+func (*AssociationExecution) ProtoMessage()    {}
+func (*AssociationExecution) Descriptor() ([]byte, []int) {
+	return fileDescriptor_localipc_36eb3758ddee93d3, []int{11}
+}
+func (m *AssociationExecution) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AssociationExecution.Unmarshal(m, b)
+}
+func (m *AssociationExecution) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AssociationExecution.Marshal(b, m, deterministic)
+}
+func (dst *AssociationExecution) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AssociationExecution.Merge(dst, src)
+}
+func (m *AssociationExecution) XXX_Size() int {
+	return xxx_messageInfo_AssociationExecution.Size(m)
+}
+func (m *AssociationExecution) XXX_DiscardUnknown() {
+	xxx_messageInfo_AssociationExecution.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AssociationExecution proto.InternalMessageInfo
+
+func (m *AssociationExecution) GetAssociationId() string {
+	if m != nil {
+		return m.AssociationId
+	}
+	return ""
+}
+
+func (m *AssociationExecution) GetDocumentName() string {
+	if m != nil {
+		return m.DocumentName
+	}
+	return ""
+}
+
+func (m *AssociationExecution) GetDocumentVersion() string {
+	if m != nil {
+		return m.DocumentVersion
+	}
+	return ""
+}
+
+func (m *AssociationExecution) GetExecutionId() string {
+	if m != nil {
+		return m.ExecutionId
+	}
+	return ""
+}
+
+func (m *AssociationExecution) GetStatus() string {
+	if m != nil {
+		return m.Status
+	}
+	return ""
+}
+
+func (m *AssociationExecution) GetStartDateTime() string {
+	if m != nil {
+		return m.StartDateTime
+	}
+	return ""
+}
+
+func (m *AssociationExecution) GetEndDateTime() string {
+	if m != nil {
+		return m.EndDateTime
+	}
+	return ""
+}
+
+func (m *AssociationExecution) GetDurationSeconds() float64 {
+	if m != nil {
+		return m.DurationSeconds
+	}
+	return 0
+}
+
+func (m *AssociationExecution) GetPluginResults() []*PluginExecution {
+	if m != nil {
+		return m.PluginResults
+	}
+	return nil
+}
+
+type PluginExecution struct {
+	PluginId             string   `protobuf:"bytes,1,opt,name=plugin_id,json=pluginId" json:"plugin_id,omitempty"`
+	PluginName           string   `protobuf:"bytes,2,opt,name=plugin_name,json=pluginName" json:"plugin_name,omitempty"`
+	Status               string   `protobuf:"bytes,3,opt,name=status" json:"status,omitempty"`
+	Code                 int32    `protobuf:"varint,4,opt,name=code" json:"code,omitempty"`
+	Error                string   `protobuf:"bytes,5,opt,name=error" json:"error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PluginExecution) Reset()         { *m = PluginExecution{} }
+func (m *PluginExecution) String() string { return proto.CompactTextString(m) }
+func (*PluginExecution) ProtoMessage()    {}
+func (*PluginExecution) Descriptor() ([]byte, []int) {
+	return fileDescriptor_localipc_36eb3758ddee93d3, []int{12}
+}
+func (m *PluginExecution) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PluginExecution.Unmarshal(m, b)
+}
+func (m *PluginExecution) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PluginExecution.Marshal(b, m, deterministic)
+}
+func (dst *PluginExecution) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PluginExecution.Merge(dst, src)
+}
+func (m *PluginExecution) XXX_Size() int {
+	return xxx_messageInfo_PluginExecution.Size(m)
+}
+func (m *PluginExecution) XXX_DiscardUnknown() {
+	xxx_messageInfo_PluginExecution.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PluginExecution proto.InternalMessageInfo
+
+func (m *PluginExecution) GetPluginId() string {
+	if m != nil {
+		return m.PluginId
+	}
+	return ""
+}
+
+func (m *PluginExecution) GetPluginName() string {
+	if m != nil {
+		return m.PluginName
+	}
+	return ""
+}
+
+func (m *PluginExecution) GetStatus() string {
+	if m != nil {
+		return m.Status
+	}
+	return ""
+}
+
+func (m *PluginExecution) GetCode() int32 {
+	if m != nil {
+		return m.Code
+	}
+	return 0
+}
+
+func (m *PluginExecution) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+type GetHealthRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetHealthRequest) Reset()         { *m = GetHealthRequest{} }
+func (m *GetHealthRequest) String() string { return proto.CompactTextString(m) }
+func (*GetHealthRequest) ProtoMessage()    {}
+func (*GetHealthRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_localipc_36eb3758ddee93d3, []int{13}
+}
+func (m *GetHealthRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetHealthRequest.Unmarshal(m, b)
+}
+func (m *GetHealthRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetHealthRequest.Marshal(b, m, deterministic)
+}
+func (dst *GetHealthRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetHealthRequest.Merge(dst, src)
+}
+func (m *GetHealthRequest) XXX_Size() int {
+	return xxx_messageInfo_GetHealthRequest.Size(m)
+}
+func (m *GetHealthRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetHealthRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetHealthRequest proto.InternalMessageInfo
+
+type GetHealthResponse struct {
+	AgentName            string   `protobuf:"bytes,1,opt,name=agent_name,json=agentName" json:"agent_name,omitempty"`
+	AgentVersion         string   `protobuf:"bytes,2,opt,name=agent_version,json=agentVersion" json:"agent_version,omitempty"`
+	InstanceId           string   `protobuf:"bytes,3,opt,name=instance_id,json=instanceId" json:"instance_id,omitempty"`
+	StartTime            string   `protobuf:"bytes,4,opt,name=start_time,json=startTime" json:"start_time,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetHealthResponse) Reset()         { *m = GetHealthResponse{} }
+func (m *GetHealthResponse) String() string { return proto.CompactTextString(m) }
+func (*GetHealthResponse) ProtoMessage()    {}
+func (*GetHealthResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_localipc_36eb3758ddee93d3, []int{14}
+}
+func (m *GetHealthResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetHealthResponse.Unmarshal(m, b)
+}
+func (m *GetHealthResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetHealthResponse.Marshal(b, m, deterministic)
+}
+func (dst *GetHealthResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetHealthResponse.Merge(dst, src)
+}
+func (m *GetHealthResponse) XXX_Size() int {
+	return xxx_messageInfo_GetHealthResponse.Size(m)
+}
+func (m *GetHealthResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetHealthResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetHealthResponse proto.InternalMessageInfo
+
+func (m *GetHealthResponse) GetAgentName() string {
+	if m != nil {
+		return m.AgentName
+	}
+	return ""
+}
+
+func (m *GetHealthResponse) GetAgentVersion() string {
+	if m != nil {
+		return m.AgentVersion
+	}
+	return ""
+}
+
+func (m *GetHealthResponse) GetInstanceId() string {
+	if m != nil {
+		return m.InstanceId
+	}
+	return ""
+}
+
+func (m *GetHealthResponse) GetStartTime() string {
+	if m != nil {
+		return m.StartTime
+	}
+	return ""
+}
+
+type GetMetricsRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetMetricsRequest) Reset()         { *m = GetMetricsRequest{} }
+func (m *GetMetricsRequest) String() string { return proto.CompactTextString(m) }
+func (*GetMetricsRequest) ProtoMessage()    {}
+func (*GetMetricsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_localipc_36eb3758ddee93d3, []int{15}
+}
+func (m *GetMetricsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetMetricsRequest.Unmarshal(m, b)
+}
+func (m *GetMetricsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetMetricsRequest.Marshal(b, m, deterministic)
+}
+func (dst *GetMetricsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetMetricsRequest.Merge(dst, src)
+}
+func (m *GetMetricsRequest) XXX_Size() int {
+	return xxx_messageInfo_GetMetricsRequest.Size(m)
+}
+func (m *GetMetricsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetMetricsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetMetricsRequest proto.InternalMessageInfo
+
+type GetMetricsResponse struct {
+	Counters             map[string]int64      `protobuf:"bytes,1,rep,name=counters" json:"counters,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	Histograms           map[string]*Histogram `protobuf:"bytes,2,rep,name=histograms" json:"histograms,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *GetMetricsResponse) Reset()         { *m = GetMetricsResponse{} }
+func (m *GetMetricsResponse) String() string { return proto.CompactTextString(m) }
+func (*GetMetricsResponse) ProtoMessage()    {}
+func (*GetMetricsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_localipc_36eb3758ddee93d3, []int{16}
+}
+func (m *GetMetricsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetMetricsResponse.Unmarshal(m, b)
+}
+func (m *GetMetricsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetMetricsResponse.Marshal(b, m, deterministic)
+}
+func (dst *GetMetricsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetMetricsResponse.Merge(dst, src)
+}
+func (m *GetMetricsResponse) XXX_Size() int {
+	return xxx_messageInfo_GetMetricsResponse.Size(m)
+}
+func (m *GetMetricsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetMetricsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetMetricsResponse proto.InternalMessageInfo
+
+func (m *GetMetricsResponse) GetCounters() map[string]int64 {
+	if m != nil {
+		return m.Counters
+	}
+	return nil
+}
+
+func (m *GetMetricsResponse) GetHistograms() map[string]*Histogram {
+	if m != nil {
+		return m.Histograms
+	}
+	return nil
+}
+
+type Histogram struct {
+	Count               int64   `protobuf:"varint,1,opt,name=count" json:"count,omitempty"`
+	SumMilliseconds     int64   `protobuf:"varint,2,opt,name=sum_milliseconds,json=sumMilliseconds" json:"sum_milliseconds,omitempty"`
+	BucketsMilliseconds []int64 `protobuf:"varint,3,rep,packed,name=buckets_milliseconds,json=bucketsMilliseconds" json:"buckets_milliseconds,omitempty"`
+	// bucket_counts has one more element than buckets_milliseconds, the latencies above the last bound.
+	BucketCounts         []int64  `protobuf:"varint,4,rep,packed,name=bucket_counts,json=bucketCounts" json:"bucket_counts,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Histogram) Reset()         { *m = Histogram{} }
+func (m *Histogram) String() string { return proto.CompactTextString(m) }
+func (*Histogram) ProtoMessage()    {}
+func (*Histogram) Descriptor() ([]byte, []int) {
+	return fileDescriptor_localipc_36eb3758ddee93d3, []int{17}
+}
+func (m *Histogram) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Histogram.Unmarshal(m, b)
+}
+func (m *Histogram) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Histogram.Marshal(b, m, deterministic)
+}
+func (dst *Histogram) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Histogram.Merge(dst, src)
+}
+func (m *Histogram) XXX_Size() int {
+	return xxx_messageInfo_Histogram.Size(m)
+}
+func (m *Histogram) XXX_DiscardUnknown() {
+	xxx_messageInfo_Histogram.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Histogram proto.InternalMessageInfo
+
+func (m *Histogram) GetCount() int64 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
+func (m *Histogram) GetSumMilliseconds() int64 {
+	if m != nil {
+		return m.SumMilliseconds
+	}
+	return 0
+}
+
+func (m *Histogram) GetBucketsMilliseconds() []int64 {
+	if m != nil {
+		return m.BucketsMilliseconds
+	}
+	return nil
+}
+
+func (m *Histogram) GetBucketCounts() []int64 {
+	if m != nil {
+		return m.BucketCounts
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*SubmitDocumentRequest)(nil), "aws.ssm.localipc.v1.SubmitDocumentRequest")
+	proto.RegisterType((*SubmitDocumentResponse)(nil), "aws.ssm.localipc.v1.SubmitDocumentResponse")
+	proto.RegisterType((*GetDocumentStatusRequest)(nil), "aws.ssm.localipc.v1.GetDocumentStatusRequest")
+	proto.RegisterType((*GetDocumentStatusResponse)(nil), "aws.ssm.localipc.v1.GetDocumentStatusResponse")
+	proto.RegisterType((*CancelDocumentRequest)(nil), "aws.ssm.localipc.v1.CancelDocumentRequest")
+	proto.RegisterType((*CancelDocumentResponse)(nil), "aws.ssm.localipc.v1.CancelDocumentResponse")
+	proto.RegisterType((*ListAssociationsRequest)(nil), "aws.ssm.localipc.v1.ListAssociationsRequest")
+	proto.RegisterType((*ListAssociationsResponse)(nil), "aws.ssm.localipc.v1.ListAssociationsResponse")
+	proto.RegisterType((*Association)(nil), "aws.ssm.localipc.v1.Association")
+	proto.RegisterType((*GetExecutionHistoryRequest)(nil), "aws.ssm.localipc.v1.GetExecutionHistoryRequest")
+	proto.RegisterType((*GetExecutionHistoryResponse)(nil), "aws.ssm.localipc.v1.GetExecutionHistoryResponse")
+	proto.RegisterType((*AssociationExecution)(nil), "aws.ssm.localipc.v1.AssociationExecution")
+	proto.RegisterType((*PluginExecution)(nil), "aws.ssm.localipc.v1.PluginExecution")
+	proto.RegisterType((*GetHealthRequest)(nil), "aws.ssm.localipc.v1.GetHealthRequest")
+	proto.RegisterType((*GetHealthResponse)(nil), "aws.ssm.localipc.v1.GetHealthResponse")
+	proto.RegisterType((*GetMetricsRequest)(nil), "aws.ssm.localipc.v1.GetMetricsRequest")
+	proto.RegisterType((*GetMetricsResponse)(nil), "aws.ssm.localipc.v1.GetMetricsResponse")
+	proto.RegisterMapType((map[string]int64)(nil), "aws.ssm.localipc.v1.GetMetricsResponse.CountersEntry")
+	proto.RegisterMapType((map[string]*Histogram)(nil), "aws.ssm.localipc.v1.GetMetricsResponse.HistogramsEntry")
+	proto.RegisterType((*Histogram)(nil), "aws.ssm.localipc.v1.Histogram")
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// Client API for LocalControl service
+
+type LocalControlClient interface {
+	// SubmitDocument runs a command document and returns its execution ID.
+	SubmitDocument(ctx context.Context, in *SubmitDocumentRequest, opts ...grpc.CallOption) (*SubmitDocumentResponse, error)
+	// GetDocumentStatus returns the status of a submitted document and its latest result.
+	GetDocumentStatus(ctx context.Context, in *GetDocumentStatusRequest, opts ...grpc.CallOption) (*GetDocumentStatusResponse, error)
+	// CancelDocument cancels a submitted document that didn't complete yet.
+	CancelDocument(ctx context.Context, in *CancelDocumentRequest, opts ...grpc.CallOption) (*CancelDocumentResponse, error)
+	// ListAssociations returns the associations scheduled on the instance.
+	ListAssociations(ctx context.Context, in *ListAssociationsRequest, opts ...grpc.CallOption) (*ListAssociationsResponse, error)
+	// GetExecutionHistory returns the latest executions of the associations, or of one association.
+	GetExecutionHistory(ctx context.Context, in *GetExecutionHistoryRequest, opts ...grpc.CallOption) (*GetExecutionHistoryResponse, error)
+	// GetHealth returns the version and the start time of the agent.
+	GetHealth(ctx context.Context, in *GetHealthRequest, opts ...grpc.CallOption) (*GetHealthResponse, error)
+	// GetMetrics returns the message and reply metrics of the channels.
+	GetMetrics(ctx context.Context, in *GetMetricsRequest, opts ...grpc.CallOption) (*GetMetricsResponse, error)
+}
+
+type localControlClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewLocalControlClient(cc *grpc.ClientConn) LocalControlClient {
+	return &localControlClient{cc}
+}
+
+func (c *localControlClient) SubmitDocument(ctx context.Context, in *SubmitDocumentRequest, opts ...grpc.CallOption) (*SubmitDocumentResponse, error) {
+	out := new(SubmitDocumentResponse)
+	err := grpc.Invoke(ctx, "/aws.ssm.localipc.v1.LocalControl/SubmitDocument", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *localControlClient) GetDocumentStatus(ctx context.Context, in *GetDocumentStatusRequest, opts ...grpc.CallOption) (*GetDocumentStatusResponse, error) {
+	out := new(GetDocumentStatusResponse)
+	err := grpc.Invoke(ctx, "/aws.ssm.localipc.v1.LocalControl/GetDocumentStatus", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *localControlClient) CancelDocument(ctx context.Context, in *CancelDocumentRequest, opts ...grpc.CallOption) (*CancelDocumentResponse, error) {
+	out := new(CancelDocumentResponse)
+	err := grpc.Invoke(ctx, "/aws.ssm.localipc.v1.LocalControl/CancelDocument", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *localControlClient) ListAssociations(ctx context.Context, in *ListAssociationsRequest, opts ...grpc.CallOption) (*ListAssociationsResponse, error) {
+	out := new(ListAssociationsResponse)
+	err := grpc.Invoke(ctx, "/aws.ssm.localipc.v1.LocalControl/ListAssociations", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *localControlClient) GetExecutionHistory(ctx context.Context, in *GetExecutionHistoryRequest, opts ...grpc.CallOption) (*GetExecutionHistoryResponse, error) {
+	out := new(GetExecutionHistoryResponse)
+	err := grpc.Invoke(ctx, "/aws.ssm.localipc.v1.LocalControl/GetExecutionHistory", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *localControlClient) GetHealth(ctx context.Context, in *GetHealthRequest, opts ...grpc.CallOption) (*GetHealthResponse, error) {
+	out := new(GetHealthResponse)
+	err := grpc.Invoke(ctx, "/aws.ssm.localipc.v1.LocalControl/GetHealth", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *localControlClient) GetMetrics(ctx context.Context, in *GetMetricsRequest, opts ...grpc.CallOption) (*GetMetricsResponse, error) {
+	out := new(GetMetricsResponse)
+	err := grpc.Invoke(ctx, "/aws.ssm.localipc.v1.LocalControl/GetMetrics", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Server API for LocalControl service
+
+type LocalControlServer interface {
+	// SubmitDocument runs a command document and returns its execution ID.
+	SubmitDocument(context.Context, *SubmitDocumentRequest) (*SubmitDocumentResponse, error)
+	// GetDocumentStatus returns the status of a submitted document and its latest result.
+	GetDocumentStatus(context.Context, *GetDocumentStatusRequest) (*GetDocumentStatusResponse, error)
+	// CancelDocument cancels a submitted document that didn't complete yet.
+	CancelDocument(context.Context, *CancelDocumentRequest) (*CancelDocumentResponse, error)
+	// ListAssociations returns the associations scheduled on the instance.
+	ListAssociations(context.Context, *ListAssociationsRequest) (*ListAssociationsResponse, error)
+	// GetExecutionHistory returns the latest executions of the associations, or of one association.
+	GetExecutionHistory(context.Context, *GetExecutionHistoryRequest) (*GetExecutionHistoryResponse, error)
+	// GetHealth returns the version and the start time of the agent.
+	GetHealth(context.Context, *GetHealthRequest) (*GetHealthResponse, error)
+	// GetMetrics returns the message and reply metrics of the channels.
+	GetMetrics(context.Context, *GetMetricsRequest) (*GetMetricsResponse, error)
+}
+
+func RegisterLocalControlServer(s *grpc.Server, srv LocalControlServer) {
+	s.RegisterService(&_LocalControl_serviceDesc, srv)
+}
+
+func _LocalControl_SubmitDocument_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubmitDocumentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LocalControlServer).SubmitDocument(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/aws.ssm.localipc.v1.LocalControl/SubmitDocument",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LocalControlServer).SubmitDocument(ctx, req.(*SubmitDocumentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LocalControl_GetDocumentStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDocumentStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LocalControlServer).GetDocumentStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/aws.ssm.localipc.v1.LocalControl/GetDocumentStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LocalControlServer).GetDocumentStatus(ctx, req.(*GetDocumentStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LocalControl_CancelDocument_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelDocumentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LocalControlServer).CancelDocument(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/aws.ssm.localipc.v1.LocalControl/CancelDocument",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LocalControlServer).CancelDocument(ctx, req.(*CancelDocumentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LocalControl_ListAssociations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAssociationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LocalControlServer).ListAssociations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/aws.ssm.localipc.v1.LocalControl/ListAssociations",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LocalControlServer).ListAssociations(ctx, req.(*ListAssociationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LocalControl_GetExecutionHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetExecutionHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LocalControlServer).GetExecutionHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/aws.ssm.localipc.v1.LocalControl/GetExecutionHistory",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LocalControlServer).GetExecutionHistory(ctx, req.(*GetExecutionHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LocalControl_GetHealth_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetHealthRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LocalControlServer).GetHealth(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/aws.ssm.localipc.v1.LocalControl/GetHealth",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LocalControlServer).GetHealth(ctx, req.(*GetHealthRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LocalControl_GetMetrics_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMetricsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LocalControlServer).GetMetrics(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/aws.ssm.localipc.v1.LocalControl/GetMetrics",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LocalControlServer).GetMetrics(ctx, req.(*GetMetricsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _LocalControl_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "aws.ssm.localipc.v1.LocalControl",
+	HandlerType: (*LocalControlServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SubmitDocument",
+			Handler:    _LocalControl_SubmitDocument_Handler,
+		},
+		{
+			MethodName: "GetDocumentStatus",
+			Handler:    _LocalControl_GetDocumentStatus_Handler,
+		},
+		{
+			MethodName: "CancelDocument",
+			Handler:    _LocalControl_CancelDocument_Handler,
+		},
+		{
+			MethodName: "ListAssociations",
+			Handler:    _LocalControl_ListAssociations_Handler,
+		},
+		{
+			MethodName: "GetExecutionHistory",
+			Handler:    _LocalControl_GetExecutionHistory_Handler,
+		},
+		{
+			MethodName: "GetHealth",
+			Handler:    _LocalControl_GetHealth_Handler,
+		},
+		{
+			MethodName: "GetMetrics",
+			Handler:    _LocalControl_GetMetrics_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "localipc.proto",
+}
+
+func init() { proto.RegisterFile("localipc.proto", fileDescriptor_localipc_36eb3758ddee93d3) }
+
+var fileDescriptor_localipc_36eb3758ddee93d3 = []byte{
+	// 1024 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x57, 0x5b, 0x73, 0xdb, 0x44,
+	0x14, 0x1e, 0x45, 0x76, 0x88, 0x8f, 0x6f, 0xe9, 0x3a, 0x0d, 0xae, 0x3a, 0x10, 0xa3, 0xd2, 0x90,
+	0x52, 0xea, 0x92, 0x00, 0x03, 0xd3, 0x0e, 0x0f, 0x90, 0x64, 0x92, 0x40, 0xcb, 0x80, 0xcc, 0xc0,
+	0x4c, 0x67, 0x3a, 0x62, 0x23, 0xed, 0x38, 0xa2, 0xba, 0x18, 0xed, 0x2a, 0x75, 0xde, 0x79, 0xe4,
+	0x85, 0x27, 0xfe, 0x00, 0x3f, 0x8b, 0xdf, 0xc2, 0x30, 0x7b, 0x93, 0x25, 0x47, 0x26, 0x62, 0x78,
+	0xb3, 0xbe, 0xf3, 0x9d, 0x8b, 0xce, 0x77, 0xf6, 0x68, 0x0d, 0xbd, 0x30, 0xf1, 0x70, 0x18, 0xcc,
+	0xbc, 0xf1, 0x2c, 0x4d, 0x58, 0x82, 0x06, 0xf8, 0x35, 0x1d, 0x53, 0x1a, 0x8d, 0x73, 0xfc, 0x72,
+	0xdf, 0xc6, 0x70, 0x7b, 0x92, 0x9d, 0x47, 0x01, 0x3b, 0x4a, 0xbc, 0x2c, 0x22, 0x31, 0x73, 0xc8,
+	0x2f, 0x19, 0xa1, 0x0c, 0xdd, 0x83, 0xae, 0xaf, 0x20, 0x37, 0xc6, 0x11, 0x19, 0x1a, 0x23, 0x63,
+	0xaf, 0xe5, 0x74, 0x34, 0xf8, 0x0d, 0x8e, 0x48, 0x89, 0xf4, 0x33, 0x4d, 0xe2, 0xe1, 0x5a, 0x99,
+	0xf4, 0x15, 0x4d, 0x62, 0x7b, 0x02, 0xdb, 0xcb, 0x29, 0xe8, 0x2c, 0x89, 0x29, 0x41, 0xef, 0x40,
+	0x87, 0xcc, 0x89, 0x97, 0xb1, 0x20, 0x89, 0xdd, 0xc0, 0x57, 0x29, 0xda, 0x39, 0x76, 0xe6, 0xa3,
+	0x6d, 0x58, 0xa7, 0x0c, 0xb3, 0x8c, 0xaa, 0xd0, 0xea, 0xc9, 0xfe, 0x1c, 0x86, 0x27, 0x24, 0x8f,
+	0x38, 0x11, 0xa0, 0x2e, 0xfd, 0xe6, 0xb0, 0xf6, 0x6b, 0xb8, 0x53, 0xe1, 0xfe, 0xbf, 0xcb, 0x42,
+	0x3b, 0xd0, 0x4e, 0x09, 0xcd, 0x42, 0xd5, 0x0e, 0x53, 0x18, 0x41, 0x42, 0xa2, 0x19, 0x4f, 0xe0,
+	0xf6, 0x21, 0x8e, 0x3d, 0x12, 0x2e, 0xf7, 0xbb, 0x46, 0xd1, 0x4f, 0x61, 0x7b, 0xd9, 0xb7, 0x76,
+	0xc5, 0xf6, 0x1d, 0x78, 0xf3, 0x59, 0x40, 0xd9, 0x17, 0x94, 0x26, 0x5e, 0x80, 0x39, 0xa8, 0xfb,
+	0x65, 0xff, 0x04, 0xc3, 0xeb, 0x26, 0x15, 0xf9, 0x08, 0x3a, 0xb8, 0x80, 0x0f, 0x8d, 0x91, 0xb9,
+	0xd7, 0x3e, 0x18, 0x8d, 0x2b, 0x66, 0x69, 0x5c, 0x08, 0xe0, 0x94, 0xbc, 0xec, 0x5f, 0xd7, 0xa0,
+	0x5d, 0xb0, 0xa2, 0xfb, 0xd0, 0x2b, 0xd8, 0x17, 0x15, 0x77, 0x0b, 0xe8, 0x99, 0x8f, 0x10, 0x34,
+	0xc4, 0xe8, 0xc9, 0x1e, 0x8b, 0xdf, 0xe8, 0x01, 0x6c, 0xe6, 0x23, 0x77, 0x49, 0x52, 0x1a, 0xe4,
+	0x6d, 0xee, 0x6b, 0xfc, 0x07, 0x09, 0xa3, 0xc7, 0x30, 0xa0, 0xde, 0x05, 0xf1, 0xb3, 0x90, 0xb8,
+	0x64, 0x3e, 0x4b, 0x09, 0x15, 0xec, 0x86, 0x60, 0x23, 0x6d, 0x3a, 0xce, 0x2d, 0x68, 0x0c, 0x83,
+	0x98, 0xcc, 0x99, 0xab, 0x4d, 0xbe, 0xeb, 0x63, 0x46, 0x86, 0x4d, 0xe1, 0x70, 0x8b, 0x9b, 0x26,
+	0xda, 0x72, 0x84, 0x19, 0xe1, 0xfc, 0x10, 0x53, 0xe6, 0x2e, 0x7a, 0x2f, 0xf8, 0xeb, 0x92, 0xcf,
+	0x4d, 0xc7, 0xda, 0xc2, 0xf9, 0xb6, 0x0f, 0xd6, 0x09, 0x59, 0x60, 0xa7, 0x01, 0x65, 0x49, 0x7a,
+	0xa5, 0x27, 0xa0, 0x66, 0x53, 0x76, 0xa0, 0x1d, 0xe1, 0xb9, 0x2b, 0x67, 0x4a, 0xce, 0x5f, 0xd3,
+	0x81, 0x08, 0xcf, 0x1d, 0x89, 0xd8, 0x17, 0x70, 0xb7, 0x32, 0x8b, 0x52, 0xf4, 0x0c, 0x20, 0xaf,
+	0x57, 0xeb, 0xf9, 0xe0, 0x26, 0x3d, 0xf3, 0x68, 0x4e, 0xc1, 0xd9, 0xfe, 0xcd, 0x84, 0xad, 0x2a,
+	0x52, 0xdd, 0x57, 0xb9, 0xb6, 0x63, 0xd6, 0x2a, 0x76, 0xcc, 0x7f, 0x10, 0x7c, 0xf9, 0x18, 0x34,
+	0xfe, 0xed, 0xe0, 0x36, 0x4b, 0x07, 0x77, 0x17, 0xfa, 0x94, 0xe1, 0x94, 0x09, 0x05, 0x5d, 0x16,
+	0x44, 0x5a, 0xc6, 0xae, 0x80, 0xb9, 0x7c, 0xdf, 0x07, 0x11, 0x41, 0x36, 0x74, 0x49, 0xec, 0x17,
+	0x58, 0x6f, 0xa8, 0x1c, 0xb1, 0x9f, 0x73, 0x78, 0xc5, 0x59, 0x2a, 0x5f, 0x9d, 0x12, 0x2f, 0x89,
+	0x7d, 0x3a, 0xdc, 0x18, 0x19, 0x7b, 0x86, 0xd3, 0xd7, 0xf8, 0x44, 0xc2, 0xe8, 0x6b, 0xe8, 0xcd,
+	0xc2, 0x6c, 0x1a, 0xc4, 0xb9, 0x9e, 0x2d, 0x21, 0xc8, 0xbb, 0x95, 0x82, 0x7c, 0x2b, 0xa8, 0x0b,
+	0x2d, 0xba, 0xd2, 0x57, 0x0b, 0xff, 0xbb, 0x01, 0xfd, 0x25, 0x0a, 0xba, 0x0b, 0x2d, 0x95, 0x20,
+	0x17, 0x61, 0x43, 0x02, 0x72, 0x94, 0x94, 0xb1, 0xd0, 0x7d, 0x90, 0x90, 0xe8, 0xfd, 0xa2, 0x5b,
+	0x66, 0xa9, 0x5b, 0x08, 0x1a, 0x5e, 0xe2, 0x13, 0xd1, 0xe0, 0xa6, 0x23, 0x7e, 0xa3, 0x2d, 0x68,
+	0x92, 0x34, 0x4d, 0x52, 0xd5, 0x58, 0xf9, 0x60, 0x23, 0xd8, 0x3c, 0x21, 0xec, 0x94, 0xe0, 0x90,
+	0x5d, 0xe8, 0x7d, 0xf3, 0x87, 0x01, 0xb7, 0x0a, 0xa0, 0x9a, 0xcb, 0xb7, 0x00, 0xf0, 0x74, 0xe9,
+	0x6b, 0xd3, 0x12, 0x88, 0xfe, 0xd4, 0xe0, 0x69, 0x71, 0x06, 0xd4, 0xac, 0xe0, 0x69, 0x61, 0x00,
+	0x76, 0xa0, 0x1d, 0xc4, 0x94, 0xf1, 0x25, 0xc9, 0xdf, 0x57, 0xad, 0x5f, 0x0d, 0x9d, 0xf9, 0x3c,
+	0x89, 0x94, 0x59, 0x68, 0x27, 0xe7, 0xa3, 0x25, 0x10, 0xae, 0x9c, 0x3d, 0x10, 0x85, 0x3d, 0x27,
+	0x2c, 0x0d, 0xbc, 0x7c, 0x3d, 0xfe, 0xb5, 0x06, 0xa8, 0x88, 0xaa, 0x7a, 0xbf, 0x83, 0x0d, 0x2f,
+	0xc9, 0x62, 0x46, 0x52, 0x7d, 0x8a, 0x3e, 0xa9, 0x14, 0xed, 0xba, 0xeb, 0xf8, 0x50, 0xf9, 0x1d,
+	0xc7, 0x2c, 0xbd, 0x72, 0xf2, 0x30, 0xe8, 0x47, 0x80, 0x0b, 0x7e, 0x5a, 0xa7, 0x29, 0x8e, 0xf8,
+	0xc9, 0xe6, 0x41, 0x3f, 0xad, 0x1b, 0xf4, 0x34, 0xf7, 0x94, 0x61, 0x0b, 0xa1, 0xac, 0xa7, 0xd0,
+	0x2d, 0xe5, 0x44, 0x9b, 0x60, 0xbe, 0x22, 0x57, 0xaa, 0xcb, 0xfc, 0x27, 0x97, 0xef, 0x12, 0x87,
+	0x99, 0x9c, 0x02, 0xd3, 0x91, 0x0f, 0x4f, 0xd6, 0x3e, 0x33, 0xac, 0x97, 0xd0, 0x5f, 0x8a, 0x5d,
+	0xe1, 0xfe, 0x71, 0xd1, 0xbd, 0x7d, 0xf0, 0x76, 0x65, 0xd5, 0x79, 0x98, 0x42, 0x78, 0xfb, 0x4f,
+	0x03, 0x5a, 0xb9, 0x81, 0x97, 0x21, 0xda, 0x21, 0x62, 0x9b, 0x8e, 0x7c, 0xe0, 0x27, 0x8a, 0x66,
+	0x91, 0x1b, 0x05, 0x61, 0x18, 0xe8, 0x13, 0x25, 0xeb, 0xec, 0xd3, 0x2c, 0x7a, 0x5e, 0x80, 0xd1,
+	0x3e, 0x6c, 0x9d, 0x67, 0xde, 0x2b, 0xc2, 0x68, 0x99, 0x6e, 0x8e, 0xcc, 0x3d, 0xd3, 0x19, 0x28,
+	0x5b, 0xc9, 0xe5, 0x1e, 0x74, 0x25, 0xec, 0x8a, 0x6c, 0x74, 0xd8, 0x10, 0xdc, 0x8e, 0x04, 0x45,
+	0xe3, 0xe8, 0xc1, 0xdf, 0x4d, 0xe8, 0x3c, 0xe3, 0xef, 0x72, 0x98, 0xc4, 0x2c, 0x4d, 0x42, 0x14,
+	0x40, 0xaf, 0x7c, 0xad, 0x41, 0xef, 0x57, 0xbe, 0x74, 0xe5, 0xf5, 0xca, 0x7a, 0x58, 0x8b, 0xab,
+	0x46, 0x2d, 0x15, 0x63, 0x59, 0xbe, 0xad, 0xa0, 0x47, 0xab, 0x06, 0xa3, 0xf2, 0x52, 0x64, 0x8d,
+	0xeb, 0xd2, 0x55, 0xce, 0x00, 0x7a, 0xe5, 0xcb, 0xc6, 0x8a, 0xd7, 0xab, 0xbc, 0xcd, 0x58, 0x0f,
+	0x6b, 0x71, 0x55, 0xaa, 0x04, 0x36, 0x97, 0xef, 0x1f, 0xe8, 0x83, 0xca, 0x00, 0x2b, 0x6e, 0x30,
+	0xd6, 0xa3, 0x9a, 0x6c, 0x95, 0x70, 0x0e, 0x83, 0x8a, 0x2f, 0x24, 0x7a, 0xbc, 0xaa, 0x45, 0x2b,
+	0xbe, 0xd8, 0xd6, 0x87, 0xf5, 0x1d, 0x54, 0xe6, 0x17, 0xd0, 0xca, 0x37, 0x1f, 0xba, 0xbf, 0xca,
+	0xbd, 0xb4, 0x2e, 0xad, 0xdd, 0x9b, 0x68, 0x2a, 0xf6, 0x4b, 0x80, 0xc5, 0x5a, 0x40, 0xbb, 0x37,
+	0xee, 0x0d, 0x19, 0xfd, 0xbd, 0x9a, 0xfb, 0xe5, 0xcb, 0xce, 0x0b, 0xd0, 0x8c, 0xcb, 0xfd, 0xf3,
+	0x75, 0xf1, 0x9f, 0xe2, 0xa3, 0x7f, 0x06, 0x00, 0x12, 0xd7, 0xc6, 0xa2, 0x65, 0x0c, 0x00, 0x00,
+}
@@ -37,11 +37,11 @@ func LoadLog(defaultLogDir string, logFile string) []byte {
         <console formatid="fmtinfo"/>
 
         `
-	logConfig += `<rollingfile type="size" filename="` + logFilePath + `" maxsize="30000000" maxrolls="5"/>`
+	logConfig += `<custom name="rotating_file" data-filename="` + logFilePath + `"/>`
 	logConfig += `
 		<filter levels="error,critical" formatid="fmterror">
 		`
-	logConfig += `<rollingfile type="size" filename="` + errorFilePath + `" maxsize="10000000" maxrolls="5"/>`
+	logConfig += `<custom name="rotating_file" data-filename="` + errorFilePath + `" data-maxsize="10000000"/>`
 	logConfig += `
         </filter>
     </outputs>
@@ -0,0 +1,343 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package log is used to initialize the logger. This package should be imported once.
+package log
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cihub/seelog"
+)
+
+// RotatingFileReceiverName is the name of the seelog custom receiver writing a log file rolled over by the rotation policy,
+// seelog.xml references it as <custom name="rotating_file" data-filename="<path of the log file>"/>.
+// The optional data-maxsize attribute rolls the file over at that size in bytes instead of the max size of the policy.
+const RotatingFileReceiverName = "rotating_file"
+
+const (
+	megabyte         = 1024 * 1024
+	compressedSuffix = ".gz"
+
+	// pruneInterval is how often the rolled files older than the max age are deleted while the log file doesn't roll over
+	pruneInterval = time.Hour
+)
+
+// RotationPolicy decides when the log files roll over and which rolled files are kept.
+// The most recent rolled file of a log file has the suffix .1, the previous one .2 and so on.
+type RotationPolicy struct {
+	// MaxFileSizeMB rolls the log file over once it reaches that size
+	MaxFileSizeMB int
+	// MaxFiles is the number of rolled files kept
+	MaxFiles int
+	// MaxAgeDays deletes the rolled files older than that, 0 keeps them regardless of age
+	MaxAgeDays int
+	// Compress gzips the rolled files
+	Compress bool
+}
+
+// DefaultRotationPolicy is the policy of the log files until SetRotationPolicy is called
+var DefaultRotationPolicy = RotationPolicy{MaxFileSizeMB: 30, MaxFiles: 5}
+
+var (
+	rotationLock   sync.Mutex
+	rotationPolicy = DefaultRotationPolicy
+	rotatingFiles  = make(map[string]*rotatingFile)
+
+	now = time.Now
+)
+
+func init() {
+	seelog.RegisterReceiver(RotatingFileReceiverName, &RotatingFileReceiver{})
+}
+
+// SetRotationPolicy changes the rotation policy of the log files, including the log files already open
+func SetRotationPolicy(policy RotationPolicy) {
+	rotationLock.Lock()
+	defer rotationLock.Unlock()
+
+	rotationPolicy = policy
+	for _, file := range rotatingFiles {
+		file.setPolicy(policy)
+	}
+}
+
+// RotatingFileReceiver is the seelog custom receiver writing the messages to a log file rolled over by the rotation policy.
+// The receivers of the same log file share it, so the logger replaced on a config change doesn't roll it over a second time.
+type RotatingFileReceiver struct {
+	file *rotatingFile
+}
+
+// AfterParse opens the log file named by the data-filename attribute
+func (receiver *RotatingFileReceiver) AfterParse(initArgs seelog.CustomReceiverInitArgs) error {
+	path := strings.TrimSpace(initArgs.XmlCustomAttrs["filename"])
+	if path == "" {
+		return fmt.Errorf("the %v output has no data-filename attribute", RotatingFileReceiverName)
+	}
+	var maxSize int64
+	if value := strings.TrimSpace(initArgs.XmlCustomAttrs["maxsize"]); value != "" {
+		var err error
+		if maxSize, err = strconv.ParseInt(value, 10, 64); err != nil || maxSize <= 0 {
+			return fmt.Errorf("the %v output has an invalid data-maxsize attribute %v", RotatingFileReceiverName, value)
+		}
+	}
+	receiver.file = openRotatingFile(path, maxSize)
+	return nil
+}
+
+// ReceiveMessage writes the formatted message to the log file
+func (receiver *RotatingFileReceiver) ReceiveMessage(message string, level seelog.LogLevel, context seelog.LogContextInterface) error {
+	return receiver.file.write([]byte(message))
+}
+
+// Flush does nothing, the messages are written to the log file as they are received
+func (receiver *RotatingFileReceiver) Flush() {}
+
+// Close closes the log file once no other receiver writes it
+func (receiver *RotatingFileReceiver) Close() error {
+	return closeRotatingFile(receiver.file)
+}
+
+// rotatingFile is a log file rolled over by the rotation policy
+type rotatingFile struct {
+	lock      sync.Mutex
+	path      string
+	policy    RotationPolicy
+	file      *os.File
+	size      int64
+	lastPrune time.Time
+
+	// maxSize rolls the file over at that size in bytes instead of the max size of the policy, 0 uses the policy
+	maxSize int64
+	// compressing tracks the compression of the rolled file, which runs without holding lock
+	compressing sync.WaitGroup
+
+	// refs is the number of receivers writing the file, guarded by rotationLock
+	refs int
+}
+
+func openRotatingFile(path string, maxSize int64) *rotatingFile {
+	rotationLock.Lock()
+	defer rotationLock.Unlock()
+
+	path = filepath.Clean(path)
+	file, found := rotatingFiles[path]
+	if !found {
+		file = &rotatingFile{path: path, policy: rotationPolicy}
+		rotatingFiles[path] = file
+	}
+	file.setMaxSize(maxSize)
+	file.refs++
+	return file
+}
+
+func closeRotatingFile(file *rotatingFile) error {
+	rotationLock.Lock()
+	defer rotationLock.Unlock()
+
+	if file.refs--; file.refs > 0 {
+		return nil
+	}
+	delete(rotatingFiles, file.path)
+	return file.close()
+}
+
+// write appends the message to the log file, rolling the file over first if the message would take it past the max size
+func (f *rotatingFile) write(message []byte) (err error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.file == nil {
+		if err = f.open(); err != nil {
+			return err
+		}
+	}
+	if f.size > 0 && f.size+int64(len(message)) > f.maxSizeLocked() {
+		if err = f.rollOver(); err != nil {
+			return err
+		}
+	} else if now().Sub(f.lastPrune) >= pruneInterval {
+		f.prune()
+	}
+
+	n, err := f.file.Write(message)
+	f.size += int64(n)
+	return err
+}
+
+func (f *rotatingFile) setPolicy(policy RotationPolicy) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.policy = policy
+	f.prune()
+}
+
+func (f *rotatingFile) setMaxSize(maxSize int64) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.maxSize = maxSize
+}
+
+// maxSizeLocked returns the size in bytes at which the file rolls over. Caller must hold lock.
+func (f *rotatingFile) maxSizeLocked() int64 {
+	if f.maxSize > 0 {
+		return f.maxSize
+	}
+	return int64(f.policy.MaxFileSizeMB) * megabyte
+}
+
+// close closes the log file once the rolled file is compressed
+func (f *rotatingFile) close() (err error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.compressing.Wait()
+	if f.file != nil {
+		err = f.file.Close()
+		f.file = nil
+	}
+	return err
+}
+
+func (f *rotatingFile) open() (err error) {
+	if err = os.MkdirAll(filepath.Dir(f.path), 0755); err != nil {
+		return err
+	}
+	if f.file, err = os.OpenFile(f.path, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0644); err != nil {
+		return err
+	}
+	info, err := f.file.Stat()
+	if err != nil {
+		f.file.Close()
+		f.file = nil
+		return err
+	}
+	f.size = info.Size()
+	return nil
+}
+
+// rollOver renames the log file to the first rolled file, after shifting the suffix of the other rolled files,
+// and opens a new log file. The first rolled file is compressed in the background, the messages don't wait for it.
+func (f *rotatingFile) rollOver() error {
+	current, currentErr := f.file.Stat()
+	f.file.Close()
+	f.file = nil
+
+	// the other agent processes writing the same log file may have rolled it over already
+	if info, err := os.Stat(f.path); err == nil && currentErr == nil && !os.SameFile(info, current) {
+		return f.open()
+	}
+
+	// the log file is moved aside before shifting the rolled files, so they stay put when another process holds it open
+	if err := os.Rename(f.path, f.rolledPath(0)); err != nil {
+		fmt.Println("Failed to roll over the log file", f.path, err)
+		if err = f.open(); err == nil {
+			// try again once the file grows by the max size instead of on every message
+			f.size = 0
+		}
+		return err
+	}
+	// the rolled file still being compressed is shifted once it's compressed
+	f.compressing.Wait()
+	for index := f.policy.MaxFiles; index >= 0; index-- {
+		for _, suffix := range []string{"", compressedSuffix} {
+			if _, err := os.Stat(f.rolledPath(index) + suffix); err == nil {
+				os.Rename(f.rolledPath(index)+suffix, f.rolledPath(index+1)+suffix)
+			}
+		}
+	}
+	f.prune()
+	if f.policy.Compress && f.policy.MaxFiles > 0 {
+		rolledPath := f.rolledPath(1)
+		f.compressing.Add(1)
+		go func() {
+			defer f.compressing.Done()
+			if err := compressFile(rolledPath); err != nil {
+				fmt.Println("Failed to compress the rolled log file", rolledPath, err)
+			}
+		}()
+	}
+	return f.open()
+}
+
+// prune deletes the rolled files beyond the max number of files and the rolled files older than the max age
+func (f *rotatingFile) prune() {
+	f.lastPrune = now()
+	files, err := ioutil.ReadDir(filepath.Dir(f.path))
+	if err != nil {
+		return
+	}
+	maxAge := time.Duration(f.policy.MaxAgeDays) * 24 * time.Hour
+	for _, info := range files {
+		index, ok := rolledIndex(filepath.Base(f.path), info.Name())
+		if !ok {
+			continue
+		}
+		if index > f.policy.MaxFiles || (maxAge > 0 && f.lastPrune.Sub(info.ModTime()) > maxAge) {
+			os.Remove(filepath.Join(filepath.Dir(f.path), info.Name()))
+		}
+	}
+}
+
+func (f *rotatingFile) rolledPath(index int) string {
+	return f.path + "." + strconv.Itoa(index)
+}
+
+// rolledIndex returns the index in the suffix of the rolled file with the given name, if it's a rolled file of the log file.
+// The log file being rolled over has the index 0 and is never pruned.
+func rolledIndex(logFileName string, name string) (int, bool) {
+	if !strings.HasPrefix(name, logFileName+".") {
+		return 0, false
+	}
+	suffix := strings.TrimSuffix(strings.TrimPrefix(name, logFileName+"."), compressedSuffix)
+	index, err := strconv.Atoi(suffix)
+	return index, err == nil && index > 0
+}
+
+// compressFile replaces the file with its gzipped copy
+func compressFile(path string) (err error) {
+	source, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	target, err := os.OpenFile(path+compressedSuffix, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	writer := gzip.NewWriter(target)
+	if _, err = io.Copy(writer, source); err == nil {
+		err = writer.Close()
+	}
+	if closeErr := target.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path + compressedSuffix)
+		return err
+	}
+	source.Close()
+	return os.Remove(path)
+}
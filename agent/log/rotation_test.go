@@ -0,0 +1,191 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cihub/seelog"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestRotatingFile returns a log file with the policy in a new temp dir
+func newTestRotatingFile(t *testing.T, policy RotationPolicy) (file *rotatingFile, cleanup func()) {
+	dir, err := ioutil.TempDir("", "rotation")
+	assert.NoError(t, err)
+	file = &rotatingFile{path: filepath.Join(dir, "test.log"), policy: policy}
+	return file, func() {
+		file.close()
+		os.RemoveAll(dir)
+	}
+}
+
+func writeKilobytes(t *testing.T, file *rotatingFile, kilobytes int, letter string) {
+	for i := 0; i < kilobytes; i++ {
+		assert.NoError(t, file.write([]byte(strings.Repeat(letter, 1023)+"\n")))
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	content, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	return string(content)
+}
+
+func TestRollOver(t *testing.T) {
+	file, cleanup := newTestRotatingFile(t, RotationPolicy{MaxFileSizeMB: 1, MaxFiles: 2})
+	defer cleanup()
+
+	writeKilobytes(t, file, 1024, "a")
+	writeKilobytes(t, file, 1024, "b")
+	writeKilobytes(t, file, 1024, "c")
+	writeKilobytes(t, file, 1, "d")
+
+	assert.Equal(t, strings.Repeat("d", 1023)+"\n", readFile(t, file.path))
+	assert.True(t, strings.HasPrefix(readFile(t, file.rolledPath(1)), "c"))
+	assert.True(t, strings.HasPrefix(readFile(t, file.rolledPath(2)), "b"))
+	_, err := os.Stat(file.rolledPath(3))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestRollOverCompressed(t *testing.T) {
+	file, cleanup := newTestRotatingFile(t, RotationPolicy{MaxFileSizeMB: 1, MaxFiles: 5, Compress: true})
+	defer cleanup()
+
+	writeKilobytes(t, file, 1024, "a")
+	writeKilobytes(t, file, 1024, "b")
+	writeKilobytes(t, file, 1, "c")
+	// the rolled files are compressed in the background
+	file.compressing.Wait()
+
+	_, err := os.Stat(file.rolledPath(1))
+	assert.True(t, os.IsNotExist(err))
+	assert.True(t, strings.HasPrefix(readCompressedFile(t, file.rolledPath(1)+compressedSuffix), "b"))
+	assert.True(t, strings.HasPrefix(readCompressedFile(t, file.rolledPath(2)+compressedSuffix), "a"))
+	assert.Equal(t, 1024*1024, len(readCompressedFile(t, file.rolledPath(1)+compressedSuffix)))
+}
+
+func readCompressedFile(t *testing.T, path string) string {
+	compressed, err := os.Open(path)
+	assert.NoError(t, err)
+	defer compressed.Close()
+	reader, err := gzip.NewReader(compressed)
+	assert.NoError(t, err)
+	content, err := ioutil.ReadAll(reader)
+	assert.NoError(t, err)
+	return string(content)
+}
+
+func TestRollOverAtMaxSize(t *testing.T) {
+	file, cleanup := newTestRotatingFile(t, RotationPolicy{MaxFileSizeMB: 30, MaxFiles: 2})
+	defer cleanup()
+	file.maxSize = 512 * 1024
+
+	writeKilobytes(t, file, 512, "a")
+	writeKilobytes(t, file, 1, "b")
+
+	assert.Equal(t, strings.Repeat("b", 1023)+"\n", readFile(t, file.path))
+	assert.Equal(t, 512*1024, len(readFile(t, file.rolledPath(1))))
+}
+
+func TestRollOverByOtherProcess(t *testing.T) {
+	file, cleanup := newTestRotatingFile(t, RotationPolicy{MaxFileSizeMB: 1, MaxFiles: 5})
+	defer cleanup()
+
+	writeKilobytes(t, file, 1024, "a")
+	assert.NoError(t, os.Rename(file.path, file.rolledPath(1)))
+	writeKilobytes(t, file, 1, "b")
+
+	assert.Equal(t, strings.Repeat("b", 1023)+"\n", readFile(t, file.path))
+	_, err := os.Stat(file.rolledPath(2))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestPrune(t *testing.T) {
+	file, cleanup := newTestRotatingFile(t, RotationPolicy{MaxFileSizeMB: 1, MaxFiles: 3, MaxAgeDays: 7})
+	defer cleanup()
+
+	old := time.Now().Add(-8 * 24 * time.Hour)
+	for _, name := range []string{file.rolledPath(1), file.rolledPath(2) + compressedSuffix, file.rolledPath(3), file.rolledPath(4), file.path + ".other"} {
+		assert.NoError(t, ioutil.WriteFile(name, []byte("rolled"), 0644))
+	}
+	assert.NoError(t, os.Chtimes(file.rolledPath(3), old, old))
+
+	file.prune()
+
+	files, err := ioutil.ReadDir(filepath.Dir(file.path))
+	assert.NoError(t, err)
+	var names []string
+	for _, info := range files {
+		names = append(names, info.Name())
+	}
+	assert.Equal(t, []string{"test.log.1", "test.log.2.gz", "test.log.other"}, names)
+}
+
+func TestSetRotationPolicy(t *testing.T) {
+	defer SetRotationPolicy(DefaultRotationPolicy)
+	dir, err := ioutil.TempDir("", "rotation")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	file := openRotatingFile(filepath.Join(dir, "test.log"), 0)
+	assert.Equal(t, file, openRotatingFile(filepath.Join(dir, "test.log"), 0))
+	assert.NoError(t, ioutil.WriteFile(file.rolledPath(2), []byte("rolled"), 0644))
+
+	SetRotationPolicy(RotationPolicy{MaxFileSizeMB: 1, MaxFiles: 1})
+	assert.Equal(t, RotationPolicy{MaxFileSizeMB: 1, MaxFiles: 1}, file.policy)
+	_, err = os.Stat(file.rolledPath(2))
+	assert.True(t, os.IsNotExist(err))
+
+	assert.NoError(t, closeRotatingFile(file))
+	assert.Contains(t, rotatingFiles, file.path)
+	assert.NoError(t, closeRotatingFile(file))
+	assert.NotContains(t, rotatingFiles, file.path)
+}
+
+func TestRotatingFileReceiver(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rotation")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	logger, err := seelog.LoggerFromConfigAsBytes(LoadLog(dir, LogFile))
+	assert.NoError(t, err)
+	// errors.log keeps rolling over at 10 MB
+	assert.Equal(t, int64(10000000), rotatingFiles[filepath.Join(dir, ErrorFile)].maxSize)
+	assert.Equal(t, int64(0), rotatingFiles[filepath.Join(dir, LogFile)].maxSize)
+	logger.Info("info message")
+	logger.Error("error message")
+	logger.Close()
+
+	agentLog := readFile(t, filepath.Join(dir, LogFile))
+	assert.Contains(t, agentLog, "INFO info message")
+	assert.Contains(t, agentLog, "ERROR error message")
+	errorLog := readFile(t, filepath.Join(dir, ErrorFile))
+	assert.NotContains(t, errorLog, "info message")
+	assert.Contains(t, errorLog, "error message")
+	assert.Empty(t, rotatingFiles)
+}
+
+func TestRotatingFileReceiverInvalidMaxSize(t *testing.T) {
+	receiver := &RotatingFileReceiver{}
+	err := receiver.AfterParse(seelog.CustomReceiverInitArgs{XmlCustomAttrs: map[string]string{"filename": "test.log", "maxsize": "10MB"}})
+	assert.Error(t, err)
+	assert.Empty(t, rotatingFiles)
+}
@@ -0,0 +1,62 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ssmlog
+
+import (
+	"fmt"
+	"html"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+)
+
+// receiverNames are the names of the custom receivers writing the logs to the log backends
+var receiverNames = map[string]string{
+	appconfig.LogBackendSyslog:   syslogReceiverName,
+	appconfig.LogBackendJournald: journaldReceiverName,
+}
+
+// getLogConfigBytes returns the seelog configuration writing the logs to the backend selected in the agent
+// configuration, the configuration of seelog.xml if the backend is file or isn't supported on the platform
+func getLogConfigBytes() []byte {
+	config, err := appconfig.Config(false)
+	if err != nil {
+		return log.GetLogConfigBytes()
+	}
+	applyRotationPolicy(config.Agent)
+	if !backendsSupported {
+		return log.GetLogConfigBytes()
+	}
+	receiver, found := receiverNames[config.Agent.LogBackend]
+	if !found {
+		return log.GetLogConfigBytes()
+	}
+	fmt.Println("Logging to", config.Agent.LogBackend)
+	return backendConfig(receiver, config.Agent.SyslogFacility)
+}
+
+// backendConfig returns the seelog configuration writing the logs to the custom receiver, which adds the time
+// and the level of the messages itself
+func backendConfig(receiver string, facility string) []byte {
+	return []byte(`
+<seelog type="adaptive" mininterval="2000000" maxinterval="100000000" critmsgcount="500" minlevel="info">
+    <outputs formatid="fmtbackend">
+        <custom name="` + receiver + `" data-facility="` + html.EscapeString(facility) + `"/>
+    </outputs>
+    <formats>
+        <format id="fmtbackend" format="%Msg"/>
+    </formats>
+</seelog>
+`)
+}
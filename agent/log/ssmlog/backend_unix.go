@@ -0,0 +1,112 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// +build darwin freebsd linux netbsd openbsd
+
+package ssmlog
+
+import (
+	"fmt"
+	"log/syslog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cihub/seelog"
+)
+
+// backendsSupported tells whether the logs can be written to syslog or journald
+const backendsSupported = true
+
+const (
+	syslogReceiverName   = "syslog_receiver"
+	journaldReceiverName = "journald_receiver"
+)
+
+// syslogFacilities are the syslog facilities by name
+var syslogFacilities = map[string]syslog.Priority{
+	"kern":     syslog.LOG_KERN,
+	"user":     syslog.LOG_USER,
+	"mail":     syslog.LOG_MAIL,
+	"daemon":   syslog.LOG_DAEMON,
+	"auth":     syslog.LOG_AUTH,
+	"syslog":   syslog.LOG_SYSLOG,
+	"lpr":      syslog.LOG_LPR,
+	"news":     syslog.LOG_NEWS,
+	"uucp":     syslog.LOG_UUCP,
+	"cron":     syslog.LOG_CRON,
+	"authpriv": syslog.LOG_AUTHPRIV,
+	"ftp":      syslog.LOG_FTP,
+	"local0":   syslog.LOG_LOCAL0,
+	"local1":   syslog.LOG_LOCAL1,
+	"local2":   syslog.LOG_LOCAL2,
+	"local3":   syslog.LOG_LOCAL3,
+	"local4":   syslog.LOG_LOCAL4,
+	"local5":   syslog.LOG_LOCAL5,
+	"local6":   syslog.LOG_LOCAL6,
+	"local7":   syslog.LOG_LOCAL7,
+}
+
+// registerBackendReceivers registers the custom receivers of the log backends of the platform
+func registerBackendReceivers() {
+	seelog.RegisterReceiver(syslogReceiverName, &SyslogCustomReceiver{})
+	seelog.RegisterReceiver(journaldReceiverName, &JournaldCustomReceiver{})
+}
+
+// identifier returns the name of the process, which tells the agent and the document workers apart in the logs
+func identifier() string {
+	return filepath.Base(os.Args[0])
+}
+
+// SyslogCustomReceiver implements seelog.CustomReceiver writing the logs to the local syslog daemon
+type SyslogCustomReceiver struct {
+	writer *syslog.Writer
+}
+
+// AfterParse connects to the syslog daemon with the facility of the data-facility attribute, daemon by default
+func (logReceiver *SyslogCustomReceiver) AfterParse(initArgs seelog.CustomReceiverInitArgs) (err error) {
+	name := strings.ToLower(strings.TrimSpace(initArgs.XmlCustomAttrs["facility"]))
+	if name == "" {
+		name = "daemon"
+	}
+	facility, found := syslogFacilities[name]
+	if !found {
+		return fmt.Errorf("unknown syslog facility %v", name)
+	}
+	logReceiver.writer, err = syslog.New(facility|syslog.LOG_INFO, identifier())
+	return
+}
+
+// ReceiveMessage writes the message with the severity of its level
+func (logReceiver *SyslogCustomReceiver) ReceiveMessage(message string, level seelog.LogLevel, context seelog.LogContextInterface) error {
+	switch level {
+	case seelog.CriticalLvl:
+		return logReceiver.writer.Crit(message)
+	case seelog.ErrorLvl:
+		return logReceiver.writer.Err(message)
+	case seelog.WarnLvl:
+		return logReceiver.writer.Warning(message)
+	case seelog.InfoLvl:
+		return logReceiver.writer.Info(message)
+	default:
+		return logReceiver.writer.Debug(message)
+	}
+}
+
+// Flush has nothing to flush, the messages are written as they are received
+func (logReceiver *SyslogCustomReceiver) Flush() {}
+
+// Close closes the connection to the syslog daemon
+func (logReceiver *SyslogCustomReceiver) Close() error {
+	return logReceiver.writer.Close()
+}
@@ -0,0 +1,55 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// +build darwin freebsd linux netbsd openbsd
+
+package ssmlog
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cihub/seelog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyslogCustomReceiverUnknownFacility(t *testing.T) {
+	receiver := &SyslogCustomReceiver{}
+	err := receiver.AfterParse(seelog.CustomReceiverInitArgs{XmlCustomAttrs: map[string]string{"facility": "local9"}})
+	assert.NotNil(t, err)
+}
+
+func TestBackendConfig(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "journald")
+	defer os.RemoveAll(dir)
+	defer func(previous string) { journaldSocket = previous }(journaldSocket)
+	journaldSocket = filepath.Join(dir, "socket")
+	journal, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: journaldSocket, Net: "unixgram"})
+	assert.Nil(t, err)
+	defer journal.Close()
+
+	registerBackendReceivers()
+	logger, err := seelog.LoggerFromConfigAsBytes(backendConfig(journaldReceiverName, "daemon"))
+	assert.Nil(t, err)
+	logger.Info("Document completed")
+	logger.Close()
+
+	buffer := make([]byte, 1024)
+	n, err := journal.Read(buffer)
+	assert.Nil(t, err)
+	assert.Contains(t, string(buffer[:n]), "MESSAGE=Document completed\n")
+	assert.Contains(t, string(buffer[:n]), "PRIORITY=6\n")
+}
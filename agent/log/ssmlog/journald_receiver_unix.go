@@ -0,0 +1,97 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// +build darwin freebsd linux netbsd openbsd
+
+package ssmlog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/aws/amazon-ssm-agent/agent/version"
+	"github.com/cihub/seelog"
+)
+
+const (
+	// maxJournaldMessageBytes is the size of the messages written to journald, longer messages are truncated
+	// so that the datagram fits in the socket buffer
+	maxJournaldMessageBytes = 64 * 1024
+)
+
+// journaldSocket is assigned to a variable to allow unit tests to override it
+var journaldSocket = "/run/systemd/journal/socket"
+
+// journaldPriorities are the syslog priorities of the levels
+var journaldPriorities = map[seelog.LogLevel]string{
+	seelog.CriticalLvl: "2",
+	seelog.ErrorLvl:    "3",
+	seelog.WarnLvl:     "4",
+	seelog.InfoLvl:     "6",
+	seelog.DebugLvl:    "7",
+	seelog.TraceLvl:    "7",
+}
+
+// JournaldCustomReceiver implements seelog.CustomReceiver writing the logs to journald with the native protocol,
+// the level, the agent version and the calling function are written as structured fields
+type JournaldCustomReceiver struct {
+	conn *net.UnixConn
+}
+
+// AfterParse connects to the journald socket
+func (logReceiver *JournaldCustomReceiver) AfterParse(initArgs seelog.CustomReceiverInitArgs) (err error) {
+	logReceiver.conn, err = net.DialUnix("unixgram", nil, &net.UnixAddr{Name: journaldSocket, Net: "unixgram"})
+	return
+}
+
+// ReceiveMessage writes the message and its fields in a datagram
+func (logReceiver *JournaldCustomReceiver) ReceiveMessage(message string, level seelog.LogLevel, context seelog.LogContextInterface) error {
+	if len(message) > maxJournaldMessageBytes {
+		message = message[:maxJournaldMessageBytes]
+	}
+	var entry bytes.Buffer
+	writeJournaldField(&entry, "MESSAGE", message)
+	writeJournaldField(&entry, "PRIORITY", journaldPriorities[level])
+	writeJournaldField(&entry, "SYSLOG_IDENTIFIER", identifier())
+	writeJournaldField(&entry, "SSM_AGENT_VERSION", version.Version)
+	if context != nil && context.IsValid() {
+		writeJournaldField(&entry, "CODE_FILE", context.FileName())
+		writeJournaldField(&entry, "CODE_LINE", strconv.Itoa(context.Line()))
+		writeJournaldField(&entry, "CODE_FUNC", context.Func())
+	}
+	_, err := logReceiver.conn.Write(entry.Bytes())
+	return err
+}
+
+// Flush has nothing to flush, the messages are written as they are received
+func (logReceiver *JournaldCustomReceiver) Flush() {}
+
+// Close closes the connection to journald
+func (logReceiver *JournaldCustomReceiver) Close() error {
+	return logReceiver.conn.Close()
+}
+
+// writeJournaldField writes the field in the journald native protocol, a value spanning several lines is
+// written with its length in binary
+func writeJournaldField(entry *bytes.Buffer, name string, value string) {
+	if !strings.Contains(value, "\n") {
+		entry.WriteString(name + "=" + value + "\n")
+		return
+	}
+	entry.WriteString(name + "\n")
+	binary.Write(entry, binary.LittleEndian, uint64(len(value)))
+	entry.WriteString(value + "\n")
+}
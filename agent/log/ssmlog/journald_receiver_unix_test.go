@@ -0,0 +1,72 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// +build darwin freebsd linux netbsd openbsd
+
+package ssmlog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cihub/seelog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteJournaldField(t *testing.T) {
+	var entry bytes.Buffer
+	writeJournaldField(&entry, "MESSAGE", "Document completed")
+	assert.Equal(t, "MESSAGE=Document completed\n", entry.String())
+
+	entry.Reset()
+	writeJournaldField(&entry, "MESSAGE", "panic: runtime error\ngoroutine 1")
+	length := make([]byte, 8)
+	binary.LittleEndian.PutUint64(length, uint64(len("panic: runtime error\ngoroutine 1")))
+	assert.Equal(t, "MESSAGE\n"+string(length)+"panic: runtime error\ngoroutine 1\n", entry.String())
+}
+
+func TestJournaldCustomReceiver(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "journald")
+	defer os.RemoveAll(dir)
+	defer func(previous string) { journaldSocket = previous }(journaldSocket)
+	journaldSocket = filepath.Join(dir, "socket")
+	journal, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: journaldSocket, Net: "unixgram"})
+	assert.Nil(t, err)
+	defer journal.Close()
+
+	receiver := &JournaldCustomReceiver{}
+	assert.Nil(t, receiver.AfterParse(seelog.CustomReceiverInitArgs{}))
+	defer receiver.Close()
+	assert.Nil(t, receiver.ReceiveMessage("Document failed", seelog.ErrorLvl, nil))
+
+	buffer := make([]byte, 1024)
+	n, err := journal.Read(buffer)
+	assert.Nil(t, err)
+	entry := string(buffer[:n])
+	assert.Contains(t, entry, "MESSAGE=Document failed\n")
+	assert.Contains(t, entry, "PRIORITY=3\n")
+	assert.Contains(t, entry, "SYSLOG_IDENTIFIER="+identifier()+"\n")
+}
+
+func TestJournaldCustomReceiverWithoutJournald(t *testing.T) {
+	defer func(previous string) { journaldSocket = previous }(journaldSocket)
+	journaldSocket = filepath.Join(os.TempDir(), "no-journald-socket")
+
+	receiver := &JournaldCustomReceiver{}
+	assert.NotNil(t, receiver.AfterParse(seelog.CustomReceiverInitArgs{}))
+}
@@ -0,0 +1,40 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ssmlog
+
+import (
+	"fmt"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+)
+
+// applyRotationPolicy makes the rotating_file outputs roll the log files over as set in the agent configuration
+func applyRotationPolicy(agent appconfig.AgentInfo) {
+	log.SetRotationPolicy(log.RotationPolicy{
+		MaxFileSizeMB: agent.LogRotationMaxFileSizeMB,
+		MaxFiles:      agent.LogRotationMaxFiles,
+		MaxAgeDays:    agent.LogRotationMaxAgeDays,
+		Compress:      agent.LogRotationCompress,
+	})
+}
+
+// reloadAppConfig reads the changed agent configuration and replaces the logger to apply its log settings
+func reloadAppConfig() {
+	if _, err := appconfig.Config(true); err != nil {
+		fmt.Println("Failed to reload the agent configuration:", err)
+		return
+	}
+	replaceLogger()
+}
@@ -19,6 +19,7 @@ import (
 
 	"sync"
 
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
 	"github.com/aws/amazon-ssm-agent/agent/log"
 	"github.com/cihub/seelog"
 )
@@ -44,7 +45,7 @@ func SSMLogger(useWatcher bool) log.T {
 // initLogger initializes a new logger based on current configurations and starts file watcher on the configurations file
 func initLogger(useWatcher bool) (logger log.T) {
 	// Read the current configurations or get the default configurations
-	logConfigBytes := log.GetLogConfigBytes()
+	logConfigBytes := getLogConfigBytes()
 	// Initialize the base seelog logger
 	baseLogger, _ := initBaseLoggerFromBytes(logConfigBytes)
 	// Create the wrapper logger
@@ -125,6 +126,11 @@ func startWatcher(logger log.T) {
 	fileWatcher.Init(logger, log.DefaultSeelogConfigFilePath, replaceLogger)
 	// Start the file watcher
 	fileWatcher.Start()
+
+	// The log backend and the log rotation are set in the agent configuration
+	appConfigWatcher := &FileWatcher{}
+	appConfigWatcher.Init(logger, appconfig.AppConfigPath, reloadAppConfig)
+	appConfigWatcher.Start()
 }
 
 // ReplaceLogger replaces the current logger with a new logger initialized from the current configurations file
@@ -135,7 +141,7 @@ func replaceLogger() {
 	logger := getCached()
 
 	//Create new logger
-	logConfigBytes := log.GetLogConfigBytes()
+	logConfigBytes := getLogConfigBytes()
 	baseLogger, err := initBaseLoggerFromBytes(logConfigBytes)
 
 	// If err in creating logger, do not replace logger
@@ -169,6 +175,7 @@ func initBaseLoggerFromBytes(seelogConfig []byte) (seelogger seelog.LoggerInterf
 	fmt.Println("Initializing new seelog logger")
 	logReceiver := &CloudWatchCustomReceiver{}
 	seelog.RegisterReceiver("cloudwatch_receiver", logReceiver)
+	registerBackendReceivers()
 	seelogger, err = seelog.LoggerFromConfigAsBytes(seelogConfig)
 	if err != nil {
 		fmt.Println("Error parsing logger config. Creating logger from default config:", err)
@@ -65,6 +65,7 @@ var (
 	logger               log.T
 	shareCreds           bool
 	shareProfile         string
+	expiration           time.Time
 )
 
 // ManagedInstanceCredentialsInstance returns a singleton instance of
@@ -85,6 +86,14 @@ func ManagedInstanceCredentialsInstance() *credentials.Credentials {
 	return credentialsSingleton
 }
 
+// CredentialsExpiration returns when the credentials last retrieved from the SSM Auth service expire,
+// the zero time if none were retrieved yet.
+func CredentialsExpiration() time.Time {
+	lock.RLock()
+	defer lock.RUnlock()
+	return expiration
+}
+
 // newManagedInstanceCredentials returns a pointer to a new Credentials object wrapping
 // the managedInstancesRoleProvider.
 func newManagedInstanceCredentials() *credentials.Credentials {
@@ -144,6 +153,9 @@ func (m *managedInstancesRoleProvider) Retrieve() (credentials.Value, error) {
 
 	// Set the expiration of our credentials
 	m.SetExpiration(*roleCreds.TokenExpirationDate, m.ExpiryWindow)
+	lock.Lock()
+	expiration = *roleCreds.TokenExpirationDate
+	lock.Unlock()
 
 	// check to see if the agent should publish the credentials to the account aws credentials
 	if shareCreds {
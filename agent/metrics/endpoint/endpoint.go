@@ -0,0 +1,120 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package endpoint implements the core module serving the agent metrics to Prometheus scrapers
+// on http://127.0.0.1:<MetricsPort>/metrics, the endpoint is only reachable from the instance.
+package endpoint
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/metrics"
+)
+
+const (
+	// Name is the core module name for the metrics endpoint
+	Name = "MetricsEndpoint"
+
+	// Path is the path the metrics are served on
+	Path = "/metrics"
+
+	// contentType is the content type of the Prometheus text format
+	contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+	readTimeout = 10 * time.Second
+)
+
+// listen is assigned to a variable to allow unit tests to override it
+var listen = net.Listen
+
+// Endpoint is the core module serving the metrics
+type Endpoint struct {
+	context context.T
+	lock    sync.Mutex
+	server  *http.Server
+}
+
+// NewEndpoint returns the metrics endpoint
+func NewEndpoint(context context.T) *Endpoint {
+	return &Endpoint{
+		context: context.With("[" + Name + "]"),
+	}
+}
+
+// ModuleName returns the name of the module
+func (e *Endpoint) ModuleName() string {
+	return Name
+}
+
+// ModuleExecute starts serving the metrics if MetricsPort is set
+func (e *Endpoint) ModuleExecute(context context.T) (err error) {
+	log := e.context.Log()
+	port := e.context.AppConfig().Agent.MetricsPort
+	if port == 0 {
+		log.Debug("Metrics endpoint is disabled")
+		return nil
+	}
+
+	address := fmt.Sprintf("127.0.0.1:%d", port)
+	listener, err := listen("tcp", address)
+	if err != nil {
+		log.Errorf("Failed to listen on %v, %v", address, err)
+		return err
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(Path, serveMetrics)
+	server := &http.Server{Handler: mux, ReadTimeout: readTimeout}
+	e.lock.Lock()
+	e.server = server
+	e.lock.Unlock()
+
+	log.Infof("Serving the metrics on http://%v%v", listener.Addr(), Path)
+	go e.serve(server, listener)
+	return nil
+}
+
+// ModuleRequestStop stops serving the metrics
+func (e *Endpoint) ModuleRequestStop(stopType contracts.StopType) (err error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if e.server != nil {
+		// Close closes the listener and the connections of the scrapers
+		e.server.Close()
+		e.server = nil
+	}
+	return nil
+}
+
+// serve serves the scrapers until the server stops
+func (e *Endpoint) serve(server *http.Server, listener net.Listener) {
+	if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+		e.context.Log().Errorf("Metrics endpoint stopped, %v", err)
+	}
+}
+
+// serveMetrics writes the metrics in the Prometheus text format
+func serveMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	metrics.WritePrometheus(w)
+}
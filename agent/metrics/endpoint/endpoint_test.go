@@ -0,0 +1,90 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package endpoint implements the core module serving the agent metrics to Prometheus scrapers
+package endpoint
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+func newContext(port int) *context.Mock {
+	config := appconfig.SsmagentConfig{}
+	config.Agent.MetricsPort = port
+	mockContext := new(context.Mock)
+	mockContext.On("Log").Return(log.NewMockLog())
+	mockContext.On("AppConfig").Return(config)
+	mockContext.On("With", "["+Name+"]").Return(mockContext)
+	return mockContext
+}
+
+func TestEndpointServesMetrics(t *testing.T) {
+	defer func() { listen = net.Listen }()
+	var address, listening string
+	listen = func(network, addr string) (net.Listener, error) {
+		address = addr
+		// an ephemeral port keeps the test from conflicting with a running agent
+		listener, err := net.Listen(network, "127.0.0.1:0")
+		if err == nil {
+			listening = listener.Addr().String()
+		}
+		return listener, err
+	}
+	metrics.Reset()
+	defer metrics.Reset()
+	metrics.Increment(metrics.ChannelDocuments, "Success")
+
+	ctx := newContext(9911)
+	endpoint := NewEndpoint(ctx)
+	assert.Nil(t, endpoint.ModuleExecute(ctx))
+	defer endpoint.ModuleRequestStop(contracts.StopTypeSoftStop)
+	assert.Equal(t, "127.0.0.1:9911", address)
+
+	response, err := http.Get("http://" + listening + Path)
+	assert.Nil(t, err)
+	defer response.Body.Close()
+	body, _ := ioutil.ReadAll(response.Body)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, contentType, response.Header.Get("Content-Type"))
+	assert.True(t, strings.Contains(string(body), `ssm_agent_documents_events_total{event="Success"} 1`))
+
+	response, err = http.Post("http://"+listening+Path, "text/plain", nil)
+	assert.Nil(t, err)
+	response.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, response.StatusCode)
+}
+
+func TestEndpointDisabled(t *testing.T) {
+	defer func() { listen = net.Listen }()
+	listen = func(network, addr string) (net.Listener, error) {
+		t.Fatal("the disabled endpoint must not listen")
+		return nil, nil
+	}
+
+	ctx := newContext(0)
+	endpoint := NewEndpoint(ctx)
+	assert.Nil(t, endpoint.ModuleExecute(ctx))
+	assert.Nil(t, endpoint.server)
+	assert.Nil(t, endpoint.ModuleRequestStop(contracts.StopTypeSoftStop))
+}
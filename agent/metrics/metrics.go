@@ -0,0 +1,240 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package metrics keeps the in-memory counters and latency histograms of the channels the agent receives
+// messages and sends replies through, so that service throttling can be told apart from local slowness,
+// along with the metrics of the documents the agent runs.
+//
+// The metrics are named after their channel and event, for instance mds.received, mds.SendReply or documents.Success.
+package metrics
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// ChannelMds is the Message Delivery Service channel
+	ChannelMds = "mds"
+
+	// ChannelMgs is the Message Gateway Service control channel
+	ChannelMgs = "mgs"
+
+	// ChannelDocuments counts the completed documents by status and gauges the queued documents
+	ChannelDocuments = "documents"
+
+	// ChannelPlugins records the durations of the plugins by plugin name
+	ChannelPlugins = "plugins"
+
+	// EventReceived counts the messages received
+	EventReceived = "received"
+
+	// EventRejected counts the messages received that weren't accepted
+	EventRejected = "rejected"
+
+	// EventAcked counts the messages acknowledged
+	EventAcked = "acked"
+
+	// EventReplied counts the replies sent
+	EventReplied = "replied"
+
+	// EventFailed counts the calls to the service that failed
+	EventFailed = "failed"
+
+	// EventThrottled counts the calls to the service that were throttled
+	EventThrottled = "throttled"
+
+	// EventRetried counts the replies sent again after a failure
+	EventRetried = "retried"
+
+	// EventAbandoned counts the replies given up after their retries
+	EventAbandoned = "abandoned"
+
+	// GaugeQueued is the number of documents submitted and waiting for a worker
+	GaugeQueued = "queued"
+)
+
+// LatencyBucketsMilliseconds are the upper bounds of the latency histogram buckets,
+// the latencies above the last bound are counted in an additional bucket
+var LatencyBucketsMilliseconds = []int64{10, 50, 100, 250, 500, 1000, 2500, 5000, 10000, 30000}
+
+// Histogram is the distribution of the latencies of an operation
+type Histogram struct {
+	Count               int64
+	SumMilliseconds     int64
+	BucketsMilliseconds []int64
+	// BucketCounts has one more element than BucketsMilliseconds, the latencies above the last bound
+	BucketCounts []int64
+}
+
+// Snapshot is a copy of the metrics at a given time
+type Snapshot struct {
+	Counters   map[string]int64
+	Gauges     map[string]int64
+	Histograms map[string]Histogram
+}
+
+// Sink receives the counters and the latencies as they are recorded, along with their tags, such as the
+// association or the plugin they were recorded for
+type Sink interface {
+	Count(channel string, event string, delta int64, tags map[string]string)
+	Timing(channel string, operation string, latency time.Duration, tags map[string]string)
+}
+
+var (
+	lock       sync.Mutex
+	counters   = make(map[string]int64)
+	gauges     = make(map[string]int64)
+	histograms = make(map[string]*Histogram)
+	contacts   = make(map[string]time.Time)
+	sink       Sink
+)
+
+// SetSink forwards the metrics recorded from now on to the sink, nil stops forwarding them
+func SetSink(s Sink) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	sink = s
+}
+
+// Increment increments the counter of the event on the channel
+func Increment(channel string, event string) {
+	AddWithTags(channel, event, 1, nil)
+}
+
+// IncrementWithTags increments the counter of the event on the channel, the tags are only forwarded to the sink
+func IncrementWithTags(channel string, event string, tags map[string]string) {
+	AddWithTags(channel, event, 1, tags)
+}
+
+// Add adds delta to the counter of the event on the channel
+func Add(channel string, event string, delta int64) {
+	AddWithTags(channel, event, delta, nil)
+}
+
+// AddWithTags adds delta to the counter of the event on the channel, the tags are only forwarded to the sink
+func AddWithTags(channel string, event string, delta int64, tags map[string]string) {
+	lock.Lock()
+	counters[name(channel, event)] += delta
+	forward := sink
+	lock.Unlock()
+
+	if forward != nil {
+		forward.Count(channel, event, delta, tags)
+	}
+}
+
+// AddGauge adds delta, which may be negative, to the gauge of the channel
+func AddGauge(channel string, gauge string, delta int64) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	gauges[name(channel, gauge)] += delta
+}
+
+// ObserveLatency records the latency of an operation on the channel
+func ObserveLatency(channel string, operation string, latency time.Duration) {
+	ObserveLatencyWithTags(channel, operation, latency, nil)
+}
+
+// ObserveLatencyWithTags records the latency of an operation on the channel, the tags are only forwarded to the sink
+func ObserveLatencyWithTags(channel string, operation string, latency time.Duration, tags map[string]string) {
+	if forward := observeLatency(channel, operation, latency); forward != nil {
+		forward.Timing(channel, operation, latency, tags)
+	}
+}
+
+// observeLatency adds the latency to the histogram of the operation and returns the sink to forward it to
+func observeLatency(channel string, operation string, latency time.Duration) Sink {
+	lock.Lock()
+	defer lock.Unlock()
+
+	key := name(channel, operation)
+	histogram, ok := histograms[key]
+	if !ok {
+		histogram = &Histogram{
+			BucketsMilliseconds: LatencyBucketsMilliseconds,
+			BucketCounts:        make([]int64, len(LatencyBucketsMilliseconds)+1),
+		}
+		histograms[key] = histogram
+	}
+
+	milliseconds := int64(latency / time.Millisecond)
+	bucket := len(LatencyBucketsMilliseconds)
+	for i, bound := range LatencyBucketsMilliseconds {
+		if milliseconds <= bound {
+			bucket = i
+			break
+		}
+	}
+	histogram.Count++
+	histogram.SumMilliseconds += milliseconds
+	histogram.BucketCounts[bucket]++
+	return sink
+}
+
+// RecordContact records that the agent reached the service of the channel now
+func RecordContact(channel string) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	contacts[channel] = time.Now()
+}
+
+// LastContact returns when the agent last reached the service of the channel, the zero time if it never did
+func LastContact(channel string) time.Time {
+	lock.Lock()
+	defer lock.Unlock()
+
+	return contacts[channel]
+}
+
+// GetSnapshot returns a copy of the metrics recorded since the agent started
+func GetSnapshot() Snapshot {
+	lock.Lock()
+	defer lock.Unlock()
+
+	snapshot := Snapshot{
+		Counters:   make(map[string]int64, len(counters)),
+		Gauges:     make(map[string]int64, len(gauges)),
+		Histograms: make(map[string]Histogram, len(histograms)),
+	}
+	for key, value := range counters {
+		snapshot.Counters[key] = value
+	}
+	for key, value := range gauges {
+		snapshot.Gauges[key] = value
+	}
+	for key, histogram := range histograms {
+		copied := *histogram
+		copied.BucketCounts = append([]int64(nil), histogram.BucketCounts...)
+		snapshot.Histograms[key] = copied
+	}
+	return snapshot
+}
+
+// Reset clears all metrics
+func Reset() {
+	lock.Lock()
+	defer lock.Unlock()
+
+	counters = make(map[string]int64)
+	gauges = make(map[string]int64)
+	histograms = make(map[string]*Histogram)
+	contacts = make(map[string]time.Time)
+}
+
+func name(channel string, event string) string {
+	return channel + "." + event
+}
@@ -0,0 +1,126 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package metrics keeps the in-memory counters and latency histograms of the channels the agent receives
+// messages and sends replies through.
+package metrics
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCounters(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	Increment(ChannelMds, EventAcked)
+	Increment(ChannelMds, EventAcked)
+	Add(ChannelMds, EventReceived, 3)
+	Increment(ChannelMgs, EventReceived)
+
+	snapshot := GetSnapshot()
+	assert.Equal(t, map[string]int64{
+		"mds.acked":    2,
+		"mds.received": 3,
+		"mgs.received": 1,
+	}, snapshot.Counters)
+}
+
+func TestObserveLatency(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	ObserveLatency(ChannelMds, "SendReply", 5*time.Millisecond)
+	ObserveLatency(ChannelMds, "SendReply", 200*time.Millisecond)
+	ObserveLatency(ChannelMds, "SendReply", time.Minute)
+
+	histogram := GetSnapshot().Histograms["mds.SendReply"]
+	assert.Equal(t, int64(3), histogram.Count)
+	assert.Equal(t, int64(60205), histogram.SumMilliseconds)
+	assert.Equal(t, int64(1), histogram.BucketCounts[0])
+	assert.Equal(t, int64(1), histogram.BucketCounts[3])
+	assert.Equal(t, int64(1), histogram.BucketCounts[len(LatencyBucketsMilliseconds)])
+}
+
+func TestSnapshotIsACopy(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	ObserveLatency(ChannelMds, "SendReply", time.Millisecond)
+	snapshot := GetSnapshot()
+	ObserveLatency(ChannelMds, "SendReply", time.Millisecond)
+
+	assert.Equal(t, int64(1), snapshot.Histograms["mds.SendReply"].Count)
+	assert.Equal(t, int64(1), snapshot.Histograms["mds.SendReply"].BucketCounts[0])
+}
+
+func TestGauges(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	AddGauge(ChannelDocuments, GaugeQueued, 2)
+	AddGauge(ChannelDocuments, GaugeQueued, -1)
+
+	assert.Equal(t, map[string]int64{"documents.queued": 1}, GetSnapshot().Gauges)
+}
+
+func TestLastContact(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	assert.True(t, LastContact(ChannelMds).IsZero())
+
+	before := time.Now()
+	RecordContact(ChannelMds)
+
+	assert.False(t, LastContact(ChannelMds).Before(before))
+	assert.True(t, LastContact(ChannelMgs).IsZero())
+
+	Reset()
+	assert.True(t, LastContact(ChannelMds).IsZero())
+}
+
+type recordingSink struct {
+	counts  []string
+	timings []string
+}
+
+func (s *recordingSink) Count(channel string, event string, delta int64, tags map[string]string) {
+	s.counts = append(s.counts, fmt.Sprintf("%v.%v %v %v", channel, event, delta, tags))
+}
+
+func (s *recordingSink) Timing(channel string, operation string, latency time.Duration, tags map[string]string) {
+	s.timings = append(s.timings, fmt.Sprintf("%v.%v %v %v", channel, operation, latency, tags))
+}
+
+func TestSink(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	recording := &recordingSink{}
+	SetSink(recording)
+	Increment(ChannelMds, EventAcked)
+	IncrementWithTags(ChannelDocuments, "Success", map[string]string{"association_id": "a-1"})
+	ObserveLatencyWithTags(ChannelPlugins, "aws:runShellScript", time.Second, map[string]string{"status": "Success"})
+	SetSink(nil)
+	Increment(ChannelMds, EventAcked)
+
+	assert.Equal(t, []string{"mds.acked 1 map[]", "documents.Success 1 map[association_id:a-1]"}, recording.counts)
+	assert.Equal(t, []string{"plugins.aws:runShellScript 1s map[status:Success]"}, recording.timings)
+	assert.Equal(t, int64(2), GetSnapshot().Counters["mds.acked"])
+	assert.Equal(t, int64(1), GetSnapshot().Histograms["plugins.aws:runShellScript"].Count)
+}
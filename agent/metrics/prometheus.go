@@ -0,0 +1,133 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package metrics keeps the in-memory counters and latency histograms of the channels the agent receives
+// messages and sends replies through.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// prometheusPrefix prefixes the name of every metric written in the Prometheus format
+const prometheusPrefix = "ssm_agent_"
+
+// invalidNameCharacters are the characters replaced by _ in the names of the Prometheus metrics
+var invalidNameCharacters = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// labelEscaper escapes the label values of the Prometheus metrics
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// WritePrometheus writes the metrics recorded since the agent started in the Prometheus text format, along with
+// the goroutines and the memory of the agent. The counters of a channel are one metric labeled by event,
+// such as ssm_agent_documents_events_total{event="Success"}, and the latencies are histograms in seconds
+// labeled by operation, such as ssm_agent_mds_duration_seconds{operation="GetMessages"}.
+func WritePrometheus(w io.Writer) error {
+	snapshot := GetSnapshot()
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+
+	out := bufio.NewWriter(w)
+	for _, family := range groupByChannel(counterNames(snapshot.Counters)) {
+		metric := prometheusName(family.channel, "events_total")
+		fmt.Fprintf(out, "# TYPE %v counter\n", metric)
+		for _, key := range family.keys {
+			fmt.Fprintf(out, "%v{event=\"%v\"} %v\n", metric, labelEscaper.Replace(key), snapshot.Counters[name(family.channel, key)])
+		}
+	}
+
+	for _, family := range groupByChannel(counterNames(snapshot.Gauges)) {
+		for _, key := range family.keys {
+			metric := prometheusName(family.channel, key)
+			fmt.Fprintf(out, "# TYPE %v gauge\n%v %v\n", metric, metric, snapshot.Gauges[name(family.channel, key)])
+		}
+	}
+
+	var histogramNames []string
+	for key := range snapshot.Histograms {
+		histogramNames = append(histogramNames, key)
+	}
+	for _, family := range groupByChannel(histogramNames) {
+		metric := prometheusName(family.channel, "duration_seconds")
+		fmt.Fprintf(out, "# TYPE %v histogram\n", metric)
+		for _, key := range family.keys {
+			writeHistogram(out, metric, labelEscaper.Replace(key), snapshot.Histograms[name(family.channel, key)])
+		}
+	}
+
+	fmt.Fprintf(out, "# TYPE %vgoroutines gauge\n%vgoroutines %v\n", prometheusPrefix, prometheusPrefix, runtime.NumGoroutine())
+	fmt.Fprintf(out, "# TYPE %vmemory_alloc_bytes gauge\n%vmemory_alloc_bytes %v\n", prometheusPrefix, prometheusPrefix, memStats.Alloc)
+	fmt.Fprintf(out, "# TYPE %vmemory_sys_bytes gauge\n%vmemory_sys_bytes %v\n", prometheusPrefix, prometheusPrefix, memStats.Sys)
+	return out.Flush()
+}
+
+// writeHistogram writes the cumulative buckets, the sum and the count of the histogram of the operation
+func writeHistogram(out io.Writer, metric string, operation string, histogram Histogram) {
+	var cumulative int64
+	for i, bound := range histogram.BucketsMilliseconds {
+		cumulative += histogram.BucketCounts[i]
+		fmt.Fprintf(out, "%v_bucket{operation=\"%v\",le=\"%v\"} %v\n", metric, operation, seconds(bound), cumulative)
+	}
+	fmt.Fprintf(out, "%v_bucket{operation=\"%v\",le=\"+Inf\"} %v\n", metric, operation, histogram.Count)
+	fmt.Fprintf(out, "%v_sum{operation=\"%v\"} %v\n", metric, operation, seconds(histogram.SumMilliseconds))
+	fmt.Fprintf(out, "%v_count{operation=\"%v\"} %v\n", metric, operation, histogram.Count)
+}
+
+// channelFamily are the sorted event or operation names of a channel
+type channelFamily struct {
+	channel string
+	keys    []string
+}
+
+// counterNames returns the names of the counters or of the gauges
+func counterNames(values map[string]int64) (names []string) {
+	for key := range values {
+		names = append(names, key)
+	}
+	return
+}
+
+// groupByChannel splits the metric names on their first dot and returns the channels sorted by name
+func groupByChannel(names []string) (families []channelFamily) {
+	byChannel := map[string][]string{}
+	for _, key := range names {
+		parts := strings.SplitN(key, ".", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		byChannel[parts[0]] = append(byChannel[parts[0]], parts[1])
+	}
+	for channel, keys := range byChannel {
+		sort.Strings(keys)
+		families = append(families, channelFamily{channel: channel, keys: keys})
+	}
+	sort.Slice(families, func(i, j int) bool { return families[i].channel < families[j].channel })
+	return
+}
+
+// prometheusName returns the name of the metric of the channel, made of the characters Prometheus accepts
+func prometheusName(channel string, suffix string) string {
+	return prometheusPrefix + invalidNameCharacters.ReplaceAllString(channel+"_"+suffix, "_")
+}
+
+// seconds formats milliseconds as seconds
+func seconds(milliseconds int64) string {
+	return strconv.FormatFloat(float64(milliseconds)/1000, 'f', -1, 64)
+}
@@ -0,0 +1,65 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package metrics keeps the in-memory counters and latency histograms of the channels the agent receives
+// messages and sends replies through.
+package metrics
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWritePrometheus(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	Increment(ChannelMds, EventReceived)
+	Increment(ChannelDocuments, "Success")
+	Increment(ChannelDocuments, "Failed")
+	Increment(ChannelDocuments, "Success")
+	AddGauge(ChannelDocuments, GaugeQueued, 3)
+	ObserveLatency(ChannelPlugins, "aws:runShellScript", 1500*time.Millisecond)
+
+	var out bytes.Buffer
+	assert.Nil(t, WritePrometheus(&out))
+	text := out.String()
+
+	assert.Contains(t, text, `# TYPE ssm_agent_documents_events_total counter
+ssm_agent_documents_events_total{event="Failed"} 1
+ssm_agent_documents_events_total{event="Success"} 2
+# TYPE ssm_agent_mds_events_total counter
+ssm_agent_mds_events_total{event="received"} 1
+# TYPE ssm_agent_documents_queued gauge
+ssm_agent_documents_queued 3
+# TYPE ssm_agent_plugins_duration_seconds histogram
+ssm_agent_plugins_duration_seconds_bucket{operation="aws:runShellScript",le="0.01"} 0
+`)
+	assert.Contains(t, text, `ssm_agent_plugins_duration_seconds_bucket{operation="aws:runShellScript",le="1"} 0
+ssm_agent_plugins_duration_seconds_bucket{operation="aws:runShellScript",le="2.5"} 1
+`)
+	assert.Contains(t, text, `ssm_agent_plugins_duration_seconds_bucket{operation="aws:runShellScript",le="+Inf"} 1
+ssm_agent_plugins_duration_seconds_sum{operation="aws:runShellScript"} 1.5
+ssm_agent_plugins_duration_seconds_count{operation="aws:runShellScript"} 1
+`)
+	assert.Contains(t, text, "# TYPE ssm_agent_goroutines gauge\n")
+	assert.Contains(t, text, "# TYPE ssm_agent_memory_alloc_bytes gauge\n")
+}
+
+func TestPrometheusNames(t *testing.T) {
+	assert.Equal(t, "ssm_agent_my_channel_events_total", prometheusName("my-channel", "events_total"))
+	assert.Equal(t, `a\"b\\c`, labelEscaper.Replace(`a"b\c`))
+}
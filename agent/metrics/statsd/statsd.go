@@ -0,0 +1,176 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package statsd implements the core module sending the agent metrics to a StatsD or DogStatsD server.
+//
+// The counters and the latencies are sent as they are recorded, with the tags of the execution they were recorded
+// for in the DogStatsD format, such as ssm_agent.documents.Success:1|c|#association_id:...,status:Success.
+// The gauges, the goroutines and the memory of the agent are sent every minute.
+package statsd
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/metrics"
+)
+
+const (
+	// Name is the core module name for the StatsD emitter
+	Name = "StatsdEmitter"
+
+	// prefix prefixes the name of every metric sent
+	prefix = "ssm_agent."
+)
+
+// dial and gaugeInterval are assigned to variables to allow unit tests to override them
+var dial = net.Dial
+var gaugeInterval = time.Minute
+
+// invalidNameCharacters are the characters replaced by _ in the names of the metrics
+var invalidNameCharacters = regexp.MustCompile(`[^a-zA-Z0-9_.\-]`)
+
+// tagEscaper replaces the separators of the StatsD format in the tag values
+var tagEscaper = strings.NewReplacer("|", "_", ",", "_", "#", "_", "\n", "_")
+
+// Emitter is the core module forwarding the metrics to StatsD
+type Emitter struct {
+	context context.T
+	lock    sync.Mutex
+	conn    net.Conn
+	stop    chan struct{}
+}
+
+// NewEmitter returns the StatsD emitter
+func NewEmitter(context context.T) *Emitter {
+	return &Emitter{
+		context: context.With("[" + Name + "]"),
+	}
+}
+
+// ModuleName returns the name of the module
+func (e *Emitter) ModuleName() string {
+	return Name
+}
+
+// ModuleExecute starts forwarding the metrics if StatsdAddress is set
+func (e *Emitter) ModuleExecute(context context.T) (err error) {
+	log := e.context.Log()
+	address := e.context.AppConfig().Agent.StatsdAddress
+	if address == "" {
+		log.Debug("StatsD emitter is disabled")
+		return nil
+	}
+
+	conn, err := dial("udp", address)
+	if err != nil {
+		log.Errorf("Failed to reach StatsD on %v, %v", address, err)
+		return err
+	}
+	stop := make(chan struct{})
+	e.lock.Lock()
+	e.conn = conn
+	e.stop = stop
+	e.lock.Unlock()
+
+	log.Infof("Sending the metrics to StatsD on %v", address)
+	metrics.SetSink(e)
+	go e.sendGauges(stop)
+	return nil
+}
+
+// ModuleRequestStop stops forwarding the metrics
+func (e *Emitter) ModuleRequestStop(stopType contracts.StopType) (err error) {
+	metrics.SetSink(nil)
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if e.conn != nil {
+		close(e.stop)
+		e.conn.Close()
+		e.conn = nil
+	}
+	return nil
+}
+
+// Count sends the counter increment
+func (e *Emitter) Count(channel string, event string, delta int64, tags map[string]string) {
+	e.send(fmt.Sprintf("%v:%d|c%v", metricName(channel, event), delta, formatTags(tags)))
+}
+
+// Timing sends the latency in milliseconds
+func (e *Emitter) Timing(channel string, operation string, latency time.Duration, tags map[string]string) {
+	e.send(fmt.Sprintf("%v:%d|ms%v", metricName(channel, operation), int64(latency/time.Millisecond), formatTags(tags)))
+}
+
+// sendGauges sends the gauges, the goroutines and the memory of the agent until the emitter stops
+func (e *Emitter) sendGauges(stop chan struct{}) {
+	ticker := time.NewTicker(gaugeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		for key, value := range metrics.GetSnapshot().Gauges {
+			e.send(fmt.Sprintf("%v%v:%d|g", prefix, invalidNameCharacters.ReplaceAllString(key, "_"), value))
+		}
+		var memStats runtime.MemStats
+		runtime.ReadMemStats(&memStats)
+		e.send(fmt.Sprintf("%vgoroutines:%d|g", prefix, runtime.NumGoroutine()))
+		e.send(fmt.Sprintf("%vmemory.alloc_bytes:%d|g", prefix, memStats.Alloc))
+		e.send(fmt.Sprintf("%vmemory.sys_bytes:%d|g", prefix, memStats.Sys))
+	}
+}
+
+// send sends the metric in its own datagram, a metric StatsD doesn't receive is lost
+func (e *Emitter) send(metric string) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if e.conn == nil {
+		return
+	}
+	if _, err := e.conn.Write([]byte(metric)); err != nil {
+		e.context.Log().Debugf("Failed to send metric %v to StatsD, %v", metric, err)
+	}
+}
+
+// metricName returns the name of the metric of the channel, made of the characters StatsD accepts
+func metricName(channel string, event string) string {
+	return prefix + invalidNameCharacters.ReplaceAllString(channel, "_") + "." + invalidNameCharacters.ReplaceAllString(event, "_")
+}
+
+// formatTags returns the tags sorted by name in the DogStatsD format, the tags with an empty value are skipped
+func formatTags(tags map[string]string) string {
+	var formatted []string
+	for name, value := range tags {
+		if value != "" {
+			formatted = append(formatted, tagEscaper.Replace(name)+":"+tagEscaper.Replace(value))
+		}
+	}
+	if len(formatted) == 0 {
+		return ""
+	}
+	sort.Strings(formatted)
+	return "|#" + strings.Join(formatted, ",")
+}
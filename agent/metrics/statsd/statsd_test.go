@@ -0,0 +1,122 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package statsd implements the core module sending the agent metrics to a StatsD or DogStatsD server.
+package statsd
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+func newContext(address string) *context.Mock {
+	config := appconfig.SsmagentConfig{}
+	config.Agent.StatsdAddress = address
+	mockContext := new(context.Mock)
+	mockContext.On("Log").Return(log.NewMockLog())
+	mockContext.On("AppConfig").Return(config)
+	mockContext.On("With", "["+Name+"]").Return(mockContext)
+	return mockContext
+}
+
+// receive returns the next datagram received by the server
+func receive(t *testing.T, server net.PacketConn) string {
+	buffer := make([]byte, 1024)
+	server.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := server.ReadFrom(buffer)
+	assert.Nil(t, err)
+	return string(buffer[:n])
+}
+
+func TestEmitter(t *testing.T) {
+	defer func() { gaugeInterval = time.Minute }()
+	gaugeInterval = time.Hour
+	metrics.Reset()
+	defer metrics.Reset()
+	server, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer server.Close()
+
+	ctx := newContext(server.LocalAddr().String())
+	emitter := NewEmitter(ctx)
+	assert.Nil(t, emitter.ModuleExecute(ctx))
+
+	metrics.IncrementWithTags(metrics.ChannelDocuments, "Success", map[string]string{
+		"association_id": "4cd6f9a1-2b8c",
+		"document_name":  "AWS-RunShellScript",
+		"status":         "Success",
+	})
+	assert.Equal(t, "ssm_agent.documents.Success:1|c|#association_id:4cd6f9a1-2b8c,document_name:AWS-RunShellScript,status:Success", receive(t, server))
+
+	metrics.ObserveLatencyWithTags(metrics.ChannelPlugins, "aws:runShellScript", 1500*time.Millisecond, map[string]string{"status": "Failed"})
+	assert.Equal(t, "ssm_agent.plugins.aws_runShellScript:1500|ms|#status:Failed", receive(t, server))
+
+	metrics.Increment(metrics.ChannelMds, metrics.EventAcked)
+	assert.Equal(t, "ssm_agent.mds.acked:1|c", receive(t, server))
+
+	assert.Nil(t, emitter.ModuleRequestStop(contracts.StopTypeSoftStop))
+	metrics.Increment(metrics.ChannelMds, metrics.EventAcked)
+	server.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	_, _, err = server.ReadFrom(make([]byte, 1024))
+	assert.NotNil(t, err)
+}
+
+func TestEmitterSendsGauges(t *testing.T) {
+	defer func() { gaugeInterval = time.Minute }()
+	gaugeInterval = 10 * time.Millisecond
+	metrics.Reset()
+	defer metrics.Reset()
+	metrics.AddGauge(metrics.ChannelDocuments, metrics.GaugeQueued, 2)
+	server, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer server.Close()
+
+	ctx := newContext(server.LocalAddr().String())
+	emitter := NewEmitter(ctx)
+	assert.Nil(t, emitter.ModuleExecute(ctx))
+	defer emitter.ModuleRequestStop(contracts.StopTypeSoftStop)
+
+	assert.Equal(t, "ssm_agent.documents.queued:2|g", receive(t, server))
+	assert.True(t, strings.HasPrefix(receive(t, server), "ssm_agent.goroutines:"))
+}
+
+func TestEmitterDisabled(t *testing.T) {
+	defer func() { dial = net.Dial }()
+	dial = func(network, address string) (net.Conn, error) {
+		t.Fatal("the disabled emitter must not dial")
+		return nil, nil
+	}
+
+	ctx := newContext("")
+	emitter := NewEmitter(ctx)
+	assert.Nil(t, emitter.ModuleExecute(ctx))
+	assert.Nil(t, emitter.ModuleRequestStop(contracts.StopTypeSoftStop))
+}
+
+func TestFormatTags(t *testing.T) {
+	assert.Equal(t, "", formatTags(nil))
+	assert.Equal(t, "|#document_name:a_b_c,plugin_name:aws:runScript", formatTags(map[string]string{
+		"plugin_name":    "aws:runScript",
+		"document_name":  "a|b,c",
+		"association_id": "",
+	}))
+}
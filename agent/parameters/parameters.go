@@ -119,6 +119,16 @@ func ReplaceParameter(input string, paramName string, paramValue string) string
 	return r.ReplaceAllString(input, paramValue)
 }
 
+// ReplaceParametersInString replaces the parameters within the input and always returns a string.
+// Values that are not strings are marshaled the same way as when they are part of a longer string.
+func ReplaceParametersInString(input string, parameters map[string]interface{}, logger log.T) string {
+	result, err := convertToString(ReplaceParameters(input, parameters, logger))
+	if err != nil {
+		logger.Error(err)
+	}
+	return result
+}
+
 // ValidParameters checks if parameter names are valid. Returns valid parameters only.
 func ValidParameters(log log.T, params map[string]interface{}) map[string]interface{} {
 	validParams := make(map[string]interface{})
@@ -0,0 +1,202 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package parameterstore contains modules to resolve ssm parameters present in the document.
+package parameterstore
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/aws/amazon-ssm-agent/agent/jsonutil"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/ssm"
+)
+
+const (
+	// secretsManagerReferencePrefix is the prefix of the ssm parameters that refer to secrets manager secrets
+	secretsManagerReferencePrefix = "/aws/reference/secretsmanager/"
+
+	// secretStringField is the only field of a secret that references can select a json key from
+	secretStringField = "SecretString"
+)
+
+// secretReference matches references of the format {{resolve:secretsmanager:*}}
+var secretReference = regexp.MustCompile(`\{\{ *resolve:secretsmanager:[^{}\s]+ *\}\}`)
+
+var callSecretService = callGetDecryptedParameters
+
+// secret is a parsed reference to a secrets manager secret
+type secret struct {
+	ID      string
+	JSONKey string
+}
+
+// ResolveSecrets resolves SecureString parameter references of the format {{ssm-secure:name}} and {{ssm-secure:name:version}},
+// and secrets manager references of the format {{resolve:secretsmanager:secret-id}} and
+// {{resolve:secretsmanager:secret-id:SecretString:json-key}}, where secret-id is the name or the ARN of the secret.
+// The values of the secrets are returned as well so that they can be redacted from the output, they must never be logged or persisted.
+func ResolveSecrets(log log.T, input interface{}) (resolved interface{}, secrets []string, err error) {
+	var secureStrings, secretValues []string
+	if resolved, secureStrings, err = resolveSecureStrings(log, input); err != nil {
+		return input, nil, err
+	}
+	if resolved, secretValues, err = resolveSecretsManagerReferences(log, resolved); err != nil {
+		return input, nil, err
+	}
+	return resolved, append(secureStrings, secretValues...), nil
+}
+
+// resolveSecretsManagerReferences resolves the secrets manager references
+func resolveSecretsManagerReferences(log log.T, input interface{}) (resolved interface{}, secrets []string, err error) {
+	references := extractSSMParameters(log, input, secretReference)
+	if len(references) == 0 {
+		return input, nil, nil
+	}
+
+	parsed := map[string]secret{}
+	secretIDs := []string{}
+	seen := map[string]bool{}
+	for _, reference := range references {
+		if parsed[reference], err = parseSecretReference(reference); err != nil {
+			return input, nil, err
+		}
+		if id := parsed[reference].ID; !seen[id] {
+			seen[id] = true
+			secretIDs = append(secretIDs, secretsManagerReferencePrefix+id)
+		}
+	}
+
+	result, err := callSecretService(log, secretIDs)
+	if err != nil {
+		return input, nil, err
+	}
+	if len(result.InvalidParameters) > 0 {
+		return input, nil, fmt.Errorf("Secrets %v could not be found", trimSecretPrefix(result.InvalidParameters))
+	}
+	values := map[string]string{}
+	for _, parameter := range result.Parameters {
+		values[strings.TrimPrefix(parameter.Name, secretsManagerReferencePrefix)] = parameter.Value
+	}
+
+	resolvedSecrets := map[string]Parameter{}
+	seen = map[string]bool{}
+	for reference, secret := range parsed {
+		value, found := values[secret.ID]
+		if !found {
+			return input, nil, fmt.Errorf("Secret %v could not be found", secret.ID)
+		}
+		if value, err = selectSecretValue(secret, value); err != nil {
+			return input, nil, err
+		}
+		resolvedSecrets[reference] = Parameter{Name: secret.ID, Type: ParamTypeString, Value: value}
+		if value != "" && !seen[value] {
+			seen[value] = true
+			secrets = append(secrets, value)
+		}
+	}
+
+	if resolved, err = replaceSSMParameters(log, input, resolvedSecrets); err != nil {
+		return input, nil, err
+	}
+	return resolved, secrets, nil
+}
+
+// parseSecretReference splits a reference into the id of the secret and the optional json key.
+// The ARN of a secret contains colons itself, so it's taken as a whole.
+func parseSecretReference(reference string) (result secret, err error) {
+	content := strings.TrimSpace(strings.Trim(reference, "{}"))
+	fields := strings.Split(strings.TrimPrefix(content, "resolve:secretsmanager:"), ":")
+
+	idFields := 1
+	if fields[0] == "arn" {
+		// arn:partition:secretsmanager:region:account-id:secret:secret-name
+		idFields = 7
+	}
+	if len(fields) < idFields {
+		return result, fmt.Errorf("Invalid secret reference %v", reference)
+	}
+	result.ID = strings.Join(fields[:idFields], ":")
+
+	switch rest := fields[idFields:]; len(rest) {
+	case 0:
+	case 2:
+		if rest[0] != secretStringField || rest[1] == "" {
+			return result, fmt.Errorf("Invalid secret reference %v, expected %v:json-key after the secret id", reference, secretStringField)
+		}
+		result.JSONKey = rest[1]
+	default:
+		return result, fmt.Errorf("Invalid secret reference %v, expected %v:json-key after the secret id", reference, secretStringField)
+	}
+	return result, nil
+}
+
+// selectSecretValue returns the value of the json key the reference selects, or the whole secret string
+func selectSecretValue(secret secret, value string) (string, error) {
+	if secret.JSONKey == "" {
+		return value, nil
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(value), &fields); err != nil {
+		return "", fmt.Errorf("Secret %v is not a json object", secret.ID)
+	}
+	field, found := fields[secret.JSONKey]
+	if !found {
+		return "", fmt.Errorf("Secret %v doesn't contain the key %v", secret.ID, secret.JSONKey)
+	}
+	if fieldValue, ok := field.(string); ok {
+		return fieldValue, nil
+	}
+	return jsonutil.Marshal(field)
+}
+
+func trimSecretPrefix(names []string) []string {
+	ids := make([]string, len(names))
+	for i, name := range names {
+		ids[i] = strings.TrimPrefix(name, secretsManagerReferencePrefix)
+	}
+	return ids
+}
+
+// callGetDecryptedParameters makes a GetParameters API call to the service with decryption of the values
+func callGetDecryptedParameters(log log.T, paramNames []string) (*GetParametersResponse, error) {
+	finalResult := GetParametersResponse{}
+
+	ssmSvc := ssm.NewService()
+
+	for i := 0; i < len(paramNames); i = i + MaxParametersPerCall {
+		limit := i + MaxParametersPerCall
+		if limit > len(paramNames) {
+			limit = len(paramNames)
+		}
+
+		result, err := ssmSvc.GetDecryptedParameters(log, paramNames[i:limit])
+		if err != nil {
+			return nil, err
+		}
+
+		var response GetParametersResponse
+		err = jsonutil.Remarshal(result, &response)
+		if err != nil {
+			log.Debug(err)
+			return nil, fmt.Errorf("%v", ErrorMsg)
+		}
+
+		finalResult.Parameters = append(finalResult.Parameters, response.Parameters...)
+		finalResult.InvalidParameters = append(finalResult.InvalidParameters, response.InvalidParameters...)
+	}
+
+	return &finalResult, nil
+}
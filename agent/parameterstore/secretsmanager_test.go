@@ -0,0 +1,111 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package parameterstore contains modules to resolve ssm parameters present in the document.
+package parameterstore
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/stretchr/testify/assert"
+)
+
+const testSecretARN = "arn:aws:secretsmanager:us-east-1:123456789012:secret:prod/db-AbCdEf"
+
+// stubSecretService makes the secrets with the given ids available and records the names requested
+func stubSecretService(secrets map[string]string) (requested *[]string) {
+	requested = &[]string{}
+	callSecretService = func(log log.T, paramNames []string) (*GetParametersResponse, error) {
+		*requested = append(*requested, paramNames...)
+		response := &GetParametersResponse{}
+		for _, name := range paramNames {
+			if value, found := secrets[name[len(secretsManagerReferencePrefix):]]; found {
+				response.Parameters = append(response.Parameters, Parameter{Name: name, Type: ParamTypeSecureString, Value: value})
+			} else {
+				response.InvalidParameters = append(response.InvalidParameters, name)
+			}
+		}
+		return response, nil
+	}
+	return requested
+}
+
+func TestResolveSecrets(t *testing.T) {
+	defer func() { callSecretService = callGetDecryptedParameters }()
+	requested := stubSecretService(map[string]string{
+		"api-token":   "t0k3n",
+		testSecretARN: `{"username":"admin","password":"hunter2","port":5432}`,
+	})
+
+	input := map[string]interface{}{
+		"runCommand": []interface{}{
+			"curl -H 'Authorization: {{ resolve:secretsmanager:api-token }}' https://example.com",
+			"psql -U {{resolve:secretsmanager:" + testSecretARN + ":SecretString:username}} -p {{resolve:secretsmanager:" + testSecretARN + ":SecretString:port}}",
+		},
+		"environment": map[string]interface{}{
+			"PGPASSWORD": "{{resolve:secretsmanager:" + testSecretARN + ":SecretString:password}}",
+		},
+		"timeoutSeconds": "{{ssm:timeout}}",
+	}
+	resolved, secrets, err := ResolveSecrets(log.NewMockLog(), input)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"runCommand": []string{
+			"curl -H 'Authorization: t0k3n' https://example.com",
+			"psql -U admin -p 5432",
+		},
+		"environment":    map[string]interface{}{"PGPASSWORD": "hunter2"},
+		"timeoutSeconds": "{{ssm:timeout}}",
+	}, resolved)
+	sort.Strings(secrets)
+	assert.Equal(t, []string{"5432", "admin", "hunter2", "t0k3n"}, secrets)
+	sort.Strings(*requested)
+	assert.Equal(t, []string{secretsManagerReferencePrefix + "api-token", secretsManagerReferencePrefix + testSecretARN}, *requested)
+	// the input is left as is
+	assert.Equal(t, "{{resolve:secretsmanager:"+testSecretARN+":SecretString:password}}", input["environment"].(map[string]interface{})["PGPASSWORD"])
+}
+
+func TestResolveSecretsWithoutReferences(t *testing.T) {
+	defer func() { callSecretService = callGetDecryptedParameters }()
+	requested := stubSecretService(nil)
+
+	input := map[string]interface{}{"runCommand": "echo {{ssm:message}}"}
+	resolved, secrets, err := ResolveSecrets(log.NewMockLog(), input)
+
+	assert.NoError(t, err)
+	assert.Equal(t, input, resolved)
+	assert.Empty(t, secrets)
+	assert.Empty(t, *requested)
+}
+
+func TestResolveSecretsErrors(t *testing.T) {
+	defer func() { callSecretService = callGetDecryptedParameters }()
+	stubSecretService(map[string]string{"api-token": "t0k3n", "config": `{"user":"admin"}`})
+
+	for _, input := range []string{
+		"{{resolve:secretsmanager:missing}}",
+		"{{resolve:secretsmanager:api-token:SecretString:user}}",
+		"{{resolve:secretsmanager:config:SecretString:password}}",
+		"{{resolve:secretsmanager:config:SecretBinary:user}}",
+		"{{resolve:secretsmanager:config:SecretString}}",
+		"{{resolve:secretsmanager:arn:aws:secretsmanager:us-east-1:123456789012}}",
+	} {
+		_, secrets, err := ResolveSecrets(log.NewMockLog(), input)
+		assert.Error(t, err, input)
+		assert.NotContains(t, err.Error(), "t0k3n")
+		assert.Empty(t, secrets)
+	}
+}
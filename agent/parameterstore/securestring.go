@@ -0,0 +1,235 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package parameterstore contains modules to resolve ssm parameters present in the document.
+package parameterstore
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/ssm"
+)
+
+// secureStringReference matches references of the format {{ssm-secure:*}}
+var secureStringReference = regexp.MustCompile(`\{\{ *ssm-secure:[/\w.:-]+ *\}\}`)
+
+// Assign method to global variables to allow unittest to override
+var (
+	callSecureStringService = callGetDecryptedParameters
+	callParameterHistory    = parameterHistoryKeys
+	configuredKmsKeyID      = kmsKeyIDFromConfig
+)
+
+// secureStringParameter is a parsed reference to a SecureString parameter, Version is 0 if it refers to the latest version
+type secureStringParameter struct {
+	Name    string
+	Version int64
+}
+
+// selector returns the name of the parameter as GetParameters expects it, with the version if there's one
+func (parameter secureStringParameter) selector() string {
+	if parameter.Version == 0 {
+		return parameter.Name
+	}
+	return fmt.Sprintf("%v:%d", parameter.Name, parameter.Version)
+}
+
+// resolveSecureStrings resolves SecureString parameter references of the format {{ssm-secure:name}} and {{ssm-secure:name:version}}.
+// The parameters are decrypted by the service when the plugin is about to run, if a KMS key is configured for the agent
+// only parameters encrypted with that key are accepted.
+func resolveSecureStrings(log log.T, input interface{}) (resolved interface{}, secrets []string, err error) {
+	references := extractSSMParameters(log, input, secureStringReference)
+	if len(references) == 0 {
+		return input, nil, nil
+	}
+
+	parsed := map[string]secureStringParameter{}
+	parameters := []secureStringParameter{}
+	selectors := []string{}
+	seen := map[string]bool{}
+	for _, reference := range references {
+		if parsed[reference], err = parseSecureStringReference(reference); err != nil {
+			return input, nil, err
+		}
+		if selector := parsed[reference].selector(); !seen[selector] {
+			seen[selector] = true
+			parameters = append(parameters, parsed[reference])
+			selectors = append(selectors, selector)
+		}
+	}
+
+	checkedLatest, err := checkKmsKey(log, parameters)
+	if err != nil {
+		return input, nil, err
+	}
+
+	result, err := callSecureStringService(log, selectors)
+	if err != nil {
+		return input, nil, err
+	}
+	if len(result.InvalidParameters) > 0 {
+		return input, nil, fmt.Errorf("Input contains invalid parameters %v", result.InvalidParameters)
+	}
+
+	resolvedParameters := map[string]Parameter{}
+	seen = map[string]bool{}
+	for reference, parameter := range parsed {
+		value, found := findParameter(result.Parameters, parameter)
+		if !found {
+			return input, nil, fmt.Errorf("Parameter %v could not be found", parameter.Name)
+		}
+		if value.Type != ParamTypeSecureString {
+			return input, nil, fmt.Errorf("Parameter %v of type %v can't be referred to with ssm-secure, use {{ssm:%v}} instead", value.Name, value.Type, value.Name)
+		}
+		if version, checked := checkedLatest[parameter.Name]; checked && parameter.Version == 0 && value.Version != version {
+			return input, nil, fmt.Errorf("Parameter %v changed while it was resolved, version %v wasn't checked against the KMS key configured for the agent", value.Name, value.Version)
+		}
+		resolvedParameters[reference] = value
+		if value.Value != "" && !seen[value.Value] {
+			seen[value.Value] = true
+			secrets = append(secrets, value.Value)
+		}
+	}
+
+	if resolved, err = replaceSSMParameters(log, input, resolvedParameters); err != nil {
+		return input, nil, err
+	}
+	return resolved, secrets, nil
+}
+
+// parseSecureStringReference splits a reference into the name of the parameter and the optional version
+func parseSecureStringReference(reference string) (result secureStringParameter, err error) {
+	content := strings.TrimSpace(strings.Trim(reference, "{}"))
+	fields := strings.Split(strings.TrimPrefix(content, "ssm-secure:"), ":")
+
+	result.Name = fields[0]
+	switch len(fields) {
+	case 1:
+	case 2:
+		if result.Version, err = strconv.ParseInt(fields[1], 10, 64); err != nil || result.Version < 1 {
+			return result, fmt.Errorf("Invalid parameter reference %v, the version must be a positive number", reference)
+		}
+	default:
+		return result, fmt.Errorf("Invalid parameter reference %v", reference)
+	}
+	if result.Name == "" {
+		return result, fmt.Errorf("Invalid parameter reference %v", reference)
+	}
+	return result, nil
+}
+
+// findParameter returns the version of the parameter the reference selects, the latest one if the reference has no version
+func findParameter(parameters []Parameter, reference secureStringParameter) (result Parameter, found bool) {
+	for _, parameter := range parameters {
+		if parameter.Name != reference.Name {
+			continue
+		}
+		if reference.Version == 0 && (!found || parameter.Version > result.Version) {
+			result, found = parameter, true
+		} else if parameter.Version == reference.Version {
+			return parameter, true
+		}
+	}
+	return result, found
+}
+
+// checkKmsKey verifies the referenced versions of the parameters are encrypted with the KMS key configured for the agent, if any.
+// It returns the latest version of the parameters referred to without a version, the version their key was checked for.
+func checkKmsKey(log log.T, parameters []secureStringParameter) (checkedLatest map[string]int64, err error) {
+	configuredKey := configuredKmsKeyID(log)
+	if configuredKey == "" {
+		return nil, nil
+	}
+
+	checkedLatest = map[string]int64{}
+	history := map[string]map[int64]string{}
+	for _, parameter := range parameters {
+		keys, found := history[parameter.Name]
+		if !found {
+			if keys, err = callParameterHistory(log, parameter.Name); err != nil {
+				return nil, err
+			}
+			history[parameter.Name] = keys
+		}
+
+		version := parameter.Version
+		if version == 0 {
+			for existing := range keys {
+				if existing > version {
+					version = existing
+				}
+			}
+			checkedLatest[parameter.Name] = version
+		}
+		keyID, found := keys[version]
+		if !found {
+			return nil, fmt.Errorf("Input contains invalid parameters [%v]", parameter.selector())
+		}
+		if !isSameKmsKey(keyID, configuredKey) {
+			return nil, fmt.Errorf("Parameter %v is not encrypted with the KMS key %v configured for the agent", parameter.selector(), configuredKey)
+		}
+	}
+	return checkedLatest, nil
+}
+
+// isSameKmsKey compares key ids, aliases and their ARNs, e.g. arn:aws:kms:us-east-1:123456789012:key/1234abcd matches 1234abcd
+// and arn:aws:kms:us-east-1:123456789012:alias/ssm matches alias/ssm
+func isSameKmsKey(keyID, configuredKey string) bool {
+	if keyID == configuredKey {
+		return true
+	}
+	for _, pair := range [][2]string{{keyID, configuredKey}, {configuredKey, keyID}} {
+		if strings.HasPrefix(pair[0], "arn:") && (strings.HasSuffix(pair[0], ":"+pair[1]) || strings.HasSuffix(pair[0], ":key/"+pair[1])) {
+			return true
+		}
+	}
+	return false
+}
+
+func kmsKeyIDFromConfig(log log.T) string {
+	config, err := appconfig.Config(false)
+	if err != nil {
+		log.Warnf("Failed to load appconfig: %v. No KMS key is enforced for SecureString parameters.", err)
+		return ""
+	}
+	return config.Kms.KeyId
+}
+
+// parameterHistoryKeys makes GetParameterHistory API calls to the service and returns the KMS key of each version of the parameter
+func parameterHistoryKeys(log log.T, paramName string) (map[int64]string, error) {
+	keys := map[int64]string{}
+
+	ssmSvc := ssm.NewService()
+
+	var nextToken *string
+	for {
+		result, err := ssmSvc.GetParameterHistory(log, paramName, nextToken)
+		if err != nil {
+			return nil, err
+		}
+		for _, parameter := range result.Parameters {
+			if parameter.Version != nil && parameter.KeyId != nil {
+				keys[*parameter.Version] = *parameter.KeyId
+			}
+		}
+		if result.NextToken == nil || *result.NextToken == "" {
+			return keys, nil
+		}
+		nextToken = result.NextToken
+	}
+}
@@ -0,0 +1,174 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package parameterstore contains modules to resolve ssm parameters present in the document.
+package parameterstore
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/stretchr/testify/assert"
+)
+
+const testKeyARN = "arn:aws:kms:us-east-1:123456789012:key/1234abcd-12ab-34cd-56ef-1234567890ab"
+
+// stubSecureStringService makes the given versions of the parameters available, all the versions of a parameter are
+// encrypted with its key, and enforces the configured key. The selectors requested are recorded.
+func stubSecureStringService(parameters []Parameter, keys map[string]string, configuredKey string) (requested *[]string, restore func()) {
+	requested = &[]string{}
+	callSecureStringService = func(log log.T, paramNames []string) (*GetParametersResponse, error) {
+		*requested = append(*requested, paramNames...)
+		response := &GetParametersResponse{}
+		for _, name := range paramNames {
+			found := false
+			for _, parameter := range parameters {
+				if name == parameter.Name || name == fmt.Sprintf("%v:%d", parameter.Name, parameter.Version) {
+					response.Parameters = append(response.Parameters, parameter)
+					found = true
+				}
+			}
+			if !found {
+				response.InvalidParameters = append(response.InvalidParameters, name)
+			}
+		}
+		return response, nil
+	}
+	callParameterHistory = func(log log.T, paramName string) (map[int64]string, error) {
+		history := map[int64]string{}
+		for _, parameter := range parameters {
+			if keyID, found := keys[parameter.Name]; found && parameter.Name == paramName {
+				history[parameter.Version] = keyID
+			}
+		}
+		return history, nil
+	}
+	configuredKmsKeyID = func(log log.T) string { return configuredKey }
+	return requested, func() {
+		callSecureStringService = callGetDecryptedParameters
+		callParameterHistory = parameterHistoryKeys
+		configuredKmsKeyID = kmsKeyIDFromConfig
+	}
+}
+
+func TestResolveSecureStrings(t *testing.T) {
+	requested, restore := stubSecureStringService([]Parameter{
+		{Name: "/prod/db/password", Type: ParamTypeSecureString, Value: "hunter2", Version: 3},
+		{Name: "api-token", Type: ParamTypeSecureString, Value: "t0k3n-v1", Version: 1},
+	}, map[string]string{"/prod/db/password": "1234abcd-12ab-34cd-56ef-1234567890ab", "api-token": testKeyARN}, testKeyARN)
+	defer restore()
+
+	input := map[string]interface{}{
+		"runCommand": []interface{}{
+			"mysql -p{{ ssm-secure:/prod/db/password }} -e 'select 1'",
+			"curl -H 'Authorization: {{ssm-secure:api-token:1}}' https://example.com",
+		},
+		"environment":    map[string]interface{}{"PASSWORD": "{{ssm-secure:/prod/db/password}}"},
+		"timeoutSeconds": "{{ssm:timeout}}",
+	}
+	resolved, secrets, err := ResolveSecrets(log.NewMockLog(), input)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"runCommand": []string{
+			"mysql -phunter2 -e 'select 1'",
+			"curl -H 'Authorization: t0k3n-v1' https://example.com",
+		},
+		"environment":    map[string]interface{}{"PASSWORD": "hunter2"},
+		"timeoutSeconds": "{{ssm:timeout}}",
+	}, resolved)
+	sort.Strings(secrets)
+	assert.Equal(t, []string{"hunter2", "t0k3n-v1"}, secrets)
+	sort.Strings(*requested)
+	assert.Equal(t, []string{"/prod/db/password", "api-token:1"}, *requested)
+	// the input is left as is
+	assert.Equal(t, "{{ssm-secure:/prod/db/password}}", input["environment"].(map[string]interface{})["PASSWORD"])
+}
+
+func TestResolveSecureStringsSelectsVersion(t *testing.T) {
+	_, restore := stubSecureStringService([]Parameter{
+		{Name: "token", Type: ParamTypeSecureString, Value: "old-token", Version: 2},
+		{Name: "token", Type: ParamTypeSecureString, Value: "new-token", Version: 3},
+	}, nil, "")
+	defer restore()
+
+	resolved, secrets, err := ResolveSecrets(log.NewMockLog(), "{{ssm-secure:token:2}} {{ssm-secure:token}}")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "old-token new-token", resolved)
+	assert.Len(t, secrets, 2)
+}
+
+func TestResolveSecureStringsErrors(t *testing.T) {
+	_, restore := stubSecureStringService([]Parameter{
+		{Name: "token", Type: ParamTypeSecureString, Value: "t0k3n", Version: 1},
+		{Name: "plain", Type: ParamTypeString, Value: "not-a-secret", Version: 1},
+	}, map[string]string{"token": "alias/aws/ssm", "plain": "alias/aws/ssm"}, "")
+	defer restore()
+
+	for _, input := range []string{
+		"{{ssm-secure:missing}}",
+		"{{ssm-secure:plain}}",
+		"{{ssm-secure:token:0}}",
+		"{{ssm-secure:token:latest}}",
+		"{{ssm-secure:token:1:2}}",
+	} {
+		_, secrets, err := ResolveSecrets(log.NewMockLog(), input)
+		assert.Error(t, err, input)
+		assert.Empty(t, secrets)
+	}
+
+	// parameters encrypted with another key than the one configured are rejected before they are decrypted
+	configuredKmsKeyID = func(log log.T) string { return testKeyARN }
+	_, secrets, err := ResolveSecrets(log.NewMockLog(), "{{ssm-secure:token}}")
+	assert.EqualError(t, err, "Parameter token is not encrypted with the KMS key "+testKeyARN+" configured for the agent")
+	assert.Empty(t, secrets)
+}
+
+func TestResolveSecureStringsChecksKeyOfVersion(t *testing.T) {
+	_, restore := stubSecureStringService([]Parameter{
+		{Name: "token", Type: ParamTypeSecureString, Value: "old-token", Version: 1},
+		{Name: "token", Type: ParamTypeSecureString, Value: "new-token", Version: 2},
+	}, nil, testKeyARN)
+	defer restore()
+	// the parameter was encrypted with the default key before it was rotated to the configured key
+	callParameterHistory = func(log log.T, paramName string) (map[int64]string, error) {
+		return map[int64]string{1: "alias/aws/ssm", 2: testKeyARN}, nil
+	}
+
+	resolved, _, err := ResolveSecrets(log.NewMockLog(), "{{ssm-secure:token}} {{ssm-secure:token:2}}")
+	assert.NoError(t, err)
+	assert.Equal(t, "new-token new-token", resolved)
+
+	_, secrets, err := ResolveSecrets(log.NewMockLog(), "{{ssm-secure:token:1}}")
+	assert.EqualError(t, err, "Parameter token:1 is not encrypted with the KMS key "+testKeyARN+" configured for the agent")
+	assert.Empty(t, secrets)
+
+	// a version put after the key of the latest version was checked isn't used
+	callParameterHistory = func(log log.T, paramName string) (map[int64]string, error) {
+		return map[int64]string{1: testKeyARN}, nil
+	}
+	_, secrets, err = ResolveSecrets(log.NewMockLog(), "{{ssm-secure:token}}")
+	assert.Error(t, err)
+	assert.Empty(t, secrets)
+}
+
+func TestIsSameKmsKey(t *testing.T) {
+	assert.True(t, isSameKmsKey("1234abcd-12ab-34cd-56ef-1234567890ab", testKeyARN))
+	assert.True(t, isSameKmsKey(testKeyARN, "1234abcd-12ab-34cd-56ef-1234567890ab"))
+	assert.True(t, isSameKmsKey("alias/ssm", "arn:aws:kms:us-east-1:123456789012:alias/ssm"))
+	assert.False(t, isSameKmsKey("alias/aws/ssm", testKeyARN))
+	assert.False(t, isSameKmsKey("5678abcd-12ab-34cd-56ef-1234567890ab", testKeyARN))
+}
@@ -25,6 +25,9 @@ var lock sync.RWMutex
 
 const errorMessage = "Failed to fetch %s. Data from vault is empty. %v"
 
+// instanceTagsPath is the metadata path listing the tag keys of the instance
+const instanceTagsPath = "tags/instance"
+
 // InstanceID returns the current instance id
 func InstanceID() (string, error) {
 	lock.RLock()
@@ -135,6 +138,33 @@ func IsManagedInstance() (bool, error) {
 	return false, nil
 }
 
+// InstanceTags returns the tags of the current instance from EC2 Instance Metadata.
+// Tags are only available on EC2 instances that allow access to tags in instance metadata,
+// they are not cached since they can change while the agent is running.
+func InstanceTags() (map[string]string, error) {
+	if managedInstance.InstanceID() != "" {
+		return nil, fmt.Errorf("instance tags are not available for managed instances")
+	}
+
+	keys, err := metadata.GetMetadata(instanceTagsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch instance tags, make sure access to tags in instance metadata is allowed. %v", err)
+	}
+
+	tags := make(map[string]string)
+	for _, key := range strings.Split(keys, "\n") {
+		if key = strings.TrimSpace(key); key == "" {
+			continue
+		}
+		value, err := metadata.GetMetadata(instanceTagsPath + "/" + key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch instance tag %v. %v", key, err)
+		}
+		tags[key] = value
+	}
+	return tags, nil
+}
+
 // fetchInstanceID fetches the instance id with the following preference order.
 // 1. managed instance registration
 // 2. EC2 Instance Metadata
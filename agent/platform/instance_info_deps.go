@@ -19,8 +19,6 @@ import (
 
 	"github.com/aws/amazon-ssm-agent/agent/managedInstances/registration"
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/ec2metadata"
-	"github.com/aws/aws-sdk-go/aws/session"
 )
 
 // dependency for managed instance registration
@@ -49,7 +47,7 @@ func (instanceInfo) AvailabilityZone() string { return registration.Availability
 
 // dependency for metadata
 var metadata metadataClient = instanceMetadata{
-	Client: ec2metadata.New(session.New(aws.NewConfig().WithMaxRetries(10).WithEC2MetadataDisableTimeoutOverride(false))),
+	Config: aws.NewConfig().WithMaxRetries(10).WithEC2MetadataDisableTimeoutOverride(false),
 }
 
 type metadataClient interface {
@@ -64,22 +62,23 @@ func init() {
 	// Macs don't have instance metadata
 	if runtime.GOOS == "darwin" {
 		metadata = instanceMetadata{
-			Client: ec2metadata.New(session.New(aws.NewConfig().WithMaxRetries(0).WithEC2MetadataDisableTimeoutOverride(true))),
+			Config: aws.NewConfig().WithMaxRetries(0).WithEC2MetadataDisableTimeoutOverride(true),
 		}
 	}
 }
 
+// instanceMetadata creates the client when it is used, once the endpoint mode of the instance metadata is configured
 type instanceMetadata struct {
-	Client *ec2metadata.EC2Metadata
+	Config *aws.Config
 }
 
 // GetMetadata uses the path provided to request
 func (c instanceMetadata) GetMetadata(p string) (string, error) {
-	return c.Client.GetMetadata(p)
+	return NewEC2MetadataService(c.Config).GetMetadata(p)
 }
 
 // Region returns the region the instance is running in.
-func (c instanceMetadata) Region() (string, error) { return c.Client.Region() }
+func (c instanceMetadata) Region() (string, error) { return NewEC2MetadataService(c.Config).Region() }
 
 // dependency for metadata
 var dynamicData dynamicDataClient = instanceDynamicData{
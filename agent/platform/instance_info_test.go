@@ -3,6 +3,7 @@ package platform
 import (
 	"errors"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -241,3 +242,40 @@ func TestFetchAvailabilityZoneForEc2Instance(t *testing.T) {
 	assert.Equal(t, value, actualOutput)
 	assert.Equal(t, nil, actualError)
 }
+
+// tagsMetadataStub answers the instance tags paths of the metadata
+type tagsMetadataStub struct {
+	metadataStub
+	tags map[string]string
+}
+
+func (c tagsMetadataStub) GetMetadata(p string) (string, error) {
+	if p == instanceTagsPath {
+		var keys []string
+		for key := range c.tags {
+			keys = append(keys, key)
+		}
+		return strings.Join(keys, "\n"), nil
+	}
+	return c.tags[strings.TrimPrefix(p, instanceTagsPath+"/")], nil
+}
+
+func TestInstanceTags(t *testing.T) {
+	tags := map[string]string{"Name": "web-1", "Environment": "prod"}
+	metadata = tagsMetadataStub{tags: tags}
+	managedInstance = invalidRegistration
+	actualOutput, actualError := InstanceTags()
+	assert.Equal(t, tags, actualOutput)
+	assert.Nil(t, actualError)
+
+	// tags are not available when access to tags in instance metadata is not allowed
+	metadata = invalidMetadata
+	_, actualError = InstanceTags()
+	assert.NotNil(t, actualError)
+
+	// nor for managed instances
+	metadata = tagsMetadataStub{tags: tags}
+	managedInstance = validRegistration
+	_, actualError = InstanceTags()
+	assert.NotNil(t, actualError)
+}
@@ -18,11 +18,20 @@ import (
 	"io/ioutil"
 	"net/http"
 	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/ec2metadata"
+	"github.com/aws/aws-sdk-go/aws/session"
 )
 
 const (
 	// EC2MetadataServiceURL is url for instance metadata.
 	EC2MetadataServiceURL = "http://169.254.169.254"
+	// EC2MetadataServiceIPv6URL is url for instance metadata on IPv6-only instances.
+	EC2MetadataServiceIPv6URL = "http://[fd00:ec2::254]"
+	// ec2MetadataAPIPath is the path of the instance metadata api the sdk client expects in its endpoint
+	ec2MetadataAPIPath = "/latest"
 	// SecurityCredentialsResource provides iam credentials
 	SecurityCredentialsResource = "/latest/meta-data/iam/security-credentials/"
 	// InstanceIdentityDocumentResource provides instance information like instance id, region, availability
@@ -43,6 +52,7 @@ const (
   "privateIp" : "172.31.32.24",
   "instanceId" : "i-e0a8424b",
   "billingProducts" : null,
+  "marketplaceProductCodes" : null,
   "version" : "2010-08-31",
   "instanceType" : "m3.medium",
   "accountId" : "099688301723",
@@ -57,6 +67,7 @@ const (
 type InstanceIdentityDocument struct {
 	InstanceID          string   `json:"instanceId"`
 	BillingProducts     []string `json:"billingProducts"`
+	MarketplaceProducts []string `json:"marketplaceProductCodes"`
 	ImageID             string   `json:"imageId"`
 	Architecture        string   `json:"architecture"`
 	PendingTimeAsString string   `json:"pendingTime"`
@@ -115,7 +126,20 @@ func (c EC2MetadataClient) InstanceIdentityDocument() (*InstanceIdentityDocument
 }
 
 func (c EC2MetadataClient) resourceServiceURL(path string) string {
-	return EC2MetadataServiceURL + path
+	return GetEC2MetadataServiceURL() + path
+}
+
+// GetEC2MetadataServiceURL returns the url for instance metadata, over IPv6 when the agent is configured for IPv6-only instances
+func GetEC2MetadataServiceURL() string {
+	if config, err := appconfig.Config(false); err == nil && config.Agent.Ec2MetadataEndpointMode == appconfig.Ec2MetadataEndpointModeIPv6 {
+		return EC2MetadataServiceIPv6URL
+	}
+	return EC2MetadataServiceURL
+}
+
+// NewEC2MetadataService creates an sdk instance metadata client with the config, reaching the instance metadata at GetEC2MetadataServiceURL
+func NewEC2MetadataService(config *aws.Config) *ec2metadata.EC2Metadata {
+	return ec2metadata.New(session.New(config.Copy().WithEndpoint(GetEC2MetadataServiceURL() + ec2MetadataAPIPath)))
 }
 
 // ReadResource reads from the url path
@@ -32,6 +32,7 @@ import (
 	"github.com/aws/amazon-ssm-agent/agent/plugins/configurepackage/installer"
 	"github.com/aws/amazon-ssm-agent/agent/plugins/configurepackage/localpackages"
 	"github.com/aws/amazon-ssm-agent/agent/plugins/configurepackage/packageservice"
+	"github.com/aws/amazon-ssm-agent/agent/plugins/configurepackage/privatesource"
 	"github.com/aws/amazon-ssm-agent/agent/plugins/configurepackage/ssms3"
 	"github.com/aws/amazon-ssm-agent/agent/plugins/configurepackage/trace"
 	"github.com/aws/amazon-ssm-agent/agent/task"
@@ -66,6 +67,23 @@ type ConfigurePackagePluginInput struct {
 	Action     string `json:"action"`
 	Source     string `json:"source"`
 	Repository string `json:"repository"`
+
+	// SourceAuthType is None, Basic or SigV4, S3 sources are accessed with the instance credentials
+	SourceAuthType       string `json:"sourceAuthType"`
+	SourceUsername       string `json:"sourceUsername"`
+	SourcePassword       string `json:"sourcePassword"`
+	SourceSigningService string `json:"sourceSigningService"`
+}
+
+// privateSource returns the repository of packages the input refers to with its credentials
+func (input *ConfigurePackagePluginInput) privateSource() privatesource.Source {
+	return privatesource.Source{
+		URL:            input.Source,
+		AuthType:       input.SourceAuthType,
+		Username:       input.SourceUsername,
+		Password:       input.SourcePassword,
+		SigningService: input.SourceSigningService,
+	}
 }
 
 // NewPlugin returns a new instance of the plugin.
@@ -278,16 +296,23 @@ func parseAndValidateInput(rawPluginInput interface{}) (*ConfigurePackagePluginI
 
 // validateInput ensures the plugin input matches the defined schema
 func validateInput(input *ConfigurePackagePluginInput) (valid bool, err error) {
-	// source not yet supported
-	if input.Source != "" {
-		return false, errors.New("source parameter is not supported in this version")
-	}
-
 	// ensure non-empty name
 	if input.Name == "" {
 		return false, errors.New("empty name field")
 	}
 
+	// packages from a source are resolved with version constraints
+	if input.Source != "" {
+		source := input.privateSource()
+		if err := privatesource.ValidateSource(&source); err != nil {
+			return false, err
+		}
+		input.SourceAuthType = source.AuthType
+		if err := privatesource.ValidateVersionConstraint(input.Version); err != nil {
+			return false, err
+		}
+	}
+
 	// dump any unsupported value for Repository
 	if input.Repository != "beta" && input.Repository != "gamma" {
 		input.Repository = ""
@@ -385,6 +410,12 @@ func selectService(tracer trace.Tracer, input *ConfigurePackagePluginInput, loca
 	response := &ssm.GetManifestOutput{}
 	var err error
 
+	if input.Source != "" {
+		*isDocumentArchive = false
+		tracer.CurrentTrace().AppendInfof("using packages from source %v", input.Source)
+		return privatesource.New(input.privateSource(), region, localrepo), nil
+	}
+
 	if (appCfg != nil && appCfg.Birdwatcher.ForceEnable) || !ssms3.UseSSMS3Service(tracer, serviceEndpoint, region) {
 		// This indicates that it would be the birdwatcher service.
 		// Before creating an object of type birdwatcher here, check if the name is of document arn. If it is, return with a Document type service
@@ -597,7 +597,39 @@ func TestValidateInput_Source(t *testing.T) {
 
 	assert.False(t, result)
 	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "source parameter is not supported")
+	assert.Contains(t, err.Error(), "unsupported source")
+}
+
+func TestValidateInput_SourceValid(t *testing.T) {
+	input := ConfigurePackagePluginInput{}
+
+	input.Version = ">=1.0, <2.0"
+	input.Name = "PVDriver"
+	input.Action = "Install"
+	input.Source = "https://packages.example.com/repo"
+	input.SourceAuthType = "basic"
+	input.SourceUsername = "user"
+	input.SourcePassword = "password"
+
+	result, err := validateInput(&input)
+
+	assert.True(t, result)
+	assert.NoError(t, err)
+	assert.Equal(t, "Basic", input.SourceAuthType)
+}
+
+func TestValidateInput_SourceInvalid(t *testing.T) {
+	for _, input := range []ConfigurePackagePluginInput{
+		{Name: "PVDriver", Source: "s3://bucket/repo", SourceAuthType: "SigV4"},
+		{Name: "PVDriver", Source: "https://packages.example.com/repo", SourceAuthType: "Basic"},
+		{Name: "PVDriver", Source: "https://packages.example.com/repo", SourceAuthType: "Digest"},
+		{Name: "PVDriver", Source: "https://packages.example.com/repo", Version: ">1.0 ~"},
+	} {
+		result, err := validateInput(&input)
+
+		assert.False(t, result, "%v", input)
+		assert.Error(t, err)
+	}
 }
 
 func TestValidateInput_NameEmpty(t *testing.T) {
@@ -781,6 +813,24 @@ func TestSelectService(t *testing.T) {
 	}
 }
 
+func TestSelectService_Source(t *testing.T) {
+	isDocumentArchive := true
+	tracer := trace.NewTracer(contextMock.Log())
+	defer tracer.BeginSection("test").End()
+
+	input := &ConfigurePackagePluginInput{
+		Name:    "package",
+		Version: "~1.2",
+		Source:  "s3://bucket/repo",
+	}
+
+	result, err := selectService(tracer, input, localpackages.NewRepository(), &appconfig.SsmagentConfig{}, &facade.FacadeStub{}, &isDocumentArchive)
+
+	assert.NoError(t, err)
+	assert.Equal(t, packageservice.PackageServiceName_privatesource, result.PackageServiceName())
+	assert.False(t, isDocumentArchive)
+}
+
 // Integration tests
 func loadFile(t *testing.T, fileName string) (result []byte) {
 	result, err := ioutil.ReadFile(fileName)
@@ -50,9 +50,10 @@ type PackageService interface {
 }
 
 const (
-	PackageServiceName_ssms3       = "ssms3"
-	PackageServiceName_birdwatcher = "birdwatcherUsingBirdwatcherArchive"
-	PackageServiceName_document    = "birdwatcherUsingDocumentArchive"
+	PackageServiceName_ssms3         = "ssms3"
+	PackageServiceName_birdwatcher   = "birdwatcherUsingBirdwatcherArchive"
+	PackageServiceName_document      = "birdwatcherUsingDocumentArchive"
+	PackageServiceName_privatesource = "privateSource"
 )
 
 // ByTiming implements sort.Interface for []*packageservice.Trace based on the
@@ -0,0 +1,62 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package privatesource
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/fileutil/artifact"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/sdkutil"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+// httpClientTimeout is the time allowed for a request to an HTTPS source, including reading the body
+const httpClientTimeout = 30 * time.Minute
+
+// dependency on S3, HTTPS repositories and the instance credentials
+type networkDep interface {
+	Download(log log.T, input artifact.DownloadInput) (artifact.DownloadOutput, error)
+	Do(request *http.Request) (*http.Response, error)
+	Credentials() *credentials.Credentials
+}
+
+type networkDepImp struct{}
+
+var networkdep networkDep = &networkDepImp{}
+
+func (networkDepImp) Download(log log.T, input artifact.DownloadInput) (artifact.DownloadOutput, error) {
+	return artifact.Download(log, input)
+}
+
+func (networkDepImp) Do(request *http.Request) (*http.Response, error) {
+	client := &http.Client{
+		Timeout: httpClientTimeout,
+		// credentials are only sent to the source, not to the locations it redirects to
+		CheckRedirect: func(redirect *http.Request, via []*http.Request) error {
+			if redirect.URL.Host != via[0].URL.Host {
+				redirect.Header.Del("Authorization")
+			}
+			return nil
+		},
+	}
+	return client.Do(request)
+}
+
+func (networkDepImp) Credentials() *credentials.Credentials {
+	return session.New(sdkutil.AwsConfig()).Config.Credentials
+}
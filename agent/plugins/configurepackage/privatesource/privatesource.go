@@ -0,0 +1,468 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package privatesource implements a PackageService for packages hosted by the customer,
+// in a private S3 bucket or in an HTTPS repository.
+//
+// The versions of a package are listed in <source>/<package name>/manifest.json:
+//
+//	{
+//	  "name": "MyPackage",
+//	  "packages": [
+//	    {"version": "1.2.0", "platform": "linux", "arch": "amd64", "path": "1.2.0/MyPackage-linux-amd64.zip", "sha256": "..."}
+//	  ]
+//	}
+//
+// The path of a package is relative to the folder of the manifest, an empty platform or arch matches any instance.
+package privatesource
+
+import (
+	"crypto/sha1"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/fileutil"
+	"github.com/aws/amazon-ssm-agent/agent/fileutil/artifact"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/plugins/configurepackage/packageservice"
+	"github.com/aws/amazon-ssm-agent/agent/plugins/configurepackage/trace"
+	"github.com/aws/amazon-ssm-agent/agent/s3util"
+	v4 "github.com/aws/aws-sdk-go/aws/signer/v4"
+)
+
+const (
+	// AuthTypeNone sends no credentials to the source, S3 sources always use the instance credentials
+	AuthTypeNone = "None"
+
+	// AuthTypeBasic authenticates to an HTTPS source with a user name and password
+	AuthTypeBasic = "Basic"
+
+	// AuthTypeSigV4 signs the requests to an HTTPS source with the instance credentials
+	AuthTypeSigV4 = "SigV4"
+
+	// DefaultSigningService is the service requests are signed for, when the source is an API Gateway endpoint
+	DefaultSigningService = "execute-api"
+
+	// ManifestFileName is the name of the file listing the versions of a package in its folder of the source
+	ManifestFileName = "manifest.json"
+
+	// manifestCacheVersion is the key the manifest of a source is cached under, for each package
+	manifestCacheVersion = "source"
+
+	// artifactCacheFolder is the folder under the download root packages are cached in by their sha256
+	artifactCacheFolder = "privatesource"
+)
+
+// Source is the location and credentials of a repository of packages
+type Source struct {
+	URL            string
+	AuthType       string
+	Username       string
+	Password       string
+	SigningService string
+}
+
+// sourceManifest lists the versions of a package in a source
+type sourceManifest struct {
+	Name     string        `json:"name"`
+	Packages []packageFile `json:"packages"`
+}
+
+// packageFile is the package of one version for a platform and architecture
+type packageFile struct {
+	Version  string `json:"version"`
+	Platform string `json:"platform"`
+	Arch     string `json:"arch"`
+	Path     string `json:"path"`
+	SHA256   string `json:"sha256"`
+}
+
+type PackageService struct {
+	source            Source
+	sourceURL         *url.URL
+	region            string
+	manifestCache     packageservice.ManifestCache
+	manifests         map[string]*sourceManifest
+	downloadDirectory string
+	cacheDirectory    string
+}
+
+// ValidateSource checks the source is an s3:// or https:// URL and the authentication is supported for it,
+// the authentication type is normalized
+func ValidateSource(source *Source) error {
+	sourceURL, err := url.Parse(source.URL)
+	if err != nil || sourceURL.Host == "" {
+		return fmt.Errorf("invalid source %v, the source is an s3:// or https:// URL", source.URL)
+	}
+
+	switch {
+	case source.AuthType == "" || strings.EqualFold(source.AuthType, AuthTypeNone):
+		source.AuthType = AuthTypeNone
+	case strings.EqualFold(source.AuthType, AuthTypeBasic):
+		source.AuthType = AuthTypeBasic
+	case strings.EqualFold(source.AuthType, AuthTypeSigV4):
+		source.AuthType = AuthTypeSigV4
+	default:
+		return fmt.Errorf("unsupported source authentication type %v, the supported types are %v, %v and %v", source.AuthType, AuthTypeNone, AuthTypeBasic, AuthTypeSigV4)
+	}
+
+	switch strings.ToLower(sourceURL.Scheme) {
+	case "s3":
+		if source.AuthType != AuthTypeNone {
+			return fmt.Errorf("source authentication type %v is not supported for S3 sources, the instance credentials are used", source.AuthType)
+		}
+	case "https":
+		if source.AuthType == AuthTypeBasic && (source.Username == "" || source.Password == "") {
+			return errors.New("a user name and password are required for source authentication type Basic")
+		}
+	default:
+		return fmt.Errorf("unsupported source %v, the source is an s3:// or https:// URL", source.URL)
+	}
+	return nil
+}
+
+// New creates the package service of a validated source
+func New(source Source, region string, manifestCache packageservice.ManifestCache) *PackageService {
+	sourceURL, _ := url.Parse(source.URL)
+	sourceURL.Scheme = strings.ToLower(sourceURL.Scheme)
+	sourceURL.Path = strings.TrimSuffix(sourceURL.Path, "/")
+	if source.AuthType == AuthTypeSigV4 && source.SigningService == "" {
+		source.SigningService = DefaultSigningService
+	}
+	return &PackageService{
+		source:            source,
+		sourceURL:         sourceURL,
+		region:            region,
+		manifestCache:     manifestCache,
+		manifests:         map[string]*sourceManifest{},
+		downloadDirectory: appconfig.DownloadRoot,
+		cacheDirectory:    filepath.Join(appconfig.DownloadRoot, artifactCacheFolder),
+	}
+}
+
+func (ds *PackageService) PackageServiceName() string {
+	return packageservice.PackageServiceName_privatesource
+}
+
+func (ds *PackageService) GetPackageArnAndVersion(packageName string, packageVersion string) (name string, version string) {
+	version = packageVersion
+	if packageservice.IsLatest(packageVersion) {
+		version = packageservice.Latest
+	}
+	return packageName, version
+}
+
+// DownloadManifest resolves the version, or version constraint, to the highest matching version available for this platform/arch.
+// The manifest is cached, so that the package can still be managed when the source is unavailable,
+// and the package isn't the same as the cache when its file changed since the previous download.
+func (ds *PackageService) DownloadManifest(tracer trace.Tracer, packageName string, version string) (string, string, bool, error) {
+	isSameAsCache := true
+
+	manifestTrace := tracer.BeginSection(fmt.Sprintf("download manifest of %v from %v", packageName, ds.source.URL))
+	cached := ds.readCachedManifest(tracer, packageName)
+	manifest, err := ds.downloadManifest(tracer, packageName)
+	if err != nil {
+		if cached == nil {
+			manifestTrace.WithError(err).End()
+			return packageName, "", isSameAsCache, err
+		}
+		manifestTrace.AppendInfof("source is unavailable, using the cached manifest: %v", err)
+		manifest = cached
+	}
+	ds.manifests[packageName] = manifest
+	manifestTrace.End()
+
+	versionTrace := tracer.BeginSection(fmt.Sprintf("resolve version %v of %v", version, packageName))
+	targetVersion, err := resolveVersion(version, manifest.versions())
+	if err != nil {
+		err = fmt.Errorf("%v for package %v on platform %v/%v in source %v", err, packageName, appconfig.PackagePlatform, runtime.GOARCH, ds.source.URL)
+		versionTrace.WithError(err).End()
+		return packageName, "", isSameAsCache, err
+	}
+	versionTrace.AppendInfof("resolved version: %v", targetVersion).End()
+
+	if cached != nil {
+		previous, wasCached := cached.file(targetVersion)
+		current, _ := manifest.file(targetVersion)
+		isSameAsCache = !wasCached || previous == current
+	}
+	if manifest != cached {
+		ds.writeCachedManifest(tracer, packageName, manifest)
+	}
+
+	return packageName, targetVersion, isSameAsCache, nil
+}
+
+// DownloadArtifact downloads the package of the version for this platform/arch, packages with a sha256 are cached locally
+func (ds *PackageService) DownloadArtifact(tracer trace.Tracer, packageName string, version string) (string, error) {
+	logger := tracer.CurrentTrace().Logger
+
+	manifest, ok := ds.manifests[packageName]
+	if !ok {
+		if manifest = ds.readCachedManifest(tracer, packageName); manifest == nil {
+			var err error
+			if manifest, err = ds.downloadManifest(tracer, packageName); err != nil {
+				return "", err
+			}
+		}
+	}
+	file, ok := manifest.file(version)
+	if !ok {
+		return "", fmt.Errorf("version %v of package %v is not available for platform %v/%v in source %v", version, packageName, appconfig.PackagePlatform, runtime.GOARCH, ds.source.URL)
+	}
+
+	var cachePath string
+	if file.SHA256 != "" {
+		cachePath = filepath.Join(ds.cacheDirectory, strings.ToLower(file.SHA256))
+		if localPath, err := ds.copyFromCache(logger, cachePath, file.SHA256); err == nil {
+			tracer.CurrentTrace().AppendInfof("using cached package %v %v", packageName, version)
+			return localPath, nil
+		}
+	}
+
+	localPath, err := ds.download(tracer, packageName+"/"+file.Path, file.SHA256)
+	if err != nil {
+		return "", err
+	}
+
+	if cachePath != "" {
+		if err := copyFile(logger, localPath, cachePath); err != nil {
+			logger.Warnf("failed to cache package %v %v, %v", packageName, version, err)
+		}
+	}
+	return localPath, nil
+}
+
+func (*PackageService) ReportResult(tracer trace.Tracer, result packageservice.PackageResult) error {
+	// NOP
+	return nil
+}
+
+// utils
+
+// versions returns the versions of the package that are available for this platform/arch
+func (m *sourceManifest) versions() (versions []string) {
+	for version := range m.files() {
+		versions = append(versions, version)
+	}
+	return versions
+}
+
+// files returns the packages for this platform/arch by version
+func (m *sourceManifest) files() map[string]packageFile {
+	files := map[string]packageFile{}
+	for _, file := range m.Packages {
+		if (file.Platform == "" || strings.EqualFold(file.Platform, appconfig.PackagePlatform)) &&
+			(file.Arch == "" || strings.EqualFold(file.Arch, runtime.GOARCH)) {
+			if _, ok := files[file.Version]; !ok {
+				files[file.Version] = file
+			}
+		}
+	}
+	return files
+}
+
+// file returns the package of the version for this platform/arch
+func (m *sourceManifest) file(version string) (packageFile, bool) {
+	file, ok := m.files()[version]
+	return file, ok
+}
+
+// parseManifest parses and validates the manifest of a package
+func parseManifest(content []byte, packageName string) (*sourceManifest, error) {
+	var manifest sourceManifest
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest of package %v, %v", packageName, err)
+	}
+	if manifest.Name != "" && !strings.EqualFold(manifest.Name, packageName) {
+		return nil, fmt.Errorf("manifest name (%v) does not match expected package name (%v)", manifest.Name, packageName)
+	}
+	for _, file := range manifest.Packages {
+		if file.Version == "" || file.Path == "" {
+			return nil, fmt.Errorf("invalid manifest of package %v, a version and path are required for each package", packageName)
+		}
+		if cleaned := path.Clean(file.Path); path.IsAbs(file.Path) || cleaned == ".." || strings.HasPrefix(cleaned, "../") || strings.Contains(file.Path, "://") {
+			return nil, fmt.Errorf("invalid manifest of package %v, path %v is not relative to the folder of the manifest", packageName, file.Path)
+		}
+	}
+	return &manifest, nil
+}
+
+// downloadManifest downloads the manifest of the package from the source
+func (ds *PackageService) downloadManifest(tracer trace.Tracer, packageName string) (*sourceManifest, error) {
+	localPath, err := ds.download(tracer, packageName+"/"+ManifestFileName, "")
+	if err != nil {
+		return nil, err
+	}
+	defer fileutil.DeleteFile(localPath)
+
+	content, err := ioutil.ReadFile(localPath)
+	if err != nil {
+		return nil, err
+	}
+	return parseManifest(content, packageName)
+}
+
+// readCachedManifest returns the manifest of the package previously downloaded from the source, or nil
+func (ds *PackageService) readCachedManifest(tracer trace.Tracer, packageName string) *sourceManifest {
+	content, err := ds.manifestCache.ReadManifest(packageName, manifestCacheVersion)
+	if err != nil || len(content) == 0 {
+		return nil
+	}
+	manifest, err := parseManifest(content, packageName)
+	if err != nil {
+		tracer.CurrentTrace().AppendDebugf("ignoring cached manifest of %v, %v", packageName, err)
+		return nil
+	}
+	return manifest
+}
+
+// writeCachedManifest caches the manifest of the package, failing to cache isn't an error
+func (ds *PackageService) writeCachedManifest(tracer trace.Tracer, packageName string, manifest *sourceManifest) {
+	content, err := json.Marshal(manifest)
+	if err == nil {
+		err = ds.manifestCache.WriteManifest(packageName, manifestCacheVersion, content)
+	}
+	if err != nil {
+		tracer.CurrentTrace().AppendDebugf("failed to cache manifest of %v, %v", packageName, err)
+	}
+}
+
+// fileURL returns the URL of a file of the source, S3 sources are accessed through the regional S3 endpoint
+func (ds *PackageService) fileURL(relativePath string) string {
+	fileURL := *ds.sourceURL
+	fileURL.Path = fileURL.Path + "/" + relativePath
+	if fileURL.Scheme == "s3" {
+		return fmt.Sprintf("https://%v/%v%v", s3util.GetS3Endpoint(ds.region), fileURL.Host, fileURL.Path)
+	}
+	return fileURL.String()
+}
+
+// download downloads a file of the source to the download directory and verifies its sha256, if any
+func (ds *PackageService) download(tracer trace.Tracer, relativePath string, sha256 string) (localPath string, err error) {
+	logger := tracer.CurrentTrace().Logger
+	fileURL := ds.fileURL(relativePath)
+
+	if ds.sourceURL.Scheme == "s3" {
+		var output artifact.DownloadOutput
+		output, err = networkdep.Download(logger, artifact.DownloadInput{SourceURL: fileURL, DestinationDirectory: ds.downloadDirectory})
+		localPath = output.LocalFilePath
+	} else {
+		localPath = filepath.Join(ds.downloadDirectory, fmt.Sprintf("%x", sha1.Sum([]byte(fileURL))))
+		err = ds.httpDownload(logger, fileURL, localPath)
+	}
+	if err != nil || localPath == "" {
+		return "", fmt.Errorf("failed to download %v, %v", fileURL, err)
+	}
+
+	if sha256 != "" {
+		if err = verifySHA256(logger, localPath, sha256); err != nil {
+			fileutil.DeleteFile(localPath)
+			return "", fmt.Errorf("failed to verify %v, %v", fileURL, err)
+		}
+	}
+	return localPath, nil
+}
+
+// httpDownload downloads a file of an HTTPS source with the credentials of the source
+func (ds *PackageService) httpDownload(logger log.T, fileURL string, destination string) error {
+	request, err := http.NewRequest("GET", fileURL, nil)
+	if err != nil {
+		return err
+	}
+
+	switch ds.source.AuthType {
+	case AuthTypeBasic:
+		request.SetBasicAuth(ds.source.Username, ds.source.Password)
+	case AuthTypeSigV4:
+		signer := v4.NewSigner(networkdep.Credentials())
+		if _, err = signer.Sign(request, nil, ds.source.SigningService, ds.region, time.Now()); err != nil {
+			return fmt.Errorf("failed to sign the request, %v", err)
+		}
+	}
+
+	response, err := networkdep.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("http request failed. status:%v statuscode:%v", response.Status, response.StatusCode)
+	}
+
+	if err = fileutil.MakeDirs(filepath.Dir(destination)); err != nil {
+		return err
+	}
+	if _, err = artifact.FileCopy(logger, destination, response.Body); err != nil {
+		fileutil.DeleteFile(destination)
+		return err
+	}
+	return nil
+}
+
+// copyFromCache copies a cached package to the download directory, since the caller deletes the package once it is extracted
+func (ds *PackageService) copyFromCache(logger log.T, cachePath string, sha256 string) (string, error) {
+	if err := verifySHA256(logger, cachePath, sha256); err != nil {
+		return "", err
+	}
+	localPath := filepath.Join(ds.downloadDirectory, filepath.Base(cachePath)+".zip")
+	if err := copyFile(logger, cachePath, localPath); err != nil {
+		return "", err
+	}
+	return localPath, nil
+}
+
+// verifySHA256 returns an error if the file doesn't exist or its sha256 isn't the expected one
+func verifySHA256(logger log.T, filePath string, sha256 string) error {
+	if !fileutil.Exists(filePath) {
+		return fmt.Errorf("%v does not exist", filePath)
+	}
+	computed, err := artifact.Sha256HashValue(logger, filePath)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(computed, sha256) {
+		return fmt.Errorf("sha256 %v does not match the expected %v", computed, sha256)
+	}
+	return nil
+}
+
+// copyFile copies the file at source to destination, creating the folder of destination
+func copyFile(logger log.T, source string, destination string) error {
+	file, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err = fileutil.MakeDirs(filepath.Dir(destination)); err != nil {
+		return err
+	}
+	if _, err = artifact.FileCopy(logger, destination, file); err != nil {
+		fileutil.DeleteFile(destination)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,295 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package privatesource
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/fileutil/artifact"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/plugins/configurepackage/packageservice"
+	"github.com/aws/amazon-ssm-agent/agent/plugins/configurepackage/trace"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/stretchr/testify/assert"
+)
+
+// networkDepStub serves the files of a source from memory
+type networkDepStub struct {
+	files    map[string][]byte
+	err      error
+	requests []*http.Request
+	s3URLs   []string
+}
+
+func (n *networkDepStub) Download(log log.T, input artifact.DownloadInput) (output artifact.DownloadOutput, err error) {
+	n.s3URLs = append(n.s3URLs, input.SourceURL)
+	content, ok := n.files[input.SourceURL]
+	if n.err != nil || !ok {
+		return output, fmt.Errorf("failed to download %v", input.SourceURL)
+	}
+	output.LocalFilePath = filepath.Join(input.DestinationDirectory, fmt.Sprintf("s3download%v", len(n.s3URLs)))
+	return output, ioutil.WriteFile(output.LocalFilePath, content, 0600)
+}
+
+func (n *networkDepStub) Do(request *http.Request) (*http.Response, error) {
+	n.requests = append(n.requests, request)
+	if n.err != nil {
+		return nil, n.err
+	}
+	content, ok := n.files[request.URL.String()]
+	if !ok {
+		return &http.Response{Status: "404 Not Found", StatusCode: http.StatusNotFound, Body: ioutil.NopCloser(&bytes.Buffer{})}, nil
+	}
+	return &http.Response{Status: "200 OK", StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewReader(content))}, nil
+}
+
+func (n *networkDepStub) Credentials() *credentials.Credentials {
+	return credentials.NewStaticCredentials("AKID", "SECRET", "")
+}
+
+func stubNetworkDep(stub *networkDepStub) (restore func()) {
+	original := networkdep
+	networkdep = stub
+	return func() {
+		networkdep = original
+	}
+}
+
+func sha256Of(content []byte) string {
+	return fmt.Sprintf("%x", sha256.Sum256(content))
+}
+
+func manifestWith(files ...string) []byte {
+	manifest := `{"name": "MyPackage", "packages": [`
+	for i, file := range files {
+		if i > 0 {
+			manifest += ","
+		}
+		manifest += file
+	}
+	return []byte(manifest + "]}")
+}
+
+func packageEntry(version string, path string, content []byte) string {
+	return fmt.Sprintf(`{"version": "%v", "platform": "%v", "arch": "%v", "path": "%v", "sha256": "%v"}`,
+		version, appconfig.PackagePlatform, runtime.GOARCH, path, sha256Of(content))
+}
+
+func newTestService(t *testing.T, source Source) (service *PackageService, cleanup func()) {
+	assert.NoError(t, ValidateSource(&source))
+	dir, err := ioutil.TempDir("", "privatesource")
+	assert.NoError(t, err)
+
+	service = New(source, "us-east-1", packageservice.ManifestCacheMemNew())
+	service.downloadDirectory = filepath.Join(dir, "download")
+	service.cacheDirectory = filepath.Join(dir, "cache")
+	os.MkdirAll(service.downloadDirectory, 0700)
+	return service, func() { os.RemoveAll(dir) }
+}
+
+func TestValidateSource(t *testing.T) {
+	source := Source{URL: "https://packages.example.com/repo", AuthType: "sigv4"}
+	assert.NoError(t, ValidateSource(&source))
+	assert.Equal(t, AuthTypeSigV4, source.AuthType)
+
+	source = Source{URL: "s3://bucket/repo"}
+	assert.NoError(t, ValidateSource(&source))
+	assert.Equal(t, AuthTypeNone, source.AuthType)
+
+	for _, source := range []Source{
+		{URL: "http://packages.example.com/repo"},
+		{URL: "/var/packages"},
+		{URL: "s3://bucket/repo", AuthType: AuthTypeBasic, Username: "user", Password: "password"},
+		{URL: "https://packages.example.com/repo", AuthType: AuthTypeBasic, Username: "user"},
+		{URL: "https://packages.example.com/repo", AuthType: "NTLM"},
+	} {
+		assert.Error(t, ValidateSource(&source), "%v", source)
+	}
+}
+
+func TestDownloadFromS3Source(t *testing.T) {
+	content := []byte("package 1.2.7")
+	manifestURL := "https://s3.amazonaws.com/bucket/repo/MyPackage/manifest.json"
+	packageURL := "https://s3.amazonaws.com/bucket/repo/MyPackage/1.2.7/MyPackage.zip"
+	stub := &networkDepStub{files: map[string][]byte{
+		manifestURL: manifestWith(packageEntry("1.2.0", "1.2.0/MyPackage.zip", []byte("package 1.2.0")), packageEntry("1.2.7", "1.2.7/MyPackage.zip", content)),
+		packageURL:  content,
+	}}
+	defer stubNetworkDep(stub)()
+	service, cleanup := newTestService(t, Source{URL: "s3://bucket/repo/"})
+	defer cleanup()
+	tracer := trace.NewTracer(log.NewMockLog())
+	defer tracer.BeginSection("test").End()
+
+	name, version, isSameAsCache, err := service.DownloadManifest(tracer, "MyPackage", "~1.2")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "MyPackage", name)
+	assert.Equal(t, "1.2.7", version)
+	assert.True(t, isSameAsCache)
+
+	localPath, err := service.DownloadArtifact(tracer, "MyPackage", version)
+	assert.NoError(t, err)
+	downloaded, _ := ioutil.ReadFile(localPath)
+	assert.Equal(t, content, downloaded)
+	assert.Equal(t, []string{manifestURL, packageURL}, stub.s3URLs)
+}
+
+func TestDownloadFromHTTPSSourceWithAuthentication(t *testing.T) {
+	content := []byte("package 2.0.0")
+	stub := &networkDepStub{files: map[string][]byte{
+		"https://packages.example.com/repo/MyPackage/manifest.json": manifestWith(
+			packageEntry("2.0.0", "MyPackage-2.0.0.zip", content),
+			`{"version": "3.0.0", "platform": "other", "path": "MyPackage-3.0.0.zip"}`),
+		"https://packages.example.com/repo/MyPackage/MyPackage-2.0.0.zip": content,
+	}}
+	defer stubNetworkDep(stub)()
+	tracer := trace.NewTracer(log.NewMockLog())
+	defer tracer.BeginSection("test").End()
+
+	service, cleanup := newTestService(t, Source{URL: "https://packages.example.com/repo", AuthType: AuthTypeBasic, Username: "user", Password: "password"})
+	defer cleanup()
+	_, version, _, err := service.DownloadManifest(tracer, "MyPackage", "latest")
+	assert.NoError(t, err)
+	// versions for other platforms are ignored
+	assert.Equal(t, "2.0.0", version)
+	_, err = service.DownloadArtifact(tracer, "MyPackage", version)
+	assert.NoError(t, err)
+	assert.Len(t, stub.requests, 2)
+	for _, request := range stub.requests {
+		username, password, ok := request.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "user", username)
+		assert.Equal(t, "password", password)
+	}
+
+	stub.requests = nil
+	service, cleanup = newTestService(t, Source{URL: "https://packages.example.com/repo", AuthType: AuthTypeSigV4})
+	defer cleanup()
+	_, _, _, err = service.DownloadManifest(tracer, "MyPackage", "2.0.0")
+	assert.NoError(t, err)
+	assert.Len(t, stub.requests, 1)
+	assert.Contains(t, stub.requests[0].Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/")
+	assert.Contains(t, stub.requests[0].Header.Get("Authorization"), "/us-east-1/execute-api/aws4_request")
+}
+
+func TestDownloadManifestUsesCacheWhenSourceIsUnavailable(t *testing.T) {
+	content := []byte("package 1.0.0")
+	stub := &networkDepStub{files: map[string][]byte{
+		"https://packages.example.com/repo/MyPackage/manifest.json": manifestWith(packageEntry("1.0.0", "MyPackage.zip", content)),
+		"https://packages.example.com/repo/MyPackage/MyPackage.zip": content,
+	}}
+	defer stubNetworkDep(stub)()
+	service, cleanup := newTestService(t, Source{URL: "https://packages.example.com/repo"})
+	defer cleanup()
+	tracer := trace.NewTracer(log.NewMockLog())
+	defer tracer.BeginSection("test").End()
+
+	_, _, _, err := service.DownloadManifest(tracer, "MyPackage", "")
+	assert.NoError(t, err)
+	localPath, err := service.DownloadArtifact(tracer, "MyPackage", "1.0.0")
+	assert.NoError(t, err)
+	os.Remove(localPath)
+
+	stub.err = errors.New("connection refused")
+	service.manifests = map[string]*sourceManifest{}
+
+	_, version, isSameAsCache, err := service.DownloadManifest(tracer, "MyPackage", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "1.0.0", version)
+	assert.True(t, isSameAsCache)
+
+	// the package is served from the local cache
+	localPath, err = service.DownloadArtifact(tracer, "MyPackage", "1.0.0")
+	assert.NoError(t, err)
+	downloaded, _ := ioutil.ReadFile(localPath)
+	assert.Equal(t, content, downloaded)
+
+	_, _, _, err = service.DownloadManifest(tracer, "OtherPackage", "")
+	assert.Error(t, err)
+}
+
+func TestDownloadManifestDetectsChangedPackage(t *testing.T) {
+	stub := &networkDepStub{files: map[string][]byte{
+		"https://packages.example.com/repo/MyPackage/manifest.json": manifestWith(packageEntry("1.0.0", "MyPackage.zip", []byte("first build"))),
+	}}
+	defer stubNetworkDep(stub)()
+	service, cleanup := newTestService(t, Source{URL: "https://packages.example.com/repo"})
+	defer cleanup()
+	tracer := trace.NewTracer(log.NewMockLog())
+	defer tracer.BeginSection("test").End()
+
+	_, _, isSameAsCache, err := service.DownloadManifest(tracer, "MyPackage", "1.0.0")
+	assert.NoError(t, err)
+	assert.True(t, isSameAsCache)
+
+	stub.files["https://packages.example.com/repo/MyPackage/manifest.json"] = manifestWith(packageEntry("1.0.0", "MyPackage.zip", []byte("second build")))
+	_, _, isSameAsCache, err = service.DownloadManifest(tracer, "MyPackage", "1.0.0")
+	assert.NoError(t, err)
+	assert.False(t, isSameAsCache)
+
+	_, _, isSameAsCache, err = service.DownloadManifest(tracer, "MyPackage", "1.0.0")
+	assert.NoError(t, err)
+	assert.True(t, isSameAsCache)
+}
+
+func TestDownloadArtifactVerifiesSHA256(t *testing.T) {
+	stub := &networkDepStub{files: map[string][]byte{
+		"https://packages.example.com/repo/MyPackage/manifest.json": manifestWith(packageEntry("1.0.0", "MyPackage.zip", []byte("expected"))),
+		"https://packages.example.com/repo/MyPackage/MyPackage.zip": []byte("tampered"),
+	}}
+	defer stubNetworkDep(stub)()
+	service, cleanup := newTestService(t, Source{URL: "https://packages.example.com/repo"})
+	defer cleanup()
+	tracer := trace.NewTracer(log.NewMockLog())
+	defer tracer.BeginSection("test").End()
+
+	_, _, _, err := service.DownloadManifest(tracer, "MyPackage", "1.0.0")
+	assert.NoError(t, err)
+	_, err = service.DownloadArtifact(tracer, "MyPackage", "1.0.0")
+	assert.Error(t, err)
+	files, _ := ioutil.ReadDir(service.downloadDirectory)
+	assert.Empty(t, files)
+}
+
+func TestParseManifest(t *testing.T) {
+	_, err := parseManifest(manifestWith(`{"version": "1.0.0", "path": "MyPackage.zip"}`), "mypackage")
+	assert.NoError(t, err)
+
+	for _, content := range [][]byte{
+		[]byte("not json"),
+		manifestWith(`{"version": "1.0.0", "path": "MyPackage.zip"}`)[:10],
+		manifestWith(`{"version": "1.0.0"}`),
+		manifestWith(`{"version": "1.0.0", "path": "../OtherPackage/OtherPackage.zip"}`),
+		manifestWith(`{"version": "1.0.0", "path": "/MyPackage.zip"}`),
+		manifestWith(`{"version": "1.0.0", "path": "https://example.com/MyPackage.zip"}`),
+	} {
+		_, err := parseManifest(content, "MyPackage")
+		assert.Error(t, err, "%s", content)
+	}
+
+	_, err = parseManifest(manifestWith(), "OtherPackage")
+	assert.Error(t, err)
+}
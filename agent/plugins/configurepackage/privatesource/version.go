@@ -0,0 +1,238 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package privatesource
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/aws/amazon-ssm-agent/agent/plugins/configurepackage/packageservice"
+)
+
+const (
+	// PatternVersion represents the regular expression for versions that can be compared, such as 1.2 or 1.2.3
+	PatternVersion = `^\d+(\.\d+)*$`
+
+	// PatternWildcard represents the regular expression for versions with a wildcard, such as 1.2.* or 1.x
+	PatternWildcard = `^(\d+\.)+[*xX]$`
+)
+
+var (
+	versionPattern  = regexp.MustCompile(PatternVersion)
+	wildcardPattern = regexp.MustCompile(PatternWildcard)
+	clausePattern   = regexp.MustCompile(`^(>=|<=|!=|>|<|=|~|\^)?(.+)$`)
+	// exactPattern matches the versions that can't be compared but can be installed by name, such as 1.0.0-beta
+	exactPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._+-]*$`)
+	// operatorSpacing matches the space between an operator and its version, as in ">= 1.0"
+	operatorSpacing = regexp.MustCompile(`(>=|<=|!=|>|<|=|~|\^)\s+`)
+)
+
+// versionClause is a single condition of a version constraint
+type versionClause struct {
+	operator string
+	version  string
+	parts    []int
+}
+
+// versionConstraint is a list of conditions a version has to satisfy, such as ">=1.2, <2.0"
+type versionConstraint []versionClause
+
+// parseVersion returns the numeric parts of a version and an error if the version can't be compared
+func parseVersion(version string) (parts []int, err error) {
+	if !versionPattern.MatchString(version) {
+		return nil, fmt.Errorf("invalid version string %v", version)
+	}
+	for _, part := range strings.Split(version, ".") {
+		number, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version string %v", version)
+		}
+		parts = append(parts, number)
+	}
+	return parts, nil
+}
+
+// compareVersions returns -1, 0 or 1 if a is lower, equal to or greater than b, missing parts are 0 so that 1.2 equals 1.2.0
+func compareVersions(a []int, b []int) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var left, right int
+		if i < len(a) {
+			left = a[i]
+		}
+		if i < len(b) {
+			right = b[i]
+		}
+		if left < right {
+			return -1
+		} else if left > right {
+			return 1
+		}
+	}
+	return 0
+}
+
+// nextVersion returns the lowest version that is greater than all the versions starting with the first index+1 parts of version
+func nextVersion(version []int, index int) []int {
+	next := append([]int{}, version[:index+1]...)
+	next[index]++
+	return next
+}
+
+// ValidateVersionConstraint returns an error if the version is neither latest nor a valid constraint
+func ValidateVersionConstraint(version string) error {
+	if packageservice.IsLatest(version) {
+		return nil
+	}
+	_, err := parseVersionConstraint(version)
+	return err
+}
+
+// parseVersionConstraint parses a constraint such as "1.2.3", ">=1.2 <2", "~1.2.0", "^1.2" or "1.2.*",
+// the clauses of a constraint are separated by commas or spaces
+func parseVersionConstraint(constraint string) (result versionConstraint, err error) {
+	constraint = operatorSpacing.ReplaceAllString(strings.TrimSpace(constraint), "$1")
+	clauses := strings.FieldsFunc(constraint, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' })
+	if len(clauses) == 0 {
+		return nil, fmt.Errorf("empty version constraint")
+	}
+
+	for _, clause := range clauses {
+		match := clausePattern.FindStringSubmatch(clause)
+		if match == nil {
+			return nil, fmt.Errorf("invalid version constraint %v", constraint)
+		}
+		operator, version := match[1], match[2]
+
+		if wildcardPattern.MatchString(version) {
+			if operator != "" && operator != "=" {
+				return nil, fmt.Errorf("invalid version constraint %v, wildcards can't be used with %v", constraint, operator)
+			}
+			prefix, _ := parseVersion(strings.TrimSuffix(version[:len(version)-1], "."))
+			result = append(result,
+				versionClause{operator: ">=", version: version, parts: prefix},
+				versionClause{operator: "<", version: version, parts: nextVersion(prefix, len(prefix)-1)})
+			continue
+		}
+
+		parts, err := parseVersion(version)
+		if err != nil {
+			// versions that can't be compared, such as 1.0.0-beta, are only matched exactly
+			if operator != "" && operator != "=" || len(clauses) > 1 || !exactPattern.MatchString(version) {
+				return nil, fmt.Errorf("invalid version constraint %v, %v", constraint, err)
+			}
+			return versionConstraint{{operator: "=", version: version}}, nil
+		}
+
+		switch operator {
+		case "~":
+			// ~1.2.3 and ~1.2 allow patch updates, ~1 minor updates
+			index := 1
+			if len(parts) == 1 {
+				index = 0
+			}
+			result = append(result,
+				versionClause{operator: ">=", version: version, parts: parts},
+				versionClause{operator: "<", version: version, parts: nextVersion(parts, index)})
+		case "^":
+			// ^1.2.3 allows updates that don't change the first non zero part of the version
+			index := 0
+			for index < len(parts)-1 && parts[index] == 0 {
+				index++
+			}
+			result = append(result,
+				versionClause{operator: ">=", version: version, parts: parts},
+				versionClause{operator: "<", version: version, parts: nextVersion(parts, index)})
+		case "":
+			result = append(result, versionClause{operator: "=", version: version, parts: parts})
+		default:
+			result = append(result, versionClause{operator: operator, version: version, parts: parts})
+		}
+	}
+	return result, nil
+}
+
+// matches returns true if the version satisfies all the clauses of the constraint
+func (c versionConstraint) matches(version string) bool {
+	parts, err := parseVersion(version)
+	for _, clause := range c {
+		if clause.parts == nil || err != nil {
+			if clause.operator != "=" || clause.version != version {
+				return false
+			}
+			continue
+		}
+
+		comparison := compareVersions(parts, clause.parts)
+		var ok bool
+		switch clause.operator {
+		case "=":
+			ok = comparison == 0
+		case "!=":
+			ok = comparison != 0
+		case ">":
+			ok = comparison > 0
+		case ">=":
+			ok = comparison >= 0
+		case "<":
+			ok = comparison < 0
+		case "<=":
+			ok = comparison <= 0
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// resolveVersion returns the highest of the versions that satisfies the constraint, latest is the highest version
+func resolveVersion(constraint string, versions []string) (string, error) {
+	var matching versionConstraint
+	if !packageservice.IsLatest(constraint) {
+		var err error
+		if matching, err = parseVersionConstraint(constraint); err != nil {
+			return "", err
+		}
+	}
+
+	var resolved string
+	var resolvedParts []int
+	for _, version := range versions {
+		if !matching.matches(version) {
+			continue
+		}
+		parts, err := parseVersion(version)
+		if err != nil {
+			// versions that can't be compared are only resolved by an exact constraint
+			if matching != nil && resolved == "" {
+				resolved = version
+			}
+			continue
+		}
+		if resolvedParts == nil || compareVersions(parts, resolvedParts) > 0 {
+			resolved, resolvedParts = version, parts
+		}
+	}
+
+	if resolved == "" {
+		if packageservice.IsLatest(constraint) {
+			return "", fmt.Errorf("no version is available")
+		}
+		return "", fmt.Errorf("no version matches %v", constraint)
+	}
+	return resolved, nil
+}
@@ -0,0 +1,77 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package privatesource
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveVersion(t *testing.T) {
+	versions := []string{"0.9.1", "1.0.0", "1.2.0", "1.2.7", "1.10.0", "2.0.0", "2.1", "3.0.0-beta"}
+
+	for constraint, expected := range map[string]string{
+		"":              "2.1",
+		"latest":        "2.1",
+		"1.2.0":         "1.2.0",
+		"1.2":           "1.2.0",
+		"=1.0":          "1.0.0",
+		"3.0.0-beta":    "3.0.0-beta",
+		">=1.0, <2.0":   "1.10.0",
+		">= 1.0 < 1.10": "1.2.7",
+		">1.2.7 <=2":    "2.0.0",
+		"<1":            "0.9.1",
+		"!=2.1":         "2.0.0",
+		"~1.2.0":        "1.2.7",
+		"~1.2":          "1.2.7",
+		"~1":            "1.10.0",
+		"^1.2":          "1.10.0",
+		"^0.9":          "0.9.1",
+		"1.2.*":         "1.2.7",
+		"1.x":           "1.10.0",
+	} {
+		resolved, err := resolveVersion(constraint, versions)
+		assert.NoError(t, err, "constraint %v", constraint)
+		assert.Equal(t, expected, resolved, "constraint %v", constraint)
+	}
+}
+
+func TestResolveVersionNoMatch(t *testing.T) {
+	versions := []string{"1.0.0", "1.2.0"}
+
+	for _, constraint := range []string{"1.1.0", ">2", "~0.9", "3.x", "1.0.0-beta"} {
+		_, err := resolveVersion(constraint, versions)
+		assert.Error(t, err, "constraint %v", constraint)
+	}
+
+	_, err := resolveVersion("latest", []string{"1.0.0-beta"})
+	assert.Error(t, err)
+}
+
+func TestValidateVersionConstraint(t *testing.T) {
+	for _, constraint := range []string{"", "latest", "1.2.3", "1.0.0-beta", ">=1.0, <2.0", "~1.2", "^0.2.3", "1.2.*"} {
+		assert.NoError(t, ValidateVersionConstraint(constraint), "constraint %v", constraint)
+	}
+	for _, constraint := range []string{">1.0.0-beta", "1.0 beta", ">=1.*", "~", ","} {
+		assert.Error(t, ValidateVersionConstraint(constraint), "constraint %v", constraint)
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	assert.Equal(t, 0, compareVersions([]int{1, 2}, []int{1, 2, 0}))
+	assert.Equal(t, -1, compareVersions([]int{1, 2}, []int{1, 10}))
+	assert.Equal(t, 1, compareVersions([]int{2}, []int{1, 99, 99}))
+}
@@ -0,0 +1,325 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package copyfile implements the aws:copyFile plugin.
+package copyfile
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/fileutil"
+	"github.com/aws/amazon-ssm-agent/agent/fileutil/artifact"
+	"github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler"
+	"github.com/aws/amazon-ssm-agent/agent/jsonutil"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+)
+
+const (
+	payloadFileName = "payload" // Name of the file the inline content is staged to under the plugin orchestration directory
+	backupSuffix    = ".bak"    // Suffix of the copy kept of the replaced destination file
+
+	contentEncodingBase64 = "base64"
+
+	// defaultFileMode is the mode of created files when no Mode is given
+	defaultFileMode os.FileMode = 0644
+
+	// resultChanged and resultUnchanged tell whether the plugin modified the destination
+	resultChanged   = "Changed"
+	resultUnchanged = "Unchanged"
+)
+
+// Assign method to global variables to allow unittest to override
+var download = artifact.Download
+
+// Plugin is the type for the aws:copyFile plugin.
+type Plugin struct{}
+
+// CopyFilePluginInput represents one file written by the aws:copyFile plugin.
+type CopyFilePluginInput struct {
+	contracts.PluginInput
+	ID              string
+	Content         string
+	ContentEncoding string
+	SourceUrl       string
+	SourceHash      string
+	DestinationPath string
+	Owner           string
+	Group           string
+	Mode            string
+	Backup          bool
+}
+
+// NewPlugin returns a new instance of the plugin.
+func NewPlugin() (*Plugin, error) {
+	return &Plugin{}, nil
+}
+
+// Name returns the plugin name
+func Name() string {
+	return appconfig.PluginNameAwsCopyFile
+}
+
+// Execute writes the payload to the destination path unless it already has the requested content and attributes.
+func (p *Plugin) Execute(context context.T, config contracts.Configuration, cancelFlag task.CancelFlag, output iohandler.IOHandler) {
+	log := context.Log()
+	log.Infof("%v started with configuration %v", Name(), config)
+
+	if cancelFlag.ShutDown() {
+		output.MarkAsShutdown()
+	} else if cancelFlag.Canceled() {
+		output.MarkAsCancelled()
+	} else if input, err := parseAndValidateInput(config.Properties); err != nil {
+		output.MarkAsFailed(err)
+	} else {
+		copyFile(log, input, config.OrchestrationDirectory, output)
+	}
+}
+
+// copyFile stages the payload, replaces the destination if its content differs and applies the file attributes.
+func copyFile(log log.T, input *CopyFilePluginInput, orchestrationDirectory string, output iohandler.IOHandler) {
+	workingDir := fileutil.BuildPath(orchestrationDirectory, input.ID)
+	if err := fileutil.MakeDirs(workingDir); err != nil {
+		output.MarkAsFailed(fmt.Errorf("failed to create orchestrationDir directory, %v", workingDir))
+		return
+	}
+
+	payload, err := stagePayload(log, input, workingDir)
+	if err != nil {
+		output.MarkAsFailed(err)
+		return
+	}
+
+	contentChanged, err := replaceContent(log, payload, input.DestinationPath, input.Backup)
+	if err != nil {
+		output.MarkAsFailed(err)
+		return
+	}
+	if contentChanged {
+		output.AppendInfof("Content of %v updated", input.DestinationPath)
+	}
+
+	modeChanged, err := applyMode(input.DestinationPath, input.Mode, contentChanged)
+	if err != nil {
+		output.MarkAsFailed(fmt.Errorf("failed to set mode of %v: %v", input.DestinationPath, err))
+		return
+	}
+	if modeChanged {
+		output.AppendInfof("Mode of %v set to %v", input.DestinationPath, input.Mode)
+	}
+
+	ownerChanged, err := applyOwnership(input.DestinationPath, input.Owner, input.Group)
+	if err != nil {
+		output.MarkAsFailed(fmt.Errorf("failed to set ownership of %v: %v", input.DestinationPath, err))
+		return
+	}
+	if ownerChanged {
+		output.AppendInfof("Ownership of %v set to %v:%v", input.DestinationPath, input.Owner, input.Group)
+	}
+
+	result := resultUnchanged
+	if contentChanged || modeChanged || ownerChanged {
+		result = resultChanged
+	}
+	output.AppendInfof("%v: %v", result, input.DestinationPath)
+	output.MarkAsSucceeded()
+}
+
+// stagePayload returns the local path of the payload, downloading it or decoding the inline content as needed.
+func stagePayload(log log.T, input *CopyFilePluginInput, workingDir string) (string, error) {
+	if input.SourceUrl != "" {
+		downloadInput := artifact.DownloadInput{
+			SourceURL:            input.SourceUrl,
+			DestinationDirectory: workingDir,
+		}
+		if input.SourceHash != "" {
+			downloadInput.SourceChecksums = map[string]string{"sha256": input.SourceHash}
+		}
+		downloadOutput, err := download(log, downloadInput)
+		if err != nil {
+			return "", fmt.Errorf("failed to download %v: %v", input.SourceUrl, err)
+		}
+		if !downloadOutput.IsHashMatched {
+			return "", fmt.Errorf("checksum of %v doesn't match SourceHash", input.SourceUrl)
+		}
+		return downloadOutput.LocalFilePath, nil
+	}
+
+	content := []byte(input.Content)
+	if input.ContentEncoding == contentEncodingBase64 {
+		var err error
+		if content, err = base64.StdEncoding.DecodeString(input.Content); err != nil {
+			return "", fmt.Errorf("failed to decode base64 Content: %v", err)
+		}
+	}
+	payload := filepath.Join(workingDir, payloadFileName)
+	if err := writeFile(payload, content, defaultFileMode); err != nil {
+		return "", fmt.Errorf("failed to stage Content: %v", err)
+	}
+	return payload, nil
+}
+
+// replaceContent writes the payload to the destination unless both have the same checksum.
+// The payload is written next to the destination first and renamed so the destination is never partially written.
+func replaceContent(log log.T, payload string, destination string, backup bool) (bool, error) {
+	payloadHash, err := artifact.Sha256HashValue(log, payload)
+	if err != nil {
+		return false, fmt.Errorf("failed to compute checksum of the payload: %v", err)
+	}
+
+	mode := defaultFileMode
+	if info, err := os.Stat(destination); err == nil {
+		if info.IsDir() {
+			return false, fmt.Errorf("DestinationPath %v is a directory", destination)
+		}
+		destinationHash, err := artifact.Sha256HashValue(log, destination)
+		if err != nil {
+			return false, fmt.Errorf("failed to compute checksum of %v: %v", destination, err)
+		}
+		if strings.EqualFold(payloadHash, destinationHash) {
+			log.Debugf("%v already has checksum %v", destination, payloadHash)
+			return false, nil
+		}
+		if backup {
+			if err := copyContent(destination, destination+backupSuffix, info.Mode()); err != nil {
+				return false, fmt.Errorf("failed to back up %v: %v", destination, err)
+			}
+		}
+		mode = info.Mode()
+	} else if !os.IsNotExist(err) {
+		return false, err
+	}
+
+	if err := fileutil.MakeDirs(filepath.Dir(destination)); err != nil {
+		return false, fmt.Errorf("failed to create directory of %v: %v", destination, err)
+	}
+	tempFile := destination + ".tmp"
+	if err := copyContent(payload, tempFile, mode); err != nil {
+		os.Remove(tempFile)
+		return false, fmt.Errorf("failed to write %v: %v", destination, err)
+	}
+	if err := os.Rename(tempFile, destination); err != nil {
+		os.Remove(tempFile)
+		return false, fmt.Errorf("failed to write %v: %v", destination, err)
+	}
+	return true, nil
+}
+
+// applyMode sets the requested mode on the destination, reporting whether it differed.
+// A freshly written file always gets the mode applied since the umask may have narrowed it.
+func applyMode(destination string, mode string, written bool) (bool, error) {
+	if mode == "" {
+		return false, nil
+	}
+	perm, _ := parseMode(mode)
+	info, err := os.Stat(destination)
+	if err != nil {
+		return false, err
+	}
+	if info.Mode().Perm() == perm && !written {
+		return false, nil
+	}
+	if err := os.Chmod(destination, perm); err != nil {
+		return false, err
+	}
+	return info.Mode().Perm() != perm, nil
+}
+
+// copyContent copies the content of src to a new or truncated dest.
+func copyContent(src string, dest string, mode os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode.Perm())
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// writeFile writes content to a new or truncated file.
+func writeFile(path string, content []byte, mode os.FileMode) error {
+	out, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	if _, err = out.Write(content); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// parseMode parses an octal file mode such as 0644.
+func parseMode(mode string) (os.FileMode, error) {
+	value, err := strconv.ParseUint(mode, 8, 32)
+	if err != nil || value > 0777 {
+		return 0, fmt.Errorf("Mode must be an octal permission between 0000 and 0777, got %q", mode)
+	}
+	return os.FileMode(value), nil
+}
+
+// parseAndValidateInput parses the plugin properties and validates them
+func parseAndValidateInput(rawPluginInput interface{}) (*CopyFilePluginInput, error) {
+	var input CopyFilePluginInput
+	if err := jsonutil.Remarshal(rawPluginInput, &input); err != nil {
+		return nil, fmt.Errorf("invalid format in plugin properties %v; \nerror %v", rawPluginInput, err)
+	}
+
+	if err := validateInput(&input); err != nil {
+		return nil, fmt.Errorf("invalid input: %v", err)
+	}
+	return &input, nil
+}
+
+// validateInput ensures the plugin input matches the defined schema
+func validateInput(input *CopyFilePluginInput) error {
+	if input.DestinationPath == "" {
+		return errors.New("DestinationPath must be specified")
+	}
+	if !filepath.IsAbs(input.DestinationPath) {
+		return fmt.Errorf("DestinationPath %v must be an absolute path", input.DestinationPath)
+	}
+	if input.SourceUrl != "" && input.Content != "" {
+		return errors.New("only one of Content and SourceUrl can be specified")
+	}
+	if input.SourceUrl == "" && input.SourceHash != "" {
+		return errors.New("SourceHash requires SourceUrl")
+	}
+	if input.ContentEncoding != "" && input.ContentEncoding != contentEncodingBase64 {
+		return fmt.Errorf("unsupported ContentEncoding %q", input.ContentEncoding)
+	}
+	if input.Mode != "" {
+		if _, err := parseMode(input.Mode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
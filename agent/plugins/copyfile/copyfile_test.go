@@ -0,0 +1,132 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package copyfile implements the aws:copyFile plugin.
+package copyfile
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/fileutil/artifact"
+	"github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler"
+	multiwritermock "github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler/multiwriter/mock"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newOutput() (*iohandler.DefaultIOHandler, *multiwritermock.MockDocumentIOMultiWriter) {
+	stdoutWriter := new(multiwritermock.MockDocumentIOMultiWriter)
+	stdoutWriter.On("WriteString", mock.Anything).Return(0, nil)
+	stderrWriter := new(multiwritermock.MockDocumentIOMultiWriter)
+	stderrWriter.On("WriteString", mock.Anything).Return(0, nil)
+	output := &iohandler.DefaultIOHandler{}
+	output.StdoutWriter = stdoutWriter
+	output.StderrWriter = stderrWriter
+	return output, stdoutWriter
+}
+
+func TestValidateInput(t *testing.T) {
+	valid := CopyFilePluginInput{Content: "hello", DestinationPath: filepath.Join(os.TempDir(), "hello.txt"), Mode: "0640"}
+	assert.NoError(t, validateInput(&valid))
+
+	relative := valid
+	relative.DestinationPath = "hello.txt"
+	assert.Error(t, validateInput(&relative))
+
+	bothSources := valid
+	bothSources.SourceUrl = "https://example.com/hello.txt"
+	assert.Error(t, validateInput(&bothSources))
+
+	hashWithoutSource := valid
+	hashWithoutSource.SourceHash = "abc"
+	assert.Error(t, validateInput(&hashWithoutSource))
+
+	invalidEncoding := valid
+	invalidEncoding.ContentEncoding = "hex"
+	assert.Error(t, validateInput(&invalidEncoding))
+
+	invalidMode := valid
+	invalidMode.Mode = "0988"
+	assert.Error(t, validateInput(&invalidMode))
+}
+
+func TestCopyFileInlineContent(t *testing.T) {
+	dir, err := ioutil.TempDir("", "copyfile")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	destination := filepath.Join(dir, "etc", "app.conf")
+	input := &CopyFilePluginInput{ID: "0.aws:copyFile", Content: "aGVsbG8=", ContentEncoding: "base64", DestinationPath: destination, Mode: "0600", Backup: true}
+
+	output, stdoutWriter := newOutput()
+	copyFile(log.NewMockLog(), input, filepath.Join(dir, "orchestration"), output)
+	assert.Equal(t, contracts.ResultStatusSuccess, output.GetStatus())
+	stdoutWriter.AssertCalled(t, "WriteString", "Changed: "+destination)
+	content, _ := ioutil.ReadFile(destination)
+	assert.Equal(t, "hello", string(content))
+	info, _ := os.Stat(destination)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	output, stdoutWriter = newOutput()
+	copyFile(log.NewMockLog(), input, filepath.Join(dir, "orchestration"), output)
+	assert.Equal(t, contracts.ResultStatusSuccess, output.GetStatus())
+	stdoutWriter.AssertCalled(t, "WriteString", "Unchanged: "+destination)
+	_, err = os.Stat(destination + backupSuffix)
+	assert.True(t, os.IsNotExist(err))
+
+	input.Content = "aGVsbG8gd29ybGQ="
+	output, stdoutWriter = newOutput()
+	copyFile(log.NewMockLog(), input, filepath.Join(dir, "orchestration"), output)
+	assert.Equal(t, contracts.ResultStatusSuccess, output.GetStatus())
+	stdoutWriter.AssertCalled(t, "WriteString", "Changed: "+destination)
+	content, _ = ioutil.ReadFile(destination)
+	assert.Equal(t, "hello world", string(content))
+	backup, _ := ioutil.ReadFile(destination + backupSuffix)
+	assert.Equal(t, "hello", string(backup))
+}
+
+func TestCopyFileSourceUrl(t *testing.T) {
+	dir, err := ioutil.TempDir("", "copyfile")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	source := filepath.Join(dir, "downloaded")
+	assert.NoError(t, ioutil.WriteFile(source, []byte("from s3"), 0644))
+
+	defer func() { download = artifact.Download }()
+	var downloadInput artifact.DownloadInput
+	download = func(log log.T, input artifact.DownloadInput) (artifact.DownloadOutput, error) {
+		downloadInput = input
+		return artifact.DownloadOutput{LocalFilePath: source, IsHashMatched: input.SourceChecksums["sha256"] == "abc"}, nil
+	}
+
+	destination := filepath.Join(dir, "app.conf")
+	input := &CopyFilePluginInput{ID: "0.aws:copyFile", SourceUrl: "https://s3.amazonaws.com/bucket/app.conf", SourceHash: "abc", DestinationPath: destination}
+
+	output, _ := newOutput()
+	copyFile(log.NewMockLog(), input, dir, output)
+	assert.Equal(t, contracts.ResultStatusSuccess, output.GetStatus())
+	assert.Equal(t, "https://s3.amazonaws.com/bucket/app.conf", downloadInput.SourceURL)
+	content, _ := ioutil.ReadFile(destination)
+	assert.Equal(t, "from s3", string(content))
+
+	input.SourceHash = "def"
+	output, _ = newOutput()
+	copyFile(log.NewMockLog(), input, dir, output)
+	assert.Equal(t, contracts.ResultStatusFailed, output.GetStatus())
+}
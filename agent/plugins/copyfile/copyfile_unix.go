@@ -0,0 +1,65 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// +build darwin freebsd linux netbsd openbsd
+
+package copyfile
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"strconv"
+	"syscall"
+)
+
+// applyOwnership sets the owner and group of the destination, reporting whether they differed.
+func applyOwnership(destination string, owner string, group string) (bool, error) {
+	if owner == "" && group == "" {
+		return false, nil
+	}
+
+	info, err := os.Stat(destination)
+	if err != nil {
+		return false, err
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return false, fmt.Errorf("failed to read ownership of %v", destination)
+	}
+	uid, gid := int(stat.Uid), int(stat.Gid)
+
+	if owner != "" {
+		u, err := user.Lookup(owner)
+		if err != nil {
+			return false, err
+		}
+		if uid, err = strconv.Atoi(u.Uid); err != nil {
+			return false, err
+		}
+	}
+	if group != "" {
+		g, err := user.LookupGroup(group)
+		if err != nil {
+			return false, err
+		}
+		if gid, err = strconv.Atoi(g.Gid); err != nil {
+			return false, err
+		}
+	}
+
+	if uid == int(stat.Uid) && gid == int(stat.Gid) {
+		return false, nil
+	}
+	return true, os.Chown(destination, uid, gid)
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/aws/amazon-ssm-agent/agent/log"
 	"github.com/aws/amazon-ssm-agent/agent/plugins/downloadcontent/gitresource"
 	"github.com/aws/amazon-ssm-agent/agent/plugins/downloadcontent/gitresource/privategithub"
+	"github.com/aws/amazon-ssm-agent/agent/plugins/downloadcontent/gitsshresource"
 	"github.com/aws/amazon-ssm-agent/agent/plugins/downloadcontent/remoteresource"
 	"github.com/aws/amazon-ssm-agent/agent/plugins/downloadcontent/s3resource"
 	"github.com/aws/amazon-ssm-agent/agent/plugins/downloadcontent/ssmdocresource"
@@ -41,6 +42,7 @@ import (
 
 const (
 	GitHub      = "GitHub"      //Github represents the source type "GitHub" from where the resource can be downloaded
+	Git         = "Git"         //Git represents the source type "Git", a repository cloned over ssh with a deploy key
 	S3          = "S3"          //S3 represents the source type "S3" from where the resource is being downloaded
 	SSMDocument = "SSMDocument" //SSMDocument represents the source type as SSM Document
 
@@ -71,6 +73,10 @@ type DownloadContentPlugin struct {
 	SourceType      string `json:"sourceType"`
 	SourceInfo      string `json:"sourceInfo"`
 	DestinationPath string `json:"destinationPath"`
+	// Checksum, Signature and SignaturePublicKey optionally validate the downloaded file before it is handed to later steps
+	Checksum           string `json:"checksum"`
+	Signature          string `json:"signature"`
+	SignaturePublicKey string `json:"signaturePublicKey"`
 	// TODO: 08/25/2017 meloniam@ Change the type of SourceInfo and documentParameters to map[string]interface{}
 	// TODO: https://amazon.awsapps.com/workdocs/index.html#/document/7d56a42ea5b040a7c33548d77dc98040f0fb380bbbfb2fd580c861225e2ee1c7
 }
@@ -83,6 +89,8 @@ func newRemoteResource(log log.T, SourceType string, SourceInfo string) (resourc
 		// TODO: https://amazon.awsapps.com/workdocs/index.html#/document/7d56a42ea5b040a7c33548d77dc98040f0fb380bbbfb2fd580c861225e2ee1c7
 		token := privategithub.NewTokenInfoImpl()
 		return gitresource.NewGitResource(log, SourceInfo, token)
+	case Git:
+		return gitsshresource.NewGitSSHResource(SourceInfo)
 	case S3:
 		return s3resource.NewS3Resource(log, SourceInfo)
 	case SSMDocument:
@@ -154,6 +162,15 @@ func (p *Plugin) runCopyContent(log log.T, input *DownloadContentPlugin, config
 		return
 	}
 
+	if err := verifyContent(log, input, result.Files); err != nil {
+		// Remove the content so that later steps can't execute an artifact that failed validation
+		for _, path := range result.Files {
+			p.filesys.DeleteFile(path)
+		}
+		output.MarkAsFailed(err)
+		return
+	}
+
 	if err := setPermissions(log, result); err != nil {
 		output.MarkAsFailed(fmt.Errorf("Failed to set right permissions to the content. Error - %v", err))
 		return
@@ -205,13 +222,27 @@ func validateInput(input *DownloadContentPlugin) (valid bool, err error) {
 		return false, errors.New("SourceType must be specified")
 	}
 	//ensure all entries are valid
-	if input.SourceType != GitHub && input.SourceType != S3 && input.SourceType != SSMDocument {
+	if input.SourceType != GitHub && input.SourceType != Git && input.SourceType != S3 && input.SourceType != SSMDocument {
 		return false, errors.New("Unsupported source type")
 	}
 	// ensure non-empty source info
 	if input.SourceInfo == "" {
 		return false, errors.New("SourceInfo must be specified")
 	}
+	if input.Checksum != "" {
+		if _, _, err := parseChecksum(input.Checksum); err != nil {
+			return false, err
+		}
+	}
+	// a signature is only meaningful with the key to verify it
+	if (input.Signature == "") != (input.SignaturePublicKey == "") {
+		return false, errors.New("Signature and SignaturePublicKey must be specified together")
+	}
+	if input.SignaturePublicKey != "" {
+		if _, err := parsePublicKey(input.SignaturePublicKey); err != nil {
+			return false, err
+		}
+	}
 	return true, nil
 }
 
@@ -0,0 +1,76 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package gitsshresource
+
+import (
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/parameterstore"
+	"github.com/aws/amazon-ssm-agent/agent/ssmparameterresolver"
+
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// dependency on parameter store to get the deploy key and on git to clone the repository
+type gitsshdeps interface {
+	GetSecureParameter(log log.T, parameterReference string) (value string, err error)
+	RunGit(log log.T, workingDir string, args []string) error
+}
+
+type gitSSHDepImpl struct {
+	paramService ssmparameterresolver.SsmParameterService
+}
+
+// GetSecureParameter resolves a {{ ssm-secure:parameter-name }} reference.
+// NOTE: Do not log the parameter value
+func (g *gitSSHDepImpl) GetSecureParameter(log log.T, parameterReference string) (value string, err error) {
+	reference := ssmSecureParameterPattern.FindStringSubmatch(parameterReference)
+	if len(reference) < 2 {
+		return "", fmt.Errorf("Invalid ssm-secure parameter reference %v", parameterReference)
+	}
+
+	resolverOptions := ssmparameterresolver.ResolveOptions{
+		IgnoreSecureParameters: false,
+	}
+	var parameters map[string]ssmparameterresolver.SsmParameterInfo
+	if parameters, err = ssmparameterresolver.ResolveParameterReferenceList(&g.paramService, log, []string{reference[1]}, resolverOptions); err != nil {
+		return "", fmt.Errorf("Could not resolve ssm parameter - %v. Error - %v", reference[1], err)
+	}
+	if len(parameters) != 1 {
+		return "", fmt.Errorf("Invalid number of parameters returned - %v", len(parameters))
+	}
+
+	for _, parameter := range parameters {
+		if parameter.Type != parameterstore.ParamTypeSecureString {
+			return "", fmt.Errorf("PrivateSSHKey %v must be of secure string type, Current type - %v", parameter.Name, parameter.Type)
+		}
+		value = parameter.Value
+	}
+	return value, nil
+}
+
+// RunGit runs git with the given arguments, the output is only used for error reporting.
+// The leading -c core.sshCommand arguments are left out of the logs.
+func (g *gitSSHDepImpl) RunGit(log log.T, workingDir string, args []string) error {
+	command := exec.Command("git", args...)
+	command.Dir = workingDir
+	gitCommand := strings.Join(args[2:], " ")
+	log.Debugf("Running git %v", gitCommand)
+	if output, err := command.CombinedOutput(); err != nil {
+		return fmt.Errorf("git %v failed - %v. %v", gitCommand, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
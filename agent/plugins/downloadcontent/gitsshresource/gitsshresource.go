@@ -0,0 +1,248 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package gitsshresource implements the methods to clone git repositories over ssh
+package gitsshresource
+
+import (
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/fileutil/filemanager"
+	"github.com/aws/amazon-ssm-agent/agent/jsonutil"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/plugins/downloadcontent/remoteresource"
+	"github.com/aws/amazon-ssm-agent/agent/ssmparameterresolver"
+
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	gitDir = ".git" // Directory of the cloned repository metadata, its files are not part of the downloaded content
+)
+
+// ssmSecureParameterPattern matches the {{ ssm-secure:parameter-name }} reference of the deploy key
+var ssmSecureParameterPattern = regexp.MustCompile(`^\{\{\s*(ssm-secure:[\w-/]+)\s*\}\}$`)
+
+// commitIDPattern matches abbreviated and full git commit ids
+var commitIDPattern = regexp.MustCompile(`^[0-9a-fA-F]{7,40}$`)
+
+// GitSSHResource is a struct for the remote resource of type git over ssh
+type GitSSHResource struct {
+	Info      GitSSHInfo
+	gitsshdep gitsshdeps
+}
+
+// GitSSHInfo represents the sourceInfo type sent by runcommand
+type GitSSHInfo struct {
+	Repository    string `json:"repository"`
+	PrivateSSHKey string `json:"privateSSHKey"`
+	Ref           string `json:"ref"`
+	CommitID      string `json:"commitID"`
+	Depth         string `json:"depth"`
+	KnownHosts    string `json:"knownHosts"`
+}
+
+// NewGitSSHResource is a constructor of type GitSSHResource
+func NewGitSSHResource(info string) (*GitSSHResource, error) {
+	gitSSHInfo, err := parseSourceInfo(info)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GitSSHResource{
+		Info: gitSSHInfo,
+		gitsshdep: &gitSSHDepImpl{
+			paramService: ssmparameterresolver.NewService(),
+		},
+	}, nil
+}
+
+// parseSourceInfo unmarshals the information in sourceInfo of type GitSSHInfo and returns it
+func parseSourceInfo(sourceInfo string) (gitSSHInfo GitSSHInfo, err error) {
+
+	if err = jsonutil.Unmarshal(sourceInfo, &gitSSHInfo); err != nil {
+		return gitSSHInfo, fmt.Errorf("Source Info could not be unmarshalled for source type Git. Please check JSON format of sourceInfo - %v", err.Error())
+	}
+
+	return gitSSHInfo, nil
+}
+
+// DownloadRemoteResource clones the repository with the deploy key and checks out the requested ref or commit
+func (git *GitSSHResource) DownloadRemoteResource(log log.T, filesys filemanager.FileSystem, destinationPath string) (err error, result *remoteresource.DownloadResult) {
+	if destinationPath == "" {
+		destinationPath = appconfig.DownloadRoot
+	}
+
+	// The repository is cloned into a folder named after it when the destination is an existing directory
+	if (filesys.Exists(destinationPath) && filesys.IsDirectory(destinationPath)) || os.IsPathSeparator(destinationPath[len(destinationPath)-1]) {
+		destinationPath = filepath.Join(destinationPath, repositoryName(git.Info.Repository))
+	}
+	if filesys.Exists(destinationPath) {
+		return fmt.Errorf("Destination %v already exists, the repository must be cloned to a new directory", destinationPath), nil
+	}
+	if err = filesys.MakeDirs(filepath.Dir(destinationPath)); err != nil {
+		return err, nil
+	}
+
+	log.Debug("Resolving the deploy key of the repository")
+	var privateKey string
+	if privateKey, err = git.gitsshdep.GetSecureParameter(log, git.Info.PrivateSSHKey); err != nil {
+		return err, nil
+	}
+
+	// ssh refuses private keys other users can read, the key and known hosts live in a private temp directory
+	var sshDir string
+	if sshDir, err = ioutil.TempDir("", "gitssh"); err != nil {
+		return fmt.Errorf("Failed to create directory for the deploy key - %v", err), nil
+	}
+	defer os.RemoveAll(sshDir)
+
+	keyPath := filepath.Join(sshDir, "id_deploy")
+	if err = ioutil.WriteFile(keyPath, []byte(strings.TrimSpace(privateKey)+"\n"), 0600); err != nil {
+		return fmt.Errorf("Failed to write the deploy key - %v", err), nil
+	}
+	sshCommand := "ssh -i " + quote(keyPath) + " -o IdentitiesOnly=yes -o BatchMode=yes"
+	if git.Info.KnownHosts != "" {
+		knownHostsPath := filepath.Join(sshDir, "known_hosts")
+		if err = ioutil.WriteFile(knownHostsPath, []byte(git.Info.KnownHosts+"\n"), 0600); err != nil {
+			return fmt.Errorf("Failed to write known hosts - %v", err), nil
+		}
+		sshCommand += " -o StrictHostKeyChecking=yes -o UserKnownHostsFile=" + quote(knownHostsPath)
+	} else {
+		sshCommand += " -o StrictHostKeyChecking=accept-new"
+	}
+
+	for _, args := range git.cloneCommands(destinationPath) {
+		if err = git.gitsshdep.RunGit(log, filepath.Dir(destinationPath), append([]string{"-c", "core.sshCommand=" + sshCommand}, args...)); err != nil {
+			os.RemoveAll(destinationPath)
+			return err, nil
+		}
+	}
+
+	result = &remoteresource.DownloadResult{}
+	if result.Files, err = listFiles(destinationPath); err != nil {
+		return err, nil
+	}
+	return nil, result
+}
+
+// cloneCommands returns the git commands fetching the repository into destinationPath.
+// A commit is fetched on its own for shallow clones since shallow clones of a branch may not contain it.
+func (git *GitSSHResource) cloneCommands(destinationPath string) [][]string {
+	var depthArgs []string
+	if git.Info.Depth != "" {
+		depthArgs = []string{"--depth", git.Info.Depth}
+	}
+
+	if git.Info.CommitID != "" && len(depthArgs) > 0 {
+		gitArgs := []string{"-C", destinationPath}
+		return [][]string{
+			{"init", "--quiet", destinationPath},
+			append(gitArgs, "remote", "add", "origin", git.Info.Repository),
+			append(append(append(gitArgs, "fetch", "--quiet"), depthArgs...), "origin", git.Info.CommitID),
+			append(gitArgs, "checkout", "--quiet", "--detach", "FETCH_HEAD"),
+		}
+	}
+
+	clone := append([]string{"clone", "--quiet"}, depthArgs...)
+	if git.Info.Ref != "" {
+		clone = append(clone, "--branch", git.Info.Ref)
+		if len(depthArgs) == 0 {
+			clone = append(clone, "--single-branch")
+		}
+	}
+	commands := [][]string{append(clone, "--", git.Info.Repository, destinationPath)}
+	if git.Info.CommitID != "" {
+		commands = append(commands, []string{"-C", destinationPath, "checkout", "--quiet", "--detach", git.Info.CommitID})
+	}
+	return commands
+}
+
+// ValidateLocationInfo ensures that the required parameters of SourceInfo are specified
+func (git *GitSSHResource) ValidateLocationInfo() (valid bool, err error) {
+	if git.Info.Repository == "" {
+		return false, errors.New("Repository for Git SourceType must be specified")
+	}
+	if strings.HasPrefix(git.Info.Repository, "-") || !isSSHRepository(git.Info.Repository) {
+		return false, errors.New("Repository for Git SourceType must be an ssh url such as git@github.com:owner/repository.git")
+	}
+	if !ssmSecureParameterPattern.MatchString(git.Info.PrivateSSHKey) {
+		return false, errors.New("PrivateSSHKey for Git SourceType must be specified as '{{ ssm-secure:parameter-name }}'")
+	}
+	if strings.HasPrefix(git.Info.Ref, "-") {
+		return false, fmt.Errorf("Invalid ref %v", git.Info.Ref)
+	}
+	if git.Info.CommitID != "" && !commitIDPattern.MatchString(git.Info.CommitID) {
+		return false, fmt.Errorf("Invalid commitID %v", git.Info.CommitID)
+	}
+	if git.Info.Depth != "" {
+		if depth, err := strconv.Atoi(git.Info.Depth); err != nil || depth < 1 {
+			return false, fmt.Errorf("Depth for Git SourceType must be a positive number, got %v", git.Info.Depth)
+		}
+		if git.Info.CommitID != "" && len(git.Info.CommitID) != 40 {
+			return false, errors.New("Shallow clones of a commit require the full 40 character commitID")
+		}
+	}
+	return true, nil
+}
+
+// isSSHRepository checks the repository is an ssh:// url or an scp like user@host:path address
+func isSSHRepository(repository string) bool {
+	if strings.HasPrefix(repository, "ssh://") {
+		return true
+	}
+	at := strings.Index(repository, "@")
+	colon := strings.Index(repository, ":")
+	return !strings.Contains(repository, "://") && at > 0 && colon > at+1 && colon < len(repository)-1
+}
+
+// repositoryName returns the name of the repository without the .git suffix
+func repositoryName(repository string) string {
+	name := strings.TrimSuffix(strings.TrimRight(repository, "/"), ".git")
+	if index := strings.LastIndexAny(name, "/:"); index >= 0 {
+		name = name[index+1:]
+	}
+	return name
+}
+
+// listFiles returns the files of the cloned repository, leaving out the git metadata
+func listFiles(root string) (files []string, err error) {
+	err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if info.Name() == gitDir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.Mode().IsRegular() {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return
+}
+
+// quote quotes a path for the ssh command run by git through the shell
+func quote(path string) string {
+	return "'" + strings.Replace(filepath.ToSlash(path), "'", `'\''`, -1) + "'"
+}
@@ -0,0 +1,164 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package downloadcontent
+
+import (
+	"github.com/aws/amazon-ssm-agent/agent/log"
+
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"math/big"
+	"os"
+	"strings"
+)
+
+const (
+	SHA256 = "sha256" //SHA256 is the checksum algorithm of 64 character checksums
+	SHA512 = "sha512" //SHA512 is the checksum algorithm of 128 character checksums
+)
+
+// parseChecksum returns the algorithm and value of a checksum given as <algorithm>:<hex value> or as a bare hex value,
+// in which case the algorithm is derived from its length.
+func parseChecksum(checksum string) (algorithm string, value string, err error) {
+	value = strings.ToLower(strings.TrimSpace(checksum))
+	if index := strings.Index(value, ":"); index >= 0 {
+		algorithm, value = value[:index], value[index+1:]
+	}
+	if _, err = hex.DecodeString(value); err != nil {
+		return "", "", fmt.Errorf("Checksum %v must be a hex encoded sha256 or sha512 value", checksum)
+	}
+
+	switch {
+	case (algorithm == "" || algorithm == SHA256) && len(value) == sha256.Size*2:
+		return SHA256, value, nil
+	case (algorithm == "" || algorithm == SHA512) && len(value) == sha512.Size*2:
+		return SHA512, value, nil
+	}
+	return "", "", fmt.Errorf("Checksum %v must be a hex encoded sha256 or sha512 value", checksum)
+}
+
+// parsePublicKey parses a PEM encoded PKIX public key, the key signing the content must be RSA or ECDSA.
+func parsePublicKey(publicKey string) (crypto.PublicKey, error) {
+	block, _ := pem.Decode([]byte(publicKey))
+	if block == nil {
+		return nil, errors.New("SignaturePublicKey must be a PEM encoded public key")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("SignaturePublicKey could not be parsed - %v", err)
+	}
+	switch key.(type) {
+	case *rsa.PublicKey, *ecdsa.PublicKey:
+		return key, nil
+	}
+	return nil, fmt.Errorf("Unsupported SignaturePublicKey type %T", key)
+}
+
+// verifyContent validates the downloaded file against the checksum and the detached signature of the input.
+// A single file must have been downloaded since both describe one artifact.
+func verifyContent(log log.T, input *DownloadContentPlugin, files []string) error {
+	if input.Checksum == "" && input.Signature == "" {
+		return nil
+	}
+	if len(files) != 1 {
+		return fmt.Errorf("Checksum and Signature can only be verified for a single file, %v files were downloaded", len(files))
+	}
+	path := files[0]
+
+	if input.Checksum != "" {
+		algorithm, expected, err := parseChecksum(input.Checksum)
+		if err != nil {
+			return err
+		}
+		var hasher hash.Hash
+		if algorithm == SHA512 {
+			hasher = sha512.New()
+		} else {
+			hasher = sha256.New()
+		}
+		if err = hashFile(path, hasher); err != nil {
+			return err
+		}
+		if actual := hex.EncodeToString(hasher.Sum(nil)); actual != expected {
+			return fmt.Errorf("%v checksum of %v is %v, expected %v", algorithm, path, actual, expected)
+		}
+		log.Infof("%v checksum of %v verified", algorithm, path)
+	}
+
+	if input.Signature != "" {
+		if err := verifySignature(path, input.Signature, input.SignaturePublicKey); err != nil {
+			return fmt.Errorf("Signature verification of %v failed - %v", path, err)
+		}
+		log.Infof("Signature of %v verified", path)
+	}
+	return nil
+}
+
+// verifySignature checks the base64 encoded detached signature of the sha256 digest of the file,
+// as produced by openssl dgst -sha256 -sign with an RSA (PKCS #1 v1.5) or ECDSA key.
+func verifySignature(path string, signature string, publicKey string) error {
+	key, err := parsePublicKey(publicKey)
+	if err != nil {
+		return err
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(signature))
+	if err != nil {
+		return fmt.Errorf("Signature must be base64 encoded - %v", err)
+	}
+
+	hasher := sha256.New()
+	if err = hashFile(path, hasher); err != nil {
+		return err
+	}
+	digest := hasher.Sum(nil)
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, digest, sig)
+	case *ecdsa.PublicKey:
+		var ecdsaSig struct {
+			R, S *big.Int
+		}
+		if _, err = asn1.Unmarshal(sig, &ecdsaSig); err != nil {
+			return fmt.Errorf("invalid ECDSA signature - %v", err)
+		}
+		if !ecdsa.Verify(key, digest, ecdsaSig.R, ecdsaSig.S) {
+			return errors.New("signature does not match the content")
+		}
+	}
+	return nil
+}
+
+// hashFile writes the content of the file to the hasher
+func hashFile(path string, hasher hash.Hash) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = io.Copy(hasher, file)
+	return err
+}
@@ -0,0 +1,157 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package downloadcontent
+
+import (
+	"github.com/stretchr/testify/assert"
+
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+const (
+	content        = "#!/bin/sh\necho hello\n"
+	invalidPEMText = "-----BEGIN PUBLIC KEY-----\nbm90IGEga2V5\n-----END PUBLIC KEY-----"
+)
+
+var contentSHA256 = sha256.Sum256([]byte(content))
+var contentSHA512 = sha512.Sum512([]byte(content))
+
+func writeContent(t *testing.T) string {
+	file, err := ioutil.TempFile("", "downloadcontent")
+	assert.NoError(t, err)
+	defer file.Close()
+	_, err = file.WriteString(content)
+	assert.NoError(t, err)
+	return file.Name()
+}
+
+func publicKeyPEM(t *testing.T, key crypto.PublicKey) string {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	assert.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func TestParseChecksum(t *testing.T) {
+	algorithm, value, err := parseChecksum(hex.EncodeToString(contentSHA256[:]))
+	assert.NoError(t, err)
+	assert.Equal(t, SHA256, algorithm)
+	assert.Equal(t, hex.EncodeToString(contentSHA256[:]), value)
+
+	algorithm, _, err = parseChecksum("sha512:" + hex.EncodeToString(contentSHA512[:]))
+	assert.NoError(t, err)
+	assert.Equal(t, SHA512, algorithm)
+
+	_, _, err = parseChecksum("sha512:" + hex.EncodeToString(contentSHA256[:]))
+	assert.Error(t, err)
+
+	_, _, err = parseChecksum("md5:d41d8cd98f00b204e9800998ecf8427e")
+	assert.Error(t, err)
+
+	_, _, err = parseChecksum("not-hex")
+	assert.Error(t, err)
+}
+
+func TestVerifyContent_Checksum(t *testing.T) {
+	path := writeContent(t)
+	defer os.Remove(path)
+
+	input := DownloadContentPlugin{Checksum: "SHA256:" + hex.EncodeToString(contentSHA256[:])}
+	assert.NoError(t, verifyContent(logger, &input, []string{path}))
+
+	input.Checksum = hex.EncodeToString(contentSHA512[:])
+	assert.NoError(t, verifyContent(logger, &input, []string{path}))
+
+	input.Checksum = strings.Repeat("0", 64)
+	err := verifyContent(logger, &input, []string{path})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "sha256 checksum")
+
+	input.Checksum = hex.EncodeToString(contentSHA256[:])
+	assert.Error(t, verifyContent(logger, &input, []string{path, path}))
+}
+
+func TestVerifyContent_RSASignature(t *testing.T) {
+	path := writeContent(t)
+	defer os.Remove(path)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, contentSHA256[:])
+	assert.NoError(t, err)
+
+	input := DownloadContentPlugin{
+		Signature:          base64.StdEncoding.EncodeToString(sig),
+		SignaturePublicKey: publicKeyPEM(t, &key.PublicKey),
+	}
+	assert.NoError(t, verifyContent(logger, &input, []string{path}))
+
+	assert.NoError(t, ioutil.WriteFile(path, []byte(content+"rm -rf /\n"), 0644))
+	assert.Error(t, verifyContent(logger, &input, []string{path}))
+}
+
+func TestVerifyContent_ECDSASignature(t *testing.T) {
+	path := writeContent(t)
+	defer os.Remove(path)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	r, s, err := ecdsa.Sign(rand.Reader, key, contentSHA256[:])
+	assert.NoError(t, err)
+	sig, err := asn1.Marshal(struct{ R, S interface{} }{r, s})
+	assert.NoError(t, err)
+
+	input := DownloadContentPlugin{
+		Signature:          base64.StdEncoding.EncodeToString(sig),
+		SignaturePublicKey: publicKeyPEM(t, &key.PublicKey),
+	}
+	assert.NoError(t, verifyContent(logger, &input, []string{path}))
+
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	input.SignaturePublicKey = publicKeyPEM(t, &otherKey.PublicKey)
+	assert.Error(t, verifyContent(logger, &input, []string{path}))
+}
+
+func TestValidateInput_Verification(t *testing.T) {
+	input := DownloadContentPlugin{SourceType: "S3", SourceInfo: "{}", Checksum: "abc"}
+	valid, err := validateInput(&input)
+	assert.False(t, valid)
+	assert.Error(t, err)
+
+	input = DownloadContentPlugin{SourceType: "S3", SourceInfo: "{}", Signature: "c2lnbmF0dXJl"}
+	valid, err = validateInput(&input)
+	assert.False(t, valid)
+	assert.Contains(t, err.Error(), "must be specified together")
+
+	input.SignaturePublicKey = invalidPEMText
+	valid, err = validateInput(&input)
+	assert.False(t, valid)
+	assert.Error(t, err)
+}
@@ -0,0 +1,415 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// +build windows
+
+// Package editregistry implements the aws:editRegistry plugin.
+package editregistry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/fileutil"
+	"github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler"
+	"github.com/aws/amazon-ssm-agent/agent/jsonutil"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+	"golang.org/x/sys/windows/registry"
+)
+
+const (
+	actionSet       = "Set"
+	actionDelete    = "Delete"
+	actionDeleteKey = "DeleteKey"
+	actionRestore   = "Restore"
+
+	typeDWord   = "DWORD"
+	typeString  = "SZ"
+	typeMultiSZ = "MULTI_SZ"
+)
+
+// registryRoots maps the root key names, long and abbreviated, to the predefined keys
+var registryRoots = map[string]registry.Key{
+	"HKLM":                registry.LOCAL_MACHINE,
+	"HKEY_LOCAL_MACHINE":  registry.LOCAL_MACHINE,
+	"HKCU":                registry.CURRENT_USER,
+	"HKEY_CURRENT_USER":   registry.CURRENT_USER,
+	"HKU":                 registry.USERS,
+	"HKEY_USERS":          registry.USERS,
+	"HKCR":                registry.CLASSES_ROOT,
+	"HKEY_CLASSES_ROOT":   registry.CLASSES_ROOT,
+	"HKCC":                registry.CURRENT_CONFIG,
+	"HKEY_CURRENT_CONFIG": registry.CURRENT_CONFIG,
+}
+
+// Plugin is the type for the aws:editRegistry plugin.
+type Plugin struct{}
+
+// EditRegistryPluginInput represents one registry edit performed by the aws:editRegistry plugin.
+type EditRegistryPluginInput struct {
+	contracts.PluginInput
+	ID         string
+	Action     string
+	Path       string
+	ValueName  string
+	ValueType  string
+	Value      interface{}
+	BackupPath string
+}
+
+// registryValue is a typed registry value, the value is an uint32, a string or a []string depending on the type.
+type registryValue struct {
+	Type  string
+	Value interface{}
+}
+
+// registryBackup records the value of a registry entry before the plugin changed it.
+type registryBackup struct {
+	Path      string
+	ValueName string
+	Existed   bool
+	ValueType string      `json:",omitempty"`
+	Value     interface{} `json:",omitempty"`
+}
+
+// NewPlugin returns a new instance of the plugin.
+func NewPlugin() (*Plugin, error) {
+	return &Plugin{}, nil
+}
+
+// Name returns the plugin name
+func Name() string {
+	return appconfig.PluginNameAwsEditRegistry
+}
+
+// Execute applies the registry edit and reports whether the registry changed.
+func (p *Plugin) Execute(context context.T, config contracts.Configuration, cancelFlag task.CancelFlag, output iohandler.IOHandler) {
+	log := context.Log()
+	log.Infof("%v started with configuration %v", Name(), config)
+
+	if cancelFlag.ShutDown() {
+		output.MarkAsShutdown()
+	} else if cancelFlag.Canceled() {
+		output.MarkAsCancelled()
+	} else if input, value, err := parseAndValidateInput(config.Properties); err != nil {
+		output.MarkAsFailed(err)
+	} else {
+		editRegistry(log, input, value, config.OrchestrationDirectory, output)
+	}
+}
+
+// editRegistry performs the action and backs up the prior value when asked to.
+func editRegistry(log log.T, input *EditRegistryPluginInput, value *registryValue, orchestrationDirectory string, output iohandler.IOHandler) {
+	backupPath := input.BackupPath
+	if backupPath != "" && !filepath.IsAbs(backupPath) {
+		backupPath = filepath.Join(orchestrationDirectory, backupPath)
+	}
+
+	action := input.Action
+	if action == "" {
+		action = actionSet
+	}
+	if action == actionRestore {
+		backup, err := readBackup(backupPath)
+		if err != nil {
+			output.MarkAsFailed(fmt.Errorf("failed to read backup %v: %v", backupPath, err))
+			return
+		}
+		input.Path, input.ValueName = backup.Path, backup.ValueName
+		if value, err = backup.value(); err != nil {
+			output.MarkAsFailed(fmt.Errorf("invalid backup %v: %v", backupPath, err))
+			return
+		}
+		action = actionDelete
+		if backup.Existed {
+			action = actionSet
+		}
+		// the backup is kept as is so that it can be restored again
+		backupPath = ""
+	}
+
+	var changed bool
+	var err error
+	if action == actionDeleteKey {
+		changed, err = deleteKey(log, input.Path)
+	} else {
+		changed, err = editValue(log, input.Path, input.ValueName, value, backupPath)
+	}
+	if err != nil {
+		output.MarkAsFailed(err)
+		return
+	}
+
+	target := input.Path
+	if action != actionDeleteKey {
+		target = fmt.Sprintf("%v\\%v", input.Path, input.ValueName)
+	}
+	if changed {
+		output.AppendInfof("Changed: %v", target)
+	} else {
+		output.AppendInfof("Unchanged: %v", target)
+	}
+	output.MarkAsSucceeded()
+}
+
+// editValue sets the value, or deletes it when value is nil, unless the registry already matches.
+func editValue(log log.T, path string, valueName string, value *registryValue, backupPath string) (changed bool, err error) {
+	root, subPath, err := parsePath(path)
+	if err != nil {
+		return false, err
+	}
+
+	var key registry.Key
+	if value == nil {
+		if key, err = registry.OpenKey(root, subPath, registry.QUERY_VALUE|registry.SET_VALUE); err == registry.ErrNotExist {
+			return false, nil
+		}
+	} else {
+		key, _, err = registry.CreateKey(root, subPath, registry.QUERY_VALUE|registry.SET_VALUE)
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to open registry key %v: %v", path, err)
+	}
+	defer key.Close()
+
+	current, err := readValue(key, valueName)
+	if err != nil {
+		return false, fmt.Errorf("failed to read registry value %v\\%v: %v", path, valueName, err)
+	}
+	if reflect.DeepEqual(current, value) {
+		log.Debugf("Registry value %v\\%v is up to date", path, valueName)
+		return false, nil
+	}
+
+	if backupPath != "" {
+		if err = writeBackup(backupPath, path, valueName, current); err != nil {
+			return false, fmt.Errorf("failed to back up registry value %v\\%v: %v", path, valueName, err)
+		}
+	}
+
+	if value == nil {
+		err = key.DeleteValue(valueName)
+	} else {
+		err = writeValue(key, valueName, value)
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to update registry value %v\\%v: %v", path, valueName, err)
+	}
+	return true, nil
+}
+
+// deleteKey deletes the registry key, a key with subkeys can't be deleted.
+func deleteKey(log log.T, path string) (changed bool, err error) {
+	root, subPath, err := parsePath(path)
+	if err != nil {
+		return false, err
+	}
+	if err = registry.DeleteKey(root, subPath); err == registry.ErrNotExist {
+		log.Debugf("Registry key %v does not exist", path)
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("failed to delete registry key %v: %v", path, err)
+	}
+	return true, nil
+}
+
+// readValue returns the value, nil if it doesn't exist.
+func readValue(key registry.Key, valueName string) (*registryValue, error) {
+	_, valueType, err := key.GetValue(valueName, nil)
+	if err == registry.ErrNotExist {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	switch valueType {
+	case registry.DWORD:
+		value, _, err := key.GetIntegerValue(valueName)
+		return &registryValue{Type: typeDWord, Value: uint32(value)}, err
+	case registry.SZ:
+		value, _, err := key.GetStringValue(valueName)
+		return &registryValue{Type: typeString, Value: value}, err
+	case registry.MULTI_SZ:
+		value, _, err := key.GetStringsValue(valueName)
+		return &registryValue{Type: typeMultiSZ, Value: value}, err
+	}
+	// values of other types never match so that they're replaced
+	return &registryValue{Type: strconv.Itoa(int(valueType))}, nil
+}
+
+// writeValue sets the value with its type.
+func writeValue(key registry.Key, valueName string, value *registryValue) error {
+	switch value.Type {
+	case typeDWord:
+		return key.SetDWordValue(valueName, value.Value.(uint32))
+	case typeMultiSZ:
+		return key.SetStringsValue(valueName, value.Value.([]string))
+	default:
+		return key.SetStringValue(valueName, value.Value.(string))
+	}
+}
+
+// writeBackup saves the current value, a missing value is recorded so that restoring deletes it.
+func writeBackup(backupPath string, path string, valueName string, current *registryValue) error {
+	backup := registryBackup{Path: path, ValueName: valueName}
+	if current != nil {
+		switch current.Type {
+		case typeDWord, typeString, typeMultiSZ:
+		default:
+			return fmt.Errorf("values of registry type %v can't be backed up", current.Type)
+		}
+		backup.Existed = true
+		backup.ValueType = current.Type
+		backup.Value = current.Value
+	}
+
+	content, err := json.MarshalIndent(backup, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = fileutil.MakeDirs(filepath.Dir(backupPath)); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(backupPath, content, appconfig.ReadWriteAccess)
+}
+
+// readBackup reads a backup written by writeBackup.
+func readBackup(backupPath string) (*registryBackup, error) {
+	content, err := ioutil.ReadFile(backupPath)
+	if err != nil {
+		return nil, err
+	}
+	var backup registryBackup
+	if err = json.Unmarshal(content, &backup); err != nil {
+		return nil, err
+	}
+	if _, _, err = parsePath(backup.Path); err != nil {
+		return nil, err
+	}
+	return &backup, nil
+}
+
+// value returns the backed up value, nil if the value didn't exist.
+func (backup *registryBackup) value() (*registryValue, error) {
+	if !backup.Existed {
+		return nil, nil
+	}
+	return parseValue(backup.ValueType, backup.Value)
+}
+
+// parsePath splits a registry path into its predefined root key and the path of the subkey.
+func parsePath(path string) (registry.Key, string, error) {
+	parts := strings.SplitN(strings.Trim(path, "\\"), "\\", 2)
+	root, ok := registryRoots[strings.ToUpper(parts[0])]
+	if !ok {
+		return 0, "", fmt.Errorf("registry path %v must start with one of HKLM, HKCU, HKU, HKCR or HKCC", path)
+	}
+	if len(parts) < 2 || parts[1] == "" {
+		return 0, "", fmt.Errorf("registry path %v must name a subkey of %v", path, parts[0])
+	}
+	return root, parts[1], nil
+}
+
+// parseValue converts the document value to the registry type,
+// DWORD values can be given as numbers or decimal or 0x prefixed hexadecimal strings.
+func parseValue(valueType string, value interface{}) (*registryValue, error) {
+	switch strings.ToUpper(valueType) {
+	case typeDWord:
+		var number float64
+		switch v := value.(type) {
+		case float64:
+			number = v
+		case string:
+			parsed, err := strconv.ParseUint(v, 0, 32)
+			if err != nil {
+				return nil, fmt.Errorf("DWORD value %q is not a 32 bit unsigned number", v)
+			}
+			number = float64(parsed)
+		default:
+			return nil, fmt.Errorf("DWORD value must be a number, got %v", value)
+		}
+		if number < 0 || number > math.MaxUint32 || number != math.Trunc(number) {
+			return nil, fmt.Errorf("DWORD value %v is not a 32 bit unsigned number", number)
+		}
+		return &registryValue{Type: typeDWord, Value: uint32(number)}, nil
+	case typeString:
+		text, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("SZ value must be a string, got %v", value)
+		}
+		return &registryValue{Type: typeString, Value: text}, nil
+	case typeMultiSZ:
+		var lines []string
+		if err := jsonutil.Remarshal(value, &lines); err != nil || value == nil {
+			return nil, fmt.Errorf("MULTI_SZ value must be a list of strings, got %v", value)
+		}
+		for _, line := range lines {
+			if line == "" {
+				return nil, errors.New("MULTI_SZ value can't contain empty strings")
+			}
+		}
+		return &registryValue{Type: typeMultiSZ, Value: lines}, nil
+	}
+	return nil, fmt.Errorf("unsupported ValueType %q, supported types are DWORD, SZ and MULTI_SZ", valueType)
+}
+
+// parseAndValidateInput parses the plugin properties and returns them along with the typed value to set
+func parseAndValidateInput(rawPluginInput interface{}) (*EditRegistryPluginInput, *registryValue, error) {
+	var input EditRegistryPluginInput
+	if err := jsonutil.Remarshal(rawPluginInput, &input); err != nil {
+		return nil, nil, fmt.Errorf("invalid format in plugin properties %v; \nerror %v", rawPluginInput, err)
+	}
+
+	value, err := validateInput(&input)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid input: %v", err)
+	}
+	return &input, value, nil
+}
+
+// validateInput ensures the plugin input matches the defined schema
+func validateInput(input *EditRegistryPluginInput) (*registryValue, error) {
+	switch input.Action {
+	case "", actionSet:
+		if _, _, err := parsePath(input.Path); err != nil {
+			return nil, err
+		}
+		return parseValue(input.ValueType, input.Value)
+	case actionDelete:
+		_, _, err := parsePath(input.Path)
+		return nil, err
+	case actionDeleteKey:
+		if input.BackupPath != "" {
+			return nil, errors.New("BackupPath is not supported with the DeleteKey action")
+		}
+		_, _, err := parsePath(input.Path)
+		return nil, err
+	case actionRestore:
+		if input.BackupPath == "" {
+			return nil, errors.New("BackupPath must be specified to restore a registry value")
+		}
+		return nil, nil
+	}
+	return nil, fmt.Errorf("unsupported Action %q, supported actions are Set, Delete, DeleteKey and Restore", input.Action)
+}
@@ -0,0 +1,114 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// +build windows
+
+package editregistry
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/sys/windows/registry"
+)
+
+const testKeyPath = `HKCU\Software\AmazonSSMAgentEditRegistryTest`
+
+func TestParsePath(t *testing.T) {
+	root, subPath, err := parsePath(`HKEY_LOCAL_MACHINE\SOFTWARE\Contoso\App`)
+	assert.NoError(t, err)
+	assert.Equal(t, registry.LOCAL_MACHINE, root)
+	assert.Equal(t, `SOFTWARE\Contoso\App`, subPath)
+
+	_, _, err = parsePath(`HKLM`)
+	assert.Error(t, err)
+	_, _, err = parsePath(`SOFTWARE\Contoso`)
+	assert.Error(t, err)
+}
+
+func TestParseValue(t *testing.T) {
+	value, err := parseValue("dword", float64(1))
+	assert.NoError(t, err)
+	assert.Equal(t, &registryValue{Type: typeDWord, Value: uint32(1)}, value)
+
+	value, err = parseValue(typeDWord, "0xffffffff")
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(0xffffffff), value.Value)
+
+	_, err = parseValue(typeDWord, float64(-1))
+	assert.Error(t, err)
+	_, err = parseValue(typeDWord, "4294967296")
+	assert.Error(t, err)
+
+	value, err = parseValue(typeMultiSZ, []interface{}{"a", "b"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, value.Value)
+
+	_, err = parseValue(typeMultiSZ, []interface{}{"a", ""})
+	assert.Error(t, err)
+	_, err = parseValue(typeString, float64(1))
+	assert.Error(t, err)
+	_, err = parseValue("BINARY", "00")
+	assert.Error(t, err)
+}
+
+func TestValidateInput(t *testing.T) {
+	_, err := validateInput(&EditRegistryPluginInput{Path: testKeyPath, ValueName: "Enabled", ValueType: typeDWord, Value: float64(1)})
+	assert.NoError(t, err)
+	_, err = validateInput(&EditRegistryPluginInput{Action: actionDeleteKey, Path: testKeyPath, BackupPath: "backup.json"})
+	assert.Error(t, err)
+	_, err = validateInput(&EditRegistryPluginInput{Action: actionRestore})
+	assert.Error(t, err)
+	_, err = validateInput(&EditRegistryPluginInput{Action: "Rename", Path: testKeyPath})
+	assert.Error(t, err)
+}
+
+func TestEditRegistrySetAndRestore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "editregistry")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	defer registry.DeleteKey(registry.CURRENT_USER, `Software\AmazonSSMAgentEditRegistryTest`)
+
+	input := &EditRegistryPluginInput{Path: testKeyPath, ValueName: "Servers", ValueType: typeMultiSZ, Value: []interface{}{"a", "b"}, BackupPath: "backup.json"}
+	value, err := validateInput(input)
+	assert.NoError(t, err)
+
+	output := &iohandler.DefaultIOHandler{}
+	editRegistry(log.NewMockLog(), input, value, dir, output)
+	assert.Equal(t, contracts.ResultStatusSuccess, output.GetStatus())
+	assert.Contains(t, output.GetStdout(), "Changed")
+
+	output = &iohandler.DefaultIOHandler{}
+	editRegistry(log.NewMockLog(), input, value, dir, output)
+	assert.Contains(t, output.GetStdout(), "Unchanged")
+
+	backup, err := readBackup(filepath.Join(dir, "backup.json"))
+	assert.NoError(t, err)
+	assert.False(t, backup.Existed)
+
+	output = &iohandler.DefaultIOHandler{}
+	editRegistry(log.NewMockLog(), &EditRegistryPluginInput{Action: actionRestore, BackupPath: "backup.json"}, nil, dir, output)
+	assert.Equal(t, contracts.ResultStatusSuccess, output.GetStatus())
+
+	key, err := registry.OpenKey(registry.CURRENT_USER, `Software\AmazonSSMAgentEditRegistryTest`, registry.QUERY_VALUE)
+	assert.NoError(t, err)
+	defer key.Close()
+	_, _, err = key.GetStringsValue("Servers")
+	assert.Equal(t, registry.ErrNotExist, err)
+}
@@ -0,0 +1,273 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package extractarchive implements the aws:extractArchive plugin.
+package extractarchive
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/fileutil"
+	"github.com/aws/amazon-ssm-agent/agent/fileutil/artifact"
+	"github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler"
+	"github.com/aws/amazon-ssm-agent/agent/jsonutil"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+)
+
+const (
+	downloadsDir = "downloads" //Directory under the orchestration directory where the downloaded resource resides
+
+	archiveTypeZip   = "zip"
+	archiveTypeTar   = "tar"
+	archiveTypeTarGz = "tar.gz"
+	archiveTypeTarXz = "tar.xz"
+
+	// OverwritePolicy values, existing files are replaced by default
+	overwriteAlways = "Always"
+	overwriteNever  = "Never"
+	overwriteFail   = "Fail"
+)
+
+// Assign method to global variables to allow unittest to override
+var download = artifact.Download
+var lookPath = exec.LookPath
+
+// Plugin is the type for the aws:extractArchive plugin.
+type Plugin struct{}
+
+// ExtractArchivePluginInput represents one archive extracted by the aws:extractArchive plugin.
+type ExtractArchivePluginInput struct {
+	contracts.PluginInput
+	ID              string
+	SourceUrl       string
+	SourcePath      string
+	SourceHash      string
+	ArchiveType     string
+	DestinationPath string
+	StripComponents interface{}
+	OverwritePolicy string
+	Owner           string
+	Group           string
+}
+
+// NewPlugin returns a new instance of the plugin.
+func NewPlugin() (*Plugin, error) {
+	return &Plugin{}, nil
+}
+
+// Name returns the plugin name
+func Name() string {
+	return appconfig.PluginNameAwsExtractArchive
+}
+
+// Execute fetches the archive and extracts it to the destination path.
+func (p *Plugin) Execute(context context.T, config contracts.Configuration, cancelFlag task.CancelFlag, output iohandler.IOHandler) {
+	log := context.Log()
+	log.Infof("%v started with configuration %v", Name(), config)
+
+	if cancelFlag.ShutDown() {
+		output.MarkAsShutdown()
+	} else if cancelFlag.Canceled() {
+		output.MarkAsCancelled()
+	} else if input, err := parseAndValidateInput(config.Properties); err != nil {
+		output.MarkAsFailed(err)
+	} else {
+		extractArchive(log, input, config.PluginID, config.OrchestrationDirectory, output)
+	}
+}
+
+// extractArchive resolves the archive and the destination and extracts every entry of the archive.
+func extractArchive(log log.T, input *ExtractArchivePluginInput, pluginID string, orchestrationDirectory string, output iohandler.IOHandler) {
+	// Relative paths are resolved against the downloads directory shared with aws:downloadContent
+	downloadsPath := filepath.Join(strings.TrimSuffix(orchestrationDirectory, pluginID), downloadsDir)
+	resolve := func(path string) string {
+		if filepath.IsAbs(path) {
+			return path
+		}
+		return filepath.Join(downloadsPath, path)
+	}
+
+	archivePath, err := fetchArchive(log, input, orchestrationDirectory, resolve)
+	if err != nil {
+		output.MarkAsFailed(err)
+		return
+	}
+
+	archiveType := input.ArchiveType
+	if archiveType == "" {
+		archiveType = archiveTypeOf(input.SourcePath + input.SourceUrl)
+	}
+	strip, _ := stripComponents(input.StripComponents)
+	policy := input.OverwritePolicy
+	if policy == "" {
+		policy = overwriteAlways
+	}
+
+	x := &extractor{
+		log:             log,
+		destination:     resolve(input.DestinationPath),
+		stripComponents: strip,
+		overwritePolicy: policy,
+	}
+	if err = fileutil.MakeDirs(x.destination); err != nil {
+		output.MarkAsFailed(fmt.Errorf("failed to create destination directory %v: %v", x.destination, err))
+		return
+	}
+
+	log.Debugf("Extracting %v archive %v to %v", archiveType, archivePath, x.destination)
+	if err = x.extract(archiveType, archivePath); err != nil {
+		output.MarkAsFailed(fmt.Errorf("failed to extract %v: %v", archivePath, err))
+		return
+	}
+
+	if err = applyOwnership(x.extracted, input.Owner, input.Group); err != nil {
+		output.MarkAsFailed(fmt.Errorf("failed to set ownership of the extracted files: %v", err))
+		return
+	}
+
+	output.AppendInfof("Extracted %v entries to %v, %v existing files skipped", len(x.extracted), x.destination, x.skipped)
+	output.MarkAsSucceeded()
+}
+
+// fetchArchive returns the local path of the archive, downloading it first when a SourceUrl is given.
+func fetchArchive(log log.T, input *ExtractArchivePluginInput, orchestrationDirectory string, resolve func(string) string) (string, error) {
+	if input.SourceUrl == "" {
+		archivePath := resolve(input.SourcePath)
+		if !fileutil.IsFile(archivePath) {
+			return "", fmt.Errorf("SourcePath %v does not exist", archivePath)
+		}
+		return archivePath, nil
+	}
+
+	workingDir := fileutil.BuildPath(orchestrationDirectory, input.ID)
+	if err := fileutil.MakeDirs(workingDir); err != nil {
+		return "", fmt.Errorf("failed to create orchestrationDir directory, %v", workingDir)
+	}
+	downloadInput := artifact.DownloadInput{
+		SourceURL:            input.SourceUrl,
+		DestinationDirectory: workingDir,
+	}
+	if input.SourceHash != "" {
+		downloadInput.SourceChecksums = map[string]string{"sha256": input.SourceHash}
+	}
+	downloadOutput, err := download(log, downloadInput)
+	if err != nil {
+		return "", fmt.Errorf("failed to download %v: %v", input.SourceUrl, err)
+	}
+	if !downloadOutput.IsHashMatched {
+		return "", fmt.Errorf("checksum of %v doesn't match SourceHash", input.SourceUrl)
+	}
+	return downloadOutput.LocalFilePath, nil
+}
+
+// archiveTypeOf derives the archive type from the file extension.
+func archiveTypeOf(name string) string {
+	name = strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(name, ".zip"):
+		return archiveTypeZip
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		return archiveTypeTarGz
+	case strings.HasSuffix(name, ".tar.xz"), strings.HasSuffix(name, ".txz"):
+		return archiveTypeTarXz
+	case strings.HasSuffix(name, ".tar"):
+		return archiveTypeTar
+	}
+	return ""
+}
+
+// stripComponents parses the number of leading path components to remove, it can be given as a number or a string.
+func stripComponents(value interface{}) (int, error) {
+	var strip int
+	switch v := value.(type) {
+	case nil:
+		return 0, nil
+	case float64:
+		strip = int(v)
+		if float64(strip) != v {
+			return 0, fmt.Errorf("StripComponents must be a whole number, got %v", v)
+		}
+	case string:
+		if v == "" {
+			return 0, nil
+		}
+		var err error
+		if strip, err = strconv.Atoi(v); err != nil {
+			return 0, fmt.Errorf("StripComponents must be a number, got %q", v)
+		}
+	default:
+		return 0, fmt.Errorf("StripComponents must be a number, got %v", v)
+	}
+	if strip < 0 {
+		return 0, fmt.Errorf("StripComponents must not be negative, got %v", strip)
+	}
+	return strip, nil
+}
+
+// parseAndValidateInput parses the plugin properties and validates them
+func parseAndValidateInput(rawPluginInput interface{}) (*ExtractArchivePluginInput, error) {
+	var input ExtractArchivePluginInput
+	if err := jsonutil.Remarshal(rawPluginInput, &input); err != nil {
+		return nil, fmt.Errorf("invalid format in plugin properties %v; \nerror %v", rawPluginInput, err)
+	}
+
+	if err := validateInput(&input); err != nil {
+		return nil, fmt.Errorf("invalid input: %v", err)
+	}
+	return &input, nil
+}
+
+// validateInput ensures the plugin input matches the defined schema
+func validateInput(input *ExtractArchivePluginInput) error {
+	if (input.SourceUrl == "") == (input.SourcePath == "") {
+		return errors.New("exactly one of SourceUrl and SourcePath must be specified")
+	}
+	if input.SourceUrl == "" && input.SourceHash != "" {
+		return errors.New("SourceHash requires SourceUrl")
+	}
+	if input.DestinationPath == "" {
+		return errors.New("DestinationPath must be specified")
+	}
+
+	archiveType := input.ArchiveType
+	if archiveType == "" {
+		if archiveType = archiveTypeOf(input.SourcePath + input.SourceUrl); archiveType == "" {
+			return errors.New("ArchiveType must be specified when it can't be derived from the source extension")
+		}
+	}
+	switch archiveType {
+	case archiveTypeZip, archiveTypeTar, archiveTypeTarGz, archiveTypeTarXz:
+	default:
+		return fmt.Errorf("unsupported ArchiveType %q, supported types are zip, tar, tar.gz and tar.xz", archiveType)
+	}
+
+	switch input.OverwritePolicy {
+	case "", overwriteAlways, overwriteNever, overwriteFail:
+	default:
+		return fmt.Errorf("unsupported OverwritePolicy %q, supported policies are Always, Never and Fail", input.OverwritePolicy)
+	}
+
+	if _, err := stripComponents(input.StripComponents); err != nil {
+		return err
+	}
+	return nil
+}
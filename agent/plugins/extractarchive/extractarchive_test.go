@@ -0,0 +1,276 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package extractarchive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler"
+	multiwritermock "github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler/multiwriter/mock"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var archiveEntries = []struct {
+	name    string
+	content string
+}{
+	{"app-1.0/", ""},
+	{"app-1.0/bin/app", "#!/bin/sh\necho app\n"},
+	{"app-1.0/README", "readme"},
+}
+
+func newOutput() *iohandler.DefaultIOHandler {
+	stdoutWriter := new(multiwritermock.MockDocumentIOMultiWriter)
+	stdoutWriter.On("WriteString", mock.Anything).Return(0, nil)
+	stderrWriter := new(multiwritermock.MockDocumentIOMultiWriter)
+	stderrWriter.On("WriteString", mock.Anything).Return(0, nil)
+	output := &iohandler.DefaultIOHandler{}
+	output.StdoutWriter = stdoutWriter
+	output.StderrWriter = stderrWriter
+	return output
+}
+
+func createZip(t *testing.T, path string) {
+	file, err := os.Create(path)
+	assert.NoError(t, err)
+	defer file.Close()
+	w := zip.NewWriter(file)
+	for _, entry := range archiveEntries {
+		f, err := w.Create(entry.name)
+		assert.NoError(t, err)
+		f.Write([]byte(entry.content))
+	}
+	assert.NoError(t, w.Close())
+}
+
+func createTarGz(t *testing.T, path string, extra ...*tar.Header) {
+	file, err := os.Create(path)
+	assert.NoError(t, err)
+	defer file.Close()
+	gw := gzip.NewWriter(file)
+	tw := tar.NewWriter(gw)
+	for _, entry := range archiveEntries {
+		hdr := &tar.Header{Name: entry.name, Mode: 0755, Size: int64(len(entry.content)), Typeflag: tar.TypeReg}
+		if entry.content == "" {
+			hdr.Typeflag = tar.TypeDir
+		}
+		assert.NoError(t, tw.WriteHeader(hdr))
+		tw.Write([]byte(entry.content))
+	}
+	for _, hdr := range extra {
+		assert.NoError(t, tw.WriteHeader(hdr))
+	}
+	assert.NoError(t, tw.Close())
+	assert.NoError(t, gw.Close())
+}
+
+func TestValidateInput(t *testing.T) {
+	valid := ExtractArchivePluginInput{SourcePath: "app.tar.gz", DestinationPath: "/opt/app", StripComponents: "1"}
+	assert.NoError(t, validateInput(&valid))
+
+	noSource := valid
+	noSource.SourcePath = ""
+	assert.Error(t, validateInput(&noSource))
+
+	unknownType := valid
+	unknownType.SourcePath = "app.rar"
+	assert.Error(t, validateInput(&unknownType))
+
+	explicitType := unknownType
+	explicitType.ArchiveType = "zip"
+	assert.NoError(t, validateInput(&explicitType))
+
+	invalidPolicy := valid
+	invalidPolicy.OverwritePolicy = "Sometimes"
+	assert.Error(t, validateInput(&invalidPolicy))
+
+	negativeStrip := valid
+	negativeStrip.StripComponents = float64(-1)
+	assert.Error(t, validateInput(&negativeStrip))
+}
+
+func TestStrip(t *testing.T) {
+	x := &extractor{stripComponents: 1}
+	relative, ok := x.strip("app-1.0/bin/app")
+	assert.True(t, ok)
+	assert.Equal(t, "bin/app", relative)
+
+	_, ok = x.strip("app-1.0/")
+	assert.False(t, ok)
+
+	relative, ok = x.strip("../../etc/passwd")
+	assert.True(t, ok)
+	assert.Equal(t, "passwd", relative)
+}
+
+func TestExtractZip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "extractarchive")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	archive := filepath.Join(dir, "app.zip")
+	createZip(t, archive)
+	destination := filepath.Join(dir, "app")
+
+	input := &ExtractArchivePluginInput{SourcePath: archive, DestinationPath: destination, StripComponents: float64(1)}
+	output := newOutput()
+	extractArchive(log.NewMockLog(), input, "aws:extractArchive", dir, output)
+	assert.Equal(t, contracts.ResultStatusSuccess, output.GetStatus())
+
+	content, _ := ioutil.ReadFile(filepath.Join(destination, "bin", "app"))
+	assert.Equal(t, "#!/bin/sh\necho app\n", string(content))
+	assert.True(t, fileExists(filepath.Join(destination, "README")))
+}
+
+func TestExtractTarGzOverwritePolicy(t *testing.T) {
+	dir, err := ioutil.TempDir("", "extractarchive")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	archive := filepath.Join(dir, "app.tar.gz")
+	createTarGz(t, archive)
+	destination := filepath.Join(dir, "app")
+	assert.NoError(t, os.MkdirAll(destination, 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(destination, "README"), []byte("local"), 0644))
+
+	input := &ExtractArchivePluginInput{SourcePath: archive, DestinationPath: destination, StripComponents: "1", OverwritePolicy: overwriteFail}
+	output := newOutput()
+	extractArchive(log.NewMockLog(), input, "aws:extractArchive", dir, output)
+	assert.Equal(t, contracts.ResultStatusFailed, output.GetStatus())
+
+	input.OverwritePolicy = overwriteNever
+	output = newOutput()
+	extractArchive(log.NewMockLog(), input, "aws:extractArchive", dir, output)
+	assert.Equal(t, contracts.ResultStatusSuccess, output.GetStatus())
+	content, _ := ioutil.ReadFile(filepath.Join(destination, "README"))
+	assert.Equal(t, "local", string(content))
+	info, _ := os.Stat(filepath.Join(destination, "bin", "app"))
+	assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+
+	input.OverwritePolicy = ""
+	output = newOutput()
+	extractArchive(log.NewMockLog(), input, "aws:extractArchive", dir, output)
+	assert.Equal(t, contracts.ResultStatusSuccess, output.GetStatus())
+	content, _ = ioutil.ReadFile(filepath.Join(destination, "README"))
+	assert.Equal(t, "readme", string(content))
+}
+
+func TestExtractTarRejectsEscapingSymlink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "extractarchive")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	archive := filepath.Join(dir, "app.tar.gz")
+	createTarGz(t, archive, &tar.Header{Name: "app-1.0/etc", Linkname: "../../../etc", Typeflag: tar.TypeSymlink})
+
+	input := &ExtractArchivePluginInput{SourcePath: archive, DestinationPath: filepath.Join(dir, "app")}
+	output := newOutput()
+	extractArchive(log.NewMockLog(), input, "aws:extractArchive", dir, output)
+	assert.Equal(t, contracts.ResultStatusFailed, output.GetStatus())
+}
+
+func TestExtractTarRejectsChainedSymlinkEscape(t *testing.T) {
+	dir, err := ioutil.TempDir("", "extractarchive")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// each link looks inside the destination on its own, together a/b points to the parent of the destination
+	archive := filepath.Join(dir, "app.tar.gz")
+	createTarGz(t, archive,
+		&tar.Header{Name: "a", Linkname: ".", Typeflag: tar.TypeSymlink},
+		&tar.Header{Name: "a/b", Linkname: "..", Typeflag: tar.TypeSymlink},
+		&tar.Header{Name: "a/b/passwd", Mode: 0644, Typeflag: tar.TypeReg})
+
+	input := &ExtractArchivePluginInput{SourcePath: archive, DestinationPath: filepath.Join(dir, "app")}
+	output := newOutput()
+	extractArchive(log.NewMockLog(), input, "aws:extractArchive", dir, output)
+	assert.Equal(t, contracts.ResultStatusFailed, output.GetStatus())
+	assert.False(t, fileExists(filepath.Join(dir, "passwd")))
+}
+
+func TestExtractTarRejectsWritingThroughExistingSymlink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "extractarchive")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	archive := filepath.Join(dir, "app.tar.gz")
+	createTarGz(t, archive)
+	destination := filepath.Join(dir, "app")
+	outside := filepath.Join(dir, "outside")
+	assert.NoError(t, os.MkdirAll(destination, 0755))
+	assert.NoError(t, os.MkdirAll(outside, 0755))
+	assert.NoError(t, os.Symlink(outside, filepath.Join(destination, "app-1.0")))
+
+	input := &ExtractArchivePluginInput{SourcePath: archive, DestinationPath: destination}
+	output := newOutput()
+	extractArchive(log.NewMockLog(), input, "aws:extractArchive", dir, output)
+	assert.Equal(t, contracts.ResultStatusFailed, output.GetStatus())
+	assert.False(t, fileExists(filepath.Join(outside, "README")))
+}
+
+func TestExtractTarFollowsSymlinkInside(t *testing.T) {
+	dir, err := ioutil.TempDir("", "extractarchive")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	archive := filepath.Join(dir, "app.tar.gz")
+	createTarGz(t, archive,
+		&tar.Header{Name: "app-1.0/lib", Linkname: "bin", Typeflag: tar.TypeSymlink},
+		&tar.Header{Name: "app-1.0/lib/tool", Mode: 0755, Typeflag: tar.TypeReg})
+
+	destination := filepath.Join(dir, "app")
+	input := &ExtractArchivePluginInput{SourcePath: archive, DestinationPath: destination}
+	output := newOutput()
+	extractArchive(log.NewMockLog(), input, "aws:extractArchive", dir, output)
+	assert.Equal(t, contracts.ResultStatusSuccess, output.GetStatus())
+	assert.True(t, fileExists(filepath.Join(destination, "app-1.0", "bin", "tool")))
+}
+
+func TestExtractTarXz(t *testing.T) {
+	xz, err := exec.LookPath("xz")
+	if err != nil {
+		t.Skip("xz is not installed")
+	}
+
+	dir, err := ioutil.TempDir("", "extractarchive")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	tarGz := filepath.Join(dir, "app.tar.gz")
+	createTarGz(t, tarGz)
+	archive := filepath.Join(dir, "app.tar.xz")
+	assert.NoError(t, exec.Command("sh", "-c", "gzip -dc '"+tarGz+"' | '"+xz+"' > '"+archive+"'").Run())
+
+	destination := filepath.Join(dir, "app")
+	input := &ExtractArchivePluginInput{SourcePath: archive, DestinationPath: destination}
+	output := newOutput()
+	extractArchive(log.NewMockLog(), input, "aws:extractArchive", dir, output)
+	assert.Equal(t, contracts.ResultStatusSuccess, output.GetStatus())
+	assert.True(t, fileExists(filepath.Join(destination, "app-1.0", "bin", "app")))
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
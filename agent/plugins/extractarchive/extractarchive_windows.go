@@ -0,0 +1,26 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// +build windows
+
+package extractarchive
+
+import "errors"
+
+// applyOwnership is not supported on windows, file ownership is managed through ACLs there.
+func applyOwnership(paths []string, owner string, group string) error {
+	if owner == "" && group == "" {
+		return nil
+	}
+	return errors.New("Owner and Group are not supported on windows")
+}
@@ -0,0 +1,293 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package extractarchive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+)
+
+// extractor writes archive entries under destination, honoring the strip components and overwrite policy.
+type extractor struct {
+	log             log.T
+	destination     string
+	stripComponents int
+	overwritePolicy string
+	// root is the destination with its symlinks resolved
+	root string
+
+	// extracted holds the paths created, skipped counts the existing files left untouched
+	extracted []string
+	skipped   int
+}
+
+// extract extracts every entry of the archive of the given type.
+func (x *extractor) extract(archiveType string, archivePath string) error {
+	if archiveType == archiveTypeZip {
+		return x.extractZip(archivePath)
+	}
+
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	switch archiveType {
+	case archiveTypeTarGz:
+		gr, err := gzip.NewReader(file)
+		if err != nil {
+			return err
+		}
+		defer gr.Close()
+		return x.extractTar(gr)
+	case archiveTypeTarXz:
+		return x.extractTarXz(file)
+	default:
+		return x.extractTar(file)
+	}
+}
+
+// extractZip extracts the entries of a zip archive.
+func (x *extractor) extractZip(archivePath string) error {
+	r, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	for _, f := range r.File {
+		if err = x.extractZipEntry(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// extractZipEntry extracts one zip entry, the content of a symlink entry is its target.
+func (x *extractor) extractZipEntry(f *zip.File) error {
+	mode := f.Mode()
+	if f.FileInfo().IsDir() {
+		return x.writeEntry(f.Name, mode, "", nil)
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	if mode&os.ModeSymlink != 0 {
+		var target bytes.Buffer
+		if _, err = io.Copy(&target, io.LimitReader(rc, 4096)); err != nil {
+			return err
+		}
+		return x.writeEntry(f.Name, mode, target.String(), nil)
+	}
+	return x.writeEntry(f.Name, mode, "", rc)
+}
+
+// extractTarXz decompresses the archive with xz, the standard library has no xz support.
+func (x *extractor) extractTarXz(archive io.Reader) error {
+	xz, err := lookPath("xz")
+	if err != nil {
+		return errors.New("xz was not found, xz must be installed to extract tar.xz archives")
+	}
+
+	var stderr bytes.Buffer
+	command := exec.Command(xz, "--decompress", "--stdout")
+	command.Stdin = archive
+	command.Stderr = &stderr
+	stdout, err := command.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err = command.Start(); err != nil {
+		return err
+	}
+
+	extractErr := x.extractTar(stdout)
+	// drain the pipe so xz can exit if extraction stopped early
+	io.Copy(ioutil.Discard, stdout)
+	if err = command.Wait(); err != nil {
+		return fmt.Errorf("xz failed: %v %v", err, strings.TrimSpace(stderr.String()))
+	}
+	return extractErr
+}
+
+// extractTar extracts the entries of an uncompressed tar stream.
+func (x *extractor) extractTar(archive io.Reader) error {
+	tr := tar.NewReader(archive)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		mode := hdr.FileInfo().Mode()
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			err = x.writeEntry(hdr.Name, mode, "", nil)
+		case tar.TypeSymlink:
+			err = x.writeEntry(hdr.Name, mode, hdr.Linkname, nil)
+		case tar.TypeReg, tar.TypeRegA:
+			err = x.writeEntry(hdr.Name, mode, "", tr)
+		default:
+			x.log.Debugf("Skipping %v, entries of type %v are not extracted", hdr.Name, string(hdr.Typeflag))
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// writeEntry creates the directory, symlink or file for one archive entry.
+func (x *extractor) writeEntry(name string, mode os.FileMode, linkname string, content io.Reader) error {
+	relative, ok := x.strip(name)
+	if !ok {
+		return nil
+	}
+	target := filepath.Join(x.destination, filepath.FromSlash(relative))
+	if !isUnderDir(target, x.destination) {
+		return fmt.Errorf("%v attempts to place files outside %v", name, x.destination)
+	}
+
+	if mode.IsDir() {
+		if _, err := x.resolveInside(name, target); err != nil {
+			return err
+		}
+		if err := os.MkdirAll(target, appconfig.ReadWriteExecuteAccess); err != nil {
+			return err
+		}
+		x.extracted = append(x.extracted, target)
+		return nil
+	}
+
+	// the parent may go through symlinks extracted earlier, e.g. a -> . followed by a/b -> .. and a/b/file
+	parent, err := x.resolveInside(name, filepath.Dir(target))
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Lstat(target); err == nil {
+		switch x.overwritePolicy {
+		case overwriteNever:
+			x.skipped++
+			return nil
+		case overwriteFail:
+			return fmt.Errorf("%v already exists", target)
+		}
+		if err = os.Remove(target); err != nil {
+			return err
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(target), appconfig.ReadWriteExecuteAccess); err != nil {
+		return err
+	}
+
+	if mode&os.ModeSymlink != 0 {
+		// links may only point inside the destination so that later entries can't be written through them
+		if filepath.IsAbs(linkname) || !isUnderDir(filepath.Join(parent, linkname), x.root) {
+			return fmt.Errorf("%v links to %v outside %v", name, linkname, x.destination)
+		}
+		if err := os.Symlink(linkname, target); err != nil {
+			return err
+		}
+		x.extracted = append(x.extracted, target)
+		return nil
+	}
+
+	file, err := os.OpenFile(target, appconfig.FileFlagsCreateOrTruncate, mode.Perm())
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(file, content); err != nil {
+		file.Close()
+		return err
+	}
+	if err = file.Close(); err != nil {
+		return err
+	}
+	// the umask may have narrowed the mode of the archive entry
+	if err = os.Chmod(target, mode.Perm()); err != nil {
+		return err
+	}
+	x.extracted = append(x.extracted, target)
+	return nil
+}
+
+// resolveInside returns the path with the symlinks of its existing components resolved, it fails if the path
+// resolves outside the destination. The missing components are created below the deepest existing one.
+func (x *extractor) resolveInside(name string, path string) (string, error) {
+	if x.root == "" {
+		root, err := filepath.EvalSymlinks(x.destination)
+		if err != nil {
+			return "", err
+		}
+		x.root = root
+	}
+
+	existing, missing := path, ""
+	for {
+		if _, err := os.Lstat(existing); err == nil {
+			break
+		} else if !os.IsNotExist(err) {
+			return "", err
+		}
+		missing = filepath.Join(filepath.Base(existing), missing)
+		existing = filepath.Dir(existing)
+	}
+	resolved, err := filepath.EvalSymlinks(existing)
+	if err != nil {
+		return "", err
+	}
+	if !isUnderDir(resolved, x.root) {
+		return "", fmt.Errorf("%v attempts to place files outside %v through a symlink", name, x.destination)
+	}
+	return filepath.Join(resolved, missing), nil
+}
+
+// strip removes the leading path components of an entry name, entries without enough components are skipped.
+func (x *extractor) strip(name string) (string, bool) {
+	cleaned := strings.TrimPrefix(path.Clean("/"+strings.Replace(name, "\\", "/", -1)), "/")
+	if cleaned == "" {
+		return "", false
+	}
+	components := strings.Split(cleaned, "/")
+	if len(components) <= x.stripComponents {
+		return "", false
+	}
+	return strings.Join(components[x.stripComponents:], "/"), true
+}
+
+// isUnderDir determines if a given path is in or under a given parent directory (after accounting for path traversal)
+func isUnderDir(childPath, parentDirPath string) bool {
+	return strings.HasPrefix(filepath.Clean(childPath)+string(filepath.Separator), filepath.Clean(parentDirPath)+string(filepath.Separator))
+}
@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/aws/amazon-ssm-agent/agent/appconfig"
 	"github.com/aws/amazon-ssm-agent/agent/context"
@@ -45,12 +46,17 @@ func machineInfoProvider() (name string, err error) {
 type Optimizer interface {
 	UpdateContentHash(inventoryItemName, hash string) (err error)
 	GetContentHash(inventoryItemName string) (hash string)
+	UpdateLastFullUpload(inventoryItemName string, uploadTime time.Time) (err error)
+	GetLastFullUpload(inventoryItemName string) (uploadTime time.Time)
 }
 
 // Impl implements content hash optimizations for inventory plugin
 type Impl struct {
 	log      log.T
 	location string //where the content hash data is persisted in file-systems
+	// fullUploadLocation is where the time the content of each inventory type was last uploaded is persisted
+	fullUploadLocation string
+	fullUploadStore    map[string]string
 }
 
 func NewOptimizerImpl(context context.T) (*Impl, error) {
@@ -76,7 +82,13 @@ func NewOptimizerImplWithLocation(log log.T, rootDir string, fileName string) (*
 		rootDir,
 		fileName)
 
+	optimizer.fullUploadLocation = filepath.Join(appconfig.DefaultDataStorePath,
+		machineID,
+		rootDir,
+		appconfig.InventoryFullUploadFileName)
+
 	contentHashStore = make(map[string]string)
+	optimizer.fullUploadStore = make(map[string]string)
 
 	//read old content hash values from file
 	if fileutil.Exists(optimizer.location) {
@@ -94,6 +106,14 @@ func NewOptimizerImplWithLocation(log log.T, rootDir string, fileName string) (*
 		}
 	}
 
+	//read the times of the last full uploads, the content is uploaded again when they are missing
+	if content, err = fileutil.ReadAllText(optimizer.fullUploadLocation); err == nil {
+		if err = json.Unmarshal([]byte(content), &optimizer.fullUploadStore); err != nil {
+			optimizer.log.Debugf("Unable to read the full upload times of inventory plugin - thereby ignoring any older values")
+			optimizer.fullUploadStore = make(map[string]string)
+		}
+	}
+
 	return &optimizer, nil
 }
 
@@ -127,3 +147,28 @@ func (i *Impl) GetContentHash(inventoryItemName string) (hash string) {
 
 	return
 }
+
+// UpdateLastFullUpload records the content of the given inventory type was uploaded at uploadTime
+func (i *Impl) UpdateLastFullUpload(inventoryItemName string, uploadTime time.Time) (err error) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	i.fullUploadStore[inventoryItemName] = uploadTime.UTC().Format(time.RFC3339)
+
+	//persist the data in file system
+	dataB, _ := json.Marshal(i.fullUploadStore)
+
+	if _, err = fileutil.WriteIntoFileWithPermissions(i.fullUploadLocation, string(dataB), appconfig.ReadWriteAccess); err != nil {
+		err = fmt.Errorf("Unable to update full upload time in file - %v because - %v", i.fullUploadLocation, err.Error())
+	}
+	return
+}
+
+// GetLastFullUpload returns when the content of the given inventory type was last uploaded, the zero time if it never was
+func (i *Impl) GetLastFullUpload(inventoryItemName string) (uploadTime time.Time) {
+	lock.RLock()
+	defer lock.RUnlock()
+
+	uploadTime, _ = time.Parse(time.RFC3339, i.fullUploadStore[inventoryItemName])
+	return
+}
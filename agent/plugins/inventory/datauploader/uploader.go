@@ -29,6 +29,7 @@ import (
 	"github.com/aws/amazon-ssm-agent/agent/platform"
 	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/model"
 	"github.com/aws/amazon-ssm-agent/agent/sdkutil"
+	"github.com/aws/amazon-ssm-agent/agent/tracing"
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/ssm"
@@ -56,6 +57,8 @@ type SSMCaller interface {
 type InventoryUploader struct {
 	ssm       SSMCaller
 	optimizer Optimizer //helps inventory plugin to optimize PutInventory calls
+	// fullUploadInterval is the interval at which the content of unchanged inventory types is uploaded again, 0 disables it
+	fullUploadInterval time.Duration
 }
 
 // NewInventoryUploader creates a new InventoryUploader (which sends data to SSM Inventory)
@@ -87,9 +90,11 @@ func NewInventoryUploader(context context.T) (*InventoryUploader, error) {
 		if appCfg.Agent.Region != "" {
 			cfg.Region = &appCfg.Agent.Region
 		}
+		uploader.fullUploadInterval = time.Duration(appCfg.Ssm.InventoryFullUploadIntervalHours) * time.Hour
 	}
 	sess := session.New(cfg)
 	sess.Handlers.Build.PushBack(request.MakeAddToUserAgentHandler(appCfg.Agent.Name, appCfg.Agent.Version))
+	tracing.TraceRequests(&sess.Handlers)
 
 	uploader.ssm = ssm.New(sess)
 
@@ -158,12 +163,28 @@ func getRandomBackOffTime(context context.T, instanceID string) (sleepTime int)
 func (u *InventoryUploader) updateContentHash(context context.T, items []*ssm.InventoryItem) {
 	log := context.Log()
 	log.Debugf("Updating cache")
+	uploadTime := time.Now()
 	for _, item := range items {
 		if err := u.optimizer.UpdateContentHash(*item.TypeName, *item.ContentHash); err != nil {
 			err = fmt.Errorf("failed to update content hash cache because of - %v", err.Error())
 			log.Error(err.Error())
 		}
+		// items carrying their content are full uploads, the others carry their content hash only
+		if item.Content != nil {
+			if err := u.optimizer.UpdateLastFullUpload(*item.TypeName, uploadTime); err != nil {
+				log.Errorf("failed to update full upload time because of - %v", err.Error())
+			}
+		}
+	}
+}
+
+// isFullUploadDue returns true when the content of the inventory type must be uploaded again even though it is unchanged,
+// reconciling the inventory of the instance with Systems Manager
+func (u *InventoryUploader) isFullUploadDue(itemName string) bool {
+	if u.fullUploadInterval <= 0 {
+		return false
 	}
+	return time.Since(u.optimizer.GetLastFullUpload(itemName)) >= u.fullUploadInterval
 }
 
 func calculateCheckSum(data []byte) (checkSum string) {
@@ -223,7 +244,12 @@ func (u *InventoryUploader) ConvertToSsmInventoryItems(context context.T, items
 
 		log.Debugf("old hash - %v, new hash - %v for the inventory type - %v", oldHash, newHash, itemName)
 
-		if newHash == oldHash {
+		if newHash == oldHash && u.isFullUploadDue(itemName) {
+			log.Infof("Inventory data for %v is same as before but its full upload is due - sending the content for reconciliation", itemName)
+
+			optimizedInventoryItems = append(optimizedInventoryItems, nonOptimizedItem)
+
+		} else if newHash == oldHash {
 			log.Debugf("Inventory data for %v is same as before - we can just send content hash", itemName)
 
 			//set the inventory item accordingly
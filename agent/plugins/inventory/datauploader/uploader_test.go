@@ -18,6 +18,7 @@ import (
 	"encoding/json"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/aws/amazon-ssm-agent/agent/context"
 	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/model"
@@ -32,6 +33,8 @@ func MockInventoryUploader() *InventoryUploader {
 	optimizer := NewMockDefault()
 	optimizer.On("GetContentHash", mock.AnythingOfType("string")).Return("RandomInventoryItem")
 	optimizer.On("UpdateContentHash", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(nil)
+	optimizer.On("GetLastFullUpload", mock.AnythingOfType("string")).Return(time.Now())
+	optimizer.On("UpdateLastFullUpload", mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).Return(nil)
 
 	uploader.optimizer = optimizer
 	return &uploader
@@ -105,6 +108,36 @@ func TestConvertToSsmInventoryItems(t *testing.T) {
 	assert.NotNil(t, err, "Error should be thrown for unsupported Item.Content")
 }
 
+func TestConvertToSsmInventoryItemsReconcilesUnchangedItems(t *testing.T) {
+	c := context.NewMockDefault()
+	items := FakeInventoryItems(1)
+	dataB, _ := json.Marshal(items[0].Content)
+	hash := calculateCheckSum(dataB)
+
+	optimizer := NewMockDefault()
+	optimizer.On("GetContentHash", "RandomInventoryItem").Return(hash)
+	u := &InventoryUploader{optimizer: optimizer, fullUploadInterval: 24 * time.Hour}
+
+	// the unchanged item carries its content hash only while its full upload is recent
+	optimizer.On("GetLastFullUpload", "RandomInventoryItem").Return(time.Now().Add(-time.Hour)).Once()
+	optimizedItems, _, err := u.ConvertToSsmInventoryItems(c, items)
+	assert.Nil(t, err)
+	assert.Nil(t, optimizedItems[0].Content)
+	assert.Equal(t, hash, *optimizedItems[0].ContentHash)
+
+	// the unchanged item carries its content again once its full upload is due
+	optimizer.On("GetLastFullUpload", "RandomInventoryItem").Return(time.Now().Add(-25 * time.Hour)).Once()
+	optimizedItems, _, err = u.ConvertToSsmInventoryItems(c, items)
+	assert.Nil(t, err)
+	assert.NotNil(t, optimizedItems[0].Content)
+
+	// reconciliation is disabled without interval
+	u.fullUploadInterval = 0
+	optimizedItems, _, err = u.ConvertToSsmInventoryItems(c, items)
+	assert.Nil(t, err)
+	assert.Nil(t, optimizedItems[0].Content)
+}
+
 func TestConvertExcludedAndEmptyToSsmInventoryItems(t *testing.T) {
 
 	var items []model.Item
@@ -220,6 +253,7 @@ func testSendData(t *testing.T, putInventorySucceeds bool) {
 	if putInventorySucceeds {
 		for _, item := range inventoryItems {
 			mockOptimizer.On("UpdateContentHash", *item.TypeName, hash).Return(nil)
+			mockOptimizer.On("UpdateLastFullUpload", *item.TypeName, mock.AnythingOfType("time.Time")).Return(nil)
 		}
 	}
 
@@ -0,0 +1,112 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// +build darwin freebsd linux netbsd openbsd
+
+// Package application contains application gatherer.
+package application
+
+import (
+	"bufio"
+	"os"
+	"strings"
+
+	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/model"
+)
+
+// apkInstalledDatabase is the database of the packages installed by apk on Alpine, it is assigned to a variable to allow
+// unit tests to override it
+var apkInstalledDatabase = "/lib/apk/db/installed"
+
+// apkApplicationType is reported as the application type of apk packages
+const apkApplicationType = "apk"
+
+// getApkApplicationData reads the packages installed by apk, the database is only found on Alpine and other musl systems.
+func getApkApplicationData() (data []model.ApplicationData, found bool, err error) {
+	file, err := os.Open(apkInstalledDatabase)
+	if err != nil {
+		return nil, false, nil
+	}
+	defer file.Close()
+
+	data, err = parseApkInstalledDatabase(bufio.NewScanner(file))
+	return data, true, err
+}
+
+// parseApkInstalledDatabase converts the apk database to application data.
+func parseApkInstalledDatabase(scanner *bufio.Scanner) (data []model.ApplicationData, err error) {
+
+	/*
+		Each package is a block of single letter fields, blocks are separated by an empty line:
+
+		P:musl
+		V:1.1.24-r2
+		A:x86_64
+		T:the musl c library (libc) implementation
+		U:https://musl.libc.org/
+		o:musl
+		m:Timo Teräs <timo.teras@iki.fi>
+		t:1584790550
+		F:lib
+		R:libc.musl-x86_64.so.1
+
+		The version holds the package release after -r, the origin is the source package the package was built from.
+		apk doesn't record when packages were installed, so the install time is left out.
+	*/
+
+	var app model.ApplicationData
+	add := func() {
+		if app.Name != "" {
+			app.ApplicationType = apkApplicationType
+			app.CompType = componentType(app.Name)
+			data = append(data, app)
+		}
+		app = model.ApplicationData{}
+	}
+
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			add()
+			continue
+		}
+		if len(line) < 2 || line[1] != ':' {
+			continue
+		}
+
+		value := line[2:]
+		switch line[0] {
+		case 'P':
+			app.Name = value
+		case 'V':
+			app.Version = value
+			if i := strings.LastIndex(value, "-r"); i > 0 {
+				app.Version, app.Release = value[:i], value[i+1:]
+			}
+		case 'A':
+			app.Architecture = model.FormatArchitecture(value)
+		case 'T':
+			app.Summary = value
+		case 'U':
+			app.URL = value
+		case 'o':
+			app.PackageId = value
+		case 'm':
+			app.Publisher = value
+		}
+	}
+	add()
+
+	return data, scanner.Err()
+}
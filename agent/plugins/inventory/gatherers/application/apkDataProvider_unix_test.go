@@ -0,0 +1,88 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// +build darwin freebsd linux netbsd openbsd
+
+// Package application contains application gatherer.
+package application
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/model"
+	"github.com/stretchr/testify/assert"
+)
+
+const sampleApkDatabase = `C:Q1tsNsuwGYMOqv4ZprRzTMkGFQxuE=
+P:musl
+V:1.1.24-r2
+A:x86_64
+S:377201
+I:614400
+T:the musl c library (libc) implementation
+U:https://musl.libc.org/
+L:MIT
+o:musl
+m:Timo Teräs <timo.teras@iki.fi>
+t:1584790550
+c:2dd23b5c5c8d4d3d9b5b0c4c3d1c0f1c0a1b2c3d
+F:lib
+R:libc.musl-x86_64.so.1
+
+P:amazon-ssm-agent
+V:2.3.0
+A:noarch
+o:amazon-ssm-agent
+`
+
+func TestGetApkApplicationData(t *testing.T) {
+	dir, err := ioutil.TempDir("", "apk")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	defer func(original string) { apkInstalledDatabase = original }(apkInstalledDatabase)
+
+	apkInstalledDatabase = filepath.Join(dir, "installed")
+	_, found, err := getApkApplicationData()
+	assert.False(t, found)
+	assert.NoError(t, err)
+
+	assert.NoError(t, ioutil.WriteFile(apkInstalledDatabase, []byte(sampleApkDatabase), 0600))
+	data, found, err := getApkApplicationData()
+	assert.True(t, found)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.ApplicationData{
+		{
+			Name:            "musl",
+			Publisher:       "Timo Teräs <timo.teras@iki.fi>",
+			Version:         "1.1.24",
+			Release:         "r2",
+			ApplicationType: apkApplicationType,
+			Architecture:    model.FormatArchitecture("x86_64"),
+			URL:             "https://musl.libc.org/",
+			Summary:         "the musl c library (libc) implementation",
+			PackageId:       "musl",
+			CompType:        componentType("musl"),
+		},
+		{
+			Name:            "amazon-ssm-agent",
+			Version:         "2.3.0",
+			ApplicationType: apkApplicationType,
+			Architecture:    model.FormatArchitecture("noarch"),
+			PackageId:       "amazon-ssm-agent",
+			CompType:        componentType("amazon-ssm-agent"),
+		},
+	}, data)
+}
@@ -72,12 +72,29 @@ func platformInfoProvider(log log.T) (name string, err error) {
 	return platform.PlatformName(log)
 }
 
-// collectPlatformDependentApplicationData collects all application data from the system using rpm or dpkg query.
+// collectPlatformDependentApplicationData collects all application data from the system using the apk database,
+// rpm or dpkg query, along with the snaps and Flatpak applications.
 func collectPlatformDependentApplicationData(context context.T) (appData []model.ApplicationData) {
+	appData = collectPackageManagerApplicationData(context)
+	return append(appData, collectSandboxedApplicationData(context.Log())...)
+}
+
+// collectPackageManagerApplicationData collects the packages of the package manager of the system.
+func collectPackageManagerApplicationData(context context.T) (appData []model.ApplicationData) {
 
 	var err error
+	var found bool
 	log := context.Log()
 
+	// the apk database is only found on Alpine, which has neither dpkg nor rpm
+	if appData, found, err = getApkApplicationData(); found {
+		if err != nil {
+			log.Errorf("Unable to read the apk database %v - %v", apkInstalledDatabase, err)
+		}
+		log.Infof("Number of apk packages detected - %v", len(appData))
+		return
+	}
+
 	args := []string{dpkgArgsToGetAllApplications, dpkgQueryFormat}
 	cmd := dpkgCmd
 
@@ -184,6 +184,7 @@ func TestGetApplicationData(t *testing.T) {
 }
 
 func TestCollectApplicationData(t *testing.T) {
+	defer stubSandboxedApplications(nil, nil)()
 	mockContext := context.NewMockDefault()
 
 	// both dpkg and rpm return result without error
@@ -203,6 +204,7 @@ func TestCollectApplicationData(t *testing.T) {
 }
 
 func TestCollectAndMergePackages(t *testing.T) {
+	defer stubSandboxedApplications(nil, nil)()
 	mockContext := context.NewMockDefault()
 	packageRepository = MockPackageRepository([]model.ApplicationData{
 		{Name: "amazon-ssm-agent", Version: "1.2.0.0-1", Architecture: model.Arch64Bit, CompType: model.AWSComponent},
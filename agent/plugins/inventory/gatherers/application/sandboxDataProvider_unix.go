@@ -0,0 +1,190 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// +build darwin freebsd linux netbsd openbsd
+
+// Package application contains application gatherer.
+package application
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/model"
+)
+
+const (
+	// snapdRequestTimeout is the time given to snapd to list the installed snaps
+	snapdRequestTimeout = 30 * time.Second
+	snapApplicationType = "snap"
+
+	flatpakCmd                = "flatpak"
+	flatpakApplicationType    = "flatpak"
+	flatpakArgsToListColumns  = "--columns=application,version,branch,active,origin,arch,name"
+	flatpakArgToListInstalled = "list"
+)
+
+var (
+	snapdSocket = "/run/snapd.socket"
+	// the functions are assigned to variables to allow unit tests to override them
+	querySnapd   = snapdQuery
+	queryFlatpak = flatpakQuery
+)
+
+// snapdSnaps is the part of the installed snaps listed by the snapd REST API
+type snapdSnaps struct {
+	Result []struct {
+		Name            string `json:"name"`
+		Version         string `json:"version"`
+		Revision        string `json:"revision"`
+		Channel         string `json:"channel"`
+		TrackingChannel string `json:"tracking-channel"`
+		Summary         string `json:"summary"`
+		InstallDate     string `json:"install-date"`
+		Website         string `json:"website"`
+		Publisher       struct {
+			Username    string `json:"username"`
+			DisplayName string `json:"display-name"`
+		} `json:"publisher"`
+	} `json:"result"`
+}
+
+// collectSandboxedApplicationData collects the snaps and the Flatpak applications and runtimes, which are installed aside
+// of the package manager of the system. The channel the snap tracks or the branch of the Flatpak is part of the
+// application type, such as snap/latest/stable, the revision or the active commit is the release.
+func collectSandboxedApplicationData(log log.T) (appData []model.ApplicationData) {
+	if snaps, err := getSnapApplicationData(); err != nil {
+		log.Errorf("Unable to list the installed snaps - %v", err)
+	} else if len(snaps) > 0 {
+		log.Infof("Number of snaps detected - %v", len(snaps))
+		appData = append(appData, snaps...)
+	}
+
+	if flatpaks, err := getFlatpakApplicationData(); err != nil {
+		log.Errorf("Unable to list the installed Flatpak applications - %v", err)
+	} else if len(flatpaks) > 0 {
+		log.Infof("Number of Flatpak applications and runtimes detected - %v", len(flatpaks))
+		appData = append(appData, flatpaks...)
+	}
+	return
+}
+
+// getSnapApplicationData lists the snaps installed by snapd, no snap is listed without snapd
+func getSnapApplicationData() (data []model.ApplicationData, err error) {
+	if _, err = os.Stat(snapdSocket); err != nil {
+		return nil, nil
+	}
+
+	var snaps snapdSnaps
+	if err = querySnapd("/v2/snaps", &snaps); err != nil {
+		return nil, err
+	}
+
+	for _, snap := range snaps.Result {
+		channel := snap.TrackingChannel
+		if channel == "" {
+			channel = snap.Channel
+		}
+		app := model.ApplicationData{
+			Name:            snap.Name,
+			Publisher:       snap.Publisher.DisplayName,
+			Version:         snap.Version,
+			Release:         snap.Revision,
+			ApplicationType: snapApplicationType,
+			URL:             snap.Website,
+			Summary:         snap.Summary,
+			PackageId:       fmt.Sprintf("%v_%v.snap", snap.Name, snap.Revision),
+			CompType:        componentType(snap.Name),
+		}
+		if app.Publisher == "" {
+			app.Publisher = snap.Publisher.Username
+		}
+		if channel != "" {
+			app.ApplicationType = snapApplicationType + "/" + channel
+		}
+		if installDate, err := time.Parse(time.RFC3339, snap.InstallDate); err == nil {
+			app.InstalledTime = installDate.UTC().Format(time.RFC3339)
+		}
+		data = append(data, app)
+	}
+	return data, nil
+}
+
+// getFlatpakApplicationData lists the Flatpak applications and runtimes, nothing is listed without flatpak
+func getFlatpakApplicationData() (data []model.ApplicationData, err error) {
+	output, found, err := queryFlatpak()
+	if !found || err != nil {
+		return nil, err
+	}
+
+	// every line lists the tab separated columns of an installed ref:
+	// org.gimp.GIMP	2.10.18	stable	4c1e2a1b5f2d	flathub	x86_64	GNU Image Manipulation Program
+	for _, line := range strings.Split(string(output), "\n") {
+		columns := strings.Split(strings.TrimRight(line, "\r"), "\t")
+		if len(columns) < 7 || columns[0] == "" {
+			continue
+		}
+		application, version, branch, active, origin, arch, name := columns[0], columns[1], columns[2], columns[3], columns[4], columns[5], columns[6]
+		data = append(data, model.ApplicationData{
+			Name:            application,
+			Publisher:       origin,
+			Version:         version,
+			Release:         active,
+			ApplicationType: flatpakApplicationType + "/" + branch,
+			Architecture:    model.FormatArchitecture(arch),
+			Summary:         name,
+			PackageId:       fmt.Sprintf("%v/%v/%v", application, arch, branch),
+			CompType:        componentType(application),
+		})
+	}
+	return data, nil
+}
+
+// snapdQuery sends the GET request of the snapd REST API to the snapd socket and decodes the response in v
+func snapdQuery(path string, v interface{}) error {
+	client := &http.Client{
+		Timeout: snapdRequestTimeout,
+		Transport: &http.Transport{
+			Dial: func(network, addr string) (net.Conn, error) {
+				return net.Dial("unix", snapdSocket)
+			},
+		},
+	}
+	resp, err := client.Get("http://snapd" + path)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("snapd replied %v to %v", resp.Status, path)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// flatpakQuery lists the installed refs with flatpak, found is false when flatpak isn't installed
+func flatpakQuery() (output []byte, found bool, err error) {
+	if _, err = exec.LookPath(flatpakCmd); err != nil {
+		return nil, false, nil
+	}
+	if output, err = exec.Command(flatpakCmd, flatpakArgToListInstalled, flatpakArgsToListColumns).Output(); err != nil {
+		return nil, true, fmt.Errorf("%v %v failed - %v", flatpakCmd, flatpakArgToListInstalled, err)
+	}
+	return output, true, nil
+}
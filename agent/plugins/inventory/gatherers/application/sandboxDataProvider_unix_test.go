@@ -0,0 +1,114 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// +build darwin freebsd linux netbsd openbsd
+
+// Package application contains application gatherer.
+package application
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/model"
+	"github.com/stretchr/testify/assert"
+)
+
+const sampleSnapdSnaps = `{"type":"sync","status-code":200,"result":[
+{"name":"core18","version":"20200427","revision":"1754","channel":"stable","summary":"Runtime environment based on Ubuntu 18.04",
+"install-date":"2020-05-14T10:11:12.5+02:00","publisher":{"username":"canonical","display-name":"Canonical"}},
+{"name":"lxd","version":"4.0.1","revision":"14890","channel":"4.0/stable","tracking-channel":"4.0/stable/ubuntu-20.04",
+"website":"https://linuxcontainers.org","publisher":{"username":"canonical"}}]}`
+
+const sampleFlatpakList = "org.gimp.GIMP\t2.10.18\tstable\t4c1e2a1b5f2d\tflathub\tx86_64\tGNU Image Manipulation Program\n" +
+	"org.gnome.Platform\t\t3.36\t9a8b7c6d5e4f\tflathub\tx86_64\tGNOME Application Platform version 3.36\n"
+
+// stubSandboxedApplications makes the snapd and flatpak queries return the given outputs, nil is not installed.
+// It returns the function restoring the queries.
+func stubSandboxedApplications(snaps []byte, flatpaks []byte) func() {
+	originalSocket, originalSnapd, originalFlatpak := snapdSocket, querySnapd, queryFlatpak
+	snapdSocket = ""
+	if snaps != nil {
+		// any existing file stands in for the snapd socket
+		snapdSocket = os.Args[0]
+	}
+	querySnapd = func(path string, v interface{}) error {
+		return json.Unmarshal(snaps, v)
+	}
+	queryFlatpak = func() ([]byte, bool, error) {
+		return flatpaks, flatpaks != nil, nil
+	}
+	return func() {
+		snapdSocket, querySnapd, queryFlatpak = originalSocket, originalSnapd, originalFlatpak
+	}
+}
+
+func TestCollectSandboxedApplicationData(t *testing.T) {
+	defer stubSandboxedApplications([]byte(sampleSnapdSnaps), []byte(sampleFlatpakList))()
+
+	data := collectSandboxedApplicationData(log.NewMockLog())
+	assert.Equal(t, []model.ApplicationData{
+		{
+			Name:            "core18",
+			Publisher:       "Canonical",
+			Version:         "20200427",
+			Release:         "1754",
+			InstalledTime:   "2020-05-14T08:11:12Z",
+			ApplicationType: "snap/stable",
+			Summary:         "Runtime environment based on Ubuntu 18.04",
+			PackageId:       "core18_1754.snap",
+		},
+		{
+			Name:            "lxd",
+			Publisher:       "canonical",
+			Version:         "4.0.1",
+			Release:         "14890",
+			ApplicationType: "snap/4.0/stable/ubuntu-20.04",
+			URL:             "https://linuxcontainers.org",
+			PackageId:       "lxd_14890.snap",
+		},
+		{
+			Name:            "org.gimp.GIMP",
+			Publisher:       "flathub",
+			Version:         "2.10.18",
+			Release:         "4c1e2a1b5f2d",
+			ApplicationType: "flatpak/stable",
+			Architecture:    "x86_64",
+			Summary:         "GNU Image Manipulation Program",
+			PackageId:       "org.gimp.GIMP/x86_64/stable",
+		},
+		{
+			Name:            "org.gnome.Platform",
+			Publisher:       "flathub",
+			Release:         "9a8b7c6d5e4f",
+			ApplicationType: "flatpak/3.36",
+			Architecture:    "x86_64",
+			Summary:         "GNOME Application Platform version 3.36",
+			PackageId:       "org.gnome.Platform/x86_64/3.36",
+		},
+	}, data)
+}
+
+func TestCollectSandboxedApplicationDataWithoutSnapdAndFlatpak(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapd")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	defer stubSandboxedApplications(nil, nil)()
+	snapdSocket = filepath.Join(dir, "snapd.socket")
+
+	assert.Empty(t, collectSandboxedApplicationData(log.NewMockLog()))
+}
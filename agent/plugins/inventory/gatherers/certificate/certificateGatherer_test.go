@@ -0,0 +1,56 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package certificate contains a gatherer for the certificates of the configured files, directories and certificate stores
+package certificate
+
+import (
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGatherer(t *testing.T) {
+	defer func() { collectData = collectCertificateData }()
+	config := appconfig.SsmagentConfig{}
+	config.Ssm.InventoryCertificateLocations = []string{"/etc/pki/tls/certs"}
+	contextMock := new(context.Mock)
+	contextMock.On("Log").Return(log.NewMockLog())
+	contextMock.On("AppConfig").Return(config)
+	gatherer := Gatherer(contextMock)
+
+	var collectedLocations []string
+	collectData = func(context context.T, locations []string) []model.CertificateData {
+		collectedLocations = locations
+		return []model.CertificateData{{Subject: "CN=www.example.com"}}
+	}
+	items, err := gatherer.Run(contextMock, model.Config{})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(items))
+	assert.Equal(t, TypeName, items[0].Name)
+	assert.Equal(t, []model.CertificateData{{Subject: "CN=www.example.com"}}, items[0].Content)
+	assert.Equal(t, []string{"/etc/pki/tls/certs"}, collectedLocations)
+}
+
+func TestGathererWithoutLocation(t *testing.T) {
+	contextMock := context.NewMockDefault()
+	gatherer := Gatherer(contextMock)
+
+	items, err := gatherer.Run(contextMock, model.Config{})
+	assert.Nil(t, err)
+	assert.Empty(t, items)
+}
@@ -0,0 +1,141 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package certificate
+
+import (
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/model"
+)
+
+// storePrefix starts the locations of the Windows certificate stores
+const storePrefix = `Cert:\`
+
+// collectCertificateData collects the certificates of every location, a location failing to be read is skipped
+func collectCertificateData(context context.T, locations []string) (data []model.CertificateData) {
+	log := context.Log()
+	for _, location := range locations {
+		var certificates map[string][]*x509.Certificate
+		var err error
+		if strings.HasPrefix(strings.ToLower(location), strings.ToLower(storePrefix)) {
+			certificates, err = storeCertificates(log, location)
+		} else {
+			certificates, err = fileCertificates(location)
+		}
+		if err != nil {
+			log.Errorf("Unable to read the certificates of %v - %v", location, err)
+			continue
+		}
+		// the paths are sorted so the content hash of unchanged certificates doesn't change
+		var paths []string
+		for path := range certificates {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		for _, path := range paths {
+			for _, certificate := range certificates[path] {
+				data = append(data, convertToCertificateData(certificate, path))
+			}
+		}
+	}
+	log.Infof("%v certificates found", len(data))
+	return
+}
+
+// fileCertificates returns the certificates of the file, or of the files of the directory, by file path.
+// The files which aren't certificates are skipped.
+func fileCertificates(location string) (certificates map[string][]*x509.Certificate, err error) {
+	var info os.FileInfo
+	if info, err = os.Stat(location); err != nil {
+		return
+	}
+
+	paths := []string{location}
+	if info.IsDir() {
+		var files []os.FileInfo
+		if files, err = ioutil.ReadDir(location); err != nil {
+			return
+		}
+		paths = nil
+		for _, file := range files {
+			if file.Mode().IsRegular() {
+				paths = append(paths, filepath.Join(location, file.Name()))
+			}
+		}
+	}
+
+	certificates = map[string][]*x509.Certificate{}
+	for _, path := range paths {
+		content, readErr := ioutil.ReadFile(path)
+		if readErr != nil {
+			continue
+		}
+		if parsed := parseCertificates(content); len(parsed) > 0 {
+			certificates[path] = parsed
+		}
+	}
+	return
+}
+
+// parseCertificates returns the certificates of PEM content, such as a bundle, or of DER content
+func parseCertificates(content []byte) (certificates []*x509.Certificate) {
+	if !strings.Contains(string(content), "-----BEGIN") {
+		certificates, _ = x509.ParseCertificates(content)
+		return
+	}
+
+	for block, rest := pem.Decode(content); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		if certificate, err := x509.ParseCertificate(block.Bytes); err == nil {
+			certificates = append(certificates, certificate)
+		}
+	}
+	return
+}
+
+// convertToCertificateData returns the attributes of the certificate, the thumbprint is the SHA-1 hash shown by Windows
+func convertToCertificateData(certificate *x509.Certificate, location string) model.CertificateData {
+	var names []string
+	names = append(names, certificate.DNSNames...)
+	for _, ip := range certificate.IPAddresses {
+		names = append(names, ip.String())
+	}
+	names = append(names, certificate.EmailAddresses...)
+	for _, uri := range certificate.URIs {
+		names = append(names, uri.String())
+	}
+
+	return model.CertificateData{
+		Subject:                 certificate.Subject.String(),
+		Issuer:                  certificate.Issuer.String(),
+		SerialNumber:            fmt.Sprintf("%X", certificate.SerialNumber),
+		SubjectAlternativeNames: strings.Join(names, ", "),
+		NotBefore:               certificate.NotBefore.UTC().Format(time.RFC3339),
+		NotAfter:                certificate.NotAfter.UTC().Format(time.RFC3339),
+		Thumbprint:              fmt.Sprintf("%X", sha1.Sum(certificate.Raw)),
+		Location:                location,
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package certificate
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/stretchr/testify/assert"
+)
+
+var notBefore = time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+func createCertificate(t *testing.T, commonName string, serialNumber int64, dnsNames ...string) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serialNumber),
+		Subject:      pkix.Name{CommonName: commonName, Organization: []string{"Example"}},
+		NotBefore:    notBefore,
+		NotAfter:     notBefore.AddDate(1, 0, 0),
+		DNSNames:     dnsNames,
+		IPAddresses:  []net.IP{net.ParseIP("10.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.Nil(t, err)
+	return der
+}
+
+func TestCollectCertificateData(t *testing.T) {
+	dir, err := ioutil.TempDir("", "certificates")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	serverDER := createCertificate(t, "www.example.com", 255, "www.example.com", "example.com")
+	caDER := createCertificate(t, "Example CA", 1)
+	bundle := append(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: serverDER}), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: []byte("key")})...)
+	bundle = append(bundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER})...)
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "server.pem"), bundle, 0600))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "ca.der"), caDER, 0600))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "README"), []byte("not a certificate"), 0600))
+	assert.Nil(t, os.Mkdir(filepath.Join(dir, "private"), 0700))
+
+	data := collectCertificateData(context.NewMockDefault(), []string{
+		dir,
+		filepath.Join(dir, "ca.der"),
+		filepath.Join(dir, "missing.pem"),
+	})
+
+	assert.Equal(t, 4, len(data))
+	assert.Equal(t, filepath.Join(dir, "ca.der"), data[0].Location)
+	assert.Equal(t, filepath.Join(dir, "server.pem"), data[1].Location)
+	assert.Equal(t, filepath.Join(dir, "server.pem"), data[2].Location)
+	assert.Equal(t, filepath.Join(dir, "ca.der"), data[3].Location)
+
+	server := data[1]
+	assert.Equal(t, "CN=www.example.com,O=Example", server.Subject)
+	assert.Equal(t, "CN=www.example.com,O=Example", server.Issuer)
+	assert.Equal(t, "FF", server.SerialNumber)
+	assert.Equal(t, "www.example.com, example.com, 10.0.0.1", server.SubjectAlternativeNames)
+	assert.Equal(t, "2026-01-01T00:00:00Z", server.NotBefore)
+	assert.Equal(t, "2027-01-01T00:00:00Z", server.NotAfter)
+	assert.Len(t, server.Thumbprint, 40)
+	assert.Equal(t, "CN=Example CA,O=Example", data[2].Subject)
+	assert.Equal(t, data[0].Thumbprint, data[2].Thumbprint)
+}
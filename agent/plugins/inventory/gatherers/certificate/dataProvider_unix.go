@@ -0,0 +1,28 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// +build darwin freebsd linux netbsd openbsd
+
+package certificate
+
+import (
+	"crypto/x509"
+	"fmt"
+
+	"github.com/aws/amazon-ssm-agent/agent/log"
+)
+
+// storeCertificates fails since the certificate stores only exist on Windows
+func storeCertificates(log log.T, location string) (map[string][]*x509.Certificate, error) {
+	return nil, fmt.Errorf("certificate stores are only supported on Windows")
+}
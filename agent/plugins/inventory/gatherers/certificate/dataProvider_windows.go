@@ -0,0 +1,64 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// +build windows
+
+package certificate
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/aws/amazon-ssm-agent/agent/log"
+)
+
+const (
+	powershellCmd = "powershell"
+	// storeQueryCmd prints the certificates of the store, one base64 encoded DER certificate per line
+	storeQueryCmd = `Get-ChildItem -Path '%v' | Where-Object { $_ -is [System.Security.Cryptography.X509Certificates.X509Certificate2] } | ForEach-Object { [Convert]::ToBase64String($_.RawData) }`
+)
+
+// cmdExecutor is assigned to a variable to allow unit tests to override it
+var cmdExecutor = executeCommand
+
+func executeCommand(command string, args ...string) ([]byte, error) {
+	return exec.Command(command, args...).Output()
+}
+
+// storeCertificates returns the certificates of the certificate store, such as Cert:\LocalMachine\My
+func storeCertificates(log log.T, location string) (certificates map[string][]*x509.Certificate, err error) {
+	var output []byte
+	if output, err = cmdExecutor(powershellCmd, fmt.Sprintf(storeQueryCmd, strings.Replace(location, "'", "''", -1))); err != nil {
+		return
+	}
+
+	certificates = map[string][]*x509.Certificate{}
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(make([]byte, bufio.MaxScanTokenSize), 1024*1024)
+	for scanner.Scan() {
+		der, decodeErr := base64.StdEncoding.DecodeString(strings.TrimSpace(scanner.Text()))
+		if decodeErr != nil {
+			log.Debugf("Skipping the output line of certificate store %v - %v", location, decodeErr)
+			continue
+		}
+		if certificate, parseErr := x509.ParseCertificate(der); parseErr == nil {
+			certificates[location] = append(certificates[location], certificate)
+		}
+	}
+	return
+}
@@ -0,0 +1,82 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package container contains a gatherer for the containers and images of the local container runtime
+package container
+
+import (
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/model"
+)
+
+const (
+	// GathererName captures name of container gatherer
+	GathererName = "Container"
+	// ContainerTypeName represents the custom inventory type of the containers
+	ContainerTypeName = "Custom:Container"
+	// ImageTypeName represents the custom inventory type of the container images
+	ImageTypeName = "Custom:ContainerImage"
+	// SchemaVersionOfContainerGatherer represents schema version of container gatherer
+	SchemaVersionOfContainerGatherer = "1.0"
+)
+
+// T represents container gatherer
+type T struct{}
+
+// Gatherer returns new container gatherer
+func Gatherer(context context.T) *T {
+	return new(T)
+}
+
+var collectData = collectContainerData
+
+// Name returns name of container gatherer
+func (t *T) Name() string {
+	return GathererName
+}
+
+// Run executes container gatherer and returns the containers and the images of the local Docker engine or containerd,
+// an instance without container runtime reports no item.
+func (t *T) Run(context context.T, configuration model.Config) (items []model.Item, err error) {
+	//CaptureTime must comply with format: 2016-07-30T18:15:37Z to comply with regex at SSM.
+	captureTime := time.Now().UTC().Format(time.RFC3339)
+
+	var containers []model.ContainerData
+	var images []model.ContainerImageData
+	var found bool
+	if containers, images, found, err = collectData(context); err != nil || !found {
+		return
+	}
+
+	items = append(items, model.Item{
+		Name:          ContainerTypeName,
+		SchemaVersion: SchemaVersionOfContainerGatherer,
+		Content:       containers,
+		CaptureTime:   captureTime,
+	}, model.Item{
+		Name:          ImageTypeName,
+		SchemaVersion: SchemaVersionOfContainerGatherer,
+		Content:       images,
+		CaptureTime:   captureTime,
+	})
+	return
+}
+
+// RequestStop stops the execution of container gatherer.
+func (t *T) RequestStop(stopType contracts.StopType) error {
+	var err error
+	return err
+}
@@ -0,0 +1,46 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package container contains a gatherer for the containers and images of the local container runtime
+package container
+
+import (
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGatherer(t *testing.T) {
+	defer func() { collectData = collectContainerData }()
+	contextMock := context.NewMockDefault()
+	gatherer := Gatherer(contextMock)
+
+	collectData = func(context context.T) ([]model.ContainerData, []model.ContainerImageData, bool, error) {
+		return []model.ContainerData{{ContainerId: "8dfafdbc3a40"}}, []model.ContainerImageData{{ImageId: "sha256:06144b2878"}}, true, nil
+	}
+	items, err := gatherer.Run(contextMock, model.Config{})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(items))
+	assert.Equal(t, ContainerTypeName, items[0].Name)
+	assert.Equal(t, []model.ContainerData{{ContainerId: "8dfafdbc3a40"}}, items[0].Content)
+	assert.Equal(t, ImageTypeName, items[1].Name)
+
+	collectData = func(context context.T) ([]model.ContainerData, []model.ContainerImageData, bool, error) {
+		return nil, nil, false, nil
+	}
+	items, err = gatherer.Run(contextMock, model.Config{})
+	assert.Nil(t, err)
+	assert.Empty(t, items)
+}
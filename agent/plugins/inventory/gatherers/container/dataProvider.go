@@ -0,0 +1,289 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package container contains a gatherer for the containers and images of the local container runtime
+package container
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/model"
+)
+
+const (
+	// requestTimeout is the time given to the container runtime to list the containers or the images
+	requestTimeout = 30 * time.Second
+	crictlCmd      = "crictl"
+)
+
+var (
+	dockerSocket     = "/var/run/docker.sock"
+	containerdSocket = "/run/containerd/containerd.sock"
+	// the functions are assigned to variables to allow unit tests to override them
+	socketExists   = fileExists
+	queryDocker    = dockerQuery
+	queryContainer = crictlQuery
+)
+
+// dockerContainer is the part of a container listed by the Docker engine API
+type dockerContainer struct {
+	Id      string
+	Names   []string
+	Image   string
+	ImageID string
+	State   string
+	Ports   []struct {
+		IP          string
+		PrivatePort int
+		PublicPort  int
+		Type        string
+	}
+}
+
+// dockerImage is the part of an image listed by the Docker engine API
+type dockerImage struct {
+	Id          string
+	RepoTags    []string
+	RepoDigests []string
+	Size        int64
+}
+
+// crictlContainers is the part of the containers listed by crictl
+type crictlContainers struct {
+	Containers []struct {
+		Id       string
+		Metadata struct{ Name string }
+		Image    struct{ Image string }
+		ImageRef string
+		State    string
+	}
+}
+
+// crictlImages is the part of the images listed by crictl
+type crictlImages struct {
+	Images []struct {
+		Id          string
+		RepoTags    []string
+		RepoDigests []string
+		Size        string
+	}
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// collectContainerData lists the containers and the images of the Docker engine, or of containerd when Docker isn't
+// running, found is false when the instance runs neither of them.
+func collectContainerData(context context.T) (containers []model.ContainerData, images []model.ContainerImageData, found bool, err error) {
+	log := context.Log()
+	if socketExists(dockerSocket) {
+		log.Infof("Collecting the containers of the Docker engine")
+		containers, images, err = collectDockerData()
+	} else if socketExists(containerdSocket) {
+		log.Infof("Collecting the containers of containerd")
+		containers, images, err = collectContainerdData()
+	} else {
+		log.Debugf("No container runtime found")
+		return
+	}
+
+	if err != nil {
+		err = fmt.Errorf("Unable to list the containers - %v", err.Error())
+		log.Error(err.Error())
+		return
+	}
+	found = true
+	return
+}
+
+// dockerQuery sends the GET request of the Docker engine API to the Docker socket and decodes the response in v
+func dockerQuery(path string, v interface{}) error {
+	client := &http.Client{
+		Timeout: requestTimeout,
+		Transport: &http.Transport{
+			Dial: func(network, addr string) (net.Conn, error) {
+				return net.Dial("unix", dockerSocket)
+			},
+		},
+	}
+	resp, err := client.Get("http://docker" + path)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("the Docker engine replied %v to %v", resp.Status, path)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// crictlQuery runs crictl against the containerd socket and decodes its output in v
+func crictlQuery(v interface{}, args ...string) error {
+	args = append([]string{"--runtime-endpoint", "unix://" + containerdSocket}, append(args, "-o", "json")...)
+	cmd := exec.Command(crictlCmd, args...)
+	timer := time.AfterFunc(requestTimeout, func() {
+		if cmd.Process != nil {
+			cmd.Process.Kill()
+		}
+	})
+	defer timer.Stop()
+	output, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("%v %v failed - %v", crictlCmd, strings.Join(args, " "), err)
+	}
+	return json.Unmarshal(output, v)
+}
+
+func collectDockerData() (containers []model.ContainerData, images []model.ContainerImageData, err error) {
+	var dockerContainers []dockerContainer
+	var dockerImages []dockerImage
+	if err = queryDocker("/containers/json?all=1", &dockerContainers); err != nil {
+		return
+	}
+	if err = queryDocker("/images/json", &dockerImages); err != nil {
+		return
+	}
+
+	digests := map[string]string{}
+	images = []model.ContainerImageData{}
+	for _, image := range dockerImages {
+		digests[image.Id] = firstDigest(image.RepoDigests)
+		images = append(images, imageData(image.Id, image.RepoTags, image.RepoDigests, strconv.FormatInt(image.Size, 10))...)
+	}
+
+	containers = []model.ContainerData{}
+	for _, container := range dockerContainers {
+		var ports []string
+		for _, port := range container.Ports {
+			if port.PublicPort != 0 {
+				ports = append(ports, fmt.Sprintf("%v:%v->%v/%v", port.IP, port.PublicPort, port.PrivatePort, port.Type))
+			} else {
+				ports = append(ports, fmt.Sprintf("%v/%v", port.PrivatePort, port.Type))
+			}
+		}
+		sort.Strings(ports)
+		var name string
+		if len(container.Names) > 0 {
+			name = strings.TrimPrefix(container.Names[0], "/")
+		}
+		containers = append(containers, model.ContainerData{
+			ContainerId: container.Id,
+			Name:        name,
+			Image:       container.Image,
+			Tag:         imageTag(container.Image),
+			Digest:      digests[container.ImageID],
+			State:       container.State,
+			Ports:       strings.Join(ports, ", "),
+		})
+	}
+	return
+}
+
+func collectContainerdData() (containers []model.ContainerData, images []model.ContainerImageData, err error) {
+	var criContainers crictlContainers
+	var criImages crictlImages
+	if err = queryContainer(&criContainers, "ps", "-a"); err != nil {
+		return
+	}
+	if err = queryContainer(&criImages, "images"); err != nil {
+		return
+	}
+
+	digests := map[string]string{}
+	images = []model.ContainerImageData{}
+	for _, image := range criImages.Images {
+		digests[image.Id] = firstDigest(image.RepoDigests)
+		images = append(images, imageData(image.Id, image.RepoTags, image.RepoDigests, image.Size)...)
+	}
+
+	containers = []model.ContainerData{}
+	for _, container := range criContainers.Containers {
+		digest := digests[container.ImageRef]
+		if repoDigest := firstDigest([]string{container.ImageRef}); repoDigest != "" {
+			digest = repoDigest
+		}
+		containers = append(containers, model.ContainerData{
+			ContainerId: container.Id,
+			Name:        container.Metadata.Name,
+			Image:       container.Image.Image,
+			Tag:         imageTag(container.Image.Image),
+			Digest:      digest,
+			State:       strings.ToLower(strings.TrimPrefix(container.State, "CONTAINER_")),
+		})
+	}
+	return
+}
+
+// imageData returns an entry per tag of the image, or one untagged entry
+func imageData(id string, repoTags []string, repoDigests []string, size string) (images []model.ContainerImageData) {
+	digest := firstDigest(repoDigests)
+	for _, repoTag := range repoTags {
+		images = append(images, model.ContainerImageData{
+			ImageId:    id,
+			Repository: imageRepository(repoTag),
+			Tag:        imageTag(repoTag),
+			Digest:     digest,
+			Size:       size,
+		})
+	}
+	if len(images) == 0 {
+		var repository string
+		if len(repoDigests) > 0 {
+			repository = strings.SplitN(repoDigests[0], "@", 2)[0]
+		}
+		images = append(images, model.ContainerImageData{ImageId: id, Repository: repository, Digest: digest, Size: size})
+	}
+	return
+}
+
+// firstDigest returns the digest of the first repository digest, such as sha256:... of repository@sha256:...
+func firstDigest(repoDigests []string) string {
+	for _, repoDigest := range repoDigests {
+		if parts := strings.SplitN(repoDigest, "@", 2); len(parts) == 2 {
+			return parts[1]
+		}
+	}
+	return ""
+}
+
+// imageRepository returns the repository of the image reference, without tag nor digest
+func imageRepository(reference string) string {
+	reference = strings.SplitN(reference, "@", 2)[0]
+	if i := strings.LastIndex(reference, ":"); i > strings.LastIndex(reference, "/") {
+		return reference[:i]
+	}
+	return reference
+}
+
+// imageTag returns the tag of the image reference, latest when the reference has neither tag nor digest
+func imageTag(reference string) string {
+	if strings.Contains(reference, "@") || strings.HasPrefix(reference, "sha256:") {
+		return ""
+	}
+	if i := strings.LastIndex(reference, ":"); i > strings.LastIndex(reference, "/") {
+		return reference[i+1:]
+	}
+	return "latest"
+}
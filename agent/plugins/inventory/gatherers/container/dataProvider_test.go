@@ -0,0 +1,104 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package container contains a gatherer for the containers and images of the local container runtime
+package container
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/model"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	dockerContainersOutput = `[
+{"Id": "8dfafdbc3a40", "Names": ["/web"], "Image": "nginx:1.15", "ImageID": "sha256:06144b2878", "State": "running",
+ "Ports": [{"IP": "0.0.0.0", "PrivatePort": 80, "PublicPort": 8080, "Type": "tcp"}, {"PrivatePort": 443, "Type": "tcp"}]},
+{"Id": "2b5c4e1a0f9d", "Names": ["/job"], "Image": "sha256:a1b2c3d4e5", "ImageID": "sha256:a1b2c3d4e5", "State": "exited", "Ports": []}]`
+	dockerImagesOutput = `[
+{"Id": "sha256:06144b2878", "RepoTags": ["nginx:1.15", "nginx:stable"], "RepoDigests": ["nginx@sha256:9ad0746d8f"], "Size": 109331233},
+{"Id": "sha256:a1b2c3d4e5", "RepoTags": null, "RepoDigests": ["registry:5000/batch@sha256:55c1b1e6f7"], "Size": 2048}]`
+	crictlContainersOutput = `{"containers": [{"id": "4f1b2c", "metadata": {"name": "coredns"}, "image": {"image": "k8s.gcr.io/coredns:1.2.2"},
+ "imageRef": "sha256:367cdc8433", "state": "CONTAINER_RUNNING"}]}`
+	crictlImagesOutput = `{"images": [{"id": "sha256:367cdc8433", "repoTags": ["k8s.gcr.io/coredns:1.2.2"],
+ "repoDigests": ["k8s.gcr.io/coredns@sha256:3e2be1cec8"], "size": "39186617"}]}`
+)
+
+func TestCollectDockerData(t *testing.T) {
+	defer func() { socketExists, queryDocker = fileExists, dockerQuery }()
+	socketExists = func(path string) bool { return path == dockerSocket }
+	queryDocker = func(path string, v interface{}) error {
+		if path == "/images/json" {
+			return json.Unmarshal([]byte(dockerImagesOutput), v)
+		}
+		return json.Unmarshal([]byte(dockerContainersOutput), v)
+	}
+
+	containers, images, found, err := collectContainerData(context.NewMockDefault())
+
+	assert.Nil(t, err)
+	assert.True(t, found)
+	assert.Equal(t, []model.ContainerData{
+		{ContainerId: "8dfafdbc3a40", Name: "web", Image: "nginx:1.15", Tag: "1.15", Digest: "sha256:9ad0746d8f", State: "running", Ports: "0.0.0.0:8080->80/tcp, 443/tcp"},
+		{ContainerId: "2b5c4e1a0f9d", Name: "job", Image: "sha256:a1b2c3d4e5", Digest: "sha256:55c1b1e6f7", State: "exited"},
+	}, containers)
+	assert.Equal(t, []model.ContainerImageData{
+		{ImageId: "sha256:06144b2878", Repository: "nginx", Tag: "1.15", Digest: "sha256:9ad0746d8f", Size: "109331233"},
+		{ImageId: "sha256:06144b2878", Repository: "nginx", Tag: "stable", Digest: "sha256:9ad0746d8f", Size: "109331233"},
+		{ImageId: "sha256:a1b2c3d4e5", Repository: "registry:5000/batch", Digest: "sha256:55c1b1e6f7", Size: "2048"},
+	}, images)
+}
+
+func TestCollectContainerdData(t *testing.T) {
+	defer func() { socketExists, queryContainer = fileExists, crictlQuery }()
+	socketExists = func(path string) bool { return path == containerdSocket }
+	queryContainer = func(v interface{}, args ...string) error {
+		if args[0] == "images" {
+			return json.Unmarshal([]byte(crictlImagesOutput), v)
+		}
+		assert.Equal(t, []string{"ps", "-a"}, args)
+		return json.Unmarshal([]byte(crictlContainersOutput), v)
+	}
+
+	containers, images, found, err := collectContainerData(context.NewMockDefault())
+
+	assert.Nil(t, err)
+	assert.True(t, found)
+	assert.Equal(t, []model.ContainerData{
+		{ContainerId: "4f1b2c", Name: "coredns", Image: "k8s.gcr.io/coredns:1.2.2", Tag: "1.2.2", Digest: "sha256:3e2be1cec8", State: "running"},
+	}, containers)
+	assert.Equal(t, []model.ContainerImageData{
+		{ImageId: "sha256:367cdc8433", Repository: "k8s.gcr.io/coredns", Tag: "1.2.2", Digest: "sha256:3e2be1cec8", Size: "39186617"},
+	}, images)
+}
+
+func TestCollectContainerDataWithoutRuntime(t *testing.T) {
+	defer func() { socketExists = fileExists }()
+	socketExists = func(path string) bool { return false }
+
+	_, _, found, err := collectContainerData(context.NewMockDefault())
+
+	assert.Nil(t, err)
+	assert.False(t, found)
+}
+
+func TestImageTag(t *testing.T) {
+	assert.Equal(t, "latest", imageTag("nginx"))
+	assert.Equal(t, "latest", imageTag("registry:5000/batch"))
+	assert.Equal(t, "2.1", imageTag("registry:5000/batch:2.1"))
+	assert.Equal(t, "", imageTag("nginx@sha256:9ad0746d8f"))
+	assert.Equal(t, "registry:5000/batch", imageRepository("registry:5000/batch:2.1"))
+}
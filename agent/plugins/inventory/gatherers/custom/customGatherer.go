@@ -172,7 +172,7 @@ func getItemFromFile(log log.T, file string) (result model.Item, err error) {
 		return
 	}
 
-	result, err = convertToItem(log, content)
+	result, err = ConvertToItem(log, content)
 	if err != nil {
 		LogError(log, fmt.Errorf("Failed to convert file (%v) to inventory item, error: %v",
 			file, err))
@@ -180,8 +180,9 @@ func getItemFromFile(log log.T, file string) (result model.Item, err error) {
 	return
 }
 
-// convertToItem Validates custom inventory content's schema and convert to inventory.Item
-func convertToItem(log log.T, content []byte) (item model.Item, err error) {
+// ConvertToItem Validates custom inventory content's schema and convert to inventory.Item, the gatherers of
+// custom inventory types validate their items with it
+func ConvertToItem(log log.T, content []byte) (item model.Item, err error) {
 
 	var customInventoryItem model.CustomInventoryItem
 
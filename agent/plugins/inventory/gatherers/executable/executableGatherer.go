@@ -0,0 +1,158 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package executable contains a gatherer running an executable to collect custom inventory items
+package executable
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/gatherers/custom"
+	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/model"
+)
+
+const (
+	// GathererNamePrefix represents the prefix of the names of executable gatherers
+	GathererNamePrefix = "Executable:"
+	// executionTimeout is the time an executable is given to print its inventory items
+	executionTimeout = 5 * time.Minute
+	// outputLimit is the size of the output read from an executable, one item per custom inventory type at most
+	outputLimit = custom.CustomInventoryCountLimit * model.SizeLimitKBPerInventoryType * 1024
+)
+
+// T represents a gatherer running an executable which prints custom inventory items in JSON on its standard output,
+// either one item or an array of items in the format of the custom inventory files.
+type T struct {
+	path string
+	// lock guards cmd, the running executable
+	lock sync.Mutex
+	cmd  *exec.Cmd
+}
+
+// GathererName returns the name of the gatherer running the executable
+func GathererName(path string) string {
+	return GathererNamePrefix + strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+}
+
+// Gatherer returns a new gatherer running the executable
+func Gatherer(context context.T, path string) *T {
+	return &T{path: path}
+}
+
+// Name returns name of the gatherer
+func (t *T) Name() string {
+	return GathererName(t.path)
+}
+
+// runExecutable is assigned to a variable to allow unit tests to override it
+var runExecutable = (*T).runExecutable
+
+// Run runs the executable and returns the inventory items it prints
+func (t *T) Run(context context.T, configuration model.Config) (items []model.Item, err error) {
+	log := context.Log()
+
+	var output []byte
+	if output, err = runExecutable(t); err != nil {
+		err = fmt.Errorf("inventory gatherer %v failed, %v", t.path, err)
+		log.Error(err)
+		return
+	}
+
+	var contents []json.RawMessage
+	if output = bytes.TrimSpace(output); bytes.HasPrefix(output, []byte("[")) {
+		if err = json.Unmarshal(output, &contents); err != nil {
+			err = fmt.Errorf("inventory gatherer %v printed invalid items, %v", t.path, err)
+			log.Error(err)
+			return
+		}
+	} else if len(output) > 0 {
+		contents = append(contents, output)
+	}
+
+	typeNames := make(map[string]bool)
+	for _, content := range contents {
+		var item model.Item
+		if item, err = custom.ConvertToItem(log, content); err != nil {
+			err = fmt.Errorf("inventory gatherer %v printed an invalid item, %v", t.path, err)
+			log.Error(err)
+			return nil, err
+		}
+		if typeNames[item.Name] {
+			err = fmt.Errorf("inventory gatherer %v printed type %v more than once", t.path, item.Name)
+			log.Error(err)
+			return nil, err
+		}
+		typeNames[item.Name] = true
+		items = append(items, item)
+	}
+	log.Debugf("Count of inventory items from %v : %v.", t.path, len(items))
+	return
+}
+
+// runExecutable runs the executable until it exits or times out and returns its standard output
+func (t *T) runExecutable() ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(t.path)
+	cmd.Stderr = &stderr
+	stdoutPipe, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err = cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	t.lock.Lock()
+	t.cmd = cmd
+	t.lock.Unlock()
+	defer func() {
+		t.lock.Lock()
+		t.cmd = nil
+		t.lock.Unlock()
+	}()
+
+	timer := time.AfterFunc(executionTimeout, func() {
+		cmd.Process.Kill()
+	})
+	defer timer.Stop()
+
+	n, err := io.Copy(&stdout, io.LimitReader(stdoutPipe, outputLimit+1))
+	if err == nil && n > outputLimit {
+		err = fmt.Errorf("the output exceeds %v bytes", outputLimit)
+		cmd.Process.Kill()
+	}
+	if waitErr := cmd.Wait(); err == nil && waitErr != nil {
+		err = fmt.Errorf("%v %v", waitErr, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), err
+}
+
+// RequestStop stops the running executable
+func (t *T) RequestStop(stopType contracts.StopType) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.cmd == nil || t.cmd.Process == nil {
+		return nil
+	}
+	return t.cmd.Process.Kill()
+}
@@ -0,0 +1,69 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package executable contains a gatherer running an executable to collect custom inventory items
+package executable
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/model"
+	"github.com/stretchr/testify/assert"
+)
+
+const licensesItem = `{"TypeName": "Custom:Licenses", "SchemaVersion": "1.0", "Content": [{"Product": "db", "Seats": "10"}]}`
+const disksItem = `{"TypeName": "Custom:Disks", "SchemaVersion": "1.0", "Content": {"Count": "2"}}`
+
+func TestGathererName(t *testing.T) {
+	assert.Equal(t, "Executable:licenses", GathererName("/opt/inventory/licenses.sh"))
+	assert.Equal(t, "Executable:disks", Gatherer(context.NewMockDefault(), "/opt/inventory/disks").Name())
+}
+
+func TestRun(t *testing.T) {
+	defer func() { runExecutable = (*T).runExecutable }()
+
+	testCases := []struct {
+		output string
+		err    error
+		types  []string
+		failed bool
+	}{
+		{output: licensesItem, types: []string{"Custom:Licenses"}},
+		{output: "\n[" + licensesItem + "," + disksItem + "]\n", types: []string{"Custom:Licenses", "Custom:Disks"}},
+		{output: "  "},
+		{output: "[" + licensesItem + "," + licensesItem + "]", failed: true},
+		{output: `{"TypeName": "Licenses", "SchemaVersion": "1.0", "Content": {}}`, failed: true},
+		{output: "[" + licensesItem, failed: true},
+		{output: licensesItem, err: fmt.Errorf("exit status 1"), failed: true},
+	}
+	for _, testCase := range testCases {
+		runExecutable = func(t *T) ([]byte, error) {
+			return []byte(testCase.output), testCase.err
+		}
+
+		items, err := Gatherer(context.NewMockDefault(), "licenses").Run(context.NewMockDefault(), model.Config{})
+		if testCase.failed {
+			assert.NotNil(t, err)
+			assert.Empty(t, items)
+			continue
+		}
+		assert.Nil(t, err)
+		var types []string
+		for _, item := range items {
+			types = append(types, item.Name)
+		}
+		assert.Equal(t, testCase.types, types)
+	}
+}
@@ -1,10 +1,15 @@
 package file
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
+	"strconv"
+	"time"
 
 	"strings"
 
@@ -22,6 +27,8 @@ type filterObj struct {
 	Pattern      []string
 	Recursive    bool
 	DirScanLimit *int
+	// Hash records the SHA-256 of the matched files in the Custom:FileHash inventory type
+	Hash bool
 }
 
 type fileInfoObject struct {
@@ -37,6 +44,9 @@ const FileCountLimitExceeded = "File Count Limit Exceeded"
 const DirScanLimit = 5000
 const DirScanLimitExceeded = "Directory Scan Limit Exceeded"
 
+// HashSizeLimit is the size of the largest file hashed, larger files are recorded without hash
+const HashSizeLimit = 512 * 1024 * 1024
+
 //decoupling for easy testability
 var readDirFunc = ReadDir
 var existsPath = exists
@@ -44,6 +54,7 @@ var getFullPath func(path string, mapping func(string) string) (string, error)
 var filepathWalk = filepath.Walk
 var getFilesFunc = getFiles
 var getMetaDataFunc = getMetaData
+var getHashDataFunc = getHashData
 var DirScanLimitError = errors.New(DirScanLimitExceeded)
 var FileCountLimitError = errors.New(FileCountLimitExceeded)
 
@@ -164,15 +175,59 @@ func getFiles(log log.T, path string, pattern []string, recursive bool, fileLimi
 	return
 }
 
-//getAllMeta processes the filter, gets paths of all filtered files, and get file info of all files
-func getAllMeta(log log.T, config model.Config) (data []model.FileData, err error) {
+//getFilters parses the filters of the configuration
+func getFilters(log log.T, config model.Config) (filterList []filterObj, err error) {
 	jsonBody := []byte(strings.Replace(config.Filters, `\`, `/`, -1)) //this is to convert the backslash in windows path to slash
-	var filterList []filterObj
 	if err = json.Unmarshal(jsonBody, &filterList); err != nil {
 		LogError(log, err)
+	}
+	return
+}
+
+//getAllMeta processes the filter, gets paths of all filtered files, and get file info of all files
+func getAllMeta(log log.T, config model.Config) (data []model.FileData, err error) {
+	var filterList []filterObj
+	if filterList, err = getFilters(log, config); err != nil {
 		return
 	}
 	var fileList []string
+	if fileList, err = getFilteredFiles(log, filterList); err != nil {
+		return
+	}
+
+	if len(fileList) > 0 {
+		data, err = getMetaDataFunc(log, fileList)
+	}
+	log.Infof("Collected Files %d", len(data))
+	return
+}
+
+//getAllHashes gets the hashes of the files filtered by the filters with Hash set
+func getAllHashes(log log.T, config model.Config) (data []model.FileHashData, err error) {
+	var filterList, hashFilterList []filterObj
+	if filterList, err = getFilters(log, config); err != nil {
+		return
+	}
+	for _, filter := range filterList {
+		if filter.Hash {
+			hashFilterList = append(hashFilterList, filter)
+		}
+	}
+	if len(hashFilterList) == 0 {
+		return
+	}
+
+	var fileList []string
+	if fileList, err = getFilteredFiles(log, hashFilterList); err != nil {
+		return
+	}
+	data = getHashDataFunc(log, fileList)
+	log.Infof("Hashed Files %d", len(data))
+	return
+}
+
+//getFilteredFiles gets paths of all the files filtered by the filters
+func getFilteredFiles(log log.T, filterList []filterObj) (fileList []string, err error) {
 	for _, filter := range filterList {
 
 		var fullPath string
@@ -200,14 +255,47 @@ func getAllMeta(log log.T, config model.Config) (data []model.FileData, err erro
 		fileList = append(fileList, foundFiles...)
 		fileList = removeDuplicatesString(fileList)
 	}
+	return
+}
 
-	if len(fileList) > 0 {
-		data, err = getMetaDataFunc(log, fileList)
+//getHashData gets the path, size, modification time and SHA-256 of the specified file paths
+func getHashData(log log.T, paths []string) (hashData []model.FileHashData) {
+	for _, p := range paths {
+		fi, err := os.Stat(p)
+		if err != nil {
+			LogError(log, err)
+			continue
+		}
+		data := model.FileHashData{
+			Path:             p,
+			Size:             strconv.FormatInt(fi.Size(), 10),
+			ModificationTime: fi.ModTime().Format(time.RFC3339),
+		}
+		if fi.Size() > HashSizeLimit {
+			log.Warnf("File %v is larger than %d bytes, recording it without hash", p, HashSizeLimit)
+		} else if data.Sha256, err = hashFile(p); err != nil {
+			LogError(log, err)
+			continue
+		}
+		hashData = append(hashData, data)
 	}
-	log.Infof("Collected Files %d", len(data))
 	return
 }
 
+//hashFile returns the hex encoded SHA-256 of the file content
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	hash := sha256.New()
+	if _, err = io.Copy(hash, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
 //fileMatchesAnyPattern returns true if file name matches any pattern specified
 func fileMatchesAnyPattern(log log.T, pattern []string, fname string) bool {
 	for _, item := range pattern {
@@ -230,3 +318,11 @@ func collectFileData(context context.T, config model.Config) (data []model.FileD
 	data, err = getAllMeta(log, config)
 	return
 }
+
+//collectFileHashData returns the hashes of the files filtered by the filters with Hash set
+func collectFileHashData(context context.T, config model.Config) (data []model.FileHashData, err error) {
+	log := context.Log()
+	getFullPath = expand
+	data, err = getAllHashes(log, config)
+	return
+}
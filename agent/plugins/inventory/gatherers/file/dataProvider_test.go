@@ -2,6 +2,8 @@ package file
 
 import (
 	"errors"
+	"io/ioutil"
+	"os"
 	"testing"
 
 	"fmt"
@@ -298,3 +300,46 @@ func TestGetFilesPathExists(t *testing.T) {
 	fmt.Println(data)
 	assert.Nil(t, data, "data is not Nil")
 }
+
+func TestGetAllHashes(t *testing.T) {
+	mockContext := context.NewMockDefault()
+	mockLog := mockContext.Log()
+	mockFilters := `[{"Path": "/etc","Pattern":["*.conf"],"Recursive": false}, {"Path": "/usr/bin","Pattern":["*"],"Recursive": false, "Hash": true}]`
+	mockConfig := model.Config{Collection: "Enabled", Filters: mockFilters, Location: ""}
+	var paths []string
+	getFullPath = func(path string, mapping func(string) string) (string, error) { return path, nil }
+	getFilesFunc = func(log log.T, path string, pattern []string, recursive bool, fileLimit int, dirLimit int) ([]string, error) {
+		paths = append(paths, path)
+		return []string{filepath.Join(path, "sudo")}, nil
+	}
+	getHashDataFunc = func(log log.T, paths []string) []model.FileHashData {
+		return []model.FileHashData{{Path: paths[0]}}
+	}
+	defer func() { getHashDataFunc = getHashData }()
+
+	data, err := getAllHashes(mockLog, mockConfig)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"/usr/bin"}, paths)
+	assert.Equal(t, []model.FileHashData{{Path: filepath.Join("/usr/bin", "sudo")}}, data)
+
+	paths = nil
+	data, err = getAllHashes(mockLog, model.Config{Collection: "Enabled", Filters: `[{"Path": "/etc","Pattern":["*.conf"]}]`})
+	assert.Nil(t, err)
+	assert.Nil(t, paths)
+	assert.Nil(t, data)
+}
+
+func TestGetHashData(t *testing.T) {
+	mockLog := context.NewMockDefault().Log()
+	dir, err := ioutil.TempDir("", "filehash")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "app.conf")
+	assert.Nil(t, ioutil.WriteFile(path, []byte("hello"), 0600))
+
+	data := getHashData(mockLog, []string{path, filepath.Join(dir, "missing.conf")})
+	assert.Equal(t, 1, len(data))
+	assert.Equal(t, path, data[0].Path)
+	assert.Equal(t, "5", data[0].Size)
+	assert.Equal(t, "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", data[0].Sha256)
+}
@@ -13,6 +13,8 @@ const (
 	GathererName = "AWS:File"
 	// SchemaVersionOfFileGatherer represents schema version of file gatherer
 	SchemaVersionOfFileGatherer = "1.0"
+	// HashTypeName represents the custom inventory type of the file hashes
+	HashTypeName = "Custom:FileHash"
 )
 
 type T struct{}
@@ -23,6 +25,7 @@ func Gatherer(context context.T) *T {
 }
 
 var collectData = collectFileData
+var collectHashData = collectFileHashData
 
 // Name returns name of file gatherer
 func (t *T) Name() string {
@@ -48,6 +51,19 @@ func (t *T) Run(context context.T, configuration model.Config) (items []model.It
 	}
 
 	items = append(items, result)
+	if err != nil {
+		return
+	}
+
+	var hashData []model.FileHashData
+	if hashData, err = collectHashData(context, configuration); err == nil && hashData != nil {
+		items = append(items, model.Item{
+			Name:          HashTypeName,
+			SchemaVersion: SchemaVersionOfFileGatherer,
+			Content:       hashData,
+			CaptureTime:   captureTime,
+		})
+	}
 	return
 }
 
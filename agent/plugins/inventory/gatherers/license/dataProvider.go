@@ -0,0 +1,70 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package license contains a gatherer for the billing products and the license of the operating system
+package license
+
+import (
+	"strings"
+
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/platform"
+	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/model"
+)
+
+const (
+	// LicenseIncluded is the license type of the instances billed for their license by EC2
+	LicenseIncluded = "LicenseIncluded"
+	// BringYourOwnLicense is the license type of the instances licensed by their owner, including the managed instances
+	BringYourOwnLicense = "BYOL"
+	// NotApplicable is the activation status of the operating systems without activation
+	NotApplicable = "NotApplicable"
+	// Unknown is the activation status of the operating systems whose activation can't be read
+	Unknown = "Unknown"
+)
+
+// the functions are assigned to variables to allow unit tests to override them
+var (
+	isManagedInstance        = platform.IsManagedInstance
+	instanceIdentityDocument = func() (*platform.InstanceIdentityDocument, error) {
+		return platform.NewEC2MetadataClient().InstanceIdentityDocument()
+	}
+	activationStatus = collectActivationStatus
+)
+
+// collectLicenseData returns the billing products of the instance metadata, the license type they imply and the
+// activation status of the operating system
+func collectLicenseData(context context.T) (data model.LicenseData) {
+	log := context.Log()
+	data.LicenseType = BringYourOwnLicense
+	data.ActivationStatus = activationStatus(context)
+
+	if managed, err := isManagedInstance(); err != nil || managed {
+		// managed instances run outside of EC2, their owner brings the licenses
+		return
+	}
+
+	document, err := instanceIdentityDocument()
+	if err != nil {
+		log.Errorf("Unable to read the instance identity document - %v", err)
+		return
+	}
+	data.BillingProducts = strings.Join(document.BillingProducts, ",")
+	data.MarketplaceProductCodes = strings.Join(document.MarketplaceProducts, ",")
+	// the billing products charge the license of the operating system or of software such as SQL Server,
+	// the marketplace product codes charge the products of their seller
+	if len(document.BillingProducts) > 0 {
+		data.LicenseType = LicenseIncluded
+	}
+	return
+}
@@ -0,0 +1,80 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package license contains a gatherer for the billing products and the license of the operating system
+package license
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/platform"
+	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollectLicenseData(t *testing.T) {
+	defer func() {
+		isManagedInstance = platform.IsManagedInstance
+		activationStatus = collectActivationStatus
+	}()
+	activationStatus = func(context context.T) string { return "Licensed" }
+	contextMock := context.NewMockDefault()
+
+	testCases := []struct {
+		managed  bool
+		document *platform.InstanceIdentityDocument
+		err      error
+		license  model.LicenseData
+	}{
+		{
+			document: &platform.InstanceIdentityDocument{BillingProducts: []string{"bp-6ba54002"}, MarketplaceProducts: []string{"6x5jmcajty9edm3f211pqjfn2", "aw0evgkw8e5c1q413zgy5pjce"}},
+			license:  model.LicenseData{BillingProducts: "bp-6ba54002", MarketplaceProductCodes: "6x5jmcajty9edm3f211pqjfn2,aw0evgkw8e5c1q413zgy5pjce", LicenseType: LicenseIncluded, ActivationStatus: "Licensed"},
+		},
+		{
+			document: &platform.InstanceIdentityDocument{MarketplaceProducts: []string{"aw0evgkw8e5c1q413zgy5pjce"}},
+			license:  model.LicenseData{MarketplaceProductCodes: "aw0evgkw8e5c1q413zgy5pjce", LicenseType: BringYourOwnLicense, ActivationStatus: "Licensed"},
+		},
+		{
+			err:     fmt.Errorf("connection refused"),
+			license: model.LicenseData{LicenseType: BringYourOwnLicense, ActivationStatus: "Licensed"},
+		},
+		{
+			managed: true,
+			license: model.LicenseData{LicenseType: BringYourOwnLicense, ActivationStatus: "Licensed"},
+		},
+	}
+	for _, testCase := range testCases {
+		isManagedInstance = func() (bool, error) { return testCase.managed, nil }
+		instanceIdentityDocument = func() (*platform.InstanceIdentityDocument, error) {
+			assert.False(t, testCase.managed)
+			return testCase.document, testCase.err
+		}
+		assert.Equal(t, testCase.license, collectLicenseData(contextMock))
+	}
+}
+
+func TestGatherer(t *testing.T) {
+	defer func() { collectData = collectLicenseData }()
+	collectData = func(context context.T) model.LicenseData {
+		return model.LicenseData{LicenseType: BringYourOwnLicense}
+	}
+	contextMock := context.NewMockDefault()
+
+	items, err := Gatherer(contextMock).Run(contextMock, model.Config{})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(items))
+	assert.Equal(t, TypeName, items[0].Name)
+	assert.Equal(t, []model.LicenseData{{LicenseType: BringYourOwnLicense}}, items[0].Content)
+}
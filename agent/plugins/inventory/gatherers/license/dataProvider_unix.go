@@ -0,0 +1,69 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// +build darwin freebsd linux netbsd openbsd
+
+// Package license contains a gatherer for the billing products and the license of the operating system
+package license
+
+import (
+	"encoding/json"
+	"os/exec"
+	"strings"
+
+	"github.com/aws/amazon-ssm-agent/agent/context"
+)
+
+const (
+	subscriptionManagerCmd = "subscription-manager"
+	suseConnectCmd         = "SUSEConnect"
+)
+
+// cmdExecutor is assigned to a variable to allow unit tests to override it
+var cmdExecutor = executeCommand
+
+func executeCommand(command string, args ...string) ([]byte, error) {
+	return exec.Command(command, args...).Output()
+}
+
+// collectActivationStatus returns the subscription status of Red Hat or SUSE, the other distributions have no activation
+func collectActivationStatus(context context.T) string {
+	log := context.Log()
+
+	if _, err := exec.LookPath(subscriptionManagerCmd); err == nil {
+		// subscription-manager exits with 1 when the system isn't subscribed, the status is still printed
+		output, _ := cmdExecutor(subscriptionManagerCmd, "status")
+		for _, line := range strings.Split(string(output), "\n") {
+			if parts := strings.SplitN(line, ":", 2); len(parts) == 2 && strings.TrimSpace(parts[0]) == "Overall Status" {
+				return strings.TrimSpace(parts[1])
+			}
+		}
+		log.Errorf("Unable to read the subscription status - %v", string(output))
+		return Unknown
+	}
+
+	if _, err := exec.LookPath(suseConnectCmd); err == nil {
+		output, err := cmdExecutor(suseConnectCmd, "--status")
+		var products []struct {
+			Identifier string `json:"identifier"`
+			Status     string `json:"status"`
+		}
+		if err != nil || json.Unmarshal(output, &products) != nil || len(products) == 0 {
+			log.Errorf("Unable to read the registration status - %v", string(output))
+			return Unknown
+		}
+		// the first product is the base product, the operating system
+		return products[0].Status
+	}
+	return NotApplicable
+}
@@ -0,0 +1,64 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// +build windows
+
+// Package license contains a gatherer for the billing products and the license of the operating system
+package license
+
+import (
+	"os/exec"
+	"strings"
+
+	"github.com/aws/amazon-ssm-agent/agent/context"
+)
+
+const (
+	powershellCmd = "powershell"
+	// activationScript prints the license status of Windows, see the LicenseStatus property of SoftwareLicensingProduct
+	activationScript = `$product = Get-WmiObject -Query "SELECT LicenseStatus FROM SoftwareLicensingProduct WHERE PartialProductKey IS NOT NULL AND ApplicationID='55c92734-d682-4d71-983e-d6ec3f16059f'" | Select-Object -First 1
+if ($product) { Write-Output $product.LicenseStatus }`
+)
+
+// licenseStatuses are the names of the license statuses of SoftwareLicensingProduct
+var licenseStatuses = map[string]string{
+	"0": "Unlicensed",
+	"1": "Licensed",
+	"2": "OOBGrace",
+	"3": "OOTGrace",
+	"4": "NonGenuineGrace",
+	"5": "Notification",
+	"6": "ExtendedGrace",
+}
+
+// cmdExecutor is assigned to a variable to allow unit tests to override it
+var cmdExecutor = executeCommand
+
+func executeCommand(command string, args ...string) ([]byte, error) {
+	return exec.Command(command, args...).CombinedOutput()
+}
+
+// collectActivationStatus returns the activation status of Windows
+func collectActivationStatus(context context.T) string {
+	log := context.Log()
+	output, err := cmdExecutor(powershellCmd, activationScript)
+	if err != nil {
+		log.Errorf("Unable to read the activation status - %v", string(output))
+		return Unknown
+	}
+	if status, known := licenseStatuses[strings.TrimSpace(string(output))]; known {
+		return status
+	}
+	log.Errorf("Unexpected activation status - %v", string(output))
+	return Unknown
+}
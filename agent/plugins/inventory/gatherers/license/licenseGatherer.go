@@ -0,0 +1,67 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package license contains a gatherer for the billing products and the license of the operating system
+package license
+
+import (
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/model"
+)
+
+const (
+	// GathererName captures name of license gatherer
+	GathererName = "License"
+	// TypeName represents the custom inventory type of the license
+	TypeName = "Custom:License"
+	// SchemaVersionOfLicenseGatherer represents schema version of license gatherer
+	SchemaVersionOfLicenseGatherer = "1.0"
+)
+
+// T represents license gatherer
+type T struct{}
+
+// Gatherer returns new license gatherer
+func Gatherer(context context.T) *T {
+	return new(T)
+}
+
+var collectData = collectLicenseData
+
+// Name returns name of license gatherer
+func (t *T) Name() string {
+	return GathererName
+}
+
+// Run executes license gatherer and returns the license of the instance
+func (t *T) Run(context context.T, configuration model.Config) (items []model.Item, err error) {
+	//CaptureTime must comply with format: 2016-07-30T18:15:37Z to comply with regex at SSM.
+	captureTime := time.Now().UTC().Format(time.RFC3339)
+
+	items = append(items, model.Item{
+		Name:          TypeName,
+		SchemaVersion: SchemaVersionOfLicenseGatherer,
+		Content:       []model.LicenseData{collectData(context)},
+		CaptureTime:   captureTime,
+	})
+	return
+}
+
+// RequestStop stops the execution of license gatherer.
+func (t *T) RequestStop(stopType contracts.StopType) error {
+	var err error
+	return err
+}
@@ -19,9 +19,12 @@ import (
 	"github.com/aws/amazon-ssm-agent/agent/contracts"
 	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/gatherers/application"
 	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/gatherers/awscomponent"
+	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/gatherers/certificate"
+	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/gatherers/container"
 	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/gatherers/custom"
 	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/gatherers/file"
 	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/gatherers/instancedetailedinformation"
+	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/gatherers/license"
 	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/gatherers/network"
 	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/gatherers/registry"
 	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/gatherers/role"
@@ -53,19 +56,7 @@ func InitializeGatherers(context context.T) (SupportedGatherer, InstalledGathere
 	log := context.Log()
 	var installedGathererNames []string
 
-	installedGatherer := InstalledGatherer{
-		application.GathererName:                 application.Gatherer(context),
-		awscomponent.GathererName:                awscomponent.Gatherer(context),
-		custom.GathererName:                      custom.Gatherer(context),
-		network.GathererName:                     network.Gatherer(context),
-		windowsUpdate.GathererName:               windowsUpdate.Gatherer(context),
-		file.GathererName:                        file.Gatherer(context),
-		instancedetailedinformation.GathererName: instancedetailedinformation.Gatherer(context),
-		role.GathererName:                        role.Gatherer(context),
-		service.GathererName:                     service.Gatherer(context),
-		registry.GathererName:                    registry.Gatherer(context),
-	}
-
+	installedGatherer := builtinGatherers(context)
 	for key := range installedGatherer {
 		installedGathererNames = append(installedGathererNames, key)
 	}
@@ -79,5 +70,31 @@ func InitializeGatherers(context context.T) (SupportedGatherer, InstalledGathere
 
 	log.Infof("Supported Gatherer: %v", supportedGathererNames)
 
+	// the gatherers of custom inventory types run on every platform
+	for name, gatherer := range customGatherers(context, installedGatherer) {
+		installedGatherer[name] = gatherer
+		supportedGatherer[name] = gatherer
+		log.Infof("Custom Inventory Gatherer: %v", name)
+	}
+
 	return supportedGatherer, installedGatherer
 }
+
+// builtinGatherers returns the gatherers shipped with the agent
+func builtinGatherers(context context.T) InstalledGatherer {
+	return InstalledGatherer{
+		application.GathererName:                 application.Gatherer(context),
+		awscomponent.GathererName:                awscomponent.Gatherer(context),
+		certificate.GathererName:                 certificate.Gatherer(context),
+		container.GathererName:                   container.Gatherer(context),
+		custom.GathererName:                      custom.Gatherer(context),
+		network.GathererName:                     network.Gatherer(context),
+		windowsUpdate.GathererName:               windowsUpdate.Gatherer(context),
+		file.GathererName:                        file.Gatherer(context),
+		instancedetailedinformation.GathererName: instancedetailedinformation.Gatherer(context),
+		license.GathererName:                     license.Gatherer(context),
+		role.GathererName:                        role.Gatherer(context),
+		service.GathererName:                     service.Gatherer(context),
+		registry.GathererName:                    registry.Gatherer(context),
+	}
+}
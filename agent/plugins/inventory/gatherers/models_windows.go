@@ -20,9 +20,11 @@ package gatherers
 import (
 	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/gatherers/application"
 	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/gatherers/awscomponent"
+	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/gatherers/certificate"
 	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/gatherers/custom"
 	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/gatherers/file"
 	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/gatherers/instancedetailedinformation"
+	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/gatherers/license"
 	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/gatherers/network"
 	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/gatherers/registry"
 	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/gatherers/role"
@@ -33,11 +35,13 @@ import (
 var supportedGathererNames = []string{
 	application.GathererName,
 	awscomponent.GathererName,
+	certificate.GathererName,
 	custom.GathererName,
 	network.GathererName,
 	windowsUpdate.GathererName,
 	file.GathererName,
 	instancedetailedinformation.GathererName,
+	license.GathererName,
 	role.GathererName,
 	service.GathererName,
 	registry.GathererName,
@@ -0,0 +1,266 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// +build darwin freebsd linux netbsd openbsd
+
+// Package network contains a network gatherer.
+package network
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/model"
+)
+
+const (
+	// tcpListen is the state of the listening tcp sockets in /proc/net/tcp
+	tcpListen = "0A"
+	// udpUnconnected is the state of the bound udp sockets in /proc/net/udp
+	udpUnconnected = "07"
+	// routeUp is the flag of the usable routes in /proc/net/route
+	routeUp = 0x1
+)
+
+// procRoot and resolvConf are assigned to variables to allow unit tests to override them
+var procRoot = "/proc"
+var resolvConf = "/etc/resolv.conf"
+
+// readProcTable returns the fields of the lines of the table under /proc/net, without header
+func readProcTable(name string, header bool) (rows [][]string, err error) {
+	file, err := os.Open(filepath.Join(procRoot, "net", name))
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for first := true; scanner.Scan(); first = false {
+		if first && header {
+			continue
+		}
+		if fields := strings.Fields(scanner.Text()); len(fields) > 0 {
+			rows = append(rows, fields)
+		}
+	}
+	err = scanner.Err()
+	return
+}
+
+// parseIPv4 parses the address of /proc/net, an ipv4 address in hex of the host byte order
+func parseIPv4(s string) net.IP {
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) != net.IPv4len {
+		return nil
+	}
+	return net.IPv4(b[3], b[2], b[1], b[0]).To4()
+}
+
+// parseIPv6 parses the address of /proc/net/tcp6 and udp6, four words of the host byte order
+func parseIPv6(s string) net.IP {
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) != net.IPv6len {
+		return nil
+	}
+	ip := make(net.IP, net.IPv6len)
+	for i := 0; i < net.IPv6len; i += 4 {
+		binary.BigEndian.PutUint32(ip[i:], binary.LittleEndian.Uint32(b[i:]))
+	}
+	return ip
+}
+
+// collectRouteData collects the ipv4 and ipv6 routes from /proc/net, nil when they can't be read
+func collectRouteData(context context.T) (data []model.NetworkRouteData) {
+	log := context.Log()
+
+	routes, err := readProcTable("route", true)
+	if err != nil {
+		log.Debugf("Unable to read the ipv4 routes - %v", err)
+		return
+	}
+	data = []model.NetworkRouteData{}
+	for _, route := range routes {
+		// Iface Destination Gateway Flags RefCnt Use Metric Mask MTU Window IRTT
+		if len(route) < 8 {
+			continue
+		}
+		flags, _ := strconv.ParseUint(route[3], 16, 32)
+		destination, gateway, mask := parseIPv4(route[1]), parseIPv4(route[2]), parseIPv4(route[7])
+		if flags&routeUp == 0 || destination == nil || mask == nil {
+			continue
+		}
+		prefix, _ := net.IPMask(mask).Size()
+		data = append(data, model.NetworkRouteData{
+			Interface:   route[0],
+			Destination: (&net.IPNet{IP: destination, Mask: net.CIDRMask(prefix, 32)}).String(),
+			Gateway:     gateway.String(),
+			Metric:      route[6],
+		})
+	}
+
+	routes, err = readProcTable("ipv6_route", false)
+	if err != nil {
+		log.Debugf("Unable to read the ipv6 routes - %v", err)
+		return
+	}
+	for _, route := range routes {
+		// Destination PrefixLength Source SourcePrefixLength NextHop Metric RefCnt Use Flags Iface
+		if len(route) < 10 || route[9] == "lo" {
+			continue
+		}
+		flags, _ := strconv.ParseUint(route[8], 16, 32)
+		prefix, _ := strconv.ParseUint(route[1], 16, 8)
+		metric, _ := strconv.ParseUint(route[5], 16, 32)
+		destination, nextHop := parseRawIPv6(route[0]), parseRawIPv6(route[4])
+		if flags&routeUp == 0 || destination == nil || nextHop == nil {
+			continue
+		}
+		data = append(data, model.NetworkRouteData{
+			Interface:   route[9],
+			Destination: (&net.IPNet{IP: destination, Mask: net.CIDRMask(int(prefix), 128)}).String(),
+			Gateway:     nextHop.String(),
+			Metric:      strconv.FormatUint(metric, 10),
+		})
+	}
+	return
+}
+
+// parseRawIPv6 parses the address of /proc/net/ipv6_route, in hex of the network byte order
+func parseRawIPv6(s string) net.IP {
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) != net.IPv6len {
+		return nil
+	}
+	return net.IP(b)
+}
+
+// collectListeningPortData collects the listening tcp and the bound udp sockets from /proc/net along their process,
+// nil when they can't be read
+func collectListeningPortData(context context.T) (data []model.ListeningPortData) {
+	log := context.Log()
+	processes := socketProcesses()
+
+	for _, table := range []struct {
+		name     string
+		protocol string
+		state    string
+		parseIP  func(string) net.IP
+	}{
+		{"tcp", "TCP", tcpListen, parseIPv4},
+		{"tcp6", "TCP", tcpListen, parseIPv6},
+		{"udp", "UDP", udpUnconnected, parseIPv4},
+		{"udp6", "UDP", udpUnconnected, parseIPv6},
+	} {
+		sockets, err := readProcTable(table.name, true)
+		if err != nil {
+			log.Debugf("Unable to read the %v sockets - %v", table.name, err)
+			continue
+		}
+		if data == nil {
+			data = []model.ListeningPortData{}
+		}
+		for _, socket := range sockets {
+			// sl local_address rem_address st tx_queue:rx_queue tr:tm->when retrnsmt uid timeout inode
+			if len(socket) < 10 || socket[3] != table.state {
+				continue
+			}
+			local := strings.Split(socket[1], ":")
+			if len(local) != 2 {
+				continue
+			}
+			ip := table.parseIP(local[0])
+			port, err := strconv.ParseUint(local[1], 16, 16)
+			if ip == nil || err != nil {
+				continue
+			}
+			process := processes[socket[9]]
+			data = append(data, model.ListeningPortData{
+				Protocol:    table.protocol,
+				Address:     ip.String(),
+				Port:        strconv.FormatUint(port, 10),
+				ProcessId:   process.id,
+				ProcessName: process.name,
+			})
+		}
+	}
+	return
+}
+
+type socketProcess struct {
+	id   string
+	name string
+}
+
+// socketProcesses returns the processes owning the sockets by socket inode, the sockets of the processes the agent
+// can't inspect are left out
+func socketProcesses() map[string]socketProcess {
+	processes := map[string]socketProcess{}
+	pids, _ := ioutil.ReadDir(procRoot)
+	for _, pid := range pids {
+		if _, err := strconv.Atoi(pid.Name()); err != nil {
+			continue
+		}
+		fds, err := ioutil.ReadDir(filepath.Join(procRoot, pid.Name(), "fd"))
+		if err != nil {
+			continue
+		}
+		var name string
+		for _, fd := range fds {
+			link, err := os.Readlink(filepath.Join(procRoot, pid.Name(), "fd", fd.Name()))
+			if err != nil || !strings.HasPrefix(link, "socket:[") {
+				continue
+			}
+			if name == "" {
+				comm, _ := ioutil.ReadFile(filepath.Join(procRoot, pid.Name(), "comm"))
+				name = strings.TrimSpace(string(comm))
+			}
+			processes[strings.TrimSuffix(strings.TrimPrefix(link, "socket:["), "]")] = socketProcess{id: pid.Name(), name: name}
+		}
+	}
+	return processes
+}
+
+// dnsServer returns the first name server of the resolver configuration
+func dnsServer() string {
+	content, err := ioutil.ReadFile(resolvConf)
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		if fields := strings.Fields(line); len(fields) >= 2 && fields[0] == "nameserver" {
+			return fields[1]
+		}
+	}
+	return ""
+}
+
+// defaultGateways returns the gateways of the default ipv4 routes by interface
+func defaultGateways(routes []model.NetworkRouteData) map[string]string {
+	gateways := map[string]string{}
+	for _, route := range routes {
+		if route.Destination == "0.0.0.0/0" {
+			if _, exists := gateways[route.Interface]; !exists {
+				gateways[route.Interface] = route.Gateway
+			}
+		}
+	}
+	return gateways
+}
@@ -0,0 +1,103 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// +build darwin freebsd linux netbsd openbsd
+
+// Package network contains a network gatherer.
+package network
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/model"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testRoutes = `Iface	Destination	Gateway 	Flags	RefCnt	Use	Metric	Mask		MTU	Window	IRTT
+eth0	00000000	0100000A	0003	0	0	100	00000000	0	0	0
+eth0	0000000A	00000000	0001	0	0	100	00FFFFFF	0	0	0
+eth1	0000A8C0	00000000	0000	0	0	0	00FFFFFF	0	0	0
+`
+	testIPv6Routes = `fe800000000000000000000000000000 40 00000000000000000000000000000000 00 00000000000000000000000000000000 00000100 00000001 00000000 00000001     eth0
+00000000000000000000000000000001 80 00000000000000000000000000000000 00 00000000000000000000000000000000 00000000 00000002 00000000 80200001       lo
+`
+	testTCP = `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000:0016 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 14235 1 0000000000000000 100 0 0 10 0
+   1: 0100000A:D2F0 0200000A:0016 01 00000000:00000000 02:0009C8D3 00000000  1000        0 31337 4 0000000000000000 20 4 31 10 -1
+`
+	testTCP6 = `  sl  local_address                         remote_address                        st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000000000000000000001000000:0050 00000000000000000000000000000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 20480 1 0000000000000000 100 0 0 10 0
+`
+	testUDP = `   sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode ref pointer drops
+  100: 3500007F:0035 00000000:0000 07 00000000:00000000 00:00000000 00000000   101        0 16384 2 0000000000000000 0
+`
+)
+
+func writeTestFile(t *testing.T, path string, content string) {
+	assert.Nil(t, os.MkdirAll(filepath.Dir(path), 0700))
+	assert.Nil(t, ioutil.WriteFile(path, []byte(content), 0600))
+}
+
+func TestCollectRouteAndListeningPortData(t *testing.T) {
+	dir, err := ioutil.TempDir("", "proc")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	defer func() { procRoot = "/proc" }()
+	procRoot = dir
+
+	writeTestFile(t, filepath.Join(dir, "net", "route"), testRoutes)
+	writeTestFile(t, filepath.Join(dir, "net", "ipv6_route"), testIPv6Routes)
+	writeTestFile(t, filepath.Join(dir, "net", "tcp"), testTCP)
+	writeTestFile(t, filepath.Join(dir, "net", "tcp6"), testTCP6)
+	writeTestFile(t, filepath.Join(dir, "net", "udp"), testUDP)
+	writeTestFile(t, filepath.Join(dir, "812", "comm"), "sshd\n")
+	assert.Nil(t, os.MkdirAll(filepath.Join(dir, "812", "fd"), 0700))
+	assert.Nil(t, os.Symlink("socket:[14235]", filepath.Join(dir, "812", "fd", "3")))
+	assert.Nil(t, os.Symlink("/dev/null", filepath.Join(dir, "812", "fd", "0")))
+
+	contextMock := context.NewMockDefault()
+	routes := collectRouteData(contextMock)
+	assert.Equal(t, []model.NetworkRouteData{
+		{Interface: "eth0", Destination: "0.0.0.0/0", Gateway: "10.0.0.1", Metric: "100"},
+		{Interface: "eth0", Destination: "10.0.0.0/24", Gateway: "0.0.0.0", Metric: "100"},
+		{Interface: "eth0", Destination: "fe80::/64", Gateway: "::", Metric: "256"},
+	}, routes)
+	assert.Equal(t, map[string]string{"eth0": "10.0.0.1"}, defaultGateways(routes))
+
+	assert.Equal(t, []model.ListeningPortData{
+		{Protocol: "TCP", Address: "0.0.0.0", Port: "22", ProcessId: "812", ProcessName: "sshd"},
+		{Protocol: "TCP", Address: "::1", Port: "80"},
+		{Protocol: "UDP", Address: "127.0.0.53", Port: "53"},
+	}, collectListeningPortData(contextMock))
+}
+
+func TestDNSServer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "resolv")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	defer func() { resolvConf = "/etc/resolv.conf" }()
+	resolvConf = filepath.Join(dir, "resolv.conf")
+
+	writeTestFile(t, resolvConf, "# generated\nsearch ec2.internal\nnameserver 10.0.0.2\nnameserver 10.0.0.3\n")
+	assert.Equal(t, "10.0.0.2", dnsServer())
+}
+
+func TestExcludeAttributes(t *testing.T) {
+	data := excludeAttributes([]model.NetworkData{{Name: "eth0", MacAddress: "0a:1b", IPV4: "10.0.0.5"}}, map[string]bool{"MacAddress": true})
+	assert.Equal(t, []model.NetworkData{{Name: "eth0", IPV4: "10.0.0.5"}}, data)
+}
@@ -0,0 +1,67 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// +build windows
+
+// Package network contains a network gatherer.
+package network
+
+import (
+	"encoding/json"
+
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/model"
+)
+
+const (
+	cmdArgsToGetRoutes = `ConvertTo-Json -InputObject @(Get-NetRoute | Select-Object @{Name="Interface";Expression={$_.InterfaceAlias}}, @{Name="Destination";Expression={$_.DestinationPrefix}}, @{Name="Gateway";Expression={$_.NextHop}}, @{Name="Metric";Expression={[string]$_.RouteMetric}})`
+
+	cmdArgsToGetListeningPorts = `$processes = @{}; Get-Process | ForEach-Object { $processes[$_.Id] = $_.ProcessName }
+ConvertTo-Json -InputObject @(@(Get-NetTCPConnection -State Listen | Select-Object @{Name="Protocol";Expression={"TCP"}}, LocalAddress, LocalPort, OwningProcess) + @(Get-NetUDPEndpoint | Select-Object @{Name="Protocol";Expression={"UDP"}}, LocalAddress, LocalPort, OwningProcess) | Select-Object Protocol, @{Name="Address";Expression={$_.LocalAddress}}, @{Name="Port";Expression={[string]$_.LocalPort}}, @{Name="ProcessId";Expression={[string]$_.OwningProcess}}, @{Name="ProcessName";Expression={$processes[[int]$_.OwningProcess]}})`
+)
+
+// collectWithPowershell runs the powershell command printing an array in json and decodes it in data
+func collectWithPowershell(context context.T, commandArgs string, data interface{}) bool {
+	log := context.Log()
+	log.Infof("Executing command: %v %v", cmd, commandArgs)
+
+	output, err := cmdExecutor(cmd, commandArgs)
+	if err != nil {
+		log.Errorf("Command failed with error: %v", string(output))
+		return false
+	}
+	if err = json.Unmarshal(output, data); err != nil {
+		log.Errorf("Unable to parse command output - %v", err.Error())
+		return false
+	}
+	return true
+}
+
+// collectRouteData collects the routes of the network interfaces using powershell, nil when they can't be read
+func collectRouteData(context context.T) (data []model.NetworkRouteData) {
+	routes := []model.NetworkRouteData{}
+	if collectWithPowershell(context, cmdArgsToGetRoutes, &routes) {
+		data = routes
+	}
+	return
+}
+
+// collectListeningPortData collects the listening tcp and the udp endpoints along their process using powershell,
+// nil when they can't be read
+func collectListeningPortData(context context.T) (data []model.ListeningPortData) {
+	ports := []model.ListeningPortData{}
+	if collectWithPowershell(context, cmdArgsToGetListeningPorts, &ports) {
+		data = ports
+	}
+	return
+}
@@ -27,8 +27,7 @@ import (
 // CollectNetworkData collects network information for linux
 func CollectNetworkData(context context.T) (data []model.NetworkData) {
 
-	//TODO: collect dhcp, dns server info from dhcp lease
-	//TODO: collect gateway addresses (possibly from 'route -n')
+	//TODO: collect dhcp server info from dhcp lease
 	//TODO: collect subnetmask
 
 	var interfaces []net.Interface
@@ -45,6 +44,9 @@ func CollectNetworkData(context context.T) (data []model.NetworkData) {
 		return
 	}
 
+	dns := dnsServer()
+	gateways := defaultGateways(collectRouteData(context))
+
 	for _, i := range interfaces {
 		var networkData model.NetworkData
 
@@ -54,6 +56,8 @@ func CollectNetworkData(context context.T) (data []model.NetworkData) {
 		}
 
 		networkData = setNetworkData(context, i)
+		networkData.DNSServer = dns
+		networkData.Gateway = gateways[i.Name]
 
 		dataB, _ := json.Marshal(networkData)
 
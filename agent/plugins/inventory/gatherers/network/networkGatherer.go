@@ -27,6 +27,10 @@ const (
 	GathererName = "AWS:Network"
 	// SchemaVersionOfApplication represents schema version of network gatherer
 	SchemaVersionOfApplication = "1.0"
+	// RouteTypeName represents the custom inventory type of the network routes
+	RouteTypeName = "Custom:NetworkRoute"
+	// ListeningPortTypeName represents the custom inventory type of the listening ports
+	ListeningPortTypeName = "Custom:ListeningPort"
 )
 
 // T represents network gatherer which implements all contracts for gatherers.
@@ -51,17 +55,64 @@ func (t *T) Run(context context.T, configuration model.Config) (items []model.It
 	currentTime := time.Now().UTC()
 	captureTime := currentTime.Format(time.RFC3339)
 
+	excluded := map[string]bool{}
+	for _, name := range context.AppConfig().Ssm.NetworkInventoryExcludedItems {
+		excluded[name] = true
+	}
+
 	result = model.Item{
 		Name:          t.Name(),
 		SchemaVersion: SchemaVersionOfApplication,
-		Content:       CollectNetworkData(context),
+		Content:       excludeAttributes(CollectNetworkData(context), excluded),
 		CaptureTime:   captureTime,
 	}
 
 	items = append(items, result)
+
+	if !excluded[RouteTypeName] {
+		if routes := collectRouteData(context); routes != nil {
+			items = append(items, model.Item{
+				Name:          RouteTypeName,
+				SchemaVersion: SchemaVersionOfApplication,
+				Content:       routes,
+				CaptureTime:   captureTime,
+			})
+		}
+	}
+
+	if !excluded[ListeningPortTypeName] {
+		if ports := collectListeningPortData(context); ports != nil {
+			items = append(items, model.Item{
+				Name:          ListeningPortTypeName,
+				SchemaVersion: SchemaVersionOfApplication,
+				Content:       ports,
+				CaptureTime:   captureTime,
+			})
+		}
+	}
 	return
 }
 
+// excludeAttributes clears the excluded attributes of the network interfaces
+func excludeAttributes(data []model.NetworkData, excluded map[string]bool) []model.NetworkData {
+	for i := range data {
+		for name, value := range map[string]*string{
+			"SubnetMask": &data[i].SubnetMask,
+			"Gateway":    &data[i].Gateway,
+			"DHCPServer": &data[i].DHCPServer,
+			"DNSServer":  &data[i].DNSServer,
+			"MacAddress": &data[i].MacAddress,
+			"IPV4":       &data[i].IPV4,
+			"IPV6":       &data[i].IPV6,
+		} {
+			if excluded[name] {
+				*value = ""
+			}
+		}
+	}
+	return data
+}
+
 // RequestStop stops the execution of application gatherer.
 func (t *T) RequestStop(stopType contracts.StopType) error {
 	var err error
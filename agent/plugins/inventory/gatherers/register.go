@@ -0,0 +1,87 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package gatherers contains routines for different types of inventory gatherers
+package gatherers
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/gatherers/executable"
+)
+
+// NewGathererFunc returns a new gatherer of custom inventory types
+type NewGathererFunc func(context context.T) T
+
+var (
+	registeredGatherersLock sync.RWMutex
+	registeredGatherers     = map[string]NewGathererFunc{}
+)
+
+// RegisterGatherer registers a gatherer of custom inventory types, forks call it from the init function of their package.
+// The inventory plugin runs the registered gatherers along the custom gatherer once the inventory policy enables the
+// custom inventory, their items must have a type name starting with Custom:.
+func RegisterGatherer(name string, newGatherer NewGathererFunc) error {
+	if name == "" {
+		return fmt.Errorf("the inventory gatherer has no name")
+	}
+
+	registeredGatherersLock.Lock()
+	defer registeredGatherersLock.Unlock()
+	if _, exists := registeredGatherers[name]; exists {
+		return fmt.Errorf("an inventory gatherer is already registered as %v", name)
+	}
+	registeredGatherers[name] = newGatherer
+	return nil
+}
+
+// customGatherers returns the registered gatherers and the executable gatherers of the agent configuration by name,
+// the gatherers named after a built-in gatherer or another custom gatherer are ignored
+func customGatherers(context context.T, builtin InstalledGatherer) map[string]T {
+	log := context.Log()
+	gatherers := map[string]T{}
+	add := func(name string, gatherer T) {
+		if _, exists := builtin[name]; exists {
+			log.Warnf("Ignoring inventory gatherer %v, it has the name of a built-in gatherer", name)
+		} else if _, exists := gatherers[name]; exists {
+			log.Warnf("Ignoring inventory gatherer %v, another gatherer has the same name", name)
+		} else {
+			gatherers[name] = gatherer
+		}
+	}
+
+	registeredGatherersLock.RLock()
+	for name, newGatherer := range registeredGatherers {
+		add(name, newGatherer(context))
+	}
+	registeredGatherersLock.RUnlock()
+
+	for _, path := range context.AppConfig().Ssm.CustomInventoryGatherers {
+		add(executable.GathererName(path), executable.Gatherer(context, path))
+	}
+	return gatherers
+}
+
+// CustomGathererNames returns the names of the gatherers of custom inventory types, which run along the custom
+// gatherer once the inventory policy enables the custom inventory
+func CustomGathererNames(context context.T) []string {
+	var names []string
+	for name := range customGatherers(context, builtinGatherers(context)) {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
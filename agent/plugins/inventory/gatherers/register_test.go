@@ -0,0 +1,48 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package gatherers contains routines for different types of inventory gatherers
+package gatherers
+
+import (
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/gatherers/application"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterGatherer(t *testing.T) {
+	defer func() { registeredGatherers = map[string]NewGathererFunc{} }()
+
+	newGatherer := func(context context.T) T { return NewMockDefault() }
+	assert.NotNil(t, RegisterGatherer("", newGatherer))
+	assert.Nil(t, RegisterGatherer("Packages", newGatherer))
+	assert.NotNil(t, RegisterGatherer("Packages", newGatherer))
+	assert.Nil(t, RegisterGatherer(application.GathererName, newGatherer))
+
+	config := appconfig.SsmagentConfig{}
+	config.Ssm.CustomInventoryGatherers = []string{"/opt/inventory/licenses.sh", "/usr/local/bin/licenses.sh", "/opt/inventory/disks"}
+	mockContext := new(context.Mock)
+	mockContext.On("Log").Return(log.NewMockLog())
+	mockContext.On("AppConfig").Return(config)
+
+	assert.Equal(t, []string{"Executable:disks", "Executable:licenses", "Packages"}, CustomGathererNames(mockContext))
+
+	supported, installed := InitializeGatherers(mockContext)
+	assert.Equal(t, supported["Packages"], installed["Packages"])
+	assert.Equal(t, "Executable:licenses", supported["Executable:licenses"].Name())
+	assert.IsType(t, application.Gatherer(mockContext), installed[application.GathererName])
+}
@@ -37,8 +37,10 @@ const (
 )
 
 type filterObj struct {
-	Path       string
-	Recursive  bool
+	Path      string
+	Recursive bool
+	// Depth limits the levels of subkeys collected under the path when Recursive is set, 0 collects every level
+	Depth      int
 	ValueNames []string
 }
 
@@ -124,8 +126,15 @@ func collectRegistryData(context context.T, config model.Config) (data []model.R
 		log.Infof("valueNames %v", valueNames)
 		registryPath := "Registry::" + path
 		execScript := registryInfoScript + "-Path \"" + registryPath + "\" -ValueLimit " + fmt.Sprint(valueScanLimit)
+		if filter.Depth < 0 {
+			LogError(log, fmt.Errorf("Invalid depth %v of registry path %v", filter.Depth, path))
+			continue
+		}
 		if recursive == true {
 			execScript += " -Recursive"
+			if filter.Depth > 0 {
+				execScript += " -Depth " + fmt.Sprint(filter.Depth)
+			}
 		}
 		if valueNames != nil && len(valueNames) > 0 {
 			valueNamesArg := strings.Join(valueNames, ",")
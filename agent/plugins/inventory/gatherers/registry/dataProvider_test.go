@@ -15,6 +15,7 @@ package registry
 
 import (
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/aws/amazon-ssm-agent/agent/context"
@@ -146,3 +147,21 @@ func TestGetRegistryDataInvalidMarker(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, testRegistryOutputDataSingleCall, data)
 }
+
+func TestGetRegistryDataDepth(t *testing.T) {
+
+	contextMock := context.NewMockDefault()
+	var scripts []string
+	cmdExecutor = func(command string, args ...string) ([]byte, error) {
+		scripts = append(scripts, args[0])
+		return []byte("[]"), nil
+	}
+	mockFilters := `[{"Path": "HKEY_LOCAL_MACHINE\\SOFTWARE\\Amazon","Recursive": true, "Depth": 2}, {"Path": "HKEY_LOCAL_MACHINE\\SOFTWARE\\Amazon","Recursive": true, "Depth": -1}, {"Path": "HKEY_LOCAL_MACHINE\\SOFTWARE\\Amazon","Depth": 2}]`
+	mockConfig := model.Config{Collection: "Enabled", Filters: mockFilters, Location: ""}
+	_, err := collectRegistryData(contextMock, mockConfig)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(scripts))
+	assert.True(t, strings.Contains(scripts[0], " -Recursive -Depth 2"))
+	assert.False(t, strings.Contains(scripts[1], "-Depth"))
+}
@@ -55,7 +55,7 @@ var (
     }
 
 
-	function Get-RegistryKeys ($key, $valueLimit, $Recursive) {
+	function Get-RegistryKeys ($key, $valueLimit, $Recursive, $Depth, $level) {
 	   try {
 	       $global:count = $global:count + 1
 
@@ -69,7 +69,7 @@ var (
 
 	       }
 
-	       if ($Recursive) {
+	       if ($Recursive -and ($Depth -eq 0 -or $level -lt $Depth)) {
 	           foreach ($sub in $subKeys) {
 			      if ($global:valueCount -gt $valueLimit) {
 				    return;
@@ -77,7 +77,7 @@ var (
 	              try {
 
 	                   $subKey = $key.OpenSubKey($sub)
-                       Get-RegistryKeys $subKey $valueLimit $Recursive
+                       Get-RegistryKeys $subKey $valueLimit $Recursive $Depth ($level + 1)
 
 
 	              } catch {
@@ -95,7 +95,7 @@ var (
 
 	}
 
-	function Get-RegistryKeysFromPath($path, $valueLimit, [switch]$Recursive, [String[]]$Values) {
+	function Get-RegistryKeysFromPath($path, $valueLimit, [switch]$Recursive, [int]$Depth = 0, [String[]]$Values) {
 		try {
             $keyExists = Test-Path $path
             if ($keyExists) {
@@ -110,7 +110,7 @@ var (
                    }
 
                 } else {
-                    Get-RegistryKeys $key $valueLimit $Recursive
+                    Get-RegistryKeys $key $valueLimit $Recursive $Depth 0
 
                 }
 				if ($global:valueCount -gt $valueLimit) {
@@ -0,0 +1,131 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// +build darwin freebsd linux netbsd openbsd
+
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/model"
+)
+
+const (
+	systemctlCmd = "systemctl"
+	// unitProperties are the properties of the service units reported by systemctl show
+	unitProperties = "Id,Description,ActiveState,Type,ExecStart,Requires,RequiredBy"
+	serviceSuffix  = ".service"
+)
+
+// collectServiceData collects the systemd service units and their executable paths,
+// the service data is empty on hosts without systemd
+func collectServiceData(context context.T, config model.Config) (data []model.ServiceData, executables []model.ServiceExecutableData, err error) {
+	log := context.Log()
+	log.Infof("collectServiceData called")
+
+	var output []byte
+	if output, err = cmdExecutor(systemctlCmd, "list-unit-files", "--type=service", "--no-legend", "--no-pager"); err != nil {
+		log.Infof("Unable to list the systemd services, %v %v", err, string(output))
+		return data, executables, nil
+	}
+	startTypes := parseUnitFiles(output)
+	if len(startTypes) == 0 {
+		return
+	}
+
+	units := make([]string, 0, len(startTypes))
+	for unit := range startTypes {
+		units = append(units, unit)
+	}
+	args := append([]string{"show", "--no-pager", "--property=" + unitProperties}, units...)
+	if output, err = cmdExecutor(systemctlCmd, args...); err != nil {
+		err = fmt.Errorf("Command failed with error: %v", string(output))
+		LogError(log, err)
+		return
+	}
+	data, executables = parseUnits(output, startTypes)
+	log.Infof("%v services found", len(data))
+	return
+}
+
+// parseUnitFiles returns the startup type of the service units by unit name, the template units are skipped
+// since they don't run by themselves
+func parseUnitFiles(output []byte) map[string]string {
+	startTypes := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || strings.HasSuffix(fields[0], "@"+serviceSuffix) {
+			continue
+		}
+		startTypes[fields[0]] = fields[1]
+	}
+	return startTypes
+}
+
+// parseUnits converts the output of systemctl show, one block of properties per unit separated by blank lines
+func parseUnits(output []byte, startTypes map[string]string) (data []model.ServiceData, executables []model.ServiceExecutableData) {
+	properties := map[string]string{}
+	flush := func() {
+		if id := properties["Id"]; id != "" {
+			name := strings.TrimSuffix(id, serviceSuffix)
+			data = append(data, model.ServiceData{
+				Name:               name,
+				DisplayName:        properties["Description"],
+				Status:             properties["ActiveState"],
+				DependentServices:  properties["RequiredBy"],
+				ServicesDependedOn: properties["Requires"],
+				ServiceType:        properties["Type"],
+				StartType:          startTypes[id],
+			})
+			if path := executablePath(properties["ExecStart"]); path != "" {
+				executables = append(executables, model.ServiceExecutableData{Name: name, ExecutablePath: path})
+			}
+		}
+		properties = map[string]string{}
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(make([]byte, bufio.MaxScanTokenSize), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			flush()
+			continue
+		}
+		// units running several commands list ExecStart once per command, the first one is kept
+		if parts := strings.SplitN(line, "=", 2); len(parts) == 2 {
+			if _, found := properties[parts[0]]; !found {
+				properties[parts[0]] = parts[1]
+			}
+		}
+	}
+	flush()
+	return
+}
+
+// executablePath returns the path of the first command of ExecStart,
+// e.g. /usr/sbin/sshd in { path=/usr/sbin/sshd ; argv[]=/usr/sbin/sshd -D ; ... }
+func executablePath(execStart string) string {
+	for _, field := range strings.Split(strings.Trim(execStart, "{ }"), " ; ") {
+		if strings.HasPrefix(field, "path=") {
+			return strings.TrimPrefix(field, "path=")
+		}
+	}
+	return ""
+}
@@ -0,0 +1,122 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// +build darwin freebsd linux netbsd openbsd
+
+package service
+
+import (
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/model"
+	"github.com/stretchr/testify/assert"
+)
+
+var testUnitFilesOutput = `amazon-ssm-agent.service    enabled  enabled
+getty@.service              enabled  enabled
+ssh.service                 disabled enabled
+`
+
+var testUnitsOutput = `Id=amazon-ssm-agent.service
+Description=amazon-ssm-agent
+ActiveState=active
+Type=simple
+ExecStart={ path=/usr/bin/amazon-ssm-agent ; argv[]=/usr/bin/amazon-ssm-agent ; ignore_errors=no ; start_time=[n/a] ; stop_time=[n/a] ; pid=0 ; code=(null) ; status=0/0 }
+Requires=system.slice sysinit.target
+RequiredBy=
+
+Id=ssh.service
+Description=OpenBSD Secure Shell server
+ActiveState=inactive
+Type=notify
+ExecStart={ path=/usr/sbin/sshd ; argv[]=/usr/sbin/sshd -D $SSHD_OPTS ; ignore_errors=no ; start_time=[n/a] ; stop_time=[n/a] ; pid=0 ; code=(null) ; status=0/0 }
+ExecStart={ path=/usr/bin/true ; argv[]=/usr/bin/true ; ignore_errors=no ; start_time=[n/a] ; stop_time=[n/a] ; pid=0 ; code=(null) ; status=0/0 }
+Requires=
+RequiredBy=
+`
+
+var testUnitsData = []model.ServiceData{
+	{
+		Name:               "amazon-ssm-agent",
+		DisplayName:        "amazon-ssm-agent",
+		Status:             "active",
+		ServicesDependedOn: "system.slice sysinit.target",
+		ServiceType:        "simple",
+		StartType:          "enabled",
+	},
+	{
+		Name:        "ssh",
+		DisplayName: "OpenBSD Secure Shell server",
+		Status:      "inactive",
+		ServiceType: "notify",
+		StartType:   "disabled",
+	},
+}
+
+var testUnitsExecutables = []model.ServiceExecutableData{
+	{Name: "amazon-ssm-agent", ExecutablePath: "/usr/bin/amazon-ssm-agent"},
+	{Name: "ssh", ExecutablePath: "/usr/sbin/sshd"},
+}
+
+func createMockSystemctl(unitFiles string, unitFilesErr error, units string, unitsErr error) func(string, ...string) ([]byte, error) {
+	return func(command string, args ...string) ([]byte, error) {
+		if args[0] == "list-unit-files" {
+			return []byte(unitFiles), unitFilesErr
+		}
+		return []byte(units), unitsErr
+	}
+}
+
+func TestServiceData(t *testing.T) {
+	contextMock := context.NewMockDefault()
+	var shownUnits []string
+	cmdExecutor = func(command string, args ...string) ([]byte, error) {
+		if args[0] == "show" {
+			shownUnits = args[3:]
+		}
+		return createMockSystemctl(testUnitFilesOutput, nil, testUnitsOutput, nil)(command, args...)
+	}
+
+	data, executables, err := collectServiceData(contextMock, model.Config{})
+
+	assert.Nil(t, err)
+	assert.Equal(t, testUnitsData, data)
+	assert.Equal(t, testUnitsExecutables, executables)
+	sort.Strings(shownUnits)
+	assert.Equal(t, []string{"amazon-ssm-agent.service", "ssh.service"}, shownUnits)
+}
+
+func TestServiceDataWithoutSystemd(t *testing.T) {
+	contextMock := context.NewMockDefault()
+	cmdExecutor = createMockSystemctl("", errors.New("executable file not found in $PATH"), "", nil)
+
+	data, executables, err := collectServiceData(contextMock, model.Config{})
+
+	assert.Nil(t, err)
+	assert.Empty(t, data)
+	assert.Empty(t, executables)
+}
+
+func TestServiceDataShowErr(t *testing.T) {
+	contextMock := context.NewMockDefault()
+	cmdExecutor = createMockSystemctl(testUnitFilesOutput, nil, "Failed to connect to bus", errors.New("exit status 1"))
+
+	data, executables, err := collectServiceData(contextMock, model.Config{})
+
+	assert.NotNil(t, err)
+	assert.Nil(t, data)
+	assert.Nil(t, executables)
+}
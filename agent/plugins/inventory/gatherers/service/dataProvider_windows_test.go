@@ -12,6 +12,8 @@
 // permissions and limitations under the License.
 //
 
+// +build windows
+
 package service
 
 import (
@@ -23,8 +25,8 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
-var testServiceOutput = "[{\"Name\": \"AJRouter\", \"DisplayName\": \"AllJoyn Router Service\", \"Status\": \"Stopped\", \"DependentServices\": \"\", \"ServicesDependedOn\": \"\", \"ServiceType\": \"Win32ShareProcess\", \"StartType\": \"\"},{\"Name\": \"ALG\", \"DisplayName\": \"Application Layer Gateway Service\", \"Status\": \"Stopped\", \"DependentServices\": \"\", \"ServicesDependedOn\": \"BrokerInfrastructure\", \"ServiceType\": \"Win32OwnProcess\", \"StartType\": \"\"}]"
-var testServiceOutputIncorrect = "[{\"Name\": \"<start123>AJRouter\", \"DisplayName\": \"AllJoyn Router Service\", \"Status\": \"Stopped\", \"DependentServices\": \"\", \"ServicesDependedOn\": \"\", \"ServiceType\": \"Win32ShareProcess\", \"StartType\": \"\"},{\"Name\": \"ALG\", \"DisplayName\": \"Application Layer Gateway Service\", \"Status\": \"Stopped\", \"DependentServices\": \"\", \"ServicesDependedOn\": \"BrokerInfrastructure\", \"ServiceType\": \"Win32OwnProcess\", \"StartType\": \"\"}]"
+var testServiceOutput = "[{\"Name\": \"AJRouter\", \"DisplayName\": \"AllJoyn Router Service\", \"Status\": \"Stopped\", \"DependentServices\": \"\", \"ServicesDependedOn\": \"\", \"ServiceType\": \"Win32ShareProcess\", \"StartType\": \"\", \"ExecutablePath\": \"C:\\\\Windows\\\\System32\\\\svchost.exe -k LocalService\"},{\"Name\": \"ALG\", \"DisplayName\": \"Application Layer Gateway Service\", \"Status\": \"Stopped\", \"DependentServices\": \"\", \"ServicesDependedOn\": \"BrokerInfrastructure\", \"ServiceType\": \"Win32OwnProcess\", \"StartType\": \"\", \"ExecutablePath\": \"C:\\\\Windows\\\\System32\\\\svchost.exe -k LocalService\"}]"
+var testServiceOutputIncorrect = "[{\"Name\": \"<start123>AJRouter\", \"DisplayName\": \"AllJoyn Router Service\", \"Status\": \"Stopped\", \"DependentServices\": \"\", \"ServicesDependedOn\": \"\", \"ServiceType\": \"Win32ShareProcess\", \"StartType\": \"\", \"ExecutablePath\": \"C:\\\\Windows\\\\System32\\\\svchost.exe -k LocalService\"},{\"Name\": \"ALG\", \"DisplayName\": \"Application Layer Gateway Service\", \"Status\": \"Stopped\", \"DependentServices\": \"\", \"ServicesDependedOn\": \"BrokerInfrastructure\", \"ServiceType\": \"Win32OwnProcess\", \"StartType\": \"\", \"ExecutablePath\": \"C:\\\\Windows\\\\System32\\\\svchost.exe -k LocalService\"}]"
 
 var testServiceOutputData = []model.ServiceData{
 	{
@@ -47,6 +49,11 @@ var testServiceOutputData = []model.ServiceData{
 	},
 }
 
+var testServiceOutputExecutables = []model.ServiceExecutableData{
+	{Name: "AJRouter", ExecutablePath: `C:\Windows\System32\svchost.exe -k LocalService`},
+	{Name: "ALG", ExecutablePath: `C:\Windows\System32\svchost.exe -k LocalService`},
+}
+
 func createMockTestExecuteCommand(output string, err error) func(string, ...string) ([]byte, error) {
 
 	return func(string, ...string) ([]byte, error) {
@@ -59,10 +66,11 @@ func TestServiceData(t *testing.T) {
 	contextMock := context.NewMockDefault()
 	cmdExecutor = createMockTestExecuteCommand(testServiceOutput, nil)
 
-	data, err := collectServiceData(contextMock, model.Config{})
+	data, executables, err := collectServiceData(contextMock, model.Config{})
 
 	assert.Nil(t, err)
 	assert.Equal(t, data, testServiceOutputData)
+	assert.Equal(t, executables, testServiceOutputExecutables)
 }
 
 func TestServiceDataCmdErr(t *testing.T) {
@@ -70,7 +78,7 @@ func TestServiceDataCmdErr(t *testing.T) {
 	contextMock := context.NewMockDefault()
 	cmdExecutor = createMockTestExecuteCommand("", errors.New("error"))
 
-	data, err := collectServiceData(contextMock, model.Config{})
+	data, _, err := collectServiceData(contextMock, model.Config{})
 
 	assert.NotNil(t, err)
 	assert.Nil(t, data)
@@ -81,7 +89,7 @@ func TestServiceDataInvalidOutput(t *testing.T) {
 	contextMock := context.NewMockDefault()
 	cmdExecutor = createMockTestExecuteCommand("Invalid", nil)
 
-	data, err := collectServiceData(contextMock, model.Config{})
+	data, _, err := collectServiceData(contextMock, model.Config{})
 
 	assert.NotNil(t, err)
 	assert.Nil(t, data)
@@ -93,7 +101,7 @@ func TestServiceDataInvalidMarker(t *testing.T) {
 	contextMock := context.NewMockDefault()
 	cmdExecutor = createMockTestExecuteCommand(testServiceOutputIncorrect, nil)
 
-	data, err := collectServiceData(contextMock, model.Config{})
+	data, _, err := collectServiceData(contextMock, model.Config{})
 
 	assert.NotNil(t, err)
 	assert.Nil(t, data)
@@ -27,6 +27,8 @@ const (
 	GathererName = "AWS:Service"
 	// SchemaVersionOfServiceGatherer represents schema version of Service gatherer
 	SchemaVersionOfServiceGatherer = "1.0"
+	// ExecutableTypeName represents the custom inventory type of the service executable paths
+	ExecutableTypeName = "Custom:ServiceExecutable"
 )
 
 type T struct{}
@@ -51,7 +53,8 @@ func (t *T) Run(context context.T, configuration model.Config) (items []model.It
 	currentTime := time.Now().UTC()
 	captureTime := currentTime.Format(time.RFC3339)
 	var data []model.ServiceData
-	data, err = collectData(context, configuration)
+	var executables []model.ServiceExecutableData
+	data, executables, err = collectData(context, configuration)
 
 	result = model.Item{
 		Name:          t.Name(),
@@ -61,6 +64,16 @@ func (t *T) Run(context context.T, configuration model.Config) (items []model.It
 	}
 
 	items = append(items, result)
+
+	// AWS:Service has a fixed schema, so the executable paths are reported in their own type
+	if len(executables) > 0 {
+		items = append(items, model.Item{
+			Name:          ExecutableTypeName,
+			SchemaVersion: SchemaVersionOfServiceGatherer,
+			Content:       executables,
+			CaptureTime:   captureTime,
+		})
+	}
 	return
 }
 
@@ -42,8 +42,19 @@ var testService = []model.ServiceData{
 	},
 }
 
-func testCollectServiceData(context context.T, config model.Config) (data []model.ServiceData, err error) {
-	return testService, nil
+var testServiceExecutables = []model.ServiceExecutableData{
+	{
+		Name:           "BrokerInfrastructure",
+		ExecutablePath: `C:\Windows\system32\svchost.exe -k DcomLaunch`,
+	},
+}
+
+func testCollectServiceData(context context.T, config model.Config) (data []model.ServiceData, executables []model.ServiceExecutableData, err error) {
+	return testService, testServiceExecutables, nil
+}
+
+func testCollectServiceDataWithoutExecutables(context context.T, config model.Config) (data []model.ServiceData, executables []model.ServiceExecutableData, err error) {
+	return testService, nil, nil
 }
 
 func TestGatherer(t *testing.T) {
@@ -52,8 +63,22 @@ func TestGatherer(t *testing.T) {
 	collectData = testCollectServiceData
 	item, err := gatherer.Run(contextMock, model.Config{})
 	assert.Nil(t, err)
-	assert.Equal(t, 1, len(item))
+	assert.Equal(t, 2, len(item))
 	assert.Equal(t, GathererName, item[0].Name)
 	assert.Equal(t, SchemaVersionOfServiceGatherer, item[0].SchemaVersion)
 	assert.Equal(t, testService, item[0].Content)
+	assert.Equal(t, ExecutableTypeName, item[1].Name)
+	assert.Equal(t, SchemaVersionOfServiceGatherer, item[1].SchemaVersion)
+	assert.Equal(t, testServiceExecutables, item[1].Content)
+}
+
+func TestGathererWithoutExecutables(t *testing.T) {
+	contextMock := context.NewMockDefault()
+	gatherer := Gatherer(contextMock)
+	collectData = testCollectServiceDataWithoutExecutables
+	item, err := gatherer.Run(contextMock, model.Config{})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(item))
+	assert.Equal(t, GathererName, item[0].Name)
+	assert.Equal(t, testService, item[0].Content)
 }
@@ -13,6 +13,7 @@
 package windowsUpdate
 
 import (
+	"fmt"
 	"testing"
 
 	"encoding/json"
@@ -37,27 +38,52 @@ var testUpdate = []model.WindowsUpdateData{
 	},
 }
 
-func testExecuteCommand(command string, args ...string) ([]byte, error) {
+var testRebootIndicators = `{"ComponentBasedServicing": false, "WindowsUpdate": true, "PendingFileRenameOperations": false, "PendingComputerRename": false}`
 
+func testExecuteCommand(command string, args ...string) ([]byte, error) {
+	if args[0] == pendingRebootQueryCmd {
+		return []byte(testRebootIndicators), nil
+	}
 	output, _ := json.Marshal(testUpdate)
 	return output, nil
 }
 
 func testExecuteCommandEmpty(command string, args ...string) ([]byte, error) {
-
+	if args[0] == pendingRebootQueryCmd {
+		return []byte(testRebootIndicators), nil
+	}
 	return make([]byte, 0), nil
 }
 
+func testExecuteCommandRebootError(command string, args ...string) ([]byte, error) {
+	if args[0] == pendingRebootQueryCmd {
+		return []byte("Access denied"), fmt.Errorf("exit status 1")
+	}
+	output, _ := json.Marshal(testUpdate)
+	return output, nil
+}
+
 func TestGatherer(t *testing.T) {
 	contextMock := context.NewMockDefault()
 	gatherer := Gatherer(contextMock)
 	cmdExecutor = testExecuteCommand
 	item, err := gatherer.Run(contextMock, model.Config{})
 	assert.Nil(t, err)
-	assert.Equal(t, 1, len(item))
+	assert.Equal(t, 2, len(item))
 	assert.Equal(t, GathererName, item[0].Name)
 	assert.Equal(t, schemaVersionOfWindowsUpdate, item[0].SchemaVersion)
 	assert.Equal(t, testUpdate, item[0].Content)
+	assert.Equal(t, PendingRebootTypeName, item[1].Name)
+	assert.Equal(t, schemaVersionOfPendingReboot, item[1].SchemaVersion)
+	assert.Equal(t, []model.PendingRebootData{
+		{
+			RebootPending:               "true",
+			ComponentBasedServicing:     "false",
+			WindowsUpdate:               "true",
+			PendingFileRenameOperations: "false",
+			PendingComputerRename:       "false",
+		},
+	}, item[1].Content)
 }
 
 func TestGathererEmpty(t *testing.T) {
@@ -67,8 +93,19 @@ func TestGathererEmpty(t *testing.T) {
 	var expectContent []model.WindowsUpdateData
 	item, err := gatherer.Run(contextMock, model.Config{})
 	assert.Nil(t, err)
-	assert.Equal(t, 1, len(item))
+	assert.Equal(t, 2, len(item))
 	assert.Equal(t, GathererName, item[0].Name)
 	assert.Equal(t, schemaVersionOfWindowsUpdate, item[0].SchemaVersion)
 	assert.Equal(t, expectContent, item[0].Content)
 }
+
+func TestGathererPendingRebootError(t *testing.T) {
+	contextMock := context.NewMockDefault()
+	gatherer := Gatherer(contextMock)
+	cmdExecutor = testExecuteCommandRebootError
+	item, err := gatherer.Run(contextMock, model.Config{})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(item))
+	assert.Equal(t, GathererName, item[0].Name)
+	assert.Equal(t, testUpdate, item[0].Content)
+}
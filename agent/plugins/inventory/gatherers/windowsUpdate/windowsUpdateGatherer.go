@@ -15,7 +15,9 @@ package windowsUpdate
 
 import (
 	"encoding/json"
+	"fmt"
 	"os/exec"
+	"strconv"
 	"time"
 
 	"github.com/aws/amazon-ssm-agent/agent/context"
@@ -26,14 +28,36 @@ import (
 const (
 	// GathererName represents name of windows update gatherer
 	GathererName = "AWS:WindowsUpdate"
+	// PendingRebootTypeName represents name of the pending reboot inventory type reported along the windows updates
+	PendingRebootTypeName = "Custom:PendingReboot"
 
 	schemaVersionOfWindowsUpdate = "1.0"
+	schemaVersionOfPendingReboot = "1.0"
 	cmd                          = "powershell"
 	windowsUpdateQueryCmd        = `
   [Console]::OutputEncoding = [System.Text.Encoding]::UTF8
   Get-WmiObject -Class win32_quickfixengineering | Select-Object HotFixId,Description,@{l="InstalledTime";e={[DateTime]::Parse($_.psbase.properties["installedon"].value,$([System.Globalization.CultureInfo]::GetCultureInfo("en-US"))).ToUniversalTime().ToString("yyyy-MM-ddTHH:mm:ssZ")}},InstalledBy | sort InstalledTime -desc | ConvertTo-Json`
+	pendingRebootQueryCmd = `
+  [Console]::OutputEncoding = [System.Text.Encoding]::UTF8
+  $sessionManager = Get-ItemProperty 'HKLM:\SYSTEM\CurrentControlSet\Control\Session Manager' -ErrorAction SilentlyContinue
+  $activeName = (Get-ItemProperty 'HKLM:\SYSTEM\CurrentControlSet\Control\ComputerName\ActiveComputerName' -ErrorAction SilentlyContinue).ComputerName
+  $pendingName = (Get-ItemProperty 'HKLM:\SYSTEM\CurrentControlSet\Control\ComputerName\ComputerName' -ErrorAction SilentlyContinue).ComputerName
+  New-Object PSObject -Property @{
+    ComponentBasedServicing=(Test-Path 'HKLM:\SOFTWARE\Microsoft\Windows\CurrentVersion\Component Based Servicing\RebootPending');
+    WindowsUpdate=(Test-Path 'HKLM:\SOFTWARE\Microsoft\Windows\CurrentVersion\WindowsUpdate\Auto Update\RebootRequired');
+    PendingFileRenameOperations=($sessionManager.PendingFileRenameOperations -ne $null);
+    PendingComputerRename=($activeName -ne $pendingName)
+  } | ConvertTo-Json`
 )
 
+// rebootIndicators are the pending reboot indicators returned by pendingRebootQueryCmd
+type rebootIndicators struct {
+	ComponentBasedServicing     bool
+	WindowsUpdate               bool
+	PendingFileRenameOperations bool
+	PendingComputerRename       bool
+}
+
 // T represents windows update gatherer
 type T struct{}
 
@@ -77,6 +101,41 @@ func (t *T) Run(context context.T, configuration model.Config) (items []model.It
 		log.Errorf("Unable to fetch windows update - %v %v", err.Error(), string(out))
 	}
 	items = append(items, result)
+
+	// the pending reboot indicators tell whether the installed updates are effective yet,
+	// a failure to read them doesn't fail the windows update inventory
+	if pendingReboot, rebootErr := collectPendingReboot(); rebootErr == nil {
+		items = append(items, model.Item{
+			Name:          PendingRebootTypeName,
+			SchemaVersion: schemaVersionOfPendingReboot,
+			Content:       []model.PendingRebootData{pendingReboot},
+			CaptureTime:   time.Now().UTC().Format(time.RFC3339),
+		})
+		log.Infof("Pending reboot = %v", pendingReboot.RebootPending)
+	} else {
+		log.Errorf("Unable to fetch pending reboot indicators - %v", rebootErr)
+	}
+	return
+}
+
+// collectPendingReboot reads the pending reboot indicators, the reboot is pending if any of them is set
+func collectPendingReboot() (data model.PendingRebootData, err error) {
+	var out []byte
+	var indicators rebootIndicators
+	if out, err = cmdExecutor(cmd, pendingRebootQueryCmd); err != nil {
+		return data, fmt.Errorf("%v %v", err.Error(), string(out))
+	}
+	if err = json.Unmarshal(out, &indicators); err != nil {
+		return
+	}
+	data = model.PendingRebootData{
+		RebootPending: strconv.FormatBool(indicators.ComponentBasedServicing || indicators.WindowsUpdate ||
+			indicators.PendingFileRenameOperations || indicators.PendingComputerRename),
+		ComponentBasedServicing:     strconv.FormatBool(indicators.ComponentBasedServicing),
+		WindowsUpdate:               strconv.FormatBool(indicators.WindowsUpdate),
+		PendingFileRenameOperations: strconv.FormatBool(indicators.PendingFileRenameOperations),
+		PendingComputerRename:       strconv.FormatBool(indicators.PendingComputerRename),
+	}
 	return
 }
 
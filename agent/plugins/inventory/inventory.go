@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/association/control"
 	"github.com/aws/amazon-ssm-agent/agent/context"
 	"github.com/aws/amazon-ssm-agent/agent/contracts"
 	"github.com/aws/amazon-ssm-agent/agent/fileutil"
@@ -33,9 +34,12 @@ import (
 	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/gatherers"
 	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/gatherers/application"
 	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/gatherers/awscomponent"
+	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/gatherers/certificate"
+	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/gatherers/container"
 	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/gatherers/custom"
 	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/gatherers/file"
 	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/gatherers/instancedetailedinformation"
+	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/gatherers/license"
 	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/gatherers/network"
 	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/gatherers/registry"
 	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/gatherers/role"
@@ -51,15 +55,19 @@ import (
 // TODO: add more unit tests.
 
 const (
-	errorMsgForMultipleAssociations           = "%v detected multiple inventory configurations associated with one instance. Each instance can be associated with just one inventory configuration. Conflicting inventory configuration IDs: %v and %v"
-	errorMsgForInvalidInventoryInput          = "invalid or unrecognized input was received for %v plugin"
-	errorMsgForExecutingInventoryViaAssociate = "%v plugin can only be invoked via ssm-associate"
-	errorMsgForUnableToDetectInvocationType   = "it could not be detected if %v plugin was invoked via ssm-associate because - %v"
-	errorMsgForInabilityToSendDataToSSM       = "inventory data could not be uploaded to Systems Manager. Additional troubleshooting information - %v"
-	msgWhenNoDataToReturnForInventoryPlugin   = "Inventory policy has been successfully applied but there is no inventory data to upload to SSM"
-	successfulMsgForInventoryPlugin           = "Inventory policy has been successfully applied and collected inventory data has been uploaded to SSM"
+	errorMsgForMultipleAssociations         = "%v detected multiple inventory configurations associated with one instance. Each instance can be associated with just one inventory configuration. Conflicting inventory configuration IDs: %v and %v"
+	errorMsgForInvalidInventoryInput        = "invalid or unrecognized input was received for %v plugin"
+	errorMsgForOnDemandCollection           = "%v could not collect inventory on demand because - %v"
+	errorMsgForUnableToDetectInvocationType = "it could not be detected if %v plugin was invoked via ssm-associate because - %v"
+	errorMsgForInabilityToSendDataToSSM     = "inventory data could not be uploaded to Systems Manager. Additional troubleshooting information - %v"
+	msgWhenNoDataToReturnForInventoryPlugin = "Inventory policy has been successfully applied but there is no inventory data to upload to SSM"
+	successfulMsgForInventoryPlugin         = "Inventory policy has been successfully applied and collected inventory data has been uploaded to SSM"
+	msgWhenInventoryUploadDisabled          = "Inventory policy has been successfully applied and collected inventory data has been written to the inventory outputs, upload to SSM is disabled"
 )
 
+// onDemandCollectionTimeout is the time a command waits for the inventory association it runs on demand
+const onDemandCollectionTimeout = 10 * time.Minute
+
 // PluginInput represents configuration which is applied to inventory plugin during execution.
 type PluginInput struct {
 	contracts.PluginInput
@@ -74,11 +82,18 @@ type PluginInput struct {
 	InstanceDetailedInformation string
 	CustomInventory             string
 	CustomInventoryDirectory    string
+	// GathererIntervals lists the minimum interval in minutes between two collections of gatherers
+	// as comma separated name=minutes pairs, such as AWS:Network=1440
+	GathererIntervals string
 }
 
 // decoupling platform.InstanceID for easy testability
 var machineIDProvider = machineInfoProvider
 
+// requestInventoryRun and waitForInventoryRun are assigned to variables to allow unit tests to override them
+var requestInventoryRun = control.RequestRun
+var waitForInventoryRun = control.WaitForRun
+
 func machineInfoProvider() (name string, err error) {
 	return platform.InstanceID()
 }
@@ -144,6 +159,7 @@ func (p *Plugin) ApplyInventoryPolicy(context context.T, inventoryInput PluginIn
 
 	//map of all valid gatherers & respective configs to run
 	var gatherers map[gatherers.T]model.Config
+	var intervals map[string]time.Duration
 
 	//validate all gatherers
 	if gatherers, err = p.ValidateInventoryInput(context, inventoryInput); err != nil {
@@ -153,6 +169,16 @@ func (p *Plugin) ApplyInventoryPolicy(context context.T, inventoryInput PluginIn
 		return
 	}
 
+	//skip the gatherers which collected within their interval
+	if intervals, err = gathererIntervals(p.context.AppConfig().Ssm.InventoryGathererIntervalMinutes, inventoryInput.GathererIntervals); err != nil {
+		log.Info(err.Error())
+		output.SetExitCode(1)
+		output.AppendError(err.Error())
+		return
+	}
+	collectionTime := time.Now()
+	gatherers = dueGatherers(log, gatherers, intervals, loadLastCollections(log, p.machineID), collectionTime)
+
 	//execute all eligible gatherers with their respective config
 	if items, err = p.RunGatherers(gatherers); err != nil {
 		log.Info(err.Error())
@@ -165,6 +191,7 @@ func (p *Plugin) ApplyInventoryPolicy(context context.T, inventoryInput PluginIn
 	if len(items) == 0 {
 		//no data to send to ssm - no need to call PutInventory API
 		log.Info(msgWhenNoDataToReturnForInventoryPlugin)
+		recordCollections(log, p.machineID, gatherers, collectionTime)
 		output.SetExitCode(0)
 		output.AppendInfo(msgWhenNoDataToReturnForInventoryPlugin)
 		return
@@ -174,6 +201,23 @@ func (p *Plugin) ApplyInventoryPolicy(context context.T, inventoryInput PluginIn
 	d, _ := json.Marshal(items)
 	log.Debugf("Collected Inventory data: %v", string(d))
 
+	//write collected data to the inventory outputs, in addition to or instead of sending it to SSM
+	ssmConfig := p.context.AppConfig().Ssm
+	outputErr := writeInventoryOutput(log, ssmConfig, p.machineID, items, collectionTime)
+	if ssmConfig.InventoryUploadDisabled {
+		if outputErr != nil {
+			log.Info(outputErr.Error())
+			output.SetExitCode(1)
+			output.AppendError(outputErr.Error())
+			return
+		}
+		log.Infof("%v wrote inventory data to the inventory outputs", Name())
+		recordCollections(log, p.machineID, gatherers, collectionTime)
+		output.SetExitCode(0)
+		output.AppendInfo(msgWhenInventoryUploadDisabled)
+		return
+	}
+
 	if optimizedInventoryItems, nonOptimizedInventoryItems, err = p.uploader.ConvertToSsmInventoryItems(p.context, items); err != nil {
 		log.Infof("Encountered error in converting data to SSM InventoryItems - %v. Skipping upload to SSM", err.Error())
 		output.SetExitCode(1)
@@ -203,9 +247,17 @@ func (p *Plugin) ApplyInventoryPolicy(context context.T, inventoryInput PluginIn
 	}
 
 	log.Infof("%v uploaded inventory data to SSM", Name())
+	recordCollections(log, p.machineID, gatherers, collectionTime)
 	output.SetExitCode(0)
 	output.AppendInfo(successfulMsgForInventoryPlugin)
 
+	//the upload succeeded but the inventory outputs are missing the data
+	if outputErr != nil {
+		log.Info(outputErr.Error())
+		output.SetExitCode(1)
+		output.AppendError(outputErr.Error())
+	}
+
 	return
 }
 
@@ -382,6 +434,10 @@ func (p *Plugin) ValidateInventoryInput(context context.T, input PluginInput) (c
 		network.GathererName:                     input.NetworkConfig,
 		windowsUpdate.GathererName:               input.WindowsUpdates,
 		instancedetailedinformation.GathererName: input.InstanceDetailedInformation,
+		// the containers, the license and the certificates are custom inventory types collected along the custom inventory
+		container.GathererName:   input.CustomInventory,
+		license.GathererName:     input.CustomInventory,
+		certificate.GathererName: input.CustomInventory,
 	}
 
 	predefinedGatherersWithFilters := map[string]string{
@@ -419,6 +475,16 @@ func (p *Plugin) ValidateInventoryInput(context context.T, input PluginInput) (c
 		configuredGatherers[gatherer] = cfg
 	}
 
+	//the gatherers of custom inventory types run along the custom gatherer
+	for _, gathererName := range gatherers.CustomGathererNames(context) {
+		if canGathererRun, gatherer, cfg, err = p.validatePredefinedGatherer(context, input.CustomInventory, gathererName); err != nil {
+			log.Errorf("Error while validating gatherer %v", err.Error())
+			return
+		} else if canGathererRun {
+			configuredGatherers[gatherer] = cfg
+		}
+	}
+
 	return
 }
 
@@ -545,6 +611,48 @@ func (p *Plugin) IsInventoryBeingInvokedAsAssociation(fileName string) (status b
 	return
 }
 
+// CollectOnDemand runs the inventory association of the instance immediately, outside of its schedule,
+// and reports the result of the collection and upload in the plugin output.
+func (p *Plugin) CollectOnDemand(output iohandler.IOHandler) {
+	log := p.context.Log()
+	log.Infof("%v plugin was invoked by a command, running the inventory association on demand", Name())
+
+	requested := time.Now()
+	if err := requestInventoryRun(p.machineID, control.InventoryRunRequest); err != nil {
+		p.failOnDemandCollection(output, err)
+		return
+	}
+
+	execution, err := waitForInventoryRun(p.machineID, control.InventoryRunRequest, requested, onDemandCollectionTimeout)
+	if err != nil {
+		p.failOnDemandCollection(output, err)
+		return
+	}
+
+	output.AppendInfof("Inventory association %v completed with status %v", execution.AssociationID, execution.Status)
+	for _, plugin := range execution.PluginResults {
+		if plugin.PluginName != appconfig.PluginNameAwsSoftwareInventory {
+			continue
+		}
+		if plugin.Error != "" {
+			output.AppendError(plugin.Error)
+		}
+		output.SetExitCode(plugin.Code)
+		output.SetStatus(contracts.ResultStatus(plugin.Status))
+		return
+	}
+	output.SetStatus(contracts.ResultStatus(execution.Status))
+}
+
+// failOnDemandCollection reports the on demand collection failed with the given error
+func (p *Plugin) failOnDemandCollection(output iohandler.IOHandler, err error) {
+	errorMsg := fmt.Sprintf(errorMsgForOnDemandCollection, Name(), err)
+	p.context.Log().Error(errorMsg)
+	output.SetExitCode(1)
+	output.SetStatus(contracts.ResultStatusFailed)
+	output.AppendError(errorMsg)
+}
+
 // ParseAssociationIdFromFileName parses associationID from the given input
 // NOTE: Input will be of format - AssociationID.RunID -> as per the format of bookkeepingfilename for associate documents
 func (p *Plugin) ParseAssociationIdFromFileName(input string) string {
@@ -571,15 +679,9 @@ func (p *Plugin) Execute(context context.T, config contracts.Configuration, canc
 
 	associationID = p.ParseAssociationIdFromFileName(config.BookKeepingFileName)
 
-	// Check if the inventory plugin is being invoked as association, if not or if detection fails for some reason,
-	// then fail association - because inventory plugin currently supports invocation via ssm associate only.
-	if isAssociation, err = p.IsInventoryBeingInvokedAsAssociation(config.BookKeepingFileName); err != nil || !isAssociation {
-		if err != nil {
-			errorMsg = fmt.Sprintf(errorMsgForUnableToDetectInvocationType, pluginName, err.Error())
-		} else {
-			errorMsg = fmt.Sprintf(errorMsgForExecutingInventoryViaAssociate, pluginName)
-		}
-
+	// Check if the inventory plugin is being invoked as association, fail if detection fails for some reason.
+	if isAssociation, err = p.IsInventoryBeingInvokedAsAssociation(config.BookKeepingFileName); err != nil {
+		errorMsg = fmt.Sprintf(errorMsgForUnableToDetectInvocationType, pluginName, err.Error())
 		log.Error(errorMsg)
 
 		//setting up plugin output
@@ -589,6 +691,12 @@ func (p *Plugin) Execute(context context.T, config contracts.Configuration, canc
 		return
 	}
 
+	// Inventory is collected by the inventory association only, a command runs the association on demand instead.
+	if !isAssociation {
+		p.CollectOnDemand(output)
+		return
+	}
+
 	log.Debugf("%v plugin is being invoked via ssm-associate - proceeding ahead with execution", pluginName)
 
 	// Check if there exists multiple associations for software inventory plugin, if so - then fail association - because
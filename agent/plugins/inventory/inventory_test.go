@@ -18,9 +18,14 @@ import (
 	"bytes"
 	"fmt"
 	"testing"
+	"time"
 
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/association/control"
+	"github.com/aws/amazon-ssm-agent/agent/association/recorder"
 	"github.com/aws/amazon-ssm-agent/agent/context"
 	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler"
 	"github.com/aws/amazon-ssm-agent/agent/log"
 	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/gatherers"
 	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/model"
@@ -219,3 +224,45 @@ func TestShouldRetryWithNonOptimizedData(t *testing.T) {
 		assert.Equal(t, testCase.shouldRetry, shouldRetryWithNonOptimizedData(testCase.err, log))
 	}
 }
+
+func TestCollectOnDemand(t *testing.T) {
+	defer func() {
+		requestInventoryRun = control.RequestRun
+		waitForInventoryRun = control.WaitForRun
+	}()
+	p, _ := MockInventoryPlugin(nil, nil)
+	p.machineID = "i-1234567890"
+
+	var requested []string
+	requestInventoryRun = func(instanceID string, associationID string) error {
+		requested = append(requested, associationID)
+		return nil
+	}
+	waitForInventoryRun = func(instanceID string, associationID string, since time.Time, timeout time.Duration) (recorder.AssociationExecution, error) {
+		return recorder.AssociationExecution{
+			AssociationID: "b2f71a28-cbe1-4429-b848-26c7e1f5ad0d",
+			Status:        string(contracts.ResultStatusFailed),
+			PluginResults: []recorder.PluginExecution{{
+				PluginName: appconfig.PluginNameAwsSoftwareInventory,
+				Status:     string(contracts.ResultStatusFailed),
+				Code:       1,
+				Error:      "upload failed",
+			}},
+		}, nil
+	}
+
+	output := iohandler.DefaultIOHandler{}
+	p.CollectOnDemand(&output)
+	assert.Equal(t, []string{control.InventoryRunRequest}, requested)
+	assert.Equal(t, contracts.ResultStatusFailed, output.GetStatus())
+	assert.Equal(t, 1, output.GetExitCode())
+	assert.Contains(t, output.GetStderr(), "upload failed")
+
+	waitForInventoryRun = func(instanceID string, associationID string, since time.Time, timeout time.Duration) (recorder.AssociationExecution, error) {
+		return recorder.AssociationExecution{}, fmt.Errorf("timed out")
+	}
+	output = iohandler.DefaultIOHandler{}
+	p.CollectOnDemand(&output)
+	assert.Equal(t, contracts.ResultStatusFailed, output.GetStatus())
+	assert.Contains(t, output.GetStderr(), "timed out")
+}
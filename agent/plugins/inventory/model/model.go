@@ -106,6 +106,14 @@ type FileData struct {
 	ProductLanguage  string
 }
 
+// FileHashData captures all attributes present in Custom:FileHash inventory type
+type FileHashData struct {
+	Path             string
+	Size             string
+	ModificationTime string
+	Sha256           string
+}
+
 type RoleData struct {
 	Name                      string
 	DisplayName               string
@@ -130,6 +138,12 @@ type ServiceData struct {
 	StartType          string
 }
 
+// ServiceExecutableData captures all attributes present in Custom:ServiceExecutable inventory type
+type ServiceExecutableData struct {
+	Name           string
+	ExecutablePath string
+}
+
 type RegistryData struct {
 	ValueName string
 	ValueType string
@@ -137,6 +151,63 @@ type RegistryData struct {
 	Value     string
 }
 
+// NetworkRouteData captures all attributes present in Custom:NetworkRoute inventory type
+type NetworkRouteData struct {
+	Interface   string
+	Destination string
+	Gateway     string
+	Metric      string
+}
+
+// ListeningPortData captures all attributes present in Custom:ListeningPort inventory type
+type ListeningPortData struct {
+	Protocol    string
+	Address     string
+	Port        string
+	ProcessId   string
+	ProcessName string
+}
+
+// LicenseData captures all attributes present in Custom:License inventory type
+type LicenseData struct {
+	BillingProducts         string
+	MarketplaceProductCodes string
+	LicenseType             string
+	ActivationStatus        string
+}
+
+// ContainerData captures all attributes present in Custom:Container inventory type
+type ContainerData struct {
+	ContainerId string
+	Name        string
+	Image       string
+	Tag         string
+	Digest      string
+	State       string
+	Ports       string
+}
+
+// ContainerImageData captures all attributes present in Custom:ContainerImage inventory type
+type ContainerImageData struct {
+	ImageId    string
+	Repository string
+	Tag        string
+	Digest     string
+	Size       string
+}
+
+// CertificateData captures all attributes present in Custom:Certificate inventory type
+type CertificateData struct {
+	Subject                 string
+	Issuer                  string
+	SerialNumber            string
+	SubjectAlternativeNames string
+	NotBefore               string
+	NotAfter                string
+	Thumbprint              string
+	Location                string
+}
+
 // NetworkData captures all attributes present in AWS:Network inventory type
 type NetworkData struct {
 	Name       string
@@ -158,6 +229,15 @@ type WindowsUpdateData struct {
 	InstalledBy   string
 }
 
+// PendingRebootData captures all attributes present in Custom:PendingReboot inventory type
+type PendingRebootData struct {
+	RebootPending               string
+	ComponentBasedServicing     string
+	WindowsUpdate               string
+	PendingFileRenameOperations string
+	PendingComputerRename       string
+}
+
 // InstanceDetailedInformation captures all attributes present in AWS:InstanceDetailedInformation inventory type
 type InstanceDetailedInformation struct {
 	CPUModel              string
@@ -0,0 +1,130 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package inventory contains routines that periodically updates basic instance inventory to Inventory service
+package inventory
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/fileutil"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/model"
+	"github.com/aws/amazon-ssm-agent/agent/s3util"
+	"github.com/aws/amazon-ssm-agent/agent/times"
+)
+
+// inventoryOutput is the content of the inventory written to the outputs
+type inventoryOutput struct {
+	InstanceId  string
+	CaptureTime string
+	Items       []model.Item
+}
+
+// s3Upload is assigned to a variable to allow unit tests to override it
+var s3Upload = func(log log.T, bucketName string, objectKey string, filePath string) error {
+	return s3util.NewAmazonS3Util(log, bucketName).S3Upload(log, bucketName, objectKey, filePath)
+}
+
+// hasInventoryOutput returns true if the collected inventory is written to a directory or a bucket
+func hasInventoryOutput(config appconfig.SsmCfg) bool {
+	return config.InventoryOutputDirectory != "" || config.InventoryOutputS3BucketName != ""
+}
+
+// writeInventoryOutput writes the collected inventory as JSON to the output directory and uploads it to the output bucket.
+// The file is only kept in the output directory, without output directory it is written to a temporary directory for the upload.
+// It fails when the upload to SSM is disabled and there is no output, since the inventory would go nowhere.
+func writeInventoryOutput(log log.T, config appconfig.SsmCfg, machineID string, items []model.Item, captureTime time.Time) (err error) {
+	if !hasInventoryOutput(config) {
+		if config.InventoryUploadDisabled {
+			return fmt.Errorf("inventory upload to SSM is disabled and no inventory output directory or S3 bucket is configured")
+		}
+		return nil
+	}
+
+	dataB, err := json.Marshal(inventoryOutput{
+		InstanceId:  machineID,
+		CaptureTime: captureTime.UTC().Format(time.RFC3339),
+		Items:       items,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to marshal the inventory output because - %v", err)
+	}
+
+	directory := config.InventoryOutputDirectory
+	if directory == "" {
+		if directory, err = ioutil.TempDir("", "inventory"); err != nil {
+			return fmt.Errorf("unable to create the inventory output directory because - %v", err)
+		}
+		defer os.RemoveAll(directory)
+	} else if err = fileutil.MakeDirs(directory); err != nil {
+		return fmt.Errorf("unable to create the inventory output directory %v because - %v", directory, err)
+	}
+
+	fileName := fmt.Sprintf("%v-%v.json", machineID, times.ToIsoDashUTC(captureTime))
+	filePath := filepath.Join(directory, fileName)
+	if _, err = fileutil.WriteIntoFileWithPermissions(filePath, string(dataB), appconfig.ReadWriteAccess); err != nil {
+		return fmt.Errorf("unable to write the inventory output %v because - %v", filePath, err)
+	}
+	log.Infof("Inventory written to %v", filePath)
+	if config.InventoryOutputDirectory != "" {
+		pruneInventoryOutput(log, directory, machineID, config.InventoryOutputMaxFiles)
+	}
+
+	if config.InventoryOutputS3BucketName != "" {
+		objectKey := path.Join(config.InventoryOutputS3KeyPrefix, fileName)
+		if err = s3Upload(log, config.InventoryOutputS3BucketName, objectKey, filePath); err != nil {
+			return fmt.Errorf("unable to upload the inventory output to s3://%v/%v because - %v", config.InventoryOutputS3BucketName, objectKey, err)
+		}
+	}
+	return nil
+}
+
+// pruneInventoryOutput deletes the oldest inventory files of the instance in the output directory beyond maxFiles,
+// 0 keeps them all. The capture time in the file names sorts in chronological order.
+func pruneInventoryOutput(log log.T, directory string, machineID string, maxFiles int) {
+	if maxFiles <= 0 {
+		return
+	}
+	fileNames, err := fileutil.GetFileNames(directory)
+	if err != nil {
+		log.Warnf("unable to list the inventory output directory %v because - %v", directory, err)
+		return
+	}
+
+	var outputs []string
+	for _, fileName := range fileNames {
+		if strings.HasPrefix(fileName, machineID+"-") && strings.HasSuffix(fileName, ".json") {
+			outputs = append(outputs, fileName)
+		}
+	}
+	if len(outputs) <= maxFiles {
+		return
+	}
+	sort.Strings(outputs)
+	for _, fileName := range outputs[:len(outputs)-maxFiles] {
+		log.Debugf("Deleting inventory output %v", fileName)
+		if err = fileutil.DeleteFile(filepath.Join(directory, fileName)); err != nil {
+			log.Warnf("unable to delete the inventory output %v because - %v", fileName, err)
+		}
+	}
+}
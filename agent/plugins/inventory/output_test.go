@@ -0,0 +1,99 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package inventory contains routines that periodically updates basic instance inventory to Inventory service
+package inventory
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/fileutil"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteInventoryOutputToDirectory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "inventory")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	captureTime := time.Date(2018, 1, 1, 10, 0, 0, 0, time.UTC)
+	config := appconfig.SsmCfg{InventoryOutputDirectory: filepath.Join(dir, "output")}
+	assert.NoError(t, writeInventoryOutput(log.NewMockLog(), config, "i-1234567890", MockInventoryItems(), captureTime))
+
+	dataB, err := ioutil.ReadFile(filepath.Join(dir, "output", "i-1234567890-2018-01-01T10-00-00.000Z.json"))
+	assert.NoError(t, err)
+	var written inventoryOutput
+	assert.NoError(t, json.Unmarshal(dataB, &written))
+	assert.Equal(t, "i-1234567890", written.InstanceId)
+	assert.Equal(t, "2018-01-01T10:00:00Z", written.CaptureTime)
+	assert.Equal(t, "Fake:Name", written.Items[0].Name)
+}
+
+func TestWriteInventoryOutputToBucket(t *testing.T) {
+	defer func(original func(log.T, string, string, string) error) { s3Upload = original }(s3Upload)
+	var uploadedKey, uploadedPath string
+	s3Upload = func(log log.T, bucketName string, objectKey string, filePath string) error {
+		assert.Equal(t, "inventory-bucket", bucketName)
+		assert.True(t, fileutil.Exists(filePath))
+		uploadedKey, uploadedPath = objectKey, filePath
+		return nil
+	}
+
+	captureTime := time.Date(2018, 1, 1, 10, 0, 0, 0, time.UTC)
+	config := appconfig.SsmCfg{InventoryOutputS3BucketName: "inventory-bucket", InventoryOutputS3KeyPrefix: "fleet"}
+	assert.NoError(t, writeInventoryOutput(log.NewMockLog(), config, "i-1234567890", MockInventoryItems(), captureTime))
+
+	assert.Equal(t, "fleet/i-1234567890-2018-01-01T10-00-00.000Z.json", uploadedKey)
+	// without output directory the file is only kept for the upload
+	assert.False(t, fileutil.Exists(uploadedPath))
+}
+
+func TestWriteInventoryOutputWithoutOutputs(t *testing.T) {
+	assert.NoError(t, writeInventoryOutput(log.NewMockLog(), appconfig.SsmCfg{}, "i-1234567890", MockInventoryItems(), time.Now()))
+
+	// the inventory would go nowhere
+	err := writeInventoryOutput(log.NewMockLog(), appconfig.SsmCfg{InventoryUploadDisabled: true}, "i-1234567890", MockInventoryItems(), time.Now())
+	assert.Error(t, err)
+}
+
+func TestWriteInventoryOutputKeepsMaxFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "inventory")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// the files of other instances and other files are left alone
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "i-0987654321-2017-01-01T10-00-00.000Z.json"), []byte("{}"), 0644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "i-1234567890.txt"), []byte(""), 0644))
+
+	config := appconfig.SsmCfg{InventoryOutputDirectory: dir, InventoryOutputMaxFiles: 2}
+	captureTime := time.Date(2018, 1, 1, 10, 0, 0, 0, time.UTC)
+	for hour := 0; hour < 4; hour++ {
+		assert.NoError(t, writeInventoryOutput(log.NewMockLog(), config, "i-1234567890", MockInventoryItems(), captureTime.Add(time.Duration(hour)*time.Hour)))
+	}
+
+	fileNames, err := fileutil.GetFileNames(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"i-0987654321-2017-01-01T10-00-00.000Z.json",
+		"i-1234567890-2018-01-01T12-00-00.000Z.json",
+		"i-1234567890-2018-01-01T13-00-00.000Z.json",
+		"i-1234567890.txt",
+	}, fileNames)
+}
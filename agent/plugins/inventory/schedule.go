@@ -0,0 +1,114 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package inventory contains routines that periodically updates basic instance inventory to Inventory service
+package inventory
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/fileutil"
+	"github.com/aws/amazon-ssm-agent/agent/jsonutil"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/gatherers"
+	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/model"
+)
+
+// collectionsLocation is assigned to a variable to allow unit tests to override it
+var collectionsLocation = func(machineID string) string {
+	return filepath.Join(appconfig.DefaultDataStorePath,
+		machineID,
+		appconfig.InventoryRootDirName,
+		appconfig.InventoryCollectionFileName)
+}
+
+// gathererIntervals returns the minimum interval between two collections of the gatherers, by gatherer name.
+// The intervals of the inventory policy override the intervals of the agent configuration.
+func gathererIntervals(configured map[string]int, policy string) (intervals map[string]time.Duration, err error) {
+	intervals = make(map[string]time.Duration)
+	for name, minutes := range configured {
+		if minutes > 0 {
+			intervals[name] = time.Duration(minutes) * time.Minute
+		}
+	}
+
+	// the policy lists the intervals in minutes as comma separated name=minutes pairs, such as AWS:Network=1440
+	for _, pair := range strings.Split(policy, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid gatherer interval %v, expected name=minutes", pair)
+		}
+		minutes, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil || minutes < 0 {
+			return nil, fmt.Errorf("invalid gatherer interval %v, minutes should be a non-negative integer", pair)
+		}
+		intervals[strings.TrimSpace(parts[0])] = time.Duration(minutes) * time.Minute
+	}
+	return intervals, nil
+}
+
+// dueGatherers returns the gatherers whose interval elapsed since their last collection, gatherers without interval are always due
+func dueGatherers(log log.T, configured map[gatherers.T]model.Config, intervals map[string]time.Duration, lastCollections map[string]time.Time, now time.Time) map[gatherers.T]model.Config {
+	due := make(map[gatherers.T]model.Config)
+	for gatherer, config := range configured {
+		name := gatherer.Name()
+		if interval, ok := intervals[name]; ok && now.Sub(lastCollections[name]) < interval {
+			log.Infof("Skipping gatherer %v, it collected at %v and collects every %v", name, lastCollections[name].Format(time.RFC3339), interval)
+			continue
+		}
+		due[gatherer] = config
+	}
+	return due
+}
+
+// loadLastCollections returns when the gatherers last collected inventory that was uploaded, by gatherer name
+func loadLastCollections(log log.T, machineID string) map[string]time.Time {
+	lastCollections := make(map[string]time.Time)
+	location := collectionsLocation(machineID)
+	if !fileutil.Exists(location) {
+		return lastCollections
+	}
+
+	if err := jsonutil.UnmarshalFile(location, &lastCollections); err != nil {
+		log.Debugf("Unable to read the last collections of the inventory gatherers - thereby ignoring any older values, %v", err)
+		return make(map[string]time.Time)
+	}
+	return lastCollections
+}
+
+// recordCollections records the gatherers collected inventory that was uploaded at collectionTime
+func recordCollections(log log.T, machineID string, collected map[gatherers.T]model.Config, collectionTime time.Time) {
+	lastCollections := loadLastCollections(log, machineID)
+	for gatherer := range collected {
+		lastCollections[gatherer.Name()] = collectionTime.UTC()
+	}
+
+	dataB, _ := json.Marshal(lastCollections)
+	location := collectionsLocation(machineID)
+	if err := fileutil.MakeDirs(filepath.Dir(location)); err != nil {
+		log.Errorf("Unable to record the last collections of the inventory gatherers because - %v", err)
+		return
+	}
+	if _, err := fileutil.WriteIntoFileWithPermissions(location, string(dataB), appconfig.ReadWriteAccess); err != nil {
+		log.Errorf("Unable to record the last collections of the inventory gatherers because - %v", err)
+	}
+}
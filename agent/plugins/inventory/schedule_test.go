@@ -0,0 +1,96 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package inventory contains routines that periodically updates basic instance inventory to Inventory service
+package inventory
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/gatherers"
+	"github.com/aws/amazon-ssm-agent/agent/plugins/inventory/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func namedGatherer(name string) *gatherers.Mock {
+	gatherer := gatherers.NewMockDefault()
+	gatherer.On("Name").Return(name)
+	return gatherer
+}
+
+func TestGathererIntervals(t *testing.T) {
+	configured := map[string]int{"AWS:Application": 60, "AWS:Network": 1440, "AWS:Service": 0}
+
+	intervals, err := gathererIntervals(configured, "")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]time.Duration{"AWS:Application": time.Hour, "AWS:Network": 24 * time.Hour}, intervals)
+
+	// the policy overrides the agent configuration
+	intervals, err = gathererIntervals(configured, "AWS:Network = 720, AWS:File=30,")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]time.Duration{"AWS:Application": time.Hour, "AWS:Network": 12 * time.Hour, "AWS:File": 30 * time.Minute}, intervals)
+
+	_, err = gathererIntervals(nil, "AWS:Network")
+	assert.Error(t, err)
+	_, err = gathererIntervals(nil, "AWS:Network=daily")
+	assert.Error(t, err)
+}
+
+func TestDueGatherers(t *testing.T) {
+	now := time.Now()
+	application := namedGatherer("AWS:Application")
+	network := namedGatherer("AWS:Network")
+	service := namedGatherer("AWS:Service")
+	configured := map[gatherers.T]model.Config{
+		application: {Collection: "Enabled"},
+		network:     {Collection: "Enabled"},
+		service:     {Collection: "Enabled"},
+	}
+	intervals := map[string]time.Duration{"AWS:Application": time.Hour, "AWS:Network": 24 * time.Hour}
+	lastCollections := map[string]time.Time{
+		"AWS:Application": now.Add(-2 * time.Hour),
+		"AWS:Network":     now.Add(-2 * time.Hour),
+		"AWS:Service":     now.Add(-time.Minute),
+	}
+
+	due := dueGatherers(log.NewMockLog(), configured, intervals, lastCollections, now)
+	assert.Equal(t, 2, len(due))
+	assert.Contains(t, due, gatherers.T(application))
+	assert.Contains(t, due, gatherers.T(service))
+}
+
+func TestRecordCollections(t *testing.T) {
+	dir, err := ioutil.TempDir("", "inventory")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	defer func(original func(string) string) { collectionsLocation = original }(collectionsLocation)
+	collectionsLocation = func(machineID string) string {
+		return filepath.Join(dir, machineID, "lastCollection")
+	}
+
+	logger := log.NewMockLog()
+	assert.Empty(t, loadLastCollections(logger, "i-1234567890"))
+
+	collectionTime := time.Date(2018, 1, 1, 10, 0, 0, 0, time.UTC)
+	recordCollections(logger, "i-1234567890", map[gatherers.T]model.Config{namedGatherer("AWS:Network"): {}}, collectionTime)
+	recordCollections(logger, "i-1234567890", map[gatherers.T]model.Config{namedGatherer("AWS:Application"): {}}, collectionTime.Add(time.Hour))
+
+	lastCollections := loadLastCollections(logger, "i-1234567890")
+	assert.True(t, collectionTime.Equal(lastCollections["AWS:Network"]))
+	assert.True(t, collectionTime.Add(time.Hour).Equal(lastCollections["AWS:Application"]))
+}
@@ -0,0 +1,477 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package managefilesystem implements the aws:manageFilesystem plugin.
+package managefilesystem
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler"
+	"github.com/aws/amazon-ssm-agent/agent/jsonutil"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+)
+
+const (
+	fstabMode = 0644
+
+	// blkid exits with 2 when the device has no filesystem signature
+	blkidNoSignatureExitCode = 2
+
+	// growpart exits with 1 and prints NOCHANGE when the partition already fills the disk
+	growpartNoChange = "NOCHANGE"
+)
+
+// supportedFilesystems are the filesystem types that can be created and grown
+var supportedFilesystems = map[string]struct{}{"ext2": {}, "ext3": {}, "ext4": {}, "xfs": {}}
+
+// Assign method and paths to global variables to allow unittest to override
+var runCommand = defaultRunCommand
+var freeSpace = statFreeSpace
+var mountsPath = "/proc/mounts"
+var fstabPath = "/etc/fstab"
+var sysBlockPath = "/sys/class/block"
+
+// Plugin is the type for the aws:manageFilesystem plugin.
+type Plugin struct{}
+
+// ManageFilesystemPluginInput represents the desired state of one filesystem managed by the aws:manageFilesystem plugin.
+type ManageFilesystemPluginInput struct {
+	contracts.PluginInput
+	ID                 string
+	Device             string
+	FilesystemType     string
+	Label              string
+	MountPoint         string
+	MountOptions       string
+	PersistMount       bool
+	Grow               bool
+	MinimumFreeSpaceMB interface{}
+	MinimumFreePercent interface{}
+}
+
+// NewPlugin returns a new instance of the plugin.
+func NewPlugin() (*Plugin, error) {
+	return &Plugin{}, nil
+}
+
+// Name returns the plugin name
+func Name() string {
+	return appconfig.PluginNameAwsManageFilesystem
+}
+
+// Execute brings the device, its filesystem and its mount to the requested state.
+func (p *Plugin) Execute(context context.T, config contracts.Configuration, cancelFlag task.CancelFlag, output iohandler.IOHandler) {
+	log := context.Log()
+	log.Infof("%v started with configuration %v", Name(), config)
+
+	if cancelFlag.ShutDown() {
+		output.MarkAsShutdown()
+	} else if cancelFlag.Canceled() {
+		output.MarkAsCancelled()
+	} else if runtime.GOOS != "linux" {
+		output.MarkAsFailed(fmt.Errorf("%v is only supported on Linux", Name()))
+	} else if input, err := parseAndValidateInput(config.Properties); err != nil {
+		output.MarkAsFailed(err)
+	} else if err = manageFilesystem(log, input, output); err != nil {
+		output.MarkAsFailed(err)
+	} else {
+		output.MarkAsSucceeded()
+	}
+}
+
+// manageFilesystem creates the filesystem, mounts it, grows it and checks its free space, in that order.
+// Each operation is skipped when the system is already in the requested state.
+func manageFilesystem(log log.T, input *ManageFilesystemPluginInput, output iohandler.IOHandler) (err error) {
+	device := input.Device
+	if device != "" {
+		// devices are often given as /dev/disk/by-id links, /proc/mounts lists the device nodes
+		if device, err = filepath.EvalSymlinks(input.Device); err != nil {
+			return fmt.Errorf("device %v not found: %v", input.Device, err)
+		}
+	}
+
+	if input.FilesystemType != "" {
+		if err = createFilesystem(log, device, input.FilesystemType, input.Label, output); err != nil {
+			return err
+		}
+	}
+
+	if input.MountPoint != "" && device != "" {
+		if err = mount(log, device, input.MountPoint, input.MountOptions, output); err != nil {
+			return err
+		}
+		if input.PersistMount {
+			if err = persistMount(log, device, input.MountPoint, input.MountOptions, output); err != nil {
+				return err
+			}
+		}
+	}
+
+	if input.Grow {
+		if err = grow(log, device, output); err != nil {
+			return err
+		}
+	}
+
+	if input.MinimumFreeSpaceMB != nil || input.MinimumFreePercent != nil {
+		minimumMB, _ := parseThreshold(input.MinimumFreeSpaceMB)
+		minimumPercent, _ := parseThreshold(input.MinimumFreePercent)
+		return checkFreeSpace(input.MountPoint, minimumMB, minimumPercent, output)
+	}
+	return nil
+}
+
+// createFilesystem creates the filesystem unless the device already has one, an existing filesystem is never replaced.
+func createFilesystem(log log.T, device string, filesystemType string, label string, output iohandler.IOHandler) error {
+	current, err := filesystemOf(log, device)
+	if err != nil {
+		return err
+	}
+	if current == filesystemType {
+		log.Debugf("%v already has a %v filesystem", device, current)
+		return nil
+	} else if current != "" {
+		return fmt.Errorf("%v already has a %v filesystem, it won't be replaced by %v", device, current, filesystemType)
+	}
+
+	args := []string{"-t", filesystemType}
+	if label != "" {
+		args = append(args, "-L", label)
+	}
+	if _, err = runCommand(log, "mkfs", append(args, device)...); err != nil {
+		return fmt.Errorf("failed to create %v filesystem on %v: %v", filesystemType, device, err)
+	}
+	output.AppendInfof("Created %v filesystem on %v", filesystemType, device)
+	return nil
+}
+
+// mount mounts the device unless it's already mounted on the mount point.
+func mount(log log.T, device string, mountPoint string, options string, output iohandler.IOHandler) error {
+	mounted, err := mountedDevice(mountPoint)
+	if err != nil {
+		return err
+	}
+	if mounted == device {
+		log.Debugf("%v is already mounted on %v", device, mountPoint)
+		return nil
+	} else if mounted != "" {
+		return fmt.Errorf("%v is already mounted on %v", mounted, mountPoint)
+	}
+
+	if err = os.MkdirAll(mountPoint, 0755); err != nil {
+		return fmt.Errorf("failed to create mount point %v: %v", mountPoint, err)
+	}
+	args := []string{device, mountPoint}
+	if options != "" {
+		args = []string{"-o", options, device, mountPoint}
+	}
+	if _, err = runCommand(log, "mount", args...); err != nil {
+		return fmt.Errorf("failed to mount %v on %v: %v", device, mountPoint, err)
+	}
+	output.AppendInfof("Mounted %v on %v", device, mountPoint)
+	return nil
+}
+
+// persistMount adds the mount to fstab unless an entry for the mount point exists.
+// Devices are identified by UUID since device names can change across reboots.
+func persistMount(log log.T, device string, mountPoint string, options string, output iohandler.IOHandler) error {
+	content, err := ioutil.ReadFile(fstabPath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		if fields := strings.Fields(line); len(fields) >= 2 && !strings.HasPrefix(fields[0], "#") && filepath.Clean(fields[1]) == filepath.Clean(mountPoint) {
+			log.Debugf("%v already has an entry for %v", fstabPath, mountPoint)
+			return nil
+		}
+	}
+
+	uuid, err := blkid(log, device, "UUID")
+	if err != nil || uuid == "" {
+		return fmt.Errorf("failed to read the UUID of %v: %v", device, err)
+	}
+	filesystemType, err := filesystemOf(log, device)
+	if err != nil {
+		return err
+	}
+	if options == "" {
+		options = "defaults"
+	}
+	// nofail lets the instance boot when the volume is detached
+	if !strings.Contains(options, "nofail") {
+		options += ",nofail"
+	}
+
+	entry := fmt.Sprintf("UUID=%v %v %v %v 0 2\n", uuid, mountPoint, filesystemType, options)
+	if len(content) > 0 && !strings.HasSuffix(string(content), "\n") {
+		entry = "\n" + entry
+	}
+	file, err := os.OpenFile(fstabPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, fstabMode)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if _, err = file.WriteString(entry); err != nil {
+		return err
+	}
+	output.AppendInfof("Added %v to %v", mountPoint, fstabPath)
+	return nil
+}
+
+// grow grows the partition of the device to the end of its disk and the filesystem to the size of the partition.
+func grow(log log.T, device string, output iohandler.IOHandler) error {
+	if disk, partition, ok := partitionOf(device); ok {
+		out, err := runCommand(log, "growpart", disk, partition)
+		if err != nil && !strings.Contains(out, growpartNoChange) {
+			return fmt.Errorf("failed to grow partition %v of %v: %v", partition, disk, err)
+		} else if err == nil {
+			output.AppendInfof("Grew partition %v of %v", partition, disk)
+		}
+	}
+
+	filesystemType, err := filesystemOf(log, device)
+	if err != nil {
+		return err
+	}
+	switch filesystemType {
+	case "ext2", "ext3", "ext4":
+		_, err = runCommand(log, "resize2fs", device)
+	case "xfs":
+		// xfs can only be grown while mounted
+		var mountPoint string
+		if mountPoint, err = mountPointOf(device); err != nil {
+			return err
+		} else if mountPoint == "" {
+			return fmt.Errorf("%v must be mounted to grow its xfs filesystem", device)
+		}
+		_, err = runCommand(log, "xfs_growfs", mountPoint)
+	default:
+		return fmt.Errorf("growing %v filesystems is not supported", filesystemType)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to grow the filesystem of %v: %v", device, err)
+	}
+	output.AppendInfof("Grew %v filesystem of %v", filesystemType, device)
+	return nil
+}
+
+// checkFreeSpace fails when the free space of the filesystem is below either threshold.
+func checkFreeSpace(path string, minimumMB float64, minimumPercent float64, output iohandler.IOHandler) error {
+	available, total, err := freeSpace(path)
+	if err != nil {
+		return fmt.Errorf("failed to read the free space of %v: %v", path, err)
+	}
+	availableMB := float64(available) / (1 << 20)
+	var availablePercent float64
+	if total > 0 {
+		availablePercent = float64(available) * 100 / float64(total)
+	}
+	output.AppendInfof("Free space on %v: %.0f MB (%.1f%%)", path, availableMB, availablePercent)
+
+	if availableMB < minimumMB {
+		return fmt.Errorf("free space on %v is %.0f MB, below the minimum of %v MB", path, availableMB, minimumMB)
+	}
+	if availablePercent < minimumPercent {
+		return fmt.Errorf("free space on %v is %.1f%%, below the minimum of %v%%", path, availablePercent, minimumPercent)
+	}
+	return nil
+}
+
+// filesystemOf returns the filesystem type of the device, empty if it has none.
+func filesystemOf(log log.T, device string) (string, error) {
+	out, err := runCommand(log, "blkid", "-p", "-o", "value", "-s", "TYPE", device)
+	if err != nil {
+		// blkid exits with 2 when the device has no filesystem signature, any other failure leaves the filesystem unknown
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.ExitStatus() == blkidNoSignatureExitCode {
+				return "", nil
+			}
+		}
+		return "", fmt.Errorf("failed to read the filesystem of %v: %v", device, err)
+	}
+	return out, nil
+}
+
+// blkid returns one tag of the device.
+func blkid(log log.T, device string, tag string) (string, error) {
+	return runCommand(log, "blkid", "-o", "value", "-s", tag, device)
+}
+
+// partitionOf returns the disk device and the partition number when the device is a partition.
+func partitionOf(device string) (disk string, partition string, ok bool) {
+	name := filepath.Base(device)
+	content, err := ioutil.ReadFile(filepath.Join(sysBlockPath, name, "partition"))
+	if err != nil {
+		return "", "", false
+	}
+	// the sysfs entry of a partition is a child of the entry of its disk
+	sysPath, err := filepath.EvalSymlinks(filepath.Join(sysBlockPath, name))
+	if err != nil {
+		return "", "", false
+	}
+	return "/dev/" + filepath.Base(filepath.Dir(sysPath)), strings.TrimSpace(string(content)), true
+}
+
+// mountedDevice returns the device mounted on the mount point, empty if nothing is mounted there.
+func mountedDevice(mountPoint string) (string, error) {
+	mounts, err := readMounts()
+	if err != nil {
+		return "", err
+	}
+	device := ""
+	for _, m := range mounts {
+		// the last entry wins when mounts are stacked
+		if filepath.Clean(m[1]) == filepath.Clean(mountPoint) {
+			device = m[0]
+		}
+	}
+	return device, nil
+}
+
+// mountPointOf returns the first mount point of the device, empty if it's not mounted.
+func mountPointOf(device string) (string, error) {
+	mounts, err := readMounts()
+	if err != nil {
+		return "", err
+	}
+	for _, m := range mounts {
+		if m[0] == device {
+			return m[1], nil
+		}
+	}
+	return "", nil
+}
+
+// readMounts returns the device and mount point of every mount, devices are resolved to their device node.
+func readMounts() ([][2]string, error) {
+	content, err := ioutil.ReadFile(mountsPath)
+	if err != nil {
+		return nil, err
+	}
+	var mounts [][2]string
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		device := fields[0]
+		if resolved, err := filepath.EvalSymlinks(device); err == nil {
+			device = resolved
+		}
+		// /proc/mounts escapes spaces in mount points as \040
+		mounts = append(mounts, [2]string{device, strings.Replace(fields[1], `\040`, " ", -1)})
+	}
+	return mounts, nil
+}
+
+// defaultRunCommand runs the command and returns its trimmed combined output, the output is included in the error.
+func defaultRunCommand(log log.T, name string, args ...string) (string, error) {
+	log.Debugf("Running %v %v", name, args)
+	out, err := exec.Command(name, args...).CombinedOutput()
+	output := strings.TrimSpace(string(out))
+	if err != nil && output != "" {
+		return output, fmt.Errorf("%v - %v", err, output)
+	}
+	return output, err
+}
+
+// parseThreshold parses a free space threshold given as a number or a string.
+func parseThreshold(value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case nil:
+		return 0, nil
+	case float64:
+		if v < 0 {
+			return 0, fmt.Errorf("free space thresholds must not be negative, got %v", v)
+		}
+		return v, nil
+	case string:
+		if v == "" {
+			return 0, nil
+		}
+		number, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return 0, fmt.Errorf("free space thresholds must be numbers, got %q", v)
+		}
+		return parseThreshold(number)
+	}
+	return 0, fmt.Errorf("free space thresholds must be numbers, got %v", value)
+}
+
+// parseAndValidateInput parses the plugin properties and validates them
+func parseAndValidateInput(rawPluginInput interface{}) (*ManageFilesystemPluginInput, error) {
+	var input ManageFilesystemPluginInput
+	if err := jsonutil.Remarshal(rawPluginInput, &input); err != nil {
+		return nil, fmt.Errorf("invalid format in plugin properties %v; \nerror %v", rawPluginInput, err)
+	}
+
+	if err := validateInput(&input); err != nil {
+		return nil, fmt.Errorf("invalid input: %v", err)
+	}
+	return &input, nil
+}
+
+// validateInput ensures the plugin input matches the defined schema
+func validateInput(input *ManageFilesystemPluginInput) error {
+	if input.Device != "" && !strings.HasPrefix(filepath.Clean(input.Device), "/dev/") {
+		return fmt.Errorf("Device %v must be a path under /dev", input.Device)
+	}
+	if input.MountPoint != "" && !filepath.IsAbs(input.MountPoint) {
+		return fmt.Errorf("MountPoint %v must be an absolute path", input.MountPoint)
+	}
+	if input.FilesystemType != "" {
+		if _, ok := supportedFilesystems[input.FilesystemType]; !ok {
+			return fmt.Errorf("unsupported FilesystemType %q, supported types are ext2, ext3, ext4 and xfs", input.FilesystemType)
+		}
+	}
+	if strings.ContainsAny(input.MountOptions, " \t\n") {
+		return errors.New("MountOptions must be a comma separated list without spaces")
+	}
+
+	if input.Device == "" && (input.FilesystemType != "" || input.Grow || input.PersistMount) {
+		return errors.New("Device must be specified to create, grow or persist a filesystem")
+	}
+	if input.PersistMount && input.MountPoint == "" {
+		return errors.New("MountPoint must be specified to persist the mount")
+	}
+
+	if _, err := parseThreshold(input.MinimumFreeSpaceMB); err != nil {
+		return err
+	}
+	if percent, err := parseThreshold(input.MinimumFreePercent); err != nil {
+		return err
+	} else if percent > 100 {
+		return fmt.Errorf("MinimumFreePercent must not exceed 100, got %v", percent)
+	}
+	if (input.MinimumFreeSpaceMB != nil || input.MinimumFreePercent != nil) && input.MountPoint == "" {
+		return errors.New("MountPoint must be specified to check the free space")
+	}
+	if input.Device == "" && input.MountPoint == "" {
+		return errors.New("Device or MountPoint must be specified")
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package managefilesystem
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeSystem records the commands run and answers blkid with the filesystem and UUID of the device.
+type fakeSystem struct {
+	filesystem string
+	// blkidErr is returned by blkid instead of reading the filesystem
+	blkidErr error
+	commands []string
+}
+
+func (f *fakeSystem) run(log log.T, name string, args ...string) (string, error) {
+	command := name + " " + strings.Join(args, " ")
+	if name != "blkid" {
+		f.commands = append(f.commands, command)
+	}
+	switch {
+	case strings.HasPrefix(command, "blkid -p"):
+		if f.blkidErr != nil {
+			return "", f.blkidErr
+		}
+		if f.filesystem == "" {
+			return "", exitError(2)
+		}
+		return f.filesystem, nil
+	case strings.HasPrefix(command, "blkid -o value -s UUID"):
+		return "0b1f6a52-1bd5-4a5a-9f0a-2b5c9b0e2d7e", nil
+	case name == "mkfs":
+		f.filesystem = args[1]
+	case name == "growpart":
+		return "NOCHANGE: partition 1 is size 16775135. it cannot be grown", errors.New("exit status 1 - NOCHANGE: partition 1 is size 16775135. it cannot be grown")
+	}
+	return "", nil
+}
+
+// exitError returns the error of a command which exited with the given code.
+func exitError(code int) error {
+	return exec.Command("sh", "-c", fmt.Sprintf("exit %d", code)).Run()
+}
+
+// setUp points the plugin at fake mounts, fstab and sysfs files and returns a device and a cleanup function.
+func setUp(t *testing.T, system *fakeSystem, mounts string) (string, string, func()) {
+	dir, err := ioutil.TempDir("", "managefilesystem")
+	assert.NoError(t, err)
+
+	device := filepath.Join(dir, "xvdf")
+	assert.NoError(t, ioutil.WriteFile(device, nil, 0600))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "mounts"), []byte(strings.Replace(mounts, "DEVICE", device, -1)), 0600))
+
+	runCommand, mountsPath, fstabPath, sysBlockPath = system.run, filepath.Join(dir, "mounts"), filepath.Join(dir, "fstab"), filepath.Join(dir, "sys")
+	return dir, device, func() {
+		runCommand, mountsPath, fstabPath, sysBlockPath = defaultRunCommand, "/proc/mounts", "/etc/fstab", "/sys/class/block"
+		os.RemoveAll(dir)
+	}
+}
+
+func TestValidateInput(t *testing.T) {
+	assert.NoError(t, validateInput(&ManageFilesystemPluginInput{Device: "/dev/xvdf", FilesystemType: "xfs", MountPoint: "/data", PersistMount: true}))
+	assert.NoError(t, validateInput(&ManageFilesystemPluginInput{MountPoint: "/", MinimumFreePercent: "10"}))
+	assert.Error(t, validateInput(&ManageFilesystemPluginInput{Device: "/tmp/disk", FilesystemType: "ext4"}))
+	assert.Error(t, validateInput(&ManageFilesystemPluginInput{Device: "/dev/xvdf", FilesystemType: "ntfs"}))
+	assert.Error(t, validateInput(&ManageFilesystemPluginInput{Device: "/dev/xvdf", MountPoint: "data"}))
+	assert.Error(t, validateInput(&ManageFilesystemPluginInput{Device: "/dev/xvdf", PersistMount: true}))
+	assert.Error(t, validateInput(&ManageFilesystemPluginInput{MountPoint: "/data", Grow: true}))
+	assert.Error(t, validateInput(&ManageFilesystemPluginInput{MountPoint: "/data", MinimumFreePercent: float64(120)}))
+	assert.Error(t, validateInput(&ManageFilesystemPluginInput{MountPoint: "/data", MinimumFreeSpaceMB: "lots"}))
+	assert.Error(t, validateInput(&ManageFilesystemPluginInput{Device: "/dev/xvdf", MountPoint: "/data", MountOptions: "noatime, nodev"}))
+	assert.Error(t, validateInput(&ManageFilesystemPluginInput{}))
+}
+
+func TestManageFilesystemCreatesMountsAndPersists(t *testing.T) {
+	system := &fakeSystem{}
+	dir, device, cleanUp := setUp(t, system, "proc /proc proc rw 0 0\n")
+	defer cleanUp()
+	mountPoint := filepath.Join(dir, "data")
+
+	input := &ManageFilesystemPluginInput{Device: device, FilesystemType: "ext4", Label: "data", MountPoint: mountPoint, MountOptions: "noatime", PersistMount: true}
+	output := &iohandler.DefaultIOHandler{}
+	assert.NoError(t, manageFilesystem(log.NewMockLog(), input, output))
+
+	assert.Equal(t, []string{
+		"mkfs -t ext4 -L data " + device,
+		"mount -o noatime " + device + " " + mountPoint,
+	}, system.commands)
+	fstab, _ := ioutil.ReadFile(fstabPath)
+	assert.Equal(t, "UUID=0b1f6a52-1bd5-4a5a-9f0a-2b5c9b0e2d7e "+mountPoint+" ext4 noatime,nofail 0 2\n", string(fstab))
+	assert.Contains(t, output.GetStdout(), "Created ext4 filesystem")
+
+	// once mounted and persisted nothing changes
+	assert.NoError(t, ioutil.WriteFile(mountsPath, []byte(device+" "+mountPoint+" ext4 rw 0 0\n"), 0600))
+	system.commands = nil
+	assert.NoError(t, manageFilesystem(log.NewMockLog(), input, &iohandler.DefaultIOHandler{}))
+	assert.Empty(t, system.commands)
+	fstab, _ = ioutil.ReadFile(fstabPath)
+	assert.Equal(t, 1, strings.Count(string(fstab), "UUID="))
+}
+
+func TestManageFilesystemNeverReformats(t *testing.T) {
+	system := &fakeSystem{filesystem: "xfs"}
+	_, device, cleanUp := setUp(t, system, "")
+	defer cleanUp()
+
+	err := manageFilesystem(log.NewMockLog(), &ManageFilesystemPluginInput{Device: device, FilesystemType: "ext4"}, &iohandler.DefaultIOHandler{})
+	assert.Error(t, err)
+	assert.Empty(t, system.commands)
+}
+
+func TestManageFilesystemFailsWhenTheFilesystemIsUnknown(t *testing.T) {
+	for _, blkidErr := range []error{exitError(4), errors.New("blkid: not found")} {
+		system := &fakeSystem{blkidErr: blkidErr}
+		_, device, cleanUp := setUp(t, system, "")
+
+		err := manageFilesystem(log.NewMockLog(), &ManageFilesystemPluginInput{Device: device, FilesystemType: "ext4"}, &iohandler.DefaultIOHandler{})
+		assert.Error(t, err)
+		assert.Empty(t, system.commands)
+		cleanUp()
+	}
+}
+
+func TestManageFilesystemGrow(t *testing.T) {
+	system := &fakeSystem{filesystem: "xfs"}
+	dir, device, cleanUp := setUp(t, system, "DEVICE /data xfs rw 0 0\n")
+	defer cleanUp()
+
+	// sysfs lists partitions under their disk
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "devices", "xvda", "xvdf"), 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "devices", "xvda", "xvdf", "partition"), []byte("1\n"), 0644))
+	assert.NoError(t, os.MkdirAll(sysBlockPath, 0755))
+	assert.NoError(t, os.Symlink(filepath.Join(dir, "devices", "xvda", "xvdf"), filepath.Join(sysBlockPath, "xvdf")))
+
+	output := &iohandler.DefaultIOHandler{}
+	assert.NoError(t, manageFilesystem(log.NewMockLog(), &ManageFilesystemPluginInput{Device: device, Grow: true}, output))
+	assert.Equal(t, []string{"growpart /dev/xvda 1", "xfs_growfs /data"}, system.commands)
+	assert.NotContains(t, output.GetStdout(), "Grew partition")
+	assert.Contains(t, output.GetStdout(), "Grew xfs filesystem")
+}
+
+func TestCheckFreeSpace(t *testing.T) {
+	defer func() { freeSpace = statFreeSpace }()
+	freeSpace = func(path string) (uint64, uint64, error) {
+		return 512 << 20, 4096 << 20, nil
+	}
+
+	output := &iohandler.DefaultIOHandler{}
+	assert.NoError(t, checkFreeSpace("/data", 500, 10, output))
+	assert.Contains(t, output.GetStdout(), "Free space on /data: 512 MB (12.5%)")
+
+	assert.Error(t, checkFreeSpace("/data", 1024, 0, &iohandler.DefaultIOHandler{}))
+	assert.Error(t, checkFreeSpace("/data", 0, 20, &iohandler.DefaultIOHandler{}))
+}
+
+func TestParseAndValidateInput(t *testing.T) {
+	input, err := parseAndValidateInput(map[string]interface{}{"Device": "/dev/xvdf", "FilesystemType": "xfs", "MinimumFreeSpaceMB": 1024, "MountPoint": "/data"})
+	assert.NoError(t, err)
+	assert.Equal(t, float64(1024), input.MinimumFreeSpaceMB)
+
+	_, err = parseAndValidateInput(map[string]interface{}{"Device": "/dev/xvdf", "FilesystemType": "btrfs"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,23 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// +build windows
+
+package managefilesystem
+
+import "errors"
+
+// statFreeSpace is not supported, the plugin only manages Linux filesystems.
+func statFreeSpace(path string) (available uint64, total uint64, err error) {
+	return 0, 0, errors.New("free space checks are not supported on Windows")
+}
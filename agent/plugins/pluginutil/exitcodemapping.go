@@ -0,0 +1,165 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package pluginutil implements some common functions shared by multiple plugins.
+package pluginutil
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+)
+
+// exitCodeRange matches an exit code or a range of exit codes such as 100-110
+var exitCodeRange = regexp.MustCompile(`^(-?\d+)(?:\s*-\s*(-?\d+))?$`)
+
+// mappableStatuses are the statuses exit codes can be mapped to
+var mappableStatuses = []contracts.ResultStatus{
+	contracts.ResultStatusSuccess,
+	contracts.ResultStatusSuccessAndReboot,
+	contracts.ResultStatusFailed,
+}
+
+// ExitCodeStatus maps exit codes of a script to the status the plugin reports
+type ExitCodeStatus struct {
+	// ExitCodes is an exit code, a range of exit codes such as 100-110, or a comma separated list of both
+	ExitCodes interface{}
+	// Status is Success, SuccessAndReboot or Failed
+	Status string
+	// Message is added to the output when one of the exit codes is returned, it is the error of a Failed status
+	Message string
+}
+
+// ExitCodeMapping is a validated mapping of exit codes to statuses, the first rule an exit code matches applies
+type ExitCodeMapping []exitCodeRule
+
+type exitCodeRule struct {
+	from    int
+	to      int
+	status  contracts.ResultStatus
+	message string
+}
+
+// ParseExitCodeMapping validates the mapping of the plugin input
+func ParseExitCodeMapping(mapping []ExitCodeStatus) (result ExitCodeMapping, err error) {
+	for _, entry := range mapping {
+		status, err := mappableStatus(entry.Status)
+		if err != nil {
+			return nil, err
+		}
+		ranges, err := parseExitCodes(entry.ExitCodes)
+		if err != nil {
+			return nil, err
+		}
+		for _, codes := range ranges {
+			result = append(result, exitCodeRule{from: codes[0], to: codes[1], status: status, message: entry.Message})
+		}
+	}
+	return result, nil
+}
+
+// mappableStatus returns the status with the case used by the agent
+func mappableStatus(status string) (contracts.ResultStatus, error) {
+	for _, mappable := range mappableStatuses {
+		if strings.EqualFold(strings.TrimSpace(status), string(mappable)) {
+			return mappable, nil
+		}
+	}
+	supported := make([]string, len(mappableStatuses))
+	for i, mappable := range mappableStatuses {
+		supported[i] = string(mappable)
+	}
+	return "", fmt.Errorf("Invalid status %v in exitCodeMapping, exit codes can be mapped to %v", status, strings.Join(supported, ", "))
+}
+
+// parseExitCodes returns the ranges of exit codes, a single exit code is a range of one
+func parseExitCodes(exitCodes interface{}) (ranges [][2]int, err error) {
+	var list string
+	switch value := exitCodes.(type) {
+	case float64:
+		if value != math.Trunc(value) {
+			return nil, fmt.Errorf("Invalid exit code %v in exitCodeMapping", value)
+		}
+		return [][2]int{{int(value), int(value)}}, nil
+	case int:
+		return [][2]int{{value, value}}, nil
+	case string:
+		list = value
+	default:
+		return nil, fmt.Errorf("Invalid exit codes %v in exitCodeMapping, exit codes are a number, a range such as 100-110 or a comma separated list of both", exitCodes)
+	}
+
+	for _, item := range strings.Split(list, ",") {
+		match := exitCodeRange.FindStringSubmatch(strings.TrimSpace(item))
+		if match == nil {
+			return nil, fmt.Errorf("Invalid exit codes %v in exitCodeMapping, exit codes are a number, a range such as 100-110 or a comma separated list of both", list)
+		}
+		from, err := strconv.Atoi(match[1])
+		if err != nil {
+			return nil, fmt.Errorf("Invalid exit code %v in exitCodeMapping", match[1])
+		}
+		to := from
+		if match[2] != "" {
+			if to, err = strconv.Atoi(match[2]); err != nil || to < from {
+				return nil, fmt.Errorf("Invalid exit code range %v in exitCodeMapping", item)
+			}
+		}
+		ranges = append(ranges, [2]int{from, to})
+	}
+	return ranges, nil
+}
+
+// GetStatus returns the status the exit code is mapped to and its message, mapped is false if no rule matches the exit code.
+// The exit code of commands the agent stopped is never mapped, so that cancellations and timeouts are reported as such.
+func (m ExitCodeMapping) GetStatus(exitCode int) (status contracts.ResultStatus, message string, mapped bool) {
+	if exitCode == appconfig.CommandStoppedPreemptivelyExitCode {
+		return "", "", false
+	}
+	for _, rule := range m {
+		if exitCode >= rule.from && exitCode <= rule.to {
+			return rule.status, rule.message, true
+		}
+	}
+	return "", "", false
+}
+
+// SetStatus sets the status of the plugin from the exit code, the mapping takes precedence over the default status of the exit code.
+// It returns true if the exit code was mapped, the error the command returned for the exit code is not a failure of the plugin then.
+func (m ExitCodeMapping) SetStatus(output iohandler.IOHandler, exitCode int, cancelFlag task.CancelFlag) (mapped bool) {
+	status, message, mapped := m.GetStatus(exitCode)
+	if !mapped {
+		output.SetStatus(GetStatus(exitCode, cancelFlag))
+		return false
+	}
+
+	if status == contracts.ResultStatusFailed {
+		if message == "" {
+			message = fmt.Sprintf("exit code %v is mapped to %v", exitCode, status)
+		}
+		output.MarkAsFailed(errors.New(message))
+		return true
+	}
+	output.SetStatus(status)
+	if message != "" {
+		output.AppendInfo(message)
+	}
+	return true
+}
@@ -0,0 +1,108 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package pluginutil implements some common functions shared by multiple plugins.
+package pluginutil
+
+import (
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler"
+	"github.com/aws/amazon-ssm-agent/agent/jsonutil"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExitCodeMapping(t *testing.T) {
+	var input []ExitCodeStatus
+	err := jsonutil.Unmarshal(`[
+		{"exitCodes": 2, "status": "Success"},
+		{"exitCodes": "3", "status": "successAndReboot", "message": "Reboot to finish the installation"},
+		{"exitCodes": "100-110, 120", "status": "Failed", "message": "The disk is full"},
+		{"exitCodes": "1-200", "status": "Success"}
+	]`, &input)
+	assert.NoError(t, err)
+
+	mapping, err := ParseExitCodeMapping(input)
+	assert.NoError(t, err)
+
+	for exitCode, expected := range map[int]contracts.ResultStatus{
+		2:   contracts.ResultStatusSuccess,
+		3:   contracts.ResultStatusSuccessAndReboot,
+		100: contracts.ResultStatusFailed,
+		110: contracts.ResultStatusFailed,
+		120: contracts.ResultStatusFailed,
+		111: contracts.ResultStatusSuccess,
+	} {
+		status, _, mapped := mapping.GetStatus(exitCode)
+		assert.True(t, mapped, "exit code %v", exitCode)
+		assert.Equal(t, expected, status, "exit code %v", exitCode)
+	}
+	_, message, _ := mapping.GetStatus(105)
+	assert.Equal(t, "The disk is full", message)
+
+	for _, exitCode := range []int{0, 201, appconfig.CommandStoppedPreemptivelyExitCode} {
+		_, _, mapped := mapping.GetStatus(exitCode)
+		assert.False(t, mapped, "exit code %v", exitCode)
+	}
+}
+
+func TestParseExitCodeMappingErrors(t *testing.T) {
+	for _, input := range []ExitCodeStatus{
+		{ExitCodes: "2", Status: "TimedOut"},
+		{ExitCodes: "2", Status: ""},
+		{ExitCodes: "110-100", Status: "Failed"},
+		{ExitCodes: "2,", Status: "Failed"},
+		{ExitCodes: "two", Status: "Failed"},
+		{ExitCodes: 2.5, Status: "Failed"},
+		{ExitCodes: nil, Status: "Failed"},
+		{ExitCodes: []interface{}{2.0}, Status: "Failed"},
+	} {
+		_, err := ParseExitCodeMapping([]ExitCodeStatus{input})
+		assert.Error(t, err, "%v", input)
+	}
+}
+
+func TestExitCodeMappingSetStatus(t *testing.T) {
+	mapping, err := ParseExitCodeMapping([]ExitCodeStatus{
+		{ExitCodes: "2", Status: "Success", Message: "Nothing to update"},
+		{ExitCodes: "100", Status: "Failed", Message: "The disk is full"},
+		{ExitCodes: "101", Status: "Failed"},
+	})
+	assert.NoError(t, err)
+	cancelFlag := task.NewChanneledCancelFlag()
+
+	output := &iohandler.DefaultIOHandler{}
+	assert.True(t, mapping.SetStatus(output, 2, cancelFlag))
+	assert.Equal(t, contracts.ResultStatusSuccess, output.GetStatus())
+	assert.Contains(t, output.GetStdout(), "Nothing to update")
+
+	output = &iohandler.DefaultIOHandler{}
+	assert.True(t, mapping.SetStatus(output, 100, cancelFlag))
+	assert.Equal(t, contracts.ResultStatusFailed, output.GetStatus())
+	assert.Contains(t, output.GetStderr(), "The disk is full")
+
+	output = &iohandler.DefaultIOHandler{}
+	assert.True(t, mapping.SetStatus(output, 101, cancelFlag))
+	assert.Contains(t, output.GetStderr(), "exit code 101 is mapped to Failed")
+
+	// exit codes that aren't mapped have their default status
+	output = &iohandler.DefaultIOHandler{}
+	assert.False(t, mapping.SetStatus(output, appconfig.SuccessExitCode, cancelFlag))
+	assert.Equal(t, contracts.ResultStatusSuccess, output.GetStatus())
+	output = &iohandler.DefaultIOHandler{}
+	assert.False(t, mapping.SetStatus(output, 3, cancelFlag))
+	assert.Equal(t, contracts.ResultStatusFailed, output.GetStatus())
+}
@@ -54,6 +54,31 @@ func StringPrefix(input string, maxLength int, truncatedSuffix string) string {
 	return truncatedSuffix[:maxLength]
 }
 
+// StringSuffix returns the ending part of a string, truncated to the given limit.
+func StringSuffix(input string, maxLength int, truncatedPrefix string) string {
+	// no need to truncate
+	if len(input) < maxLength {
+		return input
+	}
+
+	// truncate and add prefix
+	if maxLength > len(truncatedPrefix) {
+		pos := len(input) - maxLength + len(truncatedPrefix)
+		return truncatedPrefix + string(input[pos:])
+	}
+
+	// prefix longer than maxLength - return beginning of prefix
+	return truncatedPrefix[:maxLength]
+}
+
+// TruncateString truncates a string to the given limit, keeping its beginning or its end depending on the strategy.
+func TruncateString(input string, maxLength int, truncatedMarker string, strategy string) string {
+	if strategy == appconfig.OutputTruncationStrategyTail {
+		return StringSuffix(input, maxLength, truncatedMarker)
+	}
+	return StringPrefix(input, maxLength, truncatedMarker)
+}
+
 // ReadPrefix returns the beginning data from a given Reader, truncated to the given limit.
 func ReadPrefix(input io.Reader, maxLength int, truncatedSuffix string) (out string, err error) {
 	// read up to maxLength bytes from input
@@ -19,6 +19,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
 	"github.com/aws/amazon-ssm-agent/agent/log"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -70,6 +71,17 @@ func testTruncatedString(t *testing.T, original string, truncated string, trunca
 	}
 }
 
+// TestTruncateString tests that the tail strategy keeps the end of the string.
+func TestTruncateString(t *testing.T) {
+	input := "a string to truncate"
+	marker := "-z-"
+
+	assert.Equal(t, "a string-z-", TruncateString(input, 11, marker, appconfig.OutputTruncationStrategyHead))
+	assert.Equal(t, "-z-truncate", TruncateString(input, 11, marker, appconfig.OutputTruncationStrategyTail))
+	assert.Equal(t, input, TruncateString(input, len(input)+1, marker, appconfig.OutputTruncationStrategyTail))
+	assert.Equal(t, "-z", TruncateString(input, 2, marker, appconfig.OutputTruncationStrategyTail))
+}
+
 func TestValidateExecutionTimeout(t *testing.T) {
 	logger := log.NewMockLog()
 	logger.On("Error", mock.Anything).Return(nil)
@@ -0,0 +1,396 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package runchefrecipe implements the aws:runChefRecipe plugin.
+package runchefrecipe
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/executers"
+	"github.com/aws/amazon-ssm-agent/agent/fileutil"
+	"github.com/aws/amazon-ssm-agent/agent/fileutil/artifact"
+	"github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler"
+	"github.com/aws/amazon-ssm-agent/agent/jsonutil"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/plugins/pluginutil"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+)
+
+const (
+	cookbooksDir       = "cookbooks"       // Directory under the plugin orchestration directory where the cookbook archive is extracted
+	cacheDir           = "cache"           // Directory under the plugin orchestration directory used as chef file cache
+	clientConfigName   = "client.rb"       // Name of the chef-client configuration file
+	jsonAttributesName = "attributes.json" // Name of the file holding the json attributes of the run
+
+	// chef-client exit codes asking for a reboot, see https://docs.chef.io/uploads/chef_client_exit_codes.pdf
+	chefRebootScheduledExitCode = 35
+	chefRebootNeededExitCode    = 37
+
+	maxSummaryLineLength = 4096
+)
+
+// chefVersionPattern restricts the chef client versions accepted by the bootstrap command.
+var chefVersionPattern = regexp.MustCompile(`^[0-9A-Za-z.\-]*$`)
+
+// convergeSummaryPattern matches the line chef-client prints once the converge ends, e.g.
+// "Chef Infra Client finished, 3/10 resources updated in 05 seconds" or "Chef Client failed. 1 resources updated in 02 seconds"
+var convergeSummaryPattern = regexp.MustCompile(`Chef (?:Infra )?Client (finished|failed)[,.] (\d+)(?:/(\d+))? resources? updated`)
+
+// Assign method to global variables to allow unittest to override
+var download = artifact.Download
+var lookPath = exec.LookPath
+
+// Plugin is the type for the aws:runChefRecipe plugin.
+type Plugin struct {
+	// CommandExecuter is an object that can execute commands.
+	CommandExecuter executers.T
+}
+
+// RunChefRecipePluginInput represents one chef-client run executed by the aws:runChefRecipe plugin.
+type RunChefRecipePluginInput struct {
+	contracts.PluginInput
+	ID                string
+	SourceUrl         string
+	RunList           []string
+	JsonAttributes    string
+	ChefClientVersion string
+	// ChefInstallerHash is the sha256 checksum of the chef install script, required to install chef-client when it's missing
+	ChefInstallerHash string
+	ChefLicense       string
+	TimeoutSeconds    interface{}
+}
+
+// NewPlugin returns a new instance of the plugin.
+func NewPlugin() (*Plugin, error) {
+	var plugin Plugin
+	plugin.CommandExecuter = executers.ShellCommandExecuter{}
+
+	return &plugin, nil
+}
+
+// Name returns the plugin name
+func Name() string {
+	return appconfig.PluginNameAwsRunChefRecipe
+}
+
+// Execute bootstraps chef-client if needed, fetches the cookbooks and converges the run list in local mode.
+func (p *Plugin) Execute(context context.T, config contracts.Configuration, cancelFlag task.CancelFlag, output iohandler.IOHandler) {
+	log := context.Log()
+	log.Infof("%v started with configuration %v", Name(), config)
+
+	if cancelFlag.ShutDown() {
+		output.MarkAsShutdown()
+	} else if cancelFlag.Canceled() {
+		output.MarkAsCancelled()
+	} else if input, err := parseAndValidateInput(config.Properties); err != nil {
+		output.MarkAsFailed(err)
+	} else {
+		p.runChef(log, input, config.OrchestrationDirectory, cancelFlag, output)
+	}
+}
+
+// runChef executes one chef-client run and reports the converge result.
+func (p *Plugin) runChef(log log.T, input *RunChefRecipePluginInput, orchestrationDirectory string, cancelFlag task.CancelFlag, output iohandler.IOHandler) {
+	workingDir := fileutil.BuildPath(orchestrationDirectory, input.ID)
+	if err := fileutil.MakeDirsWithExecuteAccess(workingDir); err != nil {
+		output.MarkAsFailed(fmt.Errorf("failed to create orchestrationDir directory, %v", workingDir))
+		return
+	}
+
+	cookbookPath := filepath.Join(workingDir, cookbooksDir)
+	if err := fetchCookbooks(log, input.SourceUrl, workingDir, cookbookPath); err != nil {
+		output.MarkAsFailed(fmt.Errorf("failed to fetch cookbooks from %v: %v", input.SourceUrl, err))
+		return
+	}
+
+	executionTimeout := pluginutil.ValidateExecutionTimeout(log, input.TimeoutSeconds)
+
+	chefClient, err := p.resolveChefClient(log, input, workingDir, cancelFlag, executionTimeout, output)
+	if err != nil {
+		output.MarkAsFailed(err)
+		return
+	}
+
+	commandArguments, err := chefClientArguments(workingDir, cookbookPath, input)
+	if err != nil {
+		output.MarkAsFailed(err)
+		return
+	}
+
+	summary := &convergeSummary{}
+	stdout := io.MultiWriter(summary, output.GetStdoutWriter())
+	log.Debugf("Running %v %v in workingDirectory %v", chefClient, commandArguments, workingDir)
+	exitCode, err := p.CommandExecuter.NewExecute(log, workingDir, stdout, output.GetStderrWriter(), cancelFlag, executionTimeout, chefClient, commandArguments, nil)
+	summary.flush()
+
+	if exitCode == chefRebootScheduledExitCode || exitCode == chefRebootNeededExitCode {
+		output.AppendInfof("chef-client exited with %v and requested a reboot", exitCode)
+		exitCode = appconfig.RebootExitCode
+	}
+
+	if summary.found {
+		result := "succeeded"
+		if !summary.succeeded {
+			result = "failed"
+		}
+		if summary.total >= 0 {
+			output.AppendInfof("Chef converge %v, %v/%v resources updated", result, summary.updated, summary.total)
+		} else {
+			output.AppendInfof("Chef converge %v, %v resources updated", result, summary.updated)
+		}
+	}
+
+	output.SetExitCode(exitCode)
+	output.SetStatus(pluginutil.GetStatus(exitCode, cancelFlag))
+
+	if err != nil {
+		status := output.GetStatus()
+		if status != contracts.ResultStatusCancelled &&
+			status != contracts.ResultStatusTimedOut &&
+			status != contracts.ResultStatusSuccessAndReboot {
+			output.MarkAsFailed(fmt.Errorf("failed to run chef-client: %v", err))
+		}
+	}
+}
+
+// fetchCookbooks downloads the cookbook archive to workingDir and extracts it to cookbookPath.
+func fetchCookbooks(log log.T, sourceURL string, workingDir string, cookbookPath string) (err error) {
+	var extract func(src, dest string) error
+	lowerURL := strings.ToLower(sourceURL)
+	switch {
+	case strings.HasSuffix(lowerURL, ".zip"):
+		extract = fileutil.Unzip
+	case strings.HasSuffix(lowerURL, ".tar.gz"), strings.HasSuffix(lowerURL, ".tgz"):
+		extract = func(src, dest string) error {
+			return fileutil.Uncompress(log, src, dest)
+		}
+	default:
+		return errors.New("cookbook archive must be a .zip, .tar.gz or .tgz file")
+	}
+
+	var downloadOutput artifact.DownloadOutput
+	if downloadOutput, err = download(log, artifact.DownloadInput{
+		SourceURL:            sourceURL,
+		DestinationDirectory: workingDir,
+	}); err != nil {
+		return
+	}
+	log.Debugf("Extracting cookbook archive %v to %v", downloadOutput.LocalFilePath, cookbookPath)
+	return extract(downloadOutput.LocalFilePath, cookbookPath)
+}
+
+// resolveChefClient returns the chef-client executable, installing chef-client first if it can't be found.
+func (p *Plugin) resolveChefClient(log log.T, input *RunChefRecipePluginInput, workingDir string, cancelFlag task.CancelFlag, executionTimeout int, output iohandler.IOHandler) (string, error) {
+	if chefClient, found := findChefClient(); found {
+		return chefClient, nil
+	}
+	if input.ChefInstallerHash == "" {
+		return "", errors.New("chef-client was not found and ChefInstallerHash is not set to install it")
+	}
+
+	output.AppendInfo("chef-client was not found, installing it")
+	installer, err := downloadInstaller(log, input.ChefInstallerHash, workingDir)
+	if err != nil {
+		return "", err
+	}
+	commandName, commandArguments := bootstrapCommand(installer, input.ChefClientVersion)
+	exitCode, err := p.CommandExecuter.NewExecute(log, workingDir, output.GetStdoutWriter(), output.GetStderrWriter(), cancelFlag, executionTimeout, commandName, commandArguments, nil)
+	if err != nil || exitCode != appconfig.SuccessExitCode {
+		return "", fmt.Errorf("failed to install chef-client, exit code %v: %v", exitCode, err)
+	}
+
+	if chefClient, found := findChefClient(); found {
+		return chefClient, nil
+	}
+	return "", errors.New("chef-client was installed but could not be found")
+}
+
+// downloadInstaller downloads the chef install script and verifies its checksum before it's run.
+func downloadInstaller(log log.T, installerHash string, workingDir string) (string, error) {
+	downloadOutput, err := download(log, artifact.DownloadInput{
+		SourceURL:            chefInstallerURL,
+		DestinationDirectory: workingDir,
+		SourceChecksums:      map[string]string{"sha256": installerHash},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to download %v: %v", chefInstallerURL, err)
+	}
+	if !downloadOutput.IsHashMatched {
+		return "", fmt.Errorf("checksum of %v doesn't match ChefInstallerHash", chefInstallerURL)
+	}
+	return downloadOutput.LocalFilePath, nil
+}
+
+// findChefClient looks for chef-client on the path and in the default install locations.
+func findChefClient() (string, bool) {
+	for _, candidate := range chefClientCandidates {
+		if chefClient, err := lookPath(candidate); err == nil {
+			return chefClient, true
+		}
+	}
+	return "", false
+}
+
+// chefClientArguments writes the chef-client configuration and returns the arguments of a local mode run.
+func chefClientArguments(workingDir string, cookbookPath string, input *RunChefRecipePluginInput) ([]string, error) {
+	clientConfig := filepath.Join(workingDir, clientConfigName)
+	if err := fileutil.WriteAllText(clientConfig, clientConfigContent(workingDir, cookbookPath)); err != nil {
+		return nil, fmt.Errorf("failed to write chef-client configuration. %v", err)
+	}
+
+	commandArguments := []string{
+		"--local-mode",
+		"--config", clientConfig,
+		"--override-runlist", strings.Join(normalizeRunList(input.RunList), ","),
+		"--force-formatter",
+		"--no-color",
+	}
+
+	if input.JsonAttributes != "" {
+		attributes := filepath.Join(workingDir, jsonAttributesName)
+		if err := fileutil.WriteAllText(attributes, input.JsonAttributes); err != nil {
+			return nil, fmt.Errorf("failed to write json attributes. %v", err)
+		}
+		commandArguments = append(commandArguments, "--json-attributes", attributes)
+	}
+
+	if input.ChefLicense != "" {
+		commandArguments = append(commandArguments, "--chef-license", input.ChefLicense)
+	}
+	return commandArguments, nil
+}
+
+// clientConfigContent returns a chef-client configuration pointing local mode at the extracted cookbooks.
+// Cookbooks are accepted both at the root of the archive and under a cookbooks folder.
+func clientConfigContent(workingDir string, cookbookPath string) string {
+	quote := func(path string) string {
+		return "'" + strings.Replace(filepath.ToSlash(path), "'", "\\'", -1) + "'"
+	}
+
+	var buffer bytes.Buffer
+	buffer.WriteString("local_mode true\n")
+	buffer.WriteString(fmt.Sprintf("chef_repo_path %v\n", quote(workingDir)))
+	buffer.WriteString(fmt.Sprintf("cookbook_path [%v, %v]\n", quote(cookbookPath), quote(filepath.Join(cookbookPath, cookbooksDir))))
+	buffer.WriteString(fmt.Sprintf("file_cache_path %v\n", quote(filepath.Join(workingDir, cacheDir))))
+	return buffer.String()
+}
+
+// normalizeRunList wraps bare recipe names in recipe[], leaving recipe[] and role[] entries untouched.
+func normalizeRunList(runList []string) []string {
+	normalized := make([]string, 0, len(runList))
+	for _, item := range runList {
+		item = strings.TrimSpace(item)
+		if !strings.HasPrefix(item, "recipe[") && !strings.HasPrefix(item, "role[") {
+			item = "recipe[" + item + "]"
+		}
+		normalized = append(normalized, item)
+	}
+	return normalized
+}
+
+// parseAndValidateInput parses the plugin properties and validates them
+func parseAndValidateInput(rawPluginInput interface{}) (*RunChefRecipePluginInput, error) {
+	var input RunChefRecipePluginInput
+	if err := jsonutil.Remarshal(rawPluginInput, &input); err != nil {
+		return nil, fmt.Errorf("invalid format in plugin properties %v; \nerror %v", rawPluginInput, err)
+	}
+
+	if err := validateInput(&input); err != nil {
+		return nil, fmt.Errorf("invalid input: %v", err)
+	}
+	return &input, nil
+}
+
+// validateInput ensures the plugin input matches the defined schema
+func validateInput(input *RunChefRecipePluginInput) error {
+	if input.SourceUrl == "" {
+		return errors.New("SourceUrl must be specified")
+	}
+	if len(input.RunList) == 0 {
+		return errors.New("RunList must contain at least one recipe")
+	}
+	for _, item := range input.RunList {
+		if strings.TrimSpace(item) == "" || strings.Contains(item, ",") {
+			return fmt.Errorf("invalid RunList entry %q", item)
+		}
+	}
+	if input.JsonAttributes != "" {
+		var attributes map[string]interface{}
+		if err := json.Unmarshal([]byte(input.JsonAttributes), &attributes); err != nil {
+			return fmt.Errorf("JsonAttributes must be a json object: %v", err)
+		}
+	}
+	if !chefVersionPattern.MatchString(input.ChefClientVersion) {
+		return fmt.Errorf("invalid ChefClientVersion %q", input.ChefClientVersion)
+	}
+	return nil
+}
+
+// convergeSummary is a writer which picks the converge result out of the chef-client output.
+type convergeSummary struct {
+	line      []byte
+	found     bool
+	succeeded bool
+	updated   int
+	total     int
+}
+
+// Write scans the complete lines written so far for the converge result.
+func (s *convergeSummary) Write(p []byte) (int, error) {
+	s.line = append(s.line, p...)
+	for {
+		index := bytes.IndexByte(s.line, '\n')
+		if index < 0 {
+			break
+		}
+		s.parse(s.line[:index])
+		s.line = s.line[index+1:]
+	}
+	if len(s.line) > maxSummaryLineLength {
+		s.line = nil
+	}
+	return len(p), nil
+}
+
+// flush scans the last line if it wasn't terminated.
+func (s *convergeSummary) flush() {
+	s.parse(s.line)
+	s.line = nil
+}
+
+func (s *convergeSummary) parse(line []byte) {
+	match := convergeSummaryPattern.FindSubmatch(line)
+	if match == nil {
+		return
+	}
+	s.found = true
+	s.succeeded = string(match[1]) == "finished"
+	s.updated, _ = strconv.Atoi(string(match[2]))
+	s.total = -1
+	if len(match[3]) > 0 {
+		s.total, _ = strconv.Atoi(string(match[3]))
+	}
+}
@@ -0,0 +1,225 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package runchefrecipe implements the aws:runChefRecipe plugin.
+package runchefrecipe
+
+import (
+	"archive/zip"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/executers"
+	"github.com/aws/amazon-ssm-agent/agent/fileutil"
+	"github.com/aws/amazon-ssm-agent/agent/fileutil/artifact"
+	"github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler"
+	multiwritermock "github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler/multiwriter/mock"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const chefOutput = "Converging 10 resources\nRecipe: apache2::default\nChef Infra Client finished, 3/10 resources updated in 05 seconds\n"
+
+func TestValidateInput(t *testing.T) {
+	valid := RunChefRecipePluginInput{SourceUrl: "https://example.com/cookbooks.tar.gz", RunList: []string{"apache2"}}
+	assert.NoError(t, validateInput(&valid))
+
+	withAttributes := valid
+	withAttributes.JsonAttributes = `{"apache": {"port": 8080}}`
+	assert.NoError(t, validateInput(&withAttributes))
+
+	noSource := valid
+	noSource.SourceUrl = ""
+	assert.Error(t, validateInput(&noSource))
+
+	noRunList := valid
+	noRunList.RunList = nil
+	assert.Error(t, validateInput(&noRunList))
+
+	commaInRunList := valid
+	commaInRunList.RunList = []string{"apache2,mysql"}
+	assert.Error(t, validateInput(&commaInRunList))
+
+	invalidAttributes := valid
+	invalidAttributes.JsonAttributes = "port=8080"
+	assert.Error(t, validateInput(&invalidAttributes))
+
+	invalidVersion := valid
+	invalidVersion.ChefClientVersion = "15; rm -rf /"
+	assert.Error(t, validateInput(&invalidVersion))
+}
+
+func TestNormalizeRunList(t *testing.T) {
+	assert.Equal(t,
+		[]string{"recipe[apache2]", "recipe[apache2::mod_ssl]", "role[web]"},
+		normalizeRunList([]string{"apache2", " recipe[apache2::mod_ssl] ", "role[web]"}))
+}
+
+func TestConvergeSummary(t *testing.T) {
+	summary := &convergeSummary{}
+	summary.Write([]byte(chefOutput[:50]))
+	summary.Write([]byte(chefOutput[50:]))
+	summary.flush()
+	assert.True(t, summary.found)
+	assert.True(t, summary.succeeded)
+	assert.Equal(t, 3, summary.updated)
+	assert.Equal(t, 10, summary.total)
+
+	summary = &convergeSummary{}
+	summary.Write([]byte("Running handlers complete\nChef Client failed. 1 resources updated in 02 seconds"))
+	summary.flush()
+	assert.True(t, summary.found)
+	assert.False(t, summary.succeeded)
+	assert.Equal(t, 1, summary.updated)
+	assert.Equal(t, -1, summary.total)
+
+	summary = &convergeSummary{}
+	summary.Write([]byte("Starting Chef Infra Client\n"))
+	summary.flush()
+	assert.False(t, summary.found)
+}
+
+func TestRunChef(t *testing.T) {
+	dir, err := ioutil.TempDir("", "runchefrecipe")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	archive := filepath.Join(dir, "cookbooks.zip")
+	createCookbookArchive(t, archive)
+
+	defer func() {
+		download = artifact.Download
+		lookPath = exec.LookPath
+	}()
+	download = func(log log.T, input artifact.DownloadInput) (artifact.DownloadOutput, error) {
+		return artifact.DownloadOutput{LocalFilePath: archive}, nil
+	}
+	lookPath = func(file string) (string, error) {
+		return "/usr/bin/chef-client", nil
+	}
+
+	stdoutWriter := new(multiwritermock.MockDocumentIOMultiWriter)
+	stdoutWriter.On("Write", mock.Anything).Return(len(chefOutput), nil)
+	stdoutWriter.On("WriteString", mock.Anything).Return(0, nil)
+	output := &iohandler.DefaultIOHandler{}
+	output.StdoutWriter = stdoutWriter
+	output.StderrWriter = new(multiwritermock.MockDocumentIOMultiWriter)
+
+	cancelFlag := new(task.MockCancelFlag)
+	cancelFlag.On("Canceled").Return(false)
+	cancelFlag.On("ShutDown").Return(false)
+
+	mockExecuter := new(executers.MockCommandExecuter)
+	mockExecuter.On("NewExecute", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, "/usr/bin/chef-client", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			args.Get(2).(io.Writer).Write([]byte(chefOutput))
+		}).Return(0, nil)
+
+	p := &Plugin{CommandExecuter: mockExecuter}
+	input := &RunChefRecipePluginInput{
+		ID:             "0.aws:runChefRecipe",
+		SourceUrl:      "https://example.com/cookbooks.zip",
+		RunList:        []string{"apache2"},
+		JsonAttributes: `{"apache": {"port": 8080}}`,
+	}
+	p.runChef(log.NewMockLog(), input, dir, cancelFlag, output)
+
+	assert.Equal(t, contracts.ResultStatusSuccess, output.GetStatus())
+	stdoutWriter.AssertCalled(t, "WriteString", "Chef converge succeeded, 3/10 resources updated")
+
+	workingDir := fileutil.BuildPath(dir, input.ID)
+	assert.True(t, fileutil.Exists(filepath.Join(workingDir, cookbooksDir, "apache2", "metadata.rb")))
+	assert.True(t, fileutil.Exists(filepath.Join(workingDir, jsonAttributesName)))
+	commandArguments := mockExecuter.Calls[0].Arguments.Get(7).([]string)
+	assert.Contains(t, commandArguments, "recipe[apache2]")
+	assert.Contains(t, commandArguments, filepath.Join(workingDir, clientConfigName))
+}
+
+func TestResolveChefClientInstallsVerifiedInstaller(t *testing.T) {
+	defer func() {
+		download = artifact.Download
+		lookPath = exec.LookPath
+	}()
+	installed := false
+	lookPath = func(file string) (string, error) {
+		if installed {
+			return "/opt/chef/bin/chef-client", nil
+		}
+		return "", errors.New("not found")
+	}
+	var downloadInput artifact.DownloadInput
+	download = func(log log.T, input artifact.DownloadInput) (artifact.DownloadOutput, error) {
+		downloadInput = input
+		return artifact.DownloadOutput{LocalFilePath: "/tmp/install", IsHashMatched: true}, nil
+	}
+
+	mockExecuter := new(executers.MockCommandExecuter)
+	mockExecuter.On("NewExecute", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			installed = true
+		}).Return(0, nil)
+
+	p := &Plugin{CommandExecuter: mockExecuter}
+	input := &RunChefRecipePluginInput{ChefInstallerHash: "abc123"}
+	chefClient, err := p.resolveChefClient(log.NewMockLog(), input, "/tmp", nil, 3600, &iohandler.DefaultIOHandler{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "/opt/chef/bin/chef-client", chefClient)
+	assert.Equal(t, chefInstallerURL, downloadInput.SourceURL)
+	assert.Equal(t, map[string]string{"sha256": "abc123"}, downloadInput.SourceChecksums)
+	commandName, commandArguments := bootstrapCommand("/tmp/install", "")
+	mockExecuter.AssertCalled(t, "NewExecute", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, commandName, commandArguments, mock.Anything)
+}
+
+func TestResolveChefClientRejectsUnverifiedInstaller(t *testing.T) {
+	defer func() {
+		download = artifact.Download
+		lookPath = exec.LookPath
+	}()
+	lookPath = func(file string) (string, error) {
+		return "", errors.New("not found")
+	}
+	download = func(log log.T, input artifact.DownloadInput) (artifact.DownloadOutput, error) {
+		return artifact.DownloadOutput{LocalFilePath: "/tmp/install", IsHashMatched: false}, nil
+	}
+
+	mockExecuter := new(executers.MockCommandExecuter)
+	p := &Plugin{CommandExecuter: mockExecuter}
+
+	_, err := p.resolveChefClient(log.NewMockLog(), &RunChefRecipePluginInput{}, "/tmp", nil, 3600, &iohandler.DefaultIOHandler{})
+	assert.Error(t, err)
+
+	_, err = p.resolveChefClient(log.NewMockLog(), &RunChefRecipePluginInput{ChefInstallerHash: "abc123"}, "/tmp", nil, 3600, &iohandler.DefaultIOHandler{})
+	assert.Error(t, err)
+	mockExecuter.AssertNotCalled(t, "NewExecute", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func createCookbookArchive(t *testing.T, path string) {
+	file, err := os.Create(path)
+	assert.NoError(t, err)
+	defer file.Close()
+
+	writer := zip.NewWriter(file)
+	entry, err := writer.Create("apache2/metadata.rb")
+	assert.NoError(t, err)
+	entry.Write([]byte("name 'apache2'\n"))
+	assert.NoError(t, writer.Close())
+}
@@ -0,0 +1,33 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// +build windows
+
+package runchefrecipe
+
+import "strings"
+
+// chefClientCandidates are the chef-client executables looked up, in order.
+var chefClientCandidates = []string{"chef-client", `C:\opscode\chef\bin\chef-client.bat`}
+
+// chefInstallerURL is the omnitruck install script, downloaded and verified before it's run.
+const chefInstallerURL = "https://omnitruck.chef.io/install.ps1"
+
+// bootstrapCommand returns the command installing chef-client with the downloaded install script.
+func bootstrapCommand(installer string, version string) (string, []string) {
+	script := ". '" + strings.Replace(installer, "'", "''", -1) + "'; install"
+	if version != "" && version != "latest" {
+		script += " -version " + version
+	}
+	return "powershell", []string{"-NoProfile", "-NonInteractive", "-ExecutionPolicy", "Bypass", "-Command", script}
+}
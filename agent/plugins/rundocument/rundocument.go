@@ -20,11 +20,13 @@ import (
 	"github.com/aws/amazon-ssm-agent/agent/context"
 	"github.com/aws/amazon-ssm-agent/agent/contracts"
 	"github.com/aws/amazon-ssm-agent/agent/docparser"
+	"github.com/aws/amazon-ssm-agent/agent/fileutil/artifact"
 	"github.com/aws/amazon-ssm-agent/agent/fileutil/filemanager"
 	"github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/basicexecuter"
 	"github.com/aws/amazon-ssm-agent/agent/jsonutil"
 	"github.com/aws/amazon-ssm-agent/agent/log"
 	"github.com/aws/amazon-ssm-agent/agent/platform"
+	"github.com/aws/amazon-ssm-agent/agent/s3util"
 	ssmsvc "github.com/aws/amazon-ssm-agent/agent/ssm"
 	"github.com/aws/amazon-ssm-agent/agent/task"
 	"github.com/aws/amazon-ssm-agent/agent/times"
@@ -33,7 +35,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/url"
 	"path/filepath"
+	"sort"
 	"time"
 
 	"strings"
@@ -49,6 +53,7 @@ const (
 
 	SSMDocumentType = "SSMDocument"
 	LocalPathType   = "LocalPath"
+	S3Type          = "S3"
 
 	downloadsDir = "downloads" //Directory under the orchestration directory where the downloaded resource resides
 
@@ -56,6 +61,9 @@ const (
 	PassExitCode = 0
 )
 
+// Assign method to global variables to allow unittest to override
+var download = artifact.Download
+
 // NewPlugin returns a new instance of the plugin.
 func NewPlugin() (*Plugin, error) {
 	var plugin Plugin
@@ -141,6 +149,12 @@ func (p *Plugin) runDocument(context context.T, input *RunDocumentPluginInput, c
 	if input.DocumentType == SSMDocumentType {
 		if documentPath, err = p.downloadDocumentFromSSM(log, config, input); err != nil {
 			output.MarkAsFailed(err)
+			return
+		}
+	} else if input.DocumentType == S3Type {
+		if documentPath, err = downloadDocumentFromS3(log, config, input); err != nil {
+			output.MarkAsFailed(err)
+			return
 		}
 	} else {
 		if filepath.IsAbs(input.DocumentPath) {
@@ -165,6 +179,7 @@ func (p *Plugin) runDocument(context context.T, input *RunDocumentPluginInput, c
 	var pluginOutput map[string]*contracts.PluginResult
 	if resultsChannel, err = p.execDoc.ExecuteDocument(config, context, pluginsInfo, config.BookKeepingFileName, times.ToIso8601UTC(time.Now())); err != nil {
 		output.MarkAsFailed(fmt.Errorf("There was an error while running documents - %v", err.Error()))
+		return
 	}
 	for res := range resultsChannel {
 		if res.LastPlugin == "" {
@@ -174,8 +189,10 @@ func (p *Plugin) runDocument(context context.T, input *RunDocumentPluginInput, c
 	}
 	if pluginOutput == nil {
 		output.MarkAsFailed(errors.New("No output obtained from executing document"))
+		return
 	}
-	for _, pluginOut := range pluginOutput {
+	for _, pluginID := range orderedResultIDs(pluginsInfo, pluginOutput) {
+		pluginOut := pluginOutput[pluginID]
 		if pluginOut.StandardOutput != "" {
 			// separating the append so that the output is on a new line
 			output.AppendInfof("%v", pluginOut.StandardOutput)
@@ -189,6 +206,44 @@ func (p *Plugin) runDocument(context context.T, input *RunDocumentPluginInput, c
 		}
 		output.SetStatus(contracts.MergeResultStatus(output.GetStatus(), pluginOut.Status))
 	}
+	appendStepStatuses(pluginsInfo, pluginOutput, output)
+}
+
+// orderedResultIDs returns the ids of the results in the order of the steps of the sub-document,
+// results of unknown steps come last.
+func orderedResultIDs(pluginsInfo []contracts.PluginState, pluginOutput map[string]*contracts.PluginResult) []string {
+	var ids []string
+	ordered := make(map[string]bool)
+	for _, plugin := range pluginsInfo {
+		if _, ok := pluginOutput[plugin.Id]; ok && !ordered[plugin.Id] {
+			ids = append(ids, plugin.Id)
+			ordered[plugin.Id] = true
+		}
+	}
+	var remaining []string
+	for id := range pluginOutput {
+		if !ordered[id] {
+			remaining = append(remaining, id)
+		}
+	}
+	sort.Strings(remaining)
+	return append(ids, remaining...)
+}
+
+// appendStepStatuses appends the status of every step of the sub-document so that the parent reply shows which step failed.
+func appendStepStatuses(pluginsInfo []contracts.PluginState, pluginOutput map[string]*contracts.PluginResult, output iohandler.IOHandler) {
+	if len(pluginsInfo) < 2 {
+		return
+	}
+	var statuses []string
+	for _, plugin := range pluginsInfo {
+		status := contracts.ResultStatusNotStarted
+		if pluginOut, ok := pluginOutput[plugin.Id]; ok {
+			status = pluginOut.Status
+		}
+		statuses = append(statuses, fmt.Sprintf("%v (%v): %v", plugin.Id, plugin.Name, status))
+	}
+	output.AppendInfof("Sub-document steps:\n%v", strings.Join(statuses, "\n"))
 }
 
 func (p *Plugin) downloadDocumentFromSSM(log log.T, config contracts.Configuration, input *RunDocumentPluginInput) (string, error) {
@@ -220,6 +275,27 @@ func (p *Plugin) downloadDocumentFromSSM(log log.T, config contracts.Configurati
 
 }
 
+// downloadDocumentFromS3 downloads the document from the S3 url to the downloads folder.
+func downloadDocumentFromS3(log log.T, config contracts.Configuration, input *RunDocumentPluginInput) (string, error) {
+	documentURL, err := url.Parse(input.DocumentPath)
+	if err != nil {
+		return "", err
+	}
+	if !s3util.ParseAmazonS3URL(log, documentURL).IsValidS3URI {
+		return "", fmt.Errorf("Document path %v is not a valid S3 url", input.DocumentPath)
+	}
+
+	downloadOutput, err := download(log, artifact.DownloadInput{
+		SourceURL:            input.DocumentPath,
+		DestinationDirectory: filepath.Join(config.OrchestrationDirectory, downloadsDir),
+	})
+	if err != nil {
+		log.Errorf("Unable to download document from S3. %v", err)
+		return "", err
+	}
+	return downloadOutput.LocalFilePath, nil
+}
+
 // PrepareDocumentForExecution parses the raw content of the document, validates it and returns a PluginState that can be executed.
 func (p *Plugin) prepareDocumentForExecution(log log.T, pathToFile string, config contracts.Configuration, params interface{}) (pluginsInfo []contracts.PluginState, err error) {
 	parameters := make(map[string]interface{})
@@ -279,9 +355,9 @@ func parseAndValidateInput(rawPluginInput interface{}) (*RunDocumentPluginInput,
 func validateInput(input *RunDocumentPluginInput) (valid bool, err error) {
 	// ensure non-empty location type
 	if input.DocumentType == "" {
-		return false, errors.New("Document Type must be specified to either by SSMDocument or LocalPath.")
+		return false, errors.New("Document Type must be specified to either by SSMDocument, S3 or LocalPath.")
 	}
-	if input.DocumentType != SSMDocumentType && input.DocumentType != LocalPathType {
+	if input.DocumentType != SSMDocumentType && input.DocumentType != LocalPathType && input.DocumentType != S3Type {
 		return false, errors.New("Document type specified in invalid")
 	}
 	if input.DocumentPath == "" {
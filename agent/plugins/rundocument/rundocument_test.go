@@ -25,10 +25,13 @@ import (
 	"time"
 
 	"io/ioutil"
+	"path/filepath"
 
 	"github.com/aws/amazon-ssm-agent/agent/context"
 	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/fileutil/artifact"
 	filemock "github.com/aws/amazon-ssm-agent/agent/fileutil/filemanager/mock"
+	"github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler"
 	iohandlermocks "github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler/mock"
 	executermocks "github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/mock"
 	"github.com/aws/amazon-ssm-agent/agent/log"
@@ -542,7 +545,7 @@ func TestValidateInput_NoDocumentType(t *testing.T) {
 
 	assert.False(t, result)
 	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "Document Type must be specified to either by SSMDocument or LocalPath.")
+	assert.Contains(t, err.Error(), "Document Type must be specified to either by SSMDocument, S3 or LocalPath.")
 
 }
 func TestValidateInput_UnknownDocumentType(t *testing.T) {
@@ -651,3 +654,71 @@ func loadFile(t *testing.T, fileName string) (result []byte) {
 	}
 	return
 }
+
+func TestValidateInput_S3DocumentType(t *testing.T) {
+	input := RunDocumentPluginInput{
+		DocumentType: S3Type,
+		DocumentPath: "https://s3.amazonaws.com/bucket/documents/install.json",
+	}
+	valid, err := validateInput(&input)
+
+	assert.True(t, valid)
+	assert.NoError(t, err)
+}
+
+func TestPlugin_RunDocumentFromS3AggregatesStepStatuses(t *testing.T) {
+	execMock := NewExecMock()
+	fileMock := filemock.FileSystemMock{}
+	output := &iohandler.DefaultIOHandler{}
+	conf := createStubConfiguration("orch", "bucket", "prefix", "1234-1234-1234", "directory")
+
+	documentURL := "https://s3.amazonaws.com/bucket/documents/install.json"
+	defer func() { download = artifact.Download }()
+	download = func(log log.T, input artifact.DownloadInput) (artifact.DownloadOutput, error) {
+		assert.Equal(t, documentURL, input.SourceURL)
+		assert.Equal(t, filepath.Join("orch", downloadsDir), input.DestinationDirectory)
+		return artifact.DownloadOutput{LocalFilePath: "orch/downloads/install.json"}, nil
+	}
+
+	plugins := []contracts.PluginState{
+		{Id: "install", Name: "aws:runShellScript"},
+		{Id: "configure", Name: "aws:runShellScript"},
+		{Id: "verify", Name: "aws:runShellScript"},
+	}
+	pluginResults := map[string]*contracts.PluginResult{
+		"configure": {PluginID: "configure", Status: contracts.ResultStatusFailed, StandardOutput: "configuring", StandardError: "permission denied"},
+		"install":   {PluginID: "install", Status: contracts.ResultStatusSuccess, StandardOutput: "installing"},
+	}
+	resChan := make(chan contracts.DocumentResult, 1)
+	resChan <- contracts.DocumentResult{Status: contracts.ResultStatusFailed, PluginResults: pluginResults}
+	close(resChan)
+
+	content := "content"
+	fileMock.On("ReadFile", "orch/downloads/install.json").Return(content, nil)
+	execMock.On("ParseDocument", contextMock.Log(), []byte(content), conf.OrchestrationDirectory, conf.OutputS3BucketName, conf.OutputS3KeyPrefix, conf.MessageId, conf.PluginID, conf.DefaultWorkingDirectory, map[string]interface{}{}).Return(plugins, nil)
+	execMock.On("ExecuteDocument", contextMock, mock.Anything, conf.BookKeepingFileName, mock.Anything).Return(resChan, nil)
+
+	p := Plugin{
+		filesys: fileMock,
+		execDoc: execMock,
+	}
+	p.runDocument(contextMock, &RunDocumentPluginInput{DocumentType: S3Type, DocumentPath: documentURL}, conf, output)
+
+	execMock.AssertExpectations(t)
+	assert.Equal(t, contracts.ResultStatusFailed, output.GetStatus())
+	assert.Equal(t, "installing\nconfiguring\nSub-document steps:\n"+
+		"install (aws:runShellScript): Success\n"+
+		"configure (aws:runShellScript): Failed\n"+
+		"verify (aws:runShellScript): NotStarted", output.GetStdout())
+	assert.Equal(t, "permission denied", output.GetStderr())
+}
+
+func TestPlugin_RunDocumentFromInvalidS3URL(t *testing.T) {
+	output := &iohandler.DefaultIOHandler{}
+	conf := createStubConfiguration("orch", "bucket", "prefix", "1234-1234-1234", "directory")
+
+	p := Plugin{}
+	p.runDocument(contextMock, &RunDocumentPluginInput{DocumentType: S3Type, DocumentPath: "https://example.com/install.json"}, conf, output)
+
+	assert.Equal(t, contracts.ResultStatusFailed, output.GetStatus())
+}
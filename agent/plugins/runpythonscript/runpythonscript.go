@@ -0,0 +1,277 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package runpythonscript implements the aws:runPythonScript plugin.
+package runpythonscript
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/executers"
+	"github.com/aws/amazon-ssm-agent/agent/fileutil"
+	"github.com/aws/amazon-ssm-agent/agent/fileutil/artifact"
+	"github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler"
+	"github.com/aws/amazon-ssm-agent/agent/jsonutil"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/plugins/pluginutil"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+)
+
+const (
+	downloadsDir = "downloads" //Directory under the orchestration directory where the downloaded resource resides
+
+	scriptName       = "_script.py"       // Name of the file the inline script is written to
+	requirementsName = "requirements.txt" // Name of the file holding the pip requirements
+)
+
+// parameterNamePattern restricts parameter names to valid environment variable names.
+var parameterNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Assign method to global variables to allow unittest to override
+var download = artifact.Download
+var lookPath = exec.LookPath
+
+// Plugin is the type for the aws:runPythonScript plugin.
+type Plugin struct {
+	// CommandExecuter is an object that can execute commands.
+	CommandExecuter executers.T
+}
+
+// RunPythonScriptPluginInput represents one python script executed by the aws:runPythonScript plugin.
+type RunPythonScriptPluginInput struct {
+	contracts.PluginInput
+	ID               string
+	Script           []string
+	SourceUrl        string
+	SourceHash       string
+	Interpreter      string
+	VirtualEnv       string
+	Requirements     []string
+	Parameters       map[string]string
+	WorkingDirectory string
+	TimeoutSeconds   interface{}
+	// ExitCodeMapping maps exit codes of the script to the status of the plugin
+	ExitCodeMapping []pluginutil.ExitCodeStatus
+}
+
+// NewPlugin returns a new instance of the plugin.
+func NewPlugin() (*Plugin, error) {
+	var plugin Plugin
+	plugin.CommandExecuter = executers.ShellCommandExecuter{}
+
+	return &plugin, nil
+}
+
+// Name returns the plugin name
+func Name() string {
+	return appconfig.PluginNameAwsRunPythonScript
+}
+
+// Execute installs the pip requirements and runs the python script.
+func (p *Plugin) Execute(context context.T, config contracts.Configuration, cancelFlag task.CancelFlag, output iohandler.IOHandler) {
+	log := context.Log()
+	log.Infof("%v started with configuration %v", Name(), config)
+
+	if cancelFlag.ShutDown() {
+		output.MarkAsShutdown()
+	} else if cancelFlag.Canceled() {
+		output.MarkAsCancelled()
+	} else if input, err := parseAndValidateInput(config.Properties); err != nil {
+		output.MarkAsFailed(err)
+	} else {
+		p.runPython(log, input, config.PluginID, config.OrchestrationDirectory, config.DefaultWorkingDirectory, cancelFlag, output)
+	}
+}
+
+// runPython executes one python script and reports its exit code.
+func (p *Plugin) runPython(log log.T, input *RunPythonScriptPluginInput, pluginID string, orchestrationDirectory string, defaultWorkingDirectory string, cancelFlag task.CancelFlag, output iohandler.IOHandler) {
+	var workingDir string
+	if filepath.IsAbs(input.WorkingDirectory) {
+		workingDir = input.WorkingDirectory
+	} else {
+		orchestrationDir := strings.TrimSuffix(orchestrationDirectory, pluginID)
+		workingDir = filepath.Join(orchestrationDir, downloadsDir, input.WorkingDirectory)
+		if !fileutil.Exists(workingDir) {
+			workingDir = defaultWorkingDirectory
+		}
+	}
+
+	orchestrationDir := fileutil.BuildPath(orchestrationDirectory, input.ID)
+	if err := fileutil.MakeDirsWithExecuteAccess(orchestrationDir); err != nil {
+		output.MarkAsFailed(fmt.Errorf("failed to create orchestrationDir directory, %v", orchestrationDir))
+		return
+	}
+
+	interpreter, err := resolveInterpreter(input.Interpreter, input.VirtualEnv)
+	if err != nil {
+		output.MarkAsFailed(err)
+		return
+	}
+
+	scriptPath, err := stageScript(log, input, orchestrationDir)
+	if err != nil {
+		output.MarkAsFailed(err)
+		return
+	}
+
+	executionTimeout := pluginutil.ValidateExecutionTimeout(log, input.TimeoutSeconds)
+
+	if len(input.Requirements) > 0 {
+		if err := p.installRequirements(log, interpreter, input.Requirements, orchestrationDir, workingDir, cancelFlag, executionTimeout, output); err != nil {
+			output.MarkAsFailed(err)
+			return
+		}
+	}
+
+	// the mapping is validated with the input
+	exitCodeMapping, _ := pluginutil.ParseExitCodeMapping(input.ExitCodeMapping)
+
+	// the parameters are only passed in the environment of the script, they may hold secrets which aren't written to disk
+	commandArguments := []string{scriptPath}
+	log.Debugf("Running %v %v in workingDirectory %v", interpreter, commandArguments, workingDir)
+	exitCode, err := p.CommandExecuter.NewExecute(log, workingDir, output.GetStdoutWriter(), output.GetStderrWriter(), cancelFlag, executionTimeout, interpreter, commandArguments, input.Parameters)
+
+	output.SetExitCode(exitCode)
+	mapped := exitCodeMapping.SetStatus(output, exitCode, cancelFlag)
+
+	if err != nil && !mapped {
+		status := output.GetStatus()
+		if status != contracts.ResultStatusCancelled &&
+			status != contracts.ResultStatusTimedOut &&
+			status != contracts.ResultStatusSuccessAndReboot {
+			output.MarkAsFailed(fmt.Errorf("failed to run python script: %v", err))
+		}
+	}
+}
+
+// installRequirements pip installs the requirements with the interpreter running the script.
+func (p *Plugin) installRequirements(log log.T, interpreter string, requirements []string, orchestrationDir string, workingDir string, cancelFlag task.CancelFlag, executionTimeout int, output iohandler.IOHandler) error {
+	requirementsPath := filepath.Join(orchestrationDir, requirementsName)
+	if err := fileutil.WriteAllText(requirementsPath, strings.Join(requirements, "\n")+"\n"); err != nil {
+		return fmt.Errorf("failed to write pip requirements. %v", err)
+	}
+
+	output.AppendInfof("Installing pip requirements %v", strings.Join(requirements, ", "))
+	commandArguments := []string{"-m", "pip", "install", "--disable-pip-version-check", "-r", requirementsPath}
+	exitCode, err := p.CommandExecuter.NewExecute(log, workingDir, output.GetStdoutWriter(), output.GetStderrWriter(), cancelFlag, executionTimeout, interpreter, commandArguments, nil)
+	if err != nil || exitCode != appconfig.SuccessExitCode {
+		return fmt.Errorf("failed to install pip requirements, exit code %v: %v", exitCode, err)
+	}
+	return nil
+}
+
+// stageScript returns the path of the script, writing the inline script or downloading it first.
+func stageScript(log log.T, input *RunPythonScriptPluginInput, orchestrationDir string) (string, error) {
+	if input.SourceUrl == "" {
+		scriptPath := filepath.Join(orchestrationDir, scriptName)
+		if err := pluginutil.CreateScriptFile(log, scriptPath, input.Script, fileutil.ByteOrderMarkSkip); err != nil {
+			return "", fmt.Errorf("failed to create script file. %v", err)
+		}
+		return scriptPath, nil
+	}
+
+	downloadInput := artifact.DownloadInput{
+		SourceURL:            input.SourceUrl,
+		DestinationDirectory: orchestrationDir,
+	}
+	if input.SourceHash != "" {
+		downloadInput.SourceChecksums = map[string]string{"sha256": input.SourceHash}
+	}
+	downloadOutput, err := download(log, downloadInput)
+	if err != nil {
+		return "", fmt.Errorf("failed to download %v: %v", input.SourceUrl, err)
+	}
+	if !downloadOutput.IsHashMatched {
+		return "", fmt.Errorf("checksum of %v doesn't match SourceHash", input.SourceUrl)
+	}
+	return downloadOutput.LocalFilePath, nil
+}
+
+// resolveInterpreter returns the python executable of the virtual env, the given interpreter or the first python found on the path.
+func resolveInterpreter(interpreter string, virtualEnv string) (string, error) {
+	if virtualEnv != "" {
+		python := filepath.Join(virtualEnv, virtualEnvPython)
+		if !fileutil.Exists(python) {
+			return "", fmt.Errorf("VirtualEnv %v has no python interpreter at %v", virtualEnv, python)
+		}
+		return python, nil
+	}
+
+	candidates := pythonCandidates
+	if interpreter != "" {
+		candidates = []string{interpreter}
+	}
+	for _, candidate := range candidates {
+		if python, err := lookPath(candidate); err == nil {
+			return python, nil
+		}
+	}
+	if interpreter != "" {
+		return "", fmt.Errorf("Interpreter %v was not found", interpreter)
+	}
+	return "", errors.New("python was not found, python 3 must be installed to run python scripts")
+}
+
+// parseAndValidateInput parses the plugin properties and validates them
+func parseAndValidateInput(rawPluginInput interface{}) (*RunPythonScriptPluginInput, error) {
+	var input RunPythonScriptPluginInput
+	if err := jsonutil.Remarshal(rawPluginInput, &input); err != nil {
+		return nil, fmt.Errorf("invalid format in plugin properties %v; \nerror %v", rawPluginInput, err)
+	}
+
+	if err := validateInput(&input); err != nil {
+		return nil, fmt.Errorf("invalid input: %v", err)
+	}
+	return &input, nil
+}
+
+// validateInput ensures the plugin input matches the defined schema
+func validateInput(input *RunPythonScriptPluginInput) error {
+	if len(input.Script) == 0 && input.SourceUrl == "" {
+		return errors.New("either Script or SourceUrl must be specified")
+	}
+	if len(input.Script) > 0 && input.SourceUrl != "" {
+		return errors.New("only one of Script and SourceUrl can be specified")
+	}
+	if input.SourceUrl == "" && input.SourceHash != "" {
+		return errors.New("SourceHash requires SourceUrl")
+	}
+	if input.Interpreter != "" && input.VirtualEnv != "" {
+		return errors.New("only one of Interpreter and VirtualEnv can be specified")
+	}
+	if input.VirtualEnv != "" && !filepath.IsAbs(input.VirtualEnv) {
+		return fmt.Errorf("VirtualEnv %v must be an absolute path", input.VirtualEnv)
+	}
+	for _, requirement := range input.Requirements {
+		if strings.TrimSpace(requirement) == "" || strings.ContainsAny(requirement, "\r\n") {
+			return fmt.Errorf("invalid Requirements entry %q", requirement)
+		}
+	}
+	for name := range input.Parameters {
+		if !parameterNamePattern.MatchString(name) {
+			return fmt.Errorf("invalid Parameters name %q, names must be valid environment variable names", name)
+		}
+	}
+	if _, err := pluginutil.ParseExitCodeMapping(input.ExitCodeMapping); err != nil {
+		return err
+	}
+	return nil
+}
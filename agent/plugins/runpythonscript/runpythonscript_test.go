@@ -0,0 +1,145 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package runpythonscript implements the aws:runPythonScript plugin.
+package runpythonscript
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/executers"
+	"github.com/aws/amazon-ssm-agent/agent/fileutil"
+	"github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler"
+	multiwritermock "github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler/multiwriter/mock"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestValidateInput(t *testing.T) {
+	valid := RunPythonScriptPluginInput{Script: []string{"print('hello')"}, Parameters: map[string]string{"APP_PORT": "8080"}}
+	assert.NoError(t, validateInput(&valid))
+
+	noScript := valid
+	noScript.Script = nil
+	assert.Error(t, validateInput(&noScript))
+
+	bothSources := valid
+	bothSources.SourceUrl = "https://example.com/script.py"
+	assert.Error(t, validateInput(&bothSources))
+
+	interpreterAndVirtualEnv := valid
+	interpreterAndVirtualEnv.Interpreter = "python3"
+	interpreterAndVirtualEnv.VirtualEnv = "/opt/venv"
+	assert.Error(t, validateInput(&interpreterAndVirtualEnv))
+
+	relativeVirtualEnv := valid
+	relativeVirtualEnv.VirtualEnv = "venv"
+	assert.Error(t, validateInput(&relativeVirtualEnv))
+
+	invalidRequirement := valid
+	invalidRequirement.Requirements = []string{"requests\n--index-url http://example.com"}
+	assert.Error(t, validateInput(&invalidRequirement))
+
+	invalidParameter := valid
+	invalidParameter.Parameters = map[string]string{"APP-PORT": "8080"}
+	assert.Error(t, validateInput(&invalidParameter))
+}
+
+func TestResolveInterpreter(t *testing.T) {
+	defer func() { lookPath = exec.LookPath }()
+	lookPath = func(file string) (string, error) {
+		if file == pythonCandidates[0] {
+			return "/usr/bin/" + file, nil
+		}
+		return "", exec.ErrNotFound
+	}
+
+	python, err := resolveInterpreter("", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "/usr/bin/"+pythonCandidates[0], python)
+
+	_, err = resolveInterpreter("python3.11", "")
+	assert.Error(t, err)
+
+	dir, err := ioutil.TempDir("", "venv")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	_, err = resolveInterpreter("", dir)
+	assert.Error(t, err)
+
+	venvPython := filepath.Join(dir, virtualEnvPython)
+	assert.NoError(t, os.MkdirAll(filepath.Dir(venvPython), 0755))
+	assert.NoError(t, ioutil.WriteFile(venvPython, nil, 0755))
+	python, err = resolveInterpreter("", dir)
+	assert.NoError(t, err)
+	assert.Equal(t, venvPython, python)
+}
+
+func TestRunPython(t *testing.T) {
+	dir, err := ioutil.TempDir("", "runpythonscript")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	defer func() { lookPath = exec.LookPath }()
+	lookPath = func(file string) (string, error) {
+		return "/usr/bin/python3", nil
+	}
+
+	stdoutWriter := new(multiwritermock.MockDocumentIOMultiWriter)
+	stdoutWriter.On("WriteString", mock.Anything).Return(0, nil)
+	output := &iohandler.DefaultIOHandler{}
+	output.StdoutWriter = stdoutWriter
+	output.StderrWriter = new(multiwritermock.MockDocumentIOMultiWriter)
+
+	cancelFlag := new(task.MockCancelFlag)
+	cancelFlag.On("Canceled").Return(false)
+	cancelFlag.On("ShutDown").Return(false)
+
+	mockExecuter := new(executers.MockCommandExecuter)
+	mockExecuter.On("NewExecute", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, "/usr/bin/python3", mock.Anything, mock.Anything).Return(0, nil)
+
+	p := &Plugin{CommandExecuter: mockExecuter}
+	input := &RunPythonScriptPluginInput{
+		ID:           "0.aws:runPythonScript",
+		Script:       []string{"import os", "print(os.environ['APP_PORT'])"},
+		Requirements: []string{"requests==2.31.0"},
+		Parameters:   map[string]string{"APP_PORT": "8080"},
+	}
+	p.runPython(log.NewMockLog(), input, "aws:runPythonScript", dir, dir, cancelFlag, output)
+
+	assert.Equal(t, contracts.ResultStatusSuccess, output.GetStatus())
+	orchestrationDir := fileutil.BuildPath(dir, input.ID)
+	assert.Equal(t,
+		[]string{"-m", "pip", "install", "--disable-pip-version-check", "-r", filepath.Join(orchestrationDir, requirementsName)},
+		mockExecuter.Calls[0].Arguments.Get(7).([]string))
+	assert.Equal(t,
+		[]string{filepath.Join(orchestrationDir, scriptName)},
+		mockExecuter.Calls[1].Arguments.Get(7).([]string))
+	assert.Nil(t, mockExecuter.Calls[0].Arguments.Get(8))
+	assert.Equal(t, map[string]string{"APP_PORT": "8080"}, mockExecuter.Calls[1].Arguments.Get(8))
+
+	// the parameters are never written to the orchestration directory
+	files, _ := ioutil.ReadDir(orchestrationDir)
+	for _, file := range files {
+		assert.Contains(t, []string{scriptName, requirementsName}, file.Name())
+	}
+	requirements, _ := ioutil.ReadFile(filepath.Join(orchestrationDir, requirementsName))
+	assert.Equal(t, "requests==2.31.0\n", string(requirements))
+}
@@ -0,0 +1,311 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package runsaltstate implements the aws:runSaltState plugin.
+package runsaltstate
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/executers"
+	"github.com/aws/amazon-ssm-agent/agent/fileutil"
+	"github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler"
+	"github.com/aws/amazon-ssm-agent/agent/jsonutil"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/plugins/pluginutil"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+)
+
+const (
+	downloadsDir = "downloads" //Directory under the orchestration directory where the downloaded resource resides
+
+	// stateKeySeparator separates the module, id, name and function in the keys of salt state results
+	stateKeySeparator = "_|-"
+)
+
+// Assign method to global variables to allow unittest to override
+var lookPath = exec.LookPath
+
+// Plugin is the type for the aws:runSaltState plugin.
+type Plugin struct {
+	// CommandExecuter is an object that can execute commands.
+	CommandExecuter executers.T
+}
+
+// RunSaltStatePluginInput represents one salt-call run executed by the aws:runSaltState plugin.
+type RunSaltStatePluginInput struct {
+	contracts.PluginInput
+	ID             string
+	States         []string
+	StateTree      string
+	Pillar         interface{}
+	Test           bool
+	TimeoutSeconds interface{}
+}
+
+// StateResult is the outcome of one salt state.
+type StateResult struct {
+	ID       string
+	Function string
+	Status   contracts.ResultStatus
+	Changed  bool
+	Comment  string
+	runNum   int
+}
+
+// saltStateReturn is one entry of the salt-call json output.
+type saltStateReturn struct {
+	Result  *bool                  `json:"result"`
+	Changes map[string]interface{} `json:"changes"`
+	Comment interface{}            `json:"comment"`
+	RunNum  int                    `json:"__run_num__"`
+}
+
+// NewPlugin returns a new instance of the plugin.
+func NewPlugin() (*Plugin, error) {
+	var plugin Plugin
+	plugin.CommandExecuter = executers.ShellCommandExecuter{}
+
+	return &plugin, nil
+}
+
+// Name returns the plugin name
+func Name() string {
+	return appconfig.PluginNameAwsRunSaltState
+}
+
+// Execute applies the requested salt states masterless and reports the result of each state.
+func (p *Plugin) Execute(context context.T, config contracts.Configuration, cancelFlag task.CancelFlag, output iohandler.IOHandler) {
+	log := context.Log()
+	log.Infof("%v started with configuration %v", Name(), config)
+
+	if cancelFlag.ShutDown() {
+		output.MarkAsShutdown()
+	} else if cancelFlag.Canceled() {
+		output.MarkAsCancelled()
+	} else if input, pillar, err := parseAndValidateInput(config.Properties); err != nil {
+		output.MarkAsFailed(err)
+	} else {
+		p.runSalt(log, input, pillar, config.PluginID, config.OrchestrationDirectory, config.DefaultWorkingDirectory, cancelFlag, output)
+	}
+}
+
+// runSalt executes salt-call and maps the state results to the plugin status.
+func (p *Plugin) runSalt(log log.T, input *RunSaltStatePluginInput, pillar string, pluginID string, orchestrationDirectory string, defaultWorkingDirectory string, cancelFlag task.CancelFlag, output iohandler.IOHandler) {
+	saltCall, found := findSaltCall()
+	if !found {
+		output.MarkAsFailed(errors.New("salt-call was not found, salt minion must be installed to apply salt states"))
+		return
+	}
+
+	workingDir := defaultWorkingDirectory
+	commandArguments := []string{"--local", "--out=json", "--retcode-passthrough"}
+	if input.StateTree != "" {
+		stateTree := input.StateTree
+		if !filepath.IsAbs(stateTree) {
+			orchestrationDir := strings.TrimSuffix(orchestrationDirectory, pluginID)
+			stateTree = filepath.Join(orchestrationDir, downloadsDir, stateTree)
+		}
+		if !fileutil.Exists(stateTree) {
+			output.MarkAsFailed(fmt.Errorf("StateTree %v does not exist", stateTree))
+			return
+		}
+		workingDir = stateTree
+		commandArguments = append(commandArguments, "--file-root="+stateTree)
+	}
+
+	commandArguments = append(commandArguments, "state.apply")
+	if len(input.States) > 0 {
+		commandArguments = append(commandArguments, strings.Join(input.States, ","))
+	}
+	if pillar != "" {
+		commandArguments = append(commandArguments, "pillar="+pillar)
+	}
+	if input.Test {
+		commandArguments = append(commandArguments, "test=True")
+	}
+
+	executionTimeout := pluginutil.ValidateExecutionTimeout(log, input.TimeoutSeconds)
+
+	var stdout bytes.Buffer
+	log.Debugf("Running %v %v in workingDirectory %v", saltCall, commandArguments, workingDir)
+	exitCode, err := p.CommandExecuter.NewExecute(log, workingDir, io.MultiWriter(&stdout, output.GetStdoutWriter()), output.GetStderrWriter(), cancelFlag, executionTimeout, saltCall, commandArguments, nil)
+
+	output.SetExitCode(exitCode)
+	status := pluginutil.GetStatus(exitCode, cancelFlag)
+	if status == contracts.ResultStatusCancelled ||
+		status == contracts.ResultStatusTimedOut ||
+		status == contracts.ResultStatusSuccessAndReboot {
+		output.SetStatus(status)
+		return
+	}
+
+	// --retcode-passthrough makes salt-call exit non-zero when a state fails, so the output is parsed regardless
+	// of the exit code and the status comes from the state results
+	results, parseErr := parseStateResults(stdout.Bytes())
+	if parseErr != nil {
+		if err != nil {
+			output.MarkAsFailed(fmt.Errorf("failed to run salt-call: %v, %v", err, parseErr))
+		} else {
+			output.MarkAsFailed(fmt.Errorf("failed to parse salt-call output: %v", parseErr))
+		}
+		return
+	}
+	output.SetStatus(contracts.ResultStatusSuccess)
+	reportStateResults(results, output)
+}
+
+// reportStateResults appends one line per state and fails the plugin if any state failed, whatever the exit code.
+func reportStateResults(results []StateResult, output iohandler.IOHandler) {
+	var succeeded, changed, failed, skipped int
+	for _, result := range results {
+		switch result.Status {
+		case contracts.ResultStatusSuccess:
+			succeeded++
+		case contracts.ResultStatusFailed:
+			failed++
+		default:
+			skipped++
+		}
+		if result.Changed {
+			changed++
+		}
+		output.AppendInfof("[%v] %v (%v): %v", result.Status, result.ID, result.Function, result.Comment)
+	}
+	output.AppendInfof("Salt states: %v succeeded, %v changed, %v failed, %v skipped", succeeded, changed, failed, skipped)
+
+	if failed > 0 {
+		output.MarkAsFailed(fmt.Errorf("%v salt states failed", failed))
+	}
+}
+
+// parseStateResults parses the json output of salt-call state.apply.
+// Salt returns a list of errors instead of state results when it fails to render the states.
+func parseStateResults(stdout []byte) ([]StateResult, error) {
+	var output struct {
+		Local json.RawMessage `json:"local"`
+	}
+	if err := json.Unmarshal(stdout, &output); err != nil {
+		return nil, err
+	}
+
+	var renderErrors []string
+	if err := json.Unmarshal(output.Local, &renderErrors); err == nil {
+		return nil, fmt.Errorf("salt failed to render the states: %v", strings.Join(renderErrors, "; "))
+	}
+
+	var returns map[string]saltStateReturn
+	if err := json.Unmarshal(output.Local, &returns); err != nil {
+		return nil, err
+	}
+
+	results := make([]StateResult, 0, len(returns))
+	for key, ret := range returns {
+		results = append(results, newStateResult(key, ret))
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].runNum < results[j].runNum
+	})
+	return results, nil
+}
+
+// newStateResult maps a salt state return to a plugin result status,
+// a state without result is one test mode would change.
+func newStateResult(key string, ret saltStateReturn) StateResult {
+	result := StateResult{
+		ID:       key,
+		Function: key,
+		Changed:  len(ret.Changes) > 0,
+		Comment:  fmt.Sprint(ret.Comment),
+		runNum:   ret.RunNum,
+	}
+	if parts := strings.Split(key, stateKeySeparator); len(parts) == 4 {
+		result.ID = parts[1]
+		result.Function = parts[0] + "." + parts[3]
+	}
+
+	switch {
+	case ret.Result == nil:
+		result.Status = contracts.ResultStatusSkipped
+	case *ret.Result:
+		result.Status = contracts.ResultStatusSuccess
+	default:
+		result.Status = contracts.ResultStatusFailed
+	}
+	return result
+}
+
+// findSaltCall looks for salt-call on the path and in the default install locations.
+func findSaltCall() (string, bool) {
+	for _, candidate := range saltCallCandidates {
+		if saltCall, err := lookPath(candidate); err == nil {
+			return saltCall, true
+		}
+	}
+	return "", false
+}
+
+// parseAndValidateInput parses the plugin properties and returns them along with the pillar data as json
+func parseAndValidateInput(rawPluginInput interface{}) (*RunSaltStatePluginInput, string, error) {
+	var input RunSaltStatePluginInput
+	if err := jsonutil.Remarshal(rawPluginInput, &input); err != nil {
+		return nil, "", fmt.Errorf("invalid format in plugin properties %v; \nerror %v", rawPluginInput, err)
+	}
+
+	for _, state := range input.States {
+		if state == "" || strings.ContainsAny(state, ", \t") {
+			return nil, "", fmt.Errorf("invalid input: invalid state name %q", state)
+		}
+	}
+
+	pillar, err := pillarJSON(input.Pillar)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid input: %v", err)
+	}
+	return &input, pillar, nil
+}
+
+// pillarJSON returns the pillar data as a json object, it can be given as an object or as a json string.
+func pillarJSON(pillar interface{}) (string, error) {
+	if text, ok := pillar.(string); ok {
+		if strings.TrimSpace(text) == "" {
+			return "", nil
+		}
+		if err := json.Unmarshal([]byte(text), &pillar); err != nil {
+			return "", fmt.Errorf("Pillar must be a json object: %v", err)
+		}
+	}
+	if pillar == nil {
+		return "", nil
+	}
+	if _, ok := pillar.(map[string]interface{}); !ok {
+		return "", errors.New("Pillar must be a json object")
+	}
+
+	content, err := json.Marshal(pillar)
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
@@ -0,0 +1,152 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package runsaltstate implements the aws:runSaltState plugin.
+package runsaltstate
+
+import (
+	"errors"
+	"io"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/executers"
+	"github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler"
+	multiwritermock "github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler/multiwriter/mock"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const saltOutput = `{"local": {
+	"service_|-nginx_|-nginx_|-running": {"result": false, "changes": {}, "comment": "Service nginx failed to start", "__run_num__": 1},
+	"pkg_|-nginx_|-nginx_|-installed": {"result": true, "changes": {"nginx": {"new": "1.12", "old": ""}}, "comment": "1 package installed", "__run_num__": 0},
+	"file_|-config_|-/etc/nginx/nginx.conf_|-managed": {"result": null, "changes": {}, "comment": "The file would be updated", "__run_num__": 2}
+}}`
+
+func TestParseStateResults(t *testing.T) {
+	results, err := parseStateResults([]byte(saltOutput))
+	assert.NoError(t, err)
+	assert.Equal(t, []StateResult{
+		{ID: "nginx", Function: "pkg.installed", Status: contracts.ResultStatusSuccess, Changed: true, Comment: "1 package installed", runNum: 0},
+		{ID: "nginx", Function: "service.running", Status: contracts.ResultStatusFailed, Comment: "Service nginx failed to start", runNum: 1},
+		{ID: "config", Function: "file.managed", Status: contracts.ResultStatusSkipped, Comment: "The file would be updated", runNum: 2},
+	}, results)
+
+	_, err = parseStateResults([]byte(`{"local": ["Rendering SLS 'base:nginx' failed"]}`))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Rendering SLS")
+
+	_, err = parseStateResults([]byte("not json"))
+	assert.Error(t, err)
+}
+
+func TestParseAndValidateInput(t *testing.T) {
+	input, pillar, err := parseAndValidateInput(map[string]interface{}{
+		"States": []string{"nginx", "users"},
+		"Pillar": `{"port": 8080}`,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"nginx", "users"}, input.States)
+	assert.Equal(t, `{"port":8080}`, pillar)
+
+	_, pillar, err = parseAndValidateInput(map[string]interface{}{
+		"Pillar": map[string]interface{}{"port": 8080},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"port":8080}`, pillar)
+
+	_, pillar, err = parseAndValidateInput(map[string]interface{}{"Pillar": ""})
+	assert.NoError(t, err)
+	assert.Empty(t, pillar)
+
+	_, _, err = parseAndValidateInput(map[string]interface{}{"Pillar": "[1, 2]"})
+	assert.Error(t, err)
+
+	_, _, err = parseAndValidateInput(map[string]interface{}{"States": []string{"nginx,users"}})
+	assert.Error(t, err)
+}
+
+const saltSuccessOutput = `{"local": {
+	"pkg_|-nginx_|-nginx_|-installed": {"result": true, "changes": {}, "comment": "All specified packages are already installed", "__run_num__": 0}
+}}`
+
+// runSaltWithOutput runs the plugin with a salt-call that prints stdout and exits with the exit code and error
+func runSaltWithOutput(t *testing.T, stdout string, exitCode int, err error) (*iohandler.DefaultIOHandler, *multiwritermock.MockDocumentIOMultiWriter, *executers.MockCommandExecuter) {
+	defer func() { lookPath = exec.LookPath }()
+	lookPath = func(file string) (string, error) {
+		return "/usr/bin/salt-call", nil
+	}
+
+	stdoutWriter := new(multiwritermock.MockDocumentIOMultiWriter)
+	stdoutWriter.On("Write", mock.Anything).Return(len(stdout), nil)
+	stdoutWriter.On("WriteString", mock.Anything).Return(0, nil)
+	stderrWriter := new(multiwritermock.MockDocumentIOMultiWriter)
+	stderrWriter.On("WriteString", mock.Anything).Return(0, nil)
+	output := &iohandler.DefaultIOHandler{}
+	output.StdoutWriter = stdoutWriter
+	output.StderrWriter = stderrWriter
+
+	cancelFlag := new(task.MockCancelFlag)
+	cancelFlag.On("Canceled").Return(false)
+	cancelFlag.On("ShutDown").Return(false)
+
+	mockExecuter := new(executers.MockCommandExecuter)
+	mockExecuter.On("NewExecute", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, "/usr/bin/salt-call", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			args.Get(2).(io.Writer).Write([]byte(stdout))
+		}).Return(exitCode, err)
+
+	p := &Plugin{CommandExecuter: mockExecuter}
+	input := &RunSaltStatePluginInput{States: []string{"nginx"}, Test: true}
+	p.runSalt(log.NewMockLog(), input, `{"port":8080}`, "aws:runSaltState", "OrchesDir", "", cancelFlag, output)
+	return output, stdoutWriter, mockExecuter
+}
+
+func TestRunSalt(t *testing.T) {
+	output, stdoutWriter, mockExecuter := runSaltWithOutput(t, saltOutput, 0, nil)
+
+	assert.Equal(t, contracts.ResultStatusFailed, output.GetStatus())
+	stdoutWriter.AssertCalled(t, "WriteString", "[Success] nginx (pkg.installed): 1 package installed")
+	stdoutWriter.AssertCalled(t, "WriteString", "Salt states: 1 succeeded, 1 changed, 1 failed, 1 skipped")
+	assert.Equal(t,
+		[]string{"--local", "--out=json", "--retcode-passthrough", "state.apply", "nginx", `pillar={"port":8080}`, "test=True"},
+		mockExecuter.Calls[0].Arguments.Get(7).([]string))
+}
+
+func TestRunSaltReportsStatesOnFailingExitCode(t *testing.T) {
+	// salt-call exits with 2 when a state fails with --retcode-passthrough
+	output, stdoutWriter, _ := runSaltWithOutput(t, saltOutput, 2, errors.New("exit status 2"))
+
+	assert.Equal(t, contracts.ResultStatusFailed, output.GetStatus())
+	stdoutWriter.AssertCalled(t, "WriteString", "[Failed] nginx (service.running): Service nginx failed to start")
+	stdoutWriter.AssertCalled(t, "WriteString", "Salt states: 1 succeeded, 1 changed, 1 failed, 1 skipped")
+}
+
+func TestRunSaltSucceeds(t *testing.T) {
+	output, stdoutWriter, _ := runSaltWithOutput(t, saltSuccessOutput, 0, nil)
+
+	assert.Equal(t, contracts.ResultStatusSuccess, output.GetStatus())
+	stdoutWriter.AssertCalled(t, "WriteString", "Salt states: 1 succeeded, 0 changed, 0 failed, 0 skipped")
+}
+
+func TestRunSaltRenderErrors(t *testing.T) {
+	output, stdoutWriter, _ := runSaltWithOutput(t, `{"local": ["Rendering SLS 'base:nginx' failed"]}`, 1, errors.New("exit status 1"))
+
+	assert.Equal(t, contracts.ResultStatusFailed, output.GetStatus())
+	assert.Equal(t, 1, output.GetExitCode())
+	stdoutWriter.AssertNotCalled(t, "WriteString", mock.MatchedBy(func(line string) bool { return strings.HasPrefix(line, "Salt states:") }))
+}
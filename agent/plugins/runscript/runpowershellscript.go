@@ -19,6 +19,7 @@ import (
 	"strings"
 
 	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/context"
 	"github.com/aws/amazon-ssm-agent/agent/executers"
 	"github.com/aws/amazon-ssm-agent/agent/fileutil"
 )
@@ -32,12 +33,12 @@ type runPowerShellPlugin struct {
 }
 
 // NewRunPowerShellPlugin returns a new instance of the PSPlugin.
-func NewRunPowerShellPlugin() (*runPowerShellPlugin, error) {
+func NewRunPowerShellPlugin(context context.T) (*runPowerShellPlugin, error) {
 	psplugin := runPowerShellPlugin{
 		Plugin{
 			Name:            appconfig.PluginNameAwsRunPowerShellScript,
 			ScriptName:      powerShellScriptName,
-			ShellCommand:    appconfig.PowerShellPluginCommandName,
+			ShellCommand:    powerShellCommand(context.AppConfig().Agent.PowerShellPath),
 			ShellArguments:  strings.Split(appconfig.PowerShellPluginCommandArgs, " "),
 			ByteOrderMark:   fileutil.ByteOrderMarkEmit,
 			CommandExecuter: executers.ShellCommandExecuter{},
@@ -46,3 +47,17 @@ func NewRunPowerShellPlugin() (*runPowerShellPlugin, error) {
 
 	return &psplugin, nil
 }
+
+// powerShellCommand returns the configured PowerShell binary or the first one installed on the instance.
+// PowerShell is looked up every time the plugin runs, so a document can install pwsh and use it in a later step.
+func powerShellCommand(configuredPath string) string {
+	if configuredPath != "" {
+		return configuredPath
+	}
+	for _, path := range appconfig.PowerShellPluginCommandPaths {
+		if fileutil.Exists(path) {
+			return path
+		}
+	}
+	return appconfig.PowerShellPluginCommandName
+}
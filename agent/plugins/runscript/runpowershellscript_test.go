@@ -0,0 +1,42 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package runscript
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPowerShellCommand(t *testing.T) {
+	dir, err := ioutil.TempDir("", "powershell")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	installed := filepath.Join(dir, "pwsh")
+	assert.NoError(t, ioutil.WriteFile(installed, nil, 0700))
+
+	pathsOrig := appconfig.PowerShellPluginCommandPaths
+	defer func() { appconfig.PowerShellPluginCommandPaths = pathsOrig }()
+	appconfig.PowerShellPluginCommandPaths = []string{filepath.Join(dir, "missing", "pwsh"), installed}
+
+	assert.Equal(t, installed, powerShellCommand(""))
+	assert.Equal(t, "/opt/powershell/pwsh", powerShellCommand("/opt/powershell/pwsh"))
+
+	appconfig.PowerShellPluginCommandPaths = []string{filepath.Join(dir, "missing", "pwsh")}
+	assert.Equal(t, appconfig.PowerShellPluginCommandName, powerShellCommand(""))
+}
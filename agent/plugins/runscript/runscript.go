@@ -16,10 +16,14 @@ package runscript
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
 	"path/filepath"
-
+	"regexp"
 	"strings"
 
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
 	"github.com/aws/amazon-ssm-agent/agent/context"
 	"github.com/aws/amazon-ssm-agent/agent/contracts"
 	"github.com/aws/amazon-ssm-agent/agent/executers"
@@ -35,6 +39,16 @@ const (
 	downloadsDir = "downloads" //Directory under the orchestration directory where the downloaded resource resides
 )
 
+// environmentVariableName matches the names of environment variables that can be set for the commands
+var environmentVariableName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// supportedShells are the shells that can be selected by name, any other shell is selected by its absolute path
+var supportedShells = []string{"bash", "sh", "zsh"}
+
+// Assign method to global variables to allow unittest to override
+var changeOwner = executers.ChangeOwner
+var lookPath = exec.LookPath
+
 // Plugin is the type for the runscript plugin.
 type Plugin struct {
 	// ExecuteCommand is an object that can execute commands.
@@ -53,14 +67,26 @@ type RunScriptPluginInput struct {
 	RunCommand       []string
 	ID               string
 	WorkingDirectory string
-	TimeoutSeconds   interface{}
+	// CreateWorkingDirectory creates the working directory if it doesn't exist, instead of failing
+	CreateWorkingDirectory bool
+	TimeoutSeconds         interface{}
+	// Environment holds the environment variables set for the commands, their values are never logged
+	Environment map[string]string
+	// RunAsUser and RunAsGroup are the account the commands run as, only supported by aws:runShellScript
+	RunAsUser  string
+	RunAsGroup string
+	// Shell is bash, sh, zsh or the absolute path of the shell the commands run with, only supported by aws:runShellScript.
+	// Without a shell, commands starting with a shebang such as #!/usr/bin/env python3 run with the interpreter of the shebang
+	Shell string
+	// ExitCodeMapping maps exit codes of the commands to the status of the plugin
+	ExitCodeMapping []pluginutil.ExitCodeStatus
 }
 
 // Execute runs multiple sets of commands and returns their outputs.
 // res.Output will contain a slice of RunScriptPluginOutput.
 func (p *Plugin) Execute(context context.T, config contracts.Configuration, cancelFlag task.CancelFlag, output iohandler.IOHandler) {
 	log := context.Log()
-	log.Infof("%v started with configuration %v", p.Name, config)
+	log.Infof("%v started with plugin id %v", p.Name, config.PluginID)
 	log.Debugf("DefaultWorkingDirectory %v", config.DefaultWorkingDirectory)
 
 	if cancelFlag.ShutDown() {
@@ -78,7 +104,7 @@ func (p *Plugin) runCommandsRawInput(log log.T, pluginID string, rawPluginInput
 	var pluginInput RunScriptPluginInput
 	err := jsonutil.Remarshal(rawPluginInput, &pluginInput)
 	if err != nil {
-		errorString := fmt.Errorf("Invalid format in plugin properties %v;\nerror %v", rawPluginInput, err)
+		errorString := fmt.Errorf("Invalid format in plugin properties;\nerror %v", err)
 		output.MarkAsFailed(errorString)
 		return
 	}
@@ -88,17 +114,39 @@ func (p *Plugin) runCommandsRawInput(log log.T, pluginID string, rawPluginInput
 // runCommands executes one set of commands and returns their output.
 func (p *Plugin) runCommands(log log.T, pluginID string, pluginInput RunScriptPluginInput, orchestrationDirectory string, defaultWorkingDirectory string, cancelFlag task.CancelFlag, output iohandler.IOHandler) {
 	var err error
-	var workingDir string
 
-	if filepath.IsAbs(pluginInput.WorkingDirectory) {
-		workingDir = pluginInput.WorkingDirectory
-	} else {
-		orchestrationDir := strings.TrimSuffix(orchestrationDirectory, pluginID)
-		// The Document path is expected to have the name of the document
-		workingDir = filepath.Join(orchestrationDir, downloadsDir, pluginInput.WorkingDirectory)
-		if !fileutil.Exists(workingDir) {
-			workingDir = defaultWorkingDirectory
-		}
+	if err = validateEnvironment(pluginInput.Environment); err != nil {
+		output.MarkAsFailed(err)
+		return
+	}
+	if err = p.validateRunAs(pluginInput); err != nil {
+		output.MarkAsFailed(err)
+		return
+	}
+	runAs := executers.RunAs{User: pluginInput.RunAsUser, Group: pluginInput.RunAsGroup}
+
+	exitCodeMapping, err := pluginutil.ParseExitCodeMapping(pluginInput.ExitCodeMapping)
+	if err != nil {
+		output.MarkAsFailed(err)
+		return
+	}
+
+	shell, err := p.resolveShell(pluginInput.Shell)
+	if err != nil {
+		output.MarkAsFailed(err)
+		return
+	}
+
+	interpreter, err := p.resolveInterpreter(shell, pluginInput.RunCommand)
+	if err != nil {
+		output.MarkAsFailed(err)
+		return
+	}
+
+	workingDir, err := resolveWorkingDirectory(log, pluginInput, pluginID, orchestrationDirectory, defaultWorkingDirectory, runAs)
+	if err != nil {
+		output.MarkAsFailed(err)
+		return
 	}
 
 	// TODO:MF: This subdirectory is only needed because we could be running multiple sets of properties for the same plugin - otherwise the orchestration directory would already be unique
@@ -112,14 +160,31 @@ func (p *Plugin) runCommands(log log.T, pluginID string, pluginInput RunScriptPl
 	}
 
 	// Create script file path
-	scriptPath := filepath.Join(orchestrationDir, p.ScriptName)
-	log.Debugf("Writing commands %v to file %v", pluginInput, scriptPath)
+	scriptDir := orchestrationDir
+	if runAs.User != "" {
+		// the orchestration directory is only accessible to the agent, so the script goes to a directory the user owns
+		if scriptDir, err = ioutil.TempDir("", "ssm-script"); err != nil {
+			output.MarkAsFailed(fmt.Errorf("failed to create script directory. %v", err))
+			return
+		}
+		defer os.RemoveAll(scriptDir)
+	}
+	scriptPath := filepath.Join(scriptDir, p.ScriptName)
+	log.Debugf("Writing commands %v to file %v", pluginInput.RunCommand, scriptPath)
 
 	// Create script file
 	if err = pluginutil.CreateScriptFile(log, scriptPath, pluginInput.RunCommand, p.ByteOrderMark); err != nil {
 		output.MarkAsFailed(fmt.Errorf("failed to create script file. %v", err))
 		return
 	}
+	if runAs.User != "" {
+		for _, path := range []string{scriptDir, scriptPath} {
+			if err = changeOwner(path, runAs); err != nil {
+				output.MarkAsFailed(fmt.Errorf("failed to give user %v access to the script file. %v", runAs.User, err))
+				return
+			}
+		}
+	}
 
 	// Set execution time
 	executionTimeout := pluginutil.ValidateExecutionTimeout(log, pluginInput.TimeoutSeconds)
@@ -127,15 +192,32 @@ func (p *Plugin) runCommands(log log.T, pluginID string, pluginInput RunScriptPl
 	// Construct Command Name and Arguments
 	commandName := p.ShellCommand
 	commandArguments := append(p.ShellArguments, scriptPath)
+	if shell != "" {
+		// the script is read by the shell rather than started by the default shell, so that its interpreter is the one selected
+		log.Debugf("Running commands with shell %v", shell)
+		commandName = shell
+		commandArguments = []string{scriptPath}
+	} else if interpreter != "" {
+		// the executable script is started directly, so that the kernel runs it with the interpreter of its shebang
+		log.Debugf("Running commands with interpreter %v", interpreter)
+		commandName = scriptPath
+		commandArguments = []string{}
+	}
 
 	// Execute Command
-	exitCode, err := p.CommandExecuter.NewExecute(log, workingDir, output.GetStdoutWriter(), output.GetStderrWriter(), cancelFlag, executionTimeout, commandName, commandArguments)
+	var exitCode int
+	if runAs.User != "" {
+		log.Debugf("Running commands as user %v", runAs.User)
+		exitCode, err = p.CommandExecuter.NewExecuteAs(log, workingDir, output.GetStdoutWriter(), output.GetStderrWriter(), cancelFlag, executionTimeout, commandName, commandArguments, pluginInput.Environment, runAs)
+	} else {
+		exitCode, err = p.CommandExecuter.NewExecute(log, workingDir, output.GetStdoutWriter(), output.GetStderrWriter(), cancelFlag, executionTimeout, commandName, commandArguments, pluginInput.Environment)
+	}
 
 	// Set output status
 	output.SetExitCode(exitCode)
-	output.SetStatus(pluginutil.GetStatus(exitCode, cancelFlag))
+	mapped := exitCodeMapping.SetStatus(output, exitCode, cancelFlag)
 
-	if err != nil {
+	if err != nil && !mapped {
 		status := output.GetStatus()
 		if status != contracts.ResultStatusCancelled &&
 			status != contracts.ResultStatusTimedOut &&
@@ -144,3 +226,121 @@ func (p *Plugin) runCommands(log log.T, pluginID string, pluginInput RunScriptPl
 		}
 	}
 }
+
+// validateEnvironment checks the names of the environment variables, the values are not validated as they can hold anything.
+func validateEnvironment(environment map[string]string) error {
+	for name := range environment {
+		if !environmentVariableName.MatchString(name) {
+			return fmt.Errorf("Invalid environment variable name %v, names must contain only letters, digits and underscores and not start with a digit", name)
+		}
+	}
+	return nil
+}
+
+// validateRunAs checks the user is only set for plugins that support it and a group is only set together with a user.
+// The executer checks the user and group exist.
+func (p *Plugin) validateRunAs(pluginInput RunScriptPluginInput) error {
+	if pluginInput.RunAsUser == "" {
+		if pluginInput.RunAsGroup != "" {
+			return fmt.Errorf("RunAsGroup %v can only be set together with RunAsUser", pluginInput.RunAsGroup)
+		}
+		return nil
+	}
+	if p.Name != appconfig.PluginNameAwsRunShellScript {
+		return fmt.Errorf("RunAsUser is not supported by %v", p.Name)
+	}
+	return nil
+}
+
+// resolveShell returns the path of the shell selected for the commands, empty if the default shell of the plugin is used
+func (p *Plugin) resolveShell(shell string) (string, error) {
+	if shell == "" {
+		return "", nil
+	}
+	if p.Name != appconfig.PluginNameAwsRunShellScript {
+		return "", fmt.Errorf("Shell is not supported by %v", p.Name)
+	}
+
+	if !filepath.IsAbs(shell) {
+		for _, supported := range supportedShells {
+			if shell == supported {
+				path, err := lookPath(shell)
+				if err != nil {
+					return "", fmt.Errorf("Shell %v is not installed, %v", shell, err)
+				}
+				return path, nil
+			}
+		}
+		return "", fmt.Errorf("Invalid shell %v, the shell must be one of %v or an absolute path", shell, strings.Join(supportedShells, ", "))
+	}
+
+	info, err := os.Stat(shell)
+	if err != nil {
+		return "", fmt.Errorf("Shell %v cannot be found, %v", shell, err)
+	}
+	if info.IsDir() || info.Mode()&0111 == 0 {
+		return "", fmt.Errorf("Shell %v is not an executable file", shell)
+	}
+	return shell, nil
+}
+
+// resolveInterpreter returns the interpreter of the shebang the commands start with, empty if the commands have no shebang
+// or are run by a shell. Only the interpreter itself is checked, the program /usr/bin/env looks for is found when the script runs.
+func (p *Plugin) resolveInterpreter(shell string, runCommand []string) (string, error) {
+	if shell != "" || p.Name != appconfig.PluginNameAwsRunShellScript || len(runCommand) == 0 || !strings.HasPrefix(runCommand[0], "#!") {
+		return "", nil
+	}
+
+	line := strings.SplitN(runCommand[0], "\n", 2)[0]
+	fields := strings.Fields(strings.TrimPrefix(line, "#!"))
+	if len(fields) == 0 || !filepath.IsAbs(fields[0]) {
+		return "", fmt.Errorf("Invalid shebang %v, the interpreter must be an absolute path", line)
+	}
+
+	info, err := os.Stat(fields[0])
+	if err != nil {
+		return "", fmt.Errorf("Interpreter %v of the shebang cannot be found, %v", fields[0], err)
+	}
+	if info.IsDir() || info.Mode()&0111 == 0 {
+		return "", fmt.Errorf("Interpreter %v of the shebang is not an executable file", fields[0])
+	}
+	return fields[0], nil
+}
+
+// resolveWorkingDirectory returns the directory the commands run in. Relative directories are in the downloads directory
+// of the document, the default working directory is used if they are missing. Absolute directories must exist
+// unless CreateWorkingDirectory is set, in which case they are created and given to the user the commands run as.
+func resolveWorkingDirectory(log log.T, pluginInput RunScriptPluginInput, pluginID string, orchestrationDirectory string, defaultWorkingDirectory string, runAs executers.RunAs) (string, error) {
+	workingDir := pluginInput.WorkingDirectory
+	if !filepath.IsAbs(workingDir) {
+		orchestrationDir := strings.TrimSuffix(orchestrationDirectory, pluginID)
+		// The Document path is expected to have the name of the document
+		workingDir = filepath.Join(orchestrationDir, downloadsDir, pluginInput.WorkingDirectory)
+		if !fileutil.Exists(workingDir) && (pluginInput.WorkingDirectory == "" || !pluginInput.CreateWorkingDirectory) {
+			return defaultWorkingDirectory, nil
+		}
+	}
+
+	info, err := os.Stat(workingDir)
+	switch {
+	case err == nil && !info.IsDir():
+		return "", fmt.Errorf("Working directory %v is not a directory", workingDir)
+	case err == nil:
+		return workingDir, nil
+	case !os.IsNotExist(err):
+		return "", fmt.Errorf("Working directory %v cannot be accessed, %v", workingDir, err)
+	case !pluginInput.CreateWorkingDirectory:
+		return "", fmt.Errorf("Working directory %v does not exist, set createWorkingDirectory to create it", workingDir)
+	}
+
+	log.Infof("Creating working directory %v", workingDir)
+	if err = fileutil.MakeDirsWithExecuteAccess(workingDir); err != nil {
+		return "", err
+	}
+	if runAs.User != "" {
+		if err = changeOwner(workingDir, runAs); err != nil {
+			return "", fmt.Errorf("failed to give user %v access to the working directory. %v", runAs.User, err)
+		}
+	}
+	return workingDir, nil
+}
@@ -14,17 +14,24 @@
 package runscript
 
 import (
+	"errors"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"testing"
 
 	"github.com/aws/amazon-ssm-agent/agent/context"
 	"github.com/aws/amazon-ssm-agent/agent/contracts"
 	"github.com/aws/amazon-ssm-agent/agent/executers"
+	"github.com/aws/amazon-ssm-agent/agent/fileutil"
 	"github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler"
 	iohandlermocks "github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler/mock"
 	multiwritermock "github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler/multiwriter/mock"
 	"github.com/aws/amazon-ssm-agent/agent/jsonutil"
 	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/plugins/pluginutil"
 	"github.com/aws/amazon-ssm-agent/agent/task"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -65,8 +72,9 @@ func generateTestCaseOk(id string) TestCase {
 	input := RunScriptPluginInput{
 		RunCommand:       []string{"echo " + id},
 		ID:               id + ".aws:runScript",
-		WorkingDirectory: "/Dir" + id,
+		WorkingDirectory: os.TempDir(),
 		TimeoutSeconds:   "1",
+		Environment:      map[string]string{"TEST_CASE_ID": id},
 	}
 	testCase := TestCase{
 		Input:  input,
@@ -149,6 +157,259 @@ func testRunScripts(t *testing.T, testCase TestCase, rawInput bool) {
 	testExecution(t, runScriptTester)
 }
 
+// TestRunScriptsInvalidEnvironment tests that commands don't run if an environment variable name is invalid.
+func TestRunScriptsInvalidEnvironment(t *testing.T) {
+	for _, name := range []string{"", "1ST_VALUE", "MY-VALUE", "PATH=/tmp:$PATH"} {
+		testCase := generateTestCaseOk("0")
+		testCase.Input.Environment = map[string]string{name: "value"}
+		runScriptTester := func(p *Plugin, mockCancelFlag *task.MockCancelFlag, mockExecuter *executers.MockCommandExecuter, mockIOHandler *iohandlermocks.MockIOHandler) {
+			mockIOHandler.On("MarkAsFailed", mock.Anything).Return()
+
+			p.runCommands(logger, pluginID, testCase.Input, orchestrationDirectory, defaultWorkingDirectory, mockCancelFlag, mockIOHandler)
+		}
+
+		testExecution(t, runScriptTester)
+	}
+}
+
+// TestRunScriptsAsUser tests that the script is given to the user and run as the user.
+func TestRunScriptsAsUser(t *testing.T) {
+	defer func() { changeOwner = executers.ChangeOwner }()
+	var owned []string
+	changeOwner = func(path string, runAs executers.RunAs) error {
+		owned = append(owned, path)
+		return nil
+	}
+
+	testCase := generateTestCaseOk("0")
+	testCase.Input.RunAsUser = "ssm-user"
+	runAs := executers.RunAs{User: "ssm-user"}
+	runScriptTester := func(p *Plugin, mockCancelFlag *task.MockCancelFlag, mockExecuter *executers.MockCommandExecuter, mockIOHandler *iohandlermocks.MockIOHandler) {
+		mockExecuter.On("NewExecuteAs", mock.Anything, testCase.Input.WorkingDirectory, testCase.Output.StdoutWriter, testCase.Output.StderrWriter, mockCancelFlag, mock.Anything, "sh", mock.Anything, testCase.Input.Environment, runAs).Return(0, nil)
+		setIOHandlerExpectations(mockIOHandler, testCase)
+
+		p.runCommands(logger, pluginID, testCase.Input, orchestrationDirectory, defaultWorkingDirectory, mockCancelFlag, mockIOHandler)
+
+		commandArguments := mockExecuter.Calls[0].Arguments.Get(7).([]string)
+		scriptPath := commandArguments[len(commandArguments)-1]
+		assert.Equal(t, []string{filepath.Dir(scriptPath), scriptPath}, owned)
+		assert.NotContains(t, scriptPath, orchestrationDirectory)
+		assert.False(t, fileutil.Exists(scriptPath), "the script of the user is removed after it ran")
+	}
+
+	testExecution(t, runScriptTester)
+}
+
+// TestRunScriptsInvalidRunAs tests that commands don't run if the user is not supported.
+func TestRunScriptsInvalidRunAs(t *testing.T) {
+	for _, input := range []struct{ pluginName, user, group string }{
+		{"aws:runShellScript", "", "ssm-users"},
+		{"aws:runPowerShellScript", "ssm-user", ""},
+	} {
+		testCase := generateTestCaseOk("0")
+		testCase.Input.RunAsUser = input.user
+		testCase.Input.RunAsGroup = input.group
+		runScriptTester := func(p *Plugin, mockCancelFlag *task.MockCancelFlag, mockExecuter *executers.MockCommandExecuter, mockIOHandler *iohandlermocks.MockIOHandler) {
+			p.Name = input.pluginName
+			mockIOHandler.On("MarkAsFailed", mock.Anything).Return()
+
+			p.runCommands(logger, pluginID, testCase.Input, orchestrationDirectory, defaultWorkingDirectory, mockCancelFlag, mockIOHandler)
+		}
+
+		testExecution(t, runScriptTester)
+	}
+}
+
+// TestRunScriptsWithShell tests that the script is read by the selected shell.
+func TestRunScriptsWithShell(t *testing.T) {
+	defer func() { lookPath = exec.LookPath }()
+	lookPath = func(file string) (string, error) { return "/usr/bin/" + file, nil }
+
+	testCase := generateTestCaseOk("0")
+	testCase.Input.Shell = "zsh"
+	runScriptTester := func(p *Plugin, mockCancelFlag *task.MockCancelFlag, mockExecuter *executers.MockCommandExecuter, mockIOHandler *iohandlermocks.MockIOHandler) {
+		mockExecuter.On("NewExecute", mock.Anything, testCase.Input.WorkingDirectory, testCase.Output.StdoutWriter, testCase.Output.StderrWriter, mockCancelFlag, mock.Anything, "/usr/bin/zsh", mock.Anything, testCase.Input.Environment).Return(0, nil)
+		setIOHandlerExpectations(mockIOHandler, testCase)
+
+		p.runCommands(logger, pluginID, testCase.Input, orchestrationDirectory, defaultWorkingDirectory, mockCancelFlag, mockIOHandler)
+
+		commandArguments := mockExecuter.Calls[0].Arguments.Get(7).([]string)
+		assert.Len(t, commandArguments, 1)
+		assert.Equal(t, "_script.sh", filepath.Base(commandArguments[0]))
+	}
+
+	testExecution(t, runScriptTester)
+}
+
+// TestRunScriptsWithShebang tests that commands starting with a shebang are executed directly.
+func TestRunScriptsWithShebang(t *testing.T) {
+	testCase := generateTestCaseOk("0")
+	testCase.Input.RunCommand = []string{"#!/bin/sh -e", "echo hello"}
+	runScriptTester := func(p *Plugin, mockCancelFlag *task.MockCancelFlag, mockExecuter *executers.MockCommandExecuter, mockIOHandler *iohandlermocks.MockIOHandler) {
+		mockExecuter.On("NewExecute", mock.Anything, testCase.Input.WorkingDirectory, testCase.Output.StdoutWriter, testCase.Output.StderrWriter, mockCancelFlag, mock.Anything, mock.Anything, mock.Anything, testCase.Input.Environment).Return(0, nil)
+		setIOHandlerExpectations(mockIOHandler, testCase)
+
+		p.runCommands(logger, pluginID, testCase.Input, orchestrationDirectory, defaultWorkingDirectory, mockCancelFlag, mockIOHandler)
+
+		commandName := mockExecuter.Calls[0].Arguments.Get(6).(string)
+		assert.Equal(t, "_script.sh", filepath.Base(commandName))
+		assert.Empty(t, mockExecuter.Calls[0].Arguments.Get(7).([]string))
+	}
+
+	testExecution(t, runScriptTester)
+}
+
+// TestRunScriptsWithExitCodeMapping tests that a mapped exit code sets the status instead of failing the plugin.
+func TestRunScriptsWithExitCodeMapping(t *testing.T) {
+	testCase := generateTestCaseOk("0")
+	testCase.Input.ExitCodeMapping = []pluginutil.ExitCodeStatus{{ExitCodes: "2-3", Status: "Success"}}
+	testCase.Output.ExitCode = 2
+	runScriptTester := func(p *Plugin, mockCancelFlag *task.MockCancelFlag, mockExecuter *executers.MockCommandExecuter, mockIOHandler *iohandlermocks.MockIOHandler) {
+		mockExecuter.On("NewExecute", mock.Anything, testCase.Input.WorkingDirectory, testCase.Output.StdoutWriter, testCase.Output.StderrWriter, mockCancelFlag, mock.Anything, mock.Anything, mock.Anything, testCase.Input.Environment).Return(2, errors.New("exit status 2"))
+		setIOHandlerExpectations(mockIOHandler, testCase)
+
+		p.runCommands(logger, pluginID, testCase.Input, orchestrationDirectory, defaultWorkingDirectory, mockCancelFlag, mockIOHandler)
+
+		mockIOHandler.AssertNotCalled(t, "MarkAsFailed", mock.Anything)
+	}
+
+	testExecution(t, runScriptTester)
+
+	testCase.Input.ExitCodeMapping = []pluginutil.ExitCodeStatus{{ExitCodes: "2", Status: "Done"}}
+	runScriptTester = func(p *Plugin, mockCancelFlag *task.MockCancelFlag, mockExecuter *executers.MockCommandExecuter, mockIOHandler *iohandlermocks.MockIOHandler) {
+		mockIOHandler.On("MarkAsFailed", mock.Anything).Return()
+
+		p.runCommands(logger, pluginID, testCase.Input, orchestrationDirectory, defaultWorkingDirectory, mockCancelFlag, mockIOHandler)
+	}
+
+	testExecution(t, runScriptTester)
+}
+
+// TestRunScriptsInvalidWorkingDirectory tests that commands don't run in a working directory that doesn't exist.
+func TestRunScriptsInvalidWorkingDirectory(t *testing.T) {
+	testCase := generateTestCaseOk("0")
+	testCase.Input.WorkingDirectory = filepath.Join(os.TempDir(), "missing-working-directory")
+	runScriptTester := func(p *Plugin, mockCancelFlag *task.MockCancelFlag, mockExecuter *executers.MockCommandExecuter, mockIOHandler *iohandlermocks.MockIOHandler) {
+		mockIOHandler.On("MarkAsFailed", mock.Anything).Return()
+
+		p.runCommands(logger, pluginID, testCase.Input, orchestrationDirectory, defaultWorkingDirectory, mockCancelFlag, mockIOHandler)
+	}
+
+	testExecution(t, runScriptTester)
+}
+
+func TestResolveShell(t *testing.T) {
+	defer func() { lookPath = exec.LookPath }()
+	lookPath = func(file string) (string, error) {
+		if file == "zsh" {
+			return "", errors.New("executable file not found in $PATH")
+		}
+		return "/bin/" + file, nil
+	}
+	dir, err := ioutil.TempDir("", "shell")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	customShell := filepath.Join(dir, "fish")
+	assert.NoError(t, ioutil.WriteFile(customShell, []byte{}, 0755))
+	notExecutable := filepath.Join(dir, "notes")
+	assert.NoError(t, ioutil.WriteFile(notExecutable, []byte{}, 0644))
+
+	p := &Plugin{Name: "aws:runShellScript"}
+	for shell, expected := range map[string]string{"": "", "bash": "/bin/bash", "sh": "/bin/sh", customShell: customShell} {
+		path, err := p.resolveShell(shell)
+		assert.NoError(t, err, shell)
+		assert.Equal(t, expected, path)
+	}
+	for _, shell := range []string{"zsh", "fish", "./fish", dir, notExecutable, filepath.Join(dir, "missing")} {
+		_, err := p.resolveShell(shell)
+		assert.Error(t, err, shell)
+	}
+
+	p.Name = "aws:runPowerShellScript"
+	_, err = p.resolveShell("bash")
+	assert.Error(t, err)
+}
+
+func TestResolveInterpreter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "interpreter")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	interpreter := filepath.Join(dir, "python3")
+	assert.NoError(t, ioutil.WriteFile(interpreter, []byte{}, 0755))
+	notExecutable := filepath.Join(dir, "notes")
+	assert.NoError(t, ioutil.WriteFile(notExecutable, []byte{}, 0644))
+
+	p := &Plugin{Name: "aws:runShellScript"}
+	for runCommand, expected := range map[string]string{
+		"echo hello":                         "",
+		"  #!/bin/sh":                        "",
+		"#!" + interpreter + "\nprint('hi')": interpreter,
+		"#! " + interpreter + " -u":          interpreter,
+	} {
+		path, err := p.resolveInterpreter("", []string{runCommand})
+		assert.NoError(t, err, runCommand)
+		assert.Equal(t, expected, path, runCommand)
+	}
+	for _, runCommand := range []string{"#!", "#!python3", "#!" + filepath.Join(dir, "missing"), "#!" + dir, "#!" + notExecutable} {
+		_, err := p.resolveInterpreter("", []string{runCommand})
+		assert.Error(t, err, runCommand)
+	}
+
+	// the shell or plugin decides the interpreter otherwise
+	path, err := p.resolveInterpreter("/bin/bash", []string{"#!" + interpreter})
+	assert.NoError(t, err)
+	assert.Empty(t, path)
+	p.Name = "aws:runPowerShellScript"
+	path, err = p.resolveInterpreter("", []string{"#!" + interpreter})
+	assert.NoError(t, err)
+	assert.Empty(t, path)
+}
+
+func TestResolveWorkingDirectory(t *testing.T) {
+	defer func() { changeOwner = executers.ChangeOwner }()
+	var owned []string
+	changeOwner = func(path string, runAs executers.RunAs) error {
+		owned = append(owned, path)
+		return nil
+	}
+	dir, err := ioutil.TempDir("", "workingdir")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	orchestrationDir := filepath.Join(dir, "document", pluginID)
+	file := filepath.Join(dir, "file")
+	assert.NoError(t, ioutil.WriteFile(file, []byte{}, 0644))
+
+	// existing directories are used as is
+	workingDir, err := resolveWorkingDirectory(logger, RunScriptPluginInput{WorkingDirectory: dir}, pluginID, orchestrationDir, "default", executers.RunAs{})
+	assert.NoError(t, err)
+	assert.Equal(t, dir, workingDir)
+
+	// missing relative directories fall back to the default working directory
+	workingDir, err = resolveWorkingDirectory(logger, RunScriptPluginInput{WorkingDirectory: "src"}, pluginID, orchestrationDir, "default", executers.RunAs{})
+	assert.NoError(t, err)
+	assert.Equal(t, "default", workingDir)
+
+	// missing absolute directories and files are rejected
+	_, err = resolveWorkingDirectory(logger, RunScriptPluginInput{WorkingDirectory: filepath.Join(dir, "app")}, pluginID, orchestrationDir, "default", executers.RunAs{})
+	assert.Error(t, err)
+	_, err = resolveWorkingDirectory(logger, RunScriptPluginInput{WorkingDirectory: file, CreateWorkingDirectory: true}, pluginID, orchestrationDir, "default", executers.RunAs{})
+	assert.Error(t, err)
+
+	// missing directories are created on request and given to the user
+	input := RunScriptPluginInput{WorkingDirectory: filepath.Join(dir, "app", "current"), CreateWorkingDirectory: true}
+	workingDir, err = resolveWorkingDirectory(logger, input, pluginID, orchestrationDir, "default", executers.RunAs{User: "ssm-user"})
+	assert.NoError(t, err)
+	assert.Equal(t, input.WorkingDirectory, workingDir)
+	assert.True(t, fileutil.IsDirectory(workingDir))
+	assert.Equal(t, []string{workingDir}, owned)
+
+	input = RunScriptPluginInput{WorkingDirectory: "src", CreateWorkingDirectory: true}
+	workingDir, err = resolveWorkingDirectory(logger, input, pluginID, orchestrationDir, "default", executers.RunAs{})
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "document", downloadsDir, "src"), workingDir)
+	assert.True(t, fileutil.IsDirectory(workingDir))
+}
+
 // TestBucketsInDifferentRegions tests runScripts when S3Buckets are present in IAD and PDX region.
 func TestBucketsInDifferentRegions(t *testing.T) {
 	for _, testCase := range TestCases {
@@ -323,7 +584,7 @@ func testExecution(t *testing.T, commandtester CommandTester) {
 }
 
 func setExecuterExpectations(mockExecuter *executers.MockCommandExecuter, t TestCase, cancelFlag task.CancelFlag, p *Plugin) {
-	mockExecuter.On("NewExecute", mock.Anything, t.Input.WorkingDirectory, t.Output.StdoutWriter, t.Output.StderrWriter, cancelFlag, mock.Anything, mock.Anything, mock.Anything).Return(
+	mockExecuter.On("NewExecute", mock.Anything, t.Input.WorkingDirectory, t.Output.StdoutWriter, t.Output.StderrWriter, cancelFlag, mock.Anything, mock.Anything, mock.Anything, t.Input.Environment).Return(
 		t.Output.ExitCode, t.ExecuterError)
 }
 
@@ -0,0 +1,270 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package servicecontrol implements the aws:serviceControl plugin.
+package servicecontrol
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/executers"
+	"github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler"
+	"github.com/aws/amazon-ssm-agent/agent/jsonutil"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/plugins/pluginutil"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+)
+
+const (
+	actionStart   = "start"
+	actionStop    = "stop"
+	actionRestart = "restart"
+	actionEnable  = "enable"
+	actionDisable = "disable"
+
+	// Normalized service states reported by every service manager
+	stateRunning = "running"
+	stateStopped = "stopped"
+
+	defaultWaitTimeoutSeconds = 60
+	pollInterval              = time.Second
+)
+
+// serviceNamePattern matches systemd units, SysV scripts, launchd labels and Windows service names
+var serviceNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.@:\- ]*$`)
+
+// Assign method to global variables to allow unittest to override
+var lookPath = exec.LookPath
+var runCommand = defaultRunCommand
+var runHealthCheck = defaultRunHealthCheck
+var sleep = time.Sleep
+var now = time.Now
+
+// serviceManager controls services through one init system.
+type serviceManager interface {
+	// Name returns the name of the init system
+	Name() string
+	// Control performs the action on the service
+	Control(log log.T, service string, action string) error
+	// State returns the normalized state of the service
+	State(log log.T, service string) (string, error)
+	// HealthCheckCommand returns the command running the health check script
+	HealthCheckCommand(script string) (string, []string)
+}
+
+// Plugin is the type for the aws:serviceControl plugin.
+type Plugin struct{}
+
+// ServiceControlPluginInput represents one service action performed by the aws:serviceControl plugin.
+type ServiceControlPluginInput struct {
+	contracts.PluginInput
+	ID                 string
+	ServiceName        string
+	Action             string
+	WaitForState       bool
+	TimeoutSeconds     interface{}
+	HealthCheckCommand string
+}
+
+// NewPlugin returns a new instance of the plugin.
+func NewPlugin() (*Plugin, error) {
+	return &Plugin{}, nil
+}
+
+// Name returns the plugin name
+func Name() string {
+	return appconfig.PluginNameAwsServiceControl
+}
+
+// Execute performs the requested action on the service and reports its final state.
+func (p *Plugin) Execute(context context.T, config contracts.Configuration, cancelFlag task.CancelFlag, output iohandler.IOHandler) {
+	log := context.Log()
+	log.Infof("%v started with configuration %v", Name(), config)
+
+	if cancelFlag.ShutDown() {
+		output.MarkAsShutdown()
+	} else if cancelFlag.Canceled() {
+		output.MarkAsCancelled()
+	} else if input, err := parseAndValidateInput(config.Properties); err != nil {
+		output.MarkAsFailed(err)
+	} else if manager, err := detectServiceManager(); err != nil {
+		output.MarkAsFailed(err)
+	} else {
+		controlService(log, manager, input, cancelFlag, output)
+	}
+}
+
+// controlService performs the action, waits for the expected state and runs the health check.
+func controlService(log log.T, manager serviceManager, input *ServiceControlPluginInput, cancelFlag task.CancelFlag, output iohandler.IOHandler) {
+	action := strings.ToLower(input.Action)
+	log.Infof("Performing %v of service %v through %v", action, input.ServiceName, manager.Name())
+	if err := manager.Control(log, input.ServiceName, action); err != nil {
+		output.MarkAsFailed(fmt.Errorf("failed to %v service %v: %v", action, input.ServiceName, err))
+		return
+	}
+
+	deadline := now().Add(time.Duration(pluginutil.ValidateExecutionTimeout(log, timeoutOrDefault(input.TimeoutSeconds))) * time.Second)
+
+	state, err := manager.State(log, input.ServiceName)
+	if expected := expectedState(action); input.WaitForState && expected != "" {
+		for err == nil && state != expected && now().Before(deadline) && !cancelFlag.Canceled() && !cancelFlag.ShutDown() {
+			sleep(pollInterval)
+			state, err = manager.State(log, input.ServiceName)
+		}
+		if err == nil && state != expected {
+			output.AppendInfof("Service %v is %v", input.ServiceName, state)
+			markAsInterruptedOr(cancelFlag, output, fmt.Errorf("service %v did not reach state %v", input.ServiceName, expected))
+			return
+		}
+	}
+	if err != nil {
+		output.MarkAsFailed(fmt.Errorf("failed to query the state of service %v: %v", input.ServiceName, err))
+		return
+	}
+
+	if input.HealthCheckCommand != "" {
+		if err = healthCheck(log, manager, input.HealthCheckCommand, deadline, cancelFlag, output); err != nil {
+			output.AppendInfof("Service %v is %v", input.ServiceName, state)
+			markAsInterruptedOr(cancelFlag, output, err)
+			return
+		}
+	}
+
+	output.AppendInfof("Service %v is %v", input.ServiceName, state)
+	output.MarkAsSucceeded()
+}
+
+// healthCheck runs the health check command until it succeeds or the deadline passes.
+// Each run is killed once the deadline passes or the command is cancelled.
+func healthCheck(log log.T, manager serviceManager, script string, deadline time.Time, cancelFlag task.CancelFlag, output iohandler.IOHandler) error {
+	name, args := manager.HealthCheckCommand(script)
+	for attempt := 1; ; attempt++ {
+		commandOutput, err := runHealthCheck(log, cancelFlag, remainingSeconds(deadline), name, args...)
+		if err == nil {
+			output.AppendInfof("Health check succeeded after %v attempts", attempt)
+			return nil
+		}
+		log.Debugf("Health check attempt %v failed: %v %v", attempt, err, commandOutput)
+		if !now().Before(deadline) || cancelFlag.Canceled() || cancelFlag.ShutDown() {
+			if commandOutput != "" {
+				output.AppendError(commandOutput)
+			}
+			return fmt.Errorf("health check failed after %v attempts: %v", attempt, err)
+		}
+		sleep(pollInterval)
+	}
+}
+
+// markAsInterruptedOr marks the plugin cancelled or shut down when the command was interrupted, failed otherwise.
+func markAsInterruptedOr(cancelFlag task.CancelFlag, output iohandler.IOHandler, err error) {
+	if cancelFlag.ShutDown() {
+		output.MarkAsShutdown()
+	} else if cancelFlag.Canceled() {
+		output.MarkAsCancelled()
+	} else {
+		output.MarkAsFailed(err)
+	}
+}
+
+// expectedState returns the state the service reaches after the action, enabling and disabling don't change it.
+func expectedState(action string) string {
+	switch action {
+	case actionStart, actionRestart:
+		return stateRunning
+	case actionStop:
+		return stateStopped
+	}
+	return ""
+}
+
+// timeoutOrDefault returns the configured timeout, services get a minute to change state by default.
+func timeoutOrDefault(timeoutSeconds interface{}) interface{} {
+	if timeoutSeconds == nil || timeoutSeconds == "" {
+		return defaultWaitTimeoutSeconds
+	}
+	return timeoutSeconds
+}
+
+// defaultRunCommand runs the command and returns its combined output.
+func defaultRunCommand(name string, args ...string) (string, error) {
+	out, err := exec.Command(name, args...).CombinedOutput()
+	return strings.TrimSpace(string(out)), err
+}
+
+// defaultRunHealthCheck runs the health check command, killing it after timeoutSeconds or when the command is cancelled,
+// and returns its output.
+func defaultRunHealthCheck(log log.T, cancelFlag task.CancelFlag, timeoutSeconds int, name string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	_, err := executers.ExecuteCommand(log, cancelFlag, "", &stdout, &stderr, timeoutSeconds, name, args, nil)
+	return strings.TrimSpace(strings.TrimSpace(stdout.String()) + "\n" + strings.TrimSpace(stderr.String())), err
+}
+
+// remainingSeconds returns the seconds left until the deadline, rounded up, a run gets at least a second.
+func remainingSeconds(deadline time.Time) int {
+	remaining := int((deadline.Sub(now()) + time.Second - 1) / time.Second)
+	if remaining < 1 {
+		return 1
+	}
+	return remaining
+}
+
+// runManagerCommand runs a service manager command, its output is included in the error.
+func runManagerCommand(log log.T, name string, args ...string) (string, error) {
+	log.Debugf("Running %v %v", name, args)
+	out, err := runCommand(name, args...)
+	if err != nil {
+		if out != "" {
+			return out, fmt.Errorf("%v - %v", err, out)
+		}
+		return out, err
+	}
+	return out, nil
+}
+
+// parseAndValidateInput parses the plugin properties and validates them
+func parseAndValidateInput(rawPluginInput interface{}) (*ServiceControlPluginInput, error) {
+	var input ServiceControlPluginInput
+	if err := jsonutil.Remarshal(rawPluginInput, &input); err != nil {
+		return nil, fmt.Errorf("invalid format in plugin properties %v; \nerror %v", rawPluginInput, err)
+	}
+
+	if err := validateInput(&input); err != nil {
+		return nil, fmt.Errorf("invalid input: %v", err)
+	}
+	return &input, nil
+}
+
+// validateInput ensures the plugin input matches the defined schema
+func validateInput(input *ServiceControlPluginInput) error {
+	if input.ServiceName == "" {
+		return errors.New("ServiceName must be specified")
+	}
+	if !serviceNamePattern.MatchString(input.ServiceName) {
+		return fmt.Errorf("invalid ServiceName %q", input.ServiceName)
+	}
+	switch strings.ToLower(input.Action) {
+	case actionStart, actionStop, actionRestart, actionEnable, actionDisable:
+	default:
+		return fmt.Errorf("unsupported Action %q, supported actions are start, stop, restart, enable and disable", input.Action)
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package servicecontrol
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeManager reports the states in order, the last one is repeated.
+type fakeManager struct {
+	controlErr error
+	states     []string
+	actions    []string
+}
+
+func (m *fakeManager) Name() string {
+	return "fake"
+}
+
+func (m *fakeManager) Control(log log.T, service string, action string) error {
+	m.actions = append(m.actions, action)
+	return m.controlErr
+}
+
+func (m *fakeManager) State(log log.T, service string) (string, error) {
+	state := m.states[0]
+	if len(m.states) > 1 {
+		m.states = m.states[1:]
+	}
+	return state, nil
+}
+
+func (m *fakeManager) HealthCheckCommand(script string) (string, []string) {
+	return "check", []string{script}
+}
+
+// newOutput returns an output without writers, so that stdout and stderr are kept in memory
+func newOutput() *iohandler.DefaultIOHandler {
+	return &iohandler.DefaultIOHandler{}
+}
+
+// stubClock makes sleep advance a fake clock instead of waiting.
+func stubClock() func() {
+	clock := time.Now()
+	now = func() time.Time { return clock }
+	sleep = func(d time.Duration) { clock = clock.Add(d) }
+	return func() {
+		now = time.Now
+		sleep = time.Sleep
+	}
+}
+
+func TestValidateInput(t *testing.T) {
+	assert.NoError(t, validateInput(&ServiceControlPluginInput{ServiceName: "nginx", Action: "Restart"}))
+	assert.NoError(t, validateInput(&ServiceControlPluginInput{ServiceName: "getty@tty1.service", Action: "enable"}))
+	assert.NoError(t, validateInput(&ServiceControlPluginInput{ServiceName: "com.example.agent", Action: "stop"}))
+	assert.Error(t, validateInput(&ServiceControlPluginInput{Action: "start"}))
+	assert.Error(t, validateInput(&ServiceControlPluginInput{ServiceName: "nginx; reboot", Action: "start"}))
+	assert.Error(t, validateInput(&ServiceControlPluginInput{ServiceName: "-nginx", Action: "start"}))
+	assert.Error(t, validateInput(&ServiceControlPluginInput{ServiceName: "nginx", Action: "reload"}))
+}
+
+func TestControlServiceWaitsForState(t *testing.T) {
+	defer stubClock()()
+	manager := &fakeManager{states: []string{"activating", "activating", stateRunning}}
+	output := newOutput()
+	controlService(log.NewMockLog(), manager, &ServiceControlPluginInput{ServiceName: "nginx", Action: "Start", WaitForState: true}, task.NewChanneledCancelFlag(), output)
+
+	assert.Equal(t, contracts.ResultStatusSuccess, output.GetStatus())
+	assert.Equal(t, []string{actionStart}, manager.actions)
+	assert.Contains(t, output.GetStdout(), "Service nginx is running")
+}
+
+func TestControlServiceStateTimeout(t *testing.T) {
+	defer stubClock()()
+	manager := &fakeManager{states: []string{stateRunning}}
+	output := newOutput()
+	controlService(log.NewMockLog(), manager, &ServiceControlPluginInput{ServiceName: "nginx", Action: "stop", WaitForState: true, TimeoutSeconds: "30"}, task.NewChanneledCancelFlag(), output)
+
+	assert.Equal(t, contracts.ResultStatusFailed, output.GetStatus())
+	assert.Contains(t, output.GetStdout(), "Service nginx is running")
+}
+
+func TestControlServiceFails(t *testing.T) {
+	manager := &fakeManager{controlErr: errors.New("Unit nginx.service not found.")}
+	output := newOutput()
+	controlService(log.NewMockLog(), manager, &ServiceControlPluginInput{ServiceName: "nginx", Action: "restart"}, task.NewChanneledCancelFlag(), output)
+
+	assert.Equal(t, contracts.ResultStatusFailed, output.GetStatus())
+	assert.Contains(t, output.GetStderr(), "not found")
+}
+
+func TestControlServiceHealthCheck(t *testing.T) {
+	defer stubClock()()
+	defer func() { runHealthCheck = defaultRunHealthCheck }()
+	attempts := 0
+	runHealthCheck = func(log log.T, cancelFlag task.CancelFlag, timeoutSeconds int, name string, args ...string) (string, error) {
+		assert.Equal(t, "check", name)
+		assert.Equal(t, []string{"curl -f http://localhost/"}, args)
+		if attempts++; attempts < 3 {
+			return "connection refused", errors.New("exit status 7")
+		}
+		return "", nil
+	}
+
+	manager := &fakeManager{states: []string{stateRunning}}
+	input := &ServiceControlPluginInput{ServiceName: "nginx", Action: "restart", WaitForState: true, HealthCheckCommand: "curl -f http://localhost/"}
+	output := newOutput()
+	controlService(log.NewMockLog(), manager, input, task.NewChanneledCancelFlag(), output)
+
+	assert.Equal(t, contracts.ResultStatusSuccess, output.GetStatus())
+	assert.Equal(t, 3, attempts)
+	assert.Contains(t, output.GetStdout(), "Health check succeeded after 3 attempts")
+}
+
+func TestControlServiceHealthCheckBoundedByDeadline(t *testing.T) {
+	defer stubClock()()
+	defer func() { runHealthCheck = defaultRunHealthCheck }()
+	var timeouts []int
+	runHealthCheck = func(log log.T, cancelFlag task.CancelFlag, timeoutSeconds int, name string, args ...string) (string, error) {
+		timeouts = append(timeouts, timeoutSeconds)
+		sleep(3 * time.Second)
+		return "connection refused", errors.New("exit status 7")
+	}
+
+	manager := &fakeManager{states: []string{stateRunning}}
+	input := &ServiceControlPluginInput{ServiceName: "nginx", Action: "restart", TimeoutSeconds: "10", HealthCheckCommand: "curl -f http://localhost/"}
+	output := newOutput()
+	controlService(log.NewMockLog(), manager, input, task.NewChanneledCancelFlag(), output)
+
+	assert.Equal(t, contracts.ResultStatusFailed, output.GetStatus())
+	// each run only gets the time left until the deadline
+	assert.Equal(t, []int{10, 6, 2}, timeouts)
+	assert.Contains(t, output.GetStderr(), "health check failed after 3 attempts")
+}
+
+func TestControlServiceHealthCheckCancelled(t *testing.T) {
+	defer stubClock()()
+	defer func() { runHealthCheck = defaultRunHealthCheck }()
+	cancelFlag := task.NewChanneledCancelFlag()
+	attempts := 0
+	runHealthCheck = func(log log.T, runCancelFlag task.CancelFlag, timeoutSeconds int, name string, args ...string) (string, error) {
+		// the run is killed through the cancel flag of the plugin
+		assert.Equal(t, cancelFlag, runCancelFlag)
+		attempts++
+		cancelFlag.Set(task.Canceled)
+		return "", errors.New("Cancelled process")
+	}
+
+	manager := &fakeManager{states: []string{stateRunning}}
+	input := &ServiceControlPluginInput{ServiceName: "nginx", Action: "restart", HealthCheckCommand: "curl -f http://localhost/"}
+	output := newOutput()
+	controlService(log.NewMockLog(), manager, input, cancelFlag, output)
+
+	assert.Equal(t, contracts.ResultStatusCancelled, output.GetStatus())
+	assert.Equal(t, 1, attempts)
+}
@@ -0,0 +1,171 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// +build darwin freebsd linux netbsd openbsd
+
+package servicecontrol
+
+import (
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/aws/amazon-ssm-agent/agent/fileutil"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+)
+
+// systemdRuntimeDir only exists when systemd is the running init system
+var systemdRuntimeDir = "/run/systemd/system"
+
+// launchdStatePattern matches the state line of launchctl print
+var launchdStatePattern = regexp.MustCompile(`(?m)^\s*state = (.+)$`)
+
+// detectServiceManager returns the service manager of the running init system.
+func detectServiceManager() (serviceManager, error) {
+	if runtime.GOOS == "darwin" {
+		return launchdManager{}, nil
+	}
+	if fileutil.Exists(systemdRuntimeDir) {
+		return systemdManager{}, nil
+	}
+	return sysvManager{}, nil
+}
+
+// systemdManager controls systemd units with systemctl.
+type systemdManager struct{}
+
+func (systemdManager) Name() string {
+	return "systemd"
+}
+
+func (systemdManager) Control(log log.T, service string, action string) error {
+	_, err := runManagerCommand(log, "systemctl", action, service)
+	return err
+}
+
+func (systemdManager) State(log log.T, service string) (string, error) {
+	// is-active exits non-zero for units that aren't active, the state is still printed
+	out, err := runCommand("systemctl", "is-active", service)
+	switch out {
+	case "":
+		return "", err
+	case "active":
+		return stateRunning, nil
+	case "inactive", "failed":
+		return stateStopped, nil
+	}
+	return out, nil
+}
+
+func (systemdManager) HealthCheckCommand(script string) (string, []string) {
+	return shellCommand(script)
+}
+
+// sysvManager controls SysV init scripts with service and chkconfig or update-rc.d.
+type sysvManager struct{}
+
+func (sysvManager) Name() string {
+	return "SysV init"
+}
+
+func (sysvManager) Control(log log.T, service string, action string) error {
+	var err error
+	switch action {
+	case actionEnable, actionDisable:
+		if chkconfig, lookErr := lookPath("chkconfig"); lookErr == nil {
+			state := "on"
+			if action == actionDisable {
+				state = "off"
+			}
+			_, err = runManagerCommand(log, chkconfig, service, state)
+		} else {
+			_, err = runManagerCommand(log, "update-rc.d", service, action)
+		}
+	default:
+		if serviceCommand, lookErr := lookPath("service"); lookErr == nil {
+			_, err = runManagerCommand(log, serviceCommand, service, action)
+		} else {
+			_, err = runManagerCommand(log, "/etc/init.d/"+service, action)
+		}
+	}
+	return err
+}
+
+func (sysvManager) State(log log.T, service string) (string, error) {
+	var err error
+	if serviceCommand, lookErr := lookPath("service"); lookErr == nil {
+		_, err = runCommand(serviceCommand, service, "status")
+	} else {
+		_, err = runCommand("/etc/init.d/"+service, "status")
+	}
+	// init scripts report the state through the exit code of status, any failure means the service isn't running
+	if err == nil {
+		return stateRunning, nil
+	}
+	if _, ok := err.(*exec.ExitError); ok {
+		return stateStopped, nil
+	}
+	return "", err
+}
+
+func (sysvManager) HealthCheckCommand(script string) (string, []string) {
+	return shellCommand(script)
+}
+
+// launchdManager controls launchd jobs of the system domain with launchctl, services are identified by their label.
+type launchdManager struct{}
+
+func (launchdManager) Name() string {
+	return "launchd"
+}
+
+func (launchdManager) Control(log log.T, service string, action string) error {
+	target := "system/" + service
+	var err error
+	switch action {
+	case actionStart:
+		_, err = runManagerCommand(log, "launchctl", "kickstart", target)
+	case actionRestart:
+		_, err = runManagerCommand(log, "launchctl", "kickstart", "-k", target)
+	case actionStop:
+		_, err = runManagerCommand(log, "launchctl", "kill", "SIGTERM", target)
+	default:
+		_, err = runManagerCommand(log, "launchctl", action, target)
+	}
+	return err
+}
+
+func (launchdManager) State(log log.T, service string) (string, error) {
+	out, err := runManagerCommand(log, "launchctl", "print", "system/"+service)
+	if err != nil {
+		return "", err
+	}
+	match := launchdStatePattern.FindStringSubmatch(out)
+	if match == nil {
+		return stateStopped, nil
+	}
+	if state := strings.TrimSpace(match[1]); state != stateRunning {
+		return stateStopped, nil
+	}
+	return stateRunning, nil
+}
+
+func (launchdManager) HealthCheckCommand(script string) (string, []string) {
+	return shellCommand(script)
+}
+
+// shellCommand runs the health check script with sh.
+func shellCommand(script string) (string, []string) {
+	return "sh", []string{"-c", script}
+}
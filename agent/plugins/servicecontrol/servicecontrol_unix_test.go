@@ -0,0 +1,76 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// +build darwin freebsd linux netbsd openbsd
+
+package servicecontrol
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSystemdState(t *testing.T) {
+	defer func() { runCommand = defaultRunCommand }()
+	for out, expected := range map[string]string{"active": stateRunning, "inactive": stateStopped, "failed": stateStopped, "activating": "activating"} {
+		runCommand = func(name string, args ...string) (string, error) {
+			assert.Equal(t, []string{"is-active", "nginx"}, args)
+			return out, nil
+		}
+		state, err := systemdManager{}.State(log.NewMockLog(), "nginx")
+		assert.NoError(t, err)
+		assert.Equal(t, expected, state)
+	}
+}
+
+func TestSysvState(t *testing.T) {
+	defer func() { runCommand = defaultRunCommand }()
+	runCommand = func(name string, args ...string) (string, error) {
+		return "", exec.Command("sh", "-c", "exit 3").Run()
+	}
+	state, err := sysvManager{}.State(log.NewMockLog(), "nginx")
+	assert.NoError(t, err)
+	assert.Equal(t, stateStopped, state)
+
+	runCommand = func(name string, args ...string) (string, error) {
+		return "", errors.New("permission denied")
+	}
+	_, err = sysvManager{}.State(log.NewMockLog(), "nginx")
+	assert.Error(t, err)
+}
+
+func TestLaunchdControl(t *testing.T) {
+	defer func() { runCommand = defaultRunCommand }()
+	var commands []string
+	runCommand = func(name string, args ...string) (string, error) {
+		commands = append(commands, name+" "+strings.Join(args, " "))
+		return "system/com.example.agent = {\n\tstate = running\n}", nil
+	}
+
+	manager := launchdManager{}
+	assert.NoError(t, manager.Control(log.NewMockLog(), "com.example.agent", actionRestart))
+	assert.NoError(t, manager.Control(log.NewMockLog(), "com.example.agent", actionDisable))
+	state, err := manager.State(log.NewMockLog(), "com.example.agent")
+	assert.NoError(t, err)
+	assert.Equal(t, stateRunning, state)
+	assert.Equal(t, []string{
+		"launchctl kickstart -k system/com.example.agent",
+		"launchctl disable system/com.example.agent",
+		"launchctl print system/com.example.agent",
+	}, commands)
+}
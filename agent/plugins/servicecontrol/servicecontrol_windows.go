@@ -0,0 +1,77 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// +build windows
+
+package servicecontrol
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+)
+
+// detectServiceManager returns the service control manager.
+func detectServiceManager() (serviceManager, error) {
+	return scmManager{}, nil
+}
+
+// scmManager controls services of the Windows service control manager with the powershell service cmdlets.
+type scmManager struct{}
+
+func (scmManager) Name() string {
+	return "Windows SCM"
+}
+
+func (scmManager) Control(log log.T, service string, action string) error {
+	var command string
+	switch action {
+	case actionStart:
+		command = "Start-Service -Name '%v'"
+	case actionStop:
+		command = "Stop-Service -Name '%v' -Force"
+	case actionRestart:
+		command = "Restart-Service -Name '%v' -Force"
+	case actionEnable:
+		command = "Set-Service -Name '%v' -StartupType Automatic"
+	case actionDisable:
+		command = "Set-Service -Name '%v' -StartupType Disabled"
+	}
+	_, err := runManagerCommand(log, appconfig.PowerShellPluginCommandName, powershellArgs(fmt.Sprintf(command, service))...)
+	return err
+}
+
+func (scmManager) State(log log.T, service string) (string, error) {
+	out, err := runManagerCommand(log, appconfig.PowerShellPluginCommandName, powershellArgs(fmt.Sprintf("(Get-Service -Name '%v').Status", service))...)
+	if err != nil {
+		return "", err
+	}
+	switch out {
+	case "Running":
+		return stateRunning, nil
+	case "Stopped":
+		return stateStopped, nil
+	}
+	return strings.ToLower(out), nil
+}
+
+func (scmManager) HealthCheckCommand(script string) (string, []string) {
+	return appconfig.PowerShellPluginCommandName, powershellArgs(script)
+}
+
+// powershellArgs returns the arguments running the command with powershell, cmdlet errors make it exit non-zero.
+func powershellArgs(command string) []string {
+	return []string{"-NoProfile", "-NonInteractive", "-Command", "$ErrorActionPreference = 'Stop'; " + command}
+}
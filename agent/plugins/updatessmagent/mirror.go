@@ -0,0 +1,129 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package updatessmagent implements the UpdateSsmAgent plugin.
+package updatessmagent
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/sdkutil"
+	"github.com/aws/amazon-ssm-agent/agent/updateutil"
+	"github.com/aws/aws-sdk-go/aws/session"
+	v4 "github.com/aws/aws-sdk-go/aws/signer/v4"
+)
+
+const (
+	// SourceAuthTypeNone downloads from the source without credentials, S3 URLs use the instance credentials
+	SourceAuthTypeNone = "None"
+
+	// SourceAuthTypeSigV4 presigns the URLs of an HTTPS mirror with the instance credentials
+	SourceAuthTypeSigV4 = "SigV4"
+
+	// DefaultSigningService is the service URLs are presigned for, when the mirror is an API Gateway endpoint
+	DefaultSigningService = "execute-api"
+
+	// SignatureExtension is appended to the manifest URL to locate its detached GPG signature by default
+	SignatureExtension = ".sig"
+
+	// presignExpiry is how long presigned URLs are valid, the updater downloads the packages after the plugin completes
+	presignExpiry = time.Hour
+)
+
+// Assign method to global variables to allow unittest to override
+var presignURL = presignWithInstanceCredentials
+var verifyManifestSignature = gpgVerify
+
+// validateSource normalizes the authentication of the source, SigV4 is only supported for HTTPS mirrors
+func validateSource(pluginInput *UpdatePluginInput) error {
+	switch {
+	case pluginInput.SourceAuthType == "" || strings.EqualFold(pluginInput.SourceAuthType, SourceAuthTypeNone):
+		pluginInput.SourceAuthType = SourceAuthTypeNone
+	case strings.EqualFold(pluginInput.SourceAuthType, SourceAuthTypeSigV4):
+		pluginInput.SourceAuthType = SourceAuthTypeSigV4
+		if !strings.HasPrefix(strings.ToLower(pluginInput.Source), "https://") {
+			return fmt.Errorf("source authentication type %v requires an https:// source, %v", SourceAuthTypeSigV4, pluginInput.Source)
+		}
+		if pluginInput.SourceSigningService == "" {
+			pluginInput.SourceSigningService = DefaultSigningService
+		}
+	default:
+		return fmt.Errorf("unsupported source authentication type %v, the supported types are %v and %v", pluginInput.SourceAuthType, SourceAuthTypeNone, SourceAuthTypeSigV4)
+	}
+	return nil
+}
+
+// urlSigner returns the function that presigns the URLs of the source, nil if the source isn't authenticated.
+// Only the URLs on the host of the source are presigned, so that packages the manifest links elsewhere are downloaded as is.
+func urlSigner(pluginInput *UpdatePluginInput, region string) func(string) (string, error) {
+	if pluginInput.SourceAuthType != SourceAuthTypeSigV4 {
+		return nil
+	}
+	source, _ := url.Parse(pluginInput.Source)
+	service := pluginInput.SourceSigningService
+	return func(fileURL string) (string, error) {
+		if parsed, err := url.Parse(fileURL); err != nil || source == nil || !strings.EqualFold(parsed.Host, source.Host) {
+			return fileURL, nil
+		}
+		return presignURL(fileURL, service, region)
+	}
+}
+
+// presignWithInstanceCredentials returns the URL with a SigV4 signature in its query string
+func presignWithInstanceCredentials(fileURL string, service string, region string) (string, error) {
+	request, err := http.NewRequest("GET", fileURL, nil)
+	if err != nil {
+		return "", err
+	}
+	signer := v4.NewSigner(session.New(sdkutil.AwsConfig()).Config.Credentials)
+	if _, err = signer.Presign(request, nil, service, region, presignExpiry, time.Now()); err != nil {
+		return "", fmt.Errorf("failed to presign %v, %v", fileURL, err)
+	}
+	return request.URL.String(), nil
+}
+
+// isDefaultManifestLocation returns true if the source is the manifest AWS publishes for the region
+func isDefaultManifestLocation(source string, region string) bool {
+	for _, manifestURL := range []string{CommonManifestURL, ChinaManifestURL} {
+		if strings.EqualFold(source, strings.Replace(manifestURL, updateutil.RegionHolder, region, -1)) {
+			return true
+		}
+	}
+	return false
+}
+
+// gpgVerify verifies the detached signature of the manifest with the public keys of the keyring
+func gpgVerify(log log.T, keyring string, signaturePath string, manifestPath string) error {
+	var cmd *exec.Cmd
+	if gpgv, err := exec.LookPath("gpgv"); err == nil {
+		cmd = exec.Command(gpgv, "--keyring", keyring, signaturePath, manifestPath)
+	} else if gpg, err := exec.LookPath("gpg"); err == nil {
+		cmd = exec.Command(gpg, "--batch", "--no-default-keyring", "--keyring", keyring, "--verify", signaturePath, manifestPath)
+	} else {
+		return errors.New("gpgv or gpg is required to verify the signature of the manifest")
+	}
+
+	output, err := cmd.CombinedOutput()
+	log.Debugf("Signature verification output: %v", string(output))
+	if err != nil {
+		return fmt.Errorf("failed to verify the signature of the manifest, %v", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package updatessmagent implements the UpdateSsmAgent plugin.
+package updatessmagent
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateSource(t *testing.T) {
+	input := UpdatePluginInput{Source: "https://s3.amazonaws.com/mirror/ssm-agent-manifest.json"}
+	assert.NoError(t, validateSource(&input))
+	assert.Equal(t, SourceAuthTypeNone, input.SourceAuthType)
+
+	input = UpdatePluginInput{Source: "https://mirror.example.com/ssm-agent-manifest.json", SourceAuthType: "sigv4"}
+	assert.NoError(t, validateSource(&input))
+	assert.Equal(t, SourceAuthTypeSigV4, input.SourceAuthType)
+	assert.Equal(t, DefaultSigningService, input.SourceSigningService)
+
+	input = UpdatePluginInput{Source: "http://mirror.example.com/ssm-agent-manifest.json", SourceAuthType: "SigV4"}
+	assert.Error(t, validateSource(&input))
+
+	input = UpdatePluginInput{Source: "https://mirror.example.com/ssm-agent-manifest.json", SourceAuthType: "Basic"}
+	assert.Error(t, validateSource(&input))
+}
+
+func TestURLSigner(t *testing.T) {
+	presignURL = func(fileURL string, service string, region string) (string, error) {
+		return fileURL + "?X-Amz-Credential=" + region + "/" + service, nil
+	}
+	defer func() { presignURL = presignWithInstanceCredentials }()
+
+	input := UpdatePluginInput{Source: "https://mirror.example.com/ssm-agent-manifest.json"}
+	assert.Nil(t, urlSigner(&input, "us-east-1"))
+
+	input = UpdatePluginInput{Source: "https://mirror.example.com/ssm-agent-manifest.json", SourceAuthType: SourceAuthTypeSigV4, SourceSigningService: "execute-api"}
+	signURL := urlSigner(&input, "us-east-1")
+
+	signed, err := signURL("https://mirror.example.com/amazon-ssm-agent/2.0.0.0/amazon-ssm-agent-linux-amd64.tar.gz")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://mirror.example.com/amazon-ssm-agent/2.0.0.0/amazon-ssm-agent-linux-amd64.tar.gz?X-Amz-Credential=us-east-1/execute-api", signed)
+
+	// packages hosted elsewhere are downloaded as is
+	signed, err = signURL("https://s3.amazonaws.com/packages/amazon-ssm-agent-linux-amd64.tar.gz")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://s3.amazonaws.com/packages/amazon-ssm-agent-linux-amd64.tar.gz", signed)
+}
+
+func TestDownloadURLAndHash_SignsURL(t *testing.T) {
+	plugin := createStubPluginInput()
+	context := createStubInstanceContext()
+	manifest := createStubManifest(plugin, context, true, true)
+	manifest.signURL = func(fileURL string) (string, error) {
+		return fileURL + "?signed", nil
+	}
+
+	source, _, err := manifest.DownloadURLAndHash(context, plugin.AgentName, plugin.TargetVersion)
+
+	assert.NoError(t, err)
+	assert.True(t, strings.HasSuffix(source, "?signed"))
+}
+
+func TestIsDefaultManifestLocation(t *testing.T) {
+	assert.True(t, isDefaultManifestLocation("https://s3.us-east-1.amazonaws.com/amazon-ssm-us-east-1/ssm-agent-manifest.json", "us-east-1"))
+	assert.True(t, isDefaultManifestLocation("https://s3.cn-north-1.amazonaws.com.cn/amazon-ssm-cn-north-1/ssm-agent-manifest.json", "cn-north-1"))
+	assert.False(t, isDefaultManifestLocation("https://s3.us-east-1.amazonaws.com/amazon-ssm-us-west-2/ssm-agent-manifest.json", "us-east-1"))
+	assert.False(t, isDefaultManifestLocation("https://mirror.example.com/ssm-agent-manifest.json", "us-east-1"))
+}
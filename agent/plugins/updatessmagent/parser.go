@@ -29,6 +29,9 @@ type Manifest struct {
 	SchemaVersion string            `json:"SchemaVersion"`
 	URIFormat     string            `json:"UriFormat"`
 	Packages      []*PackageContent `json:"Packages"`
+
+	// signURL presigns the download URLs of packages hosted by an authenticated mirror
+	signURL func(string) (string, error)
 }
 
 // PackageContent section in the Manifest json.
@@ -145,6 +148,11 @@ func (m *Manifest) DownloadURLAndHash(
 							result = strings.Replace(result, updateutil.PackageNameHolder, packageName, -1)
 							result = strings.Replace(result, updateutil.PackageVersionHolder, version, -1)
 							result = strings.Replace(result, updateutil.FileNameHolder, f.Name, -1)
+							if m.signURL != nil {
+								if result, err = m.signURL(result); err != nil {
+									return "", "", err
+								}
+							}
 							if version == updateutil.PipelineTestVersion {
 								return result, "", nil
 							}
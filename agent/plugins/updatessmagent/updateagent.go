@@ -50,6 +50,12 @@ type UpdatePluginInput struct {
 	TargetVersion  string `json:"targetVersion"`
 	Source         string `json:"source"`
 	UpdaterName    string `json:"-"`
+
+	// SourceAuthType is None or SigV4, the URLs of an HTTPS mirror are presigned with the instance credentials for SigV4
+	SourceAuthType       string `json:"sourceAuthType"`
+	SourceSigningService string `json:"sourceSigningService"`
+	// ManifestSignature is the detached GPG signature of the manifest, the manifest URL with .sig appended by default
+	ManifestSignature string `json:"manifestSignature"`
 }
 
 // UpdatePluginConfig is used for initializing update agent plugin with default values
@@ -137,6 +143,10 @@ func runUpdateAgent(
 	}
 	//Calculate manifest location base on current instance's region
 	pluginInput.Source = strings.Replace(pluginInput.Source, updateutil.RegionHolder, context.Region, -1)
+	if err = validateSource(&pluginInput); err != nil {
+		output.MarkAsFailed(err)
+		return
+	}
 	//Calculate updater package name base on agent name
 	pluginInput.UpdaterName = pluginInput.AgentName + updateutil.UpdaterPackageNamePrefix
 	//Generate update output
@@ -288,19 +298,65 @@ func (m *updateManager) downloadManifest(log log.T,
 		return nil, err
 	}
 
+	// manifests from mirrors are only trusted once their signature is verified with the configured keyring
+	appConfig, _ := getAppConfig(false)
+	keyring := appConfig.Agent.UpdateManifestKeyring
+	isMirror := !isDefaultManifestLocation(pluginInput.Source, context.Region)
+	if isMirror && keyring == "" {
+		return nil, fmt.Errorf("the signature of the manifest %v can't be verified, UpdateManifestKeyring must be configured to update from a mirror", pluginInput.Source)
+	}
+
+	signURL := urlSigner(pluginInput, context.Region)
+	manifestPath, err := downloadSourceFile(log, signURL, pluginInput.Source, updateDownload)
+	if err != nil {
+		return nil, err
+	}
+	out.AppendInfof("Successfully downloaded %v\n", pluginInput.Source)
+
+	if isMirror {
+		signatureSource := pluginInput.ManifestSignature
+		if signatureSource == "" {
+			signatureSource = pluginInput.Source + SignatureExtension
+		}
+		signaturePath, err := downloadSourceFile(log, signURL, signatureSource, updateDownload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to download the signature of the manifest, %v", err)
+		}
+		if err = verifyManifestSignature(log, keyring, signaturePath, manifestPath); err != nil {
+			return nil, err
+		}
+		out.AppendInfof("Successfully verified the signature of %v\n", pluginInput.Source)
+	}
+
+	if manifest, err = ParseManifest(log, manifestPath, context, pluginInput.AgentName); err != nil {
+		return nil, err
+	}
+	manifest.signURL = signURL
+	return manifest, nil
+}
+
+// downloadSourceFile downloads a file of the source, presigning its URL if the source is authenticated
+func downloadSourceFile(log log.T, signURL func(string) (string, error), source string, destination string) (localPath string, err error) {
 	downloadInput := artifact.DownloadInput{
-		SourceURL:            pluginInput.Source,
-		DestinationDirectory: updateDownload,
+		SourceURL:            source,
+		DestinationDirectory: destination,
+	}
+	if signURL != nil {
+		if downloadInput.SourceURL, err = signURL(source); err != nil {
+			return "", err
+		}
 	}
 
 	downloadOutput, downloadErr := fileDownload(log, downloadInput)
 	if downloadErr != nil ||
 		downloadOutput.IsHashMatched == false ||
 		downloadOutput.LocalFilePath == "" {
-		return nil, downloadErr
+		if downloadErr == nil {
+			downloadErr = fmt.Errorf("failed to download %v", source)
+		}
+		return "", downloadErr
 	}
-	out.AppendInfof("Successfully downloaded %v\n", downloadInput.SourceURL)
-	return ParseManifest(log, downloadOutput.LocalFilePath, context, pluginInput.AgentName)
+	return downloadOutput.LocalFilePath, nil
 }
 
 //downloadUpdater downloads updater from the s3 bucket
@@ -449,7 +505,9 @@ func GetUpdatePluginConfig(context context.T) UpdatePluginConfig {
 	}
 
 	var manifestUrl string
-	if strings.HasPrefix(region, s3util.ChinaRegionPrefix) {
+	if mirror := context.AppConfig().Agent.UpdateManifestLocation; mirror != "" {
+		manifestUrl = mirror
+	} else if strings.HasPrefix(region, s3util.ChinaRegionPrefix) {
 		manifestUrl = ChinaManifestURL
 	} else {
 		manifestUrl = CommonManifestURL
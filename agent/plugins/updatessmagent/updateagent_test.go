@@ -16,9 +16,11 @@ package updatessmagent
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
 	"github.com/aws/amazon-ssm-agent/agent/context"
 	"github.com/aws/amazon-ssm-agent/agent/contracts"
 	"github.com/aws/amazon-ssm-agent/agent/fileutil/artifact"
@@ -53,6 +55,7 @@ func TestGenerateUpdateCmd(t *testing.T) {
 func TestDownloadManifest(t *testing.T) {
 	plugin := createStubPluginInput()
 	context := createStubInstanceContext()
+	plugin.Source = strings.Replace(CommonManifestURL, updateutil.RegionHolder, context.Region, -1)
 
 	manager := updateManager{}
 	util := fakeUtility{}
@@ -71,6 +74,114 @@ func TestDownloadManifest(t *testing.T) {
 	assert.NotNil(t, manifest)
 }
 
+func TestDownloadManifest_VerifiesSignatureOfMirror(t *testing.T) {
+	plugin := createStubPluginInput()
+	plugin.Source = "https://mirror.example.com/ssm-agent-manifest.json"
+	context := createStubInstanceContext()
+
+	manager := updateManager{}
+	util := fakeUtility{}
+	out := iohandler.DefaultIOHandler{}
+
+	downloaded := []string{}
+	fileDownload = func(log log.T, input artifact.DownloadInput) (output artifact.DownloadOutput, err error) {
+		downloaded = append(downloaded, input.SourceURL)
+		return artifact.DownloadOutput{IsHashMatched: true, LocalFilePath: "testdata/sampleManifest.json"}, nil
+	}
+	getAppConfig = func(bool) (appconfig.SsmagentConfig, error) {
+		config := appconfig.SsmagentConfig{}
+		config.Agent.UpdateManifestKeyring = "/etc/amazon/ssm/update-keyring.gpg"
+		return config, nil
+	}
+	defer func() { getAppConfig = appconfig.Config }()
+	verifiedKeyring := ""
+	verifyManifestSignature = func(log log.T, keyring string, signaturePath string, manifestPath string) error {
+		verifiedKeyring = keyring
+		return nil
+	}
+	defer func() { verifyManifestSignature = gpgVerify }()
+
+	manifest, err := manager.downloadManifest(logger, &util, plugin, context, &out)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, manifest)
+	assert.Equal(t, []string{plugin.Source, plugin.Source + ".sig"}, downloaded)
+	assert.Equal(t, "/etc/amazon/ssm/update-keyring.gpg", verifiedKeyring)
+
+	// a manifest whose signature doesn't verify isn't used
+	verifyManifestSignature = func(log log.T, keyring string, signaturePath string, manifestPath string) error {
+		return fmt.Errorf("BAD signature")
+	}
+	manifest, err = manager.downloadManifest(logger, &util, plugin, context, &out)
+
+	assert.Error(t, err)
+	assert.Nil(t, manifest)
+}
+
+func TestDownloadManifest_PresignsURLsOfAuthenticatedMirror(t *testing.T) {
+	plugin := createStubPluginInput()
+	plugin.Source = "https://mirror.example.com/ssm-agent-manifest.json"
+	plugin.SourceAuthType = "sigv4"
+	assert.NoError(t, validateSource(plugin))
+	context := createStubInstanceContext()
+
+	manager := updateManager{}
+	util := fakeUtility{}
+	out := iohandler.DefaultIOHandler{}
+
+	downloaded := []string{}
+	fileDownload = func(log log.T, input artifact.DownloadInput) (output artifact.DownloadOutput, err error) {
+		downloaded = append(downloaded, input.SourceURL)
+		return artifact.DownloadOutput{IsHashMatched: true, LocalFilePath: "testdata/sampleManifest.json"}, nil
+	}
+	presignURL = func(fileURL string, service string, region string) (string, error) {
+		return fileURL + "?X-Amz-Signature=" + service, nil
+	}
+	defer func() { presignURL = presignWithInstanceCredentials }()
+	getAppConfig = func(bool) (appconfig.SsmagentConfig, error) {
+		config := appconfig.SsmagentConfig{}
+		config.Agent.UpdateManifestKeyring = "/etc/amazon/ssm/update-keyring.gpg"
+		return config, nil
+	}
+	defer func() { getAppConfig = appconfig.Config }()
+	verifyManifestSignature = func(log log.T, keyring string, signaturePath string, manifestPath string) error {
+		return nil
+	}
+	defer func() { verifyManifestSignature = gpgVerify }()
+
+	manifest, err := manager.downloadManifest(logger, &util, plugin, context, &out)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{plugin.Source + "?X-Amz-Signature=execute-api", plugin.Source + ".sig?X-Amz-Signature=execute-api"}, downloaded)
+	assert.NotNil(t, manifest.signURL)
+}
+
+func TestDownloadManifest_FailsMirrorWithoutKeyring(t *testing.T) {
+	plugin := createStubPluginInput()
+	plugin.Source = "https://mirror.example.com/ssm-agent-manifest.json"
+	context := createStubInstanceContext()
+
+	manager := updateManager{}
+	util := fakeUtility{}
+	out := iohandler.DefaultIOHandler{}
+
+	downloaded := []string{}
+	fileDownload = func(log log.T, input artifact.DownloadInput) (output artifact.DownloadOutput, err error) {
+		downloaded = append(downloaded, input.SourceURL)
+		return artifact.DownloadOutput{IsHashMatched: true, LocalFilePath: "testdata/sampleManifest.json"}, nil
+	}
+	getAppConfig = func(bool) (appconfig.SsmagentConfig, error) {
+		return appconfig.SsmagentConfig{}, nil
+	}
+	defer func() { getAppConfig = appconfig.Config }()
+
+	manifest, err := manager.downloadManifest(logger, &util, plugin, context, &out)
+
+	assert.Error(t, err)
+	assert.Nil(t, manifest)
+	assert.Empty(t, downloaded)
+}
+
 func TestDownloadUpdater(t *testing.T) {
 	plugin := createStubPluginInput()
 	context := createStubInstanceContext()
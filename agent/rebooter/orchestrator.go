@@ -0,0 +1,172 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package rebooter provides utilities used to reboot a machine.
+package rebooter
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/fileutil"
+	"github.com/aws/amazon-ssm-agent/agent/jsonutil"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/times"
+)
+
+// ResumeTokenFileName represents the file recording the documents waiting for a reboot
+const ResumeTokenFileName = "RebootResumeToken.json"
+
+// ResumeToken records the documents which requested a reboot, their remaining plugins resume after boot
+type ResumeToken struct {
+	DocumentIDs []string
+	RequestedAt string
+	// BootID identifies the boot the reboot was requested from, the token is only consumed once the machine booted again
+	BootID string `json:",omitempty"`
+}
+
+// dataStorePath, requestReboot and bootID are assigned to variables to allow unit tests to override them
+var dataStorePath = appconfig.DefaultDataStorePath
+var requestReboot = RequestPendingReboot
+var bootID = getBootID
+
+var orchestratorLock sync.Mutex
+var windowTimer *time.Timer
+
+// OrchestrateReboot records that the document needs a reboot to resume, then reboots the machine
+// unless the agent is configured to wait for an external reboot. The reboot is delayed to the
+// start of the configured reboot window when requested outside of it.
+func OrchestrateReboot(log log.T, instanceID string, documentID string) {
+	orchestratorLock.Lock()
+	defer orchestratorLock.Unlock()
+
+	if err := addToResumeToken(instanceID, documentID); err != nil {
+		log.Errorf("Failed to persist the reboot resume token of document %v, %v", documentID, err)
+	}
+
+	config, err := appconfig.Config(false)
+	if err != nil {
+		log.Debugf("Failed to load agent config, rebooting right away, %v", err)
+	}
+	if err == nil && !config.Agent.AutoReboot {
+		log.Infof("Document %v requires a reboot, waiting for the machine to be rebooted", documentID)
+		return
+	}
+
+	var window *times.DailyWindow
+	if err == nil && config.Agent.RebootWindow != "" {
+		if window, err = times.ParseDailyWindow(config.Agent.RebootWindow); err != nil {
+			log.Errorf("Ignoring reboot window, %v", err)
+		}
+	}
+
+	now := time.Now()
+	if window == nil || window.Contains(now) {
+		requestReboot(log)
+		return
+	}
+
+	if windowTimer != nil {
+		log.Infof("Document %v requires a reboot, reboot already scheduled", documentID)
+		return
+	}
+	rebootDate := window.NextStart(now)
+	log.Infof("Document %v requires a reboot, rebooting at the start of the reboot window at %v", documentID, rebootDate)
+	windowTimer = time.AfterFunc(rebootDate.Sub(now), func() {
+		orchestratorLock.Lock()
+		windowTimer = nil
+		orchestratorLock.Unlock()
+		requestReboot(log)
+	})
+}
+
+// ResumeAfterReboot returns the documents which were waiting for a reboot and clears the resume token once the machine
+// rebooted, the documents themselves resume from their persisted state. An agent restart within the boot the reboot was
+// requested from keeps the token and returns no document.
+func ResumeAfterReboot(log log.T, instanceID string) []string {
+	orchestratorLock.Lock()
+	defer orchestratorLock.Unlock()
+
+	token := loadResumeToken(instanceID)
+	if len(token.DocumentIDs) == 0 {
+		return token.DocumentIDs
+	}
+	if token.BootID != "" {
+		if current, err := bootID(); err != nil {
+			log.Warnf("Failed to get the boot id, assuming the machine rebooted, %v", err)
+		} else if current == token.BootID {
+			log.Infof("Documents %v are still waiting for the reboot requested at %v", token.DocumentIDs, token.RequestedAt)
+			return nil
+		}
+	}
+
+	log.Infof("Resuming documents %v which requested a reboot at %v", token.DocumentIDs, token.RequestedAt)
+	if err := os.Remove(getResumeTokenFileName(instanceID)); err != nil && !os.IsNotExist(err) {
+		log.Errorf("Failed to clear the reboot resume token, %v", err)
+	}
+	return token.DocumentIDs
+}
+
+// addToResumeToken adds the document to the persisted resume token, caller must hold orchestratorLock
+func addToResumeToken(instanceID string, documentID string) error {
+	token := loadResumeToken(instanceID)
+	for _, id := range token.DocumentIDs {
+		if id == documentID {
+			return nil
+		}
+	}
+	token.DocumentIDs = append(token.DocumentIDs, documentID)
+	token.RequestedAt = times.ToIso8601UTC(time.Now())
+	// without a boot id the token is consumed on the next start, as if the machine rebooted
+	token.BootID, _ = bootID()
+
+	location := path.Dir(getResumeTokenFileName(instanceID))
+	if err := fileutil.MakeDirs(location); err != nil {
+		return fmt.Errorf("cannot make directory of %v because: %v", location, err)
+	}
+
+	content, err := jsonutil.Marshal(token)
+	if err != nil {
+		return err
+	}
+	_, err = fileutil.WriteIntoFileWithPermissions(
+		getResumeTokenFileName(instanceID),
+		content,
+		os.FileMode(int(appconfig.ReadWriteAccess)))
+	return err
+}
+
+// loadResumeToken reads the persisted resume token, caller must hold orchestratorLock
+func loadResumeToken(instanceID string) (token ResumeToken) {
+	fileName := getResumeTokenFileName(instanceID)
+	if !fileutil.Exists(fileName) {
+		return
+	}
+	if err := jsonutil.UnmarshalFile(fileName, &token); err != nil {
+		return ResumeToken{}
+	}
+	return
+}
+
+// getResumeTokenFileName returns the full file name of the resume token
+func getResumeTokenFileName(instanceID string) string {
+	return path.Join(dataStorePath,
+		instanceID,
+		appconfig.DefaultDocumentRootDirName,
+		appconfig.DefaultLocationOfState,
+		ResumeTokenFileName)
+}
@@ -0,0 +1,79 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package rebooter provides utilities used to reboot a machine.
+package rebooter
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrchestrateRebootPersistsResumeToken(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rebooter")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	defer func(original string) { dataStorePath = original }(dataStorePath)
+	dataStorePath = dir
+	defer func(original func(log.T) bool) { requestReboot = original }(requestReboot)
+	defer func(original func() (string, error)) { bootID = original }(bootID)
+	bootID = func() (string, error) { return "boot1", nil }
+	requests := 0
+	requestReboot = func(log.T) bool {
+		requests++
+		return true
+	}
+
+	OrchestrateReboot(log.NewMockLog(), "i-1234567890", "document1")
+	OrchestrateReboot(log.NewMockLog(), "i-1234567890", "document2")
+	OrchestrateReboot(log.NewMockLog(), "i-1234567890", "document1")
+
+	assert.Equal(t, 3, requests)
+	// the agent restarts before the reboot
+	assert.Empty(t, ResumeAfterReboot(log.NewMockLog(), "i-1234567890"))
+
+	bootID = func() (string, error) { return "boot2", nil }
+	assert.Equal(t, []string{"document1", "document2"}, ResumeAfterReboot(log.NewMockLog(), "i-1234567890"))
+	assert.Empty(t, ResumeAfterReboot(log.NewMockLog(), "i-1234567890"))
+}
+
+func TestResumeAfterRebootWithoutBootID(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rebooter")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	defer func(original string) { dataStorePath = original }(dataStorePath)
+	dataStorePath = dir
+	defer func(original func(log.T) bool) { requestReboot = original }(requestReboot)
+	requestReboot = func(log.T) bool { return true }
+	defer func(original func() (string, error)) { bootID = original }(bootID)
+	bootID = func() (string, error) { return "", errors.New("boot id unavailable") }
+
+	OrchestrateReboot(log.NewMockLog(), "i-1234567890", "document1")
+
+	// the token is consumed on the next start when the boot can't be told apart
+	assert.Equal(t, []string{"document1"}, ResumeAfterReboot(log.NewMockLog(), "i-1234567890"))
+}
+
+func TestGetBootID(t *testing.T) {
+	first, err := getBootID()
+	assert.NoError(t, err)
+	assert.NotEmpty(t, first)
+	second, err := getBootID()
+	assert.NoError(t, err)
+	assert.Equal(t, first, second)
+}
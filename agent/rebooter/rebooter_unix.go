@@ -18,7 +18,9 @@ package rebooter
 
 import (
 	"bytes"
+	"io/ioutil"
 	"os/exec"
+	"strings"
 	"syscall"
 
 	"github.com/aws/amazon-ssm-agent/agent/log"
@@ -26,6 +28,9 @@ import (
 
 const (
 	timeOutInMinutesBeforeReboot = "+1" // Indicates 1 minute
+
+	// bootIDFile is the random id Linux generates on each boot
+	bootIDFile = "/proc/sys/kernel/random/boot_id"
 )
 
 // reboot is performed by running the following command
@@ -46,3 +51,15 @@ func reboot(log log.T) (err error) {
 	}
 	return
 }
+
+// getBootID returns an id of the current boot, the boot id of Linux or the boot time on the other platforms
+func getBootID() (string, error) {
+	if content, err := ioutil.ReadFile(bootIDFile); err == nil {
+		return strings.TrimSpace(string(content)), nil
+	}
+	output, err := exec.Command("sysctl", "-n", "kern.boottime").Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
@@ -19,6 +19,8 @@ package rebooter
 import (
 	"bytes"
 	"os/exec"
+	"syscall"
+	"time"
 
 	"github.com/aws/amazon-ssm-agent/agent/log"
 )
@@ -27,6 +29,8 @@ const (
 	timeOutInSecondsBeforeReboot = "60"
 )
 
+var getTickCount64 = syscall.NewLazyDLL("kernel32.dll").NewProc("GetTickCount64")
+
 // reboot is performed by running the following command
 // shutdown -r -t 60
 // The above command will cause the machine to reboot after 60 seconds
@@ -44,3 +48,13 @@ func reboot(log log.T) (err error) {
 	}
 	return
 }
+
+// getBootID returns an id of the current boot, the boot time to the minute computed from the milliseconds since boot
+func getBootID() (string, error) {
+	if err := getTickCount64.Find(); err != nil {
+		return "", err
+	}
+	ticks, _, _ := getTickCount64.Call()
+	bootTime := time.Now().Add(-time.Duration(ticks) * time.Millisecond)
+	return bootTime.UTC().Truncate(time.Minute).Format(time.RFC3339), nil
+}
@@ -68,6 +68,9 @@ func GetMgsEndpoint(region string) (mgsEndpoint string) {
 			}
 			return fullUrl.Path
 		}
+		if preferredEndpoint := appconfig.GetPreferredEndPoint(region, MgsServiceName); preferredEndpoint != "" {
+			return preferredEndpoint
+		}
 	}
 
 	if mgsEndpoint, ok := awsMessageGatewayServiceEndpointMap[region]; ok {
@@ -0,0 +1,115 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package runcommand implements runcommand core processing module
+package runcommand
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/fileutil"
+	"github.com/aws/amazon-ssm-agent/agent/jsonutil"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/metrics"
+	"github.com/aws/amazon-ssm-agent/agent/times"
+	"github.com/aws/aws-sdk-go/service/ssmmds"
+)
+
+const (
+	// abandonedReplyTooOld is the reason of the replies which were retried for longer than the configured age
+	abandonedReplyTooOld = "TooOld"
+	// abandonedReplyTooManyAttempts is the reason of the replies which were retried the configured number of times
+	abandonedReplyTooManyAttempts = "TooManyAttempts"
+	// abandonedReplyRejected is the reason of the replies which the service rejected with a non retryable error
+	abandonedReplyRejected = "Rejected"
+	// maxAbandonedReplies is the number of abandoned replies kept, the oldest are deleted first
+	maxAbandonedReplies = 100
+)
+
+// abandonedReply is the local record of a reply which never reached the service
+type abandonedReply struct {
+	ReplyFile     string
+	Reason        string
+	Attempts      int
+	AbandonedDate string
+	// Reply is nil when the reply file couldn't be loaded
+	Reply *ssmmds.SendReplyInput
+}
+
+// recordAbandonedReply is assigned to a variable to allow unit tests to override it
+var recordAbandonedReply = saveAbandonedReply
+
+// abandonFailedReply gives up a failed reply, it keeps a record of the reply and deletes the reply file
+func (s *RunCommandService) abandonFailedReply(log log.T, reply string, reason string, attempts int) {
+	record := abandonedReply{
+		ReplyFile:     reply,
+		Reason:        reason,
+		Attempts:      attempts,
+		AbandonedDate: times.ToIso8601UTC(time.Now()),
+	}
+	if input, err := s.service.GetFailedReply(log, reply); err == nil {
+		record.Reply = input
+	}
+	if err := recordAbandonedReply(log, s.config.InstanceID, record); err != nil {
+		log.Warnf("Failed to record abandoned reply %v, %v", reply, err)
+	}
+	log.Warnf("Abandoning reply %v after %v attempts, reason %v", reply, attempts, reason)
+	if s.name == mdsName {
+		metrics.Increment(metrics.ChannelMds, metrics.EventAbandoned)
+	}
+	s.service.DeleteFailedReply(log, reply)
+	delete(s.replyAttempts, reply)
+}
+
+// saveAbandonedReply writes the record in the abandoned replies folder of the instance
+func saveAbandonedReply(log log.T, instanceID string, record abandonedReply) error {
+	content, err := jsonutil.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	location := path.Join(appconfig.DefaultDataStorePath, instanceID, appconfig.AbandonedRepliesRootDirName)
+	if err = fileutil.MakeDirs(location); err != nil {
+		return fmt.Errorf("cannot make directory of %v because: %v", location, err)
+	}
+	if _, err = fileutil.WriteIntoFileWithPermissions(
+		path.Join(location, record.ReplyFile),
+		content,
+		os.FileMode(int(appconfig.ReadWriteAccess))); err != nil {
+		return err
+	}
+
+	pruneAbandonedReplies(log, location)
+	return nil
+}
+
+// pruneAbandonedReplies deletes the oldest records beyond maxAbandonedReplies
+func pruneAbandonedReplies(log log.T, location string) {
+	files, err := ioutil.ReadDir(location)
+	if err != nil || len(files) <= maxAbandonedReplies {
+		return
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].ModTime().Before(files[j].ModTime())
+	})
+	for _, file := range files[:len(files)-maxAbandonedReplies] {
+		log.Debugf("Deleting abandoned reply %v", file.Name())
+		os.Remove(path.Join(location, file.Name()))
+	}
+}
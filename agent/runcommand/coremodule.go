@@ -16,6 +16,7 @@ package runcommand
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -25,10 +26,17 @@ import (
 	"github.com/aws/amazon-ssm-agent/agent/contracts"
 	"github.com/aws/amazon-ssm-agent/agent/fileutil"
 	"github.com/aws/amazon-ssm-agent/agent/framework/docmanager"
+	"github.com/aws/amazon-ssm-agent/agent/framework/outboundqueue"
+	"github.com/aws/amazon-ssm-agent/agent/framework/resultsink"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/metrics"
 	"github.com/aws/amazon-ssm-agent/agent/platform"
 	messageContracts "github.com/aws/amazon-ssm-agent/agent/runcommand/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/runcommand/deadletter"
 	mdsService "github.com/aws/amazon-ssm-agent/agent/runcommand/mds"
+	"github.com/aws/amazon-ssm-agent/agent/runcommand/mgsjob"
 	"github.com/aws/amazon-ssm-agent/agent/sdkutil"
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ssmmds"
 	"github.com/carlescere/scheduler"
 )
@@ -48,6 +56,9 @@ var once sync.Once
 
 var loadDocStateFromSendCommand = parseSendCommandMessage
 var loadDocStateFromCancelCommand = parseCancelCommandMessage
+var publishResult = resultsink.Publish
+var addDeadLetter = deadletter.Add
+var takeRequeuedMessages = deadletter.TakeRequeued
 
 // Name returns the module name
 func (s *RunCommandService) ModuleName() string {
@@ -66,12 +77,18 @@ func (s *RunCommandService) ModuleExecute(context context.T) (err error) {
 	}
 
 	go s.listenReply(resultChan)
+	go s.processIntake()
 
 	if err = s.processor.InitialProcessing(); err != nil {
 		log.Errorf("initial processing in EngineProcessor encountered error: %v", err)
 		return
 	}
 
+	if s.name == mdsName && context.AppConfig().Mds.CommandDeliveryOverMgs {
+		log.Info("Receiving documents over the MGS control channel, polling MDS as fallback")
+		mgsjob.RegisterHandler(s.deliverMessage)
+	}
+
 	log.Info("Starting message polling")
 	if s.messagePollJob, err = scheduler.Every(pollMessageFrequencyMinutes).Minutes().Run(s.messagePollLoop); err != nil {
 		context.Log().Errorf("unable to schedule message poll job. %v", err)
@@ -90,10 +107,18 @@ func (s *RunCommandService) ModuleExecute(context context.T) (err error) {
 }
 
 func (s *RunCommandService) ModuleRequestStop(stopType contracts.StopType) (err error) {
+	if s.name == mdsName {
+		mgsjob.RegisterHandler(nil)
+	}
 	//first stop sending failed replies to the service and the message poller
 	s.stop()
+	s.intake.stop()
 	//second stop the message processor
 	s.processor.Stop(stopType)
+	//send the step progress of the commands interrupted by the stop
+	if s.stepStatus != nil {
+		s.stepStatus.flush()
+	}
 
 	//TODO move this out once we have association moved to a different core module
 	if s.assocProcessor != nil {
@@ -109,8 +134,17 @@ func (s *RunCommandService) listenReply(resultChan chan contracts.DocumentResult
 		//cloudwatch and refresh association needs to trigger the in-memory component, adding filter here
 		s.handleSpecialPlugin(res.LastPlugin, res.PluginResults, res.MessageID)
 
+		if res.LastPlugin == "" && res.Status == contracts.ResultStatusSuccessAndReboot {
+			// the command resumes after the reboot and sends its single final reply once it completes
+			log.Infof("command: %v waiting for reboot", res.MessageID)
+			continue
+		}
+
 		if res.LastPlugin != "" {
 			log.Infof("received plugin: %v result from Processor", res.LastPlugin)
+			if record, ok := resultsink.NewRecord(s.config.InstanceID, res); ok {
+				publishResult(log, resultsink.ConfigFromAppConfig(s.context.AppConfig()), record)
+			}
 		} else {
 			log.Infof("command: %v complete", res.MessageID)
 			//Deleting Old Log Files after the execution is over and files have been moved to completed folder
@@ -162,15 +196,32 @@ func (s *RunCommandService) processMessage(msg *ssmmds.Message) {
 		return
 	}
 
+	if s.processedMessages.contains(log, *msg.MessageId) {
+		// the message was delivered again, for example because the agent restarted before the service recorded the acknowledgement
+		log.Infof("Message %v was already processed, acknowledging it without processing it again", *msg.MessageId)
+		if err = s.service.AcknowledgeMessage(log, *msg.MessageId); err != nil {
+			sdkutil.HandleAwsError(log, err, s.processorStopPolicy)
+		}
+		return
+	}
+	if !s.processedMessages.claim(log, *msg.MessageId) {
+		log.Debugf("Message %v is already being processed", *msg.MessageId)
+		return
+	}
+	defer s.processedMessages.release(*msg.MessageId)
+
 	if strings.HasPrefix(*msg.Topic, string(SendCommandTopicPrefix)) {
 		docState, err = loadDocStateFromSendCommand(context, msg, s.orchestrationRootDir)
 		if err != nil {
 			log.Error(err)
+			s.saveDeadLetter(log, msg, err)
 			s.sendDocLevelResponse(*msg.MessageId, contracts.ResultStatusFailed, err.Error())
 			return
 		}
 	} else if strings.HasPrefix(*msg.Topic, string(CancelCommandTopicPrefix)) {
-		docState, err = loadDocStateFromCancelCommand(context, msg, s.orchestrationRootDir)
+		if docState, err = loadDocStateFromCancelCommand(context, msg, s.orchestrationRootDir); err != nil {
+			s.saveDeadLetter(log, msg, err)
+		}
 	} else {
 		err = fmt.Errorf("unexpected topic name %v", *msg.Topic)
 	}
@@ -188,6 +239,7 @@ func (s *RunCommandService) processMessage(msg *ssmmds.Message) {
 	}
 
 	log.Debugf("Ack done. Received message - messageId - %v", *msg.MessageId)
+	s.processedMessages.add(log, *msg.MessageId)
 
 	log.Debugf("Processing to send a reply to update the document status to InProgress")
 
@@ -207,53 +259,183 @@ func (s *RunCommandService) processMessage(msg *ssmmds.Message) {
 
 }
 
-// sendFailedReplies loads replies from local disk and send it again to the service, if it fails no action is needed
+// saveDeadLetter keeps the message which failed to parse so that it can be inspected and requeued
+func (s *RunCommandService) saveDeadLetter(log log.T, msg *ssmmds.Message, parseErr error) {
+	if err := addDeadLetter(log, s.config.InstanceID, s.name, msg, parseErr); err != nil {
+		log.Warnf("Failed to save message %v which failed to parse, %v", *msg.MessageId, err)
+	}
+}
+
+// queueRequeuedMessages queues the messages which failed to parse and were requeued since
+func (s *RunCommandService) queueRequeuedMessages() {
+	for _, msg := range takeRequeuedMessages(s.context.Log(), s.config.InstanceID, s.name) {
+		if err := validate(msg); err != nil {
+			s.context.Log().Error("requeued message not valid, ignoring: ", err)
+			continue
+		}
+		queueMessage(s, msg)
+	}
+}
+
+// deliverMessage processes a message received over the MGS control channel or submitted locally like the polled messages,
+// the message is acknowledged to the service so that polling doesn't receive it again
+func (s *RunCommandService) deliverMessage(msg *ssmmds.Message) bool {
+	if err := validate(msg); err != nil {
+		s.context.Log().Error("delivered message not valid, ignoring: ", err)
+		return false
+	}
+	return queueMessage(s, msg)
+}
+
+// queueMessage queues the message for processing, it returns false if the service is stopped or too many messages are queued
+func (s *RunCommandService) queueMessage(msg *ssmmds.Message) bool {
+	if !s.intake.push(msg) {
+		// the message isn't acknowledged, the service delivers it again
+		s.context.Log().Warnf("Service is stopped or too many messages are queued, message %v is not processed", *msg.MessageId)
+		return false
+	}
+	return true
+}
+
+// processIntake processes the queued messages one at a time until the service stops
+func (s *RunCommandService) processIntake() {
+	for {
+		msg, ok := s.intake.pop()
+		if !ok {
+			return
+		}
+		processMessage(s, msg)
+	}
+}
+
+// sendFailedReplies loads replies from local disk and send it again to the service,
+// if it fails the replies are sent again once the backoff delay elapsed
 func (s *RunCommandService) sendFailedReplies() {
 	log := s.context.Log()
 
+	s.replyLock.Lock()
+	defer s.replyLock.Unlock()
+	if s.replyBackoff == nil {
+		s.replyBackoff = outboundqueue.NewBackoff(replyMinBackoff, replyMaxBackoff)
+	}
+	if !s.replyBackoff.Ready() {
+		log.Debugf("Skipping failed document replies until %v", s.replyBackoff.Next())
+		return
+	}
+
 	log.Debug("Checking if there are document replies that failed to reach the service, and retry sending them")
 	replies := s.service.LoadFailedReplies(log)
+	maxAge, maxAttempts := replyRetryBudget(s.context.AppConfig())
+	if s.replyAttempts == nil {
+		s.replyAttempts = make(map[string]int)
+	}
 
-	if len(replies) != 0 {
-		log.Infof("Found document replies that need to be sent to the service")
-		for _, reply := range replies {
-			log.Debug("Loading reply ", reply)
-			if isValidReplyRequest(reply) == false {
-				log.Debug("Reply is old, document execution must have timed out. Deleting the reply")
-				s.service.DeleteFailedReply(log, reply)
+	if len(replies) == 0 {
+		log.Debugf("No failed document replies found")
+		s.replyBackoff.Succeeded()
+		return
+	}
+
+	log.Infof("Found document replies that need to be sent to the service")
+	// the replies of a command are sent in the order they were saved, a reply that failed holds back
+	// the later replies of its command only, the replies of the other commands are still sent
+	sortRepliesBySaveTime(replies)
+	blocked := make(map[string]bool)
+	failed := false
+	for _, reply := range replies {
+		log.Debug("Loading reply ", reply)
+		if isValidReplyRequest(reply, maxAge) == false {
+			log.Debug("Reply is old, document execution must have timed out")
+			s.abandonFailedReply(log, reply, abandonedReplyTooOld, s.replyAttempts[reply])
+			continue
+		}
+		sendReplyRequest, err := s.service.GetFailedReply(log, reply)
+		if err != nil {
+			log.Error("Couldn't load the reply from disk ", err)
+			continue
+		}
+		commandID := aws.StringValue(sendReplyRequest.MessageId)
+		if blocked[commandID] {
+			log.Debugf("Holding reply %v until the previous reply of %v is sent", reply, commandID)
+			continue
+		}
+
+		log.Info("Sending reply ", reply)
+		if s.name == mdsName {
+			metrics.Increment(metrics.ChannelMds, metrics.EventRetried)
+		}
+		if err = s.service.SendReplyWithInput(log, sendReplyRequest); err != nil {
+			sdkutil.HandleAwsError(log, err, s.processorStopPolicy)
+			if !sdkutil.IsRetryableError(err) {
+				log.Errorf("The service rejected reply %v, %v", reply, err)
+				s.abandonFailedReply(log, reply, abandonedReplyRejected, s.replyAttempts[reply]+1)
 				continue
 			}
-			sendReplyRequest, err := s.service.GetFailedReply(log, reply)
-			if err != nil {
-				log.Error("Couldn't load the reply from disk ", err)
-				continue
+			s.replyAttempts[reply]++
+			if maxAttempts > 0 && s.replyAttempts[reply] >= maxAttempts {
+				s.abandonFailedReply(log, reply, abandonedReplyTooManyAttempts, s.replyAttempts[reply])
 			}
+			log.Infof("Sending reply %v failed, %v", reply, err)
+			blocked[commandID] = true
+			failed = true
+			continue
+		}
+		log.Infof("Sending reply %v succeeded, deleting the reply file from disk", reply)
+		s.service.DeleteFailedReply(log, reply)
+		delete(s.replyAttempts, reply)
+	}
 
-			log.Info("Sending reply ", reply)
-			if err = s.service.SendReplyWithInput(log, sendReplyRequest); err != nil {
-				sdkutil.HandleAwsError(log, err, s.processorStopPolicy)
-				break
-			} else {
-				log.Infof("Sending reply %v succeeded, deleting the reply file from disk", reply)
-				s.service.DeleteFailedReply(log, reply)
-			}
+	if failed {
+		log.Infof("Retrying the failed document replies in %v", s.replyBackoff.Failed())
+		return
+	}
+	s.replyBackoff.Succeeded()
+}
+
+// sortRepliesBySaveTime sorts the reply files by the time in their name, oldest first
+func sortRepliesBySaveTime(replies []string) {
+	saveTime := func(reply string) string {
+		if splitFileName := strings.Split(reply, "_"); len(splitFileName) > 1 {
+			// the time format sorts in chronological order
+			return splitFileName[1]
 		}
-	} else {
-		log.Debugf("No failed document replies found")
+		return ""
+	}
+	sort.SliceStable(replies, func(i, j int) bool {
+		return saveTime(replies[i]) < saveTime(replies[j])
+	})
+}
+
+// resumeFailedReplies sends the failed replies without waiting for the backoff, once the service is reachable again
+func (s *RunCommandService) resumeFailedReplies() {
+	s.replyLock.Lock()
+	if s.replyBackoff != nil {
+		s.replyBackoff.Succeeded()
+	}
+	s.replyLock.Unlock()
+
+	s.sendFailedReplies()
+}
+
+// replyRetryBudget returns how long and how many times the failed replies are retried, 0 attempts retries until the reply is too old
+func replyRetryBudget(config appconfig.SsmagentConfig) (maxAge time.Duration, maxAttempts int) {
+	maxAge = time.Duration(config.Mds.ReplyRetryMaxAgeMinutes) * time.Minute
+	if maxAge <= 0 {
+		maxAge = documentLevelTimeOutDurationHour * time.Hour
 	}
+	return maxAge, config.Mds.ReplyRetryMaxAttempts
 }
 
-// isValidReplyRequest checks if the sendReply request is older than 2 hours
+// isValidReplyRequest checks if the sendReply request is older than maxAge
 // If so it is considered as not valid anymore as the document must have timed out
-func isValidReplyRequest(filename string) bool {
+func isValidReplyRequest(filename string, maxAge time.Duration) bool {
 	splitFileName := strings.Split(filename, "_")
 	if len(splitFileName) < 2 {
 		return false
 	}
 	t, _ := time.Parse("2006-01-02T15-04-05", splitFileName[1])
 	curTime := time.Now().UTC()
-	delta := curTime.Sub(t).Hours()
-	if delta > documentLevelTimeOutDurationHour {
+	if curTime.Sub(t) > maxAge {
 		return false
 	} else {
 		return true
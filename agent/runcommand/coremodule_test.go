@@ -19,7 +19,12 @@ import (
 	"testing"
 	"time"
 
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/log"
 	runcommandmock "github.com/aws/amazon-ssm-agent/agent/runcommand/mock"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/ssmmds"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -97,17 +102,253 @@ func TestSendFailedRepliesWithSendReplyReturnError(t *testing.T) {
 	mdsMock.AssertNumberOfCalls(t, "DeleteFailedReply", 0)
 }
 
+// TestSendFailedRepliesBacksOff tests the sendFailedReplies function waits for the backoff after an error, until the service is reachable again
+func TestSendFailedRepliesBacksOff(t *testing.T) {
+	contextMock := MockContext()
+
+	// create mocked service and set expectations
+	mdsMock := new(runcommandmock.MockedMDS)
+	replies := GetTestFailedReplies()
+	mdsMock.On("LoadFailedReplies", mock.AnythingOfType("*log.Mock")).Return(replies)
+	mdsMock.On("SendReplyWithInput", mock.AnythingOfType("*log.Mock"), &ssmmds.SendReplyInput{}).Return(fmt.Errorf("some error")).Once()
+	mdsMock.On("SendReplyWithInput", mock.AnythingOfType("*log.Mock"), &ssmmds.SendReplyInput{}).Return(nil)
+	mdsMock.On("GetFailedReply", mock.AnythingOfType("*log.Mock"), mock.AnythingOfType("string")).Return(&ssmmds.SendReplyInput{}, nil)
+	mdsMock.On("DeleteFailedReply", mock.AnythingOfType("*log.Mock"), mock.AnythingOfType("string")).Return()
+
+	proc := RunCommandService{
+		name:    mdsName,
+		context: contextMock,
+		service: mdsMock,
+	}
+
+	proc.sendFailedReplies()
+	proc.sendFailedReplies()
+	mdsMock.AssertNumberOfCalls(t, "SendReplyWithInput", 1)
+	assert.False(t, proc.replyBackoff.Ready())
+
+	proc.resumeFailedReplies()
+	mdsMock.AssertNumberOfCalls(t, "SendReplyWithInput", 4)
+	mdsMock.AssertNumberOfCalls(t, "DeleteFailedReply", 3)
+	assert.True(t, proc.replyBackoff.Ready())
+}
+
+// TestDeliverMessage tests the deliverMessage function processes the valid messages received over the control channel
+func TestDeliverMessage(t *testing.T) {
+	processed := make(chan *ssmmds.Message, 1)
+	queueMessage = func(svc *RunCommandService, msg *ssmmds.Message) bool {
+		processed <- msg
+		return true
+	}
+	defer func() { queueMessage = (*RunCommandService).queueMessage }()
+
+	proc := RunCommandService{
+		name:    mdsName,
+		context: MockContext(),
+	}
+
+	assert.False(t, proc.deliverMessage(&ssmmds.Message{MessageId: aws.String("aws.ssm.commandId.instanceId")}))
+
+	message := &ssmmds.Message{
+		MessageId:   aws.String("aws.ssm.commandId.instanceId"),
+		Topic:       aws.String("aws.ssm.sendCommand.test"),
+		Destination: aws.String("instanceId"),
+		CreatedDate: aws.String("2017-08-22T20:37:54.948Z"),
+	}
+	assert.True(t, proc.deliverMessage(message))
+	select {
+	case msg := <-processed:
+		assert.Equal(t, message, msg)
+	case <-time.After(time.Second):
+		assert.Fail(t, "message was not processed")
+	}
+}
+
 func TestValidFailedReply(t *testing.T) {
 	curT := time.Now().UTC()
 	replyFileName := fmt.Sprintf("reply_%v", curT.Format("2006-01-02T15-04-05"))
-	valid := isValidReplyRequest(replyFileName)
+	valid := isValidReplyRequest(replyFileName, 2*time.Hour)
 	assert.Equal(t, valid, true)
 
+	replyFileName = fmt.Sprintf("reply_%v", curT.Add(-time.Hour).Format("2006-01-02T15-04-05"))
+	valid = isValidReplyRequest(replyFileName, 30*time.Minute)
+	assert.Equal(t, valid, false)
+
 	replyFileName = "reply_2006-01-02T15-04-05"
-	valid = isValidReplyRequest(replyFileName)
+	valid = isValidReplyRequest(replyFileName, 2*time.Hour)
 	assert.Equal(t, valid, false)
 
 	replyFileName = "reply"
-	valid = isValidReplyRequest(replyFileName)
+	valid = isValidReplyRequest(replyFileName, 2*time.Hour)
 	assert.Equal(t, valid, false)
 }
+
+func TestReplyRetryBudget(t *testing.T) {
+	config := appconfig.SsmagentConfig{}
+	maxAge, maxAttempts := replyRetryBudget(config)
+	assert.Equal(t, 2*time.Hour, maxAge)
+	assert.Equal(t, 0, maxAttempts)
+
+	config.Mds.ReplyRetryMaxAgeMinutes = 30
+	config.Mds.ReplyRetryMaxAttempts = 5
+	maxAge, maxAttempts = replyRetryBudget(config)
+	assert.Equal(t, 30*time.Minute, maxAge)
+	assert.Equal(t, 5, maxAttempts)
+}
+
+// TestSendFailedRepliesAbandonsOldReplies tests the sendFailedReplies function records and deletes the replies older than the max age
+func TestSendFailedRepliesAbandonsOldReplies(t *testing.T) {
+	contextMock := MockContext()
+
+	var records []abandonedReply
+	recordAbandonedReply = func(log log.T, instanceID string, record abandonedReply) error {
+		records = append(records, record)
+		return nil
+	}
+	defer func() { recordAbandonedReply = saveAbandonedReply }()
+
+	// create mocked service and set expectations
+	mdsMock := new(runcommandmock.MockedMDS)
+	oldReply := "1reply_2006-01-02T15-04-05"
+	mdsMock.On("LoadFailedReplies", mock.AnythingOfType("*log.Mock")).Return([]string{oldReply})
+	mdsMock.On("GetFailedReply", mock.AnythingOfType("*log.Mock"), oldReply).Return(&ssmmds.SendReplyInput{}, nil)
+	mdsMock.On("DeleteFailedReply", mock.AnythingOfType("*log.Mock"), oldReply).Return()
+
+	proc := RunCommandService{
+		name:    mdsName,
+		context: contextMock,
+		service: mdsMock,
+	}
+
+	proc.sendFailedReplies()
+
+	mdsMock.AssertNumberOfCalls(t, "SendReplyWithInput", 0)
+	mdsMock.AssertNumberOfCalls(t, "DeleteFailedReply", 1)
+	assert.Len(t, records, 1)
+	assert.Equal(t, oldReply, records[0].ReplyFile)
+	assert.Equal(t, abandonedReplyTooOld, records[0].Reason)
+	assert.NotNil(t, records[0].Reply)
+}
+
+// TestSendFailedRepliesAbandonsAfterMaxAttempts tests the sendFailedReplies function gives up a reply once it failed the max attempts
+func TestSendFailedRepliesAbandonsAfterMaxAttempts(t *testing.T) {
+	config := appconfig.SsmagentConfig{}
+	config.Mds.ReplyRetryMaxAttempts = 2
+	contextMock := new(context.Mock)
+	contextMock.On("Log").Return(log.NewMockLog())
+	contextMock.On("AppConfig").Return(config)
+
+	var records []abandonedReply
+	recordAbandonedReply = func(log log.T, instanceID string, record abandonedReply) error {
+		records = append(records, record)
+		return nil
+	}
+	defer func() { recordAbandonedReply = saveAbandonedReply }()
+
+	// create mocked service and set expectations
+	mdsMock := new(runcommandmock.MockedMDS)
+	replies := GetTestFailedReplies()
+	mdsMock.On("LoadFailedReplies", mock.AnythingOfType("*log.Mock")).Return(replies)
+	mdsMock.On("GetFailedReply", mock.AnythingOfType("*log.Mock"), mock.AnythingOfType("string")).Return(&ssmmds.SendReplyInput{}, nil)
+	mdsMock.On("SendReplyWithInput", mock.AnythingOfType("*log.Mock"), &ssmmds.SendReplyInput{}).Return(fmt.Errorf("some error"))
+	mdsMock.On("DeleteFailedReply", mock.AnythingOfType("*log.Mock"), mock.AnythingOfType("string")).Return()
+
+	proc := RunCommandService{
+		name:    mdsName,
+		context: contextMock,
+		service: mdsMock,
+	}
+
+	proc.sendFailedReplies()
+	assert.Len(t, records, 0)
+	assert.Equal(t, 1, proc.replyAttempts[replies[0]])
+
+	proc.resumeFailedReplies()
+	mdsMock.AssertNumberOfCalls(t, "SendReplyWithInput", 2)
+	mdsMock.AssertCalled(t, "DeleteFailedReply", mock.AnythingOfType("*log.Mock"), replies[0])
+	assert.Len(t, records, 1)
+	assert.Equal(t, replies[0], records[0].ReplyFile)
+	assert.Equal(t, abandonedReplyTooManyAttempts, records[0].Reason)
+	assert.Equal(t, 2, records[0].Attempts)
+	_, tracked := proc.replyAttempts[replies[0]]
+	assert.False(t, tracked)
+}
+
+func TestSendFailedRepliesAbandonsRejectedReply(t *testing.T) {
+	contextMock := new(context.Mock)
+	contextMock.On("Log").Return(log.NewMockLog())
+	contextMock.On("AppConfig").Return(appconfig.SsmagentConfig{})
+
+	var records []abandonedReply
+	recordAbandonedReply = func(log log.T, instanceID string, record abandonedReply) error {
+		records = append(records, record)
+		return nil
+	}
+	defer func() { recordAbandonedReply = saveAbandonedReply }()
+
+	mdsMock := new(runcommandmock.MockedMDS)
+	replies := GetTestFailedReplies()
+	rejected := &ssmmds.SendReplyInput{MessageId: aws.String("rejected")}
+	accepted := &ssmmds.SendReplyInput{MessageId: aws.String("accepted")}
+	mdsMock.On("LoadFailedReplies", mock.AnythingOfType("*log.Mock")).Return(replies)
+	mdsMock.On("GetFailedReply", mock.AnythingOfType("*log.Mock"), replies[0]).Return(rejected, nil)
+	mdsMock.On("GetFailedReply", mock.AnythingOfType("*log.Mock"), mock.AnythingOfType("string")).Return(accepted, nil)
+	mdsMock.On("SendReplyWithInput", mock.AnythingOfType("*log.Mock"), rejected).Return(
+		awserr.NewRequestFailure(awserr.New("InvalidInstanceId", "", nil), 400, "requestID"))
+	mdsMock.On("SendReplyWithInput", mock.AnythingOfType("*log.Mock"), accepted).Return(nil)
+	mdsMock.On("DeleteFailedReply", mock.AnythingOfType("*log.Mock"), mock.AnythingOfType("string")).Return()
+
+	proc := RunCommandService{
+		name:    mdsName,
+		context: contextMock,
+		service: mdsMock,
+	}
+
+	proc.sendFailedReplies()
+	mdsMock.AssertNumberOfCalls(t, "SendReplyWithInput", 3)
+	mdsMock.AssertNumberOfCalls(t, "DeleteFailedReply", 3)
+	assert.Len(t, records, 1)
+	assert.Equal(t, replies[0], records[0].ReplyFile)
+	assert.Equal(t, abandonedReplyRejected, records[0].Reason)
+	// the rejected reply isn't retried, so the following attempt doesn't wait
+	assert.True(t, proc.replyBackoff.Ready())
+}
+
+func TestSendFailedRepliesHoldsBackFailedCommandOnly(t *testing.T) {
+	contextMock := new(context.Mock)
+	contextMock.On("Log").Return(log.NewMockLog())
+	contextMock.On("AppConfig").Return(appconfig.SsmagentConfig{})
+
+	// the replies are sent in the order they were saved, regardless of the order of the files
+	now := time.Now().UTC()
+	progressReply := "progress_" + now.Format("2006-01-02T15-04-05")
+	otherReply := "other_" + now.Add(-time.Second).Format("2006-01-02T15-04-05")
+	finalReply := "final_" + now.Add(time.Second).Format("2006-01-02T15-04-05")
+	progress := &ssmmds.SendReplyInput{MessageId: aws.String("command"), ReplyId: aws.String("progress")}
+	final := &ssmmds.SendReplyInput{MessageId: aws.String("command"), ReplyId: aws.String("final")}
+	other := &ssmmds.SendReplyInput{MessageId: aws.String("other")}
+
+	mdsMock := new(runcommandmock.MockedMDS)
+	mdsMock.On("LoadFailedReplies", mock.AnythingOfType("*log.Mock")).Return([]string{progressReply, otherReply, finalReply})
+	mdsMock.On("GetFailedReply", mock.AnythingOfType("*log.Mock"), progressReply).Return(progress, nil)
+	mdsMock.On("GetFailedReply", mock.AnythingOfType("*log.Mock"), otherReply).Return(other, nil)
+	mdsMock.On("GetFailedReply", mock.AnythingOfType("*log.Mock"), finalReply).Return(final, nil)
+	mdsMock.On("SendReplyWithInput", mock.AnythingOfType("*log.Mock"), progress).Return(
+		awserr.NewRequestFailure(awserr.New("ThrottlingException", "Rate exceeded", nil), 400, "requestID"))
+	mdsMock.On("SendReplyWithInput", mock.AnythingOfType("*log.Mock"), other).Return(nil)
+	mdsMock.On("DeleteFailedReply", mock.AnythingOfType("*log.Mock"), mock.AnythingOfType("string")).Return()
+
+	proc := RunCommandService{
+		name:    mdsName,
+		context: contextMock,
+		service: mdsMock,
+	}
+
+	proc.sendFailedReplies()
+	mdsMock.AssertNumberOfCalls(t, "SendReplyWithInput", 2)
+	mdsMock.AssertCalled(t, "SendReplyWithInput", mock.AnythingOfType("*log.Mock"), other)
+	mdsMock.AssertNotCalled(t, "SendReplyWithInput", mock.AnythingOfType("*log.Mock"), final)
+	mdsMock.AssertCalled(t, "DeleteFailedReply", mock.AnythingOfType("*log.Mock"), otherReply)
+	mdsMock.AssertNumberOfCalls(t, "DeleteFailedReply", 1)
+	assert.Equal(t, 1, proc.replyAttempts[progressReply])
+	assert.False(t, proc.replyBackoff.Ready())
+}
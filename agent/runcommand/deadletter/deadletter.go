@@ -0,0 +1,211 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package deadletter keeps the run command messages whose document failed to parse, together with the parse error,
+// so that operators can inspect them and requeue them once the cause is fixed.
+package deadletter
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/fileutil"
+	"github.com/aws/amazon-ssm-agent/agent/jsonutil"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/times"
+	"github.com/aws/aws-sdk-go/service/ssmmds"
+)
+
+const (
+	// RequeueDirName represents the folder where the entries requeued for processing are moved
+	RequeueDirName = "requeue"
+
+	// maxEntries is the number of entries kept, the oldest entries are deleted first
+	maxEntries = 100
+
+	// maxTotalBytes is the size of the entries kept, the oldest entries are deleted first
+	maxTotalBytes = 10 * 1024 * 1024
+)
+
+// dataStorePath is assigned to a variable to allow unit tests to override it
+var dataStorePath = appconfig.DefaultDataStorePath
+
+// Entry is a message which failed to parse
+type Entry struct {
+	// ID is the ID of the message
+	ID string
+	// Service is the name of the run command service which received the message
+	Service      string
+	ReceivedDate string
+	Error        string
+	Message      *ssmmds.Message
+}
+
+// Add records the message which failed to parse, an entry recorded earlier for the same message is replaced
+func Add(log log.T, instanceID string, service string, msg *ssmmds.Message, parseErr error) error {
+	if msg.MessageId == nil || !isValidID(*msg.MessageId) {
+		return fmt.Errorf("invalid message id")
+	}
+	entry := Entry{
+		ID:           *msg.MessageId,
+		Service:      service,
+		ReceivedDate: times.ToIso8601UTC(time.Now()),
+		Error:        parseErr.Error(),
+		Message:      msg,
+	}
+	content, err := jsonutil.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	location := getLocation(instanceID)
+	if err = fileutil.MakeDirs(location); err != nil {
+		return fmt.Errorf("cannot make directory of %v because: %v", location, err)
+	}
+	if _, err = fileutil.WriteIntoFileWithPermissions(
+		path.Join(location, entry.ID),
+		content,
+		os.FileMode(int(appconfig.ReadWriteAccess))); err != nil {
+		return err
+	}
+	log.Infof("Message %v failed to parse, it was saved in %v", entry.ID, location)
+
+	prune(log, location)
+	return nil
+}
+
+// List returns the entries, oldest first
+func List(instanceID string) ([]Entry, error) {
+	location := getLocation(instanceID)
+	files, err := ioutil.ReadDir(location)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Entry{}, nil
+		}
+		return nil, err
+	}
+
+	entries := []Entry{}
+	for _, file := range byModTime(files) {
+		if file.IsDir() {
+			continue
+		}
+		var entry Entry
+		if err = jsonutil.UnmarshalFile(path.Join(location, file.Name()), &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// Get returns the entry of the message
+func Get(instanceID string, id string) (entry Entry, err error) {
+	if !isValidID(id) {
+		return entry, fmt.Errorf("invalid message id %v", id)
+	}
+	fileName := path.Join(getLocation(instanceID), id)
+	if !fileutil.Exists(fileName) {
+		return entry, fmt.Errorf("no dead letter found for message %v", id)
+	}
+	err = jsonutil.UnmarshalFile(fileName, &entry)
+	return
+}
+
+// Requeue hands the entry back to the run command service which received the message,
+// the message is processed again like a newly received message
+func Requeue(instanceID string, id string) error {
+	if _, err := Get(instanceID, id); err != nil {
+		return err
+	}
+	location := getLocation(instanceID)
+	requeueLocation := path.Join(location, RequeueDirName)
+	if err := fileutil.MakeDirs(requeueLocation); err != nil {
+		return fmt.Errorf("cannot make directory of %v because: %v", requeueLocation, err)
+	}
+	if moved, err := fileutil.MoveFile(id, location, requeueLocation); !moved {
+		return fmt.Errorf("cannot requeue message %v, %v", id, err)
+	}
+	return nil
+}
+
+// TakeRequeued removes and returns the requeued messages received by the service
+func TakeRequeued(log log.T, instanceID string, service string) (messages []*ssmmds.Message) {
+	location := path.Join(getLocation(instanceID), RequeueDirName)
+	files, err := ioutil.ReadDir(location)
+	if err != nil {
+		return
+	}
+	for _, file := range byModTime(files) {
+		fileName := path.Join(location, file.Name())
+		var entry Entry
+		if err = jsonutil.UnmarshalFile(fileName, &entry); err != nil {
+			log.Warnf("Deleting requeued message %v which can't be loaded, %v", file.Name(), err)
+			os.Remove(fileName)
+			continue
+		}
+		if entry.Service != service || entry.Message == nil {
+			continue
+		}
+		if err = os.Remove(fileName); err != nil {
+			continue
+		}
+		log.Infof("Processing requeued message %v", entry.ID)
+		messages = append(messages, entry.Message)
+	}
+	return
+}
+
+// prune deletes the oldest entries until the entries fit in the limits
+func prune(log log.T, location string) {
+	files, err := ioutil.ReadDir(location)
+	if err != nil {
+		return
+	}
+	var count, totalBytes int64
+	files = byModTime(files)
+	for i := len(files) - 1; i >= 0; i-- {
+		if files[i].IsDir() {
+			continue
+		}
+		count++
+		totalBytes += files[i].Size()
+		if count > maxEntries || totalBytes > maxTotalBytes {
+			log.Debugf("Deleting dead letter %v", files[i].Name())
+			os.Remove(path.Join(location, files[i].Name()))
+		}
+	}
+}
+
+// byModTime sorts the files by modification time, oldest first
+func byModTime(files []os.FileInfo) []os.FileInfo {
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].ModTime().Before(files[j].ModTime())
+	})
+	return files
+}
+
+func isValidID(id string) bool {
+	return id != "" && id != "." && id != ".." && filepath.Base(id) == id
+}
+
+// getLocation returns the folder holding the entries
+func getLocation(instanceID string) string {
+	return path.Join(dataStorePath, instanceID, appconfig.DeadLetterRootDirName)
+}
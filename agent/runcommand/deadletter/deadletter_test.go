@@ -0,0 +1,112 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package deadletter keeps the run command messages whose document failed to parse
+package deadletter
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssmmds"
+	"github.com/stretchr/testify/assert"
+)
+
+const testInstanceID = "i-1234567890"
+
+func setupDataStore(t *testing.T) func() {
+	dir, err := ioutil.TempDir("", "deadletter")
+	assert.NoError(t, err)
+	dataStorePath = dir
+	return func() {
+		os.RemoveAll(dir)
+		dataStorePath = appconfig.DefaultDataStorePath
+	}
+}
+
+func testMessage(messageID string) *ssmmds.Message {
+	return &ssmmds.Message{
+		MessageId: aws.String(messageID),
+		Topic:     aws.String("aws.ssm.sendCommand.test"),
+		Payload:   aws.String("{not json"),
+	}
+}
+
+func TestAddAndGet(t *testing.T) {
+	defer setupDataStore(t)()
+	logger := log.NewMockLog()
+
+	assert.NoError(t, Add(logger, testInstanceID, "MessagingDeliveryService", testMessage("message1"), fmt.Errorf("invalid character")))
+
+	entry, err := Get(testInstanceID, "message1")
+	assert.NoError(t, err)
+	assert.Equal(t, "message1", entry.ID)
+	assert.Equal(t, "MessagingDeliveryService", entry.Service)
+	assert.Equal(t, "invalid character", entry.Error)
+	assert.Equal(t, "{not json", *entry.Message.Payload)
+
+	_, err = Get(testInstanceID, "unknown")
+	assert.Error(t, err)
+	_, err = Get(testInstanceID, "../message1")
+	assert.Error(t, err)
+	assert.Error(t, Add(logger, testInstanceID, "MessagingDeliveryService", testMessage("../message1"), fmt.Errorf("invalid")))
+}
+
+func TestAddKeepsNewestEntries(t *testing.T) {
+	defer setupDataStore(t)()
+	logger := log.NewMockLog()
+
+	for i := 0; i < maxEntries+5; i++ {
+		assert.NoError(t, Add(logger, testInstanceID, "MessagingDeliveryService", testMessage(fmt.Sprintf("message%03d", i)), fmt.Errorf("invalid")))
+	}
+
+	entries, err := List(testInstanceID)
+	assert.NoError(t, err)
+	assert.Len(t, entries, maxEntries)
+	_, err = Get(testInstanceID, fmt.Sprintf("message%03d", maxEntries+4))
+	assert.NoError(t, err)
+}
+
+func TestRequeue(t *testing.T) {
+	defer setupDataStore(t)()
+	logger := log.NewMockLog()
+
+	assert.NoError(t, Add(logger, testInstanceID, "MessagingDeliveryService", testMessage("message1"), fmt.Errorf("invalid")))
+	assert.NoError(t, Add(logger, testInstanceID, "OfflineService", testMessage("message2"), fmt.Errorf("invalid")))
+	assert.NoError(t, Requeue(testInstanceID, "message1"))
+	assert.NoError(t, Requeue(testInstanceID, "message2"))
+	assert.Error(t, Requeue(testInstanceID, "message1"))
+
+	entries, err := List(testInstanceID)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+
+	messages := TakeRequeued(logger, testInstanceID, "MessagingDeliveryService")
+	assert.Len(t, messages, 1)
+	assert.Equal(t, "message1", *messages[0].MessageId)
+	assert.Empty(t, TakeRequeued(logger, testInstanceID, "MessagingDeliveryService"))
+	assert.Len(t, TakeRequeued(logger, testInstanceID, "OfflineService"), 1)
+}
+
+func TestListEmpty(t *testing.T) {
+	defer setupDataStore(t)()
+
+	entries, err := List(testInstanceID)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
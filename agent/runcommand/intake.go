@@ -0,0 +1,147 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package runcommand implements runcommand core processing module
+package runcommand
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/aws/amazon-ssm-agent/agent/jsonutil"
+	messageContracts "github.com/aws/amazon-ssm-agent/agent/runcommand/contracts"
+	"github.com/aws/aws-sdk-go/service/ssmmds"
+)
+
+// maxQueuedMessages is the number of documents, and of cancellations, the intake holds. The queued messages aren't
+// acknowledged yet, the messages received while the intake is full are delivered again by the service.
+const maxQueuedMessages = 100
+
+// messageIntake queues the received messages until they are processed. The cancel messages are taken ahead
+// of the queued documents so that a deep document queue doesn't delay the cancellations.
+type messageIntake struct {
+	lock      sync.Mutex
+	ready     *sync.Cond
+	cancels   []*ssmmds.Message
+	documents []*ssmmds.Message
+	stopped   bool
+}
+
+// newMessageIntake returns an empty intake
+func newMessageIntake() *messageIntake {
+	q := &messageIntake{}
+	q.ready = sync.NewCond(&q.lock)
+	return q
+}
+
+// push queues the message, it returns false if the intake is stopped or already holds maxQueuedMessages of its kind
+func (q *messageIntake) push(msg *ssmmds.Message) bool {
+	if q == nil {
+		return false
+	}
+
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if q.stopped {
+		return false
+	}
+	if msg.Topic != nil && strings.HasPrefix(*msg.Topic, string(CancelCommandTopicPrefix)) {
+		if len(q.cancels) >= maxQueuedMessages {
+			return false
+		}
+		q.cancels = append(q.cancels, msg)
+	} else {
+		if len(q.documents) >= maxQueuedMessages {
+			return false
+		}
+		q.documents = append(q.documents, msg)
+	}
+	q.ready.Signal()
+	return true
+}
+
+// full returns true while the intake holds maxQueuedMessages documents, polling is pointless until they are processed
+func (q *messageIntake) full() bool {
+	if q == nil {
+		return false
+	}
+
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	return len(q.documents) >= maxQueuedMessages
+}
+
+// pop waits for the next message to process, cancellations first, it returns false once the intake is stopped.
+// A cancellation of a queued document is taken after the document, so that the document is known when it is cancelled.
+func (q *messageIntake) pop() (*ssmmds.Message, bool) {
+	if q == nil {
+		return nil, false
+	}
+
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	for !q.stopped && len(q.cancels) == 0 && len(q.documents) == 0 {
+		q.ready.Wait()
+	}
+	if q.stopped {
+		return nil, false
+	}
+
+	if len(q.cancels) == 0 {
+		msg := q.documents[0]
+		q.documents = q.documents[1:]
+		return msg, true
+	}
+
+	cancel := q.cancels[0]
+	if target := cancelTarget(cancel); target != "" {
+		for i, msg := range q.documents {
+			if msg.MessageId != nil && *msg.MessageId == target {
+				q.documents = append(q.documents[:i], q.documents[i+1:]...)
+				return msg, true
+			}
+		}
+	}
+	q.cancels = q.cancels[1:]
+	return cancel, true
+}
+
+// stop wakes up the waiting pop and drops the queued messages, the messages weren't acknowledged yet so they are delivered again
+func (q *messageIntake) stop() {
+	if q == nil {
+		return
+	}
+
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	q.stopped = true
+	q.cancels = nil
+	q.documents = nil
+	q.ready.Broadcast()
+}
+
+// cancelTarget returns the ID of the message the cancel message cancels, or an empty string if the payload is not valid
+func cancelTarget(msg *ssmmds.Message) string {
+	if msg.Payload == nil {
+		return ""
+	}
+	var payload messageContracts.CancelPayload
+	if err := jsonutil.Unmarshal(*msg.Payload, &payload); err != nil {
+		return ""
+	}
+	return payload.CancelMessageID
+}
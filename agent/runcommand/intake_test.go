@@ -0,0 +1,110 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package runcommand implements runcommand core processing module
+package runcommand
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssmmds"
+	"github.com/stretchr/testify/assert"
+)
+
+func documentMessage(messageID string) *ssmmds.Message {
+	return &ssmmds.Message{
+		MessageId: aws.String(messageID),
+		Topic:     aws.String(string(SendCommandTopicPrefix) + "test"),
+	}
+}
+
+func cancelMessage(messageID string, cancelMessageID string) *ssmmds.Message {
+	return &ssmmds.Message{
+		MessageId: aws.String(messageID),
+		Topic:     aws.String(string(CancelCommandTopicPrefix) + "test"),
+		Payload:   aws.String(`{"CancelMessageId":"` + cancelMessageID + `"}`),
+	}
+}
+
+func popMessageIDs(q *messageIntake, n int) (ids []string) {
+	for i := 0; i < n; i++ {
+		msg, ok := q.pop()
+		if !ok {
+			return
+		}
+		ids = append(ids, *msg.MessageId)
+	}
+	return
+}
+
+func TestMessageIntakeProcessesCancelsFirst(t *testing.T) {
+	q := newMessageIntake()
+	assert.True(t, q.push(documentMessage("document1")))
+	assert.True(t, q.push(documentMessage("document2")))
+	assert.True(t, q.push(cancelMessage("cancel1", "running")))
+	assert.True(t, q.push(documentMessage("document3")))
+
+	assert.Equal(t, []string{"cancel1", "document1", "document2", "document3"}, popMessageIDs(q, 4))
+}
+
+func TestMessageIntakeProcessesCancelledDocumentFirst(t *testing.T) {
+	q := newMessageIntake()
+	q.push(documentMessage("document1"))
+	q.push(documentMessage("document2"))
+	q.push(cancelMessage("cancel2", "document2"))
+
+	// the queued document is handed to the processor right before its cancellation
+	assert.Equal(t, []string{"document2", "cancel2", "document1"}, popMessageIDs(q, 3))
+}
+
+func TestMessageIntakeStop(t *testing.T) {
+	q := newMessageIntake()
+	q.push(documentMessage("document1"))
+
+	popped := make(chan bool)
+	go func() {
+		q.pop()
+		_, ok := q.pop()
+		popped <- ok
+	}()
+	time.Sleep(10 * time.Millisecond)
+	q.stop()
+
+	select {
+	case ok := <-popped:
+		assert.False(t, ok)
+	case <-time.After(time.Second):
+		assert.Fail(t, "pop was not woken up by stop")
+	}
+	assert.False(t, q.push(documentMessage("document2")))
+}
+
+func TestMessageIntakeRejectsMessagesWhenFull(t *testing.T) {
+	q := newMessageIntake()
+	for i := 0; i < maxQueuedMessages; i++ {
+		assert.False(t, q.full())
+		assert.True(t, q.push(documentMessage(fmt.Sprintf("document%d", i))))
+	}
+
+	assert.True(t, q.full())
+	assert.False(t, q.push(documentMessage("overflow")))
+	// the cancellations are queued apart from the documents
+	assert.True(t, q.push(cancelMessage("cancel1", "running")))
+
+	assert.Equal(t, []string{"cancel1", "document0"}, popMessageIDs(q, 2))
+	assert.False(t, q.full())
+	assert.True(t, q.push(documentMessage("overflow")))
+}
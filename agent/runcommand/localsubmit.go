@@ -0,0 +1,141 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package runcommand implements runcommand core processing module
+package runcommand
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/fileutil"
+	"github.com/aws/amazon-ssm-agent/agent/jsonutil"
+	messageContracts "github.com/aws/amazon-ssm-agent/agent/runcommand/contracts"
+	mdsService "github.com/aws/amazon-ssm-agent/agent/runcommand/mds"
+	"github.com/aws/amazon-ssm-agent/agent/times"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssmmds"
+	"github.com/twinj/uuid"
+)
+
+// Assign the local command directories to global variables to allow unittest to override
+var localCommandSubmittedDir = appconfig.LocalCommandRootSubmitted
+var localCommandCompletedDir = appconfig.LocalCommandRootCompleted
+
+// SubmitDocument runs a command document submitted by local tooling like the documents of the local command folder,
+// it returns the command ID the status of the document is polled with
+func (s *RunCommandService) SubmitDocument(documentName string, content contracts.DocumentContent) (commandID string, err error) {
+	if s.name != offlineName {
+		return "", fmt.Errorf("%v doesn't run local documents", s.name)
+	}
+	if documentName == "" || strings.ContainsAny(documentName, `/\`) {
+		return "", fmt.Errorf("invalid document name %v", documentName)
+	}
+
+	uuid.SwitchFormat(uuid.CleanHyphen)
+	commandID = uuid.NewV4().String()
+	msg, err := mdsService.NewLocalCommandMessage(string(SendCommandTopicPrefixOffline), s.config.InstanceID, commandID, documentName, content)
+	if err != nil {
+		return "", fmt.Errorf("failed to create message for document %v, %v", documentName, err)
+	}
+
+	// keep the document along with the documents picked up from the local command folder
+	documentContent, err := jsonutil.Marshal(content)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal document %v, %v", documentName, err)
+	}
+	if err = fileutil.MakeDirs(localCommandSubmittedDir); err != nil {
+		return "", fmt.Errorf("failed to create directory %v, %v", localCommandSubmittedDir, err)
+	}
+	if err = fileutil.WriteAllText(filepath.Join(localCommandSubmittedDir, documentName+"."+commandID), documentContent); err != nil {
+		return "", fmt.Errorf("failed to persist document %v, %v", documentName, err)
+	}
+
+	if !s.deliverMessage(msg) {
+		return "", fmt.Errorf("document %v is not valid or too many documents are queued", documentName)
+	}
+	s.context.Log().Infof("Running local document %v with command ID %v", documentName, commandID)
+	return commandID, nil
+}
+
+// CancelDocument cancels a command submitted locally that didn't complete yet
+func (s *RunCommandService) CancelDocument(commandID string) error {
+	if s.name != offlineName {
+		return fmt.Errorf("%v doesn't run local documents", s.name)
+	}
+	status, _, found, err := s.DocumentStatus(commandID)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("command %v not found", commandID)
+	}
+	if status != contracts.ResultStatusNotStarted && status != contracts.ResultStatusInProgress {
+		return fmt.Errorf("command %v already completed with status %v", commandID, status)
+	}
+
+	uuid.SwitchFormat(uuid.CleanHyphen)
+	payload, err := jsonutil.Marshal(messageContracts.CancelPayload{
+		CancelMessageID: fmt.Sprintf("aws.ssm.%v.%v", commandID, s.config.InstanceID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal the cancellation of command %v, %v", commandID, err)
+	}
+	created := times.ToIso8601UTC(time.Now())
+	msg := &ssmmds.Message{
+		CreatedDate: aws.String(created),
+		Destination: aws.String(s.config.InstanceID),
+		MessageId:   aws.String(fmt.Sprintf("aws.ssm.%v.%v", uuid.NewV4().String(), s.config.InstanceID)),
+		Payload:     aws.String(payload),
+		Topic:       aws.String(string(CancelCommandTopicPrefixOffline) + commandID),
+	}
+	if !s.deliverMessage(msg) {
+		return fmt.Errorf("failed to cancel command %v", commandID)
+	}
+	s.context.Log().Infof("Cancelling local command %v", commandID)
+	return nil
+}
+
+// DocumentStatus returns the status of a command submitted locally and its latest reply,
+// found is false if no document was submitted with the command ID
+func (s *RunCommandService) DocumentStatus(commandID string) (status contracts.ResultStatus, reply string, found bool, err error) {
+	if commandID == "" || strings.ContainsAny(commandID, `/\.`) {
+		return "", "", false, nil
+	}
+
+	resultPath := filepath.Join(localCommandCompletedDir, commandID)
+	if fileutil.Exists(resultPath) {
+		if reply, err = fileutil.ReadAllText(resultPath); err != nil {
+			return "", "", true, fmt.Errorf("failed to read the reply of command %v, %v", commandID, err)
+		}
+		var payload messageContracts.SendReplyPayload
+		if err = json.Unmarshal([]byte(reply), &payload); err != nil {
+			return "", "", true, fmt.Errorf("failed to parse the reply of command %v, %v", commandID, err)
+		}
+		return payload.DocumentStatus, reply, true, nil
+	}
+
+	// the document waits for a worker until its first reply
+	files, _ := fileutil.GetFileNames(localCommandSubmittedDir)
+	for _, file := range files {
+		if strings.HasSuffix(file, "."+commandID) {
+			return contracts.ResultStatusNotStarted, "", true, nil
+		}
+	}
+	return "", "", false, nil
+}
@@ -0,0 +1,145 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package runcommand implements runcommand core processing module
+package runcommand
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/fileutil"
+	"github.com/aws/aws-sdk-go/service/ssmmds"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubmitDocument(t *testing.T) {
+	dir, err := ioutil.TempDir("", "localcommands")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	localCommandSubmittedDir = filepath.Join(dir, "submitted")
+	localCommandCompletedDir = filepath.Join(dir, "completed")
+	defer func() {
+		localCommandSubmittedDir = appconfig.LocalCommandRootSubmitted
+		localCommandCompletedDir = appconfig.LocalCommandRootCompleted
+	}()
+
+	processed := make(chan *ssmmds.Message, 1)
+	queueMessage = func(svc *RunCommandService, msg *ssmmds.Message) bool {
+		processed <- msg
+		return true
+	}
+	defer func() { queueMessage = (*RunCommandService).queueMessage }()
+
+	proc := RunCommandService{
+		name:    offlineName,
+		context: MockContext(),
+		config:  contracts.AgentConfiguration{InstanceID: "i-1234567890"},
+	}
+
+	commandID, err := proc.SubmitDocument("bootstrap", contracts.DocumentContent{SchemaVersion: "2.2"})
+	assert.NoError(t, err)
+	select {
+	case msg := <-processed:
+		assert.Equal(t, "aws.ssm."+commandID+".i-1234567890", *msg.MessageId)
+		assert.True(t, strings.HasPrefix(*msg.Topic, string(SendCommandTopicPrefixOffline)))
+	case <-time.After(time.Second):
+		assert.Fail(t, "document was not processed")
+	}
+
+	status, reply, found, err := proc.DocumentStatus(commandID)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, contracts.ResultStatusNotStarted, status)
+	assert.Empty(t, reply)
+
+	// the offline service writes the replies of the command in the completed folder
+	assert.NoError(t, fileutil.MakeDirs(localCommandCompletedDir))
+	assert.NoError(t, fileutil.WriteAllText(filepath.Join(localCommandCompletedDir, commandID), `{"documentStatus":"InProgress"}`))
+	status, reply, found, err = proc.DocumentStatus(commandID)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, contracts.ResultStatusInProgress, status)
+	assert.Equal(t, `{"documentStatus":"InProgress"}`, reply)
+
+	_, _, found, err = proc.DocumentStatus("unknown")
+	assert.NoError(t, err)
+	assert.False(t, found)
+	_, _, found, _ = proc.DocumentStatus("../" + commandID)
+	assert.False(t, found)
+}
+
+func TestSubmitDocumentInvalid(t *testing.T) {
+	proc := RunCommandService{
+		name:    offlineName,
+		context: MockContext(),
+	}
+	_, err := proc.SubmitDocument("../bootstrap", contracts.DocumentContent{})
+	assert.Error(t, err)
+
+	// documents only run on the offline processor
+	proc.name = mdsName
+	_, err = proc.SubmitDocument("bootstrap", contracts.DocumentContent{})
+	assert.Error(t, err)
+}
+
+func TestCancelDocument(t *testing.T) {
+	dir, err := ioutil.TempDir("", "localcommands")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	localCommandSubmittedDir = filepath.Join(dir, "submitted")
+	localCommandCompletedDir = filepath.Join(dir, "completed")
+	defer func() {
+		localCommandSubmittedDir = appconfig.LocalCommandRootSubmitted
+		localCommandCompletedDir = appconfig.LocalCommandRootCompleted
+	}()
+
+	processed := make(chan *ssmmds.Message, 2)
+	queueMessage = func(svc *RunCommandService, msg *ssmmds.Message) bool {
+		processed <- msg
+		return true
+	}
+	defer func() { queueMessage = (*RunCommandService).queueMessage }()
+
+	proc := RunCommandService{
+		name:    offlineName,
+		context: MockContext(),
+		config:  contracts.AgentConfiguration{InstanceID: "i-1234567890"},
+	}
+
+	assert.Error(t, proc.CancelDocument("unknown"))
+
+	commandID, err := proc.SubmitDocument("bootstrap", contracts.DocumentContent{SchemaVersion: "2.2"})
+	assert.NoError(t, err)
+	<-processed
+
+	assert.NoError(t, proc.CancelDocument(commandID))
+	select {
+	case msg := <-processed:
+		assert.Equal(t, string(CancelCommandTopicPrefixOffline)+commandID, *msg.Topic)
+		assert.Contains(t, *msg.Payload, "aws.ssm."+commandID+".i-1234567890")
+	case <-time.After(time.Second):
+		assert.Fail(t, "cancellation was not processed")
+	}
+
+	// completed commands can't be cancelled
+	assert.NoError(t, fileutil.MakeDirs(localCommandCompletedDir))
+	assert.NoError(t, fileutil.WriteAllText(filepath.Join(localCommandCompletedDir, commandID), `{"documentStatus":"Success"}`))
+	assert.Error(t, proc.CancelDocument(commandID))
+}
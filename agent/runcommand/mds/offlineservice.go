@@ -81,7 +81,6 @@ func (ols *offlineService) GetMessages(log log.T, instanceID string) (messages *
 		messages.MessagesRequestId = &requestUuid // TODO:MF: Can this be the same as the commandID?
 
 		commandID := uuid.NewV4().String()
-		messageID := fmt.Sprintf("aws.ssm.%v.%v", commandID, instanceID)
 
 		// Parse file
 		var content contracts.DocumentContent
@@ -96,24 +95,14 @@ func (ols *offlineService) GetMessages(log log.T, instanceID string) (messages *
 		log.Debugf("Local command content:\n%v", debugContent)
 
 		// Turn it into a message
-		payload := &messageContracts.SendCommandPayload{DocumentContent: content, CommandID: commandID, DocumentName: docName}
-		var payloadstr string
-		if payloadstr, err = jsonutil.Marshal(payload); err != nil {
-			log.Errorf("Error marshalling message for command document %v with message ID %v:\n%v", docName, messageID, err)
+		var message *ssmmds.Message
+		if message, err = NewLocalCommandMessage(ols.TopicPrefix, instanceID, commandID, docName, content); err != nil {
+			log.Errorf("Error creating message for command document %v with command ID %v:\n%v", docName, commandID, err)
 			if errMove := moveCommandDocument(ols.newCommandDir, ols.invalidCommandDir, docName, commandID); errMove != nil {
 				log.Errorf("Command %v was invalid but failed to move to invalid folder: %v", commandID, errMove.Error())
 			}
 			continue
 		}
-		created := times.ToIso8601UTC(time.Now())
-		topic := fmt.Sprintf("%v.%v", ols.TopicPrefix, docName)
-		message := &ssmmds.Message{
-			CreatedDate: &created,
-			Destination: &instanceID,
-			MessageId:   &messageID,
-			Payload:     &payloadstr,
-			Topic:       &topic,
-		}
 		// Move to submitted
 		if errMove := moveCommandDocument(ols.newCommandDir, ols.submittedCommandDir, docName, commandID); errMove != nil {
 			log.Errorf("Command %v was valid but failed to move to submitted folder: %v", commandID, errMove.Error())
@@ -126,6 +115,25 @@ func (ols *offlineService) GetMessages(log log.T, instanceID string) (messages *
 	return messages, nil
 }
 
+// NewLocalCommandMessage turns a command document submitted on the instance into a message for the given command ID
+func NewLocalCommandMessage(topicPrefix string, instanceID string, commandID string, docName string, content contracts.DocumentContent) (message *ssmmds.Message, err error) {
+	messageID := fmt.Sprintf("aws.ssm.%v.%v", commandID, instanceID)
+	payload := &messageContracts.SendCommandPayload{DocumentContent: content, CommandID: commandID, DocumentName: docName}
+	var payloadstr string
+	if payloadstr, err = jsonutil.Marshal(payload); err != nil {
+		return nil, err
+	}
+	created := times.ToIso8601UTC(time.Now())
+	topic := fmt.Sprintf("%v.%v", topicPrefix, docName)
+	return &ssmmds.Message{
+		CreatedDate: &created,
+		Destination: &instanceID,
+		MessageId:   &messageID,
+		Payload:     &payloadstr,
+		Topic:       &topic,
+	}, nil
+}
+
 // TODO:MF: clean up old documents in dstDir?  Or maybe do that in SendReply?  Maybe both
 // moveCommandDocument moves a command into its final destination and attaches the command ID file extension
 func moveCommandDocument(srcDir string, dstDir string, docName string, commandID string) error {
@@ -28,8 +28,10 @@ import (
 	"github.com/aws/amazon-ssm-agent/agent/fileutil"
 	"github.com/aws/amazon-ssm-agent/agent/jsonutil"
 	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/metrics"
 	"github.com/aws/amazon-ssm-agent/agent/platform"
 	"github.com/aws/amazon-ssm-agent/agent/sdkutil"
+	"github.com/aws/amazon-ssm-agent/agent/tracing"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/request"
@@ -120,6 +122,7 @@ func NewService(region string, endpoint string, creds *credentials.Credentials,
 	appConfig, _ := appconfig.Config(false)
 	sess := session.New(config)
 	sess.Handlers.Build.PushBack(request.MakeAddToUserAgentHandler(appConfig.Agent.Name, appConfig.Agent.Version))
+	tracing.TraceRequests(&sess.Handlers)
 
 	msgSvc := ssmmds.New(sess)
 
@@ -164,9 +167,11 @@ func (mds *sdkService) GetMessages(log log.T, instanceID string) (messages *ssmm
 			//GetMessages api responded with unexpected errors - we must return this as error
 			err = fmt.Errorf("GetMessages Error: %v", requestErr)
 			log.Debug(err)
+			metrics.Increment(metrics.ChannelMds, metrics.EventFailed)
 		}
 	} else {
 		log.Debug("GetMessages Response", messages)
+		metrics.Add(metrics.ChannelMds, metrics.EventReceived, int64(len(messages.Messages)))
 	}
 	return
 }
@@ -225,8 +230,10 @@ func (mds *sdkService) AcknowledgeMessage(log log.T, messageID string) (err erro
 	if err = mds.sendRequest(req); err != nil {
 		err = fmt.Errorf("AcknowledgeMessage Error: %v", err)
 		log.Debug(err)
+		metrics.Increment(metrics.ChannelMds, metrics.EventFailed)
 	} else {
 		log.Debug("AcknowledgeMessage Response", resp)
+		metrics.Increment(metrics.ChannelMds, metrics.EventAcked)
 	}
 	return
 }
@@ -236,10 +243,12 @@ func (mds *sdkService) SendReplyWithInput(log log.T, sendReply *ssmmds.SendReply
 	log.Debug("Calling SendReply with params", sendReply)
 	req, resp := mds.sdk.SendReplyRequest(sendReply)
 	if err = mds.sendRequest(req); err != nil {
-		err = fmt.Errorf("SendReply Error: %v", err)
-		log.Debug(err)
+		// the error is returned as is, so that the caller can tell the errors worth retrying apart
+		log.Debugf("SendReply Error: %v", err)
+		metrics.Increment(metrics.ChannelMds, metrics.EventFailed)
 	} else {
 		log.Info("SendReply Response", resp)
+		metrics.Increment(metrics.ChannelMds, metrics.EventReplied)
 	}
 	return
 }
@@ -254,6 +263,10 @@ func (mds *sdkService) SendReply(log log.T, messageID string, payload string) (e
 		ReplyId:   aws.String(replyID),   // Required
 	}
 	if err = mds.SendReplyWithInput(log, &replyInput); err != nil {
+		if !sdkutil.IsRetryableError(err) {
+			log.Errorf("The service rejected reply %v, %v", replyID, err)
+			return
+		}
 		log.Infof("Saving reply %v to local disk", replyID)
 		mds.PersistFailedReply(log, replyInput)
 	}
@@ -378,11 +391,22 @@ func (mds *sdkService) Stop() {
 	}
 }
 
-// sendRequest wraps req.Send() so that it can keep track of the executing request
-func (mds *sdkService) sendRequest(req *request.Request) error {
+// sendRequest wraps req.Send() so that it can keep track of the executing request and record its latency
+func (mds *sdkService) sendRequest(req *request.Request) (err error) {
 	mds.storeRequest(req)
 	defer mds.clearRequest()
-	return mds.sendSdkRequest(req)
+
+	start := time.Now()
+	err = mds.sendSdkRequest(req)
+	if req.Operation != nil {
+		metrics.ObserveLatency(metrics.ChannelMds, req.Operation.Name, time.Since(start))
+	}
+	if err == nil {
+		metrics.RecordContact(metrics.ChannelMds)
+	} else if request.IsErrorThrottle(err) {
+		metrics.Increment(metrics.ChannelMds, metrics.EventThrottled)
+	}
+	return
 }
 
 func (mds *sdkService) storeRequest(req *request.Request) {
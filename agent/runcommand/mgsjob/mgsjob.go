@@ -0,0 +1,53 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package mgsjob hands the run command messages received over the Message Gateway Service control channel
+// to the run command service, the messages are the same messages MDS delivers when it is polled.
+package mgsjob
+
+import (
+	"sync"
+
+	"github.com/aws/amazon-ssm-agent/agent/metrics"
+	"github.com/aws/aws-sdk-go/service/ssmmds"
+)
+
+// Handler processes a run command message, it returns false if the message was not accepted
+type Handler func(message *ssmmds.Message) bool
+
+var (
+	handler Handler
+	lock    sync.RWMutex
+)
+
+// RegisterHandler sets the handler of the run command messages received over the control channel,
+// a nil handler disables the delivery over the control channel so that the messages are only received from MDS
+func RegisterHandler(h Handler) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	handler = h
+}
+
+// Deliver hands the message to the registered handler, it returns false if no handler accepted the message
+func Deliver(message *ssmmds.Message) bool {
+	lock.RLock()
+	defer lock.RUnlock()
+
+	if handler == nil || !handler(message) {
+		metrics.Increment(metrics.ChannelMgs, metrics.EventRejected)
+		return false
+	}
+	metrics.Increment(metrics.ChannelMgs, metrics.EventReceived)
+	return true
+}
@@ -0,0 +1,48 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package mgsjob hands the run command messages received over the Message Gateway Service control channel
+// to the run command service, the messages are the same messages MDS delivers when it is polled.
+package mgsjob
+
+import (
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/metrics"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssmmds"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeliver(t *testing.T) {
+	metrics.Reset()
+	defer metrics.Reset()
+
+	message := &ssmmds.Message{MessageId: aws.String("aws.ssm.commandId.instanceId")}
+	assert.False(t, Deliver(message))
+
+	var delivered *ssmmds.Message
+	RegisterHandler(func(message *ssmmds.Message) bool {
+		delivered = message
+		return true
+	})
+	assert.True(t, Deliver(message))
+	assert.Equal(t, message, delivered)
+
+	RegisterHandler(nil)
+	assert.False(t, Deliver(message))
+
+	counters := metrics.GetSnapshot().Counters
+	assert.Equal(t, int64(1), counters["mgs.received"])
+	assert.Equal(t, int64(2), counters["mgs.rejected"])
+}
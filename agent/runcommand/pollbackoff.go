@@ -0,0 +1,62 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package runcommand implements runcommand core processing module
+package runcommand
+
+import (
+	"sync"
+	"time"
+)
+
+// pollBackoff adapts the delay between two polls to the activity of the instance, the delay doubles
+// from the floor up to the ceiling while the polls return no message and drops to zero once a message arrives
+type pollBackoff struct {
+	floor   time.Duration
+	ceiling time.Duration
+	delay   time.Duration
+	lock    sync.Mutex
+}
+
+// newPollBackoff returns the backoff for the given floor and ceiling, nil if the ceiling is 0 so that polls are continuous
+func newPollBackoff(floorSeconds int, ceilingSeconds int) *pollBackoff {
+	if ceilingSeconds <= 0 {
+		return nil
+	}
+	if floorSeconds > ceilingSeconds {
+		floorSeconds = ceilingSeconds
+	}
+	return &pollBackoff{
+		floor:   time.Duration(floorSeconds) * time.Second,
+		ceiling: time.Duration(ceilingSeconds) * time.Second,
+	}
+}
+
+// next returns the delay before the next poll given the number of messages the last poll received
+func (b *pollBackoff) next(received int) time.Duration {
+	if b == nil {
+		return 0
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if received > 0 {
+		b.delay = 0
+	} else if b.delay == 0 {
+		b.delay = b.floor
+	} else if b.delay *= 2; b.delay > b.ceiling {
+		b.delay = b.ceiling
+	}
+	return b.delay
+}
@@ -0,0 +1,50 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package runcommand implements runcommand core processing module
+package runcommand
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPollBackoff(t *testing.T) {
+	backoff := newPollBackoff(5, 30)
+
+	assert.Equal(t, 5*time.Second, backoff.next(0))
+	assert.Equal(t, 10*time.Second, backoff.next(0))
+	assert.Equal(t, 20*time.Second, backoff.next(0))
+	assert.Equal(t, 30*time.Second, backoff.next(0))
+	assert.Equal(t, 30*time.Second, backoff.next(0))
+
+	// activity tightens the polls right away
+	assert.Equal(t, time.Duration(0), backoff.next(2))
+	assert.Equal(t, 5*time.Second, backoff.next(0))
+}
+
+func TestPollBackoffDisabled(t *testing.T) {
+	backoff := newPollBackoff(5, 0)
+
+	assert.Nil(t, backoff)
+	assert.Equal(t, time.Duration(0), backoff.next(0))
+}
+
+func TestPollBackoffFloorAboveCeiling(t *testing.T) {
+	backoff := newPollBackoff(60, 10)
+
+	assert.Equal(t, 10*time.Second, backoff.next(0))
+	assert.Equal(t, 10*time.Second, backoff.next(0))
+}
@@ -0,0 +1,155 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package runcommand implements runcommand core processing module
+package runcommand
+
+import (
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/fileutil"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+)
+
+const (
+	// processedMessageTTL is how long the ID of a processed message is kept, it outlives the delivery of the message
+	processedMessageTTL = 48 * time.Hour
+
+	// processedMessageSweepInterval is the minimum interval between two sweeps of the expired message IDs
+	processedMessageSweepInterval = time.Hour
+)
+
+// processedMessages persists the IDs of the messages the agent acknowledged, so that a message delivered again,
+// for example after the agent restarted, doesn't run its command twice. An ID is forgotten once its TTL elapsed.
+type processedMessages struct {
+	dir        string
+	ttl        time.Duration
+	lock       sync.Mutex
+	lastSweep  time.Time
+	inProgress map[string]bool
+}
+
+// newProcessedMessages returns the processed messages persisted in dir
+func newProcessedMessages(dir string, ttl time.Duration) *processedMessages {
+	return &processedMessages{
+		dir:        dir,
+		ttl:        ttl,
+		inProgress: make(map[string]bool),
+	}
+}
+
+// contains returns true if the message was processed within the TTL
+func (p *processedMessages) contains(log log.T, messageID string) bool {
+	if p == nil {
+		return false
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return p.containsLocked(log, messageID)
+}
+
+// claim marks the message in progress, it returns false if the message was processed or is processed by
+// another delivery, as when the message is received both over the control channel and by polling MDS
+func (p *processedMessages) claim(log log.T, messageID string) bool {
+	if p == nil {
+		return true
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.inProgress[messageID] || p.containsLocked(log, messageID) {
+		return false
+	}
+	p.inProgress[messageID] = true
+	return true
+}
+
+// release clears the claim of the message
+func (p *processedMessages) release(messageID string) {
+	if p == nil {
+		return
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	delete(p.inProgress, messageID)
+}
+
+// containsLocked returns true if the message was processed within the TTL. Caller must hold lock.
+func (p *processedMessages) containsLocked(log log.T, messageID string) bool {
+	info, err := os.Stat(p.location(messageID))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Debugf("encountered error %v while checking if message %v was processed", err, messageID)
+		}
+		return false
+	}
+	return time.Since(info.ModTime()) <= p.ttl
+}
+
+// add records the message as processed and sweeps the expired message IDs
+func (p *processedMessages) add(log log.T, messageID string) {
+	if p == nil {
+		return
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if err := fileutil.MakeDirs(p.dir); err != nil {
+		log.Errorf("encountered error %v while creating directory %v", err, p.dir)
+		return
+	}
+	if _, err := fileutil.WriteIntoFileWithPermissions(p.location(messageID), time.Now().UTC().Format(time.RFC3339), os.FileMode(int(appconfig.ReadWriteAccess))); err != nil {
+		log.Errorf("encountered error %v while recording message %v as processed", err, messageID)
+		return
+	}
+
+	if time.Since(p.lastSweep) >= processedMessageSweepInterval {
+		p.sweepLocked(log)
+	}
+}
+
+// sweepLocked deletes the message IDs older than the TTL. Caller must hold lock.
+func (p *processedMessages) sweepLocked(log log.T) {
+	p.lastSweep = time.Now()
+
+	files, err := ioutil.ReadDir(p.dir)
+	if err != nil {
+		log.Debugf("encountered error %v while listing processed messages in %v", err, p.dir)
+		return
+	}
+	for _, file := range files {
+		if file.IsDir() || time.Since(file.ModTime()) <= p.ttl {
+			continue
+		}
+		if err = fileutil.DeleteFile(filepath.Join(p.dir, file.Name())); err != nil {
+			log.Debugf("encountered error %v while deleting expired message %v", err, file.Name())
+		}
+	}
+}
+
+// location returns the file recording the message, the message ID is escaped to be a valid file name
+func (p *processedMessages) location(messageID string) string {
+	return filepath.Join(p.dir, url.QueryEscape(messageID))
+}
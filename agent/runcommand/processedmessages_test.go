@@ -0,0 +1,95 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package runcommand implements runcommand core processing module
+package runcommand
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProcessedMessages(t *testing.T) {
+	logger := log.NewMockLog()
+	dir, err := ioutil.TempDir("", "processedmessages")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	processed := newProcessedMessages(filepath.Join(dir, "messages"), time.Hour)
+	assert.False(t, processed.contains(logger, "message-1"))
+
+	processed.add(logger, "message-1")
+	assert.True(t, processed.contains(logger, "message-1"))
+	assert.False(t, processed.contains(logger, "message-2"))
+
+	// the IDs are persisted across agent restarts
+	assert.True(t, newProcessedMessages(filepath.Join(dir, "messages"), time.Hour).contains(logger, "message-1"))
+
+	// message IDs can't escape the directory
+	processed.add(logger, "../message-3")
+	assert.True(t, processed.contains(logger, "../message-3"))
+	assert.False(t, processed.contains(logger, "message-3"))
+}
+
+func TestProcessedMessagesExpire(t *testing.T) {
+	logger := log.NewMockLog()
+	dir, err := ioutil.TempDir("", "processedmessages")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	processed := newProcessedMessages(dir, time.Hour)
+	processed.add(logger, "expired")
+	expired := time.Now().Add(-2 * time.Hour)
+	assert.NoError(t, os.Chtimes(processed.location("expired"), expired, expired))
+	assert.False(t, processed.contains(logger, "expired"))
+
+	// the next add sweeps the expired IDs
+	processed.lastSweep = time.Time{}
+	processed.add(logger, "recent")
+	_, err = os.Stat(processed.location("expired"))
+	assert.True(t, os.IsNotExist(err))
+	assert.True(t, processed.contains(logger, "recent"))
+}
+
+func TestProcessedMessagesNil(t *testing.T) {
+	var processed *processedMessages
+	processed.add(log.NewMockLog(), "message")
+	assert.False(t, processed.contains(log.NewMockLog(), "message"))
+}
+
+func TestProcessedMessagesClaim(t *testing.T) {
+	logger := log.NewMockLog()
+	dir, err := ioutil.TempDir("", "processedmessages")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	processed := newProcessedMessages(dir, time.Hour)
+	assert.True(t, processed.claim(logger, "message"))
+	// the message is received again while it is processed
+	assert.False(t, processed.claim(logger, "message"))
+
+	processed.add(logger, "message")
+	processed.release("message")
+	assert.False(t, processed.claim(logger, "message"))
+
+	// a message released without being processed can be claimed again
+	assert.True(t, processed.claim(logger, "failed"))
+	processed.release("failed")
+	assert.True(t, processed.claim(logger, "failed"))
+}
@@ -25,10 +25,14 @@ import (
 	"github.com/carlescere/scheduler"
 )
 
+// intakeFullPollDelay is the delay before polling again while the message intake is full
+const intakeFullPollDelay = 5 * time.Second
+
 var lastPollTimeMap map[string]time.Time = make(map[string]time.Time)
 var lock sync.RWMutex
 
 var processMessage = (*RunCommandService).processMessage
+var queueMessage = (*RunCommandService).queueMessage
 
 func updateLastPollTime(processorType string, currentTime time.Time) {
 	lock.Lock()
@@ -68,7 +72,16 @@ func (s *RunCommandService) messagePollLoop() {
 		return
 	}
 
-	s.pollOnce()
+	s.queueRequeuedMessages()
+
+	var delay time.Duration
+	if s.intake.full() {
+		// the queued messages aren't acknowledged yet, the messages received now would be delivered again
+		log.Infof("Too many messages are queued, polling again in %v", intakeFullPollDelay)
+		delay = intakeFullPollDelay
+	} else if received, err := s.pollOnce(); err == nil {
+		delay = s.pollBackoff.next(received)
+	}
 	if s.name == mdsName {
 		log.Debugf("%v's stoppolicy after polling is %v", s.name, s.processorStopPolicy)
 	}
@@ -82,10 +95,22 @@ func (s *RunCommandService) messagePollLoop() {
 
 	// check if any other poll loop has started in the meantime
 	// to prevent any possible race condition due to the scheduler
-	if getLastPollTime(s.name) == pollStartTime {
+	if getLastPollTime(s.name) != pollStartTime {
+		return
+	}
+	if delay == 0 {
 		// skip waiting for the next scheduler polling event and start polling immediately
 		scheduleNextRun(s.messagePollJob)
+		return
 	}
+
+	// no message arrived or the intake is full, poll again once the delay elapsed
+	log.Debugf("Polling again in %v", delay)
+	time.AfterFunc(delay, func() {
+		if getLastPollTime(s.name) == pollStartTime {
+			scheduleNextRun(s.messagePollJob)
+		}
+	})
 }
 
 func (s *RunCommandService) checkStopPolicy(log log.T) error {
@@ -138,25 +163,32 @@ func (s *RunCommandService) stop() {
 	}
 }
 
-// pollOnce calls GetMessages once and processes the result.
-func (s *RunCommandService) pollOnce() {
+// pollOnce calls GetMessages once and processes the result, it returns the number of messages received.
+func (s *RunCommandService) pollOnce() (received int, err error) {
 	log := s.context.Log()
 	if s.name == mdsName {
 		log.Debugf("Polling for messages")
 	}
 	messages, err := s.service.GetMessages(log, s.config.InstanceID)
+	reconnected := err == nil && s.pollFailed
+	s.pollFailed = err != nil
 	if err != nil {
 		sdkutil.HandleAwsError(log, err, s.processorStopPolicy)
 		return
 	}
+	if reconnected {
+		log.Info("Service is reachable again, sending the failed document replies")
+		go s.resumeFailedReplies()
+	}
 	if len(messages.Messages) > 0 {
 		log.Debugf("Got %v messages", len(messages.Messages))
 	}
 
 	for _, msg := range messages.Messages {
-		processMessage(s, msg)
+		queueMessage(s, msg)
 	}
 	if s.name == mdsName {
 		log.Debugf("Done poll once")
 	}
+	return len(messages.Messages), nil
 }
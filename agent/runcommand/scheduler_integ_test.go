@@ -261,6 +261,8 @@ func TestSendReplyLoop_Multiple_Serial_Error(t *testing.T) {
 
 	for i := 0; i < multipleRetryCount; i++ {
 		proc.sendReplyLoop()
+		// skip the backoff delay so that every loop reaches the service
+		proc.replyBackoff.Succeeded()
 	}
 
 	time.Sleep(1 * time.Second)
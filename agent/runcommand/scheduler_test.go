@@ -83,8 +83,9 @@ func TestPollOnce(t *testing.T) {
 	tc.MdsMock.On("GetMessages", mock.AnythingOfType("*log.Mock"), mock.AnythingOfType("string")).Return(&getMessageOutput, nil)
 	// set expectations
 	countMessageProcessed := 0
-	processMessage = func(svc *RunCommandService, msg *ssmmds.Message) {
+	queueMessage = func(svc *RunCommandService, msg *ssmmds.Message) bool {
 		countMessageProcessed++
+		return true
 	}
 
 	// execute pollOnce
@@ -110,8 +111,9 @@ func TestPollOnceWithZeroMessage(t *testing.T) {
 	// mock GetMessages function to return mocked GetMessagesOutput and no error
 	tc.MdsMock.On("GetMessages", mock.AnythingOfType("*log.Mock"), mock.AnythingOfType("string")).Return(&getMessageOutput, nil)
 	countMessageProcessed := 0
-	processMessage = func(svc *RunCommandService, msg *ssmmds.Message) {
+	queueMessage = func(svc *RunCommandService, msg *ssmmds.Message) bool {
 		countMessageProcessed++
+		return true
 	}
 
 	// execute pollOnce
@@ -137,8 +139,9 @@ func TestPollOnceMultipleTimes(t *testing.T) {
 	// mock GetMessages function to return mocked GetMessagesOutput and no error
 	tc.MdsMock.On("GetMessages", mock.AnythingOfType("*log.Mock"), mock.AnythingOfType("string")).Return(&getMessageOutput, nil)
 	countMessageProcessed := 0
-	processMessage = func(svc *RunCommandService, msg *ssmmds.Message) {
+	queueMessage = func(svc *RunCommandService, msg *ssmmds.Message) bool {
 		countMessageProcessed++
+		return true
 	}
 
 	// execute pollOnce
@@ -164,8 +167,9 @@ func TestPollOnceWithGetMessagesReturnError(t *testing.T) {
 	// mock GetMessages function to return an error
 	tc.MdsMock.On("GetMessages", mock.AnythingOfType("*log.Mock"), mock.AnythingOfType("string")).Return(&getMessageOutput, fmt.Errorf("Test"))
 	isMessageProcessed := false
-	processMessage = func(svc *RunCommandService, msg *ssmmds.Message) {
+	queueMessage = func(svc *RunCommandService, msg *ssmmds.Message) bool {
 		isMessageProcessed = true
+		return true
 	}
 
 	// execute pollOnce
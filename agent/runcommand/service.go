@@ -17,12 +17,14 @@ package runcommand
 import (
 	"encoding/json"
 	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/aws/amazon-ssm-agent/agent/appconfig"
 	associationProcessor "github.com/aws/amazon-ssm-agent/agent/association/processor"
 	"github.com/aws/amazon-ssm-agent/agent/context"
 	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/framework/outboundqueue"
 	"github.com/aws/amazon-ssm-agent/agent/framework/processor"
 	"github.com/aws/amazon-ssm-agent/agent/framework/processor/executer"
 	"github.com/aws/amazon-ssm-agent/agent/jsonutil"
@@ -63,8 +65,11 @@ const (
 	// note: the connection timeout for MDSPoll should be less than this.
 	pollMessageFrequencyMinutes = 15
 
-	// sendReplyFrequencyMinutes is the frequency at which to send failed reply requests back to MDS
-	sendReplyFrequencyMinutes = 10
+	// sendReplyFrequencyMinutes is the frequency at which to send failed reply requests back to MDS,
+	// after a failure the replies are sent again with a backoff of replyMinBackoff up to replyMaxBackoff
+	sendReplyFrequencyMinutes = 1
+	replyMinBackoff           = 1 * time.Minute
+	replyMaxBackoff           = 10 * time.Minute
 
 	// the default stoppolicy error threshold. After 10 consecutive errors the plugin will stop for 15 minutes.
 	stopPolicyErrorThreshold = 10
@@ -97,6 +102,21 @@ type RunCommandService struct {
 	processorStopPolicy *sdkutil.StopPolicy
 	pollAssociations    bool
 	processor           processor.Processor
+	// replyLock makes sure a single loop sends the failed replies, it guards replyBackoff and replyAttempts
+	replyLock    sync.Mutex
+	replyBackoff *outboundqueue.Backoff
+	// replyAttempts counts the failed attempts to send each failed reply since the agent started
+	replyAttempts map[string]int
+	// pollFailed is set when the last poll failed to reach the service, it is only used by the poll loop
+	pollFailed bool
+	// pollBackoff spaces out the polls while no message arrives, nil polls continuously
+	pollBackoff *pollBackoff
+	// processedMessages keeps the acknowledged messages so that a message delivered again doesn't run twice
+	processedMessages *processedMessages
+	// stepStatus coalesces the step progress replies of the commands in progress
+	stepStatus *stepStatusBatcher
+	// intake queues the received messages, cancellations ahead of documents
+	intake *messageIntake
 }
 
 // NewOfflineProcessor initialize a new offline command document processor
@@ -119,7 +139,11 @@ func NewMDSService(context context.T) *RunCommandService {
 	mdsService := newMdsService(context.AppConfig())
 	config := context.AppConfig()
 
-	return NewService(messageContext, mdsName, mdsService, config.Mds.CommandWorkersLimit, CancelWorkersLimit, true, []contracts.DocumentType{contracts.SendCommand, contracts.CancelCommand})
+	service := NewService(messageContext, mdsName, mdsService, config.Mds.CommandWorkersLimit, CancelWorkersLimit, true, []contracts.DocumentType{contracts.SendCommand, contracts.CancelCommand})
+	if service != nil {
+		service.pollBackoff = newPollBackoff(config.Mds.PollBackoffFloorSeconds, config.Mds.PollBackoffCeilingSeconds)
+	}
+	return service
 }
 
 // NewProcessor performs common initialization for Mds and Offline processors
@@ -152,16 +176,36 @@ func NewService(ctx context.T, serviceName string, service mdsService.Service, c
 	// create a stop policy where we will stop after 10 consecutive errors and if time period expires.
 	stopPolicy := newStopPolicy(serviceName)
 
+	stepStatus := newStepStatusBatcher(
+		time.Duration(config.Mds.StepStatusFlushIntervalSeconds)*time.Second,
+		func(messageID string, payloadDoc messageContracts.SendReplyPayload) {
+			processSendReply(log, messageID, service, payloadDoc, stopPolicy)
+		})
+
 	// SendDocLevelResponse is used to send document level update
 	// Specify a new status of the document
 	sendDocLevelResponse := func(messageID string, resultStatus contracts.ResultStatus, documentTraceOutput string) {
 		payloadDoc := prepareReplyPayloadToUpdateDocumentStatus(agentInfo, resultStatus, documentTraceOutput)
-		processSendReply(log, messageID, service, payloadDoc, stopPolicy)
+		if resultStatus == contracts.ResultStatusInProgress {
+			// the first step replies supersede the InProgress reply when they complete within the flush interval
+			stepStatus.update(messageID, payloadDoc, false)
+			return
+		}
+		stepStatus.sendFinal(messageID, payloadDoc)
 	}
 
 	sendResponse := func(messageID string, res contracts.DocumentResult) {
 		pluginID := res.LastPlugin
-		processSendReply(log, messageID, service, FormatPayload(log, pluginID, agentInfo, res.PluginResults), stopPolicy)
+		payloadDoc := FormatPayload(log, pluginID, agentInfo, res.PluginResults)
+		if pluginID == "" {
+			stepStatus.sendFinal(messageID, payloadDoc)
+			return
+		}
+		// coalesce the step progress replies to avoid throttling on documents with many steps
+		stepStatus.update(
+			messageID,
+			withPendingSteps(payloadDoc, res.PluginResults, res.NPlugins),
+			isSignificantStepUpdate(pluginID, res.PluginResults, res.NPlugins))
 	}
 
 	var assocProc *associationProcessor.Processor
@@ -182,6 +226,9 @@ func NewService(ctx context.T, serviceName string, service mdsService.Service, c
 		assocProcessor:       assocProc,
 		pollAssociations:     pollAssoc,
 		processor:            processor,
+		stepStatus:           stepStatus,
+		processedMessages:    newProcessedMessages(filepath.Join(appconfig.DefaultDataStorePath, instanceID, appconfig.ProcessedMessagesRootDirName), processedMessageTTL),
+		intake:               newMessageIntake(),
 	}
 }
 
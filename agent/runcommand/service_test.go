@@ -16,11 +16,14 @@ package runcommand
 
 import (
 	"crypto/sha256"
+	"fmt"
 	"strings"
 	"testing"
 	"time"
 
 	"encoding/json"
+	"io/ioutil"
+	"os"
 	"path"
 
 	"github.com/aws/amazon-ssm-agent/agent/context"
@@ -31,6 +34,7 @@ import (
 	"github.com/aws/amazon-ssm-agent/agent/jsonutil"
 	"github.com/aws/amazon-ssm-agent/agent/log"
 	messageContracts "github.com/aws/amazon-ssm-agent/agent/runcommand/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/runcommand/deadletter"
 	runcommandmock "github.com/aws/amazon-ssm-agent/agent/runcommand/mock"
 	"github.com/aws/amazon-ssm-agent/agent/times"
 	"github.com/aws/aws-sdk-go/aws"
@@ -120,6 +124,32 @@ func TestProcessMessageWithCancelCommandTopicPrefix(t *testing.T) {
 	assert.True(t, *tc.IsDocLevelResponseSent)
 }
 
+// TestProcessMessageSavesDeadLetter tests processMessage keeps the messages whose document fails to parse
+func TestProcessMessageSavesDeadLetter(t *testing.T) {
+	svc, tc := prepareTestProcessMessage(testTopicSend)
+	svc.name = mdsName
+
+	loadDocStateFromSendCommand = func(context context.T,
+		msg *ssmmds.Message,
+		messagesOrchestrationRootDir string) (*contracts.DocumentState, error) {
+		return nil, fmt.Errorf("invalid document")
+	}
+	var saved *ssmmds.Message
+	addDeadLetter = func(log log.T, instanceID string, service string, msg *ssmmds.Message, parseErr error) error {
+		assert.Equal(t, mdsName, service)
+		assert.EqualError(t, parseErr, "invalid document")
+		saved = msg
+		return nil
+	}
+	defer func() { addDeadLetter = deadletter.Add }()
+
+	svc.processMessage(&tc.Message)
+
+	assert.Equal(t, &tc.Message, saved)
+	assert.True(t, *tc.IsDocLevelResponseSent)
+	tc.MdsMock.AssertNotCalled(t, "AcknowledgeMessage", mock.Anything, mock.Anything)
+}
+
 // TestProcessMessageWithInvalidCommandTopicPrefix tests processMessage with invalid topic prefix
 func TestProcessMessageWithInvalidCommandTopicPrefix(t *testing.T) {
 	// CancelCommand topic prefix
@@ -163,6 +193,33 @@ func TestProcessMessageWithInvalidMessage(t *testing.T) {
 	assert.False(t, *tc.IsDocLevelResponseSent)
 }
 
+// TestProcessMessageDeliveredAgain tests processMessage acknowledges a message delivered again without running it twice
+func TestProcessMessageDeliveredAgain(t *testing.T) {
+	var fakeDocState = contracts.DocumentState{
+		DocumentType: contracts.SendCommand,
+	}
+	svc, tc := prepareTestProcessMessage(testTopicSend)
+	dir, _ := ioutil.TempDir("", "processedmessages")
+	defer os.RemoveAll(dir)
+	svc.processedMessages = newProcessedMessages(dir, time.Hour)
+
+	tc.MdsMock.On("AcknowledgeMessage", mock.Anything, *tc.Message.MessageId).Return(nil)
+	loadDocStateFromSendCommand = func(context context.T,
+		msg *ssmmds.Message,
+		messagesOrchestrationRootDir string) (*contracts.DocumentState, error) {
+		return &fakeDocState, nil
+	}
+	tc.ProcessMock.On("Submit", fakeDocState).Return(nil)
+
+	svc.processMessage(&tc.Message)
+	// the agent restarted and the message is delivered again
+	svc.processedMessages = newProcessedMessages(dir, time.Hour)
+	svc.processMessage(&tc.Message)
+
+	tc.MdsMock.AssertNumberOfCalls(t, "AcknowledgeMessage", 2)
+	tc.ProcessMock.AssertNumberOfCalls(t, "Submit", 1)
+}
+
 func prepareTestProcessMessage(testTopic string) (svc RunCommandService, testCase TestCaseProcessMessage) {
 
 	// create mock context and log
@@ -0,0 +1,148 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package runcommand implements runcommand core processing module
+package runcommand
+
+import (
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	messageContracts "github.com/aws/amazon-ssm-agent/agent/runcommand/contracts"
+)
+
+// stepStatusBatcher coalesces the progress replies of the commands in progress, the document status and the steps
+// reported since the last flush are merged in a single reply per command sent when the flush interval elapses.
+// The final reply of a command is sent through the batcher too, so it is never followed by a progress reply.
+type stepStatusBatcher struct {
+	interval time.Duration
+	send     func(messageID string, payload messageContracts.SendReplyPayload)
+	lock     sync.Mutex
+	pending  map[string]messageContracts.SendReplyPayload
+	timer    *time.Timer
+	// sending counts the progress replies of each command handed over and not sent yet, guarded by lock
+	sending map[string]int
+	sent    *sync.Cond
+}
+
+// newStepStatusBatcher returns a stepStatusBatcher flushing at the given interval, a zero interval sends every reply immediately
+func newStepStatusBatcher(interval time.Duration, send func(messageID string, payload messageContracts.SendReplyPayload)) *stepStatusBatcher {
+	b := &stepStatusBatcher{
+		interval: interval,
+		send:     send,
+		pending:  make(map[string]messageContracts.SendReplyPayload),
+		sending:  make(map[string]int),
+	}
+	b.sent = sync.NewCond(&b.lock)
+	return b
+}
+
+// update queues the progress reply of the command, significant updates flush all pending replies right away
+func (b *stepStatusBatcher) update(messageID string, payload messageContracts.SendReplyPayload, significant bool) {
+	b.lock.Lock()
+	if queued, ok := b.pending[messageID]; ok && len(queued.RuntimeStatus) > 0 {
+		if payload.RuntimeStatus == nil {
+			payload.RuntimeStatus = make(map[string]*contracts.PluginRuntimeStatus)
+		}
+		// keep the status of the steps reported earlier, the reply only carries the steps that completed since
+		for pluginID, status := range queued.RuntimeStatus {
+			if _, found := payload.RuntimeStatus[pluginID]; !found {
+				payload.RuntimeStatus[pluginID] = status
+			}
+		}
+	}
+	b.pending[messageID] = payload
+	var replies map[string]messageContracts.SendReplyPayload
+	if significant || b.interval <= 0 {
+		replies = b.takePendingLocked()
+	} else if b.timer == nil {
+		b.timer = time.AfterFunc(b.interval, b.flush)
+	}
+	b.lock.Unlock()
+
+	b.sendAll(replies)
+}
+
+// sendFinal sends the terminal reply of the command, which supersedes its pending progress reply. The pending reply is
+// dropped under the lock and the progress reply being sent, if any, goes out first, so the terminal reply is the last one.
+func (b *stepStatusBatcher) sendFinal(messageID string, payload messageContracts.SendReplyPayload) {
+	b.lock.Lock()
+	delete(b.pending, messageID)
+	for b.sending[messageID] > 0 {
+		b.sent.Wait()
+	}
+	b.lock.Unlock()
+
+	b.send(messageID, payload)
+}
+
+// flush sends all pending replies to the service
+func (b *stepStatusBatcher) flush() {
+	b.lock.Lock()
+	replies := b.takePendingLocked()
+	b.lock.Unlock()
+
+	b.sendAll(replies)
+}
+
+// takePendingLocked stops the flush timer and hands over the pending replies, the caller holds the lock
+func (b *stepStatusBatcher) takePendingLocked() map[string]messageContracts.SendReplyPayload {
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	replies := b.pending
+	b.pending = make(map[string]messageContracts.SendReplyPayload)
+	for messageID := range replies {
+		b.sending[messageID]++
+	}
+	return replies
+}
+
+// sendAll sends the replies without holding the lock, so that slow service calls don't block the steps reporting their status
+func (b *stepStatusBatcher) sendAll(replies map[string]messageContracts.SendReplyPayload) {
+	for messageID, payload := range replies {
+		b.send(messageID, payload)
+
+		b.lock.Lock()
+		if b.sending[messageID]--; b.sending[messageID] <= 0 {
+			delete(b.sending, messageID)
+		}
+		b.sent.Broadcast()
+		b.lock.Unlock()
+	}
+}
+
+// isSignificantStepUpdate returns true when the step result should be reported without delay,
+// that is when the step did not succeed or it was the last step of the document
+func isSignificantStepUpdate(pluginID string, outputs map[string]*contracts.PluginResult, totalNumberOfPlugins int) bool {
+	if result, ok := outputs[pluginID]; ok {
+		if result.Status == contracts.ResultStatusFailed || result.Status == contracts.ResultStatusTimedOut {
+			return true
+		}
+	}
+	return len(outputs) >= totalNumberOfPlugins
+}
+
+// withPendingSteps counts the steps that didn't report a status yet as NotStarted in the status counts of the reply,
+// so that the progress of the command is known from any step reply
+func withPendingSteps(payload messageContracts.SendReplyPayload, outputs map[string]*contracts.PluginResult, totalNumberOfPlugins int) messageContracts.SendReplyPayload {
+	if notStarted := totalNumberOfPlugins - len(outputs); notStarted > 0 {
+		if payload.AdditionalInfo.RuntimeStatusCounts == nil {
+			payload.AdditionalInfo.RuntimeStatusCounts = make(map[string]int)
+		}
+		payload.AdditionalInfo.RuntimeStatusCounts[string(contracts.ResultStatusNotStarted)] += notStarted
+	}
+	return payload
+}
@@ -0,0 +1,198 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package runcommand implements runcommand core processing module
+package runcommand
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	messageContracts "github.com/aws/amazon-ssm-agent/agent/runcommand/contracts"
+	"github.com/stretchr/testify/assert"
+)
+
+type sentReply struct {
+	messageID string
+	payload   messageContracts.SendReplyPayload
+}
+
+func newTestStepStatusBatcher(interval time.Duration) (*stepStatusBatcher, chan sentReply) {
+	sent := make(chan sentReply, 10)
+	return newStepStatusBatcher(interval, func(messageID string, payload messageContracts.SendReplyPayload) {
+		sent <- sentReply{messageID: messageID, payload: payload}
+	}), sent
+}
+
+func stepReply(pluginID string, status contracts.ResultStatus) messageContracts.SendReplyPayload {
+	return messageContracts.SendReplyPayload{
+		DocumentStatus: contracts.ResultStatusInProgress,
+		RuntimeStatus: map[string]*contracts.PluginRuntimeStatus{
+			pluginID: {Status: status},
+		},
+	}
+}
+
+func TestStepStatusBatcherMergesSteps(t *testing.T) {
+	batcher, sent := newTestStepStatusBatcher(time.Hour)
+
+	batcher.update("messageID", stepReply("step1", contracts.ResultStatusSuccess), false)
+	batcher.update("messageID", stepReply("step2", contracts.ResultStatusSuccess), false)
+	assert.Len(t, sent, 0)
+
+	batcher.flush()
+	assert.Len(t, sent, 1)
+	reply := <-sent
+	assert.Equal(t, "messageID", reply.messageID)
+	assert.Len(t, reply.payload.RuntimeStatus, 2)
+}
+
+func TestStepStatusBatcherFlushesSignificantUpdates(t *testing.T) {
+	batcher, sent := newTestStepStatusBatcher(time.Hour)
+
+	batcher.update("messageID1", stepReply("step1", contracts.ResultStatusSuccess), false)
+	batcher.update("messageID2", stepReply("step1", contracts.ResultStatusFailed), true)
+
+	assert.Len(t, sent, 2)
+}
+
+func TestStepStatusBatcherFlushesOnInterval(t *testing.T) {
+	batcher, sent := newTestStepStatusBatcher(10 * time.Millisecond)
+
+	batcher.update("messageID", stepReply("step1", contracts.ResultStatusSuccess), false)
+
+	select {
+	case <-sent:
+	case <-time.After(time.Second):
+		assert.Fail(t, "pending reply was not flushed")
+	}
+}
+
+func TestStepStatusBatcherCoalescesDocumentStatus(t *testing.T) {
+	batcher, sent := newTestStepStatusBatcher(time.Hour)
+
+	batcher.update("messageID", prepareReplyPayloadToUpdateDocumentStatus(contracts.AgentInfo{}, contracts.ResultStatusInProgress, ""), false)
+	batcher.update("messageID", stepReply("step1", contracts.ResultStatusSuccess), false)
+	batcher.update("messageID", stepReply("step2", contracts.ResultStatusSuccess), false)
+	batcher.flush()
+
+	assert.Len(t, sent, 1)
+	reply := <-sent
+	assert.Equal(t, contracts.ResultStatusInProgress, reply.payload.DocumentStatus)
+	assert.Len(t, reply.payload.RuntimeStatus, 2)
+}
+
+func TestStepStatusBatcherKeepsStepsOfDocumentStatus(t *testing.T) {
+	batcher, sent := newTestStepStatusBatcher(time.Hour)
+
+	batcher.update("messageID", stepReply("step1", contracts.ResultStatusSuccess), false)
+	batcher.update("messageID", prepareReplyPayloadToUpdateDocumentStatus(contracts.AgentInfo{}, contracts.ResultStatusInProgress, ""), false)
+	batcher.flush()
+
+	reply := <-sent
+	assert.Len(t, reply.payload.RuntimeStatus, 1)
+}
+
+func TestStepStatusBatcherFinalReplyDropsPendingReply(t *testing.T) {
+	batcher, sent := newTestStepStatusBatcher(time.Hour)
+
+	batcher.update("messageID", stepReply("step1", contracts.ResultStatusSuccess), false)
+	batcher.sendFinal("messageID", messageContracts.SendReplyPayload{DocumentStatus: contracts.ResultStatusSuccess})
+	batcher.flush()
+
+	assert.Len(t, sent, 1)
+	assert.Equal(t, contracts.ResultStatusSuccess, (<-sent).payload.DocumentStatus)
+}
+
+func TestStepStatusBatcherFinalReplyAfterProgressBeingSent(t *testing.T) {
+	sending := make(chan bool)
+	release := make(chan bool)
+	sent := make(chan contracts.ResultStatus, 10)
+	batcher := newStepStatusBatcher(time.Hour, func(messageID string, payload messageContracts.SendReplyPayload) {
+		if payload.DocumentStatus == contracts.ResultStatusInProgress {
+			// the progress reply is slow to send
+			close(sending)
+			<-release
+		}
+		sent <- payload.DocumentStatus
+	})
+
+	batcher.update("messageID", stepReply("step1", contracts.ResultStatusSuccess), false)
+	go batcher.flush()
+	<-sending
+	done := make(chan bool)
+	go func() {
+		batcher.sendFinal("messageID", messageContracts.SendReplyPayload{DocumentStatus: contracts.ResultStatusSuccess})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		assert.Fail(t, "final reply sent while the progress reply was being sent")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+	<-done
+	assert.Equal(t, contracts.ResultStatusInProgress, <-sent)
+	assert.Equal(t, contracts.ResultStatusSuccess, <-sent)
+}
+
+func TestStepStatusBatcherSendsWithoutLock(t *testing.T) {
+	var batcher *stepStatusBatcher
+	sent := make(chan string, 10)
+	batcher = newStepStatusBatcher(time.Hour, func(messageID string, payload messageContracts.SendReplyPayload) {
+		// a step reporting while the reply is sent must not wait for the send to complete
+		if messageID == "messageID1" {
+			batcher.update("messageID2", stepReply("step1", contracts.ResultStatusSuccess), false)
+		}
+		sent <- messageID
+	})
+
+	done := make(chan bool)
+	go func() {
+		batcher.update("messageID1", stepReply("step1", contracts.ResultStatusFailed), true)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		assert.Fail(t, "update blocked while the reply was sent")
+	}
+	assert.Equal(t, "messageID1", <-sent)
+}
+
+func TestIsSignificantStepUpdate(t *testing.T) {
+	outputs := map[string]*contracts.PluginResult{
+		"step1": {Status: contracts.ResultStatusSuccess},
+		"step2": {Status: contracts.ResultStatusFailed},
+	}
+
+	assert.False(t, isSignificantStepUpdate("step1", outputs, 3))
+	assert.True(t, isSignificantStepUpdate("step2", outputs, 3))
+
+	outputs["step3"] = &contracts.PluginResult{Status: contracts.ResultStatusSuccess}
+	assert.True(t, isSignificantStepUpdate("step3", outputs, 3))
+}
+
+func TestWithPendingSteps(t *testing.T) {
+	outputs := map[string]*contracts.PluginResult{
+		"step1": {Status: contracts.ResultStatusSuccess},
+	}
+	payload := stepReply("step1", contracts.ResultStatusSuccess)
+	payload.AdditionalInfo.RuntimeStatusCounts = map[string]int{string(contracts.ResultStatusSuccess): 1}
+
+	payload = withPendingSteps(payload, outputs, 3)
+	assert.Equal(t, map[string]int{"Success": 1, "NotStarted": 2}, payload.AdditionalInfo.RuntimeStatusCounts)
+}
@@ -130,9 +130,18 @@ func generateCloudWatchLogStreamPrefix(commandID string) (string, error) {
 	return fmt.Sprintf("%s/%s", commandID, instanceID), nil
 }
 
-func generateCloudWatchConfigFromPayload(parsedMessage messageContracts.SendCommandPayload) (contracts.CloudWatchConfiguration, error) {
+// generateCloudWatchConfigFromPayload creates the cloudWatch output config of the command.
+// If the command doesn't enable cloudWatch output, the output is streamed to the log group configured for the agent, if any.
+func generateCloudWatchConfigFromPayload(parsedMessage messageContracts.SendCommandPayload, agentLogGroupName string) (contracts.CloudWatchConfiguration, error) {
 	cloudWatchOutputEnabled, err := strconv.ParseBool(parsedMessage.CloudWatchOutputEnabled)
 	cloudWatchConfig := contracts.CloudWatchConfiguration{}
+	if (err != nil || !cloudWatchOutputEnabled) && agentLogGroupName != "" {
+		if cloudWatchConfig.LogStreamPrefix, err = generateCloudWatchLogStreamPrefix(parsedMessage.CommandID); err != nil {
+			return contracts.CloudWatchConfiguration{}, err
+		}
+		cloudWatchConfig.LogGroupName = agentLogGroupName
+		return cloudWatchConfig, nil
+	}
 	if err != nil || !cloudWatchOutputEnabled {
 		return cloudWatchConfig, err
 	}
@@ -167,7 +176,7 @@ func parseSendCommandMessage(context context.T, msg *ssmmds.Message, messagesOrc
 	// adapt plugin configuration format from MDS to plugin expected format
 	s3KeyPrefix := path.Join(parsedMessage.OutputS3KeyPrefix, parsedMessage.CommandID, *msg.Destination)
 
-	cloudWatchConfig, err := generateCloudWatchConfigFromPayload(parsedMessage)
+	cloudWatchConfig, err := generateCloudWatchConfigFromPayload(parsedMessage, context.AppConfig().Ssm.PluginOutputCloudWatchLogGroup)
 	if err != nil {
 		log.Errorf("Encountered error while generating cloudWatch config from send command payload, err: %s", err)
 	}
@@ -191,11 +200,13 @@ func parseSendCommandMessage(context context.T, msg *ssmmds.Message, messagesOrc
 	}
 
 	docContent := &docparser.DocContent{
-		SchemaVersion: parsedMessage.DocumentContent.SchemaVersion,
-		Description:   parsedMessage.DocumentContent.Description,
-		RuntimeConfig: parsedMessage.DocumentContent.RuntimeConfig,
-		MainSteps:     parsedMessage.DocumentContent.MainSteps,
-		Parameters:    parsedMessage.DocumentContent.Parameters}
+		SchemaVersion:   parsedMessage.DocumentContent.SchemaVersion,
+		Description:     parsedMessage.DocumentContent.Description,
+		RuntimeConfig:   parsedMessage.DocumentContent.RuntimeConfig,
+		MainSteps:       parsedMessage.DocumentContent.MainSteps,
+		Parameters:      parsedMessage.DocumentContent.Parameters,
+		OutputS3Options: parsedMessage.DocumentContent.OutputS3Options,
+	}
 	//Data format persisted in Current Folder is defined by the struct - CommandState
 	docState, err := docparser.InitializeDocState(log, documentType, docContent, documentInfo, parserInfo, parsedMessage.Parameters)
 	if err != nil {
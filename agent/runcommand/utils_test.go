@@ -35,7 +35,7 @@ func TestGenerateCloudWatchConfigWithOutputEnabled(t *testing.T) {
 	expectedLogStreamName := fmt.Sprintf("%s/%s", testCommandID, testInstanceID)
 	mockParsedMessage := getSampleParsedMessage("", "true")
 
-	cloudWatchConfig, err := generateCloudWatchConfigFromPayload(mockParsedMessage)
+	cloudWatchConfig, err := generateCloudWatchConfigFromPayload(mockParsedMessage, "")
 	assert.Nil(t, err)
 	assert.Equal(t, expectedLogGroupName, cloudWatchConfig.LogGroupName)
 	assert.Equal(t, expectedLogStreamName, cloudWatchConfig.LogStreamPrefix)
@@ -47,7 +47,7 @@ func TestGenerateCloudWatchConfigWithLogGroupNameAndOutputEnabled(t *testing.T)
 	expectedLogGroupName := "myLogGroupName"
 	mockParsedMessage := getSampleParsedMessage(expectedLogGroupName, "true")
 
-	cloudWatchConfig, err := generateCloudWatchConfigFromPayload(mockParsedMessage)
+	cloudWatchConfig, err := generateCloudWatchConfigFromPayload(mockParsedMessage, "")
 	assert.Nil(t, err)
 	assert.Equal(t, expectedLogGroupName, cloudWatchConfig.LogGroupName)
 	assert.Equal(t, expectedLogStreamName, cloudWatchConfig.LogStreamPrefix)
@@ -55,21 +55,21 @@ func TestGenerateCloudWatchConfigWithLogGroupNameAndOutputEnabled(t *testing.T)
 
 func TestGenerateCloudWatchConfigWithOutputNotEnabled(t *testing.T) {
 	mockParsedMessage := getSampleParsedMessage("", "false")
-	cloudWatchConfig, err := generateCloudWatchConfigFromPayload(mockParsedMessage)
+	cloudWatchConfig, err := generateCloudWatchConfigFromPayload(mockParsedMessage, "")
 	assert.Nil(t, err)
 	assert.Equal(t, contracts.CloudWatchConfiguration{}, cloudWatchConfig)
 }
 
 func TestGenerateCloudWatchConfigWithLogGroupNameAndOutputNotEnabled(t *testing.T) {
 	mockParsedMessage := getSampleParsedMessage(testLogGroupName, "false")
-	cloudWatchConfig, err := generateCloudWatchConfigFromPayload(mockParsedMessage)
+	cloudWatchConfig, err := generateCloudWatchConfigFromPayload(mockParsedMessage, "")
 	assert.Nil(t, err)
 	assert.Equal(t, contracts.CloudWatchConfiguration{}, cloudWatchConfig)
 }
 
 func TestGenerateCloudWatchConfigWithEmptyCloudWatchConfigInPayload(t *testing.T) {
 	mockParsedMessage := getSampleParsedMessage("", "")
-	cloudWatchConfig, err := generateCloudWatchConfigFromPayload(mockParsedMessage)
+	cloudWatchConfig, err := generateCloudWatchConfigFromPayload(mockParsedMessage, "")
 	assert.Equal(t, contracts.CloudWatchConfiguration{}, cloudWatchConfig)
 	assert.NotNil(t, err)
 }
@@ -79,11 +79,27 @@ func TestGenerateCloudWatchConfigWithoutEmptyValuesInParsedMessage(t *testing.T)
 		CommandID:    testCommandID,
 		DocumentName: testDocumentName,
 	}
-	cloudWatchConfig, err := generateCloudWatchConfigFromPayload(emptyParsedMessage)
+	cloudWatchConfig, err := generateCloudWatchConfigFromPayload(emptyParsedMessage, "")
 	assert.Equal(t, contracts.CloudWatchConfiguration{}, cloudWatchConfig)
 	assert.NotNil(t, err)
 }
 
+func TestGenerateCloudWatchConfigWithAgentLogGroup(t *testing.T) {
+	systemInfo = &systemStub{}
+	expectedLogStreamName := fmt.Sprintf("%s/%s", testCommandID, testInstanceID)
+
+	for _, outputEnabled := range []string{"", "false"} {
+		cloudWatchConfig, err := generateCloudWatchConfigFromPayload(getSampleParsedMessage("", outputEnabled), "agentLogGroup")
+		assert.Nil(t, err)
+		assert.Equal(t, contracts.CloudWatchConfiguration{LogGroupName: "agentLogGroup", LogStreamPrefix: expectedLogStreamName}, cloudWatchConfig)
+	}
+
+	// the log group of the command takes precedence
+	cloudWatchConfig, err := generateCloudWatchConfigFromPayload(getSampleParsedMessage(testLogGroupName, "true"), "agentLogGroup")
+	assert.Nil(t, err)
+	assert.Equal(t, testLogGroupName, cloudWatchConfig.LogGroupName)
+}
+
 //getSampleParsedMessage returns a mocked SendCommandPayload
 func getSampleParsedMessage(logGroupName string, outputEnabled string) messageContracts.SendCommandPayload {
 
@@ -16,8 +16,21 @@
 // Package s3util contains methods for interacting with S3.
 package s3util
 
-import "github.com/aws/amazon-ssm-agent/agent/appconfig"
-import "github.com/aws/amazon-ssm-agent/agent/platform"
+import (
+	"strings"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/platform"
+)
+
+const defaultPartitionDomain = "amazonaws.com"
+
+// partitionDomains maps the region prefix of the partitions outside of the aws partition to their domain
+var partitionDomains = []struct{ regionPrefix, domain string }{
+	{"cn-", "amazonaws.com.cn"},
+	{"us-isob-", "sc2s.sgov.gov"},
+	{"us-iso-", "c2s.ic.gov"},
+}
 
 var awsS3EndpointMap = map[string]string{
 	//AUTOGEN_START
@@ -48,6 +61,9 @@ func GetS3Endpoint(region string) (s3Endpoint string) {
 		if appConfig.S3.Endpoint != "" {
 			return appConfig.S3.Endpoint
 		}
+		if preferredEndpoint := appconfig.GetPreferredEndPoint(region, "s3"); preferredEndpoint != "" {
+			return preferredEndpoint
+		}
 	}
 
 	if s3Endpoint, ok := awsS3EndpointMap[region]; ok {
@@ -59,18 +75,42 @@ func GetS3Endpoint(region string) (s3Endpoint string) {
 			return defaultEndpoint
 		}
 	}
+	if region != "" && getPartitionDomain(region) != defaultPartitionDomain {
+		return "s3." + region + "." + getPartitionDomain(region)
+	}
 	return "s3.amazonaws.com" // default global endpoint
 }
 
+// getPartitionDomain returns the domain of the endpoints of the partition the region is in
+func getPartitionDomain(region string) string {
+	for _, partition := range partitionDomains {
+		if strings.HasPrefix(region, partition.regionPrefix) {
+			return partition.domain
+		}
+	}
+	return defaultPartitionDomain
+}
+
+// getPartitionEndpoint returns the S3 endpoint of the region if it's outside of the aws partition, empty otherwise
+func getPartitionEndpoint(region string) string {
+	if domain := getPartitionDomain(region); domain != defaultPartitionDomain {
+		if s3Endpoint, ok := awsS3EndpointMap[region]; ok {
+			return s3Endpoint
+		}
+		return "s3." + region + "." + domain
+	}
+	return ""
+}
+
 /*
 This function will get the generic S3 endpoint for a certain region.
 Most regions will use us-east-1 endpoint except special ones
 */
 func GetS3GenericEndPoint(region string) (s3Endpoint string) {
-	if region == "us-gov-west-1" {
+	if strings.HasPrefix(region, "us-gov-") || strings.HasPrefix(region, "us-iso") {
 		return GetS3Endpoint(region) // Restricted regions
 	}
-	if region == "cn-north-1" {
+	if strings.HasPrefix(region, "cn-") {
 		return GetS3Endpoint("cn-north-1") // Use cn-north-1 for China
 	}
 	return GetS3Endpoint("us-east-1") // For all other regions, use us-east-1
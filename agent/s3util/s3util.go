@@ -17,14 +17,18 @@ package s3util
 import (
 	"errors"
 	"fmt"
+	"io"
 	"math"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
 	"github.com/aws/amazon-ssm-agent/agent/log"
 	"github.com/aws/amazon-ssm-agent/agent/platform"
 	"github.com/aws/amazon-ssm-agent/agent/sdkutil"
+	"github.com/aws/amazon-ssm-agent/agent/tracing"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
@@ -34,6 +38,11 @@ import (
 
 const (
 	s3ResponseRegionHeader = "x-amz-bucket-region"
+
+	// AclBucketOwnerFullControl gives the owner of the bucket full control of the output, the upload fails if it can't be set
+	AclBucketOwnerFullControl = s3.ObjectCannedACLBucketOwnerFullControl
+	// AclNone uploads the output without any ACL, for buckets that have ACLs disabled
+	AclNone = "none"
 )
 
 var getRegion = platform.Region
@@ -45,9 +54,16 @@ type IAmazonS3Util interface {
 
 type AmazonS3Util struct {
 	myUploader *s3manager.Uploader
+	options    contracts.S3OutputOptions
 }
 
 func NewAmazonS3Util(log log.T, bucketName string) *AmazonS3Util {
+	return NewAmazonS3UtilWithOptions(log, bucketName, contracts.S3OutputOptions{})
+}
+
+// NewAmazonS3UtilWithOptions creates an uploader encrypting the uploads with the KMS key and setting the ACL of the options,
+// the options that are not set are taken from the agent config.
+func NewAmazonS3UtilWithOptions(log log.T, bucketName string, options contracts.S3OutputOptions) *AmazonS3Util {
 
 	httpProvider := HttpProviderImpl{}
 	bucketRegion := GetBucketRegion(log, bucketName, httpProvider)
@@ -58,26 +74,78 @@ func NewAmazonS3Util(log log.T, bucketName string) *AmazonS3Util {
 	if errConfig != nil {
 		log.Error("failed to read appconfig.")
 	} else {
-		if appConfig.S3.Endpoint != "" {
-			config.Endpoint = &appConfig.S3.Endpoint
-		} else {
-			if region, err := platform.Region(); err == nil {
-				if defaultEndpoint := appconfig.GetDefaultEndPoint(region, "s3"); defaultEndpoint != "" {
-					config.Endpoint = &defaultEndpoint
-				}
-			} else {
-				log.Errorf("error fetching the region, %v", err)
+		options = withDefaultOptions(options, appConfig.S3)
+	}
+	options.Acl = normalizeAcl(log, options.Acl)
+
+	if options.Endpoint != "" {
+		config.Endpoint = &options.Endpoint
+	} else if preferredEndpoint := appconfig.GetPreferredEndPoint(bucketRegion, "s3"); preferredEndpoint != "" {
+		config.Endpoint = &preferredEndpoint
+	} else if endpoint := getPartitionEndpoint(bucketRegion); endpoint != "" {
+		// the sdk doesn't know the endpoints of all the partitions
+		config.Endpoint = &endpoint
+	} else if errConfig == nil {
+		if region, err := platform.Region(); err == nil {
+			if defaultEndpoint := appconfig.GetDefaultEndPoint(region, "s3"); defaultEndpoint != "" {
+				config.Endpoint = &defaultEndpoint
 			}
+		} else {
+			log.Errorf("error fetching the region, %v", err)
 		}
 	}
 	config.Region = &bucketRegion
 
 	sess := session.New(config)
 	sess.Handlers.Build.PushBack(request.MakeAddToUserAgentHandler(appConfig.Agent.Name, appConfig.Agent.Version))
+	tracing.TraceRequests(&sess.Handlers)
 
 	return &AmazonS3Util{
 		myUploader: s3manager.NewUploader(sess),
+		options:    options,
+	}
+}
+
+// withDefaultOptions fills the options that are not set with the agent config
+func withDefaultOptions(options contracts.S3OutputOptions, config appconfig.S3Cfg) contracts.S3OutputOptions {
+	if options.KmsKeyArn == "" {
+		options.KmsKeyArn = config.OutputKmsKeyArn
+	}
+	if options.Acl == "" {
+		options.Acl = config.OutputAcl
+	}
+	if options.Endpoint == "" {
+		options.Endpoint = config.Endpoint
 	}
+	return options
+}
+
+// normalizeAcl returns the supported ACL, unsupported ones are ignored
+func normalizeAcl(log log.T, acl string) string {
+	switch acl = strings.ToLower(strings.TrimSpace(acl)); acl {
+	case "", AclBucketOwnerFullControl, AclNone:
+		return acl
+	}
+	log.Warnf("ACL %v is not supported for the output, supported ACLs are %v and %v", acl, AclBucketOwnerFullControl, AclNone)
+	return ""
+}
+
+// uploadInput creates the upload request of the object with the encryption and ACL of the options
+func uploadInput(bucketName string, objectKey string, body io.Reader, options contracts.S3OutputOptions) *s3manager.UploadInput {
+	params := &s3manager.UploadInput{
+		Bucket:      aws.String(bucketName),
+		Key:         aws.String(objectKey),
+		Body:        body,
+		ContentType: aws.String("text/plain"),
+	}
+	if options.KmsKeyArn != "" {
+		params.ServerSideEncryption = aws.String(s3.ServerSideEncryptionAwsKms)
+		params.SSEKMSKeyId = aws.String(options.KmsKeyArn)
+	}
+	if options.Acl == AclBucketOwnerFullControl {
+		params.ACL = aws.String(s3.ObjectCannedACLBucketOwnerFullControl)
+	}
+	return params
 }
 
 // S3Upload uploads a file to s3.
@@ -90,14 +158,13 @@ func (u *AmazonS3Util) S3Upload(log log.T, bucketName string, objectKey string,
 	defer file.Close()
 
 	log.Infof("Uploading %v to s3://%v/%v", filePath, bucketName, objectKey)
-	params := &s3manager.UploadInput{
-		Bucket:      aws.String(bucketName),
-		Key:         aws.String(objectKey),
-		Body:        file,
-		ContentType: aws.String("text/plain"),
-	}
+	params := uploadInput(bucketName, objectKey, file, u.options)
 	if result, err := u.myUploader.Upload(params); err == nil {
 		log.Infof("Successfully uploaded file to ", result.Location)
+		if u.options.Acl != "" {
+			// the ACL is set with the upload or not at all
+			return nil
+		}
 		if _, aclErr := u.myUploader.S3.PutObjectAcl(&s3.PutObjectAclInput{
 			Bucket: aws.String(bucketName),
 			Key:    aws.String(objectKey),
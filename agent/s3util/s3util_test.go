@@ -6,6 +6,11 @@ import (
 
 	"errors"
 
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -105,6 +110,52 @@ func TestGetRegionFromS3URLWithExponentialBackoff_HeadReturns503s(t *testing.T)
 	mockHttpProvider.AssertExpectations(t)
 }
 
+func TestUploadInput(t *testing.T) {
+	params := uploadInput("bucket", "prefix/stdout", nil, contracts.S3OutputOptions{})
+	assert.Equal(t, "bucket", aws.StringValue(params.Bucket))
+	assert.Equal(t, "prefix/stdout", aws.StringValue(params.Key))
+	assert.Nil(t, params.ServerSideEncryption)
+	assert.Nil(t, params.SSEKMSKeyId)
+	assert.Nil(t, params.ACL)
+
+	keyArn := "arn:aws-cn:kms:cn-north-1:123456789012:key/1234abcd-12ab-34cd-56ef-1234567890ab"
+	params = uploadInput("bucket", "prefix/stdout", nil, contracts.S3OutputOptions{KmsKeyArn: keyArn, Acl: AclBucketOwnerFullControl})
+	assert.Equal(t, s3.ServerSideEncryptionAwsKms, aws.StringValue(params.ServerSideEncryption))
+	assert.Equal(t, keyArn, aws.StringValue(params.SSEKMSKeyId))
+	assert.Equal(t, s3.ObjectCannedACLBucketOwnerFullControl, aws.StringValue(params.ACL))
+
+	params = uploadInput("bucket", "prefix/stdout", nil, contracts.S3OutputOptions{Acl: AclNone})
+	assert.Nil(t, params.ACL)
+}
+
+func TestWithDefaultOptions(t *testing.T) {
+	config := appconfig.S3Cfg{Endpoint: "s3.example.com", OutputKmsKeyArn: "configuredKey", OutputAcl: AclNone}
+
+	assert.Equal(t, contracts.S3OutputOptions{KmsKeyArn: "configuredKey", Acl: AclNone, Endpoint: "s3.example.com"},
+		withDefaultOptions(contracts.S3OutputOptions{}, config))
+
+	// the options of the document take precedence
+	options := contracts.S3OutputOptions{KmsKeyArn: "documentKey", Acl: AclBucketOwnerFullControl, Endpoint: "s3.cn-north-1.amazonaws.com.cn"}
+	assert.Equal(t, options, withDefaultOptions(options, config))
+}
+
+func TestNormalizeAcl(t *testing.T) {
+	logger := log.NewMockLog()
+	assert.Equal(t, "", normalizeAcl(logger, ""))
+	assert.Equal(t, AclBucketOwnerFullControl, normalizeAcl(logger, " Bucket-Owner-Full-Control "))
+	assert.Equal(t, AclNone, normalizeAcl(logger, "NONE"))
+	assert.Equal(t, "", normalizeAcl(logger, "public-read"))
+}
+
+func TestGetPartitionEndpoint(t *testing.T) {
+	assert.Equal(t, "", getPartitionEndpoint("us-east-1"))
+	assert.Equal(t, "", getPartitionEndpoint("us-gov-west-1"))
+	assert.Equal(t, "s3.cn-north-1.amazonaws.com.cn", getPartitionEndpoint("cn-north-1"))
+	assert.Equal(t, "s3.cn-northwest-1.amazonaws.com.cn", getPartitionEndpoint("cn-northwest-1"))
+	assert.Equal(t, "s3.us-iso-east-1.c2s.ic.gov", getPartitionEndpoint("us-iso-east-1"))
+	assert.Equal(t, "s3.us-isob-east-1.sc2s.sgov.gov", getPartitionEndpoint("us-isob-east-1"))
+}
+
 type MockedHttpProvider struct {
 	mock.Mock
 }
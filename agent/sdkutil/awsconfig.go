@@ -24,6 +24,8 @@ import (
 	"github.com/aws/amazon-ssm-agent/agent/sdkutil/retryer"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/ec2rolecreds"
 )
 
 // AwsConfig returns the default aws.Config object while the appropriate
@@ -55,6 +57,16 @@ func AwsConfig() (awsConfig *aws.Config) {
 		creds, _ := appConfig.ProfileCredentials()
 		if creds != nil {
 			awsConfig.Credentials = creds
+		} else if appConfig.Agent.Ec2MetadataEndpointMode == appconfig.Ec2MetadataEndpointModeIPv6 {
+			// the default credential chain reaches the instance role credentials over IPv4 only
+			awsConfig.Credentials = credentials.NewChainCredentials([]credentials.Provider{
+				&credentials.EnvProvider{},
+				&credentials.SharedCredentialsProvider{},
+				&ec2rolecreds.EC2RoleProvider{
+					Client:       platform.NewEC2MetadataService(aws.NewConfig()),
+					ExpiryWindow: 5 * time.Minute,
+				},
+			})
 		}
 	}
 
@@ -15,11 +15,13 @@
 package sdkutil
 
 import (
+	"net/http"
 	"runtime"
 	"strings"
 
 	"github.com/aws/amazon-ssm-agent/agent/log"
 	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
 )
 
 // HandleAwsError logs an AWS error.
@@ -67,6 +69,23 @@ func GetAwsErrorCode(err error) string {
 	return errorCode
 }
 
+// IsRetryableError returns true if the call failed with an error that may go away when the call is retried,
+// that is throttling, a server error or a network error. The other errors returned by the service, like a
+// validation error or an invalid instance id, fail the same way on every retry.
+func IsRetryableError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if request.IsErrorThrottle(err) || request.IsErrorRetryable(err) {
+		return true
+	}
+	if reqErr, ok := err.(awserr.RequestFailure); ok && reqErr.StatusCode() != 0 {
+		return reqErr.StatusCode() >= http.StatusInternalServerError || reqErr.StatusCode() == http.StatusTooManyRequests
+	}
+	// the request didn't get a response from the service
+	return true
+}
+
 // resetStopPolicy will reset the stoppolicy error count
 func resetStopPolicy(stopPolicy *StopPolicy) {
 	if stopPolicy != nil {
@@ -18,6 +18,7 @@ import (
 	"testing"
 
 	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -49,3 +50,14 @@ func TestHandleAwsErrorCount(t *testing.T) {
 	}
 
 }
+
+func TestIsRetryableError(t *testing.T) {
+	assert.False(t, IsRetryableError(nil))
+	assert.True(t, IsRetryableError(errSample))
+	assert.True(t, IsRetryableError(awserr.New("RequestError", "send request failed", errSample)))
+	assert.True(t, IsRetryableError(awserr.NewRequestFailure(awserr.New("ThrottlingException", "Rate exceeded", nil), 400, "requestID")))
+	assert.True(t, IsRetryableError(awserr.NewRequestFailure(awserr.New("InternalServerError", "", nil), 500, "requestID")))
+	assert.True(t, IsRetryableError(awserr.NewRequestFailure(awserr.New("ServiceUnavailable", "", nil), 503, "requestID")))
+	assert.False(t, IsRetryableError(awserr.NewRequestFailure(awserr.New("ValidationException", "", nil), 400, "requestID")))
+	assert.False(t, IsRetryableError(awserr.NewRequestFailure(awserr.New("InvalidInstanceId", "", nil), 400, "requestID")))
+}
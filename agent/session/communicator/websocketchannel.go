@@ -66,6 +66,7 @@ type WebSocketChannel struct {
 	Region       string
 	IsOpen       bool
 	writeLock    *sync.Mutex
+	pingInterval time.Duration
 }
 
 // Initialize a WebSocketChannel object.
@@ -110,6 +111,10 @@ func (webSocketChannel *WebSocketChannel) Initialize(context context.T,
 	webSocketChannel.ChannelToken = channelToken
 	webSocketChannel.OnError = onErrorHandler
 	webSocketChannel.OnMessage = onMessageHandler
+	webSocketChannel.pingInterval = mgsconfig.WebSocketPingInterval
+	if channelType == mgsconfig.DataChannel {
+		webSocketChannel.pingInterval = time.Duration(context.AppConfig().Mgs.DataChannelPingIntervalSeconds) * time.Second
+	}
 
 	return nil
 }
@@ -163,7 +168,11 @@ func (webSocketChannel *WebSocketChannel) Open(log log.T) error {
 
 	webSocketChannel.Connection = ws
 	webSocketChannel.IsOpen = true
-	webSocketChannel.StartPings(log, mgsconfig.WebSocketPingInterval)
+	pingInterval := webSocketChannel.pingInterval
+	if pingInterval <= 0 {
+		pingInterval = mgsconfig.WebSocketPingInterval
+	}
+	webSocketChannel.StartPings(log, pingInterval)
 
 	// spin up a different routine to listen to the incoming traffic
 	go func() {
@@ -59,9 +59,7 @@ const (
 	ControlChannelRetryInitialDelayMillis = 5000
 	ControlChannelRetryMaxIntervalMillis  = 1000 * 60 * 60 // 1 hour
 
-	DataChannelNumMaxAttempts          = 5
-	DataChannelRetryInitialDelayMillis = 100
-	DataChannelRetryMaxIntervalMillis  = 5000
+	DataChannelNumMaxAttempts = 5
 
 	IpcFileName      = "ipcTempFile"
 	LogFileExtension = ".log"
@@ -295,6 +295,7 @@ func (agentMessage *AgentMessage) ParseAgentMessage(context context.T,
 		Inputs:          parsedMessagePayload.DocumentContent.Inputs,
 		Parameters:      parsedMessagePayload.DocumentContent.Parameters,
 		SessionCommands: parsedMessagePayload.DocumentContent.SessionCommands,
+		Properties:      parsedMessagePayload.DocumentContent.Properties,
 	}
 
 	docState, err := docparser.InitializeDocState(
@@ -351,6 +352,8 @@ func buildDocumentInfo(
 		RunID:          times.ToIsoDashUTC(times.DefaultClock.Now()),
 		DocumentName:   parsedMessagePayload.DocumentName,
 		DocumentStatus: contracts.ResultStatusInProgress,
+		RunAsUser:      parsedMessagePayload.RunAsUser,
+		SessionOwner:   parsedMessagePayload.SessionOwner,
 	}
 }
 
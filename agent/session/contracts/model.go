@@ -17,6 +17,7 @@ package contracts
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/aws/amazon-ssm-agent/agent/contracts"
@@ -44,6 +45,10 @@ const (
 	PausePublicationMessage string = "pause_publication"
 	// StartPublicationMessage message type for start sending data packages.
 	StartPublicationMessage string = "start_publication"
+	// AgentJobMessage represents message type for a run command document delivered over the control channel
+	AgentJobMessage string = "agent_job"
+	// AgentJobAcknowledgeMessage represents message type for the acknowledgement of an agent job
+	AgentJobAcknowledgeMessage string = "agent_job_ack"
 )
 
 type IMessage interface {
@@ -93,6 +98,10 @@ type AgentTaskPayload struct {
 	DocumentContent contracts.SessionDocumentContent `json:"DocumentContent"`
 	SessionId       string                           `json:"SessionId"`
 	Parameters      map[string]interface{}           `json:"Parameters"`
+	// RunAsUser is the value of the SSMSessionRunAs tag of the IAM principal starting the session, set by the service
+	RunAsUser string `json:"RunAsUser"`
+	// SessionOwner is the ARN of the IAM principal starting the session, set by the service
+	SessionOwner string `json:"SessionOwner"`
 }
 
 // AcknowledgeContent is used to inform the sender of an acknowledge message that the message has been received.
@@ -112,6 +121,16 @@ type AgentSessionStateContent struct {
 	SchemaVersion int    `json:"SchemaVersion"`
 	SessionState  string `json:"SessionState"`
 	SessionId     string `json:"SessionId"`
+	// Reason tells why the agent terminates the session, e.g. because it was idle
+	Reason string `json:"Reason,omitempty"`
+}
+
+// SessionUser returns the user who started the session, the session ids are the user name followed by a unique suffix
+func SessionUser(sessionId string) string {
+	if i := strings.LastIndex(sessionId, "-"); i > 0 {
+		return sessionId[:i]
+	}
+	return sessionId
 }
 
 // Deserialize parses AcknowledgeContent message from payload of AgentMessage.
@@ -174,6 +193,50 @@ func (channelClose *ChannelClosed) Serialize(log logger.T) (result []byte, err e
 	return
 }
 
+// AgentJobPayload parallels the structure of an MDS message, it carries a run command document delivered over the control channel.
+// * JobId is the MDS message id of the document.
+// * Topic is the MDS topic of the message, such as aws.ssm.sendCommand.
+// * Payload is the MDS payload of the message.
+// * CreatedDate is the MDS creation date of the message.
+type AgentJobPayload struct {
+	JobId         string `json:"JobId"`
+	Topic         string `json:"Topic"`
+	Payload       string `json:"Content"`
+	CreatedDate   string `json:"CreatedDate"`
+	SchemaVersion int    `json:"SchemaVersion"`
+}
+
+// AgentJobAck is sent by the agent to inform the service the agent job was received.
+type AgentJobAck struct {
+	JobId        string `json:"JobId"`
+	MessageId    string `json:"AcknowledgedMessageId"`
+	CreatedDate  string `json:"CreatedDate"`
+	StatusCode   string `json:"StatusCode"`
+	ErrorMessage string `json:"ErrorMessage"`
+}
+
+// Deserialize parses AgentJobPayload message from payload of AgentMessage.
+func (agentJob *AgentJobPayload) Deserialize(log logger.T, agentMessage AgentMessage) (err error) {
+	if agentMessage.MessageType != AgentJobMessage {
+		err = fmt.Errorf("AgentMessage is not of type AgentJob. Found message type: %s", agentMessage.MessageType)
+		return
+	}
+
+	if err = json.Unmarshal(agentMessage.Payload, agentJob); err != nil {
+		log.Errorf("Could not deserialize rawMessage to AgentJob: %s", err)
+	}
+	return
+}
+
+// Serialize marshals AgentJobAck as payloads into bytes.
+func (agentJobAck *AgentJobAck) Serialize(log logger.T) (result []byte, err error) {
+	result, err = json.Marshal(agentJobAck)
+	if err != nil {
+		log.Errorf("Could not serialize AgentJobAck message: %v, err: %s", agentJobAck, err)
+	}
+	return
+}
+
 // AgentTaskCompletePayload is sent by the agent to inform the task is complete and what the overall result was.
 type AgentTaskCompletePayload struct {
 	SchemaVersion    int    `json:"SchemaVersion"`
@@ -211,6 +274,19 @@ const (
 	HandshakeComplete    PayloadType = 7
 	EncChallengeRequest  PayloadType = 8
 	EncChallengeResponse PayloadType = 9
+	Flag                 PayloadType = 10
+	// ExitCode carries the exit code of the command of the InteractiveCommands sessions, 4 bytes big endian
+	ExitCode PayloadType = 11
+	// FileTransfer carries the FileTransferMessage of the file transfers multiplexed with the data of the shell sessions
+	FileTransfer PayloadType = 12
+)
+
+// PayloadTypeFlag is the control flag carried by the payload of the stream data messages of type Flag
+type PayloadTypeFlag uint32
+
+const (
+	// DisconnectToPort is sent by the client once it has no more data to send, the agent half-closes the connection
+	DisconnectToPort PayloadTypeFlag = 1
 )
 
 type SessionStatus string
@@ -220,6 +296,40 @@ const (
 	Terminating SessionStatus = "Terminating"
 )
 
+// FileTransferAction is the step of a file transfer a FileTransferMessage carries
+type FileTransferAction string
+
+const (
+	// FileTransferUpload starts or resumes the upload of a file by the client, with its path, size and sha256
+	FileTransferUpload FileTransferAction = "upload"
+	// FileTransferDownload starts the download of a file by the client, with its path and the offset to resume from
+	FileTransferDownload FileTransferAction = "download"
+	// FileTransferReady tells the upload goes on from the offset, or the size and sha256 of the downloaded file
+	FileTransferReady FileTransferAction = "ready"
+	// FileTransferChunk carries the data of the file at the offset
+	FileTransferChunk FileTransferAction = "chunk"
+	// FileTransferComplete is sent by the client once it sent the last chunk of an upload
+	FileTransferComplete FileTransferAction = "complete"
+	// FileTransferCompleted tells the file was transferred, with its sha256
+	FileTransferCompleted FileTransferAction = "completed"
+	// FileTransferCancel stops the transfer, the uploads can be resumed later
+	FileTransferCancel FileTransferAction = "cancel"
+	// FileTransferError tells the transfer failed
+	FileTransferError FileTransferAction = "error"
+)
+
+// FileTransferMessage is the payload of the stream data messages of type FileTransfer
+type FileTransferMessage struct {
+	Action     FileTransferAction `json:"action"`
+	TransferId string             `json:"transferId"`
+	Path       string             `json:"path,omitempty"`
+	Offset     int64              `json:"offset"`
+	Size       int64              `json:"size"`
+	Sha256     string             `json:"sha256,omitempty"`
+	Data       []byte             `json:"data,omitempty"`
+	Error      string             `json:"error,omitempty"`
+}
+
 type SizeData struct {
 	Cols uint32 `json:"cols"`
 	Rows uint32 `json:"rows"`
@@ -91,3 +91,9 @@ func TestDeserializeAgentMessageWithChannelClosed(t *testing.T) {
 	assert.Equal(t, sessionId, deserializedChannelClosed.SessionId)
 	assert.Equal(t, "destination-id", deserializedChannelClosed.DestinationId)
 }
+
+func TestSessionUser(t *testing.T) {
+	assert.Equal(t, "alice", SessionUser("alice-0a1b2c3d4e5f67890"))
+	assert.Equal(t, "role-session", SessionUser("role-session-0a1b2c3d4e5f67890"))
+	assert.Equal(t, "session", SessionUser("session"))
+}
@@ -19,12 +19,15 @@ import (
 	"fmt"
 	"math/rand"
 	"path/filepath"
+	"time"
 
 	"github.com/aws/amazon-ssm-agent/agent/appconfig"
 	"github.com/aws/amazon-ssm-agent/agent/context"
 	"github.com/aws/amazon-ssm-agent/agent/contracts"
 	"github.com/aws/amazon-ssm-agent/agent/framework/processor"
 	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/metrics"
+	"github.com/aws/amazon-ssm-agent/agent/runcommand/mgsjob"
 	"github.com/aws/amazon-ssm-agent/agent/session/communicator"
 	mgsConfig "github.com/aws/amazon-ssm-agent/agent/session/config"
 	mgsContracts "github.com/aws/amazon-ssm-agent/agent/session/contracts"
@@ -33,10 +36,18 @@ import (
 	"github.com/aws/amazon-ssm-agent/agent/times"
 	"github.com/aws/amazon-ssm-agent/agent/version"
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssmmds"
 	"github.com/gorilla/websocket"
 	"github.com/twinj/uuid"
 )
 
+const (
+	agentJobSchemaVersion = 1
+	agentJobMessageFlags  = 3
+	// agentJobStatusSuccessful is the status of an agent job the run command service accepted
+	agentJobStatusSuccessful = "Successful"
+)
+
 type IControlChannel interface {
 	Initialize(context context.T, mgsService service.Service, processor processor.Processor, instanceId string)
 	SetWebSocket(context context.T, mgsService service.Service, processor processor.Processor, instanceId string) error
@@ -92,7 +103,7 @@ func (controlChannel *ControlChannel) SetWebSocket(context context.T,
 	config := context.AppConfig()
 	orchestrationRootDir := filepath.Join(appconfig.DefaultDataStorePath, instanceId, appconfig.DefaultSessionRootDirName, config.Agent.OrchestrationRootDir)
 	onMessageHandler := func(input []byte) {
-		controlChannelIncomingMessageHandler(context, processor, input, orchestrationRootDir, instanceId)
+		controlChannelIncomingMessageHandler(context, processor, controlChannel, input, orchestrationRootDir, instanceId)
 	}
 	onErrorHandler := func(err error) {
 		callable := func() (channel interface{}, err error) {
@@ -188,12 +199,17 @@ func (controlChannel *ControlChannel) Open(log log.T) error {
 		return fmt.Errorf("error serializing openControlChannelInput: %s", err)
 	}
 
-	return controlChannel.SendMessage(log, jsonValue, websocket.TextMessage)
+	if err = controlChannel.SendMessage(log, jsonValue, websocket.TextMessage); err != nil {
+		return err
+	}
+	metrics.RecordContact(metrics.ChannelMgs)
+	return nil
 }
 
 // controlChannelIncomingMessageHandler handles the incoming messages coming to the agent.
 func controlChannelIncomingMessageHandler(context context.T,
 	processor processor.Processor,
+	controlChannel IControlChannel,
 	rawMessage []byte,
 	orchestrationRootDir string,
 	instanceId string) error {
@@ -210,6 +226,7 @@ func controlChannelIncomingMessageHandler(context context.T,
 		log.Debugf("Invalid AgentMessage: %s, err: %v.", agentMessage.MessageId, err)
 		return err
 	}
+	metrics.RecordContact(metrics.ChannelMgs)
 
 	if agentMessage.MessageType == mgsContracts.InteractiveShellMessage {
 		uuid.SwitchFormat(uuid.CleanHyphen)
@@ -217,6 +234,8 @@ func controlChannelIncomingMessageHandler(context context.T,
 		return sendStartSessionMessageToProcessor(processor, context, agentMessage, orchestrationRootDir, instanceId, clientId)
 	} else if agentMessage.MessageType == mgsContracts.ChannelClosedMessage {
 		return sendTerminateSessionMessageToProcessor(processor, context, instanceId, *agentMessage)
+	} else if agentMessage.MessageType == mgsContracts.AgentJobMessage {
+		return sendAgentJobToRunCommand(context, controlChannel, instanceId, *agentMessage)
 	}
 
 	return fmt.Errorf("invalid message type: %s", agentMessage.MessageType)
@@ -290,6 +309,73 @@ func sendTerminateSessionMessageToProcessor(
 	return nil
 }
 
+// sendAgentJobToRunCommand hands a run command document to the run command service and acknowledges the job.
+// The job isn't acknowledged when run command doesn't receive documents over the control channel, MDS delivers it instead.
+func sendAgentJobToRunCommand(
+	context context.T,
+	controlChannel IControlChannel,
+	instanceId string,
+	agentMessage mgsContracts.AgentMessage) error {
+
+	log := context.Log()
+	log.Debugf("Processing AgentJob message %s", agentMessage.MessageId.String())
+
+	agentJob := &mgsContracts.AgentJobPayload{}
+	if err := agentJob.Deserialize(log, agentMessage); err != nil {
+		log.Errorf("Cannot parse AgentJob message: %s, err: %v.", agentMessage.MessageId, err)
+		return err
+	}
+
+	message := &ssmmds.Message{
+		MessageId:   aws.String(agentJob.JobId),
+		Topic:       aws.String(agentJob.Topic),
+		Payload:     aws.String(agentJob.Payload),
+		CreatedDate: aws.String(agentJob.CreatedDate),
+		Destination: aws.String(instanceId),
+	}
+	if !mgsjob.Deliver(message) {
+		log.Debugf("Run command doesn't receive documents over the controlchannel, job %s is left to MDS", agentJob.JobId)
+		return nil
+	}
+
+	agentJobAck := &mgsContracts.AgentJobAck{
+		JobId:       agentJob.JobId,
+		MessageId:   agentMessage.MessageId.String(),
+		CreatedDate: times.ToIso8601UTC(time.Now()),
+		StatusCode:  agentJobStatusSuccessful,
+	}
+	agentJobAckBytes, err := agentJobAck.Serialize(log)
+	if err != nil {
+		return err
+	}
+
+	uuid.SwitchFormat(uuid.CleanHyphen)
+	ackMessage := &mgsContracts.AgentMessage{
+		MessageType:    mgsContracts.AgentJobAcknowledgeMessage,
+		SchemaVersion:  agentJobSchemaVersion,
+		CreatedDate:    uint64(time.Now().UnixNano() / 1000000),
+		SequenceNumber: 0,
+		Flags:          agentJobMessageFlags,
+		MessageId:      uuid.NewV4(),
+		Payload:        agentJobAckBytes,
+	}
+	msg, err := ackMessage.Serialize(log)
+	if err != nil {
+		log.Errorf("Cannot serialize AgentJobAck message err: %v", err)
+		return err
+	}
+
+	log.Debugf("Send %s message for job %s", mgsContracts.AgentJobAcknowledgeMessage, agentJob.JobId)
+	start := time.Now()
+	if err = controlChannel.SendMessage(log, msg, websocket.BinaryMessage); err != nil {
+		metrics.Increment(metrics.ChannelMgs, metrics.EventFailed)
+		return err
+	}
+	metrics.ObserveLatency(metrics.ChannelMgs, mgsContracts.AgentJobAcknowledgeMessage, time.Since(start))
+	metrics.Increment(metrics.ChannelMgs, metrics.EventAcked)
+	return nil
+}
+
 // getControlChannelToken calls CreateControlChannel to get the token for this instance
 func getControlChannelToken(log log.T,
 	mgsService service.Service,
@@ -21,6 +21,7 @@ import (
 	"github.com/aws/amazon-ssm-agent/agent/context"
 	processorMock "github.com/aws/amazon-ssm-agent/agent/framework/processor/mock"
 	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/runcommand/mgsjob"
 	communicatorMocks "github.com/aws/amazon-ssm-agent/agent/session/communicator/mocks"
 	mgsConfig "github.com/aws/amazon-ssm-agent/agent/session/config"
 	mgsContracts "github.com/aws/amazon-ssm-agent/agent/session/contracts"
@@ -28,6 +29,8 @@ import (
 	serviceMock "github.com/aws/amazon-ssm-agent/agent/session/service/mocks"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	v4 "github.com/aws/aws-sdk-go/aws/signer/v4"
+	"github.com/aws/aws-sdk-go/service/ssmmds"
+	"github.com/gorilla/websocket"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/twinj/uuid"
@@ -163,7 +166,7 @@ func TestControlChannelIncomingMessageHandlerForStartSessionMessage(t *testing.T
 	serializedBytes, _ := agentMessage.Serialize(log.NewMockLog())
 	mockProcessor.On("Submit", mock.Anything).Return(nil)
 
-	err := controlChannelIncomingMessageHandler(mockContext, mockProcessor, serializedBytes, "", "")
+	err := controlChannelIncomingMessageHandler(mockContext, mockProcessor, nil, serializedBytes, "", "")
 
 	assert.Nil(t, err)
 	mockProcessor.AssertExpectations(t)
@@ -188,12 +191,70 @@ func TestControlChannelIncomingMessageHandlerForTerminateSessionMessage(t *testi
 	serializedBytes, _ := agentMessage.Serialize(log.NewMockLog())
 	mockProcessor.On("Cancel", mock.Anything).Return(nil)
 
-	err := controlChannelIncomingMessageHandler(mockContext, mockProcessor, serializedBytes, "", "")
+	err := controlChannelIncomingMessageHandler(mockContext, mockProcessor, nil, serializedBytes, "", "")
 
 	assert.Nil(t, err)
 	mockProcessor.AssertExpectations(t)
 }
 
+func TestControlChannelIncomingMessageHandlerForAgentJobMessage(t *testing.T) {
+	u, _ := uuid.Parse(messageId)
+	agentJob := mgsContracts.AgentJobPayload{
+		JobId:         "aws.ssm.2b196342-d7d4-436e-8f09-3883a1116ac3.i-1234",
+		Topic:         "aws.ssm.sendCommand.test",
+		Payload:       "payload",
+		CreatedDate:   "2017-08-22T20:37:54.948Z",
+		SchemaVersion: 1,
+	}
+	agentJobJson, _ := json.Marshal(agentJob)
+	agentMessage := &mgsContracts.AgentMessage{
+		MessageType:    mgsContracts.AgentJobMessage,
+		SchemaVersion:  schemaVersion,
+		CreatedDate:    createdDate,
+		SequenceNumber: 1,
+		Flags:          2,
+		MessageId:      u,
+		Payload:        agentJobJson,
+	}
+	serializedBytes, _ := agentMessage.Serialize(log.NewMockLog())
+
+	wsChannel := &communicatorMocks.IWebSocketChannel{}
+	controlChannel := &ControlChannel{wsChannel: wsChannel}
+
+	// the job is left to MDS when run command doesn't receive documents over the controlchannel
+	err := controlChannelIncomingMessageHandler(mockContext, mockProcessor, controlChannel, serializedBytes, "", instanceId)
+	assert.Nil(t, err)
+	wsChannel.AssertNotCalled(t, "SendMessage", mock.Anything, mock.Anything, mock.Anything)
+
+	var delivered *ssmmds.Message
+	mgsjob.RegisterHandler(func(message *ssmmds.Message) bool {
+		delivered = message
+		return true
+	})
+	defer mgsjob.RegisterHandler(nil)
+
+	var ack []byte
+	wsChannel.On("SendMessage", mock.Anything, mock.Anything, websocket.BinaryMessage).Return(nil).Run(func(args mock.Arguments) {
+		ack = args.Get(1).([]byte)
+	})
+
+	err = controlChannelIncomingMessageHandler(mockContext, mockProcessor, controlChannel, serializedBytes, "", instanceId)
+
+	assert.Nil(t, err)
+	assert.Equal(t, agentJob.JobId, *delivered.MessageId)
+	assert.Equal(t, agentJob.Topic, *delivered.Topic)
+	assert.Equal(t, agentJob.Payload, *delivered.Payload)
+	assert.Equal(t, instanceId, *delivered.Destination)
+
+	ackMessage := &mgsContracts.AgentMessage{}
+	assert.Nil(t, ackMessage.Deserialize(log.NewMockLog(), ack))
+	assert.Equal(t, mgsContracts.AgentJobAcknowledgeMessage, ackMessage.MessageType)
+	agentJobAck := mgsContracts.AgentJobAck{}
+	assert.Nil(t, json.Unmarshal(ackMessage.Payload, &agentJobAck))
+	assert.Equal(t, agentJob.JobId, agentJobAck.JobId)
+	assert.Equal(t, messageId, agentJobAck.MessageId)
+}
+
 func getControlChannel() *ControlChannel {
 	return &ControlChannel{
 		wsChannel:   mockWsChannel,
@@ -63,12 +63,13 @@ type IDataChannel interface {
 	ProcessAcknowledgedMessage(log log.T, acknowledgeMessageContent mgsContracts.AcknowledgeContent)
 	SendAcknowledgeMessage(log log.T, agentMessage mgsContracts.AgentMessage) error
 	SendAgentSessionStateMessage(log log.T, sessionStatus mgsContracts.SessionStatus) error
+	SendAgentSessionTerminatingMessage(log log.T, reason string) error
 	AddDataToOutgoingMessageBuffer(streamMessage StreamingMessage)
 	RemoveDataFromOutgoingMessageBuffer(streamMessageElement *list.Element)
 	AddDataToIncomingMessageBuffer(streamMessage StreamingMessage)
 	RemoveDataFromIncomingMessageBuffer(sequenceNumber int64)
 	SkipHandshake(log log.T)
-	PerformHandshake(log log.T, kmsKeyId string) (err error)
+	PerformHandshake(log log.T, kmsKeyId string, sessionType string) (err error)
 }
 
 // DataChannel used for session communication between the message gateway service and the agent.
@@ -107,6 +108,9 @@ type DataChannel struct {
 	blockCipher crypto.IBlockCipher
 	// Indicates whether encryption was enabled
 	encryptionEnabled bool
+	// outgoingThrottle and incomingThrottle limit the throughput of the stream data sent and received by the session
+	outgoingThrottle *throttle
+	incomingThrottle *throttle
 }
 
 type ListMessageBuffer struct {
@@ -241,6 +245,8 @@ func (dataChannel *DataChannel) Initialize(context context.T,
 	dataChannel.wsChannel = &communicator.WebSocketChannel{}
 	dataChannel.cancelFlag = cancelFlag
 	dataChannel.inputStreamMessageHandler = inputStreamMessageHandler
+	dataChannel.outgoingThrottle = newThrottle(context.AppConfig().Mgs.SessionOutgoingBytesPerSecond)
+	dataChannel.incomingThrottle = newThrottle(context.AppConfig().Mgs.SessionIncomingBytesPerSecond)
 	dataChannel.handshake = Handshake{
 		responseChan:            make(chan bool),
 		encryptionConfirmedChan: make(chan bool),
@@ -284,13 +290,7 @@ func (dataChannel *DataChannel) SetWebSocket(context context.T,
 			}
 			return dataChannel, nil
 		}
-		retryer := retry.ExponentialRetryer{
-			CallableFunc:        callable,
-			GeometricRatio:      mgsConfig.RetryGeometricRatio,
-			InitialDelayInMilli: rand.Intn(mgsConfig.DataChannelRetryInitialDelayMillis) + mgsConfig.DataChannelRetryInitialDelayMillis,
-			MaxDelayInMilli:     mgsConfig.DataChannelRetryMaxIntervalMillis,
-			MaxAttempts:         mgsConfig.DataChannelNumMaxAttempts,
-		}
+		retryer := dataChannelRetryer(context.AppConfig().Mgs, callable)
 		if _, err := retryer.Call(); err != nil {
 			log.Error(err)
 		}
@@ -311,6 +311,23 @@ func (dataChannel *DataChannel) SetWebSocket(context context.T,
 	return nil
 }
 
+// dataChannelRetryer returns the retryer reconnecting the datachannel with the backoff and window of the config.
+// Without a reconnection window the datachannel gives up after a few attempts.
+func dataChannelRetryer(config appconfig.MgsConfig, callable func() (interface{}, error)) retry.ExponentialRetryer {
+	retryer := retry.ExponentialRetryer{
+		CallableFunc:        callable,
+		GeometricRatio:      mgsConfig.RetryGeometricRatio,
+		InitialDelayInMilli: rand.Intn(config.DataChannelRetryInitialDelayMillis) + config.DataChannelRetryInitialDelayMillis,
+		MaxDelayInMilli:     config.DataChannelRetryMaxIntervalMillis,
+		MaxAttempts:         mgsConfig.DataChannelNumMaxAttempts,
+	}
+	if config.DataChannelReconnectWindowSeconds > 0 {
+		retryer.MaxAttempts = -1
+		retryer.MaxElapsedInMilli = config.DataChannelReconnectWindowSeconds * 1000
+	}
+	return retryer
+}
+
 // Open opens the websocket connection and sends the token for service to acknowledge the connection.
 func (dataChannel *DataChannel) Open(log log.T) error {
 	// Opens websocket connection
@@ -372,6 +389,8 @@ func (dataChannel *DataChannel) SendStreamDataMessage(log log.T, payloadType mgs
 		return nil
 	}
 
+	dataChannel.outgoingThrottle.wait(len(inputData))
+
 	var flag uint64 = 0
 	if dataChannel.StreamDataSequenceNumber == 0 {
 		flag = 1
@@ -492,12 +511,25 @@ func (dataChannel *DataChannel) SendAcknowledgeMessage(log log.T, streamDataMess
 
 // SendAgentSessionStateMessage sends agent session state to MGS
 func (dataChannel *DataChannel) SendAgentSessionStateMessage(log log.T, sessionStatus mgsContracts.SessionStatus) error {
-	agentSessionStateContent := &mgsContracts.AgentSessionStateContent{
+	return dataChannel.sendAgentSessionState(log, &mgsContracts.AgentSessionStateContent{
 		SchemaVersion: schemaVersion,
 		SessionState:  string(sessionStatus),
 		SessionId:     dataChannel.ChannelId,
-	}
+	})
+}
 
+// SendAgentSessionTerminatingMessage sends the Terminating session state to MGS with the reason the agent terminates the session
+func (dataChannel *DataChannel) SendAgentSessionTerminatingMessage(log log.T, reason string) error {
+	return dataChannel.sendAgentSessionState(log, &mgsContracts.AgentSessionStateContent{
+		SchemaVersion: schemaVersion,
+		SessionState:  string(mgsContracts.Terminating),
+		SessionId:     dataChannel.ChannelId,
+		Reason:        reason,
+	})
+}
+
+// sendAgentSessionState sends the agent session state message with the given content
+func (dataChannel *DataChannel) sendAgentSessionState(log log.T, agentSessionStateContent *mgsContracts.AgentSessionStateContent) error {
 	var agentSessionStateContentBytes []byte
 	var err error
 	if agentSessionStateContentBytes, err = json.Marshal(agentSessionStateContent); err != nil {
@@ -505,7 +537,7 @@ func (dataChannel *DataChannel) SendAgentSessionStateMessage(log log.T, sessionS
 		return err
 	}
 
-	log.Tracef("Send %s message with session status %s", mgsContracts.AgentSessionState, string(sessionStatus))
+	log.Tracef("Send %s message with session status %s", mgsContracts.AgentSessionState, agentSessionStateContent.SessionState)
 	if err := dataChannel.sendAgentMessage(log, mgsContracts.AgentSessionState, agentSessionStateContentBytes); err != nil {
 		return err
 	}
@@ -790,6 +822,9 @@ func (dataChannel *DataChannel) processStreamDataMessage(log log.T, streamDataMe
 			return nil
 		}
 
+		// Holding the incoming message pushes back on the client through the websocket
+		dataChannel.incomingThrottle.wait(len(streamDataMessage.Payload))
+
 		if err = dataChannel.inputStreamMessageHandler(log, streamDataMessage); err != nil {
 			return err
 		}
@@ -885,8 +920,8 @@ var newBlockCipher = func(log log.T, kmsKeyId string) (blockCipher crypto.IBlock
 	return crypto.NewBlockCipher(log, kmsKeyId)
 }
 
-// PerformHandshake performs handshake to share version string and encryption information with clients like cli/console
-func (dataChannel *DataChannel) PerformHandshake(log log.T, kmsKeyId string) (err error) {
+// PerformHandshake performs handshake to share version string, session type and encryption information with clients like cli/console
+func (dataChannel *DataChannel) PerformHandshake(log log.T, kmsKeyId string, sessionType string) (err error) {
 
 	if dataChannel.blockCipher, err = newBlockCipher(log, kmsKeyId); err != nil {
 		return fmt.Errorf("Initializing BlockCipher failed: %s", err)
@@ -897,7 +932,7 @@ func (dataChannel *DataChannel) PerformHandshake(log log.T, kmsKeyId string) (er
 	dataChannel.encryptionEnabled = true
 
 	log.Info("Initiating Handshake")
-	handshakeRequestPayload := dataChannel.buildHandshakeRequestPayload(log, dataChannel.encryptionEnabled)
+	handshakeRequestPayload := dataChannel.buildHandshakeRequestPayload(log, dataChannel.encryptionEnabled, sessionType)
 	if err := dataChannel.sendHandshakeRequest(log, handshakeRequestPayload); err != nil {
 		return err
 	}
@@ -946,14 +981,14 @@ func (dataChannel *DataChannel) PerformHandshake(log log.T, kmsKeyId string) (er
 }
 
 // buildHandshakeRequestPayload builds payload for HandshakeRequest
-func (dataChannel *DataChannel) buildHandshakeRequestPayload(log log.T, encryptionRequested bool) mgsContracts.HandshakeRequestPayload {
+func (dataChannel *DataChannel) buildHandshakeRequestPayload(log log.T, encryptionRequested bool, sessionType string) mgsContracts.HandshakeRequestPayload {
 	handshakeRequest := mgsContracts.HandshakeRequestPayload{}
 	handshakeRequest.AgentVersion = version.Version
 	handshakeRequest.RequestedClientActions = []mgsContracts.RequestedClientAction{
 		{
 			ActionType: mgsContracts.SessionType,
 			ActionParameters: mgsContracts.SessionTypeRequest{
-				SessionType: appconfig.PluginNameStandardStream,
+				SessionType: sessionType,
 			},
 		}}
 	if encryptionRequested {
@@ -23,6 +23,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
 	"github.com/aws/amazon-ssm-agent/agent/context"
 	"github.com/aws/amazon-ssm-agent/agent/crypto"
 	cryptoMocks "github.com/aws/amazon-ssm-agent/agent/crypto/mocks"
@@ -95,6 +96,24 @@ func TestInitialize(t *testing.T) {
 	assert.Equal(t, mgsConfig.DefaultTransmissionTimeout, dataChannel.RetransmissionTimeout)
 }
 
+func TestDataChannelRetryer(t *testing.T) {
+	config := appconfig.MgsConfig{
+		DataChannelRetryInitialDelayMillis: 200,
+		DataChannelRetryMaxIntervalMillis:  10000,
+	}
+	retryer := dataChannelRetryer(config, nil)
+	assert.True(t, retryer.InitialDelayInMilli >= 200 && retryer.InitialDelayInMilli < 400)
+	assert.Equal(t, 10000, retryer.MaxDelayInMilli)
+	assert.Equal(t, mgsConfig.DataChannelNumMaxAttempts, retryer.MaxAttempts)
+	assert.Equal(t, 0, retryer.MaxElapsedInMilli)
+
+	// the reconnection window replaces the limit of attempts
+	config.DataChannelReconnectWindowSeconds = 120
+	retryer = dataChannelRetryer(config, nil)
+	assert.Equal(t, -1, retryer.MaxAttempts)
+	assert.Equal(t, 120000, retryer.MaxElapsedInMilli)
+}
+
 func TestSetWebSocket(t *testing.T) {
 	dataChannel := getDataChannel()
 
@@ -244,6 +263,15 @@ func TestSendAgentSessionStateMessage(t *testing.T) {
 	mockWsChannel.AssertExpectations(t)
 }
 
+func TestSendAgentSessionTerminatingMessage(t *testing.T) {
+	dataChannel := getDataChannel()
+
+	mockWsChannel.On("SendMessage", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	dataChannel.SendAgentSessionTerminatingMessage(mockLog, "idle")
+
+	mockWsChannel.AssertExpectations(t)
+}
+
 func TestAddDataToOutgoingMessageBuffer(t *testing.T) {
 	dataChannel := getDataChannel()
 	dataChannel.OutgoingMessageBuffer.Capacity = 2
@@ -555,7 +583,7 @@ func TestDataCHannelHandshakeInitiate(t *testing.T) {
 	dataChannel.encryptionEnabled = true
 
 	// Mocking sending of handshake request
-	handshakeRequestPayload, _ := json.Marshal(dataChannel.buildHandshakeRequestPayload(mockLog, true))
+	handshakeRequestPayload, _ := json.Marshal(dataChannel.buildHandshakeRequestPayload(mockLog, true, appconfig.PluginNameStandardStream))
 	handshakeRequestMatcher := func(sentData []byte) bool {
 		agentMessage := mgsContracts.AgentMessage{}
 		agentMessage.Deserialize(mockLog, sentData)
@@ -597,7 +625,7 @@ func TestDataCHannelHandshakeInitiate(t *testing.T) {
 		return mockCipher, nil
 	}
 
-	err := dataChannel.PerformHandshake(mockLog, kmskey)
+	err := dataChannel.PerformHandshake(mockLog, kmskey, appconfig.PluginNameStandardStream)
 
 	assert.Nil(t, err)
 	assert.True(t, dataChannel.handshake.complete)
@@ -57,13 +57,13 @@ func (_m *IDataChannel) Initialize(_a0 context.T, mgsService service.Service, se
 	_m.Called(_a0, mgsService, sessionId, clientId, instanceId, role, cancelFlag, inputStreamMessageHandler)
 }
 
-// PerformHandshake provides a mock function with given fields: _a0, kmsKeyId
-func (_m *IDataChannel) PerformHandshake(_a0 log.T, kmsKeyId string) error {
-	ret := _m.Called(_a0, kmsKeyId)
+// PerformHandshake provides a mock function with given fields: _a0, kmsKeyId, sessionType
+func (_m *IDataChannel) PerformHandshake(_a0 log.T, kmsKeyId string, sessionType string) error {
+	ret := _m.Called(_a0, kmsKeyId, sessionType)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(log.T, string) error); ok {
-		r0 = rf(_a0, kmsKeyId)
+	if rf, ok := ret.Get(0).(func(log.T, string, string) error); ok {
+		r0 = rf(_a0, kmsKeyId, sessionType)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -156,6 +156,20 @@ func (_m *IDataChannel) SendAgentSessionStateMessage(_a0 log.T, sessionStatus co
 	return r0
 }
 
+// SendAgentSessionTerminatingMessage provides a mock function with given fields: _a0, reason
+func (_m *IDataChannel) SendAgentSessionTerminatingMessage(_a0 log.T, reason string) error {
+	ret := _m.Called(_a0, reason)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(log.T, string) error); ok {
+		r0 = rf(_a0, reason)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // SendMessage provides a mock function with given fields: _a0, input, inputType
 func (_m *IDataChannel) SendMessage(_a0 log.T, input []byte, inputType int) error {
 	ret := _m.Called(_a0, input, inputType)
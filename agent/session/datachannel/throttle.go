@@ -0,0 +1,67 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package datachannel implements data channel which is used to interactively run commands.
+package datachannel
+
+import (
+	"sync"
+	"time"
+)
+
+// sleep is assigned to a variable to allow unit tests to override it
+var sleep = time.Sleep
+
+// throttle limits the throughput of one direction of the data channel.
+// It is a token bucket holding up to a second of traffic, so short bursts go through at full speed.
+type throttle struct {
+	bytesPerSecond float64
+	// lock guards the bucket and serializes the writers waiting for it
+	lock      sync.Mutex
+	available float64
+	last      time.Time
+}
+
+// newThrottle returns the throttle of the limit, nil when the limit is 0
+func newThrottle(bytesPerSecond int) *throttle {
+	if bytesPerSecond <= 0 {
+		return nil
+	}
+	return &throttle{
+		bytesPerSecond: float64(bytesPerSecond),
+		available:      float64(bytesPerSecond),
+		last:           time.Now(),
+	}
+}
+
+// wait blocks until the bytes fit in the limit, a nil throttle never blocks
+func (t *throttle) wait(bytes int) {
+	if t == nil {
+		return
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	now := time.Now()
+	t.available += now.Sub(t.last).Seconds() * t.bytesPerSecond
+	if t.available > t.bytesPerSecond {
+		t.available = t.bytesPerSecond
+	}
+	t.last = now
+
+	t.available -= float64(bytes)
+	if t.available < 0 {
+		// the bucket refills while waiting, the debt is paid once the sleep ends
+		sleep(time.Duration(-t.available / t.bytesPerSecond * float64(time.Second)))
+	}
+}
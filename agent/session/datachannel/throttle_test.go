@@ -0,0 +1,43 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package datachannel implements data channel which is used to interactively run commands.
+package datachannel
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThrottleWithoutLimit(t *testing.T) {
+	throttle := newThrottle(0)
+	assert.Nil(t, throttle)
+	// a nil throttle doesn't block
+	throttle.wait(1 << 20)
+}
+
+func TestThrottle(t *testing.T) {
+	defer func() { sleep = time.Sleep }()
+	var slept time.Duration
+	sleep = func(d time.Duration) { slept += d }
+
+	throttle := newThrottle(1000)
+	// the first second of traffic goes through as a burst
+	throttle.wait(1000)
+	assert.Equal(t, time.Duration(0), slept)
+
+	throttle.wait(500)
+	assert.InDelta(t, float64(500*time.Millisecond), float64(slept), float64(10*time.Millisecond))
+}
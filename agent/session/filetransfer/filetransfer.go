@@ -0,0 +1,423 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package filetransfer implements the file transfers multiplexed with the data of the sessions over their data channel.
+package filetransfer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	mgsConfig "github.com/aws/amazon-ssm-agent/agent/session/config"
+	mgsContracts "github.com/aws/amazon-ssm-agent/agent/session/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/session/datachannel"
+)
+
+const (
+	// partialSuffix is appended to the path of the uploads until they complete, so they can be resumed
+	partialSuffix = ".ssm-partial"
+	// chunkSize is the size of the data of each chunk, the size of the stream data the sessions send
+	chunkSize = mgsConfig.StreamDataPayloadSize
+)
+
+// upload is a file being uploaded by the client
+type upload struct {
+	path    string
+	file    *os.File
+	size    int64
+	sha256  string
+	written int64
+}
+
+// Transfers runs the file transfers of a session. The relative paths are relative to the root directory, the
+// transfers can't leave it. An empty root allows absolute paths anywhere on the instance.
+type Transfers struct {
+	dataChannel datachannel.IDataChannel
+	root        string
+	// owner is the local user the uploaded files belong to, empty keeps the agent as their owner
+	owner string
+	// lock guards uploads and downloads, the transfers by transfer id
+	lock      sync.Mutex
+	uploads   map[string]*upload
+	downloads map[string]chan struct{}
+}
+
+// New returns the file transfers of the session
+func New(dataChannel datachannel.IDataChannel, root string, owner string) *Transfers {
+	return &Transfers{
+		dataChannel: dataChannel,
+		root:        root,
+		owner:       owner,
+		uploads:     map[string]*upload{},
+		downloads:   map[string]chan struct{}{},
+	}
+}
+
+// Reject replies an error to the file transfer message, when the session doesn't allow file transfers
+func Reject(log log.T, dataChannel datachannel.IDataChannel, payload []byte, reason string) error {
+	var message mgsContracts.FileTransferMessage
+	if err := json.Unmarshal(payload, &message); err != nil {
+		return fmt.Errorf("invalid file transfer message: %v", err)
+	}
+	return send(log, dataChannel, mgsContracts.FileTransferMessage{
+		Action:     mgsContracts.FileTransferError,
+		TransferId: message.TransferId,
+		Error:      reason,
+	})
+}
+
+// HandleMessage runs the step of the transfer of the file transfer message, the failed transfers are replied an error
+func (t *Transfers) HandleMessage(log log.T, payload []byte) error {
+	var message mgsContracts.FileTransferMessage
+	if err := json.Unmarshal(payload, &message); err != nil {
+		return fmt.Errorf("invalid file transfer message: %v", err)
+	}
+
+	var err error
+	switch message.Action {
+	case mgsContracts.FileTransferUpload:
+		err = t.startUpload(log, message)
+	case mgsContracts.FileTransferChunk:
+		err = t.writeChunk(message)
+	case mgsContracts.FileTransferComplete:
+		err = t.completeUpload(log, message)
+	case mgsContracts.FileTransferDownload:
+		err = t.startDownload(log, message)
+	case mgsContracts.FileTransferCancel:
+		t.cancel(message.TransferId)
+	default:
+		err = fmt.Errorf("unknown file transfer action %v", message.Action)
+	}
+
+	if err != nil {
+		log.Warnf("File transfer %v failed: %v", message.TransferId, err)
+		t.cancel(message.TransferId)
+		return send(log, t.dataChannel, mgsContracts.FileTransferMessage{
+			Action:     mgsContracts.FileTransferError,
+			TransferId: message.TransferId,
+			Error:      err.Error(),
+		})
+	}
+	return nil
+}
+
+// Close stops the transfers once the session ends, the uploads can be resumed by another session
+func (t *Transfers) Close() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	for id, upload := range t.uploads {
+		upload.file.Close()
+		delete(t.uploads, id)
+	}
+	for id, done := range t.downloads {
+		close(done)
+		delete(t.downloads, id)
+	}
+}
+
+// startUpload opens the partial file of the upload and replies the offset the client resumes from
+func (t *Transfers) startUpload(log log.T, message mgsContracts.FileTransferMessage) error {
+	path, err := t.resolve(message.Path)
+	if err != nil {
+		return err
+	}
+	if message.Size < 0 || message.Sha256 == "" {
+		return fmt.Errorf("the upload of %v needs its size and sha256", message.Path)
+	}
+	partialPath := path + partialSuffix
+	if err = checkNotSymlink(partialPath); err != nil {
+		return err
+	}
+
+	// the partial file is only readable by its owner until the upload completes
+	file, err := os.OpenFile(partialPath, os.O_CREATE|os.O_RDWR|noFollow, 0600)
+	if err != nil {
+		return err
+	}
+	upload := &upload{path: path, file: file, size: message.Size, sha256: strings.ToLower(message.Sha256)}
+	if err = checkOpenedFile(file); err == nil {
+		err = chown(file, t.owner)
+	}
+	if err == nil {
+		upload.written, err = file.Seek(0, io.SeekEnd)
+	}
+	// a partial file longer than the upload belongs to another upload, it starts over
+	if err == nil && upload.written > upload.size {
+		if err = file.Truncate(0); err == nil {
+			upload.written = 0
+		}
+	}
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	t.lock.Lock()
+	if previous, exists := t.uploads[message.TransferId]; exists {
+		previous.file.Close()
+	}
+	t.uploads[message.TransferId] = upload
+	t.lock.Unlock()
+
+	log.Infof("Uploading %v from offset %d", path, upload.written)
+	return send(log, t.dataChannel, mgsContracts.FileTransferMessage{
+		Action:     mgsContracts.FileTransferReady,
+		TransferId: message.TransferId,
+		Offset:     upload.written,
+		Size:       upload.size,
+	})
+}
+
+// writeChunk writes the chunk of the upload, the chunks come in order from the offset the upload resumed from
+func (t *Transfers) writeChunk(message mgsContracts.FileTransferMessage) error {
+	t.lock.Lock()
+	upload, exists := t.uploads[message.TransferId]
+	t.lock.Unlock()
+	if !exists {
+		return fmt.Errorf("no upload %v in progress", message.TransferId)
+	}
+	if message.Offset != upload.written {
+		return fmt.Errorf("the chunk at offset %d doesn't follow the data written up to offset %d", message.Offset, upload.written)
+	}
+	if upload.written+int64(len(message.Data)) > upload.size {
+		return fmt.Errorf("the chunk at offset %d exceeds the size %d of the upload", message.Offset, upload.size)
+	}
+
+	n, err := upload.file.WriteAt(message.Data, message.Offset)
+	upload.written += int64(n)
+	return err
+}
+
+// completeUpload checks the size and sha256 of the uploaded file and moves it to its path
+func (t *Transfers) completeUpload(log log.T, message mgsContracts.FileTransferMessage) error {
+	t.lock.Lock()
+	upload, exists := t.uploads[message.TransferId]
+	delete(t.uploads, message.TransferId)
+	t.lock.Unlock()
+	if !exists {
+		return fmt.Errorf("no upload %v in progress", message.TransferId)
+	}
+	defer upload.file.Close()
+
+	if upload.written != upload.size {
+		return fmt.Errorf("received %d bytes of the %d bytes of the upload", upload.written, upload.size)
+	}
+	checksum, err := fileChecksum(upload.file)
+	if err != nil {
+		return err
+	}
+	if checksum != upload.sha256 {
+		// the data is corrupted, the next upload starts over
+		upload.file.Truncate(0)
+		return fmt.Errorf("the sha256 %v of the uploaded file doesn't match the sha256 %v of the upload", checksum, upload.sha256)
+	}
+	if err = checkNotSymlink(upload.path); err != nil {
+		return err
+	}
+	if err = os.Rename(upload.path+partialSuffix, upload.path); err != nil {
+		return err
+	}
+
+	log.Infof("Uploaded %v", upload.path)
+	return send(log, t.dataChannel, mgsContracts.FileTransferMessage{
+		Action:     mgsContracts.FileTransferCompleted,
+		TransferId: message.TransferId,
+		Size:       upload.size,
+		Sha256:     checksum,
+	})
+}
+
+// startDownload replies the size and sha256 of the file, then sends its chunks from the offset
+func (t *Transfers) startDownload(log log.T, message mgsContracts.FileTransferMessage) error {
+	path, err := t.resolve(message.Path)
+	if err != nil {
+		return err
+	}
+	if err = checkNotSymlink(path); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_RDONLY|noFollow, 0)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err == nil {
+		err = checkOpenedFile(file)
+	}
+	var checksum string
+	if err == nil {
+		checksum, err = fileChecksum(file)
+	}
+	if err == nil && (message.Offset < 0 || message.Offset > info.Size()) {
+		err = fmt.Errorf("the offset %d is outside of the %d bytes of the file", message.Offset, info.Size())
+	}
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	done := make(chan struct{})
+	t.lock.Lock()
+	if previous, exists := t.downloads[message.TransferId]; exists {
+		close(previous)
+	}
+	t.downloads[message.TransferId] = done
+	t.lock.Unlock()
+
+	if err = send(log, t.dataChannel, mgsContracts.FileTransferMessage{
+		Action:     mgsContracts.FileTransferReady,
+		TransferId: message.TransferId,
+		Offset:     message.Offset,
+		Size:       info.Size(),
+		Sha256:     checksum,
+	}); err != nil {
+		file.Close()
+		return err
+	}
+
+	log.Infof("Downloading %v from offset %d", path, message.Offset)
+	go t.sendChunks(log, message.TransferId, file, message.Offset, info.Size(), checksum, done)
+	return nil
+}
+
+// sendChunks sends the chunks of the downloaded file until its end or until the download is cancelled
+func (t *Transfers) sendChunks(log log.T, transferId string, file *os.File, offset int64, size int64, checksum string, done chan struct{}) {
+	defer file.Close()
+	defer t.removeDownload(transferId, done)
+
+	buffer := make([]byte, chunkSize)
+	for offset < size {
+		select {
+		case <-done:
+			log.Infof("File transfer %v cancelled", transferId)
+			return
+		default:
+		}
+
+		n, err := file.ReadAt(buffer, offset)
+		if n == 0 && err != nil {
+			log.Warnf("File transfer %v failed: %v", transferId, err)
+			send(log, t.dataChannel, mgsContracts.FileTransferMessage{
+				Action:     mgsContracts.FileTransferError,
+				TransferId: transferId,
+				Error:      err.Error(),
+			})
+			return
+		}
+		if err = send(log, t.dataChannel, mgsContracts.FileTransferMessage{
+			Action:     mgsContracts.FileTransferChunk,
+			TransferId: transferId,
+			Offset:     offset,
+			Data:       buffer[:n],
+		}); err != nil {
+			log.Warnf("File transfer %v failed: %v", transferId, err)
+			return
+		}
+		offset += int64(n)
+	}
+
+	send(log, t.dataChannel, mgsContracts.FileTransferMessage{
+		Action:     mgsContracts.FileTransferCompleted,
+		TransferId: transferId,
+		Size:       size,
+		Sha256:     checksum,
+	})
+}
+
+// removeDownload forgets the download once it stops, unless a new download took its transfer id
+func (t *Transfers) removeDownload(transferId string, done chan struct{}) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.downloads[transferId] == done {
+		delete(t.downloads, transferId)
+	}
+}
+
+// cancel stops the transfer, the partial file of an upload is kept so that it can be resumed
+func (t *Transfers) cancel(transferId string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if upload, exists := t.uploads[transferId]; exists {
+		upload.file.Close()
+		delete(t.uploads, transferId)
+	}
+	if done, exists := t.downloads[transferId]; exists {
+		close(done)
+		delete(t.downloads, transferId)
+	}
+}
+
+// resolve returns the path of the file on the instance, the files outside of the root are refused
+func (t *Transfers) resolve(path string) (string, error) {
+	if path == "" {
+		return "", fmt.Errorf("the file transfer has no path")
+	}
+	if t.root == "" {
+		if !filepath.IsAbs(path) {
+			return "", fmt.Errorf("the path %v must be absolute", path)
+		}
+		return filepath.Clean(path), nil
+	}
+
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(t.root, path)
+	}
+	root, err := filepath.EvalSymlinks(t.root)
+	if err != nil {
+		return "", err
+	}
+	// the directory of the file is resolved so that symbolic links can't lead outside of the root
+	dir, err := filepath.EvalSymlinks(filepath.Dir(filepath.Clean(path)))
+	if err != nil {
+		return "", err
+	}
+	if rel, err := filepath.Rel(root, dir); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("the path %v is outside of %v", path, t.root)
+	}
+	return filepath.Join(dir, filepath.Base(path)), nil
+}
+
+// checkNotSymlink refuses the files which are symbolic links, they could lead anywhere on the instance.
+// The files are also opened without following symbolic links, in case one replaces the file after the check.
+func checkNotSymlink(path string) error {
+	if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		return fmt.Errorf("%v is a symbolic link", path)
+	}
+	return nil
+}
+
+// fileChecksum returns the hex encoded sha256 of the content of the file
+func fileChecksum(file *os.File) (string, error) {
+	hash := sha256.New()
+	if _, err := io.Copy(hash, io.NewSectionReader(file, 0, 1<<62)); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// send sends the file transfer message to the client
+func send(log log.T, dataChannel datachannel.IDataChannel, message mgsContracts.FileTransferMessage) error {
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	return dataChannel.SendStreamDataMessage(log, mgsContracts.FileTransfer, payload)
+}
@@ -0,0 +1,171 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package filetransfer implements the file transfers multiplexed with the data of the sessions over their data channel.
+package filetransfer
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	mgsContracts "github.com/aws/amazon-ssm-agent/agent/session/contracts"
+	dataChannelMock "github.com/aws/amazon-ssm-agent/agent/session/datachannel/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// newTransfers returns the transfers of a session confined to a temporary directory and the messages they send
+func newTransfers(t *testing.T) (*Transfers, string, chan mgsContracts.FileTransferMessage) {
+	root, err := ioutil.TempDir("", "filetransfer")
+	assert.Nil(t, err)
+
+	sent := make(chan mgsContracts.FileTransferMessage, 1000)
+	mockDataChannel := &dataChannelMock.IDataChannel{}
+	mockDataChannel.On("SendStreamDataMessage", mock.Anything, mgsContracts.FileTransfer, mock.Anything).
+		Run(func(args mock.Arguments) {
+			var message mgsContracts.FileTransferMessage
+			assert.Nil(t, json.Unmarshal(args.Get(2).([]byte), &message))
+			sent <- message
+		}).Return(nil)
+	return New(mockDataChannel, root, ""), root, sent
+}
+
+func handle(t *testing.T, transfers *Transfers, message mgsContracts.FileTransferMessage) {
+	payload, err := json.Marshal(message)
+	assert.Nil(t, err)
+	assert.Nil(t, transfers.HandleMessage(log.NewMockLog(), payload))
+}
+
+func checksum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestUploadResumes(t *testing.T) {
+	transfers, root, sent := newTransfers(t)
+	defer os.RemoveAll(root)
+	content := bytes.Repeat([]byte("0123456789"), 300)
+	upload := mgsContracts.FileTransferMessage{
+		Action:     mgsContracts.FileTransferUpload,
+		TransferId: "upload",
+		Path:       "file.txt",
+		Size:       int64(len(content)),
+		Sha256:     checksum(content),
+	}
+
+	handle(t, transfers, upload)
+	assert.Equal(t, mgsContracts.FileTransferMessage{Action: mgsContracts.FileTransferReady, TransferId: "upload", Size: upload.Size}, <-sent)
+	handle(t, transfers, mgsContracts.FileTransferMessage{Action: mgsContracts.FileTransferChunk, TransferId: "upload", Data: content[:1000]})
+	transfers.Close()
+
+	// the upload resumes after the data received by the previous session
+	handle(t, transfers, upload)
+	ready := <-sent
+	assert.Equal(t, int64(1000), ready.Offset)
+	handle(t, transfers, mgsContracts.FileTransferMessage{Action: mgsContracts.FileTransferChunk, TransferId: "upload", Offset: 1000, Data: content[1000:]})
+	handle(t, transfers, mgsContracts.FileTransferMessage{Action: mgsContracts.FileTransferComplete, TransferId: "upload"})
+
+	completed := <-sent
+	assert.Equal(t, mgsContracts.FileTransferCompleted, completed.Action)
+	assert.Equal(t, upload.Sha256, completed.Sha256)
+	uploaded, err := ioutil.ReadFile(filepath.Join(root, "file.txt"))
+	assert.Nil(t, err)
+	assert.Equal(t, content, uploaded)
+	_, err = os.Stat(filepath.Join(root, "file.txt"+partialSuffix))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestUploadWithInvalidChecksum(t *testing.T) {
+	transfers, root, sent := newTransfers(t)
+	defer os.RemoveAll(root)
+
+	handle(t, transfers, mgsContracts.FileTransferMessage{Action: mgsContracts.FileTransferUpload, TransferId: "upload", Path: "file.txt", Size: 4, Sha256: checksum([]byte("data"))})
+	<-sent
+	handle(t, transfers, mgsContracts.FileTransferMessage{Action: mgsContracts.FileTransferChunk, TransferId: "upload", Data: []byte("dat!")})
+	handle(t, transfers, mgsContracts.FileTransferMessage{Action: mgsContracts.FileTransferComplete, TransferId: "upload"})
+
+	assert.Equal(t, mgsContracts.FileTransferError, (<-sent).Action)
+	_, err := os.Stat(filepath.Join(root, "file.txt"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestUploadWithChunkOutOfOrder(t *testing.T) {
+	transfers, root, sent := newTransfers(t)
+	defer os.RemoveAll(root)
+
+	handle(t, transfers, mgsContracts.FileTransferMessage{Action: mgsContracts.FileTransferUpload, TransferId: "upload", Path: "file.txt", Size: 8, Sha256: checksum([]byte("datadata"))})
+	<-sent
+	handle(t, transfers, mgsContracts.FileTransferMessage{Action: mgsContracts.FileTransferChunk, TransferId: "upload", Offset: 4, Data: []byte("data")})
+
+	assert.Equal(t, mgsContracts.FileTransferError, (<-sent).Action)
+}
+
+func TestDownloadFromOffset(t *testing.T) {
+	transfers, root, sent := newTransfers(t)
+	defer os.RemoveAll(root)
+	content := bytes.Repeat([]byte("0123456789"), 300)
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(root, "file.txt"), content, 0644))
+
+	handle(t, transfers, mgsContracts.FileTransferMessage{Action: mgsContracts.FileTransferDownload, TransferId: "download", Path: "file.txt", Offset: 500})
+
+	ready := <-sent
+	assert.Equal(t, mgsContracts.FileTransferReady, ready.Action)
+	assert.Equal(t, int64(len(content)), ready.Size)
+	assert.Equal(t, checksum(content), ready.Sha256)
+	var downloaded []byte
+	for message := range sent {
+		if message.Action != mgsContracts.FileTransferChunk {
+			assert.Equal(t, mgsContracts.FileTransferCompleted, message.Action)
+			break
+		}
+		assert.Equal(t, int64(500+len(downloaded)), message.Offset)
+		assert.True(t, len(message.Data) <= chunkSize)
+		downloaded = append(downloaded, message.Data...)
+	}
+	assert.Equal(t, content[500:], downloaded)
+}
+
+func TestTransfersAreConfinedToTheRoot(t *testing.T) {
+	transfers, root, sent := newTransfers(t)
+	defer os.RemoveAll(root)
+	outside, err := ioutil.TempDir("", "outside")
+	assert.Nil(t, err)
+	defer os.RemoveAll(outside)
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(outside, "secret"), []byte("secret"), 0600))
+	assert.Nil(t, os.Symlink(outside, filepath.Join(root, "link")))
+	assert.Nil(t, os.Symlink(filepath.Join(outside, "secret"), filepath.Join(root, "secret")))
+
+	for _, path := range []string{"../secret", filepath.Join(outside, "secret"), "link/secret", "secret"} {
+		handle(t, transfers, mgsContracts.FileTransferMessage{Action: mgsContracts.FileTransferDownload, TransferId: "download", Path: path})
+		assert.Equal(t, mgsContracts.FileTransferError, (<-sent).Action, path)
+	}
+}
+
+func TestReject(t *testing.T) {
+	var sent mgsContracts.FileTransferMessage
+	mockDataChannel := &dataChannelMock.IDataChannel{}
+	mockDataChannel.On("SendStreamDataMessage", mock.Anything, mgsContracts.FileTransfer, mock.Anything).
+		Run(func(args mock.Arguments) {
+			assert.Nil(t, json.Unmarshal(args.Get(2).([]byte), &sent))
+		}).Return(nil)
+
+	assert.Nil(t, Reject(log.NewMockLog(), mockDataChannel, []byte(`{"action":"upload","transferId":"upload"}`), "disabled"))
+	assert.Equal(t, mgsContracts.FileTransferMessage{Action: mgsContracts.FileTransferError, TransferId: "upload", Error: "disabled"}, sent)
+}
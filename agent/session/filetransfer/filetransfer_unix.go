@@ -0,0 +1,64 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// +build darwin freebsd linux netbsd openbsd
+
+// Package filetransfer implements the file transfers multiplexed with the data of the sessions over their data channel.
+package filetransfer
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"strconv"
+	"syscall"
+)
+
+// noFollow makes opening a file fail when it's a symbolic link, the agent runs the transfers as root
+const noFollow = syscall.O_NOFOLLOW
+
+// chown makes the user the owner of the open file, so that the session user can use the files it uploads
+func chown(file *os.File, owner string) error {
+	if owner == "" {
+		return nil
+	}
+	account, err := user.Lookup(owner)
+	if err != nil {
+		return err
+	}
+	uid, err := strconv.Atoi(account.Uid)
+	if err != nil {
+		return err
+	}
+	gid, err := strconv.Atoi(account.Gid)
+	if err != nil {
+		return err
+	}
+	return file.Chown(uid, gid)
+}
+
+// checkOpenedFile refuses the open files which aren't regular files or have other hard links,
+// a hard link would let the session read or write through the agent a file it can't access
+func checkOpenedFile(file *os.File) error {
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("%v isn't a regular file", file.Name())
+	}
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok && stat.Nlink > 1 {
+		return fmt.Errorf("%v has other hard links", file.Name())
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// +build darwin freebsd linux netbsd openbsd
+
+// Package filetransfer implements the file transfers multiplexed with the data of the sessions over their data channel.
+package filetransfer
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	mgsContracts "github.com/aws/amazon-ssm-agent/agent/session/contracts"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPartialFileIsPrivate(t *testing.T) {
+	transfers, root, sent := newTransfers(t)
+	defer os.RemoveAll(root)
+
+	handle(t, transfers, mgsContracts.FileTransferMessage{Action: mgsContracts.FileTransferUpload, TransferId: "upload", Path: "file.txt", Size: 4, Sha256: checksum([]byte("data"))})
+	assert.Equal(t, mgsContracts.FileTransferReady, (<-sent).Action)
+
+	info, err := os.Stat(filepath.Join(root, "file.txt"+partialSuffix))
+	assert.Nil(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestHardLinksAreRefused(t *testing.T) {
+	transfers, root, sent := newTransfers(t)
+	defer os.RemoveAll(root)
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(root, "target"), []byte("target"), 0600))
+	assert.Nil(t, os.Link(filepath.Join(root, "target"), filepath.Join(root, "file.txt"+partialSuffix)))
+	assert.Nil(t, os.Link(filepath.Join(root, "target"), filepath.Join(root, "download.txt")))
+
+	handle(t, transfers, mgsContracts.FileTransferMessage{Action: mgsContracts.FileTransferUpload, TransferId: "upload", Path: "file.txt", Size: 4, Sha256: checksum([]byte("data"))})
+	assert.Equal(t, mgsContracts.FileTransferError, (<-sent).Action)
+	handle(t, transfers, mgsContracts.FileTransferMessage{Action: mgsContracts.FileTransferDownload, TransferId: "download", Path: "download.txt"})
+	assert.Equal(t, mgsContracts.FileTransferError, (<-sent).Action)
+
+	content, err := ioutil.ReadFile(filepath.Join(root, "target"))
+	assert.Nil(t, err)
+	assert.Equal(t, "target", string(content))
+}
+
+func TestOpenDoesNotFollowSymlinks(t *testing.T) {
+	root, err := ioutil.TempDir("", "filetransfer")
+	assert.Nil(t, err)
+	defer os.RemoveAll(root)
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(root, "target"), []byte("target"), 0600))
+	assert.Nil(t, os.Symlink(filepath.Join(root, "target"), filepath.Join(root, "link")))
+
+	// a symbolic link swapped in after checkNotSymlink still isn't followed
+	_, err = os.OpenFile(filepath.Join(root, "link"), os.O_RDWR|noFollow, 0600)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,42 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// +build windows
+
+// Package filetransfer implements the file transfers multiplexed with the data of the sessions over their data channel.
+package filetransfer
+
+import (
+	"fmt"
+	"os"
+)
+
+// noFollow is empty on windows, the symbolic links are refused by checkNotSymlink
+const noFollow = 0
+
+// chown keeps the owner of the files on windows, the files inherit the permissions of their directory
+func chown(file *os.File, owner string) error {
+	return nil
+}
+
+// checkOpenedFile refuses the open files which aren't regular files
+func checkOpenedFile(file *os.File) error {
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("%v isn't a regular file", file.Name())
+	}
+	return nil
+}
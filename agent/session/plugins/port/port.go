@@ -0,0 +1,304 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package port implements session port plugin, forwarding a port of the instance or of an allowed destination.
+package port
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	agentContracts "github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler"
+	"github.com/aws/amazon-ssm-agent/agent/jsonutil"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	mgsConfig "github.com/aws/amazon-ssm-agent/agent/session/config"
+	mgsContracts "github.com/aws/amazon-ssm-agent/agent/session/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/session/datachannel"
+	"github.com/aws/amazon-ssm-agent/agent/session/plugins/sessionplugin"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+)
+
+const (
+	localHost   = "localhost"
+	dialTimeout = 10 * time.Second
+	// anyPort allows all the ports of a destination host
+	anyPort = "*"
+	// flagLength is the length of the payload of the flag messages
+	flagLength = 4
+)
+
+// halfCloser is implemented by the connections which can be closed for writing only, like tcp connections
+type halfCloser interface {
+	CloseWrite() error
+}
+
+// PortParameters are the properties of the port session document
+type PortParameters struct {
+	PortNumber string `json:"portNumber"`
+	// Host is the destination of the forwarded port, empty forwards a port of the instance
+	Host string `json:"host"`
+}
+
+// PortPlugin is the type for the port plugin.
+type PortPlugin struct {
+	dataChannel datachannel.IDataChannel
+	// connLock guards conn, the data channel writes to the destination while the plugin starts and stops
+	connLock sync.RWMutex
+	conn     net.Conn
+}
+
+// dialDestination is assigned to a variable to allow unit tests to override it
+var dialDestination = dialTCP
+
+// dialTCP opens a tcp connection to the destination
+func dialTCP(address string) (net.Conn, error) {
+	return net.DialTimeout("tcp", address, dialTimeout)
+}
+
+// NewPlugin returns a new instance of the Port Plugin
+func NewPlugin() (sessionplugin.ISessionPlugin, error) {
+	var plugin = PortPlugin{}
+	return &plugin, nil
+}
+
+// name returns the name of Port Plugin
+func (p *PortPlugin) name() string {
+	return appconfig.PluginNamePort
+}
+
+// Execute connects to the destination port.
+// It reads incoming message from data channel and writes to the destination.
+// It reads data from the destination and writes to data channel
+func (p *PortPlugin) Execute(context context.T,
+	config agentContracts.Configuration,
+	cancelFlag task.CancelFlag,
+	output iohandler.IOHandler,
+	dataChannel datachannel.IDataChannel) {
+
+	if cancelFlag.ShutDown() {
+		output.MarkAsShutdown()
+	} else if cancelFlag.Canceled() {
+		output.MarkAsCancelled()
+	} else {
+		p.execute(context, config, cancelFlag, output, dataChannel)
+	}
+}
+
+// execute checks the destination of the session is allowed and forwards the data to it
+func (p *PortPlugin) execute(context context.T,
+	config agentContracts.Configuration,
+	cancelFlag task.CancelFlag,
+	output iohandler.IOHandler,
+	dataChannel datachannel.IDataChannel) {
+
+	log := context.Log()
+
+	var parameters PortParameters
+	if err := jsonutil.Remarshal(config.Properties, &parameters); err != nil {
+		errorString := fmt.Errorf("Invalid port session properties: %s", err)
+		log.Error(errorString)
+		output.MarkAsFailed(errorString)
+		return
+	}
+	host := parameters.Host
+	if host == "" {
+		host = localHost
+	}
+	if port, err := strconv.Atoi(parameters.PortNumber); err != nil || port < 1 || port > 65535 {
+		errorString := fmt.Errorf("Invalid port number %v", parameters.PortNumber)
+		log.Error(errorString)
+		output.MarkAsFailed(errorString)
+		return
+	}
+	if !isAllowedDestination(context.AppConfig().Mgs.PortForwardingAllowedDestinations, host, parameters.PortNumber) {
+		errorString := fmt.Errorf("Forwarding to %v is not allowed by the agent configuration", net.JoinHostPort(host, parameters.PortNumber))
+		log.Error(errorString)
+		output.MarkAsFailed(errorString)
+		return
+	}
+
+	p.Forward(context, net.JoinHostPort(host, parameters.PortNumber), cancelFlag, output, dataChannel)
+}
+
+// Forward connects to the address and forwards the data between the data channel and the address until either side
+// closes or the session is cancelled
+func (p *PortPlugin) Forward(context context.T,
+	address string,
+	cancelFlag task.CancelFlag,
+	output iohandler.IOHandler,
+	dataChannel datachannel.IDataChannel) {
+
+	log := context.Log()
+	p.dataChannel = dataChannel
+	defer p.stop(log)
+
+	conn, err := dialDestination(address)
+	if err != nil {
+		errorString := fmt.Errorf("Unable to connect to %v: %s", address, err)
+		log.Error(errorString)
+		output.MarkAsFailed(errorString)
+		return
+	}
+	p.setConn(conn)
+
+	cancelled := make(chan bool, 1)
+	go func() {
+		cancelState := cancelFlag.Wait()
+		if cancelFlag.Canceled() {
+			cancelled <- true
+			log.Debug("Cancel flag set to cancelled in session")
+		}
+		log.Debugf("Cancel flag set to %v in session", cancelState)
+	}()
+
+	log.Debugf("Start separate go routine to read from the destination and write to data channel")
+	done := make(chan int, 1)
+	go func() {
+		done <- p.readPump(log, conn)
+	}()
+
+	log.Infof("Forwarding to %v started", address)
+
+	select {
+	case <-cancelled:
+		log.Info("The session was cancelled")
+		output.SetExitCode(appconfig.SuccessExitCode)
+		output.SetStatus(agentContracts.ResultStatusSuccess)
+
+	case exitCode := <-done:
+		if exitCode == appconfig.ErrorExitCode {
+			output.SetExitCode(appconfig.ErrorExitCode)
+			output.SetStatus(agentContracts.ResultStatusFailed)
+		} else {
+			output.SetExitCode(appconfig.SuccessExitCode)
+			output.SetStatus(agentContracts.ResultStatusSuccess)
+		}
+	}
+	output.SetOutput(mgsContracts.SessionPluginResultOutput{})
+
+	log.Debug("Port session execution complete")
+}
+
+// readPump reads from the destination and writes to data channel.
+func (p *PortPlugin) readPump(log log.T, conn net.Conn) (errorCode int) {
+	buf := make([]byte, mgsConfig.StreamDataPayloadSize)
+	for {
+		n, err := conn.Read(buf)
+		if n > 0 {
+			if sendErr := p.dataChannel.SendStreamDataMessage(log, mgsContracts.Output, buf[:n]); sendErr != nil {
+				log.Errorf("Unable to send stream data message: %s", sendErr)
+				return appconfig.ErrorExitCode
+			}
+		}
+		if err != nil {
+			// Terminating session
+			log.Debugf("Failed to read from the destination: %s", err)
+			if err = p.dataChannel.SendAgentSessionStateMessage(log, mgsContracts.Terminating); err != nil {
+				log.Errorf("Unable to send AgentSessionState message with session status %s. %v", mgsContracts.Terminating, err)
+			}
+			return appconfig.SuccessExitCode
+		}
+	}
+}
+
+// InputStreamMessageHandler passes payload byte stream to the destination
+func (p *PortPlugin) InputStreamMessageHandler(log log.T, streamDataMessage mgsContracts.AgentMessage) error {
+	// The lock only guards the reference, a slow destination must not block stop while the payload is written
+	p.connLock.RLock()
+	conn := p.conn
+	p.connLock.RUnlock()
+
+	if conn == nil {
+		// The client resends the rejected packets until the connection to the destination is open
+		log.Tracef("Destination connection unavailable. Reject incoming message packet")
+		return nil
+	}
+
+	switch mgsContracts.PayloadType(streamDataMessage.PayloadType) {
+	case mgsContracts.Output:
+		log.Tracef("Output message received: %d", streamDataMessage.SequenceNumber)
+		if _, err := conn.Write(streamDataMessage.Payload); err != nil {
+			log.Errorf("Unable to write to the destination, err: %v.", err)
+			return err
+		}
+	case mgsContracts.Flag:
+		if len(streamDataMessage.Payload) != flagLength {
+			log.Warnf("Ignoring flag message %d with invalid payload length %d", streamDataMessage.SequenceNumber, len(streamDataMessage.Payload))
+			return nil
+		}
+		if mgsContracts.PayloadTypeFlag(binary.BigEndian.Uint32(streamDataMessage.Payload)) == mgsContracts.DisconnectToPort {
+			// The client has no more data to send, the destination can still send its remaining data back
+			log.Debugf("Client disconnected, closing the write side of the destination connection")
+			if halfClosable, ok := conn.(halfCloser); ok {
+				return halfClosable.CloseWrite()
+			}
+			return conn.Close()
+		}
+	}
+	return nil
+}
+
+// connected returns true once the connection to the destination is open
+func (p *PortPlugin) connected() bool {
+	p.connLock.RLock()
+	defer p.connLock.RUnlock()
+	return p.conn != nil
+}
+
+func (p *PortPlugin) setConn(conn net.Conn) {
+	p.connLock.Lock()
+	defer p.connLock.Unlock()
+	p.conn = conn
+}
+
+// stop closes the connection to the destination
+func (p *PortPlugin) stop(log log.T) {
+	p.connLock.Lock()
+	defer p.connLock.Unlock()
+
+	if p.conn == nil {
+		return
+	}
+	if err := p.conn.Close(); err != nil {
+		log.Debugf("Error occurred while closing the destination connection: %v", err)
+	}
+	p.conn = nil
+}
+
+// isAllowedDestination checks the destination is the instance itself or one of the allowed destinations
+func isAllowedDestination(allowedDestinations []string, host string, port string) bool {
+	if strings.EqualFold(host, localHost) {
+		return true
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return true
+	}
+	for _, destination := range allowedDestinations {
+		allowedHost, allowedPort, err := net.SplitHostPort(strings.TrimSpace(destination))
+		if err != nil {
+			continue
+		}
+		if strings.EqualFold(allowedHost, host) && (allowedPort == anyPort || allowedPort == port) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,248 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package port implements session port plugin, forwarding a port of the instance or of an allowed destination.
+package port
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	iohandlermocks "github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler/mock"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	mgsContracts "github.com/aws/amazon-ssm-agent/agent/session/contracts"
+	dataChannelMock "github.com/aws/amazon-ssm-agent/agent/session/datachannel/mocks"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type PortTestSuite struct {
+	suite.Suite
+	mockContext     *context.Mock
+	mockLog         log.T
+	mockCancelFlag  *task.MockCancelFlag
+	mockDataChannel *dataChannelMock.IDataChannel
+	mockIohandler   *iohandlermocks.MockIOHandler
+	plugin          *PortPlugin
+}
+
+func (suite *PortTestSuite) SetupTest() {
+	suite.mockLog = log.NewMockLog()
+	config := appconfig.SsmagentConfig{}
+	config.Mgs.PortForwardingAllowedDestinations = []string{"db.internal:5432", "10.0.0.5:*"}
+	suite.mockContext = new(context.Mock)
+	suite.mockContext.On("Log").Return(suite.mockLog)
+	suite.mockContext.On("AppConfig").Return(config)
+	suite.mockCancelFlag = &task.MockCancelFlag{}
+	suite.mockDataChannel = &dataChannelMock.IDataChannel{}
+	suite.mockIohandler = new(iohandlermocks.MockIOHandler)
+	suite.plugin = &PortPlugin{}
+}
+
+// Testing Name
+func (suite *PortTestSuite) TestName() {
+	assert.Equal(suite.T(), appconfig.PluginNamePort, suite.plugin.name())
+}
+
+// Testing Execute
+func (suite *PortTestSuite) TestExecuteWhenCancelFlagIsShutDown() {
+	suite.mockCancelFlag.On("ShutDown").Return(true)
+	suite.mockIohandler.On("MarkAsShutdown").Return(nil)
+
+	suite.plugin.Execute(suite.mockContext,
+		contracts.Configuration{},
+		suite.mockCancelFlag,
+		suite.mockIohandler,
+		suite.mockDataChannel)
+
+	suite.mockCancelFlag.AssertExpectations(suite.T())
+	suite.mockIohandler.AssertExpectations(suite.T())
+}
+
+// Testing Execute forwards the data both ways until the destination closes the connection
+func (suite *PortTestSuite) TestExecuteForwardsData() {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(suite.T(), err)
+	defer listener.Close()
+
+	var dialedAddress string
+	dialDestination = func(address string) (net.Conn, error) {
+		dialedAddress = address
+		return net.Dial("tcp", listener.Addr().String())
+	}
+	defer func() { dialDestination = dialTCP }()
+
+	suite.mockCancelFlag.On("ShutDown").Return(false)
+	suite.mockCancelFlag.On("Canceled").Return(false)
+	suite.mockCancelFlag.On("Wait").Return(task.Completed)
+	suite.mockDataChannel.On("SendStreamDataMessage", suite.mockLog, mgsContracts.Output, []byte("response")).Return(nil)
+	suite.mockDataChannel.On("SendAgentSessionStateMessage", suite.mockLog, mgsContracts.Terminating).Return(nil)
+	suite.mockIohandler.On("SetExitCode", appconfig.SuccessExitCode).Return()
+	suite.mockIohandler.On("SetStatus", contracts.ResultStatusSuccess).Return()
+	suite.mockIohandler.On("SetOutput", mock.Anything).Return()
+
+	// the destination answers the request and closes the connection
+	received := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			received <- ""
+			return
+		}
+		defer conn.Close()
+		request := make([]byte, len("request"))
+		io.ReadFull(conn, request)
+		received <- string(request)
+		conn.Write([]byte("response"))
+	}()
+
+	// the client sends the request once the destination is connected
+	go func() {
+		for !suite.plugin.connected() {
+			time.Sleep(10 * time.Millisecond)
+		}
+		suite.plugin.InputStreamMessageHandler(suite.mockLog, mgsContracts.AgentMessage{
+			PayloadType: uint32(mgsContracts.Output),
+			Payload:     []byte("request"),
+		})
+	}()
+
+	suite.plugin.Execute(suite.mockContext,
+		contracts.Configuration{Properties: map[string]interface{}{"portNumber": "5432", "host": "db.internal"}},
+		suite.mockCancelFlag,
+		suite.mockIohandler,
+		suite.mockDataChannel)
+
+	assert.Equal(suite.T(), "db.internal:5432", dialedAddress)
+	assert.Equal(suite.T(), "request", <-received)
+	suite.mockDataChannel.AssertExpectations(suite.T())
+	suite.mockIohandler.AssertExpectations(suite.T())
+}
+
+// Testing Execute fails the session when the destination is not allowed
+func (suite *PortTestSuite) TestExecuteRejectsDestination() {
+	dialDestination = func(address string) (net.Conn, error) {
+		assert.Fail(suite.T(), "the destination shouldn't be dialed")
+		return nil, nil
+	}
+	defer func() { dialDestination = dialTCP }()
+
+	suite.mockCancelFlag.On("ShutDown").Return(false)
+	suite.mockCancelFlag.On("Canceled").Return(false)
+	suite.mockIohandler.On("MarkAsFailed", mock.Anything).Return()
+
+	suite.plugin.Execute(suite.mockContext,
+		contracts.Configuration{Properties: map[string]interface{}{"portNumber": "22", "host": "db.internal"}},
+		suite.mockCancelFlag,
+		suite.mockIohandler,
+		suite.mockDataChannel)
+
+	suite.mockIohandler.AssertExpectations(suite.T())
+}
+
+// Testing InputStreamMessageHandler rejects the packets until the destination is connected
+func (suite *PortTestSuite) TestInputStreamMessageHandlerWithoutConnection() {
+	err := suite.plugin.InputStreamMessageHandler(suite.mockLog, mgsContracts.AgentMessage{
+		PayloadType: uint32(mgsContracts.Output),
+		Payload:     []byte("request"),
+	})
+	assert.Nil(suite.T(), err)
+}
+
+// Testing InputStreamMessageHandler closes the write side of the destination connection once the client disconnects
+func (suite *PortTestSuite) TestInputStreamMessageHandlerHalfClosesOnDisconnect() {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(suite.T(), err)
+	defer listener.Close()
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	assert.Nil(suite.T(), err)
+	destination, err := listener.Accept()
+	assert.Nil(suite.T(), err)
+	defer destination.Close()
+	suite.plugin.setConn(conn)
+	defer suite.plugin.stop(suite.mockLog)
+
+	flag := make([]byte, 4)
+	binary.BigEndian.PutUint32(flag, uint32(mgsContracts.DisconnectToPort))
+	err = suite.plugin.InputStreamMessageHandler(suite.mockLog, mgsContracts.AgentMessage{
+		PayloadType: uint32(mgsContracts.Flag),
+		Payload:     flag,
+	})
+	assert.Nil(suite.T(), err)
+
+	// the destination reads the end of the stream and can still answer
+	_, err = destination.Read(make([]byte, 1))
+	assert.Equal(suite.T(), io.EOF, err)
+	destination.Write([]byte("response"))
+	response := make([]byte, len("response"))
+	_, err = io.ReadFull(conn, response)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "response", string(response))
+}
+
+// Testing stop closes the destination connection while a write to a destination which doesn't read is blocked
+func (suite *PortTestSuite) TestStopDuringBlockedWrite() {
+	// writes to a pipe block until the other end reads
+	conn, destination := net.Pipe()
+	defer destination.Close()
+	suite.plugin.setConn(conn)
+
+	written := make(chan error, 1)
+	go func() {
+		written <- suite.plugin.InputStreamMessageHandler(suite.mockLog, mgsContracts.AgentMessage{
+			PayloadType: uint32(mgsContracts.Output),
+			Payload:     []byte("request"),
+		})
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	stopped := make(chan struct{})
+	go func() {
+		suite.plugin.stop(suite.mockLog)
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		assert.Fail(suite.T(), "stop is blocked by the write")
+	}
+	assert.NotNil(suite.T(), <-written)
+	assert.False(suite.T(), suite.plugin.connected())
+}
+
+func TestIsAllowedDestination(t *testing.T) {
+	allowed := []string{"db.internal:5432", "10.0.0.5:*", "invalid"}
+
+	assert.True(t, isAllowedDestination(allowed, "localhost", "22"))
+	assert.True(t, isAllowedDestination(allowed, "127.0.0.1", "3389"))
+	assert.True(t, isAllowedDestination(allowed, "::1", "80"))
+	assert.True(t, isAllowedDestination(allowed, "DB.internal", "5432"))
+	assert.True(t, isAllowedDestination(allowed, "10.0.0.5", "1433"))
+	assert.False(t, isAllowedDestination(allowed, "db.internal", "22"))
+	assert.False(t, isAllowedDestination(allowed, "10.0.0.6", "1433"))
+	assert.False(t, isAllowedDestination(nil, "db.internal", "5432"))
+}
+
+// Execute the test suite
+func TestPortTestSuite(t *testing.T) {
+	suite.Run(t, new(PortTestSuite))
+}
@@ -0,0 +1,129 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package sessionplugin implements functionality common to all session manager plugins
+package sessionplugin
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	mgsContracts "github.com/aws/amazon-ssm-agent/agent/session/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/session/datachannel"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+)
+
+// ISessionHandler interface represents a session type registered outside of the core session plugins.
+// The agent sets up the data channel of the session, the handler only deals with the data of its session type.
+type ISessionHandler interface {
+	// Name returns the plugin name of the session documents of the session type
+	Name() string
+	// Open runs the session over the data channel and sets its result in output, it returns once the session ends
+	Open(context context.T, config contracts.Configuration, cancelFlag task.CancelFlag, output iohandler.IOHandler, dataChannel datachannel.IDataChannel)
+	// HandleStream handles the stream data messages sent by the client, while the session is open
+	HandleStream(log log.T, streamDataMessage mgsContracts.AgentMessage) error
+	// Close releases the resources of the session once it ends, whatever the way it ends
+	Close(log log.T)
+}
+
+// NewSessionHandlerFunc returns a new handler for each session of the session type
+type NewSessionHandlerFunc func() (ISessionHandler, error)
+
+var (
+	sessionHandlersLock sync.RWMutex
+	sessionHandlers     = map[string]NewSessionHandlerFunc{}
+)
+
+// RegisterSessionHandler registers a session type, the agent runs the sessions of the documents with the name of the
+// handler through it. Forks call it from the init function of their package to add session types.
+func RegisterSessionHandler(newHandler NewSessionHandlerFunc) error {
+	handler, err := newHandler()
+	if err != nil {
+		return fmt.Errorf("unable to create the session handler: %v", err)
+	}
+	name := handler.Name()
+	if name == "" {
+		return fmt.Errorf("the session handler has no name")
+	}
+
+	sessionHandlersLock.Lock()
+	defer sessionHandlersLock.Unlock()
+	if _, exists := sessionHandlers[name]; exists {
+		return fmt.Errorf("a session handler is already registered for %v", name)
+	}
+	sessionHandlers[name] = newHandler
+	return nil
+}
+
+// IsRegisteredSessionHandler returns true when a session handler is registered for the plugin name
+func IsRegisteredSessionHandler(name string) bool {
+	sessionHandlersLock.RLock()
+	defer sessionHandlersLock.RUnlock()
+	_, exists := sessionHandlers[name]
+	return exists
+}
+
+// RegisteredSessionHandlers returns the plugins of the registered session handlers by plugin name
+func RegisteredSessionHandlers() map[string]NewPluginFunc {
+	sessionHandlersLock.RLock()
+	defer sessionHandlersLock.RUnlock()
+
+	plugins := make(map[string]NewPluginFunc, len(sessionHandlers))
+	for name, newHandler := range sessionHandlers {
+		plugins[name] = newHandlerPluginFunc(newHandler)
+	}
+	return plugins
+}
+
+// sessionHandlerPlugin adapts a session handler to the session plugins run by SessionPlugin
+type sessionHandlerPlugin struct {
+	handler ISessionHandler
+}
+
+// newHandlerPluginFunc returns the function creating the session plugins of the handler
+func newHandlerPluginFunc(newHandler NewSessionHandlerFunc) NewPluginFunc {
+	return func() (ISessionPlugin, error) {
+		handler, err := newHandler()
+		if err != nil {
+			return nil, err
+		}
+		return &sessionHandlerPlugin{handler: handler}, nil
+	}
+}
+
+// Execute opens the session of the handler and closes it once it ends
+func (p *sessionHandlerPlugin) Execute(context context.T,
+	config contracts.Configuration,
+	cancelFlag task.CancelFlag,
+	output iohandler.IOHandler,
+	dataChannel datachannel.IDataChannel) {
+
+	defer p.handler.Close(context.Log())
+
+	if cancelFlag.ShutDown() {
+		output.MarkAsShutdown()
+	} else if cancelFlag.Canceled() {
+		output.MarkAsCancelled()
+	} else {
+		p.handler.Open(context, config, cancelFlag, output, dataChannel)
+	}
+}
+
+// InputStreamMessageHandler passes the stream data messages to the handler
+func (p *sessionHandlerPlugin) InputStreamMessageHandler(log log.T, streamDataMessage mgsContracts.AgentMessage) error {
+	return p.handler.HandleStream(log, streamDataMessage)
+}
@@ -0,0 +1,92 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package sessionplugin implements functionalities common to all session manager plugins
+package sessionplugin
+
+import (
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler"
+	iohandlerMock "github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler/mock"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	mgsContracts "github.com/aws/amazon-ssm-agent/agent/session/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/session/datachannel"
+	dataChannelMock "github.com/aws/amazon-ssm-agent/agent/session/datachannel/mocks"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+	"github.com/stretchr/testify/assert"
+)
+
+// echoHandler is a session handler sending back the stream data of the client
+type echoHandler struct {
+	dataChannel datachannel.IDataChannel
+	closed      bool
+}
+
+func (h *echoHandler) Name() string {
+	return "Echo"
+}
+
+func (h *echoHandler) Open(context context.T, config contracts.Configuration, cancelFlag task.CancelFlag, output iohandler.IOHandler, dataChannel datachannel.IDataChannel) {
+	h.dataChannel = dataChannel
+	output.MarkAsSucceeded()
+}
+
+func (h *echoHandler) HandleStream(log log.T, streamDataMessage mgsContracts.AgentMessage) error {
+	return h.dataChannel.SendStreamDataMessage(log, mgsContracts.Output, streamDataMessage.Payload)
+}
+
+func (h *echoHandler) Close(log log.T) {
+	h.closed = true
+}
+
+func TestRegisterSessionHandler(t *testing.T) {
+	defer func() { sessionHandlers = map[string]NewSessionHandlerFunc{} }()
+
+	var handler *echoHandler
+	newHandler := func() (ISessionHandler, error) {
+		handler = &echoHandler{}
+		return handler, nil
+	}
+	assert.Nil(t, RegisterSessionHandler(newHandler))
+	assert.NotNil(t, RegisterSessionHandler(newHandler))
+	assert.True(t, IsRegisteredSessionHandler("Echo"))
+	assert.False(t, IsRegisteredSessionHandler("Standard_Stream"))
+
+	newPlugin, registered := RegisteredSessionHandlers()["Echo"]
+	assert.True(t, registered)
+	plugin, err := newPlugin()
+	assert.Nil(t, err)
+
+	mockContext := context.NewMockDefault()
+	mockCancelFlag := &task.MockCancelFlag{}
+	mockCancelFlag.On("ShutDown").Return(false)
+	mockCancelFlag.On("Canceled").Return(false)
+	mockIohandler := new(iohandlerMock.MockIOHandler)
+	mockIohandler.On("MarkAsSucceeded").Return()
+	mockDataChannel := &dataChannelMock.IDataChannel{}
+	mockDataChannel.On("SendStreamDataMessage", mockContext.Log(), mgsContracts.Output, []byte("ping")).Return(nil)
+
+	plugin.Execute(mockContext, contracts.Configuration{}, mockCancelFlag, mockIohandler, mockDataChannel)
+	err = plugin.InputStreamMessageHandler(mockContext.Log(), mgsContracts.AgentMessage{
+		PayloadType: uint32(mgsContracts.Output),
+		Payload:     []byte("ping"),
+	})
+
+	assert.Nil(t, err)
+	assert.True(t, handler.closed)
+	mockIohandler.AssertExpectations(t)
+	mockDataChannel.AssertExpectations(t)
+}
@@ -0,0 +1,57 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package sessionplugin implements functionality common to all session manager plugins
+package sessionplugin
+
+import (
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	mgsContracts "github.com/aws/amazon-ssm-agent/agent/session/contracts"
+)
+
+// sessionHookTimeout is the time a hook script is given to handle the opening or closing of a session
+const sessionHookTimeout = 10 * time.Second
+
+// runSessionHook is assigned to a variable to allow unit tests to override it
+var runSessionHook = runSessionHookScript
+
+// runSessionHookScript executes the hook script of the session, empty scripts are skipped.
+// The session waits for the script, so the changes it makes to the host are in place before the session goes on.
+func runSessionHookScript(log log.T, script string, config contracts.Configuration) {
+	if script == "" {
+		return
+	}
+
+	cmd := exec.Command(script)
+	cmd.Env = append(os.Environ(),
+		"SSM_SESSION_ID="+config.SessionId,
+		"SSM_SESSION_USER="+mgsContracts.SessionUser(config.SessionId),
+		"SSM_SESSION_TYPE="+config.PluginName)
+	if err := cmd.Start(); err != nil {
+		log.Warnf("Failed to run session hook script %v, %v", script, err)
+		return
+	}
+
+	timer := time.AfterFunc(sessionHookTimeout, func() {
+		cmd.Process.Kill()
+	})
+	defer timer.Stop()
+	if err := cmd.Wait(); err != nil {
+		log.Warnf("Session hook script %v failed, %v", script, err)
+	}
+}
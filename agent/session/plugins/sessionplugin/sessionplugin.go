@@ -71,7 +71,7 @@ func (p *SessionPlugin) Execute(context context.T,
 	}
 
 	if p.isEncryptionEnabled(kmsKeyId) {
-		if err = dataChannel.PerformHandshake(log, kmsKeyId); err != nil {
+		if err = dataChannel.PerformHandshake(log, kmsKeyId, config.PluginName); err != nil {
 			errorString := fmt.Errorf("Encountered error while initiating handshake. %s", err)
 			output.MarkAsFailed(errorString)
 			log.Error(errorString)
@@ -81,6 +81,10 @@ func (p *SessionPlugin) Execute(context context.T,
 		dataChannel.SkipHandshake(log)
 	}
 
+	hooks := context.AppConfig().Mgs
+	runSessionHook(log, hooks.SessionOpenHookScript, config)
+	defer runSessionHook(log, hooks.SessionCloseHookScript, config)
+
 	p.sessionPlugin.Execute(context, config, cancelFlag, output, dataChannel)
 }
 
@@ -91,6 +95,7 @@ func (p *SessionPlugin) isEncryptionEnabled(kmsKeyId string) bool {
 
 // getDataChannelForSessionPlugin opens new data channel to MGS service
 var getDataChannelForSessionPlugin = func(context context.T, sessionId string, clientId string, cancelFlag task.CancelFlag, inputStreamMessageHandler datachannel.InputStreamMessageHandler) (datachannel.IDataChannel, error) {
+	config := context.AppConfig().Mgs
 	retryer := retry.ExponentialRetryer{
 		CallableFunc: func() (channel interface{}, err error) {
 			return datachannel.NewDataChannel(
@@ -101,8 +106,8 @@ var getDataChannelForSessionPlugin = func(context context.T, sessionId string, c
 				cancelFlag)
 		},
 		GeometricRatio:      mgsConfig.RetryGeometricRatio,
-		InitialDelayInMilli: rand.Intn(mgsConfig.DataChannelRetryInitialDelayMillis) + mgsConfig.DataChannelRetryInitialDelayMillis,
-		MaxDelayInMilli:     mgsConfig.DataChannelRetryMaxIntervalMillis,
+		InitialDelayInMilli: rand.Intn(config.DataChannelRetryInitialDelayMillis) + config.DataChannelRetryInitialDelayMillis,
+		MaxDelayInMilli:     config.DataChannelRetryMaxIntervalMillis,
 		MaxAttempts:         mgsConfig.DataChannelNumMaxAttempts,
 	}
 	channel, err := retryer.Call()
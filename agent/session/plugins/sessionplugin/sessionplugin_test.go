@@ -18,6 +18,7 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
 	"github.com/aws/amazon-ssm-agent/agent/context"
 	"github.com/aws/amazon-ssm-agent/agent/contracts"
 	iohandlerMock "github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler/mock"
@@ -89,9 +90,9 @@ func (suite *SessionPluginTestSuite) TestExecuteEncryptionHandshakeSuccess() {
 	suite.mockSessionPlugin.On("Execute", suite.mockContext, mock.Anything, suite.mockCancelFlag, suite.mockIohandler, suite.mockDataChannel).Return()
 
 	kmsKey := "some-key"
-	suite.mockDataChannel.On("PerformHandshake", suite.mockContext.Log(), kmsKey).Return(nil)
+	suite.mockDataChannel.On("PerformHandshake", suite.mockContext.Log(), kmsKey, appconfig.PluginNameStandardStream).Return(nil)
 	suite.sessionPlugin.Execute(suite.mockContext,
-		contracts.Configuration{KmsKeyId: kmsKey},
+		contracts.Configuration{KmsKeyId: kmsKey, PluginName: appconfig.PluginNameStandardStream},
 		suite.mockCancelFlag,
 		suite.mockIohandler)
 
@@ -109,13 +110,47 @@ func (suite *SessionPluginTestSuite) TestExecuteEncryptionHandshakeFailed() {
 
 	kmsKey := "some-key"
 	error := errors.New("handshake failure")
-	suite.mockDataChannel.On("PerformHandshake", suite.mockContext.Log(), kmsKey).Return(error)
+	suite.mockDataChannel.On("PerformHandshake", suite.mockContext.Log(), kmsKey, appconfig.PluginNameStandardStream).Return(error)
 	suite.mockIohandler.On("MarkAsFailed", mock.Anything).Return()
 	suite.sessionPlugin.Execute(suite.mockContext,
-		contracts.Configuration{KmsKeyId: kmsKey},
+		contracts.Configuration{KmsKeyId: kmsKey, PluginName: appconfig.PluginNameStandardStream},
 		suite.mockCancelFlag,
 		suite.mockIohandler)
 
 	suite.mockDataChannel.AssertExpectations(suite.T())
 	suite.mockSessionPlugin.AssertExpectations(suite.T())
 }
+
+func (suite *SessionPluginTestSuite) TestExecuteRunsSessionHooks() {
+	defer func() { runSessionHook = runSessionHookScript }()
+	var scripts []string
+	runSessionHook = func(log log.T, script string, config contracts.Configuration) {
+		suite.Equal("session-id", config.SessionId)
+		scripts = append(scripts, script)
+	}
+	getDataChannelForSessionPlugin =
+		func(context context.T, sessionId string, clientId string, cancelFlag task.CancelFlag, inputStreamMessageHandler datachannel.InputStreamMessageHandler) (datachannel.IDataChannel, error) {
+			return suite.mockDataChannel, nil
+		}
+	mockContext := new(context.Mock)
+	mockContext.On("Log").Return(suite.mockLog)
+	mockContext.On("AppConfig").Return(appconfig.SsmagentConfig{
+		Mgs: appconfig.MgsConfig{SessionOpenHookScript: "open.sh", SessionCloseHookScript: "close.sh"},
+	})
+	suite.mockDataChannel.On("SendAgentSessionStateMessage", suite.mockLog, mgsContracts.Connected).Return(nil)
+	suite.mockDataChannel.On("Close", suite.mockLog).Return(nil)
+	suite.mockDataChannel.On("SkipHandshake", suite.mockLog).Return()
+	suite.mockSessionPlugin.On("Execute", mockContext, mock.Anything, suite.mockCancelFlag, suite.mockIohandler, suite.mockDataChannel).
+		Run(func(args mock.Arguments) {
+			// the session runs once the open hook is done, the close hook runs once it ends
+			suite.Equal([]string{"open.sh"}, scripts)
+		}).Return()
+
+	suite.sessionPlugin.Execute(mockContext,
+		contracts.Configuration{SessionId: "session-id"},
+		suite.mockCancelFlag,
+		suite.mockIohandler)
+
+	suite.Equal([]string{"open.sh", "close.sh"}, scripts)
+	suite.mockSessionPlugin.AssertExpectations(suite.T())
+}
@@ -0,0 +1,89 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package shell implements session shell plugin.
+package shell
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// maxIdleWarning is how long before the idle timeout the client is warned the session will be terminated
+	maxIdleWarning     = time.Minute
+	idleWarningMessage = "\r\nThe session has been idle and will be terminated in %v unless there is some input.\r\n"
+	idleReasonMessage  = "The session was terminated after %v without input"
+)
+
+// idleCheckInterval is assigned to a variable to allow unit tests to override it
+var idleCheckInterval = 5 * time.Second
+
+// idleMonitor tracks the input of an interactive session to terminate it once it gets no input for too long
+type idleMonitor struct {
+	// lastInput is the time of the last input in unix nanoseconds, accessed atomically
+	lastInput int64
+	timeout   time.Duration
+	warning   time.Duration
+}
+
+// newIdleMonitor returns the monitor of a session which starts idle, the client is warned the shortest of a minute and
+// half the timeout before the session is terminated
+func newIdleMonitor(timeout time.Duration) *idleMonitor {
+	warning := maxIdleWarning
+	if timeout/2 < warning {
+		warning = timeout / 2
+	}
+	return &idleMonitor{lastInput: time.Now().UnixNano(), timeout: timeout, warning: warning}
+}
+
+// touch records the session got some input, a nil monitor tracks nothing
+func (m *idleMonitor) touch() {
+	if m == nil {
+		return
+	}
+	atomic.StoreInt64(&m.lastInput, time.Now().UnixNano())
+}
+
+// idleFor returns how long the session has been idle
+func (m *idleMonitor) idleFor() time.Duration {
+	return time.Since(time.Unix(0, atomic.LoadInt64(&m.lastInput)))
+}
+
+// wait returns true once the session has been idle for the timeout, or false once stop is closed.
+// It calls warn with the time left once the session is about to time out, again after each new idle period.
+func (m *idleMonitor) wait(stop <-chan struct{}, warn func(remaining time.Duration)) bool {
+	ticker := time.NewTicker(idleCheckInterval)
+	defer ticker.Stop()
+
+	warned := false
+	for {
+		select {
+		case <-stop:
+			return false
+		case <-ticker.C:
+			idle := m.idleFor()
+			switch {
+			case idle >= m.timeout:
+				return true
+			case idle >= m.timeout-m.warning:
+				if !warned {
+					warn(m.timeout - idle)
+					warned = true
+				}
+			default:
+				warned = false
+			}
+		}
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package shell implements session shell plugin.
+package shell
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func stubIdleCheckInterval(interval time.Duration) func() {
+	original := idleCheckInterval
+	idleCheckInterval = interval
+	return func() { idleCheckInterval = original }
+}
+
+func TestNewIdleMonitorWarning(t *testing.T) {
+	assert.Equal(t, time.Minute, newIdleMonitor(20*time.Minute).warning)
+	assert.Equal(t, 30*time.Second, newIdleMonitor(time.Minute).warning)
+}
+
+func TestIdleMonitorWarnsThenTimesOut(t *testing.T) {
+	defer stubIdleCheckInterval(5 * time.Millisecond)()
+	monitor := newIdleMonitor(200 * time.Millisecond)
+
+	warnings := 0
+	start := time.Now()
+	timedOut := monitor.wait(make(chan struct{}), func(remaining time.Duration) {
+		warnings++
+		assert.True(t, remaining <= monitor.warning)
+	})
+
+	assert.True(t, timedOut)
+	assert.Equal(t, 1, warnings)
+	assert.True(t, time.Since(start) >= 200*time.Millisecond)
+}
+
+func TestIdleMonitorInputResetsTimeout(t *testing.T) {
+	defer stubIdleCheckInterval(5 * time.Millisecond)()
+	monitor := newIdleMonitor(200 * time.Millisecond)
+
+	stop := make(chan struct{})
+	result := make(chan bool, 1)
+	go func() {
+		result <- monitor.wait(stop, func(remaining time.Duration) {})
+	}()
+	for i := 0; i < 5; i++ {
+		time.Sleep(50 * time.Millisecond)
+		monitor.touch()
+	}
+	close(stop)
+
+	assert.False(t, <-result)
+}
+
+func TestNilIdleMonitorTouch(t *testing.T) {
+	var monitor *idleMonitor
+	monitor.touch()
+}
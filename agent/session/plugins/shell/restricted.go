@@ -0,0 +1,380 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package shell implements session shell plugin.
+package shell
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	agentContracts "github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/fileutil"
+	"github.com/aws/amazon-ssm-agent/agent/jsonutil"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	mgsConfig "github.com/aws/amazon-ssm-agent/agent/session/config"
+	mgsContracts "github.com/aws/amazon-ssm-agent/agent/session/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/session/datachannel"
+	"github.com/aws/amazon-ssm-agent/agent/times"
+)
+
+const (
+	restrictedBanner          = "This session is restricted to the commands allowed by the agent configuration.\r\n"
+	restrictedPrompt          = "$ "
+	restrictedRejectedMessage = "%s: the command is not allowed on this host\r\n"
+	restrictedFailedMessage   = "%s: unable to run the command: %v\r\n"
+	restrictedExitCommand     = "exit"
+	restrictedAuditFileName   = "restrictedcommands.jsonl"
+	// maxQueuedLines is the number of command lines the client can type ahead of the running command
+	maxQueuedLines = 64
+	// shellMetacharacters make the shell run more than the words of the command line: separators, pipes,
+	// redirections, substitutions, quoting, globbing and the variables and escapes of sh, cmd and PowerShell
+	shellMetacharacters = ";&|<>()$`\\\"'{}[]*?~#!%@^"
+
+	// control characters handled by the line editor of the restricted sessions
+	ctrlC     = 0x03
+	ctrlD     = 0x04
+	backspace = 0x08
+	escape    = 0x1b
+	del       = 0x7f
+)
+
+// commandAllowlist holds the commands the restricted sessions may run
+type commandAllowlist struct {
+	commands []string
+	patterns []*regexp.Regexp
+}
+
+// commandAudit is the local audit record of a command of a restricted session
+type commandAudit struct {
+	Time      string
+	SessionId string
+	ClientId  string
+	Command   string
+	Allowed   bool
+}
+
+// auditCommand is assigned to a variable to allow unit tests to override it
+var auditCommand = appendCommandAudit
+
+// restrictedSessions returns true when the agent configuration restricts the shell sessions to an allowlist
+func restrictedSessions(config appconfig.MgsConfig) bool {
+	return len(config.RestrictedSessionAllowedCommands) > 0 || len(config.RestrictedSessionAllowedPatterns) > 0
+}
+
+// newCommandAllowlist returns the allowlist of the agent configuration, the invalid patterns allow nothing
+func newCommandAllowlist(log log.T, config appconfig.MgsConfig) *commandAllowlist {
+	allowlist := &commandAllowlist{}
+	for _, command := range config.RestrictedSessionAllowedCommands {
+		if command = strings.TrimSpace(command); command != "" {
+			allowlist.commands = append(allowlist.commands, command)
+		}
+	}
+	for _, pattern := range config.RestrictedSessionAllowedPatterns {
+		// the patterns match the whole command, and the commands matched by a pattern can't contain shell
+		// metacharacters since the allowed command runs through the shell: ls .* mustn't allow ls; rm -rf /
+		expression, err := regexp.Compile("^(?:" + pattern + ")$")
+		if err != nil {
+			log.Errorf("Ignoring invalid restricted session pattern %v: %v", pattern, err)
+			continue
+		}
+		allowlist.patterns = append(allowlist.patterns, expression)
+	}
+	return allowlist
+}
+
+// allows returns true when the command is one of the allowed commands, or matches one of the allowed patterns
+// without shell metacharacters
+func (a *commandAllowlist) allows(command string) bool {
+	command = strings.TrimSpace(command)
+	for _, allowed := range a.commands {
+		if command == allowed {
+			return true
+		}
+	}
+	if strings.ContainsAny(command, shellMetacharacters) {
+		return false
+	}
+	for _, pattern := range a.patterns {
+		if pattern.MatchString(command) {
+			return true
+		}
+	}
+	return false
+}
+
+// audit checks the command of the session against the allowlist and keeps a local record of the decision
+func (a *commandAllowlist) audit(log log.T, instanceID string, config agentContracts.Configuration, command string) bool {
+	allowed := a.allows(command)
+	if allowed {
+		log.Infof("Restricted session %v runs the allowed command %q", config.SessionId, command)
+	} else {
+		log.Warnf("Restricted session %v rejected the command %q", config.SessionId, command)
+	}
+	record := commandAudit{
+		Time:      times.ToIso8601UTC(time.Now()),
+		SessionId: config.SessionId,
+		ClientId:  config.ClientId,
+		Command:   command,
+		Allowed:   allowed,
+	}
+	if err := auditCommand(instanceID, record); err != nil {
+		log.Errorf("Failed to audit the command of restricted session %v: %v", config.SessionId, err)
+	}
+	return allowed
+}
+
+// appendCommandAudit appends the record to the audit file of the restricted sessions of the instance
+func appendCommandAudit(instanceID string, record commandAudit) error {
+	content, err := jsonutil.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	location := filepath.Join(appconfig.DefaultDataStorePath, instanceID, appconfig.DefaultSessionRootDirName, appconfig.SessionAuditRootDirName)
+	if err = fileutil.MakeDirs(location); err != nil {
+		return fmt.Errorf("cannot make directory of %v because: %v", location, err)
+	}
+	file, err := os.OpenFile(filepath.Join(location, restrictedAuditFileName), os.O_APPEND|os.O_CREATE|os.O_WRONLY, os.FileMode(int(appconfig.ReadWriteAccess)))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.WriteString(content + "\n")
+	return err
+}
+
+// lineEditor echoes the input of a restricted session and gathers it into command lines
+type lineEditor struct {
+	line []byte
+	// escapeState skips the escape sequences of the terminal, like the arrow keys: 1 after ESC, 2 inside a sequence
+	escapeState int
+}
+
+// input returns the echo of the input for the client and the command lines it completes.
+// Ctrl-C discards the current line and Ctrl-D on an empty line exits the session.
+func (e *lineEditor) input(payload []byte) (echo []byte, lines []string) {
+	var buf bytes.Buffer
+	for _, b := range payload {
+		switch {
+		case e.escapeState == 1:
+			e.escapeState = 0
+			if b == '[' || b == 'O' {
+				e.escapeState = 2
+			}
+		case e.escapeState == 2:
+			if b >= 0x40 && b <= 0x7e {
+				e.escapeState = 0
+			}
+		case b == escape:
+			e.escapeState = 1
+		case b == '\r' || b == '\n':
+			buf.WriteString("\r\n")
+			lines = append(lines, string(e.line))
+			e.line = e.line[:0]
+		case b == backspace || b == del:
+			if len(e.line) > 0 {
+				_, size := utf8.DecodeLastRune(e.line)
+				e.line = e.line[:len(e.line)-size]
+				buf.WriteString("\b \b")
+			}
+		case b == ctrlC:
+			buf.WriteString("^C\r\n")
+			lines = append(lines, "")
+			e.line = e.line[:0]
+		case b == ctrlD:
+			if len(e.line) == 0 {
+				buf.WriteString("\r\n")
+				lines = append(lines, restrictedExitCommand)
+			}
+		case b < 0x20:
+			// the other control characters have no meaning outside of a shell
+		default:
+			buf.WriteByte(b)
+			e.line = append(e.line, b)
+		}
+	}
+	return buf.Bytes(), lines
+}
+
+// restrictedSession runs the command lines typed by the client on their own once the allowlist allows them.
+// The client types the lines to the agent, the commands only get the input of the client while they run.
+type restrictedSession struct {
+	allowlist  *commandAllowlist
+	instanceID string
+	editor     lineEditor
+	lines      chan string
+	// done is closed once the session ends
+	done chan struct{}
+	// commandLock guards the stdin of the running command and the size of the terminal of the client
+	commandLock sync.Mutex
+	command     *os.File
+	size        *mgsContracts.SizeData
+}
+
+func newRestrictedSession(allowlist *commandAllowlist, instanceID string) *restrictedSession {
+	return &restrictedSession{
+		allowlist:  allowlist,
+		instanceID: instanceID,
+		lines:      make(chan string, maxQueuedLines),
+		done:       make(chan struct{}),
+	}
+}
+
+// input passes the input of the client to the running command, or to the line editor between the commands
+func (s *restrictedSession) input(log log.T, dataChannel datachannel.IDataChannel, payload []byte) error {
+	s.commandLock.Lock()
+	if s.command != nil {
+		defer s.commandLock.Unlock()
+		if _, err := s.command.Write(payload); err != nil {
+			log.Errorf("Unable to write to stdin, err: %v.", err)
+			return err
+		}
+		return nil
+	}
+	echo, lines := s.editor.input(payload)
+	s.commandLock.Unlock()
+
+	if len(echo) > 0 {
+		if err := dataChannel.SendStreamDataMessage(log, mgsContracts.Output, echo); err != nil {
+			return err
+		}
+	}
+	for _, line := range lines {
+		select {
+		case s.lines <- line:
+		case <-s.done:
+			return nil
+		}
+	}
+	return nil
+}
+
+// setSize resizes the terminal of the running command, the next commands start with the size
+func (s *restrictedSession) setSize(log log.T, size mgsContracts.SizeData) error {
+	s.commandLock.Lock()
+	defer s.commandLock.Unlock()
+	s.size = &size
+	if s.command == nil {
+		return nil
+	}
+	return SetSize(log, size.Cols, size.Rows)
+}
+
+// setCommand sets the stdin of the running command, nil once it exits
+func (s *restrictedSession) setCommand(log log.T, stdin *os.File) {
+	s.commandLock.Lock()
+	defer s.commandLock.Unlock()
+	s.command = stdin
+	if stdin != nil && s.size != nil {
+		if err := SetSize(log, s.size.Cols, s.size.Rows); err != nil {
+			log.Warnf("Unable to set pty size: %s", err)
+		}
+	}
+}
+
+// restrictedPump runs the command lines of a restricted session until the client exits and writes the output to
+// the data channel, it replaces writePump for the restricted sessions.
+func (p *ShellPlugin) restrictedPump(log log.T, config agentContracts.Configuration, shell string) (errorCode int) {
+	// Create ipc file
+	file, err := os.Create(p.ipcFilePath)
+	if err != nil {
+		log.Errorf("Encountered an error while creating file: %s", err)
+		return appconfig.ErrorExitCode
+	}
+	defer file.Close()
+
+	if err = p.sendRestrictedOutput(log, file, restrictedBanner+restrictedPrompt); err != nil {
+		log.Errorf("Error processing stdout data, %v", err)
+		return appconfig.ErrorExitCode
+	}
+	for {
+		var line string
+		select {
+		case <-p.restricted.done:
+			return appconfig.SuccessExitCode
+		case line = <-p.restricted.lines:
+		}
+
+		command := strings.TrimSpace(line)
+		if _, err = file.WriteString(line + "\r\n"); err != nil {
+			log.Errorf("Encountered an error while writing to file: %s", err)
+			return appconfig.ErrorExitCode
+		}
+		switch {
+		case command == restrictedExitCommand:
+			if err = p.dataChannel.SendAgentSessionStateMessage(log, mgsContracts.Terminating); err != nil {
+				log.Errorf("Unable to send AgentSessionState message with session status %s. %v", mgsContracts.Terminating, err)
+			}
+			return appconfig.SuccessExitCode
+		case command == "":
+		case !p.restricted.allowlist.audit(log, p.restricted.instanceID, config, command):
+			err = p.sendRestrictedOutput(log, file, fmt.Sprintf(restrictedRejectedMessage, command))
+		default:
+			err = p.runRestrictedCommand(log, config, file, command, shell)
+		}
+		if err == nil {
+			err = p.sendRestrictedOutput(log, file, restrictedPrompt)
+		}
+		if err != nil {
+			log.Errorf("Error processing stdout data, %v", err)
+			return appconfig.ErrorExitCode
+		}
+	}
+}
+
+// runRestrictedCommand runs the allowed command in its own pty and writes its output until it exits
+func (p *ShellPlugin) runRestrictedCommand(log log.T, config agentContracts.Configuration, file *os.File, command string, shell string) error {
+	stdin, stdout, err := startPty(log, p.runAs, command, shell)
+	if err != nil {
+		log.Errorf("Unable to run the command of restricted session %v: %v", config.SessionId, err)
+		return p.sendRestrictedOutput(log, file, fmt.Sprintf(restrictedFailedMessage, command, err))
+	}
+	p.restricted.setCommand(log, stdin)
+	defer func() {
+		p.restricted.setCommand(log, nil)
+		if err := Stop(log); err != nil {
+			log.Debugf("Error occurred while closing pty: %v", err)
+		}
+	}()
+
+	stdoutBytes := make([]byte, mgsConfig.StreamDataPayloadSize)
+	reader := bufio.NewReader(stdout)
+	var unprocessedBuf bytes.Buffer
+	for {
+		stdoutBytesLen, err := reader.Read(stdoutBytes)
+		if err != nil {
+			// the pty fails the reads once the command exits
+			log.Debugf("Command of restricted session %v exited: %s", config.SessionId, err)
+			return nil
+		}
+		if unprocessedBuf, err = p.processStdoutData(log, stdoutBytes, stdoutBytesLen, unprocessedBuf, file); err != nil {
+			return err
+		}
+	}
+}
+
+// sendRestrictedOutput writes the messages of the restricted session to the data channel and to the ipc file
+func (p *ShellPlugin) sendRestrictedOutput(log log.T, file *os.File, message string) error {
+	_, err := p.processStdoutData(log, []byte(message), len(message), bytes.Buffer{}, file)
+	return err
+}
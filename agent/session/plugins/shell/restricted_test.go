@@ -0,0 +1,140 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package shell implements session shell plugin.
+package shell
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	mgsContracts "github.com/aws/amazon-ssm-agent/agent/session/contracts"
+	dataChannelMock "github.com/aws/amazon-ssm-agent/agent/session/datachannel/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCommandAllowlist(t *testing.T) {
+	allowlist := newCommandAllowlist(log.NewMockLog(), appconfig.MgsConfig{
+		RestrictedSessionAllowedCommands: []string{"systemctl restart nginx", " "},
+		RestrictedSessionAllowedPatterns: []string{`journalctl -u [a-z]+`, "("},
+	})
+
+	assert.True(t, allowlist.allows("systemctl restart nginx"))
+	assert.True(t, allowlist.allows(" systemctl restart nginx "))
+	assert.True(t, allowlist.allows("journalctl -u nginx"))
+	assert.False(t, allowlist.allows("systemctl restart sshd"))
+	assert.False(t, allowlist.allows("journalctl -u nginx; rm -rf /"))
+	assert.False(t, allowlist.allows(""))
+	assert.False(t, allowlist.allows("("))
+
+	// the allowed commands may contain metacharacters, the commands matching a pattern may not
+	allowlist = newCommandAllowlist(log.NewMockLog(), appconfig.MgsConfig{
+		RestrictedSessionAllowedCommands: []string{"journalctl -u nginx | tail"},
+		RestrictedSessionAllowedPatterns: []string{`systemctl status .*`},
+	})
+	assert.True(t, allowlist.allows("journalctl -u nginx | tail"))
+	assert.True(t, allowlist.allows("systemctl status nginx.service"))
+	for _, injection := range []string{
+		"systemctl status nginx; rm -rf /",
+		"systemctl status nginx && reboot",
+		"systemctl status nginx | sh",
+		"systemctl status $(reboot)",
+		"systemctl status `reboot`",
+		"systemctl status nginx > /etc/passwd",
+		"systemctl status ${IFS}",
+		"systemctl status 'a';reboot'",
+		"systemctl status nginx & reboot",
+		"systemctl status /*",
+	} {
+		assert.False(t, allowlist.allows(injection), injection)
+	}
+
+	assert.True(t, restrictedSessions(appconfig.MgsConfig{RestrictedSessionAllowedPatterns: []string{"("}}))
+	assert.False(t, restrictedSessions(appconfig.MgsConfig{}))
+}
+
+func TestLineEditor(t *testing.T) {
+	editor := lineEditor{}
+
+	echo, lines := editor.input([]byte("lx\x7fs"))
+	assert.Equal(t, "lx\b \bs", string(echo))
+	assert.Empty(t, lines)
+
+	// the arrow keys are ignored
+	echo, lines = editor.input([]byte("\x1b[A -l\r"))
+	assert.Equal(t, " -l\r\n", string(echo))
+	assert.Equal(t, []string{"ls -l"}, lines)
+
+	echo, lines = editor.input([]byte("rm\x03\x04"))
+	assert.Equal(t, "rm^C\r\n\r\n", string(echo))
+	assert.Equal(t, []string{"", restrictedExitCommand}, lines)
+}
+
+func TestRestrictedPump(t *testing.T) {
+	tempDir, _ := ioutil.TempDir("", "restricted")
+	defer os.RemoveAll(tempDir)
+
+	var audited []commandAudit
+	auditCommand = func(instanceID string, record commandAudit) error {
+		audited = append(audited, record)
+		return nil
+	}
+	defer func() { auditCommand = appendCommandAudit }()
+
+	var started []string
+	startPty = func(log log.T, runAs RunAsAccount, shellCmd string, shell string) (stdin *os.File, stdout *os.File, err error) {
+		started = append(started, shellCmd)
+		stdout, output, _ := os.Pipe()
+		output.Write([]byte("output"))
+		output.Close()
+		_, stdin, _ = os.Pipe()
+		return stdin, stdout, nil
+	}
+	defer func() { startPty = StartPty }()
+
+	mockLog := log.NewMockLog()
+	mockDataChannel := &dataChannelMock.IDataChannel{}
+	mockDataChannel.On("SendStreamDataMessage", mockLog, mgsContracts.Output, mock.Anything).Return(nil)
+	mockDataChannel.On("SendAgentSessionStateMessage", mockLog, mgsContracts.Terminating).Return(nil)
+
+	allowlist := newCommandAllowlist(mockLog, appconfig.MgsConfig{RestrictedSessionAllowedCommands: []string{"uptime"}})
+	plugin := &ShellPlugin{
+		ipcFilePath: filepath.Join(tempDir, "ipcTempFile.log"),
+		dataChannel: mockDataChannel,
+		restricted:  newRestrictedSession(allowlist, "i-1234"),
+	}
+	plugin.InputStreamMessageHandler(mockLog, mgsContracts.AgentMessage{
+		PayloadType: uint32(mgsContracts.Output),
+		Payload:     []byte("uptime\rreboot\rexit\r"),
+	})
+
+	exitCode := plugin.restrictedPump(mockLog, contracts.Configuration{SessionId: "session", ClientId: "client"}, "")
+
+	assert.Equal(t, appconfig.SuccessExitCode, exitCode)
+	assert.Equal(t, []string{"uptime"}, started)
+	if assert.Len(t, audited, 2) {
+		assert.Equal(t, commandAudit{Time: audited[0].Time, SessionId: "session", ClientId: "client", Command: "uptime", Allowed: true}, audited[0])
+		assert.Equal(t, "reboot", audited[1].Command)
+		assert.False(t, audited[1].Allowed)
+	}
+	mockDataChannel.AssertCalled(t, "SendStreamDataMessage", mockLog, mgsContracts.Output, []byte("output"))
+	mockDataChannel.AssertCalled(t, "SendStreamDataMessage", mockLog, mgsContracts.Output, []byte(fmt.Sprintf(restrictedRejectedMessage, "reboot")))
+	mockDataChannel.AssertExpectations(t)
+}
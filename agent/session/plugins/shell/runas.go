@@ -0,0 +1,110 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package shell implements session shell plugin.
+package shell
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os/user"
+	"strings"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	agentContracts "github.com/aws/amazon-ssm-agent/agent/contracts"
+)
+
+// assumedRolePrefix starts the resource of the ARN of the assumed role sessions
+const assumedRolePrefix = "assumed-role/"
+
+// RunAsAccount is the local account a session runs as, the session runs as the agent when User is empty
+type RunAsAccount struct {
+	User string
+	// WorkingDirectory is the directory the session starts in, the home directory of User when it's empty
+	WorkingDirectory string
+}
+
+// directory returns the directory the session starts in, the file transfers of the session are confined to it.
+// The sessions running as the agent aren't confined to a directory.
+func (account RunAsAccount) directory() (string, error) {
+	if account.User == "" || account.WorkingDirectory != "" {
+		return account.WorkingDirectory, nil
+	}
+	runAsUser, err := user.Lookup(account.User)
+	if err != nil {
+		return "", err
+	}
+	return runAsUser.HomeDir, nil
+}
+
+// runAsMapping is the content of the RunAs mapping file of the agent. The sessions are mapped on the attributes of
+// their IAM principal authenticated by the service, the session names are chosen by the callers and aren't trusted.
+type runAsMapping struct {
+	// Tags are the local accounts by value of the SSMSessionRunAs tag of the IAM principals starting sessions
+	Tags map[string]RunAsAccount
+	// Principals are the local accounts by ARN of the IAM users and roles starting sessions,
+	// the sessions of an assumed role are mapped on the ARN of the role
+	Principals map[string]RunAsAccount
+}
+
+// readRunAsMapping is assigned to a variable to allow unit tests to override it
+var readRunAsMapping = ioutil.ReadFile
+
+// sessionRunAsAccount returns the local account the session runs as. The mapping file of the agent maps the principal
+// tag or the principal of the sessions to their local account, the other sessions run as the default account.
+// The file is read for every session so that changes apply without restarting the agent.
+func sessionRunAsAccount(mgs appconfig.MgsConfig, config agentContracts.Configuration) (RunAsAccount, error) {
+	defaultAccount := RunAsAccount{User: mgs.RunAsDefaultUser}
+	if defaultAccount.User == "" {
+		defaultAccount.User = appconfig.DefaultRunAsUserName
+	}
+	if mgs.RunAsMappingFile == "" {
+		return defaultAccount, nil
+	}
+
+	content, err := readRunAsMapping(mgs.RunAsMappingFile)
+	if err != nil {
+		return RunAsAccount{}, fmt.Errorf("unable to read the RunAs mapping file %v: %v", mgs.RunAsMappingFile, err)
+	}
+	var mapping runAsMapping
+	if err = json.Unmarshal(content, &mapping); err != nil {
+		return RunAsAccount{}, fmt.Errorf("invalid RunAs mapping file %v: %v", mgs.RunAsMappingFile, err)
+	}
+
+	account, mapped := RunAsAccount{}, false
+	if config.RunAsUser != "" {
+		account, mapped = mapping.Tags[config.RunAsUser]
+	}
+	if !mapped && config.SessionOwner != "" {
+		account, mapped = mapping.Principals[principalArn(config.SessionOwner)]
+	}
+	if !mapped {
+		return defaultAccount, nil
+	}
+	if account.User == "" {
+		account.User = defaultAccount.User
+	}
+	return account, nil
+}
+
+// principalArn returns the ARN of the role of an assumed role session, the ARN of the other principals as is:
+// arn:aws:sts::123456789012:assumed-role/Deploy/alice is arn:aws:iam::123456789012:role/Deploy
+func principalArn(arn string) string {
+	parts := strings.SplitN(arn, ":", 6)
+	if len(parts) != 6 || parts[2] != "sts" || !strings.HasPrefix(parts[5], assumedRolePrefix) {
+		return arn
+	}
+	role := strings.SplitN(strings.TrimPrefix(parts[5], assumedRolePrefix), "/", 2)[0]
+	return strings.Join([]string{parts[0], parts[1], "iam", "", parts[4], "role/" + role}, ":")
+}
@@ -0,0 +1,110 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package shell implements session shell plugin.
+package shell
+
+import (
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/stretchr/testify/assert"
+)
+
+const runAsMappingContent = `{
+	"Tags": {
+		"alice": {"User": "alice", "WorkingDirectory": "/srv/app"}
+	},
+	"Principals": {
+		"arn:aws:iam::123456789012:role/Deploy": {"WorkingDirectory": "/srv/deploy"},
+		"arn:aws:iam::123456789012:user/bob": {"User": "bob"}
+	}
+}`
+
+func TestSessionRunAsAccountWithoutMapping(t *testing.T) {
+	config := contracts.Configuration{SessionId: "alice-0a1b2c3d4e5f67890", RunAsUser: "alice"}
+	account, err := sessionRunAsAccount(appconfig.MgsConfig{}, config)
+	assert.Nil(t, err)
+	assert.Equal(t, RunAsAccount{User: appconfig.DefaultRunAsUserName}, account)
+
+	account, err = sessionRunAsAccount(appconfig.MgsConfig{RunAsDefaultUser: "guest"}, config)
+	assert.Nil(t, err)
+	assert.Equal(t, RunAsAccount{User: "guest"}, account)
+}
+
+func TestSessionRunAsAccountWithMapping(t *testing.T) {
+	defer func() { readRunAsMapping = ioutil.ReadFile }()
+	readRunAsMapping = func(path string) ([]byte, error) {
+		assert.Equal(t, "/etc/amazon/ssm/runas.json", path)
+		return []byte(runAsMappingContent), nil
+	}
+	mgs := appconfig.MgsConfig{RunAsMappingFile: "/etc/amazon/ssm/runas.json", RunAsDefaultUser: "guest"}
+
+	account, err := sessionRunAsAccount(mgs, contracts.Configuration{
+		RunAsUser:    "alice",
+		SessionOwner: "arn:aws:iam::123456789012:user/bob",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, RunAsAccount{User: "alice", WorkingDirectory: "/srv/app"}, account)
+
+	// the sessions of an assumed role are mapped on the role whatever their session name
+	account, err = sessionRunAsAccount(mgs, contracts.Configuration{
+		SessionId:    "alice-0a1b2c3d4e5f67890",
+		SessionOwner: "arn:aws:sts::123456789012:assumed-role/Deploy/alice",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, RunAsAccount{User: "guest", WorkingDirectory: "/srv/deploy"}, account)
+
+	account, err = sessionRunAsAccount(mgs, contracts.Configuration{
+		RunAsUser:    "mallory",
+		SessionOwner: "arn:aws:iam::123456789012:user/bob",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, RunAsAccount{User: "bob"}, account)
+
+	// the session name chosen by the caller doesn't map the session
+	account, err = sessionRunAsAccount(mgs, contracts.Configuration{
+		SessionId:    "alice-0a1b2c3d4e5f67890",
+		SessionOwner: "arn:aws:sts::123456789012:assumed-role/Other/alice",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, RunAsAccount{User: "guest"}, account)
+}
+
+func TestSessionRunAsAccountWithInvalidMapping(t *testing.T) {
+	defer func() { readRunAsMapping = ioutil.ReadFile }()
+	mgs := appconfig.MgsConfig{RunAsMappingFile: "/etc/amazon/ssm/runas.json"}
+	config := contracts.Configuration{RunAsUser: "alice"}
+
+	readRunAsMapping = func(path string) ([]byte, error) {
+		return nil, errors.New("permission denied")
+	}
+	_, err := sessionRunAsAccount(mgs, config)
+	assert.NotNil(t, err)
+
+	readRunAsMapping = func(path string) ([]byte, error) {
+		return []byte("{"), nil
+	}
+	_, err = sessionRunAsAccount(mgs, config)
+	assert.NotNil(t, err)
+}
+
+func TestPrincipalArn(t *testing.T) {
+	assert.Equal(t, "arn:aws:iam::123456789012:role/Deploy", principalArn("arn:aws:sts::123456789012:assumed-role/Deploy/alice"))
+	assert.Equal(t, "arn:aws-cn:iam::123456789012:role/Deploy", principalArn("arn:aws-cn:sts::123456789012:assumed-role/Deploy/alice"))
+	assert.Equal(t, "arn:aws:iam::123456789012:user/bob", principalArn("arn:aws:iam::123456789012:user/bob"))
+	assert.Equal(t, "invalid", principalArn("invalid"))
+}
@@ -17,11 +17,14 @@ package shell
 import (
 	"bufio"
 	"bytes"
+	"encoding/binary"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 	"unicode/utf8"
 
@@ -33,17 +36,23 @@ import (
 	"github.com/aws/amazon-ssm-agent/agent/fileutil"
 	"github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler"
 	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/platform"
 	"github.com/aws/amazon-ssm-agent/agent/s3util"
 	mgsConfig "github.com/aws/amazon-ssm-agent/agent/session/config"
 	mgsContracts "github.com/aws/amazon-ssm-agent/agent/session/contracts"
 	"github.com/aws/amazon-ssm-agent/agent/session/datachannel"
+	"github.com/aws/amazon-ssm-agent/agent/session/filetransfer"
 	"github.com/aws/amazon-ssm-agent/agent/session/plugins/sessionplugin"
+	"github.com/aws/amazon-ssm-agent/agent/session/recorder"
 	"github.com/aws/amazon-ssm-agent/agent/task"
 )
 
 var ShellPluginCommandName = "sh"
 var ShellPluginCommandArgs = []string{"-c"}
 
+// fileTransferDisabledMessage is the error of the file transfers of the sessions which don't allow them
+const fileTransferDisabledMessage = "File transfers are not allowed in this session"
+
 // Plugin is the type for the plugin.
 type ShellPlugin struct {
 	stdin       *os.File
@@ -51,6 +60,20 @@ type ShellPlugin struct {
 	ipcFilePath string
 	logFilePath string
 	dataChannel datachannel.IDataChannel
+	// recorder records the transcript of the session when the agent configuration enables the session recording
+	recorder *recorder.Recorder
+	// idle tracks the input of the interactive sessions when the agent configuration sets an idle timeout
+	idle *idleMonitor
+	// restricted runs the interactive session when the agent configuration restricts the sessions to an allowlist
+	restricted *restrictedSession
+	// interactiveCommands runs the single command of the InteractiveCommands sessions and sends its exit code
+	interactiveCommands bool
+	// commandExitCode is the exit code of the command of the InteractiveCommands sessions
+	commandExitCode int
+	// runAs is the local account the session runs as, empty when the session runs elevated
+	runAs RunAsAccount
+	// transfers runs the file transfers of the session when the agent configuration enables them
+	transfers *filetransfer.Transfers
 }
 
 // NewPlugin returns a new instance of the Shell Plugin
@@ -59,8 +82,18 @@ func NewPlugin() (sessionplugin.ISessionPlugin, error) {
 	return &plugin, nil
 }
 
+// NewInteractiveCommandsPlugin returns a new instance of the Shell Plugin running the single command of the
+// InteractiveCommands sessions in a pseudo terminal, the client gets the exit code of the command before the session ends
+func NewInteractiveCommandsPlugin() (sessionplugin.ISessionPlugin, error) {
+	var plugin = ShellPlugin{interactiveCommands: true}
+	return &plugin, nil
+}
+
 // name returns the name of Shell Plugin
 func (p *ShellPlugin) name() string {
+	if p.interactiveCommands {
+		return appconfig.PluginNameInteractiveCommands
+	}
 	return appconfig.PluginNameStandardStream
 }
 
@@ -115,8 +148,36 @@ func (p *ShellPlugin) Execute(context context.T,
 	}
 }
 
-var startPty = func(log log.T, runAsSsmUser bool, shellCmd string) (stdin *os.File, stdout *os.File, err error) {
-	return StartPty(log, runAsSsmUser, shellCmd)
+var startPty = func(log log.T, runAs RunAsAccount, shellCmd string, shell string) (stdin *os.File, stdout *os.File, err error) {
+	return StartPty(log, runAs, shellCmd, shell)
+}
+
+var waitPty = func(log log.T) (exitCode int, err error) {
+	return Wait(log)
+}
+
+// shellProfile returns the shell and the profile run at the start of the interactive sessions.
+// The environment of the agent profile is set first, then the profile of the session document runs unless the agent
+// profile overrides it, and the commands of the agent profile run last so the local admins have the final say.
+func shellProfile(agentProfile appconfig.SessionShellProfile, serviceProfile string) (shell string, profile string) {
+	var commands []string
+
+	names := make([]string, 0, len(agentProfile.Environment))
+	for name := range agentProfile.Environment {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		commands = append(commands, environmentCommand(name, agentProfile.Environment[name]))
+	}
+
+	if !agentProfile.OverrideServiceProfile && strings.TrimSpace(serviceProfile) != "" {
+		commands = append(commands, serviceProfile)
+	}
+	if strings.TrimSpace(agentProfile.Commands) != "" {
+		commands = append(commands, agentProfile.Commands)
+	}
+	return strings.TrimSpace(agentProfile.Shell), strings.Join(commands, newLineCharacter)
 }
 
 // execute starts pseudo terminal.
@@ -148,14 +209,93 @@ func (p *ShellPlugin) execute(context context.T,
 		return
 	}
 
-	p.stdin, p.stdout, err = startPty(log, !config.RunAsElevated, config.Commands)
-	if err != nil {
-		errorString := fmt.Errorf("Unable to start shell: %s", err)
+	if appConfig := context.AppConfig(); recorder.Enabled(appConfig.Mgs) {
+		var instanceID string
+		if instanceID, err = platform.InstanceID(); err == nil {
+			p.recorder, err = recorder.New(instanceID, config.SessionId)
+		}
+		if err != nil {
+			errorString := fmt.Errorf("Unable to record the session: %s", err)
+			log.Error(errorString)
+			output.MarkAsFailed(errorString)
+			return
+		}
+		defer func() {
+			p.recorder.Close(log)
+			go recorder.Archive(log, appConfig.Mgs, instanceID)
+		}()
+	}
+
+	if p.interactiveCommands && strings.TrimSpace(config.Commands) == "" {
+		errorString := fmt.Errorf("The %s sessions need a command to run", p.name())
 		log.Error(errorString)
 		output.MarkAsFailed(errorString)
 		return
 	}
 
+	agentProfile, serviceProfile := platformShellProfile(context.AppConfig(), config)
+	shell, profile := shellProfile(agentProfile, serviceProfile)
+
+	if mgs := context.AppConfig().Mgs; restrictedSessions(mgs) {
+		var instanceID string
+		if instanceID, err = platform.InstanceID(); err != nil {
+			errorString := fmt.Errorf("Unable to audit the restricted session: %s", err)
+			log.Error(errorString)
+			output.MarkAsFailed(errorString)
+			return
+		}
+		allowlist := newCommandAllowlist(log, mgs)
+		if strings.TrimSpace(config.Commands) == "" {
+			// The interactive restricted sessions run each allowed command on its own, without the shell profile
+			p.restricted = newRestrictedSession(allowlist, instanceID)
+			defer close(p.restricted.done)
+		} else if !allowlist.audit(log, instanceID, config, config.Commands) {
+			errorString := fmt.Errorf("The commands of the session are not allowed on this host")
+			log.Error(errorString)
+			output.MarkAsFailed(errorString)
+			return
+		}
+	}
+
+	if !config.RunAsElevated {
+		if p.runAs, err = sessionRunAsAccount(context.AppConfig().Mgs, config); err != nil {
+			errorString := fmt.Errorf("Unable to find the account of the session: %s", err)
+			log.Error(errorString)
+			output.MarkAsFailed(errorString)
+			return
+		}
+	}
+
+	// The restricted sessions only run the allowed commands, the files they could transfer would escape the audit
+	if mgs := context.AppConfig().Mgs; mgs.SessionFileTransferEnabled && !restrictedSessions(mgs) {
+		if root, err := p.runAs.directory(); err != nil {
+			log.Warnf("File transfers are disabled in session %v: %v", config.SessionId, err)
+		} else {
+			p.transfers = filetransfer.New(p.dataChannel, root, p.runAs.User)
+			defer p.transfers.Close()
+		}
+	}
+
+	if p.restricted == nil {
+		if p.stdin, p.stdout, err = startPty(log, p.runAs, config.Commands, shell); err != nil {
+			errorString := fmt.Errorf("Unable to start shell: %s", err)
+			log.Error(errorString)
+			output.MarkAsFailed(errorString)
+			return
+		}
+	}
+
+	// The profile only runs in the interactive sessions, the sessions running commands exit once they complete
+	if p.restricted == nil && strings.TrimSpace(config.Commands) == "" && profile != "" {
+		log.Debug("Running the shell profile")
+		if _, err = p.stdin.Write([]byte(profile + newLineCharacter)); err != nil {
+			errorString := fmt.Errorf("Unable to run the shell profile: %s", err)
+			log.Error(errorString)
+			output.MarkAsFailed(errorString)
+			return
+		}
+	}
+
 	// Generate ipc file path
 	p.ipcFilePath = filepath.Join(config.OrchestrationDirectory, mgsConfig.IpcFileName+mgsConfig.LogFileExtension)
 
@@ -173,10 +313,26 @@ func (p *ShellPlugin) execute(context context.T,
 		log.Debugf("Cancel flag set to %v in session", cancelState)
 	}()
 
+	idle := make(chan time.Duration, 1)
+	if timeout := context.AppConfig().Mgs.IdleSessionTimeoutMinutes; timeout > 0 && strings.TrimSpace(config.Commands) == "" {
+		p.idle = newIdleMonitor(time.Duration(timeout) * time.Minute)
+		stopIdle := make(chan struct{})
+		defer close(stopIdle)
+		go func() {
+			if p.idle.wait(stopIdle, func(remaining time.Duration) { p.warnIdle(log, remaining) }) {
+				idle <- p.idle.timeout
+			}
+		}()
+	}
+
 	log.Debugf("Start separate go routine to read from pty stdout and write to data channel")
 	done := make(chan int, 1)
 	go func() {
-		done <- p.writePump(log)
+		if p.restricted != nil {
+			done <- p.restrictedPump(log, config, shell)
+		} else {
+			done <- p.writePump(log)
+		}
 	}()
 
 	log.Infof("Plugin %s started", p.name())
@@ -189,10 +345,23 @@ func (p *ShellPlugin) execute(context context.T,
 		output.SetStatus(agentContracts.ResultStatusSuccess)
 		log.Info("The session was cancelled")
 
+	case timeout := <-idle:
+		reason := fmt.Sprintf(idleReasonMessage, timeout)
+		log.Info(reason)
+		if err = p.dataChannel.SendAgentSessionTerminatingMessage(log, reason); err != nil {
+			log.Errorf("Unable to send AgentSessionState message with session status %s. %v", mgsContracts.Terminating, err)
+		}
+		output.SetExitCode(appconfig.SuccessExitCode)
+		output.SetStatus(agentContracts.ResultStatusSuccess)
+		sessionPluginResultOutput.Output = reason
+
 	case exitCode := <-done:
 		if exitCode == 1 {
 			output.SetExitCode(appconfig.ErrorExitCode)
 			output.SetStatus(agentContracts.ResultStatusFailed)
+		} else if p.interactiveCommands && p.commandExitCode != 0 {
+			output.SetExitCode(p.commandExitCode)
+			output.SetStatus(agentContracts.ResultStatusFailed)
 		} else {
 			output.SetExitCode(appconfig.SuccessExitCode)
 			output.SetStatus(agentContracts.ResultStatusSuccess)
@@ -233,6 +402,14 @@ func (p *ShellPlugin) execute(context context.T,
 	log.Debug("Shell session execution complete")
 }
 
+// warnIdle tells the client the session will be terminated unless there is some input
+func (p *ShellPlugin) warnIdle(log log.T, remaining time.Duration) {
+	log.Infof("The session has been idle, it will be terminated in %v", remaining)
+	if err := p.dataChannel.SendStreamDataMessage(log, mgsContracts.Output, []byte(fmt.Sprintf(idleWarningMessage, remaining.Round(time.Second)))); err != nil {
+		log.Warnf("Unable to warn the client the session is idle: %v", err)
+	}
+}
+
 // uploadShellSessionLogsToS3 uploads shell session logs to S3 bucket specified.
 func (p *ShellPlugin) uploadShellSessionLogsToS3(log log.T, s3UploaderUtil s3util.IAmazonS3Util, config agentContracts.Configuration, s3KeyPrefix string) {
 	log.Debugf("Preparing to upload session logs to S3 bucket %s and prefix %s", config.OutputS3BucketName, s3KeyPrefix)
@@ -271,6 +448,9 @@ func (p *ShellPlugin) writePump(log log.T) (errorCode int) {
 		if err != nil {
 			// Terminating session
 			log.Debugf("Failed to read from pty master: %s", err)
+			if p.interactiveCommands {
+				p.sendExitCode(log)
+			}
 			if err = p.dataChannel.SendAgentSessionStateMessage(log, mgsContracts.Terminating); err != nil {
 				log.Errorf("Unable to send AgentSessionState message with session status %s. %v", mgsContracts.Terminating, err)
 			}
@@ -287,6 +467,23 @@ func (p *ShellPlugin) writePump(log log.T) (errorCode int) {
 	}
 }
 
+// sendExitCode waits for the command of the InteractiveCommands session to exit and sends its exit code to the client
+func (p *ShellPlugin) sendExitCode(log log.T) {
+	exitCode, err := waitPty(log)
+	if err != nil {
+		log.Errorf("Unable to get the exit code of the command: %s", err)
+		exitCode = appconfig.ErrorExitCode
+	}
+	log.Infof("The command of the session exited with code %d", exitCode)
+	p.commandExitCode = exitCode
+
+	payload := make([]byte, 4)
+	binary.BigEndian.PutUint32(payload, uint32(exitCode))
+	if err = p.dataChannel.SendStreamDataMessage(log, mgsContracts.ExitCode, payload); err != nil {
+		log.Errorf("Unable to send the exit code of the command: %s", err)
+	}
+}
+
 // processStdoutData reads utf8 encoded unicode characters from stdoutBytes and sends it over websocket channel.
 func (p *ShellPlugin) processStdoutData(
 	log log.T,
@@ -320,6 +517,7 @@ func (p *ShellPlugin) processStdoutData(
 	if err := p.dataChannel.SendStreamDataMessage(log, mgsContracts.Output, processedBuf.Bytes()); err != nil {
 		return processedBuf, fmt.Errorf("unable to send stream data message: %s", err)
 	}
+	p.recorder.RecordOutput(log, processedBuf.Bytes())
 
 	if _, err := file.Write(processedBuf.Bytes()); err != nil {
 		return processedBuf, fmt.Errorf("encountered an error while writing to file: %s", err)
@@ -335,6 +533,12 @@ func (p *ShellPlugin) processStdoutData(
 
 // InputStreamMessageHandler passes payload byte stream to shell stdin
 func (p *ShellPlugin) InputStreamMessageHandler(log log.T, streamDataMessage mgsContracts.AgentMessage) error {
+	if mgsContracts.PayloadType(streamDataMessage.PayloadType) == mgsContracts.FileTransfer {
+		return p.handleFileTransfer(log, streamDataMessage.Payload)
+	}
+	if p.restricted != nil {
+		return p.restrictedInputStreamMessageHandler(log, streamDataMessage)
+	}
 	if p.stdin == nil || p.stdout == nil {
 		// This is to handle scenario when cli/console starts sending size data but pty has not been started yet
 		// Since packets are rejected, cli/console will resend these packets until pty starts successfully in separate thread
@@ -349,6 +553,8 @@ func (p *ShellPlugin) InputStreamMessageHandler(log log.T, streamDataMessage mgs
 			log.Errorf("Unable to write to stdin, err: %v.", err)
 			return err
 		}
+		p.recorder.RecordInput(log, streamDataMessage.Payload)
+		p.idle.touch()
 	case mgsContracts.Size:
 		var size mgsContracts.SizeData
 		if err := json.Unmarshal(streamDataMessage.Payload, &size); err != nil {
@@ -363,3 +569,39 @@ func (p *ShellPlugin) InputStreamMessageHandler(log log.T, streamDataMessage mgs
 	}
 	return nil
 }
+
+// handleFileTransfer passes the file transfer messages to the transfers of the session, or refuses them
+func (p *ShellPlugin) handleFileTransfer(log log.T, payload []byte) error {
+	if p.transfers == nil {
+		if p.dataChannel == nil {
+			return nil
+		}
+		return filetransfer.Reject(log, p.dataChannel, payload, fileTransferDisabledMessage)
+	}
+	return p.transfers.HandleMessage(log, payload)
+}
+
+// restrictedInputStreamMessageHandler passes payload byte stream to the restricted session
+func (p *ShellPlugin) restrictedInputStreamMessageHandler(log log.T, streamDataMessage mgsContracts.AgentMessage) error {
+	switch mgsContracts.PayloadType(streamDataMessage.PayloadType) {
+	case mgsContracts.Output:
+		log.Tracef("Output message received: %d", streamDataMessage.SequenceNumber)
+		if err := p.restricted.input(log, p.dataChannel, streamDataMessage.Payload); err != nil {
+			return err
+		}
+		p.recorder.RecordInput(log, streamDataMessage.Payload)
+		p.idle.touch()
+	case mgsContracts.Size:
+		var size mgsContracts.SizeData
+		if err := json.Unmarshal(streamDataMessage.Payload, &size); err != nil {
+			log.Errorf("Invalid size message: %s", err)
+			return err
+		}
+		log.Tracef("Resize data received: cols: %d, rows: %d", size.Cols, size.Rows)
+		if err := p.restricted.setSize(log, size); err != nil {
+			log.Errorf("Unable to set pty size: %s", err)
+			return err
+		}
+	}
+	return nil
+}
@@ -18,6 +18,8 @@ import (
 	"bytes"
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -57,6 +59,8 @@ type ShellTestSuite struct {
 	stdin           *os.File
 	stdout          *os.File
 	plugin          *ShellPlugin
+	// tempDir is the orchestration directory of the tests, so the ipc files are not written in the package
+	tempDir string
 }
 
 func (suite *ShellTestSuite) SetupTest() {
@@ -81,11 +85,13 @@ func (suite *ShellTestSuite) SetupTest() {
 		stdin:  stdin,
 		stdout: stdout,
 	}
+	suite.tempDir, _ = ioutil.TempDir("", "shell")
 }
 
 func (suite *ShellTestSuite) TearDownTest() {
 	suite.stdin.Close()
 	suite.stdout.Close()
+	os.RemoveAll(suite.tempDir)
 }
 
 // Testing Name
@@ -136,7 +142,7 @@ func (suite *ShellTestSuite) TestExecute() {
 
 	stdout, stdin, _ := os.Pipe()
 	stdin.Write(payload)
-	startPty = func(log log.T, runAsSsmUser bool, shellCmd string) (stdin *os.File, stdout *os.File, err error) {
+	startPty = func(log log.T, runAs RunAsAccount, shellCmd string, shell string) (stdin *os.File, stdout *os.File, err error) {
 		return stdin, stdout, nil
 	}
 	plugin := &ShellPlugin{
@@ -145,7 +151,7 @@ func (suite *ShellTestSuite) TestExecute() {
 	}
 
 	plugin.Execute(suite.mockContext,
-		contracts.Configuration{},
+		contracts.Configuration{OrchestrationDirectory: suite.tempDir},
 		suite.mockCancelFlag,
 		suite.mockIohandler,
 		suite.mockDataChannel)
@@ -157,6 +163,62 @@ func (suite *ShellTestSuite) TestExecute() {
 	stdout.Close()
 }
 
+// Testing Execute of the InteractiveCommands sessions sends the exit code of the command
+func (suite *ShellTestSuite) TestExecuteInteractiveCommands() {
+	suite.mockCancelFlag.On("Canceled").Return(false)
+	suite.mockCancelFlag.On("ShutDown").Return(false)
+	suite.mockCancelFlag.On("Wait").Return(task.Completed)
+	suite.mockDataChannel.On("SendStreamDataMessage", mock.Anything, mgsContracts.Output, payload).Return(nil)
+	suite.mockDataChannel.On("SendStreamDataMessage", mock.Anything, mgsContracts.ExitCode, []byte{0, 0, 0, 3}).Return(nil)
+	suite.mockDataChannel.On("SendAgentSessionStateMessage", mock.Anything, mgsContracts.Terminating).Return(nil)
+	suite.mockIohandler.On("SetExitCode", 3).Return(nil)
+	suite.mockIohandler.On("SetStatus", contracts.ResultStatusFailed).Return()
+	suite.mockIohandler.On("SetOutput", mock.Anything).Return()
+
+	var command string
+	startPty = func(log log.T, runAs RunAsAccount, shellCmd string, shell string) (stdin *os.File, stdout *os.File, err error) {
+		command = shellCmd
+		stdout, output, _ := os.Pipe()
+		output.Write(payload)
+		output.Close()
+		return suite.stdin, stdout, nil
+	}
+	waitPty = func(log log.T) (int, error) {
+		return 3, nil
+	}
+	defer func() {
+		startPty = StartPty
+		waitPty = Wait
+	}()
+
+	plugin, _ := NewInteractiveCommandsPlugin()
+	plugin.Execute(suite.mockContext,
+		contracts.Configuration{OrchestrationDirectory: suite.tempDir, Commands: "top -n 1"},
+		suite.mockCancelFlag,
+		suite.mockIohandler,
+		suite.mockDataChannel)
+
+	assert.Equal(suite.T(), "top -n 1", command)
+	suite.mockDataChannel.AssertExpectations(suite.T())
+	suite.mockIohandler.AssertExpectations(suite.T())
+}
+
+// Testing Execute of the InteractiveCommands sessions fails without a command
+func (suite *ShellTestSuite) TestExecuteInteractiveCommandsWithoutCommand() {
+	suite.mockCancelFlag.On("Canceled").Return(false)
+	suite.mockCancelFlag.On("ShutDown").Return(false)
+	suite.mockIohandler.On("MarkAsFailed", mock.Anything).Return()
+
+	plugin, _ := NewInteractiveCommandsPlugin()
+	plugin.Execute(suite.mockContext,
+		contracts.Configuration{OrchestrationDirectory: suite.tempDir},
+		suite.mockCancelFlag,
+		suite.mockIohandler,
+		suite.mockDataChannel)
+
+	suite.mockIohandler.AssertExpectations(suite.T())
+}
+
 // Testing writepump separately
 func (suite *ShellTestSuite) TestWritePump() {
 	stdout, stdin, _ := os.Pipe()
@@ -168,7 +230,7 @@ func (suite *ShellTestSuite) TestWritePump() {
 
 	plugin := &ShellPlugin{
 		stdout:      stdout,
-		ipcFilePath: "test.log",
+		ipcFilePath: filepath.Join(suite.tempDir, "test.log"),
 		dataChannel: suite.mockDataChannel,
 	}
 
@@ -222,6 +284,46 @@ func (suite *ShellTestSuite) TestProcessStreamMessage() {
 	assert.Equal(suite.T(), "testPayload", string(stdinFileContent))
 }
 
+// Testing the file transfers are refused when the session doesn't allow them
+func (suite *ShellTestSuite) TestProcessStreamMessageRejectsFileTransfer() {
+	plugin := &ShellPlugin{dataChannel: suite.mockDataChannel}
+	rejected := []byte(`{"action":"error","transferId":"upload","offset":0,"size":0,"error":"` + fileTransferDisabledMessage + `"}`)
+	suite.mockDataChannel.On("SendStreamDataMessage", mockLog, mgsContracts.FileTransfer, rejected).Return(nil)
+
+	agentMessage := getAgentMessage(uint32(mgsContracts.FileTransfer), []byte(`{"action":"upload","transferId":"upload","path":"file"}`))
+	err := plugin.InputStreamMessageHandler(mockLog, *agentMessage)
+
+	assert.Nil(suite.T(), err)
+	suite.mockDataChannel.AssertExpectations(suite.T())
+}
+
+// Testing the shell profile merges the agent profile with the profile of the session document
+func (suite *ShellTestSuite) TestShellProfile() {
+	agentProfile := appconfig.SessionShellProfile{
+		Shell:       " bash ",
+		Commands:    "echo banner",
+		Environment: map[string]string{"TMOUT": "600", "LANG": "C"},
+	}
+
+	shell, profile := shellProfile(agentProfile, "cd ~")
+	assert.Equal(suite.T(), "bash", shell)
+	assert.Equal(suite.T(), strings.Join([]string{
+		environmentCommand("LANG", "C"),
+		environmentCommand("TMOUT", "600"),
+		"cd ~",
+		"echo banner",
+	}, newLineCharacter), profile)
+
+	agentProfile.OverrideServiceProfile = true
+	agentProfile.Environment = nil
+	_, profile = shellProfile(agentProfile, "cd ~")
+	assert.Equal(suite.T(), "echo banner", profile)
+
+	shell, profile = shellProfile(appconfig.SessionShellProfile{}, "")
+	assert.Equal(suite.T(), "", shell)
+	assert.Equal(suite.T(), "", profile)
+}
+
 //Execute the test suite
 func TestShellTestSuite(t *testing.T) {
 	suite.Run(t, new(ShellTestSuite))
@@ -17,9 +17,11 @@
 package shell
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+	"os/user"
 	"strconv"
 	"strings"
 	"syscall"
@@ -34,6 +36,9 @@ import (
 
 var ptyFile *os.File
 
+// ptyCmd is the command started by the last StartPty
+var ptyCmd *exec.Cmd
+
 const (
 	termEnvVariable       = "TERM=xterm-256color"
 	startRecordSessionCmd = "script"
@@ -42,39 +47,50 @@ const (
 	homeEnvVariable       = "HOME=/home/" + appconfig.DefaultRunAsUserName
 )
 
-var getUserAndGroupIdCall = func(log log.T) (uid int, gid int, err error) {
-	return getUserAndGroupId(log)
+var getUserAndGroupIdCall = func(log log.T, userName string) (uid int, gid int, home string, err error) {
+	return getUserAndGroupId(log, userName)
 }
 
-//StartPty starts pty and provides handles to stdin and stdout
-func StartPty(log log.T, runAsSsmUser bool, shellCmd string) (stdin *os.File, stdout *os.File, err error) {
+//StartPty starts pty and provides handles to stdin and stdout, an empty shell starts sh.
+//The pty runs as the account when it has a user, in the working directory of the account.
+func StartPty(log log.T, runAs RunAsAccount, shellCmd string, shell string) (stdin *os.File, stdout *os.File, err error) {
 	log.Info("Starting pty")
+	if shell == "" {
+		shell = ShellPluginCommandName
+	}
 	//Start the command with a pty
 	var cmd *exec.Cmd
 	if strings.TrimSpace(shellCmd) == "" {
-		cmd = exec.Command("sh")
+		cmd = exec.Command(shell)
 	} else {
 		commandArgs := append(ShellPluginCommandArgs, shellCmd)
-		cmd = exec.Command("sh", commandArgs...)
+		cmd = exec.Command(shell, commandArgs...)
 	}
 
 	//TERM is set as linux by pty which has an issue where vi editor screen does not get cleared.
 	//Setting TERM as xterm-256color as used by standard terminals to fix this issue
-	cmd.Env = append(os.Environ(),
-		termEnvVariable,
-		homeEnvVariable,
-	)
+	cmd.Env = append(os.Environ(), termEnvVariable)
+	cmd.Dir = runAs.WorkingDirectory
 
 	// Get the uid and gid of the runas user.
-	if runAsSsmUser {
-		uid, gid, err := getUserAndGroupIdCall(log)
+	if runAs.User != "" {
+		uid, gid, home, err := getUserAndGroupIdCall(log, runAs.User)
 		if err != nil {
 			return nil, nil, err
 		}
 		cmd.SysProcAttr = &syscall.SysProcAttr{}
 		cmd.SysProcAttr.Credential = &syscall.Credential{Uid: uint32(uid), Gid: uint32(gid)}
+		cmd.Env = append(cmd.Env, "HOME="+home, "USER="+runAs.User, "LOGNAME="+runAs.User)
+		if cmd.Dir == "" {
+			if info, err := os.Stat(home); err == nil && info.IsDir() {
+				cmd.Dir = home
+			}
+		}
+	} else {
+		cmd.Env = append(cmd.Env, homeEnvVariable)
 	}
 
+	ptyCmd = cmd
 	ptyFile, err = pty.Start(cmd)
 	if err != nil {
 		log.Errorf("Failed to start pty: %s\n", err)
@@ -93,6 +109,22 @@ func Stop(log log.T) (err error) {
 	return nil
 }
 
+//Wait waits for the command of the pty to exit and returns its exit code.
+func Wait(log log.T) (exitCode int, err error) {
+	if ptyCmd == nil {
+		return 0, errors.New("no command started in pty")
+	}
+	if err = ptyCmd.Wait(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			if status, ok := exitErr.Sys().(syscall.WaitStatus); ok {
+				return status.ExitStatus(), nil
+			}
+		}
+		return 0, fmt.Errorf("unable to wait for the command: %s", err)
+	}
+	return 0, nil
+}
+
 //SetSize sets size of console terminal window.
 func SetSize(log log.T, ws_col, ws_row uint32) (err error) {
 	winSize := pty.Winsize{
@@ -106,44 +138,45 @@ func SetSize(log log.T, ws_col, ws_row uint32) (err error) {
 	return nil
 }
 
-//getUserAndGroupId returns the uid and gid of the runas user.
-func getUserAndGroupId(log log.T) (uid int, gid int, err error) {
-	shellCmdArgs := append(ShellPluginCommandArgs, fmt.Sprintf("id -u %s", appconfig.DefaultRunAsUserName))
-	cmd := exec.Command(ShellPluginCommandName, shellCmdArgs...)
-	out, err := cmd.Output()
-	if err != nil {
-		log.Errorf("Failed retrieve uid for %s: %v", appconfig.DefaultRunAsUserName, err)
-		return
-	}
+// platformShellProfile returns the shell profiles of the agent configuration and of the session document for linux
+func platformShellProfile(appConfig appconfig.SsmagentConfig, config agentContracts.Configuration) (appconfig.SessionShellProfile, string) {
+	return appConfig.Mgs.ShellProfile.Linux, config.ShellProfile.Linux
+}
 
-	u, err := strconv.Atoi(strings.TrimSpace(string(out)))
-	if err != nil {
-		log.Errorf("%s not found: %v", appconfig.DefaultRunAsUserName, err)
-	}
+// environmentCommand returns the command exporting the environment variable in sh
+func environmentCommand(name string, value string) string {
+	return fmt.Sprintf("export %s='%s'", name, strings.Replace(value, "'", `'\''`, -1))
+}
 
-	shellCmdArgs = append(ShellPluginCommandArgs, fmt.Sprintf("id -g %s", appconfig.DefaultRunAsUserName))
-	cmd = exec.Command(ShellPluginCommandName, shellCmdArgs...)
-	out, err = cmd.Output()
+//getUserAndGroupId returns the uid, gid and home directory of the runas user.
+func getUserAndGroupId(log log.T, userName string) (uid int, gid int, home string, err error) {
+	account, err := user.Lookup(userName)
 	if err != nil {
-		log.Errorf("Failed retrieve gid for %s: %v", appconfig.DefaultRunAsUserName, err)
+		log.Errorf("%s not found: %v", userName, err)
 		return
 	}
 
-	g, err := strconv.Atoi(strings.TrimSpace(string(out)))
-	if err != nil {
-		log.Errorf("%s not found: %v", appconfig.DefaultRunAsUserName, err)
+	if uid, err = strconv.Atoi(account.Uid); err != nil {
+		log.Errorf("Invalid uid %s for %s: %v", account.Uid, userName, err)
+		return
+	}
+	if gid, err = strconv.Atoi(account.Gid); err != nil {
+		log.Errorf("Invalid gid %s for %s: %v", account.Gid, userName, err)
+		return
 	}
 
-	// Make sure they are non-zero valid positive ids
-	if u > 0 && g > 0 {
-		return u, g, nil
+	// Make sure they are non-zero valid positive ids, the sessions never run as root
+	if uid <= 0 || gid <= 0 {
+		err = fmt.Errorf("the sessions can't run as %s, uid %d and gid %d must be positive", userName, uid, gid)
+		log.Error(err)
+		return 0, 0, "", err
 	}
-	return
+	return uid, gid, account.HomeDir, nil
 }
 
 // generateLogData generates a log file with the executed commands.
 func (p *ShellPlugin) generateLogData(log log.T, config agentContracts.Configuration) error {
-	shadowShellInput, _, err := StartPty(log, false, "")
+	shadowShellInput, _, err := StartPty(log, RunAsAccount{}, "", "")
 	if err != nil {
 		return err
 	}
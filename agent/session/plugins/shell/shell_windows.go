@@ -65,21 +65,29 @@ var (
 	winptyDllFilePath = filepath.Join(winptyDllDir, winptyDllName)
 )
 
-//StartPty starts winpty agent and provides handles to stdin and stdout.
-func StartPty(log log.T, runAsSsmUser bool, shellCmd string) (stdin *os.File, stdout *os.File, err error) {
+//StartPty starts winpty agent and provides handles to stdin and stdout, an empty shell starts powershell.
+//The sessions only run as the default ssm user on windows, since the agent resets the password of the user it logs on.
+func StartPty(log log.T, runAs RunAsAccount, shellCmd string, shell string) (stdin *os.File, stdout *os.File, err error) {
 	log.Info("Starting winpty")
 	if _, err := os.Stat(winptyDllFilePath); os.IsNotExist(err) {
 		return nil, nil, fmt.Errorf("Missing %s file.", winptyDllFilePath)
 	}
 
+	if shell == "" {
+		shell = winptyCmd
+	}
 	var finalCmd string
 	if strings.TrimSpace(shellCmd) == "" {
-		finalCmd = winptyCmd
+		finalCmd = shell
 	} else {
-		finalCmd = winptyCmd + " " + shellCmd
+		finalCmd = shell + " " + shellCmd
+	}
+
+	if runAs.User != "" && runAs.User != appconfig.DefaultRunAsUserName {
+		return nil, nil, fmt.Errorf("sessions can only run as %s on windows, not as %s", appconfig.DefaultRunAsUserName, runAs.User)
 	}
 
-	if runAsSsmUser {
+	if runAs.User != "" {
 		// Reset password for default ssm user
 		var newPassword string
 		newPassword, err = u.GeneratePasswordForDefaultUser()
@@ -119,6 +127,15 @@ func Stop(log log.T) (err error) {
 	return nil
 }
 
+//Wait waits for the command of winpty to exit and returns its exit code.
+func Wait(log log.T) (exitCode int, err error) {
+	code, err := pty.Wait()
+	if err != nil {
+		return 0, fmt.Errorf("Wait winpty failed: %s", err)
+	}
+	return int(int32(code)), nil
+}
+
 //SetSize sets size of console terminal window.
 func SetSize(log log.T, ws_col, ws_row uint32) (err error) {
 	if err = pty.SetSize(ws_col, ws_row); err != nil {
@@ -128,6 +145,16 @@ func SetSize(log log.T, ws_col, ws_row uint32) (err error) {
 	return nil
 }
 
+// platformShellProfile returns the shell profiles of the agent configuration and of the session document for windows
+func platformShellProfile(appConfig appconfig.SsmagentConfig, config agentContracts.Configuration) (appconfig.SessionShellProfile, string) {
+	return appConfig.Mgs.ShellProfile.Windows, config.ShellProfile.Windows
+}
+
+// environmentCommand returns the command setting the environment variable in powershell
+func environmentCommand(name string, value string) string {
+	return fmt.Sprintf("$env:%s = '%s'", name, strings.Replace(value, "'", "''", -1))
+}
+
 //startPtyAsUser starts a winpty process in runas user context.
 func startPtyAsUser(log log.T, user string, pass string, shellCmd string) (err error) {
 	runtime.LockOSThread()
@@ -249,7 +276,7 @@ func (p *ShellPlugin) generateLogData(log log.T, config agentContracts.Configura
 
 // generateTranscriptFile generates a transcript file using PowerShell
 func generateTranscriptFile(log log.T, transcriptFile string, loggerFile string, enableVirtualTerminalProcessingForWindows bool) error {
-	shadowShellInput, _, err := StartPty(log, false, "")
+	shadowShellInput, _, err := StartPty(log, RunAsAccount{}, "", "")
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,66 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package ssh implements session ssh plugin, tunneling the session to the local sshd so clients can use it as ssh ProxyCommand.
+package ssh
+
+import (
+	"net"
+	"strconv"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	agentContracts "github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler"
+	"github.com/aws/amazon-ssm-agent/agent/session/datachannel"
+	"github.com/aws/amazon-ssm-agent/agent/session/plugins/port"
+	"github.com/aws/amazon-ssm-agent/agent/session/plugins/sessionplugin"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+)
+
+const localHost = "localhost"
+
+// SSHPlugin is the type for the ssh plugin, it forwards the session to the local sshd the way the port plugin forwards a port.
+// The ssh client multiplexes its channels over the single stream, the plugin only relays the bytes both ways.
+type SSHPlugin struct {
+	port.PortPlugin
+}
+
+// NewPlugin returns a new instance of the SSH Plugin
+func NewPlugin() (sessionplugin.ISessionPlugin, error) {
+	var plugin = SSHPlugin{}
+	return &plugin, nil
+}
+
+// name returns the name of SSH Plugin
+func (p *SSHPlugin) name() string {
+	return appconfig.PluginNameSSH
+}
+
+// Execute connects to the local sshd and forwards the data between the data channel and sshd until either side closes.
+func (p *SSHPlugin) Execute(context context.T,
+	config agentContracts.Configuration,
+	cancelFlag task.CancelFlag,
+	output iohandler.IOHandler,
+	dataChannel datachannel.IDataChannel) {
+
+	if cancelFlag.ShutDown() {
+		output.MarkAsShutdown()
+	} else if cancelFlag.Canceled() {
+		output.MarkAsCancelled()
+	} else {
+		address := net.JoinHostPort(localHost, strconv.Itoa(context.AppConfig().Mgs.SSHPort))
+		context.Log().Infof("Plugin %s connecting to sshd at %v", p.name(), address)
+		p.Forward(context, address, cancelFlag, output, dataChannel)
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package ssh implements session ssh plugin, tunneling the session to the local sshd so clients can use it as ssh ProxyCommand.
+package ssh
+
+import (
+	"net"
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	iohandlermocks "github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler/mock"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	mgsContracts "github.com/aws/amazon-ssm-agent/agent/session/contracts"
+	dataChannelMock "github.com/aws/amazon-ssm-agent/agent/session/datachannel/mocks"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type SSHTestSuite struct {
+	suite.Suite
+	mockLog         log.T
+	mockCancelFlag  *task.MockCancelFlag
+	mockDataChannel *dataChannelMock.IDataChannel
+	mockIohandler   *iohandlermocks.MockIOHandler
+	plugin          *SSHPlugin
+}
+
+func (suite *SSHTestSuite) SetupTest() {
+	suite.mockLog = log.NewMockLog()
+	suite.mockCancelFlag = &task.MockCancelFlag{}
+	suite.mockDataChannel = &dataChannelMock.IDataChannel{}
+	suite.mockIohandler = new(iohandlermocks.MockIOHandler)
+	suite.plugin = &SSHPlugin{}
+}
+
+func (suite *SSHTestSuite) newContext(sshPort int) *context.Mock {
+	config := appconfig.SsmagentConfig{}
+	config.Mgs.SSHPort = sshPort
+	mockContext := new(context.Mock)
+	mockContext.On("Log").Return(suite.mockLog)
+	mockContext.On("AppConfig").Return(config)
+	return mockContext
+}
+
+// Testing Name
+func (suite *SSHTestSuite) TestName() {
+	assert.Equal(suite.T(), appconfig.PluginNameSSH, suite.plugin.name())
+}
+
+// Testing Execute
+func (suite *SSHTestSuite) TestExecuteWhenCancelFlagIsShutDown() {
+	suite.mockCancelFlag.On("ShutDown").Return(true)
+	suite.mockIohandler.On("MarkAsShutdown").Return(nil)
+
+	suite.plugin.Execute(suite.newContext(appconfig.DefaultSSHPort),
+		contracts.Configuration{},
+		suite.mockCancelFlag,
+		suite.mockIohandler,
+		suite.mockDataChannel)
+
+	suite.mockCancelFlag.AssertExpectations(suite.T())
+	suite.mockIohandler.AssertExpectations(suite.T())
+}
+
+// Testing Execute connects to sshd on the configured port and relays its data
+func (suite *SSHTestSuite) TestExecuteConnectsToConfiguredPort() {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(suite.T(), err)
+	defer listener.Close()
+
+	// sshd sends its banner and closes the connection
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		conn.Write([]byte("SSH-2.0-OpenSSH\r\n"))
+		conn.Close()
+	}()
+
+	suite.mockCancelFlag.On("ShutDown").Return(false)
+	suite.mockCancelFlag.On("Canceled").Return(false)
+	suite.mockCancelFlag.On("Wait").Return(task.Completed)
+	suite.mockDataChannel.On("SendStreamDataMessage", suite.mockLog, mgsContracts.Output, []byte("SSH-2.0-OpenSSH\r\n")).Return(nil)
+	suite.mockDataChannel.On("SendAgentSessionStateMessage", suite.mockLog, mgsContracts.Terminating).Return(nil)
+	suite.mockIohandler.On("SetExitCode", appconfig.SuccessExitCode).Return()
+	suite.mockIohandler.On("SetStatus", contracts.ResultStatusSuccess).Return()
+	suite.mockIohandler.On("SetOutput", mock.Anything).Return()
+
+	suite.plugin.Execute(suite.newContext(listener.Addr().(*net.TCPAddr).Port),
+		contracts.Configuration{},
+		suite.mockCancelFlag,
+		suite.mockIohandler,
+		suite.mockDataChannel)
+
+	suite.mockDataChannel.AssertExpectations(suite.T())
+	suite.mockIohandler.AssertExpectations(suite.T())
+}
+
+// Execute the test suite
+func TestSSHTestSuite(t *testing.T) {
+	suite.Run(t, new(SSHTestSuite))
+}
@@ -0,0 +1,166 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package recorder records the transcript of the interactive sessions in a local spool and archives it to S3.
+package recorder
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/fileutil"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/s3util"
+)
+
+const (
+	// recordingExtension is the extension of the complete recordings, ready to be archived
+	recordingExtension = ".jsonl"
+	// partialExtension is the extension of the recordings of the sessions still running
+	partialExtension = ".part"
+
+	streamInput  = "input"
+	streamOutput = "output"
+)
+
+// record is one chunk of the transcript, written as one json line
+type record struct {
+	Time   string `json:"time"`
+	Stream string `json:"stream"`
+	Data   string `json:"data"`
+}
+
+// Recorder writes the input and output of a session to the local spool, a nil Recorder records nothing
+type Recorder struct {
+	lock    sync.Mutex
+	file    *os.File
+	encoder *json.Encoder
+	path    string
+}
+
+// archiveLock makes sure a single goroutine archives the spool at a time
+var archiveLock sync.Mutex
+
+// Assign method to global variables to allow unittest to override
+var spoolDir = func(instanceID string) string {
+	return filepath.Join(appconfig.DefaultDataStorePath, instanceID, appconfig.DefaultSessionRootDirName, appconfig.SessionRecordingsRootDirName)
+}
+var newUploader = func(log log.T, config appconfig.MgsConfig) s3util.IAmazonS3Util {
+	return s3util.NewAmazonS3UtilWithOptions(log, config.SessionRecordingS3BucketName, contracts.S3OutputOptions{KmsKeyArn: config.SessionRecordingKmsKeyArn})
+}
+
+// Enabled returns true if the agent configuration asks to record the sessions
+func Enabled(config appconfig.MgsConfig) bool {
+	return config.SessionRecordingS3BucketName != ""
+}
+
+// New creates the recording of the session in the spool of the instance
+func New(instanceID string, sessionID string) (*Recorder, error) {
+	location := spoolDir(instanceID)
+	if err := fileutil.MakeDirs(location); err != nil {
+		return nil, fmt.Errorf("cannot make directory of %v because: %v", location, err)
+	}
+	recordingPath := filepath.Join(location, sessionID+partialExtension)
+	file, err := os.OpenFile(recordingPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, os.FileMode(int(appconfig.ReadWriteAccess)))
+	if err != nil {
+		return nil, err
+	}
+	return &Recorder{file: file, encoder: json.NewEncoder(file), path: recordingPath}, nil
+}
+
+// RecordInput records the data the client sent to the session
+func (r *Recorder) RecordInput(log log.T, data []byte) {
+	r.record(log, streamInput, data)
+}
+
+// RecordOutput records the data the session sent to the client
+func (r *Recorder) RecordOutput(log log.T, data []byte) {
+	r.record(log, streamOutput, data)
+}
+
+func (r *Recorder) record(log log.T, stream string, data []byte) {
+	if r == nil || len(data) == 0 {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.file == nil {
+		return
+	}
+	if err := r.encoder.Encode(record{Time: time.Now().UTC().Format(time.RFC3339Nano), Stream: stream, Data: string(data)}); err != nil {
+		log.Warnf("Failed to record the session %v: %v", stream, err)
+	}
+}
+
+// Close completes the recording, it's archived with the rest of the spool
+func (r *Recorder) Close(log log.T) {
+	if r == nil {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.file == nil {
+		return
+	}
+	if err := r.file.Close(); err != nil {
+		log.Warnf("Failed to close the session recording %v: %v", r.path, err)
+	}
+	r.file = nil
+	if err := os.Rename(r.path, strings.TrimSuffix(r.path, partialExtension)+recordingExtension); err != nil {
+		log.Warnf("Failed to complete the session recording %v: %v", r.path, err)
+	}
+}
+
+// Archive uploads the complete recordings of the spool to the configured S3 prefix and deletes the uploaded ones,
+// the recordings which fail to upload are retried the next time the spool is archived
+func Archive(log log.T, config appconfig.MgsConfig, instanceID string) {
+	archiveLock.Lock()
+	defer archiveLock.Unlock()
+
+	location := spoolDir(instanceID)
+	files, err := ioutil.ReadDir(location)
+	if err != nil {
+		log.Debugf("No session recordings to archive: %v", err)
+		return
+	}
+
+	var uploader s3util.IAmazonS3Util
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != recordingExtension {
+			continue
+		}
+		if uploader == nil {
+			uploader = newUploader(log, config)
+		}
+		objectKey := path.Join(config.SessionRecordingS3KeyPrefix, instanceID, file.Name())
+		recordingPath := filepath.Join(location, file.Name())
+		if err := uploader.S3Upload(log, config.SessionRecordingS3BucketName, objectKey, recordingPath); err != nil {
+			log.Warnf("Failed to archive the session recording %v, it will be retried: %v", file.Name(), err)
+			continue
+		}
+		if err := os.Remove(recordingPath); err != nil {
+			log.Warnf("Failed to delete the archived session recording %v: %v", file.Name(), err)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package recorder records the transcript of the interactive sessions in a local spool and archives it to S3.
+package recorder
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/fileutil"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/s3util"
+	"github.com/stretchr/testify/assert"
+)
+
+func stubSpool(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "recorder")
+	assert.NoError(t, err)
+	original := spoolDir
+	spoolDir = func(instanceID string) string {
+		return filepath.Join(dir, instanceID)
+	}
+	return filepath.Join(dir, "i-123"), func() {
+		spoolDir = original
+		os.RemoveAll(dir)
+	}
+}
+
+func stubUploader(uploader s3util.IAmazonS3Util) func() {
+	original := newUploader
+	newUploader = func(log log.T, config appconfig.MgsConfig) s3util.IAmazonS3Util {
+		return uploader
+	}
+	return func() { newUploader = original }
+}
+
+func TestRecorderWritesTranscript(t *testing.T) {
+	spool, restore := stubSpool(t)
+	defer restore()
+
+	recorder, err := New("i-123", "session-1")
+	assert.NoError(t, err)
+	recorder.RecordInput(log.NewMockLog(), []byte("ls\r"))
+	recorder.RecordOutput(log.NewMockLog(), []byte("file.txt\r\n"))
+	recorder.RecordOutput(log.NewMockLog(), nil)
+	assert.True(t, fileutil.Exists(filepath.Join(spool, "session-1.part")))
+	recorder.Close(log.NewMockLog())
+
+	file, err := os.Open(filepath.Join(spool, "session-1.jsonl"))
+	assert.NoError(t, err)
+	defer file.Close()
+	var records []record
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var r record
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &r))
+		assert.NotEmpty(t, r.Time)
+		records = append(records, r)
+	}
+	assert.Equal(t, 2, len(records))
+	assert.Equal(t, streamInput, records[0].Stream)
+	assert.Equal(t, "ls\r", records[0].Data)
+	assert.Equal(t, streamOutput, records[1].Stream)
+	assert.Equal(t, "file.txt\r\n", records[1].Data)
+	assert.False(t, fileutil.Exists(filepath.Join(spool, "session-1.part")))
+}
+
+func TestNilRecorderRecordsNothing(t *testing.T) {
+	var recorder *Recorder
+	recorder.RecordInput(log.NewMockLog(), []byte("ls"))
+	recorder.Close(log.NewMockLog())
+}
+
+func TestArchiveUploadsCompleteRecordings(t *testing.T) {
+	spool, restore := stubSpool(t)
+	defer restore()
+	assert.NoError(t, fileutil.MakeDirs(spool))
+	for _, name := range []string{"session-1.jsonl", "session-2.jsonl", "session-3.part"} {
+		assert.NoError(t, fileutil.WriteAllText(filepath.Join(spool, name), "{}"))
+	}
+
+	uploader := new(s3util.MockS3Uploader)
+	uploader.On("S3Upload", "bucket", "prefix/i-123/session-1.jsonl", filepath.Join(spool, "session-1.jsonl")).Return(nil)
+	uploader.On("S3Upload", "bucket", "prefix/i-123/session-2.jsonl", filepath.Join(spool, "session-2.jsonl")).Return(errors.New("access denied"))
+	defer stubUploader(uploader)()
+
+	config := appconfig.MgsConfig{SessionRecordingS3BucketName: "bucket", SessionRecordingS3KeyPrefix: "prefix"}
+	Archive(log.NewMockLog(), config, "i-123")
+
+	uploader.AssertExpectations(t)
+	assert.False(t, fileutil.Exists(filepath.Join(spool, "session-1.jsonl")))
+	// the failed upload is retried the next time, the session still running isn't archived
+	assert.True(t, fileutil.Exists(filepath.Join(spool, "session-2.jsonl")))
+	assert.True(t, fileutil.Exists(filepath.Join(spool, "session-3.part")))
+}
+
+func TestEnabled(t *testing.T) {
+	assert.False(t, Enabled(appconfig.MgsConfig{}))
+	assert.True(t, Enabled(appconfig.MgsConfig{SessionRecordingS3BucketName: "bucket"}))
+}
@@ -31,6 +31,8 @@ type ExponentialRetryer struct {
 	InitialDelayInMilli int
 	MaxDelayInMilli     int
 	MaxAttempts         int
+	// MaxElapsedInMilli stops retrying once the next attempt would start that long after the first one, 0 doesn't limit it
+	MaxElapsedInMilli int
 }
 
 // NextSleepTime calculates the next delay of retry.
@@ -42,6 +44,7 @@ func (retryer *ExponentialRetryer) NextSleepTime(attempt int) time.Duration {
 func (retryer *ExponentialRetryer) Call() (channel interface{}, err error) {
 	attempt := 0
 	failedAttemptsSoFar := 0
+	start := time.Now()
 	for {
 		channel, err := retryer.CallableFunc()
 		if err == nil || failedAttemptsSoFar == retryer.MaxAttempts {
@@ -53,6 +56,9 @@ func (retryer *ExponentialRetryer) Call() (channel interface{}, err error) {
 		} else {
 			attempt++
 		}
+		if retryer.MaxElapsedInMilli > 0 && time.Since(start)+sleep > time.Duration(retryer.MaxElapsedInMilli)*time.Millisecond {
+			return channel, err
+		}
 		time.Sleep(sleep)
 		failedAttemptsSoFar++
 	}
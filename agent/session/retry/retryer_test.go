@@ -44,6 +44,7 @@ func TestRepeatableExponentialRetryerRetriesForGivenNumberOfMaxAttempts(t *testi
 		initialDelayInMilli,
 		maxDelayInMilli,
 		maxAttempts,
+		0,
 	}
 
 	retryCounterInterface, err := retryer.Call()
@@ -52,3 +53,22 @@ func TestRepeatableExponentialRetryerRetriesForGivenNumberOfMaxAttempts(t *testi
 	assert.NotNil(t, err)
 	assert.Equal(t, retryCounter.TotalAttempts, maxAttempts+1)
 }
+
+func TestExponentialRetryerStopsAfterMaxElapsedTime(t *testing.T) {
+	totalAttempts = 0
+	retryer := ExponentialRetryer{
+		CallableFunc:        callableFunc,
+		GeometricRatio:      retryGeometricRatio,
+		InitialDelayInMilli: initialDelayInMilli,
+		MaxDelayInMilli:     maxDelayInMilli,
+		MaxAttempts:         -1,
+		MaxElapsedInMilli:   250,
+	}
+
+	retryCounterInterface, err := retryer.Call()
+
+	// the attempts start at 0ms and 100ms, the next one would start at 300ms
+	retryCounter := retryCounterInterface.(RetryCounter)
+	assert.NotNil(t, err)
+	assert.Equal(t, 2, retryCounter.TotalAttempts)
+}
@@ -109,6 +109,11 @@ func NewSession(context context.T) *Session {
 		messageGatewayServiceConfig.SessionWorkersLimit,
 		3, // TODO adjust this value
 		[]contracts.DocumentType{contracts.StartSession, contracts.TerminateSession})
+	sessionProcessor := newSessionLimiter(
+		log,
+		processor,
+		messageGatewayServiceConfig.MaxConcurrentSessions,
+		messageGatewayServiceConfig.MaxConcurrentSessionsPerUser)
 
 	controlChannel := &controlchannel.ControlChannel{}
 
@@ -118,7 +123,7 @@ func NewSession(context context.T) *Session {
 		name:           mgsConfig.SessionServiceName,
 		mgsConfig:      messageGatewayServiceConfig,
 		service:        mgsService,
-		processor:      processor,
+		processor:      sessionProcessor,
 		controlChannel: controlChannel,
 	}
 }
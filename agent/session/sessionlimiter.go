@@ -0,0 +1,160 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package session implements the core module to start web-socket connection with message gateway service.
+package session
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/framework/processor"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	mgsContracts "github.com/aws/amazon-ssm-agent/agent/session/contracts"
+)
+
+const (
+	maxSessionsReason        = "The instance already runs the maximum of %d concurrent sessions"
+	maxSessionsPerUserReason = "%v already runs the maximum of %d concurrent sessions on the instance"
+	// maxRejectedResults is the number of rejections waiting to be replied
+	maxRejectedResults = 100
+)
+
+// sessionLimiter wraps the session processor to reject the new sessions once the instance or their user runs the
+// maximum of concurrent sessions. The sessions count until their document result comes back from the processor.
+type sessionLimiter struct {
+	processor.Processor
+	log                log.T
+	maxSessions        int
+	maxSessionsPerUser int
+	rejected           chan contracts.DocumentResult
+	// lock guards sessions, the users of the running sessions by session id
+	lock     sync.Mutex
+	sessions map[string]string
+}
+
+// newSessionLimiter returns the processor limiting the concurrent sessions, a limit of 0 doesn't limit the sessions
+func newSessionLimiter(log log.T, sessionProcessor processor.Processor, maxSessions int, maxSessionsPerUser int) *sessionLimiter {
+	return &sessionLimiter{
+		Processor:          sessionProcessor,
+		log:                log,
+		maxSessions:        maxSessions,
+		maxSessionsPerUser: maxSessionsPerUser,
+		rejected:           make(chan contracts.DocumentResult, maxRejectedResults),
+		sessions:           map[string]string{},
+	}
+}
+
+// Start starts the processor, the results of the rejected sessions come along the results of the processor
+func (l *sessionLimiter) Start() (chan contracts.DocumentResult, error) {
+	processorResults, err := l.Processor.Start()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(chan contracts.DocumentResult)
+	go func() {
+		defer close(results)
+		for {
+			select {
+			case res, ok := <-processorResults:
+				if !ok {
+					return
+				}
+				if res.LastPlugin == "" {
+					l.release(res.MessageID)
+				}
+				results <- res
+			case res := <-l.rejected:
+				results <- res
+			}
+		}
+	}()
+	return results, nil
+}
+
+// Submit submits the session to the processor unless it exceeds one of the limits
+func (l *sessionLimiter) Submit(docState contracts.DocumentState) {
+	if docState.DocumentType == contracts.StartSession {
+		if reason := l.acquire(docState.DocumentInformation.MessageID); reason != "" {
+			l.log.Warnf("Rejecting session %v: %v", docState.DocumentInformation.MessageID, reason)
+			l.reject(docState, reason)
+			return
+		}
+	}
+	l.Processor.Submit(docState)
+}
+
+// acquire counts the session unless it exceeds one of the limits, it returns the reason of the rejection otherwise
+func (l *sessionLimiter) acquire(sessionId string) string {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	user := mgsContracts.SessionUser(sessionId)
+	if l.maxSessions > 0 && len(l.sessions) >= l.maxSessions {
+		return fmt.Sprintf(maxSessionsReason, l.maxSessions)
+	}
+	if l.maxSessionsPerUser > 0 {
+		userSessions := 0
+		for _, sessionUser := range l.sessions {
+			if sessionUser == user {
+				userSessions++
+			}
+		}
+		if userSessions >= l.maxSessionsPerUser {
+			return fmt.Sprintf(maxSessionsPerUserReason, user, l.maxSessionsPerUser)
+		}
+	}
+	l.sessions[sessionId] = user
+	return ""
+}
+
+// release stops counting the session once it completes
+func (l *sessionLimiter) release(sessionId string) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	delete(l.sessions, sessionId)
+}
+
+// reject replies the session failed with the reason of the rejection
+func (l *sessionLimiter) reject(docState contracts.DocumentState, reason string) {
+	res := contracts.DocumentResult{
+		DocumentName:    docState.DocumentInformation.DocumentName,
+		DocumentVersion: docState.DocumentInformation.DocumentVersion,
+		MessageID:       docState.DocumentInformation.MessageID,
+		PluginResults:   map[string]*contracts.PluginResult{},
+		Status:          contracts.ResultStatusFailed,
+		NPlugins:        len(docState.InstancePluginsInformation),
+	}
+	now := time.Now()
+	for _, plugin := range docState.InstancePluginsInformation {
+		res.PluginResults[plugin.Id] = &contracts.PluginResult{
+			PluginID:      plugin.Id,
+			PluginName:    plugin.Name,
+			Status:        contracts.ResultStatusFailed,
+			Code:          1,
+			Error:         reason,
+			StartDateTime: now,
+			EndDateTime:   now,
+		}
+		res.LastPlugin = plugin.Id
+	}
+
+	select {
+	case l.rejected <- res:
+	default:
+		l.log.Errorf("Too many rejected sessions waiting, dropping the reply of session %v", res.MessageID)
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package session implements the core module to start web-socket connection with message gateway service.
+package session
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	processorMock "github.com/aws/amazon-ssm-agent/agent/framework/processor/mock"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func sessionDocState(sessionId string) contracts.DocumentState {
+	return contracts.DocumentState{
+		DocumentInformation:        contracts.DocumentInfo{MessageID: sessionId},
+		DocumentType:               contracts.StartSession,
+		InstancePluginsInformation: []contracts.PluginState{{Id: "Standard_Stream", Name: "Standard_Stream"}},
+	}
+}
+
+func TestSessionLimiter(t *testing.T) {
+	processorResults := make(chan contracts.DocumentResult)
+	mockProcessor := new(processorMock.MockedProcessor)
+	mockProcessor.On("Start").Return(processorResults, nil)
+	mockProcessor.On("Submit", mock.Anything).Return()
+
+	limiter := newSessionLimiter(log.NewMockLog(), mockProcessor, 3, 2)
+	results, err := limiter.Start()
+	assert.Nil(t, err)
+
+	limiter.Submit(sessionDocState("alice-1"))
+	limiter.Submit(sessionDocState("alice-2"))
+	limiter.Submit(sessionDocState("alice-3"))
+	res := <-results
+	assert.Equal(t, "alice-3", res.MessageID)
+	assert.Equal(t, contracts.ResultStatusFailed, res.Status)
+	assert.Equal(t, "Standard_Stream", res.LastPlugin)
+	assert.Equal(t, fmt.Sprintf(maxSessionsPerUserReason, "alice", 2), res.PluginResults["Standard_Stream"].Error)
+
+	limiter.Submit(sessionDocState("bob-1"))
+	limiter.Submit(sessionDocState("carol-1"))
+	res = <-results
+	assert.Equal(t, "carol-1", res.MessageID)
+	assert.Equal(t, fmt.Sprintf(maxSessionsReason, 3), res.PluginResults["Standard_Stream"].Error)
+
+	// the sessions stop counting once their document result comes back
+	processorResults <- contracts.DocumentResult{MessageID: "bob-1", Status: contracts.ResultStatusSuccess}
+	res = <-results
+	assert.Equal(t, "bob-1", res.MessageID)
+	limiter.Submit(sessionDocState("carol-1"))
+
+	mockProcessor.AssertNumberOfCalls(t, "Submit", 4)
+	close(processorResults)
+	_, open := <-results
+	assert.False(t, open)
+}
@@ -29,6 +29,7 @@ import (
 
 type IWinPTY interface {
 	SetSize(ws_col, ws_row uint32) error
+	Wait() (uint32, error)
 	Close() error
 }
 
@@ -189,7 +190,7 @@ func (winpty *WinPTY) spawnProcess(cmdLine string) (err error) {
 	var createProcessErr uint32
 	spawnProcess, _, lastErr := winpty_spawn.Call(
 		winpty.agent, spawnConfig,
-		uintptr(0),
+		uintptr(unsafe.Pointer(&winpty.processHandle)),
 		uintptr(0),
 		uintptr(unsafe.Pointer(&createProcessErr)),
 		uintptr(unsafe.Pointer(&errorPtr)))
@@ -238,6 +239,22 @@ func (winpty *WinPTY) SetSize(ws_col, ws_row uint32) (err error) {
 	return nil
 }
 
+//Wait waits for the spawned process to exit and returns its exit code.
+func (winpty *WinPTY) Wait() (exitCode uint32, err error) {
+	if winpty == nil || winpty.processHandle == 0 {
+		return 0, fmt.Errorf("Process handle unavailable.")
+	}
+
+	handle := syscall.Handle(winpty.processHandle)
+	if _, err = syscall.WaitForSingleObject(handle, syscall.INFINITE); err != nil {
+		return 0, fmt.Errorf("Unable to wait for process. %s", err)
+	}
+	if err = syscall.GetExitCodeProcess(handle, &exitCode); err != nil {
+		return 0, fmt.Errorf("Unable to get process exit code. %s", err)
+	}
+	return exitCode, nil
+}
+
 //Close closes stdin, stdout and winpty process handle.
 func (winpty *WinPTY) Close() (err error) {
 	if winpty == nil || winpty.closed {
@@ -246,6 +263,11 @@ func (winpty *WinPTY) Close() (err error) {
 
 	winpty_free.Call(winpty.agent)
 
+	if winpty.processHandle != 0 {
+		syscall.CloseHandle(syscall.Handle(winpty.processHandle))
+		winpty.processHandle = 0
+	}
+
 	if winpty.StdIn != nil {
 		if err := winpty.StdIn.Close(); err != nil {
 			return fmt.Errorf("Unable to close stdin. %s", err)
@@ -187,6 +187,29 @@ func (_m *Service) GetParameters(_a0 log.T, paramNames []string) (*ssm.GetParame
 	return r0, r1
 }
 
+// GetParameterHistory provides a mock function with given fields: _a0, paramName, nextToken
+func (_m *Service) GetParameterHistory(_a0 log.T, paramName string, nextToken *string) (*ssm.GetParameterHistoryOutput, error) {
+	ret := _m.Called(_a0, paramName, nextToken)
+
+	var r0 *ssm.GetParameterHistoryOutput
+	if rf, ok := ret.Get(0).(func(log.T, string, *string) *ssm.GetParameterHistoryOutput); ok {
+		r0 = rf(_a0, paramName, nextToken)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ssm.GetParameterHistoryOutput)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(log.T, string, *string) error); ok {
+		r1 = rf(_a0, paramName, nextToken)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ListAssociations provides a mock function with given fields: _a0, instanceID
 func (_m *Service) ListAssociations(_a0 log.T, instanceID string) (*ssm.ListAssociationsOutput, error) {
 	ret := _m.Called(_a0, instanceID)
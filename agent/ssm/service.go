@@ -24,6 +24,7 @@ import (
 	"github.com/aws/amazon-ssm-agent/agent/log"
 	"github.com/aws/amazon-ssm-agent/agent/platform"
 	"github.com/aws/amazon-ssm-agent/agent/sdkutil"
+	"github.com/aws/amazon-ssm-agent/agent/tracing"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
@@ -72,6 +73,7 @@ type Service interface {
 	UpdateEmptyInstanceInformation(log log.T, agentVersion, agentName string) (response *ssm.UpdateInstanceInformationOutput, err error)
 	GetParameters(log log.T, paramNames []string) (response *ssm.GetParametersOutput, err error)
 	GetDecryptedParameters(log log.T, paramNames []string) (response *ssm.GetParametersOutput, err error)
+	GetParameterHistory(log log.T, paramName string, nextToken *string) (response *ssm.GetParameterHistoryOutput, err error)
 }
 
 var ssmStopPolicy *sdkutil.StopPolicy
@@ -116,6 +118,7 @@ func NewService() Service {
 	}
 	sess := session.New(awsConfig)
 	sess.Handlers.Build.PushBack(request.MakeAddToUserAgentHandler(appConfig.Agent.Name, appConfig.Agent.Version))
+	tracing.TraceRequests(&sess.Handlers)
 
 	ssmService := ssm.New(sess)
 	return NewSSMService(ssmService)
@@ -530,3 +533,20 @@ func (svc *sdkService) GetDecryptedParameters(log log.T, paramNames []string) (r
 	}
 	return
 }
+
+func (svc *sdkService) GetParameterHistory(log log.T, paramName string, nextToken *string) (response *ssm.GetParameterHistoryOutput, err error) {
+	serviceParams := ssm.GetParameterHistoryInput{
+		Name:      aws.String(paramName),
+		NextToken: nextToken,
+	}
+
+	log.Debugf("Calling GetParameterHistory API with params - %v", serviceParams)
+
+	if response, err = svc.sdk.GetParameterHistory(&serviceParams); err != nil {
+		errorString := fmt.Errorf("Encountered error while calling GetParameterHistory API. Error: %v", err)
+		log.Debug(err)
+		sdkutil.HandleAwsError(log, err, ssmStopPolicy)
+		return nil, errorString
+	}
+	return
+}
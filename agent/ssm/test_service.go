@@ -145,6 +145,12 @@ func (m *Mock) GetDecryptedParameters(log log.T, paramNames []string) (response
 	return args.Get(0).(*ssm.GetParametersOutput), args.Error(1)
 }
 
+// GetParameterHistory mocks the GetParameterHistory function.
+func (m *Mock) GetParameterHistory(log log.T, paramName string, nextToken *string) (response *ssm.GetParameterHistoryOutput, err error) {
+	args := m.Called(log, paramName, nextToken)
+	return args.Get(0).(*ssm.GetParameterHistoryOutput), args.Error(1)
+}
+
 // PutComplianceItem mocks the PutComplianceItem function
 func (m *Mock) PutComplianceItems(
 	log log.T,
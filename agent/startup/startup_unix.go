@@ -24,8 +24,6 @@ import (
 	"github.com/aws/amazon-ssm-agent/agent/startup/serialport"
 	"github.com/aws/amazon-ssm-agent/agent/version"
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/ec2metadata"
-	"github.com/aws/aws-sdk-go/aws/session"
 )
 
 const (
@@ -40,7 +38,7 @@ const (
 func (p *Processor) IsAllowed() bool {
 	// check if metadata is reachable which indicates the instance is in EC2.
 	// maximum retry is 10 to ensure the failure/error is not caused by arbitrary reason.
-	ec2MetadataService := ec2metadata.New(session.New(aws.NewConfig().WithMaxRetries(10)))
+	ec2MetadataService := platform.NewEC2MetadataService(aws.NewConfig().WithMaxRetries(10))
 	if metadata, err := ec2MetadataService.GetMetadata(""); err != nil || metadata == "" {
 		return false
 	}
@@ -14,6 +14,7 @@
 package task
 
 import (
+	"container/heap"
 	"fmt"
 	"sync"
 	"time"
@@ -28,6 +29,10 @@ type Pool interface {
 	// Returns an error if a job with the same name already exists.
 	Submit(log log.T, jobID string, job Job) error
 
+	// SubmitWithPriority schedules a job like Submit, queued jobs with a higher priority are started first.
+	// Submit uses the DefaultJobPriority. Both block while maxQueuedJobs jobs are waiting for a worker.
+	SubmitWithPriority(log log.T, jobID string, job Job, priority int) error
+
 	// Cancel cancels the given job. Jobs that have not started yet will never be started.
 	// Jobs that are running will have their CancelFlag set to the Canceled state.
 	// It is the responsibility of the job to terminate within a reasonable time.
@@ -47,9 +52,16 @@ type Pool interface {
 	HasJob(jobID string) bool
 }
 
+// DefaultJobPriority is the priority of jobs submitted without a priority
+const DefaultJobPriority = 0
+
+// maxQueuedJobs is the number of jobs waiting for a worker, the submitters block until a queued job is started
+const maxQueuedJobs = 100
+
 // pool implements a task pool where all jobs are managed by a root task
 type pool struct {
 	log            log.T
+	submitted      chan JobToken
 	jobQueue       chan JobToken
 	nWorkers       int
 	doneWorker     chan struct{}
@@ -66,6 +78,9 @@ type JobToken struct {
 	job        Job
 	cancelFlag *ChanneledCancelFlag
 	log        log.T
+	priority   int
+	// seq orders the jobs of the same priority by submission
+	seq uint64
 }
 
 // NewPool creates a new task pool and launches maxParallel workers.
@@ -74,6 +89,7 @@ type JobToken struct {
 func NewPool(log log.T, maxParallel int, cancelWaitDuration time.Duration, clock times.Clock) Pool {
 	p := &pool{
 		log:            log,
+		submitted:      make(chan JobToken),
 		jobQueue:       make(chan JobToken),
 		nWorkers:       maxParallel,
 		doneWorker:     make(chan struct{}),
@@ -91,6 +107,7 @@ func NewPool(log log.T, maxParallel int, cancelWaitDuration time.Duration, clock
 
 	// start the workers
 	p.start(processor)
+	go p.dispatch()
 
 	return p
 }
@@ -106,7 +123,7 @@ func (p *pool) Shutdown() {
 		// close the channel to makes all workers terminate once the pending
 		// jobs have been consumed (the pending jobs are in the Canceled state
 		// so they will simply be discarded)
-		close(p.jobQueue)
+		close(p.submitted)
 		p.isShutdown = true
 	}
 }
@@ -158,6 +175,42 @@ func (p *pool) workerDone() {
 	p.doneWorker <- struct{}{}
 }
 
+// dispatch hands the submitted jobs to the workers, highest priority first.
+// The job queue is closed once the pool is shut down and all the queued jobs have been handed off.
+func (p *pool) dispatch() {
+	queue := &jobHeap{}
+	submitted := p.submitted
+	var seq uint64
+	for submitted != nil || queue.Len() > 0 {
+		// a nil channel is never selected, so nothing is handed off while the queue is empty
+		// and nothing is accepted while the queue is full
+		var next chan JobToken
+		var top JobToken
+		if queue.Len() > 0 {
+			next = p.jobQueue
+			top = (*queue)[0]
+		}
+		receive := submitted
+		if queue.Len() >= maxQueuedJobs {
+			receive = nil
+		}
+
+		select {
+		case token, ok := <-receive:
+			if !ok {
+				submitted = nil
+				continue
+			}
+			token.seq = seq
+			seq++
+			heap.Push(queue, token)
+		case next <- top:
+			heap.Pop(queue)
+		}
+	}
+	close(p.jobQueue)
+}
+
 // worker processes jobs from a channel.
 func worker(workerName string, queue chan JobToken, processor func(JobToken)) {
 	for token := range queue {
@@ -169,17 +222,23 @@ func worker(workerName string, queue chan JobToken, processor func(JobToken)) {
 
 // Submit adds a job to the execution queue of this pool.
 func (p *pool) Submit(log log.T, jobID string, job Job) (err error) {
+	return p.SubmitWithPriority(log, jobID, job, DefaultJobPriority)
+}
+
+// SubmitWithPriority adds a job to the execution queue of this pool ahead of the queued jobs with a lower priority.
+func (p *pool) SubmitWithPriority(log log.T, jobID string, job Job, priority int) (err error) {
 	token := JobToken{
 		id:         jobID,
 		job:        job,
 		cancelFlag: NewChanneledCancelFlag(),
 		log:        log,
+		priority:   priority,
 	}
 	err = p.jobStore.AddJob(jobID, &token)
 	if err != nil {
 		return
 	}
-	p.jobQueue <- token
+	p.submitted <- token
 	return
 }
 
@@ -224,3 +283,29 @@ func (p *pool) ShutDownAll() {
 		token.cancelFlag.Set(ShutDown)
 	}
 }
+
+// jobHeap orders the queued jobs by priority, and by submission for jobs of the same priority
+type jobHeap []JobToken
+
+func (h jobHeap) Len() int { return len(h) }
+
+func (h jobHeap) Less(i, j int) bool {
+	if h[i].priority != h[j].priority {
+		return h[i].priority > h[j].priority
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h jobHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *jobHeap) Push(x interface{}) {
+	*h = append(*h, x.(JobToken))
+}
+
+func (h *jobHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	token := old[n-1]
+	*h = old[:n-1]
+	return token
+}
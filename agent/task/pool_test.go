@@ -119,3 +119,67 @@ func exercisePool(t *testing.T, pool Pool, jobID string, shouldCancel bool) {
 	// see that job completes
 	assert.True(t, <-jobState)
 }
+
+func TestPoolStartsHigherPriorityJobsFirst(t *testing.T) {
+	pool := NewPool(logger, 1, 100*time.Millisecond, times.DefaultClock)
+	defer pool.Shutdown()
+
+	// occupy the only worker so the following jobs are queued
+	started := make(chan bool)
+	release := make(chan bool)
+	assert.Nil(t, pool.Submit(logger, "blocking", func(CancelFlag) {
+		started <- true
+		<-release
+	}))
+	<-started
+
+	done := make(chan string, 3)
+	submit := func(jobID string, priority int) {
+		assert.Nil(t, pool.SubmitWithPriority(logger, jobID, func(CancelFlag) { done <- jobID }, priority))
+	}
+	submit("inventory", -10)
+	submit("command", DefaultJobPriority)
+	submit("patching", 10)
+	close(release)
+
+	assert.Equal(t, "patching", <-done)
+	assert.Equal(t, "command", <-done)
+	assert.Equal(t, "inventory", <-done)
+}
+
+func TestPoolBlocksSubmitWhenQueueIsFull(t *testing.T) {
+	pool := NewPool(logger, 1, 100*time.Millisecond, times.DefaultClock)
+	defer pool.Shutdown()
+
+	// occupy the only worker so the following jobs are queued
+	started := make(chan bool)
+	release := make(chan bool)
+	assert.Nil(t, pool.Submit(logger, "blocking", func(CancelFlag) {
+		started <- true
+		<-release
+	}))
+	<-started
+
+	for i := 0; i < maxQueuedJobs; i++ {
+		assert.Nil(t, pool.Submit(logger, fmt.Sprintf("queued-%d", i), func(CancelFlag) {}))
+	}
+
+	submitted := make(chan bool)
+	go func() {
+		assert.Nil(t, pool.Submit(logger, "overflow", func(CancelFlag) {}))
+		submitted <- true
+	}()
+	select {
+	case <-submitted:
+		assert.Fail(t, "the job was queued beyond maxQueuedJobs")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// the job is queued once the worker starts the queued jobs
+	close(release)
+	select {
+	case <-submitted:
+	case <-time.After(time.Second):
+		assert.Fail(t, "the job wasn't queued once the queue had room")
+	}
+}
@@ -33,6 +33,11 @@ func (mockPool *MockedPool) Submit(log log.T, jobID string, job Job) error {
 	return mockPool.Called(log, jobID, job).Error(0)
 }
 
+// SubmitWithPriority mocks the method with the same name.
+func (mockPool *MockedPool) SubmitWithPriority(log log.T, jobID string, job Job, priority int) error {
+	return mockPool.Called(log, jobID, job, priority).Error(0)
+}
+
 // Cancel mocks the method with the same name.
 func (mockPool *MockedPool) Cancel(jobID string) bool {
 	return mockPool.Called(jobID).Bool(0)
@@ -0,0 +1,83 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package times provides a set of utilities related to processing time.
+package times
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const dailyWindowLayout = "15:04"
+
+// DailyWindow is a window of time of day repeating every day
+type DailyWindow struct {
+	start time.Duration
+	end   time.Duration
+}
+
+// ParseDailyWindow parses a window in the "HH:MM-HH:MM" format, the window wraps around midnight if it ends before it starts
+func ParseDailyWindow(window string) (*DailyWindow, error) {
+	parts := strings.Split(window, "-")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("window %v is not in the HH:MM-HH:MM format", window)
+	}
+
+	start, err := parseTimeOfDay(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("window %v has invalid start, %v", window, err)
+	}
+	end, err := parseTimeOfDay(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("window %v has invalid end, %v", window, err)
+	}
+	if start == end {
+		return nil, fmt.Errorf("window %v is empty", window)
+	}
+
+	return &DailyWindow{start: start, end: end}, nil
+}
+
+func parseTimeOfDay(value string) (time.Duration, error) {
+	parsed, err := time.Parse(dailyWindowLayout, strings.TrimSpace(value))
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(parsed.Hour())*time.Hour + time.Duration(parsed.Minute())*time.Minute, nil
+}
+
+// Contains returns true if the given time is inside the window
+func (w *DailyWindow) Contains(t time.Time) bool {
+	offset := timeOfDay(t)
+	if w.start < w.end {
+		return offset >= w.start && offset < w.end
+	}
+	return offset >= w.start || offset < w.end
+}
+
+// NextStart returns the next time the window opens after the given time
+func (w *DailyWindow) NextStart(t time.Time) time.Time {
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	start := midnight.Add(w.start)
+	if !start.After(t) {
+		start = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location()).Add(w.start)
+	}
+	return start
+}
+
+func timeOfDay(t time.Time) time.Duration {
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute +
+		time.Duration(t.Second())*time.Second + time.Duration(t.Nanosecond())
+}
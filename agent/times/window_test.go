@@ -0,0 +1,58 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package times provides a set of utilities related to processing time.
+package times
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDailyWindowRejectsInvalidWindows(t *testing.T) {
+	for _, window := range []string{"02:00", "02:00-25:00", "foo-bar", "02:00-02:00", "02:00-03:00-04:00"} {
+		parsed, err := ParseDailyWindow(window)
+		assert.Nil(t, parsed, window)
+		assert.NotNil(t, err, window)
+	}
+}
+
+func TestDailyWindowContains(t *testing.T) {
+	window, err := ParseDailyWindow("02:00-04:00")
+	assert.Nil(t, err)
+
+	assert.False(t, window.Contains(time.Date(2017, 5, 1, 1, 59, 0, 0, time.UTC)))
+	assert.True(t, window.Contains(time.Date(2017, 5, 1, 2, 0, 0, 0, time.UTC)))
+	assert.True(t, window.Contains(time.Date(2017, 5, 1, 3, 59, 59, 0, time.UTC)))
+	assert.False(t, window.Contains(time.Date(2017, 5, 1, 4, 0, 0, 0, time.UTC)))
+}
+
+func TestDailyWindowWrapsAroundMidnight(t *testing.T) {
+	window, err := ParseDailyWindow("22:30 - 01:00")
+	assert.Nil(t, err)
+
+	assert.True(t, window.Contains(time.Date(2017, 5, 1, 23, 0, 0, 0, time.UTC)))
+	assert.True(t, window.Contains(time.Date(2017, 5, 1, 0, 30, 0, 0, time.UTC)))
+	assert.False(t, window.Contains(time.Date(2017, 5, 1, 12, 0, 0, 0, time.UTC)))
+}
+
+func TestDailyWindowNextStart(t *testing.T) {
+	window, err := ParseDailyWindow("02:00-04:00")
+	assert.Nil(t, err)
+
+	assert.Equal(t, time.Date(2017, 5, 1, 2, 0, 0, 0, time.UTC), window.NextStart(time.Date(2017, 5, 1, 1, 0, 0, 0, time.UTC)))
+	assert.Equal(t, time.Date(2017, 5, 2, 2, 0, 0, 0, time.UTC), window.NextStart(time.Date(2017, 5, 1, 9, 0, 0, 0, time.UTC)))
+	assert.Equal(t, time.Date(2017, 6, 1, 2, 0, 0, 0, time.UTC), window.NextStart(time.Date(2017, 5, 31, 9, 0, 0, 0, time.UTC)))
+}
@@ -0,0 +1,293 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/version"
+)
+
+const (
+	// serviceName is the service.name resource attribute of the spans
+	serviceName = "amazon-ssm-agent"
+	// scopeName is the name of the instrumentation scope of the spans
+	scopeName = "github.com/aws/amazon-ssm-agent/agent/tracing"
+	// tracesPath is the path of the OTLP/HTTP traces receiver
+	tracesPath = "/v1/traces"
+	// maxQueuedSpans is the number of ended spans kept until they are exported, the spans ended beyond are dropped
+	maxQueuedSpans = 2048
+	// maxBatchSize is the number of spans exported in one request
+	maxBatchSize = 512
+	// statusCodeError is the OTLP status code of the failed spans
+	statusCodeError = 2
+)
+
+// exportInterval and httpClient are assigned to variables to allow unit tests to override them
+var exportInterval = 5 * time.Second
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// exporter posts the ended spans in batches to the collector
+type exporter struct {
+	log      log.T
+	url      string
+	lock     sync.Mutex
+	spans    []*Span
+	dropped  int
+	flush    chan struct{}
+	stop     chan struct{}
+	stopped  chan struct{}
+	resource []attribute
+}
+
+var (
+	exporterLock    sync.Mutex
+	currentExporter *exporter
+)
+
+// Configure starts exporting the spans to the OTLP/HTTP receiver of the endpoint, an empty endpoint leaves
+// tracing disabled
+func Configure(log log.T, endpoint string) {
+	endpoint = strings.TrimRight(strings.TrimSpace(endpoint), "/")
+	if endpoint == "" {
+		return
+	}
+
+	exporterLock.Lock()
+	defer exporterLock.Unlock()
+	if currentExporter != nil {
+		return
+	}
+	currentExporter = &exporter{
+		log:     log,
+		url:     endpoint + tracesPath,
+		flush:   make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+		resource: toAttributes(map[string]string{
+			"service.name":            serviceName,
+			"service.version":         version.Version,
+			"process.executable.name": filepath.Base(os.Args[0]),
+			"process.pid":             strconv.Itoa(os.Getpid()),
+		}),
+	}
+	log.Infof("Exporting the traces to %v", currentExporter.url)
+	go currentExporter.run()
+}
+
+// Shutdown exports the queued spans and disables tracing
+func Shutdown() {
+	exporterLock.Lock()
+	e := currentExporter
+	currentExporter = nil
+	exporterLock.Unlock()
+	if e == nil {
+		return
+	}
+	close(e.stop)
+	<-e.stopped
+}
+
+// enabled tells whether an exporter is configured
+func enabled() bool {
+	exporterLock.Lock()
+	defer exporterLock.Unlock()
+	return currentExporter != nil
+}
+
+// queue queues the ended span for export
+func queue(span *Span) {
+	exporterLock.Lock()
+	e := currentExporter
+	exporterLock.Unlock()
+	if e == nil {
+		return
+	}
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if len(e.spans) >= maxQueuedSpans {
+		e.dropped++
+		return
+	}
+	e.spans = append(e.spans, span)
+	if len(e.spans) >= maxBatchSize {
+		select {
+		case e.flush <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// run exports the queued spans every export interval, when a batch is full and when the exporter stops
+func (e *exporter) run() {
+	defer close(e.stopped)
+	ticker := time.NewTicker(exportInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-e.stop:
+			e.export()
+			return
+		case <-ticker.C:
+		case <-e.flush:
+		}
+		e.export()
+	}
+}
+
+// export posts the queued spans, the spans the collector doesn't receive are lost
+func (e *exporter) export() {
+	for {
+		e.lock.Lock()
+		count := len(e.spans)
+		if count > maxBatchSize {
+			count = maxBatchSize
+		}
+		batch := e.spans[:count]
+		e.spans = e.spans[count:]
+		dropped := e.dropped
+		e.dropped = 0
+		e.lock.Unlock()
+
+		if dropped > 0 {
+			e.log.Warnf("Dropped %v spans, the export queue was full", dropped)
+		}
+		if len(batch) == 0 {
+			return
+		}
+		if err := e.post(batch); err != nil {
+			e.log.Warnf("Failed to export %v spans to %v, %v", len(batch), e.url, err)
+		}
+	}
+}
+
+// post sends the spans to the collector in the OTLP/HTTP JSON encoding
+func (e *exporter) post(batch []*Span) error {
+	spans := make([]otlpSpan, 0, len(batch))
+	for _, span := range batch {
+		spans = append(spans, span.toOTLP())
+	}
+	body, err := json.Marshal(otlpRequest{
+		ResourceSpans: []resourceSpans{{
+			Resource:   resource{Attributes: e.resource},
+			ScopeSpans: []scopeSpans{{Scope: scope{Name: scopeName, Version: version.Version}, Spans: spans}},
+		}},
+	})
+	if err != nil {
+		return err
+	}
+
+	response, err := httpClient.Post(e.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode/100 != 2 {
+		return fmt.Errorf("collector responded %v", response.Status)
+	}
+	return nil
+}
+
+// otlpRequest is the ExportTraceServiceRequest of the OTLP/HTTP JSON encoding
+type otlpRequest struct {
+	ResourceSpans []resourceSpans `json:"resourceSpans"`
+}
+
+type resourceSpans struct {
+	Resource   resource     `json:"resource"`
+	ScopeSpans []scopeSpans `json:"scopeSpans"`
+}
+
+type resource struct {
+	Attributes []attribute `json:"attributes"`
+}
+
+type scopeSpans struct {
+	Scope scope      `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type scope struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+type otlpSpan struct {
+	TraceID           string      `json:"traceId"`
+	SpanID            string      `json:"spanId"`
+	ParentSpanID      string      `json:"parentSpanId,omitempty"`
+	Name              string      `json:"name"`
+	Kind              SpanKind    `json:"kind"`
+	StartTimeUnixNano string      `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string      `json:"endTimeUnixNano"`
+	Attributes        []attribute `json:"attributes,omitempty"`
+	Status            status      `json:"status"`
+}
+
+type status struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type attribute struct {
+	Key   string         `json:"key"`
+	Value attributeValue `json:"value"`
+}
+
+type attributeValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+// toOTLP returns the ended span in the OTLP/HTTP JSON encoding
+func (s *Span) toOTLP() otlpSpan {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	span := otlpSpan{
+		TraceID:           s.context.TraceID,
+		SpanID:            s.context.SpanID,
+		ParentSpanID:      s.parentSpanID,
+		Name:              s.name,
+		Kind:              s.kind,
+		StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(s.end.UnixNano(), 10),
+		Attributes:        toAttributes(s.attributes),
+	}
+	if s.failed {
+		span.Status = status{Code: statusCodeError, Message: s.errorMessage}
+	}
+	return span
+}
+
+// toAttributes returns the attributes sorted by key, the attributes with an empty value are skipped
+func toAttributes(values map[string]string) (attributes []attribute) {
+	for key, value := range values {
+		if value != "" {
+			attributes = append(attributes, attribute{Key: key, Value: attributeValue{StringValue: value}})
+		}
+	}
+	sort.Slice(attributes, func(i, j int) bool { return attributes[i].Key < attributes[j].Key })
+	return
+}
@@ -0,0 +1,120 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/stretchr/testify/assert"
+)
+
+// collector records the OTLP requests it receives
+type collector struct {
+	lock     sync.Mutex
+	requests []otlpRequest
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var received otlpRequest
+	if r.URL.Path != tracesPath || json.NewDecoder(r.Body).Decode(&received) != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.requests = append(c.requests, received)
+}
+
+// spans returns the spans received by name
+func (c *collector) spans() map[string]otlpSpan {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	spans := make(map[string]otlpSpan)
+	for _, request := range c.requests {
+		for _, resourceSpan := range request.ResourceSpans {
+			for _, scopeSpan := range resourceSpan.ScopeSpans {
+				for _, span := range scopeSpan.Spans {
+					spans[span.Name] = span
+				}
+			}
+		}
+	}
+	return spans
+}
+
+func TestExport(t *testing.T) {
+	receiver := &collector{}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+	Configure(log.NewMockLog(), server.URL+"/")
+
+	document := Start("document", KindInternal, SpanContext{}, map[string]string{"ssm.document.name": "AWS-RunShellScript"})
+	plugin := Start("plugin", KindInternal, document.Context(), nil)
+	plugin.SetError("exit status 1")
+	plugin.End()
+	document.End()
+	document.End()
+	Shutdown()
+
+	spans := receiver.spans()
+	assert.Equal(t, 2, len(spans))
+	assert.Equal(t, 1, len(receiver.requests))
+	assert.Equal(t, serviceName, findAttribute(receiver.requests[0].ResourceSpans[0].Resource.Attributes, "service.name"))
+
+	exported := spans["document"]
+	assert.Equal(t, document.Context().TraceID, exported.TraceID)
+	assert.Equal(t, document.Context().SpanID, exported.SpanID)
+	assert.Equal(t, "", exported.ParentSpanID)
+	assert.Equal(t, KindInternal, exported.Kind)
+	assert.Equal(t, "AWS-RunShellScript", findAttribute(exported.Attributes, "ssm.document.name"))
+	assert.Equal(t, 0, exported.Status.Code)
+
+	exported = spans["plugin"]
+	assert.Equal(t, document.Context().SpanID, exported.ParentSpanID)
+	assert.Equal(t, status{Code: statusCodeError, Message: "exit status 1"}, exported.Status)
+}
+
+func TestExportDropsSpansBeyondQueue(t *testing.T) {
+	receiver := &collector{}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+	Configure(log.NewMockLog(), server.URL)
+
+	// hold the export so that the queue fills up
+	currentExporter.lock.Lock()
+	currentExporter.spans = make([]*Span, maxQueuedSpans)
+	currentExporter.lock.Unlock()
+	span := Start("document", KindInternal, SpanContext{}, nil)
+	span.End()
+	assert.Equal(t, 1, currentExporter.dropped)
+
+	currentExporter.lock.Lock()
+	currentExporter.spans = nil
+	currentExporter.lock.Unlock()
+	Shutdown()
+}
+
+func findAttribute(attributes []attribute, key string) string {
+	for _, attribute := range attributes {
+		if attribute.Key == key {
+			return attribute.Value.StringValue
+		}
+	}
+	return ""
+}
@@ -0,0 +1,63 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package otlp implements the core module exporting the traces of the agent to an OpenTelemetry collector.
+//
+// The document worker processes export the spans of their plugins and API calls themselves, this module
+// exports the spans of the documents and of the API calls of the agent process.
+package otlp
+
+import (
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/tracing"
+)
+
+const (
+	// Name is the core module name for the OTLP exporter
+	Name = "OtlpExporter"
+)
+
+// Exporter is the core module exporting the traces over OTLP/HTTP
+type Exporter struct {
+	context context.T
+}
+
+// NewExporter returns the OTLP exporter
+func NewExporter(context context.T) *Exporter {
+	return &Exporter{
+		context: context.With("[" + Name + "]"),
+	}
+}
+
+// ModuleName returns the name of the module
+func (e *Exporter) ModuleName() string {
+	return Name
+}
+
+// ModuleExecute starts exporting the traces if TracingEndpoint is set
+func (e *Exporter) ModuleExecute(context context.T) (err error) {
+	endpoint := e.context.AppConfig().Agent.TracingEndpoint
+	if endpoint == "" {
+		e.context.Log().Debug("OTLP exporter is disabled")
+		return nil
+	}
+	tracing.Configure(e.context.Log(), endpoint)
+	return nil
+}
+
+// ModuleRequestStop exports the remaining spans and stops tracing
+func (e *Exporter) ModuleRequestStop(stopType contracts.StopType) (err error) {
+	tracing.Shutdown()
+	return nil
+}
@@ -0,0 +1,54 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package otlp
+
+import (
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/tracing"
+	"github.com/stretchr/testify/assert"
+)
+
+func newContext(endpoint string) *context.Mock {
+	config := appconfig.SsmagentConfig{}
+	config.Agent.TracingEndpoint = endpoint
+	mockContext := new(context.Mock)
+	mockContext.On("Log").Return(log.NewMockLog())
+	mockContext.On("AppConfig").Return(config)
+	mockContext.On("With", "["+Name+"]").Return(mockContext)
+	return mockContext
+}
+
+func TestExporter(t *testing.T) {
+	ctx := newContext("http://127.0.0.1:1")
+	exporter := NewExporter(ctx)
+	assert.Equal(t, Name, exporter.ModuleName())
+	assert.Nil(t, exporter.ModuleExecute(ctx))
+	assert.NotNil(t, tracing.Start("document", tracing.KindInternal, tracing.SpanContext{}, nil))
+
+	assert.Nil(t, exporter.ModuleRequestStop(contracts.StopTypeSoftStop))
+	assert.Nil(t, tracing.Start("document", tracing.KindInternal, tracing.SpanContext{}, nil))
+}
+
+func TestExporterDisabled(t *testing.T) {
+	ctx := newContext("")
+	exporter := NewExporter(ctx)
+	assert.Nil(t, exporter.ModuleExecute(ctx))
+	assert.Nil(t, tracing.Start("document", tracing.KindInternal, tracing.SpanContext{}, nil))
+	assert.Nil(t, exporter.ModuleRequestStop(contracts.StopTypeSoftStop))
+}
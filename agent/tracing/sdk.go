@@ -0,0 +1,60 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws/request"
+)
+
+// spanKey is the key of the API call span in the context of the request
+type spanKey struct{}
+
+// TraceRequests records the requests of the service client as API call spans, children of the current span.
+// The requests made when there is no current span aren't recorded.
+func TraceRequests(handlers *request.Handlers) {
+	handlers.Build.PushFrontNamed(request.NamedHandler{Name: "ssm.TracingStartHandler", Fn: startRequestSpan})
+	handlers.Complete.PushBackNamed(request.NamedHandler{Name: "ssm.TracingEndHandler", Fn: endRequestSpan})
+}
+
+// startRequestSpan starts the span of the request, the request is only built once however many times it's retried
+func startRequestSpan(r *request.Request) {
+	span := StartChild(r.ClientInfo.ServiceName+"."+r.Operation.Name, KindClient, map[string]string{
+		"rpc.system":  "aws-api",
+		"rpc.service": r.ClientInfo.ServiceName,
+		"rpc.method":  r.Operation.Name,
+	})
+	if span != nil {
+		r.SetContext(context.WithValue(r.Context(), spanKey{}, span))
+	}
+}
+
+// endRequestSpan ends the span of the request once the request and its retries complete
+func endRequestSpan(r *request.Request) {
+	span, ok := r.Context().Value(spanKey{}).(*Span)
+	if !ok {
+		return
+	}
+	span.SetAttribute("aws.request_id", r.RequestID)
+	span.SetAttribute("aws.retry_count", strconv.Itoa(r.RetryCount))
+	if r.HTTPResponse != nil {
+		span.SetAttribute("http.status_code", strconv.Itoa(r.HTTPResponse.StatusCode))
+	}
+	if r.Error != nil {
+		span.SetError(r.Error.Error())
+	}
+	span.End()
+}
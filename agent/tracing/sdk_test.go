@@ -0,0 +1,64 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/client/metadata"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTraceRequests(t *testing.T) {
+	receiver := &collector{}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+	Configure(log.NewMockLog(), server.URL)
+
+	parent := ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	restore := SetCurrent(parent)
+	handlers := request.Handlers{}
+	TraceRequests(&handlers)
+	handlers.Send.PushBack(func(r *request.Request) {
+		r.HTTPResponse = &http.Response{StatusCode: http.StatusBadRequest}
+		r.RequestID = "e4b1c7a2"
+		r.Error = errors.New("ValidationException")
+	})
+	r := request.New(aws.Config{}, metadata.ClientInfo{ServiceName: "ssm"}, handlers, nil,
+		&request.Operation{Name: "ListAssociations"}, nil, nil)
+	assert.NotNil(t, r.Send())
+	restore()
+
+	// requests made without a current span aren't traced
+	r = request.New(aws.Config{}, metadata.ClientInfo{ServiceName: "ssm"}, handlers, nil,
+		&request.Operation{Name: "UpdateInstanceInformation"}, nil, nil)
+	r.Send()
+	Shutdown()
+
+	spans := receiver.spans()
+	assert.Equal(t, 1, len(spans))
+	exported := spans["ssm.ListAssociations"]
+	assert.Equal(t, parent.TraceID, exported.TraceID)
+	assert.Equal(t, parent.SpanID, exported.ParentSpanID)
+	assert.Equal(t, KindClient, exported.Kind)
+	assert.Equal(t, "e4b1c7a2", findAttribute(exported.Attributes, "aws.request_id"))
+	assert.Equal(t, "400", findAttribute(exported.Attributes, "http.status_code"))
+	assert.Equal(t, statusCodeError, exported.Status.Code)
+}
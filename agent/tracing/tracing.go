@@ -0,0 +1,196 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package tracing records the spans of the document executions, of their plugins and of the AWS API calls the plugins
+// make, and exports them with OTLP over HTTP to an OpenTelemetry collector.
+//
+// The agent starts a trace per document execution. The document worker process continues the trace from the
+// W3C traceparent of the document state, it sets the current span to the running plugin so that the API calls
+// of the plugin are recorded as its children. Tracing is disabled until an exporter is configured, every
+// function of the package is a no-op and every span is nil then.
+package tracing
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SpanKind tells whether the span is an internal operation or a call to a remote service
+type SpanKind int
+
+const (
+	// KindInternal is the kind of the document and plugin spans
+	KindInternal SpanKind = 1
+	// KindClient is the kind of the API call spans
+	KindClient SpanKind = 3
+)
+
+// SpanContext identifies a span and its trace
+type SpanContext struct {
+	TraceID string
+	SpanID  string
+}
+
+// IsValid tells whether the span context identifies a span
+func (c SpanContext) IsValid() bool {
+	return len(c.TraceID) == 32 && len(c.SpanID) == 16
+}
+
+// TraceParent returns the span context in the W3C traceparent format, empty if the span context isn't valid
+func (c SpanContext) TraceParent() string {
+	if !c.IsValid() {
+		return ""
+	}
+	return fmt.Sprintf("00-%v-%v-01", c.TraceID, c.SpanID)
+}
+
+// ParseTraceParent returns the span context of the W3C traceparent, the span context isn't valid if the
+// traceparent isn't
+func ParseTraceParent(traceParent string) (c SpanContext) {
+	parts := strings.Split(traceParent, "-")
+	if len(parts) != 4 || len(parts[1]) != 32 || len(parts[2]) != 16 {
+		return
+	}
+	if _, err := hex.DecodeString(parts[1] + parts[2]); err != nil {
+		return
+	}
+	return SpanContext{TraceID: parts[1], SpanID: parts[2]}
+}
+
+// Span is an operation of a trace, its methods may be called on a nil span
+type Span struct {
+	context      SpanContext
+	parentSpanID string
+	name         string
+	kind         SpanKind
+	start        time.Time
+	lock         sync.Mutex
+	end          time.Time
+	attributes   map[string]string
+	errorMessage string
+	failed       bool
+}
+
+// Start starts the span, as a child of the parent if the parent is valid or in a new trace otherwise.
+// The span is nil when tracing is disabled.
+func Start(name string, kind SpanKind, parent SpanContext, attributes map[string]string) *Span {
+	if !enabled() {
+		return nil
+	}
+	span := &Span{
+		context:    SpanContext{TraceID: parent.TraceID, SpanID: newID(8)},
+		name:       name,
+		kind:       kind,
+		start:      time.Now(),
+		attributes: make(map[string]string, len(attributes)),
+	}
+	if parent.IsValid() {
+		span.parentSpanID = parent.SpanID
+	} else {
+		span.context.TraceID = newID(16)
+	}
+	for key, value := range attributes {
+		span.attributes[key] = value
+	}
+	return span
+}
+
+// StartChild starts the span as a child of the current span, the span is nil if there is no current span
+func StartChild(name string, kind SpanKind, attributes map[string]string) *Span {
+	parent := Current()
+	if !parent.IsValid() {
+		return nil
+	}
+	return Start(name, kind, parent, attributes)
+}
+
+// Context returns the span context of the span
+func (s *Span) Context() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.context
+}
+
+// SetAttribute sets the attribute of the span
+func (s *Span) SetAttribute(key string, value string) {
+	if s == nil {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.attributes[key] = value
+}
+
+// SetError marks the span failed with the message
+func (s *Span) SetError(message string) {
+	if s == nil {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.failed = true
+	s.errorMessage = message
+}
+
+// End ends the span and queues it for export, ending a span more than once has no effect
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.lock.Lock()
+	if !s.end.IsZero() {
+		s.lock.Unlock()
+		return
+	}
+	s.end = time.Now()
+	s.lock.Unlock()
+	queue(s)
+}
+
+var (
+	currentLock sync.Mutex
+	current     SpanContext
+)
+
+// Current returns the current span of the process, which the API calls are recorded as children of.
+// The current span is only set in the document worker, which runs a single document at a time.
+func Current() SpanContext {
+	currentLock.Lock()
+	defer currentLock.Unlock()
+	return current
+}
+
+// SetCurrent sets the current span of the process and returns the function restoring the previous one
+func SetCurrent(c SpanContext) (restore func()) {
+	currentLock.Lock()
+	defer currentLock.Unlock()
+	previous := current
+	current = c
+	return func() {
+		currentLock.Lock()
+		defer currentLock.Unlock()
+		current = previous
+	}
+}
+
+// newID returns a random identifier of the given number of bytes as hex
+func newID(bytes int) string {
+	id := make([]byte, bytes)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,81 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTraceParent(t *testing.T) {
+	c := ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	assert.True(t, c.IsValid())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", c.TraceID)
+	assert.Equal(t, "00f067aa0ba902b7", c.SpanID)
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", c.TraceParent())
+
+	for _, invalid := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e473-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e47zz-00f067aa0ba902b7-01",
+	} {
+		c = ParseTraceParent(invalid)
+		assert.False(t, c.IsValid(), invalid)
+		assert.Equal(t, "", c.TraceParent())
+	}
+}
+
+func TestSpansDisabled(t *testing.T) {
+	Shutdown()
+	span := Start("document", KindInternal, SpanContext{}, nil)
+	assert.Nil(t, span)
+	// the methods of a nil span have no effect
+	span.SetAttribute("key", "value")
+	span.SetError("error")
+	span.End()
+	assert.False(t, span.Context().IsValid())
+}
+
+func TestStart(t *testing.T) {
+	defer Shutdown()
+	Configure(log.NewMockLog(), "http://127.0.0.1:1")
+
+	root := Start("document", KindInternal, SpanContext{}, map[string]string{"ssm.document.name": "AWS-RunShellScript"})
+	assert.True(t, root.Context().IsValid())
+	assert.Equal(t, "", root.parentSpanID)
+
+	child := Start("plugin", KindInternal, root.Context(), nil)
+	assert.Equal(t, root.Context().TraceID, child.Context().TraceID)
+	assert.Equal(t, root.Context().SpanID, child.parentSpanID)
+	assert.NotEqual(t, root.Context().SpanID, child.Context().SpanID)
+}
+
+func TestStartChild(t *testing.T) {
+	defer Shutdown()
+	Configure(log.NewMockLog(), "http://127.0.0.1:1")
+
+	assert.Nil(t, StartChild("ssm.ListAssociations", KindClient, nil))
+
+	parent := ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	restore := SetCurrent(parent)
+	span := StartChild("ssm.ListAssociations", KindClient, nil)
+	restore()
+
+	assert.Equal(t, parent.TraceID, span.Context().TraceID)
+	assert.Equal(t, parent.SpanID, span.parentSpanID)
+	assert.False(t, Current().IsValid())
+}
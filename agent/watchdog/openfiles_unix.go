@@ -0,0 +1,39 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// +build darwin freebsd linux netbsd openbsd
+
+package watchdog
+
+import "os"
+
+// fdDirectories list the open file descriptors of the process, /proc/self/fd on Linux and /dev/fd elsewhere
+var fdDirectories = []string{"/proc/self/fd", "/dev/fd"}
+
+// countOpenFiles returns the number of open file descriptors of the agent, -1 if they can't be counted
+func countOpenFiles() int {
+	for _, directory := range fdDirectories {
+		dir, err := os.Open(directory)
+		if err != nil {
+			continue
+		}
+		names, err := dir.Readdirnames(-1)
+		dir.Close()
+		if err != nil {
+			continue
+		}
+		// the descriptor reading the directory is listed as well
+		return len(names) - 1
+	}
+	return -1
+}
@@ -0,0 +1,37 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// +build windows
+
+package watchdog
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+var (
+	kernel32                  = syscall.NewLazyDLL("kernel32.dll")
+	procGetCurrentProcess     = kernel32.NewProc("GetCurrentProcess")
+	procGetProcessHandleCount = kernel32.NewProc("GetProcessHandleCount")
+)
+
+// countOpenFiles returns the number of open handles of the agent, -1 if they can't be counted
+func countOpenFiles() int {
+	process, _, _ := procGetCurrentProcess.Call()
+	var count uint32
+	if ret, _, _ := procGetProcessHandleCount.Call(process, uintptr(unsafe.Pointer(&count))); ret == 0 {
+		return -1
+	}
+	return int(count)
+}
@@ -0,0 +1,216 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package watchdog implements the core module watching the memory, goroutines and open files of the agent itself.
+//
+// When the usage stays above the configured thresholds the watchdog logs a diagnostic snapshot and requests
+// the agent to restart, the agent then stops its core modules, which saves their state, before restarting.
+package watchdog
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"runtime/pprof"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+)
+
+const (
+	// Name is the core module name for the watchdog
+	Name = "Watchdog"
+
+	// checkInterval is the interval between the checks of the resource usage
+	checkInterval = time.Minute
+
+	// breachesBeforeRestart is the number of consecutive checks above the thresholds that restart the agent,
+	// so that a short spike doesn't
+	breachesBeforeRestart = 3
+
+	megabyte = 1024 * 1024
+)
+
+var (
+	// getUsage is assigned to a variable to allow unit tests to override it
+	getUsage = currentUsage
+
+	restart     = make(chan struct{})
+	restartOnce sync.Once
+)
+
+// RestartRequested returns a channel that is closed once the watchdog requests the agent to restart
+func RestartRequested() <-chan struct{} {
+	return restart
+}
+
+// requestRestart closes the restart channel, it may be called many times
+func requestRestart() {
+	restartOnce.Do(func() {
+		close(restart)
+	})
+}
+
+// usage is the resource usage of the agent
+type usage struct {
+	memoryMB   int
+	goroutines int
+	// openFiles is the number of open file descriptors, or handles on Windows, -1 if they can't be counted
+	openFiles int
+}
+
+// thresholds are the resource usages above which the agent is restarted, 0 doesn't watch the resource
+type thresholds struct {
+	memoryMB   int
+	goroutines int
+	openFiles  int
+}
+
+// exceeded describes the resource usages above the thresholds
+func (t thresholds) exceeded(current usage) (exceeded []string) {
+	if t.memoryMB > 0 && current.memoryMB > t.memoryMB {
+		exceeded = append(exceeded, fmt.Sprintf("memory %v MB above %v MB", current.memoryMB, t.memoryMB))
+	}
+	if t.goroutines > 0 && current.goroutines > t.goroutines {
+		exceeded = append(exceeded, fmt.Sprintf("%v goroutines above %v", current.goroutines, t.goroutines))
+	}
+	if t.openFiles > 0 && current.openFiles > t.openFiles {
+		exceeded = append(exceeded, fmt.Sprintf("%v open files above %v", current.openFiles, t.openFiles))
+	}
+	return
+}
+
+// Watchdog is the core module restarting the agent when it uses too many resources
+type Watchdog struct {
+	context  context.T
+	lock     sync.Mutex
+	stop     chan struct{}
+	breaches int
+}
+
+// NewWatchdog returns the watchdog
+func NewWatchdog(context context.T) *Watchdog {
+	return &Watchdog{
+		context: context.With("[" + Name + "]"),
+	}
+}
+
+// ModuleName returns the name of the module
+func (w *Watchdog) ModuleName() string {
+	return Name
+}
+
+// ModuleExecute starts watching the resource usage if any threshold is set
+func (w *Watchdog) ModuleExecute(context context.T) (err error) {
+	log := w.context.Log()
+	config := w.context.AppConfig().Agent
+	limits := thresholds{
+		memoryMB:   config.WatchdogMaxMemoryMB,
+		goroutines: config.WatchdogMaxGoroutines,
+		openFiles:  config.WatchdogMaxOpenFiles,
+	}
+	if limits == (thresholds{}) {
+		log.Debug("Watchdog is disabled")
+		return nil
+	}
+
+	stop := make(chan struct{})
+	w.lock.Lock()
+	w.stop = stop
+	w.lock.Unlock()
+
+	log.Infof("Watching the agent memory above %v MB, goroutines above %v and open files above %v",
+		limits.memoryMB, limits.goroutines, limits.openFiles)
+	go w.watch(limits, stop)
+	return nil
+}
+
+// ModuleRequestStop stops watching the resource usage
+func (w *Watchdog) ModuleRequestStop(stopType contracts.StopType) (err error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.stop != nil {
+		close(w.stop)
+		w.stop = nil
+	}
+	return nil
+}
+
+// watch checks the resource usage until the watchdog stops or requests the agent to restart
+func (w *Watchdog) watch(limits thresholds, stop chan struct{}) {
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if w.check(limits) {
+				return
+			}
+		}
+	}
+}
+
+// check compares the resource usage with the thresholds and requests the agent to restart
+// once they were exceeded breachesBeforeRestart checks in a row, it returns true when it did
+func (w *Watchdog) check(limits thresholds) bool {
+	log := w.context.Log()
+	current := getUsage()
+	exceeded := limits.exceeded(current)
+	if len(exceeded) == 0 {
+		w.breaches = 0
+		return false
+	}
+
+	w.breaches++
+	log.Warnf("Agent resource usage above the watchdog thresholds (%v of %v checks): %v",
+		w.breaches, breachesBeforeRestart, strings.Join(exceeded, ", "))
+	if w.breaches < breachesBeforeRestart {
+		return false
+	}
+
+	log.Errorf("Restarting the agent, its resource usage stayed above the watchdog thresholds\n%v", diagnostics(current))
+	log.Flush()
+	requestRestart()
+	return true
+}
+
+// currentUsage returns the resource usage of the agent
+func currentUsage() usage {
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+	return usage{
+		memoryMB:   int(memStats.Sys / megabyte),
+		goroutines: runtime.NumGoroutine(),
+		openFiles:  countOpenFiles(),
+	}
+}
+
+// diagnostics describes the memory, the goroutines and the open files of the agent along with the stacks of the goroutines
+func diagnostics(current usage) string {
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+
+	var buffer bytes.Buffer
+	fmt.Fprintf(&buffer, "Memory: %v MB obtained from the system, %v MB of heap in use, %v heap objects, %v GC cycles\n",
+		memStats.Sys/megabyte, memStats.HeapInuse/megabyte, memStats.HeapObjects, memStats.NumGC)
+	fmt.Fprintf(&buffer, "Goroutines: %v, open files: %v\n", current.goroutines, current.openFiles)
+	pprof.Lookup("goroutine").WriteTo(&buffer, 1)
+	return buffer.String()
+}
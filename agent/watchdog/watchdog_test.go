@@ -0,0 +1,125 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package watchdog implements the core module watching the memory, goroutines and open files of the agent itself.
+package watchdog
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func newContext(config appconfig.SsmagentConfig) *context.Mock {
+	mockContext := new(context.Mock)
+	mockContext.On("Log").Return(log.NewMockLog())
+	mockContext.On("AppConfig").Return(config)
+	mockContext.On("With", "["+Name+"]").Return(mockContext)
+	return mockContext
+}
+
+// resetRestart lets the watchdog request a new restart
+func resetRestart() {
+	restart = make(chan struct{})
+	restartOnce = sync.Once{}
+}
+
+func restartRequested() bool {
+	select {
+	case <-RestartRequested():
+		return true
+	default:
+		return false
+	}
+}
+
+func TestThresholdsExceeded(t *testing.T) {
+	limits := thresholds{memoryMB: 100, goroutines: 1000}
+
+	assert.Empty(t, limits.exceeded(usage{memoryMB: 100, goroutines: 1000, openFiles: 5000}))
+	assert.Equal(t, []string{"memory 101 MB above 100 MB", "1001 goroutines above 1000"},
+		limits.exceeded(usage{memoryMB: 101, goroutines: 1001}))
+
+	limits = thresholds{openFiles: 10}
+	assert.Equal(t, []string{"11 open files above 10"}, limits.exceeded(usage{memoryMB: 5000, openFiles: 11}))
+	assert.Empty(t, limits.exceeded(usage{openFiles: -1}))
+}
+
+func TestCheckRestartsAfterConsecutiveBreaches(t *testing.T) {
+	resetRestart()
+	defer resetRestart()
+	defer func() { getUsage = currentUsage }()
+	current := usage{memoryMB: 200, goroutines: 10, openFiles: 10}
+	getUsage = func() usage { return current }
+
+	watchdog := NewWatchdog(newContext(appconfig.SsmagentConfig{}))
+	limits := thresholds{memoryMB: 100}
+
+	assert.False(t, watchdog.check(limits))
+	assert.False(t, watchdog.check(limits))
+
+	// the usage drops back below the thresholds
+	current.memoryMB = 50
+	assert.False(t, watchdog.check(limits))
+	assert.Equal(t, 0, watchdog.breaches)
+
+	current.memoryMB = 200
+	for i := 1; i < breachesBeforeRestart; i++ {
+		assert.False(t, watchdog.check(limits))
+		assert.False(t, restartRequested())
+	}
+	assert.True(t, watchdog.check(limits))
+	assert.True(t, restartRequested())
+}
+
+func TestDiagnostics(t *testing.T) {
+	snapshot := diagnostics(usage{goroutines: 12, openFiles: 34})
+
+	assert.True(t, strings.Contains(snapshot, "Goroutines: 12, open files: 34"))
+	assert.True(t, strings.Contains(snapshot, "goroutine profile:"))
+}
+
+func TestCurrentUsage(t *testing.T) {
+	current := currentUsage()
+
+	assert.True(t, current.goroutines > 0)
+	assert.True(t, current.openFiles != 0)
+}
+
+func TestWatchdogDisabled(t *testing.T) {
+	ctx := newContext(appconfig.SsmagentConfig{})
+	watchdog := NewWatchdog(ctx)
+
+	assert.Nil(t, watchdog.ModuleExecute(ctx))
+	assert.Nil(t, watchdog.stop)
+	assert.Nil(t, watchdog.ModuleRequestStop(contracts.StopTypeSoftStop))
+}
+
+func TestWatchdogStops(t *testing.T) {
+	config := appconfig.SsmagentConfig{}
+	config.Agent.WatchdogMaxGoroutines = 100000
+	ctx := newContext(config)
+	watchdog := NewWatchdog(ctx)
+
+	assert.Nil(t, watchdog.ModuleExecute(ctx))
+	assert.NotNil(t, watchdog.stop)
+	assert.Nil(t, watchdog.ModuleRequestStop(contracts.StopTypeSoftStop))
+	assert.Nil(t, watchdog.stop)
+	assert.Nil(t, watchdog.ModuleRequestStop(contracts.StopTypeSoftStop))
+}